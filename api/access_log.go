@@ -0,0 +1,206 @@
+package api
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Fields of access log records.
+const (
+	AccessLogFieldRequestID   = "request_id"
+	AccessLogFieldAccessKeyID = "access_key_id"
+	AccessLogFieldBucket      = "bucket"
+	AccessLogFieldObject      = "object"
+	AccessLogFieldOperation   = "operation"
+	AccessLogFieldMethod      = "method"
+	AccessLogFieldStatus      = "status"
+	AccessLogFieldBytesIn     = "bytes_in"
+	AccessLogFieldBytesOut    = "bytes_out"
+	AccessLogFieldLatency     = "latency"
+	AccessLogFieldClientIP    = "client_ip"
+	AccessLogFieldUserAgent   = "user_agent"
+)
+
+// AccessLogFields are all fields of access log records in the order of output.
+var AccessLogFields = []string{
+	AccessLogFieldRequestID,
+	AccessLogFieldAccessKeyID,
+	AccessLogFieldBucket,
+	AccessLogFieldObject,
+	AccessLogFieldOperation,
+	AccessLogFieldMethod,
+	AccessLogFieldStatus,
+	AccessLogFieldBytesIn,
+	AccessLogFieldBytesOut,
+	AccessLogFieldLatency,
+	AccessLogFieldClientIP,
+	AccessLogFieldUserAgent,
+}
+
+type (
+	// AccessLogSettings are parameters of access log records.
+	AccessLogSettings struct {
+		// Fields of records. All fields are logged if empty.
+		Fields []string
+		// SampleRatio is a fraction of requests to be logged.
+		SampleRatio float64
+		// LogErrors enables logging of all requests failed with 4xx and 5xx status codes regardless of sampling.
+		LogErrors bool
+	}
+
+	// AccessLog writes a record per request to the logger. Settings can be updated at runtime.
+	AccessLog struct {
+		log *zap.Logger
+
+		mu          sync.RWMutex
+		fields      map[string]struct{}
+		sampleRatio float64
+		logErrors   bool
+	}
+
+	accessLogRecord struct {
+		reqInfo  *ReqInfo
+		method   string
+		status   int
+		bytesIn  uint64
+		bytesOut uint64
+		latency  time.Duration
+	}
+)
+
+// NewAccessLog creates an access log writing records to log.
+func NewAccessLog(log *zap.Logger, settings AccessLogSettings) (*AccessLog, error) {
+	a := &AccessLog{log: log}
+	if err := a.Update(settings); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Update replaces settings. They aren't changed if new ones are invalid.
+func (a *AccessLog) Update(settings AccessLogSettings) error {
+	fields, err := parseAccessLogFields(settings.Fields)
+	if err != nil {
+		return err
+	}
+
+	if settings.SampleRatio < 0 || settings.SampleRatio > 1 {
+		return fmt.Errorf("invalid sample ratio %v, must be in [0, 1]", settings.SampleRatio)
+	}
+
+	a.mu.Lock()
+	a.fields = fields
+	a.sampleRatio = settings.SampleRatio
+	a.logErrors = settings.LogErrors
+	a.mu.Unlock()
+
+	return nil
+}
+
+func parseAccessLogFields(names []string) (map[string]struct{}, error) {
+	if len(names) == 0 {
+		names = AccessLogFields
+	}
+
+	known := make(map[string]struct{}, len(AccessLogFields))
+	for _, name := range AccessLogFields {
+		known[name] = struct{}{}
+	}
+
+	fields := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown access log field '%s'", name)
+		}
+		fields[name] = struct{}{}
+	}
+
+	return fields, nil
+}
+
+func (a *AccessLog) write(rec accessLogRecord) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !(a.logErrors && rec.status >= http.StatusBadRequest) && rand.Float64() >= a.sampleRatio {
+		return
+	}
+
+	fields := make([]zap.Field, 0, len(a.fields))
+	for _, name := range AccessLogFields {
+		if _, ok := a.fields[name]; ok {
+			fields = append(fields, rec.field(name))
+		}
+	}
+
+	a.log.Info("access", fields...)
+}
+
+func (r accessLogRecord) field(name string) zap.Field {
+	switch name {
+	case AccessLogFieldRequestID:
+		return zap.String(name, r.reqInfo.RequestID)
+	case AccessLogFieldAccessKeyID:
+		return zap.String(name, r.reqInfo.AccessKeyID)
+	case AccessLogFieldBucket:
+		return zap.String(name, r.reqInfo.BucketName)
+	case AccessLogFieldObject:
+		return zap.String(name, r.reqInfo.ObjectName)
+	case AccessLogFieldOperation:
+		return zap.String(name, r.reqInfo.API)
+	case AccessLogFieldMethod:
+		return zap.String(name, r.method)
+	case AccessLogFieldStatus:
+		return zap.Int(name, r.status)
+	case AccessLogFieldBytesIn:
+		return zap.Uint64(name, r.bytesIn)
+	case AccessLogFieldBytesOut:
+		return zap.Uint64(name, r.bytesOut)
+	case AccessLogFieldLatency:
+		return zap.Duration(name, r.latency)
+	case AccessLogFieldClientIP:
+		return zap.String(name, r.reqInfo.RemoteHost)
+	case AccessLogFieldUserAgent:
+		return zap.String(name, r.reqInfo.UserAgent)
+	default:
+		return zap.Skip()
+	}
+}
+
+// accessLogging writes a record of each request to the access log. Requests aren't logged if it's nil.
+func accessLogging(a *AccessLog) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		if a == nil {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			in := &countingReader{ReadCloser: r.Body}
+			out := &countingResponseWriter{ResponseWriter: w}
+			r.Body = in
+
+			h.ServeHTTP(out, r)
+
+			if out.statusCode == 0 {
+				out.statusCode = http.StatusOK
+			}
+
+			a.write(accessLogRecord{
+				reqInfo:  GetReqInfo(r.Context()),
+				method:   r.Method,
+				status:   out.statusCode,
+				bytesIn:  atomic.LoadUint64(&in.count),
+				bytesOut: atomic.LoadUint64(&out.count),
+				latency:  time.Since(start),
+			})
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLog(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+
+	accessLog, err := NewAccessLog(zap.New(core), AccessLogSettings{
+		Fields:      []string{AccessLogFieldRequestID, AccessLogFieldAccessKeyID, AccessLogFieldStatus, AccessLogFieldBytesIn, AccessLogFieldBytesOut},
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+
+	status := http.StatusOK
+	h := accessLogging(accessLog)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.Copy(io.Discard, r.Body)
+		require.NoError(t, err)
+		GetReqInfo(r.Context()).AccessKeyID = "key"
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+		}
+		_, err = w.Write([]byte("response"))
+		require.NoError(t, err)
+	}))
+
+	serve := func() {
+		r := httptest.NewRequest(http.MethodPut, "/bucket/object", strings.NewReader("payload"))
+		r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{RequestID: "id"}))
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	serve()
+	require.Equal(t, 1, logs.Len())
+	require.Equal(t, map[string]interface{}{
+		AccessLogFieldRequestID:   "id",
+		AccessLogFieldAccessKeyID: "key",
+		AccessLogFieldStatus:      int64(http.StatusOK),
+		AccessLogFieldBytesIn:     uint64(7),
+		AccessLogFieldBytesOut:    uint64(8),
+	}, logs.TakeAll()[0].ContextMap())
+
+	t.Run("sampling", func(t *testing.T) {
+		require.NoError(t, accessLog.Update(AccessLogSettings{SampleRatio: 0, LogErrors: true}))
+
+		serve()
+		require.Zero(t, logs.Len())
+
+		status = http.StatusNotFound
+		serve()
+		require.Equal(t, 1, logs.Len())
+		require.Len(t, logs.TakeAll()[0].Context, len(AccessLogFields))
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		require.Error(t, accessLog.Update(AccessLogSettings{Fields: []string{"unknown"}, SampleRatio: 1}))
+		require.Error(t, accessLog.Update(AccessLogSettings{SampleRatio: 2}))
+	})
+}
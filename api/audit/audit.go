@@ -0,0 +1,170 @@
+// Package audit implements a security audit log of authentication and authorization
+// decisions. Records are written as JSON lines chained by SHA-256 hashes: each record
+// contains the hash of the previous one, so removal or modification of records
+// breaks the chain and is detected by Verify.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Types of audit events.
+const (
+	// EventAuthFailure is an authentication failure, e.g. invalid signature or unknown access key.
+	EventAuthFailure = "auth_failure"
+	// EventCredentialUse is a successful authentication with an access key.
+	EventCredentialUse = "credential_use"
+	// EventAccessDenied is a request denied by a policy, ACL or source IP filter.
+	EventAccessDenied = "access_denied"
+	// EventACLChange is a change of bucket or object ACL or bucket policy.
+	EventACLChange = "acl_change"
+)
+
+type (
+	// Record is an audit log record.
+	Record struct {
+		Seq         uint64    `json:"seq"`
+		Time        time.Time `json:"time"`
+		Event       string    `json:"event"`
+		RequestID   string    `json:"request_id,omitempty"`
+		AccessKeyID string    `json:"access_key_id,omitempty"`
+		SourceIP    string    `json:"source_ip,omitempty"`
+		Operation   string    `json:"operation,omitempty"`
+		Bucket      string    `json:"bucket,omitempty"`
+		Object      string    `json:"object,omitempty"`
+		Reason      string    `json:"reason,omitempty"`
+		PrevHash    string    `json:"prev_hash"`
+		Hash        string    `json:"hash,omitempty"`
+	}
+
+	// Logger writes hash-chained audit records to the sink. Nil Logger discards records.
+	Logger struct {
+		mu       sync.Mutex
+		w        io.Writer
+		seq      uint64
+		prevHash string
+	}
+)
+
+// New creates audit logger writing records to w. The chain continues from the record
+// with prevSeq number and prevHash hash, zero values start a new chain.
+func New(w io.Writer, prevSeq uint64, prevHash string) *Logger {
+	return &Logger{
+		w:        w,
+		seq:      prevSeq,
+		prevHash: prevHash,
+	}
+}
+
+// OpenFile opens the audit log file for appending and creates a logger continuing
+// the chain of records which are already in the file.
+func OpenFile(path string) (*Logger, io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open audit log: %w", err)
+	}
+
+	last, err := Verify(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("verify audit log '%s': %w", path, err)
+	}
+
+	return New(f, last.Seq, last.Hash), f, nil
+}
+
+// Log writes the record to the sink. Sequence number, time and hashes are set by the logger.
+func (l *Logger) Log(rec Record) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rec.Seq = l.seq + 1
+	rec.Time = time.Now().UTC()
+	rec.PrevHash = l.prevHash
+
+	hash, err := recordHash(rec)
+	if err != nil {
+		return err
+	}
+	rec.Hash = hash
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode audit record: %w", err)
+	}
+
+	if _, err = l.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write audit record: %w", err)
+	}
+
+	l.seq = rec.Seq
+	l.prevHash = rec.Hash
+
+	return nil
+}
+
+// recordHash returns hex-encoded SHA-256 of the record encoded without its hash.
+func recordHash(rec Record) (string, error) {
+	rec.Hash = ""
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return "", fmt.Errorf("encode audit record: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verify reads audit records from r and checks their chain. The last record is returned,
+// it's zero if there are no records.
+func Verify(r io.Reader) (Record, error) {
+	var last Record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return last, fmt.Errorf("decode record after %d: %w", last.Seq, err)
+		}
+
+		if rec.Seq != last.Seq+1 || rec.PrevHash != last.Hash {
+			return last, fmt.Errorf("record %d doesn't follow record %d", rec.Seq, last.Seq)
+		}
+
+		hash, err := recordHash(rec)
+		if err != nil {
+			return last, err
+		}
+		if hash != rec.Hash {
+			return last, fmt.Errorf("hash mismatch of record %d", rec.Seq)
+		}
+
+		last = rec
+	}
+
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return last, fmt.Errorf("read records: %w", err)
+	}
+
+	return last, nil
+}
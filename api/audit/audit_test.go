@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogVerify(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := New(&buf, 0, "")
+	require.NoError(t, l.Log(Record{Event: EventAuthFailure, AccessKeyID: "key", Reason: "invalid signature"}))
+	require.NoError(t, l.Log(Record{Event: EventCredentialUse, AccessKeyID: "key"}))
+
+	last, err := Verify(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, last.Seq)
+	require.Equal(t, EventCredentialUse, last.Event)
+
+	// chain is continued by a new logger
+	l = New(&buf, last.Seq, last.Hash)
+	require.NoError(t, l.Log(Record{Event: EventACLChange, Bucket: "bucket"}))
+
+	last, err = Verify(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.EqualValues(t, 3, last.Seq)
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+
+	t.Run("modified record", func(t *testing.T) {
+		modified := strings.Replace(lines[0], "invalid signature", "valid signature", 1)
+		_, err := Verify(strings.NewReader(modified + lines[1] + lines[2]))
+		require.Error(t, err)
+	})
+
+	t.Run("removed record", func(t *testing.T) {
+		last, err := Verify(strings.NewReader(lines[0] + lines[2]))
+		require.Error(t, err)
+		require.EqualValues(t, 1, last.Seq)
+	})
+}
+
+func TestNilLogger(t *testing.T) {
+	var l *Logger
+	require.NoError(t, l.Log(Record{Event: EventAccessDenied}))
+}
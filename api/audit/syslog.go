@@ -0,0 +1,22 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package audit
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+)
+
+// DialSyslog connects to the syslog server and creates a logger sending records with
+// auth facility. Empty network and address connect to the local syslog server.
+// Chain of records is started anew, the server is expected to store records in order.
+func DialSyslog(network, addr, tag string) (*Logger, io.Closer, error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to syslog: %w", err)
+	}
+
+	return New(w, 0, ""), w, nil
+}
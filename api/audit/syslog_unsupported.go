@@ -0,0 +1,14 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package audit
+
+import (
+	"errors"
+	"io"
+)
+
+// DialSyslog returns an error since syslog isn't supported on the platform.
+func DialSyslog(string, string, string) (*Logger, io.Closer, error) {
+	return nil, nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/audit"
+	"go.uber.org/zap"
+)
+
+// aclChangeRoutes are the routes which change access rules of buckets and objects.
+var aclChangeRoutes = map[string]struct{}{
+	"PutBucketACL":       {},
+	"PutObjectACL":       {},
+	"PutBucketPolicy":    {},
+	"DeleteBucketPolicy": {},
+}
+
+// auditRecord creates an audit record of the request.
+func auditRecord(r *http.Request, event, reason string) audit.Record {
+	reqInfo := GetReqInfo(r.Context())
+
+	accessKeyID := reqInfo.AccessKeyID
+	if accessKeyID == "" {
+		accessKeyID = claimedAccessKeyID(r)
+	}
+
+	return audit.Record{
+		Event:       event,
+		RequestID:   reqInfo.RequestID,
+		AccessKeyID: accessKeyID,
+		SourceIP:    reqInfo.RemoteHost,
+		Operation:   reqInfo.API,
+		Bucket:      reqInfo.BucketName,
+		Object:      reqInfo.ObjectName,
+		Reason:      reason,
+	}
+}
+
+// writeAudit writes the record to the audit log. Failures of the audit log are logged to log.
+func writeAudit(auditLog *audit.Logger, log *zap.Logger, rec audit.Record) {
+	if err := auditLog.Log(rec); err != nil {
+		log.Error("couldn't write audit record", zap.String("event", rec.Event),
+			zap.String("request_id", rec.RequestID), zap.Error(err))
+	}
+}
+
+// auditRequests writes records of requests denied by bucket policies or ACLs
+// and of successful changes of access rules.
+func auditRequests(auditLog *audit.Logger, log *zap.Logger) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		if auditLog == nil {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lw := &logResponseWriter{ResponseWriter: w}
+			h.ServeHTTP(lw, r)
+
+			switch {
+			case lw.statusCode == http.StatusForbidden:
+				writeAudit(auditLog, log, auditRecord(r, audit.EventAccessDenied, "denied by bucket policy or ACL"))
+			case lw.statusCode < http.StatusMultipleChoices:
+				if _, ok := aclChangeRoutes[GetReqInfo(r.Context()).API]; ok {
+					writeAudit(auditLog, log, auditRecord(r, audit.EventACLChange, ""))
+				}
+			}
+		})
+	}
+}
+
+// claimedAccessKeyID returns access key id from credentials of the request
+// which failed authentication, so the attempts to use the key are audited.
+func claimedAccessKeyID(r *http.Request) string {
+	if authHeader := r.Header.Get(Authorization); authHeader != "" {
+		if i := strings.Index(authHeader, "Credential="); i >= 0 {
+			credential := authHeader[i+len("Credential="):]
+			return credential[:strings.IndexAny(credential+"/", "/,")]
+		}
+		if strings.HasPrefix(authHeader, "AWS ") {
+			credential := strings.TrimPrefix(authHeader, "AWS ")
+			return credential[:strings.IndexAny(credential+":", ":")]
+		}
+	}
+
+	query := r.URL.Query()
+	if credential := query.Get("X-Amz-Credential"); credential != "" {
+		return credential[:strings.IndexAny(credential+"/", "/")]
+	}
+
+	return query.Get("AWSAccessKeyId")
+}
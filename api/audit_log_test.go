@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimedAccessKeyID(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		url      string
+		header   string
+		expected string
+	}{
+		{
+			name:     "sigv4 header",
+			url:      "/bucket",
+			header:   "AWS4-HMAC-SHA256 Credential=key/20221110/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=abc",
+			expected: "key",
+		},
+		{
+			name:     "sigv2 header",
+			url:      "/bucket",
+			header:   "AWS key:signature",
+			expected: "key",
+		},
+		{
+			name:     "sigv4 presigned",
+			url:      "/bucket?X-Amz-Credential=key%2F20221110%2Fus-east-1%2Fs3%2Faws4_request",
+			expected: "key",
+		},
+		{
+			name:     "sigv2 presigned",
+			url:      "/bucket?AWSAccessKeyId=key&Signature=abc",
+			expected: "key",
+		},
+		{
+			name: "anonymous",
+			url:  "/bucket",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.url, nil)
+			if tc.header != "" {
+				r.Header.Set(Authorization, tc.header)
+			}
+			require.Equal(t, tc.expected, claimedAccessKeyID(r))
+		})
+	}
+}
@@ -15,13 +15,14 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	v4 "github.com/nspcc-dev/neofs-s3-gw/api/auth/signer/v4"
-	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 )
 
 // authorizationFieldRegexp -- is regexp for credentials with Base58 encoded cid and oid and '0' (zero) as delimiter.
@@ -34,12 +35,15 @@ type (
 	// Center is a user authentication interface.
 	Center interface {
 		Authenticate(request *http.Request) (*Box, error)
+		// AccessKeyOwner returns the user the access key was issued for.
+		AccessKeyOwner(ctx context.Context, accessKeyID string) (user.ID, error)
 	}
 
 	// Box contains access box and additional info.
 	Box struct {
-		AccessBox  *accessbox.Box
-		ClientTime time.Time
+		AccessBox   *accessbox.Box
+		AccessKeyID string
+		ClientTime  time.Time
 	}
 
 	center struct {
@@ -47,6 +51,9 @@ type (
 		postReg                    *RegexpSubmatcher
 		cli                        tokens.Credentials
 		allowedAccessKeyIDPrefixes []string // empty slice means all access key ids are allowed
+		revokedAccessKeys          *RevokedAccessKeys
+		importedAccessKeysCnr      *cid.ID // nil means imported access key ids aren't accepted
+		replayProtection           *ReplayProtection
 	}
 
 	prs int
@@ -92,12 +99,15 @@ func (p prs) Seek(_ int64, _ int) (int64, error) {
 var _ io.ReadSeeker = prs(0)
 
 // New creates an instance of AuthCenter.
-func New(neoFS tokens.NeoFS, key *keys.PrivateKey, prefixes []string, config *cache.Config) Center {
+func New(creds tokens.Credentials, prefixes []string, revoked *RevokedAccessKeys, importedCnr *cid.ID, replay *ReplayProtection) Center {
 	return &center{
-		cli:                        tokens.New(neoFS, key, config),
+		cli:                        creds,
 		reg:                        NewRegexpMatcher(authorizationFieldRegexp),
 		postReg:                    NewRegexpMatcher(postPolicyCredentialRegexp),
 		allowedAccessKeyIDPrefixes: prefixes,
+		revokedAccessKeys:          revoked,
+		importedAccessKeysCnr:      importedCnr,
+		replayProtection:           replay,
 	}
 }
 
@@ -108,7 +118,7 @@ func (c *center) parseAuthHeader(header string) (*authHeader, error) {
 	}
 
 	accessKey := strings.Split(submatches["access_key_id"], "0")
-	if len(accessKey) != accessKeyPartsNum {
+	if len(accessKey) != accessKeyPartsNum && c.importedAccessKeysCnr == nil {
 		return nil, apiErrors.GetAPIError(apiErrors.ErrInvalidAccessKeyID)
 	}
 
@@ -132,6 +142,43 @@ func (a *authHeader) getAddress() (oid.Address, error) {
 	return addr, nil
 }
 
+// getAddress returns address of the access box with the access key ID. Access key IDs
+// which aren't encoded addresses are looked up among the imported ones.
+func (c *center) getAddress(ctx context.Context, accessKeyID string) (oid.Address, error) {
+	addr, err := (&authHeader{AccessKeyID: accessKeyID}).getAddress()
+	if err == nil || c.importedAccessKeysCnr == nil {
+		return addr, err
+	}
+
+	addr, err = c.cli.ResolveAccessKeyID(ctx, *c.importedAccessKeysCnr, accessKeyID)
+	if err != nil {
+		if errors.Is(err, tokens.ErrAccessKeyIDNotFound) {
+			return addr, apiErrors.GetAPIError(apiErrors.ErrInvalidAccessKeyID)
+		}
+		return addr, fmt.Errorf("resolve access key id: %w", err)
+	}
+
+	return addr, nil
+}
+
+// AccessKeyOwner returns the issuer of the bearer token of the access box with the access key ID.
+func (c *center) AccessKeyOwner(ctx context.Context, accessKeyID string) (user.ID, error) {
+	addr, err := c.getAddress(ctx, accessKeyID)
+	if err != nil {
+		return user.ID{}, err
+	}
+
+	box, err := c.cli.GetBox(ctx, addr)
+	if err != nil {
+		return user.ID{}, fmt.Errorf("get box: %w", err)
+	}
+	if box.Gate == nil || box.Gate.BearerToken == nil {
+		return user.ID{}, errors.New("access box has no bearer token")
+	}
+
+	return bearer.ResolveIssuer(*box.Gate.BearerToken), nil
+}
+
 func (c *center) Authenticate(r *http.Request) (*Box, error) {
 	var (
 		err                  error
@@ -140,6 +187,10 @@ func (c *center) Authenticate(r *http.Request) (*Box, error) {
 		needClientTime       bool
 	)
 
+	if isSignatureV2(r) {
+		return c.authenticateV2(r)
+	}
+
 	queryValues := r.URL.Query()
 	if queryValues.Get(AmzAlgorithm) == "AWS4-HMAC-SHA256" {
 		creds := strings.Split(queryValues.Get(AmzCredential), "/")
@@ -159,10 +210,13 @@ func (c *center) Authenticate(r *http.Request) (*Box, error) {
 		if err != nil {
 			return nil, fmt.Errorf("couldn't parse X-Amz-Expires: %w", err)
 		}
+		if err = c.replayProtection.checkPresignedExpiration(authHdr.Expiration); err != nil {
+			return nil, err
+		}
 		signatureDateTimeStr = queryValues.Get(AmzDate)
 	} else {
 		authHeaderField := r.Header[AuthorizationHdr]
-		if len(authHeaderField) != 1 {
+		if len(authHeaderField) != 1 || authHeaderField[0] == "" {
 			if strings.HasPrefix(r.Header.Get(ContentTypeHdr), "multipart/form-data") {
 				return c.checkFormData(r)
 			}
@@ -181,26 +235,48 @@ func (c *center) Authenticate(r *http.Request) (*Box, error) {
 		return nil, fmt.Errorf("failed to parse x-amz-date header field: %w", err)
 	}
 
+	if !authHdr.IsPresigned {
+		if err = c.replayProtection.checkRequestTime(signatureDateTime); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := c.checkAccessKeyID(authHdr.AccessKeyID); err != nil {
 		return nil, err
 	}
 
-	addr, err := authHdr.getAddress()
+	addr, err := c.getAddress(r.Context(), authHdr.AccessKeyID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = c.checkRevoked(r.Context(), authHdr.AccessKeyID, addr); err != nil {
+		return nil, err
+	}
+
 	box, err := c.cli.GetBox(r.Context(), addr)
 	if err != nil {
 		return nil, fmt.Errorf("get box: %w", err)
 	}
 
-	clonedRequest := cloneRequest(r, authHdr)
-	if err = c.checkSign(authHdr, box, clonedRequest, signatureDateTime); err != nil {
+	secret, err := matchAccessKey(box, func(secret string) error {
+		return c.checkSign(authHdr, secret, cloneRequest(r, authHdr), signatureDateTime)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkSessionToken(sessionTokenFromRequest(r), authHdr.AccessKeyID, secret, box.Expiration); err != nil {
 		return nil, err
 	}
 
-	result := &Box{AccessBox: box}
+	if authHdr.IsPresigned {
+		if err = c.replayProtection.usePresigned(authHdr.SignatureV4, signatureDateTime.Add(authHdr.Expiration)); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Box{AccessBox: box, AccessKeyID: authHdr.AccessKeyID}
 	if needClientTime {
 		result.ClientTime = signatureDateTime
 	}
@@ -222,6 +298,33 @@ func (c center) checkAccessKeyID(accessKeyID string) error {
 	return apiErrors.GetAPIError(apiErrors.ErrAccessDenied)
 }
 
+// checkRevoked rejects the access key ID if it or any other access key ID of the same
+// access box is revoked. It must be called with the resolved address of the access box.
+func (c *center) checkRevoked(ctx context.Context, accessKeyID string, addr oid.Address) error {
+	if c.revokedAccessKeys.IsRevoked(accessKeyID) {
+		return apiErrors.GetAPIError(apiErrors.ErrInvalidAccessKeyID)
+	}
+
+	revoked, err := c.revokedAccessKeys.IsRevokedBox(addr, func(revokedID string) (*oid.Address, error) {
+		boxAddr, err := c.getAddress(ctx, revokedID)
+		if err != nil {
+			if errors.Is(err, apiErrors.GetAPIError(apiErrors.ErrInvalidAccessKeyID)) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &boxAddr, nil
+	})
+	if err != nil {
+		return fmt.Errorf("check revoked access key ids: %w", err)
+	}
+	if revoked {
+		return apiErrors.GetAPIError(apiErrors.ErrInvalidAccessKeyID)
+	}
+
+	return nil
+}
+
 func (c *center) checkFormData(r *http.Request) (*Box, error) {
 	if err := r.ParseMultipartForm(maxFormSizeMemory); err != nil {
 		return nil, apiErrors.GetAPIError(apiErrors.ErrInvalidArgument)
@@ -246,9 +349,13 @@ func (c *center) checkFormData(r *http.Request) (*Box, error) {
 		return nil, fmt.Errorf("failed to parse x-amz-date field: %w", err)
 	}
 
-	var addr oid.Address
-	if err = addr.DecodeString(strings.ReplaceAll(submatches["access_key_id"], "0", "/")); err != nil {
-		return nil, apiErrors.GetAPIError(apiErrors.ErrInvalidAccessKeyID)
+	addr, err := c.getAddress(r.Context(), submatches["access_key_id"])
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.checkRevoked(r.Context(), submatches["access_key_id"], addr); err != nil {
+		return nil, err
 	}
 
 	box, err := c.cli.GetBox(r.Context(), addr)
@@ -256,15 +363,22 @@ func (c *center) checkFormData(r *http.Request) (*Box, error) {
 		return nil, fmt.Errorf("get box: %w", err)
 	}
 
-	secret := box.Gate.AccessKey
 	service, region := submatches["service"], submatches["region"]
+	secret, err := matchAccessKey(box, func(secret string) error {
+		if signStr(secret, service, region, signatureDateTime, policy) != MultipartFormValue(r, "x-amz-signature") {
+			return apiErrors.GetAPIError(apiErrors.ErrSignatureDoesNotMatch)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	signature := signStr(secret, service, region, signatureDateTime, policy)
-	if signature != MultipartFormValue(r, "x-amz-signature") {
-		return nil, apiErrors.GetAPIError(apiErrors.ErrSignatureDoesNotMatch)
+	if err = checkSessionToken(MultipartFormValue(r, "x-amz-security-token"), submatches["access_key_id"], secret, box.Expiration); err != nil {
+		return nil, err
 	}
 
-	return &Box{AccessBox: box}, nil
+	return &Box{AccessBox: box, AccessKeyID: submatches["access_key_id"]}, nil
 }
 
 func cloneRequest(r *http.Request, authHeader *authHeader) *http.Request {
@@ -288,8 +402,21 @@ func cloneRequest(r *http.Request, authHeader *authHeader) *http.Request {
 	return otherRequest
 }
 
-func (c *center) checkSign(authHeader *authHeader, box *accessbox.Box, request *http.Request, signatureDateTime time.Time) error {
-	awsCreds := credentials.NewStaticCredentials(authHeader.AccessKeyID, box.Gate.AccessKey, "")
+// matchAccessKey returns the secret of the box the request is signed with.
+// The previous secret is checked too while the grace window of the rotation lasts.
+func matchAccessKey(box *accessbox.Box, check func(secret string) error) (string, error) {
+	var err error
+	for _, secret := range box.AccessKeys() {
+		if err = check(secret); err == nil || !errors.Is(err, apiErrors.GetAPIError(apiErrors.ErrSignatureDoesNotMatch)) {
+			return secret, err
+		}
+	}
+
+	return "", err
+}
+
+func (c *center) checkSign(authHeader *authHeader, secret string, request *http.Request, signatureDateTime time.Time) error {
+	awsCreds := credentials.NewStaticCredentials(authHeader.AccessKeyID, secret, "")
 	signer := v4.NewSigner(awsCreds)
 
 	var signature string
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+)
+
+// ReplayProtection rejects captured signed requests: the ones signed outside the clock skew window,
+// presigned URLs with too long expiration and the ones used more than once.
+type ReplayProtection struct {
+	mu                     sync.RWMutex
+	clockSkew              time.Duration
+	maxPresignedExpiration time.Duration
+
+	noncesMu sync.Mutex
+	nonces   gcache.Cache // nil means presigned URLs can be reused
+}
+
+// NewReplayProtection creates protection against replay of signed requests. Zero clockSkew
+// disables the check of request time, zero maxPresignedExpiration doesn't limit expiration
+// of presigned URLs, zero noncesSize allows presigned URLs to be reused.
+func NewReplayProtection(clockSkew, maxPresignedExpiration time.Duration, noncesSize int) *ReplayProtection {
+	p := &ReplayProtection{clockSkew: clockSkew, maxPresignedExpiration: maxPresignedExpiration}
+	if noncesSize > 0 {
+		p.nonces = gcache.New(noncesSize).LRU().Build()
+	}
+	return p
+}
+
+// SetClockSkew updates the maximum allowed difference between the request time and the server time.
+func (p *ReplayProtection) SetClockSkew(clockSkew time.Duration) {
+	p.mu.Lock()
+	p.clockSkew = clockSkew
+	p.mu.Unlock()
+}
+
+// SetMaxPresignedExpiration updates the maximum allowed expiration of presigned URLs.
+func (p *ReplayProtection) SetMaxPresignedExpiration(maxExpiration time.Duration) {
+	p.mu.Lock()
+	p.maxPresignedExpiration = maxExpiration
+	p.mu.Unlock()
+}
+
+// checkPresignedExpiration checks that the presigned URL doesn't expire too far in the future.
+func (p *ReplayProtection) checkPresignedExpiration(expiration time.Duration) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.RLock()
+	maxExpiration := p.maxPresignedExpiration
+	p.mu.RUnlock()
+
+	if maxExpiration > 0 && expiration > maxExpiration {
+		return apiErrors.GetAPIError(apiErrors.ErrMaximumExpires)
+	}
+
+	return nil
+}
+
+// checkRequestTime checks that the request was signed within the clock skew window.
+func (p *ReplayProtection) checkRequestTime(signed time.Time) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.RLock()
+	clockSkew := p.clockSkew
+	p.mu.RUnlock()
+
+	if clockSkew <= 0 {
+		return nil
+	}
+
+	if diff := time.Since(signed); diff > clockSkew || diff < -clockSkew {
+		return apiErrors.GetAPIError(apiErrors.ErrRequestTimeTooSkewed)
+	}
+
+	return nil
+}
+
+// usePresigned marks the presigned URL with the signature as used until its expiration.
+// It returns an error if the URL has already been used.
+func (p *ReplayProtection) usePresigned(signature string, expiration time.Time) error {
+	if p == nil || p.nonces == nil {
+		return nil
+	}
+
+	p.noncesMu.Lock()
+	defer p.noncesMu.Unlock()
+
+	if p.nonces.Has(signature) {
+		return apiErrors.GetAPIError(apiErrors.ErrAccessDenied)
+	}
+
+	ttl := time.Until(expiration)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return p.nonces.SetWithExpire(signature, struct{}{}, ttl)
+}
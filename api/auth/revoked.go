@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"sync"
+
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// RevokedAccessKeys is a set of revoked access key ids which can be updated at runtime.
+type RevokedAccessKeys struct {
+	mu    sync.RWMutex
+	keys  map[string]struct{}
+	boxes map[string]*oid.Address // access boxes of the revoked keys resolved so far, nil if unknown
+	epoch uint64                  // incremented on each Update
+}
+
+// NewRevokedAccessKeys creates a set of revoked access key ids.
+func NewRevokedAccessKeys(accessKeyIDs []string) *RevokedAccessKeys {
+	r := &RevokedAccessKeys{}
+	r.Update(accessKeyIDs)
+	return r
+}
+
+// Update replaces revoked access key ids.
+func (r *RevokedAccessKeys) Update(accessKeyIDs []string) {
+	keys := make(map[string]struct{}, len(accessKeyIDs))
+	for _, id := range accessKeyIDs {
+		keys[id] = struct{}{}
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.boxes = make(map[string]*oid.Address, len(keys))
+	r.epoch++
+	r.mu.Unlock()
+}
+
+// IsRevoked checks if the access key id is revoked.
+func (r *RevokedAccessKeys) IsRevoked(accessKeyID string) bool {
+	if r == nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.keys[accessKeyID]
+	return ok
+}
+
+// IsRevokedBox checks if any revoked access key id opens the access box, so the native
+// and the imported access key ids of the same box are revoked together. Revoked ids are
+// resolved to access boxes once and remembered until the next Update, resolve returns nil
+// address for ids which don't open any box.
+func (r *RevokedAccessKeys) IsRevokedBox(addr oid.Address, resolve func(accessKeyID string) (*oid.Address, error)) (bool, error) {
+	if r == nil {
+		return false, nil
+	}
+
+	r.mu.RLock()
+	epoch := r.epoch
+	var unresolved []string
+	for id := range r.keys {
+		boxAddr, ok := r.boxes[id]
+		if !ok {
+			unresolved = append(unresolved, id)
+		} else if boxAddr != nil && *boxAddr == addr {
+			r.mu.RUnlock()
+			return true, nil
+		}
+	}
+	r.mu.RUnlock()
+
+	resolved := make(map[string]*oid.Address, len(unresolved))
+	for _, id := range unresolved {
+		boxAddr, err := resolve(id)
+		if err != nil {
+			return false, err
+		}
+		resolved[id] = boxAddr
+	}
+
+	r.mu.Lock()
+	if r.epoch == epoch {
+		for id, boxAddr := range resolved {
+			r.boxes[id] = boxAddr
+		}
+	}
+	r.mu.Unlock()
+
+	for _, boxAddr := range resolved {
+		if boxAddr != nil && *boxAddr == addr {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+)
+
+// AmzSecurityToken is a header and query parameter with the session token of temporary credentials.
+const AmzSecurityToken = "X-Amz-Security-Token"
+
+// newSessionToken returns the session token of temporary credentials. The token contains
+// expiration time and MAC of access key ID signed with the secret key, so it can be checked
+// without storing anything besides the access box.
+func newSessionToken(accessKeyID, secret string, expiration time.Time) string {
+	exp := strconv.FormatInt(expiration.Unix(), 10)
+	return exp + "." + base64.RawURLEncoding.EncodeToString(sessionTokenMAC(accessKeyID, secret, exp))
+}
+
+func sessionTokenMAC(accessKeyID, secret, exp string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(accessKeyID + ":" + exp))
+	return mac.Sum(nil)
+}
+
+// sessionTokenFromRequest returns the session token from header or query of the request.
+func sessionTokenFromRequest(r *http.Request) string {
+	if token := r.Header.Get(AmzSecurityToken); token != "" {
+		return token
+	}
+	return r.URL.Query().Get(AmzSecurityToken)
+}
+
+// checkSessionToken checks that the session token was issued for the credentials and isn't expired.
+// Temporary credentials with non-zero expiration require the token issued with the same expiration,
+// so the expiration is enforced by the access box even if the token is forged by the secret holder.
+// Empty token is allowed for permanent credentials.
+func checkSessionToken(token, accessKeyID, secret string, expiration time.Time) error {
+	if !expiration.IsZero() && time.Now().After(expiration) {
+		return apiErrors.GetAPIError(apiErrors.ErrExpiredToken)
+	}
+
+	if token == "" {
+		if !expiration.IsZero() {
+			return apiErrors.GetAPIError(apiErrors.ErrInvalidToken)
+		}
+		return nil
+	}
+
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return apiErrors.GetAPIError(apiErrors.ErrInvalidToken)
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(mac, sessionTokenMAC(accessKeyID, secret, parts[0])) {
+		return apiErrors.GetAPIError(apiErrors.ErrInvalidToken)
+	}
+
+	exp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return apiErrors.GetAPIError(apiErrors.ErrInvalidToken)
+	}
+
+	if !expiration.IsZero() && exp != expiration.Unix() {
+		return apiErrors.GetAPIError(apiErrors.ErrInvalidToken)
+	}
+
+	if time.Now().After(time.Unix(exp, 0)) {
+		return apiErrors.GetAPIError(apiErrors.ErrExpiredToken)
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+)
+
+const (
+	signatureV2Prefix = "AWS "
+
+	AmzAccessKeyIDV2 = "AWSAccessKeyId"
+	AmzSignatureV2   = "Signature"
+	AmzExpiresV2     = "Expires"
+	DateHdr          = "Date"
+	ContentMD5Hdr    = "Content-Md5"
+)
+
+// subresourcesV2 are query parameters included in canonicalized resource of signature V2.
+var subresourcesV2 = map[string]struct{}{
+	"accelerate": {}, "acl": {}, "cors": {}, "delete": {}, "encryption": {}, "legal-hold": {},
+	"lifecycle": {}, "location": {}, "logging": {}, "notification": {}, "object-lock": {},
+	"partNumber": {}, "policy": {}, "publicAccessBlock": {}, "replication": {}, "requestPayment": {},
+	"response-cache-control": {}, "response-content-disposition": {}, "response-content-encoding": {},
+	"response-content-language": {}, "response-content-type": {}, "response-expires": {},
+	"restore": {}, "retention": {}, "tagging": {}, "torrent": {}, "uploadId": {}, "uploads": {},
+	"versionId": {}, "versioning": {}, "versions": {}, "website": {},
+}
+
+// authenticateV2 checks AWS signature V2 of the request. Access key ID and signature
+// are taken from Authorization header or from query parameters of presigned URL.
+func (c *center) authenticateV2(r *http.Request) (*Box, error) {
+	var (
+		accessKeyID, signature, date string
+		clientTime, expiration       time.Time
+	)
+
+	query := r.URL.Query()
+	if query.Get(AmzAccessKeyIDV2) != "" {
+		accessKeyID = query.Get(AmzAccessKeyIDV2)
+		signature = query.Get(AmzSignatureV2)
+		date = query.Get(AmzExpiresV2)
+
+		expires, err := strconv.ParseInt(date, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse %s: %w", AmzExpiresV2, err)
+		}
+		expiration = time.Unix(expires, 0)
+		if expiration.Before(time.Now()) {
+			return nil, apiErrors.GetAPIError(apiErrors.ErrExpiredPresignRequest)
+		}
+		if err = c.replayProtection.checkPresignedExpiration(time.Until(expiration)); err != nil {
+			return nil, err
+		}
+	} else {
+		credentials := strings.SplitN(strings.TrimPrefix(r.Header.Get(AuthorizationHdr), signatureV2Prefix), ":", 2)
+		if len(credentials) != 2 || credentials[0] == "" || credentials[1] == "" {
+			return nil, apiErrors.GetAPIError(apiErrors.ErrAuthorizationHeaderMalformed)
+		}
+		accessKeyID, signature = credentials[0], credentials[1]
+
+		dateHeader := r.Header.Get(AmzDate)
+		if dateHeader == "" {
+			dateHeader = r.Header.Get(DateHdr)
+			date = dateHeader
+		}
+
+		var err error
+		if clientTime, err = http.ParseTime(dateHeader); err != nil {
+			return nil, fmt.Errorf("failed to parse date header field: %w", err)
+		}
+		if err = c.replayProtection.checkRequestTime(clientTime); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.checkAccessKeyID(accessKeyID); err != nil {
+		return nil, err
+	}
+
+	addr, err := c.getAddress(r.Context(), accessKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.checkRevoked(r.Context(), accessKeyID, addr); err != nil {
+		return nil, err
+	}
+
+	box, err := c.cli.GetBox(r.Context(), addr)
+	if err != nil {
+		return nil, fmt.Errorf("get box: %w", err)
+	}
+
+	secret, err := matchAccessKey(box, func(secret string) error {
+		if !hmac.Equal([]byte(signV2(secret, stringToSignV2(r, date))), []byte(signature)) {
+			return apiErrors.GetAPIError(apiErrors.ErrSignatureDoesNotMatch)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkSessionToken(sessionTokenFromRequest(r), accessKeyID, secret, box.Expiration); err != nil {
+		return nil, err
+	}
+
+	if !expiration.IsZero() {
+		if err = c.replayProtection.usePresigned(signature, expiration); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Box{AccessBox: box, AccessKeyID: accessKeyID, ClientTime: clientTime}, nil
+}
+
+func isSignatureV2(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get(AuthorizationHdr), signatureV2Prefix) ||
+		r.URL.Query().Get(AmzAccessKeyIDV2) != "" && r.URL.Query().Get(AmzSignatureV2) != ""
+}
+
+func signV2(secret, strToSign string) string {
+	hash := hmac.New(sha1.New, []byte(secret))
+	hash.Write([]byte(strToSign))
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
+}
+
+// stringToSignV2 forms the string to sign according to AWS signature V2. Date is
+// the value of Date header or Expires parameter of presigned URL.
+func stringToSignV2(r *http.Request, date string) string {
+	var b strings.Builder
+
+	b.WriteString(r.Method + "\n")
+	b.WriteString(r.Header.Get(ContentMD5Hdr) + "\n")
+	b.WriteString(r.Header.Get(ContentTypeHdr) + "\n")
+	b.WriteString(date + "\n")
+	b.WriteString(canonicalizedAmzHeadersV2(r.Header))
+	b.WriteString(canonicalizedResourceV2(r))
+
+	return b.String()
+}
+
+func canonicalizedAmzHeadersV2(header http.Header) string {
+	amzHeaders := make(map[string][]string)
+	for key, values := range header {
+		lowerKey := strings.ToLower(key)
+		if !strings.HasPrefix(lowerKey, "x-amz-") {
+			continue
+		}
+		for _, value := range values {
+			amzHeaders[lowerKey] = append(amzHeaders[lowerKey], strings.TrimSpace(value))
+		}
+	}
+
+	keys := make([]string, 0, len(amzHeaders))
+	for key := range amzHeaders {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key + ":" + strings.Join(amzHeaders[key], ",") + "\n")
+	}
+
+	return b.String()
+}
+
+// canonicalizedResourceV2 returns the path of the request with subresources. The bucket
+// of virtual-hosted-style request is resolved from the route and prepended to the path.
+func canonicalizedResourceV2(r *http.Request) string {
+	path := r.URL.EscapedPath()
+	if bucket := mux.Vars(r)["bucket"]; bucket != "" && strings.HasPrefix(r.Host, bucket+".") {
+		path = "/" + bucket + path
+	}
+
+	query := r.URL.Query()
+	subresources := make([]string, 0, len(query))
+	for key := range query {
+		if _, ok := subresourcesV2[key]; ok {
+			subresources = append(subresources, key)
+		}
+	}
+	if len(subresources) == 0 {
+		return path
+	}
+	sort.Strings(subresources)
+
+	params := make([]string, 0, len(subresources))
+	for _, key := range subresources {
+		if value := query.Get(key); value != "" {
+			params = append(params, key+"="+value)
+		} else {
+			params = append(params, key)
+		}
+	}
+
+	return path + "?" + strings.Join(params, "&")
+}
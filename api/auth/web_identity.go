@@ -0,0 +1,310 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+const (
+	// MinWebIdentityDuration is the minimal lifetime of temporary credentials.
+	MinWebIdentityDuration = 15 * time.Minute
+	// DefaultWebIdentityDuration is the lifetime of temporary credentials used when it's not requested explicitly.
+	DefaultWebIdentityDuration = time.Hour
+)
+
+type (
+	// WebIdentity exchanges tokens of OIDC identity provider for temporary credentials.
+	WebIdentity interface {
+		AssumeRoleWithWebIdentity(ctx context.Context, prm AssumeRoleWithWebIdentityPrm) (*TemporaryCredentials, error)
+	}
+
+	// WebIdentityNeoFS represents virtual connection to NeoFS network used to store temporary credentials.
+	WebIdentityNeoFS interface {
+		tokens.NeoFS
+
+		// TimeToEpoch computes the current epoch and the epoch that corresponds to the provided time.
+		TimeToEpoch(context.Context, time.Time) (uint64, uint64, error)
+	}
+
+	// WebIdentityConfig contains OIDC identity provider settings and roles that can be assumed.
+	WebIdentityConfig struct {
+		// Issuer must match 'iss' claim of the token. Provider keys are discovered from
+		// Issuer if JWKSURL is empty.
+		Issuer  string
+		JWKSURL string
+		// Audiences contain allowed values of 'aud' claim, at least one is required.
+		Audiences []string
+		// Container stores access boxes of temporary credentials.
+		Container cid.ID
+		Roles     []WebIdentityRole
+		Client    *http.Client
+	}
+
+	// WebIdentityRole grants tokens of access box AccessKeyID to the holders of
+	// identity tokens with claims matching Claims patterns.
+	WebIdentityRole struct {
+		ARN         string
+		AccessKeyID string
+		// Claims map claim names to shell patterns of their values, at least one is required.
+		Claims      map[string]string
+		MaxDuration time.Duration
+	}
+
+	// AssumeRoleWithWebIdentityPrm groups parameters of AssumeRoleWithWebIdentity request.
+	AssumeRoleWithWebIdentityPrm struct {
+		RoleARN         string
+		RoleSessionName string
+		Token           string
+		Duration        time.Duration
+	}
+
+	// TemporaryCredentials are credentials issued for the identity token.
+	TemporaryCredentials struct {
+		AccessKeyID     string
+		SecretAccessKey string
+		SessionToken    string
+		Expiration      time.Time
+		Subject         string
+		Audience        string
+		Issuer          string
+	}
+
+	webIdentity struct {
+		cfg    *WebIdentityConfig
+		cli    tokens.Credentials
+		neoFS  WebIdentityNeoFS
+		key    *keys.PrivateKey
+		keySet *jsonWebKeySet
+	}
+)
+
+// Validate checks that tokens issued for other clients of the identity provider can't be exchanged
+// for credentials: audiences must be set and every role must have claim conditions.
+func (c *WebIdentityConfig) Validate() error {
+	if len(c.Audiences) == 0 {
+		return errors.New("no audiences")
+	}
+
+	for _, role := range c.Roles {
+		if len(role.Claims) == 0 {
+			return fmt.Errorf("no claims of role '%s'", role.ARN)
+		}
+	}
+
+	return nil
+}
+
+// NewWebIdentity creates an instance of WebIdentity. Temporary credentials are
+// encrypted for the gate key.
+func NewWebIdentity(neoFS WebIdentityNeoFS, key *keys.PrivateKey, cfg *WebIdentityConfig, config *cache.Config) WebIdentity {
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &webIdentity{
+		cfg:    cfg,
+		cli:    tokens.New(neoFS, key, config),
+		neoFS:  neoFS,
+		key:    key,
+		keySet: &jsonWebKeySet{client: client, issuer: cfg.Issuer, url: cfg.JWKSURL},
+	}
+}
+
+func (w *webIdentity) AssumeRoleWithWebIdentity(ctx context.Context, prm AssumeRoleWithWebIdentityPrm) (*TemporaryCredentials, error) {
+	role := w.role(prm.RoleARN)
+	if role == nil {
+		return nil, fmt.Errorf("%w: unknown role '%s'", apiErrors.GetAPIError(apiErrors.ErrAccessDenied), prm.RoleARN)
+	}
+
+	duration := prm.Duration
+	if duration == 0 {
+		duration = DefaultWebIdentityDuration
+	}
+	maxDuration := role.MaxDuration
+	if maxDuration == 0 {
+		maxDuration = DefaultWebIdentityDuration
+	}
+	if duration < MinWebIdentityDuration || duration > maxDuration {
+		return nil, fmt.Errorf("%w: duration must be between %s and %s", apiErrors.GetAPIError(apiErrors.ErrInvalidArgument),
+			MinWebIdentityDuration, maxDuration)
+	}
+
+	claims, err := w.verifyToken(ctx, prm.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !role.matches(claims) {
+		return nil, fmt.Errorf("%w: token claims don't match role '%s'", apiErrors.GetAPIError(apiErrors.ErrAccessDenied), role.ARN)
+	}
+
+	srcAddr, err := (&authHeader{AccessKeyID: role.AccessKeyID}).getAddress()
+	if err != nil {
+		return nil, fmt.Errorf("invalid access key id of role '%s': %w", role.ARN, err)
+	}
+
+	srcBox, err := w.cli.GetBox(ctx, srcAddr)
+	if err != nil {
+		return nil, fmt.Errorf("get box of role '%s': %w", role.ARN, err)
+	}
+
+	expiration := time.Now().Add(duration)
+	_, expEpoch, err := w.neoFS.TimeToEpoch(ctx, expiration)
+	if err != nil {
+		return nil, fmt.Errorf("fetch time to epoch: %w", err)
+	}
+
+	box, secrets, err := accessbox.PackTokens([]*accessbox.GateData{{
+		BearerToken:   srcBox.Gate.BearerToken,
+		SessionTokens: srcBox.Gate.SessionTokens,
+		GateKey:       w.key.PublicKey(),
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("pack tokens: %w", err)
+	}
+	// Tokens of the role are signed by its owner and can't be reissued by the gate, but they're
+	// encrypted for the gate key only, so the gate refuses to use them after the expiration.
+	box.Expiration = expiration.Unix()
+
+	for _, policy := range srcBox.Policies {
+		box.ContainerPolicy = append(box.ContainerPolicy, &accessbox.AccessBox_ContainerPolicy{
+			LocationConstraint: policy.LocationConstraint,
+			Policy:             policy.Policy.Marshal(),
+		})
+	}
+
+	if srcBox.InlinePolicy != nil {
+		if box.InlinePolicy, err = json.Marshal(srcBox.InlinePolicy); err != nil {
+			return nil, fmt.Errorf("marshal inline policy: %w", err)
+		}
+	}
+
+	var owner user.ID
+	user.IDFromKey(&owner, w.key.PrivateKey.PublicKey)
+
+	addr, err := w.cli.Put(ctx, w.cfg.Container, owner, box, expEpoch, w.key.PublicKey())
+	if err != nil {
+		return nil, fmt.Errorf("put access box: %w", err)
+	}
+
+	accessKeyID := addr.Container().EncodeToString() + "0" + addr.Object().EncodeToString()
+	creds := &TemporaryCredentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secrets.AccessKey,
+		SessionToken:    newSessionToken(accessKeyID, secrets.AccessKey, expiration),
+		Expiration:      expiration,
+		Issuer:          w.cfg.Issuer,
+	}
+	creds.Subject, _ = claims["sub"].(string)
+	if auds := claimValues(claims["aud"]); len(auds) > 0 {
+		creds.Audience = auds[0]
+	}
+
+	return creds, nil
+}
+
+func (w *webIdentity) role(arn string) *WebIdentityRole {
+	for i := range w.cfg.Roles {
+		if w.cfg.Roles[i].ARN == arn {
+			return &w.cfg.Roles[i]
+		}
+	}
+	return nil
+}
+
+// verifyToken checks signature, issuer, audience and lifetime of the token and returns its claims.
+func (w *webIdentity) verifyToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	invalidToken := apiErrors.GetAPIError(apiErrors.ErrInvalidIdentityToken)
+
+	claims, err := w.keySet.parseJWT(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", invalidToken, err)
+	}
+
+	if iss, _ := claims["iss"].(string); iss != w.cfg.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer '%s'", invalidToken, iss)
+	}
+
+	if !containsAny(claimValues(claims["aud"]), w.cfg.Audiences) {
+		return nil, fmt.Errorf("%w: audience is not allowed", invalidToken)
+	}
+
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing expiration", invalidToken)
+	}
+	if now.After(time.Unix(int64(exp), 0)) {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrExpiredIdentityToken)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("%w: token is not valid yet", invalidToken)
+	}
+
+	return claims, nil
+}
+
+// matches checks that every claim of the role has at least one value matching the pattern.
+// Role without claims matches nothing.
+func (r *WebIdentityRole) matches(claims map[string]interface{}) bool {
+	if len(r.Claims) == 0 {
+		return false
+	}
+
+	for name, pattern := range r.Claims {
+		var matched bool
+		for _, value := range claimValues(claims[name]) {
+			if ok, err := path.Match(pattern, value); err == nil && ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// claimValues converts string or list of strings claim to the slice.
+func claimValues(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func containsAny(values, allowed []string) bool {
+	for _, value := range values {
+		for _, a := range allowed {
+			if value == a {
+				return true
+			}
+		}
+	}
+	return false
+}
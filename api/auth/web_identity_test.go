@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer  = "https://issuer.example"
+	testRoleARN = "arn:aws:iam::123456789012:role/reader"
+)
+
+type epochMock struct {
+	tokens.NeoFS
+}
+
+func (epochMock) TimeToEpoch(context.Context, time.Time) (uint64, uint64, error) {
+	return 1, 10, nil
+}
+
+func TestAssumeRoleWithWebIdentity(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string][]jsonWebKey{"keys": {
+			{Kty: "RSA", Kid: "rsa", N: encodeSegment(rsaKey.N.Bytes()), E: encodeSegment(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{Kty: "EC", Kid: "ec", Crv: "P-256", X: encodeSegment(ecKey.X.Bytes()), Y: encodeSegment(ecKey.Y.Bytes())},
+		}})
+	}))
+	defer jwks.Close()
+
+	gateKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	var tkn bearer.Token
+	tkn.SetEACLTable(*eacl.NewTable())
+	require.NoError(t, tkn.Sign(gateKey.PrivateKey))
+
+	srcAddr := oidtest.Address()
+	creds := &credentialsMock{boxes: map[oid.Address]*accessbox.Box{
+		srcAddr: {Gate: &accessbox.GateData{BearerToken: &tkn}},
+	}}
+
+	w := &webIdentity{
+		cfg: &WebIdentityConfig{
+			Issuer:    testIssuer,
+			JWKSURL:   jwks.URL,
+			Audiences: []string{"s3"},
+			Container: cidtest.ID(),
+			Roles: []WebIdentityRole{{
+				ARN:         testRoleARN,
+				AccessKeyID: strings.ReplaceAll(srcAddr.EncodeToString(), "/", "0"),
+				Claims:      map[string]string{"sub": "system:serviceaccount:default:*"},
+			}},
+		},
+		cli:    creds,
+		neoFS:  epochMock{},
+		key:    gateKey,
+		keySet: &jsonWebKeySet{client: http.DefaultClient, url: jwks.URL},
+	}
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss": testIssuer,
+			"sub": "system:serviceaccount:default:app",
+			"aud": []string{"s3"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	assumeRole := func(token string) (*TemporaryCredentials, error) {
+		return w.AssumeRoleWithWebIdentity(context.Background(), AssumeRoleWithWebIdentityPrm{
+			RoleARN:         testRoleARN,
+			RoleSessionName: "session",
+			Token:           token,
+		})
+	}
+
+	t.Run("rsa", func(t *testing.T) {
+		res, err := assumeRole(signRS256(t, rsaKey, "rsa", validClaims()))
+		require.NoError(t, err)
+		require.Equal(t, "system:serviceaccount:default:app", res.Subject)
+		require.Equal(t, "s3", res.Audience)
+		require.NoError(t, checkSessionToken(res.SessionToken, res.AccessKeyID, res.SecretAccessKey, res.Expiration))
+
+		var addr oid.Address
+		require.NoError(t, addr.DecodeString(strings.Replace(res.AccessKeyID, "0", "/", 1)))
+		box, err := creds.accessBoxes[addr].GetBox(gateKey)
+		require.NoError(t, err)
+		require.Equal(t, res.SecretAccessKey, box.Gate.AccessKey)
+		require.Equal(t, tkn.Marshal(), box.Gate.BearerToken.Marshal())
+		require.Equal(t, res.Expiration.Unix(), box.Expiration.Unix())
+	})
+
+	t.Run("ecdsa", func(t *testing.T) {
+		_, err := assumeRole(signES256(t, ecKey, "ec", validClaims()))
+		require.NoError(t, err)
+	})
+
+	t.Run("claims mismatch", func(t *testing.T) {
+		claims := validClaims()
+		claims["sub"] = "system:serviceaccount:kube-system:app"
+		_, err := assumeRole(signRS256(t, rsaKey, "rsa", claims))
+		require.ErrorIs(t, err, apiErrors.GetAPIError(apiErrors.ErrAccessDenied))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		claims := validClaims()
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		_, err := assumeRole(signRS256(t, rsaKey, "rsa", claims))
+		require.ErrorIs(t, err, apiErrors.GetAPIError(apiErrors.ErrExpiredIdentityToken))
+	})
+
+	t.Run("invalid audience", func(t *testing.T) {
+		claims := validClaims()
+		claims["aud"] = "other"
+		_, err := assumeRole(signRS256(t, rsaKey, "rsa", claims))
+		require.ErrorIs(t, err, apiErrors.GetAPIError(apiErrors.ErrInvalidIdentityToken))
+	})
+
+	t.Run("role without claims", func(t *testing.T) {
+		claims := w.cfg.Roles[0].Claims
+		w.cfg.Roles[0].Claims = nil
+		defer func() { w.cfg.Roles[0].Claims = claims }()
+		_, err := assumeRole(signRS256(t, rsaKey, "rsa", validClaims()))
+		require.ErrorIs(t, err, apiErrors.GetAPIError(apiErrors.ErrAccessDenied))
+	})
+
+	t.Run("no audiences", func(t *testing.T) {
+		audiences := w.cfg.Audiences
+		w.cfg.Audiences = nil
+		defer func() { w.cfg.Audiences = audiences }()
+		_, err := assumeRole(signRS256(t, rsaKey, "rsa", validClaims()))
+		require.ErrorIs(t, err, apiErrors.GetAPIError(apiErrors.ErrInvalidIdentityToken))
+	})
+
+	t.Run("algorithm mismatches curve", func(t *testing.T) {
+		input := signingInput(t, "ES384", "ec", validClaims())
+		digest := sha512.Sum384([]byte(input))
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		require.NoError(t, err)
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		_, err = assumeRole(input + "." + encodeSegment(sig))
+		require.ErrorIs(t, err, apiErrors.GetAPIError(apiErrors.ErrInvalidIdentityToken))
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		_, err = assumeRole(signRS256(t, otherKey, "rsa", validClaims()))
+		require.ErrorIs(t, err, apiErrors.GetAPIError(apiErrors.ErrInvalidIdentityToken))
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		_, err := w.AssumeRoleWithWebIdentity(context.Background(), AssumeRoleWithWebIdentityPrm{
+			RoleARN:  testRoleARN,
+			Token:    signRS256(t, rsaKey, "rsa", validClaims()),
+			Duration: 2 * time.Hour,
+		})
+		require.ErrorIs(t, err, apiErrors.GetAPIError(apiErrors.ErrInvalidArgument))
+	})
+}
+
+func TestWebIdentityConfigValidate(t *testing.T) {
+	cfg := &WebIdentityConfig{
+		Audiences: []string{"s3"},
+		Roles:     []WebIdentityRole{{ARN: testRoleARN, Claims: map[string]string{"sub": "*"}}},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Roles = append(cfg.Roles, WebIdentityRole{ARN: testRoleARN})
+	require.Error(t, cfg.Validate())
+
+	cfg.Roles = cfg.Roles[:1]
+	cfg.Audiences = nil
+	require.Error(t, cfg.Validate())
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signingInput(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(jwtHeader{Alg: alg, Kid: kid})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return encodeSegment(header) + "." + encodeSegment(payload)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	input := signingInput(t, "RS256", kid, claims)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return input + "." + encodeSegment(sig)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	input := signingInput(t, "ES256", kid, claims)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return input + "." + encodeSegment(sig)
+}
@@ -4,14 +4,13 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/bluele/gcache"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"go.uber.org/zap"
 )
 
 // AccessControlCache provides lru cache for objects.
 type AccessControlCache struct {
-	cache  gcache.Cache
+	cache  *statCache
 	logger *zap.Logger
 }
 
@@ -33,7 +32,7 @@ func DefaultAccessControlConfig(logger *zap.Logger) *Config {
 
 // NewAccessControlCache creates an object of AccessControlCache.
 func NewAccessControlCache(config *Config) *AccessControlCache {
-	gc := gcache.New(config.Size).LRU().Expiration(config.Lifetime).Build()
+	gc := newStatCache("accesscontrol", config)
 	return &AccessControlCache{cache: gc, logger: config.Logger}
 }
 
@@ -67,3 +66,8 @@ func (o *AccessControlCache) Delete(owner user.ID, key string) bool {
 func cacheKey(owner user.ID, key string) string {
 	return owner.EncodeToString() + key
 }
+
+// Statistic returns usage counters of the cache.
+func (o *AccessControlCache) Statistic() Statistic {
+	return o.cache.statistic()
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/bluele/gcache"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"go.uber.org/zap"
@@ -14,7 +13,7 @@ type (
 	// AccessBoxCache stores an access box by its address.
 	AccessBoxCache struct {
 		logger *zap.Logger
-		cache  gcache.Cache
+		cache  *statCache
 	}
 
 	// Config stores expiration params for cache.
@@ -22,6 +21,10 @@ type (
 		Size     int
 		Lifetime time.Duration
 		Logger   *zap.Logger
+		// Disabled cache doesn't store entries.
+		Disabled bool
+		// Backend keeps entries of names, list and system caches shared between gateways instead of local LRU cache.
+		Backend Backend
 	}
 )
 
@@ -43,7 +46,7 @@ func DefaultAccessBoxConfig(logger *zap.Logger) *Config {
 
 // NewAccessBoxCache creates an object of BucketCache.
 func NewAccessBoxCache(config *Config) *AccessBoxCache {
-	gc := gcache.New(config.Size).LRU().Expiration(config.Lifetime).Build()
+	gc := newStatCache("accessbox", config)
 
 	return &AccessBoxCache{cache: gc, logger: config.Logger}
 }
@@ -69,3 +72,26 @@ func (o *AccessBoxCache) Get(address oid.Address) *accessbox.Box {
 func (o *AccessBoxCache) Put(address oid.Address, box *accessbox.Box) error {
 	return o.cache.Set(address, box)
 }
+
+// Delete removes an access box from cache.
+func (o *AccessBoxCache) Delete(address oid.Address) bool {
+	return o.cache.Remove(address)
+}
+
+// Addresses returns addresses of the cached access boxes.
+func (o *AccessBoxCache) Addresses() []oid.Address {
+	keys := o.cache.Keys(true)
+	addrs := make([]oid.Address, 0, len(keys))
+	for _, key := range keys {
+		if addr, ok := key.(oid.Address); ok {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return addrs
+}
+
+// Statistic returns usage counters of the cache.
+func (o *AccessBoxCache) Statistic() Statistic {
+	return o.cache.statistic()
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"fmt"
+
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"go.uber.org/zap"
+)
+
+// AccessKeyIDCache stores addresses of access boxes by imported access key IDs.
+type AccessKeyIDCache struct {
+	logger *zap.Logger
+	cache  *statCache
+}
+
+// NewAccessKeyIDCache creates an object of AccessKeyIDCache.
+func NewAccessKeyIDCache(config *Config) *AccessKeyIDCache {
+	gc := newStatCache("accesskeyid", config)
+
+	return &AccessKeyIDCache{cache: gc, logger: config.Logger}
+}
+
+// Get returns a cached address. Returns nil if value is missing.
+func (o *AccessKeyIDCache) Get(accessKeyID string) *oid.Address {
+	entry, err := o.cache.Get(accessKeyID)
+	if err != nil {
+		return nil
+	}
+
+	result, ok := entry.(oid.Address)
+	if !ok {
+		o.logger.Warn("invalid cache entry type", zap.String("actual", fmt.Sprintf("%T", entry)),
+			zap.String("expected", fmt.Sprintf("%T", result)))
+		return nil
+	}
+
+	return &result
+}
+
+// Put stores an address to cache.
+func (o *AccessKeyIDCache) Put(accessKeyID string, address oid.Address) error {
+	return o.cache.Set(accessKeyID, address)
+}
+
+// Delete removes an address from cache.
+func (o *AccessKeyIDCache) Delete(accessKeyID string) bool {
+	return o.cache.Remove(accessKeyID)
+}
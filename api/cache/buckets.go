@@ -4,14 +4,13 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/bluele/gcache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"go.uber.org/zap"
 )
 
 // BucketCache contains cache with objects and the lifetime of cache entries.
 type BucketCache struct {
-	cache  gcache.Cache
+	cache  *statCache
 	logger *zap.Logger
 }
 
@@ -33,7 +32,7 @@ func DefaultBucketConfig(logger *zap.Logger) *Config {
 
 // NewBucketCache creates an object of BucketCache.
 func NewBucketCache(config *Config) *BucketCache {
-	gc := gcache.New(config.Size).LRU().Expiration(config.Lifetime).Build()
+	gc := newStatCache("buckets", config)
 	return &BucketCache{cache: gc, logger: config.Logger}
 }
 
@@ -63,3 +62,14 @@ func (o *BucketCache) Put(bkt *data.BucketInfo) error {
 func (o *BucketCache) Delete(key string) bool {
 	return o.cache.Remove(key)
 }
+
+// DeleteMatching deletes entries with keys matching the filter and returns their number.
+// Keys are bucket names.
+func (o *BucketCache) DeleteMatching(match func(key string) bool) int {
+	return o.cache.removeMatching(match)
+}
+
+// Statistic returns usage counters of the cache.
+func (o *BucketCache) Statistic() Statistic {
+	return o.cache.statistic()
+}
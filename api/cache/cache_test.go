@@ -6,6 +6,7 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
 	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -47,6 +48,24 @@ func TestBucketsCacheType(t *testing.T) {
 	assertInvalidCacheEntry(t, cache.Get(bktInfo.Name), observedLog)
 }
 
+func TestEACLCacheType(t *testing.T) {
+	logger, observedLog := getObservedLogger()
+	cache := NewEACLCache(DefaultEACLConfig(logger))
+
+	cnrID := cidtest.ID()
+	table := eacl.NewTable()
+
+	err := cache.Put(cnrID, table)
+	require.NoError(t, err)
+	val := cache.Get(cnrID)
+	require.Equal(t, table, val)
+	require.Equal(t, 0, observedLog.Len())
+
+	err = cache.cache.Set(cnrID, "tmp")
+	require.NoError(t, err)
+	assertInvalidCacheEntry(t, cache.Get(cnrID), observedLog)
+}
+
 func TestObjectNamesCacheType(t *testing.T) {
 	logger, observedLog := getObservedLogger()
 	cache := NewObjectsNameCache(DefaultObjectsNameConfig(logger))
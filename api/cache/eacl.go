@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"go.uber.org/zap"
+)
+
+// EACLCache contains cache with extended ACL tables of containers.
+// Key is container ID.
+type EACLCache struct {
+	cache  *statCache
+	logger *zap.Logger
+}
+
+const (
+	// DefaultEACLCacheSize is a default maximum number of entries in cache.
+	DefaultEACLCacheSize = 1e3
+	// DefaultEACLCacheLifetime is a default lifetime of entries in cache.
+	DefaultEACLCacheLifetime = time.Minute
+)
+
+// DefaultEACLConfig returns new default cache expiration values.
+func DefaultEACLConfig(logger *zap.Logger) *Config {
+	return &Config{
+		Size:     DefaultEACLCacheSize,
+		Lifetime: DefaultEACLCacheLifetime,
+		Logger:   logger,
+	}
+}
+
+// NewEACLCache creates an object of EACLCache.
+func NewEACLCache(config *Config) *EACLCache {
+	gc := newStatCache("eacl", config)
+	return &EACLCache{cache: gc, logger: config.Logger}
+}
+
+// Get returns a cached eACL table of the container. Returns nil if value is missing.
+func (o *EACLCache) Get(cnrID cid.ID) *eacl.Table {
+	entry, err := o.cache.Get(cnrID)
+	if err != nil {
+		return nil
+	}
+
+	result, ok := entry.(*eacl.Table)
+	if !ok {
+		o.logger.Warn("invalid cache entry type", zap.String("actual", fmt.Sprintf("%T", entry)),
+			zap.String("expected", fmt.Sprintf("%T", result)))
+		return nil
+	}
+
+	return result
+}
+
+// Put puts an eACL table of the container to cache.
+func (o *EACLCache) Put(cnrID cid.ID, table *eacl.Table) error {
+	return o.cache.Set(cnrID, table)
+}
+
+// Delete deletes an eACL table of the container from cache.
+func (o *EACLCache) Delete(cnrID cid.ID) bool {
+	return o.cache.Remove(cnrID)
+}
+
+// Statistic returns usage counters of the cache.
+func (o *EACLCache) Statistic() Statistic {
+	return o.cache.statistic()
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/bluele/gcache"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"go.uber.org/zap"
 )
@@ -13,7 +12,7 @@ import (
 // This cache contains mapping nice names to object addresses.
 // Key is bucketName+objectName.
 type ObjectsNameCache struct {
-	cache  gcache.Cache
+	cache  store
 	logger *zap.Logger
 }
 
@@ -35,7 +34,7 @@ func DefaultObjectsNameConfig(logger *zap.Logger) *Config {
 
 // NewObjectsNameCache creates an object of ObjectsNameCache.
 func NewObjectsNameCache(config *Config) *ObjectsNameCache {
-	gc := gcache.New(config.Size).LRU().Expiration(config.Lifetime).Build()
+	gc := newStore("names", config)
 	return &ObjectsNameCache{cache: gc, logger: config.Logger}
 }
 
@@ -65,3 +64,14 @@ func (o *ObjectsNameCache) Put(key string, address oid.Address) error {
 func (o *ObjectsNameCache) Delete(key string) bool {
 	return o.cache.Remove(key)
 }
+
+// DeleteMatching deletes entries with keys matching the filter and returns their number.
+// Keys are <bucket>/<object>.
+func (o *ObjectsNameCache) DeleteMatching(match func(key string) bool) int {
+	return o.cache.removeMatching(match)
+}
+
+// Statistic returns usage counters of the cache.
+func (o *ObjectsNameCache) Statistic() Statistic {
+	return o.cache.statistic()
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/bluele/gcache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"go.uber.org/zap"
@@ -12,7 +11,7 @@ import (
 
 // ObjectsCache provides lru cache for objects.
 type ObjectsCache struct {
-	cache  gcache.Cache
+	cache  *statCache
 	logger *zap.Logger
 }
 
@@ -34,7 +33,7 @@ func DefaultObjectsConfig(logger *zap.Logger) *Config {
 
 // New creates an object of ObjectHeadersCache.
 func New(config *Config) *ObjectsCache {
-	gc := gcache.New(config.Size).LRU().Expiration(config.Lifetime).Build()
+	gc := newStatCache("objects", config)
 	return &ObjectsCache{cache: gc, logger: config.Logger}
 }
 
@@ -64,3 +63,14 @@ func (o *ObjectsCache) PutObject(obj *data.ExtendedObjectInfo) error {
 func (o *ObjectsCache) Delete(address oid.Address) bool {
 	return o.cache.Remove(address)
 }
+
+// DeleteMatching deletes entries with keys matching the filter and returns their number.
+// Keys are <cid>/<oid>.
+func (o *ObjectsCache) DeleteMatching(match func(key string) bool) int {
+	return o.cache.removeMatching(match)
+}
+
+// Statistic returns usage counters of the cache.
+func (o *ObjectsCache) Statistic() Statistic {
+	return o.cache.statistic()
+}
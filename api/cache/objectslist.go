@@ -6,7 +6,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/bluele/gcache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	"go.uber.org/zap"
@@ -14,7 +13,7 @@ import (
 
 /*
 	This is an implementation of cache which keeps unsorted lists of objects' IDs (all versions)
-	for a specified bucket and a prefix.
+	for a specified bucket, a prefix and a delimiter.
 
 	The cache contains gcache whose entries have a key: ObjectsListKey struct and a value: list of ids.
 	After putting a record, it lives for a while (default value is 60 seconds).
@@ -22,13 +21,16 @@ import (
 	When we receive a request from a user, we try to find the suitable and non-expired cache entry, go through the list
 	and get ObjectInfos from common object cache or with a request to NeoFS.
 
+	A list without a delimiter also serves narrower prefixes: a list of the prefix "dir/" contains everything
+	the prefix "dir/sub/" needs. Lists of prefixes ending with the separator are looked up for that.
+
 	When we put an object into a container, we invalidate entries with prefixes that are prefixes of the object's name.
 */
 
 type (
 	// ObjectsListCache contains cache for ListObjects and ListObjectVersions.
 	ObjectsListCache struct {
-		cache  gcache.Cache
+		cache  store
 		logger *zap.Logger
 	}
 
@@ -36,6 +38,7 @@ type (
 	ObjectsListKey struct {
 		cid        cid.ID
 		prefix     string
+		delimiter  string
 		latestOnly bool
 	}
 )
@@ -45,6 +48,8 @@ const (
 	DefaultObjectsListCacheLifetime = time.Second * 60
 	// DefaultObjectsListCacheSize is a default size of cache of ListObjects.
 	DefaultObjectsListCacheSize = 1e5
+
+	prefixSeparator = "/"
 )
 
 // DefaultObjectsListConfig returns new default cache expiration values.
@@ -56,13 +61,41 @@ func DefaultObjectsListConfig(logger *zap.Logger) *Config {
 	}
 }
 
+// String returns <cid>:<latest only>:<prefix> for keys without a delimiter
+// and <cid>:<latest only>:<quoted delimiter>:<prefix> otherwise.
 func (k *ObjectsListKey) String() string {
-	return k.cid.EncodeToString() + k.prefix + strconv.FormatBool(k.latestOnly)
+	res := k.cid.EncodeToString() + ":" + strconv.FormatBool(k.latestOnly) + ":"
+	if k.delimiter != "" {
+		res += strconv.Quote(k.delimiter) + ":"
+	}
+	return res + k.prefix
+}
+
+// Prefix returns the prefix of the listing.
+func (k *ObjectsListKey) Prefix() string {
+	return k.prefix
+}
+
+// BroaderKeys returns keys of lists without a delimiter which contain the list of the key:
+// the key itself without the delimiter if it's set, and the keys of shorter prefixes ending
+// with the separator, from the longest to the empty one.
+func (k *ObjectsListKey) BroaderKeys() []ObjectsListKey {
+	var res []ObjectsListKey
+	if k.delimiter != "" {
+		res = append(res, CreateObjectsListCacheKey(k.cid, k.prefix, "", k.latestOnly))
+	}
+
+	for prefix := k.prefix; prefix != ""; {
+		prefix = prefix[:strings.LastIndex(strings.TrimSuffix(prefix, prefixSeparator), prefixSeparator)+1]
+		res = append(res, CreateObjectsListCacheKey(k.cid, prefix, "", k.latestOnly))
+	}
+
+	return res
 }
 
 // NewObjectsListCache is a constructor which creates an object of ListObjectsCache with the given lifetime of entries.
 func NewObjectsListCache(config *Config) *ObjectsListCache {
-	gc := gcache.New(config.Size).LRU().Expiration(config.Lifetime).Build()
+	gc := newStore("list", config)
 	return &ObjectsListCache{cache: gc, logger: config.Logger}
 }
 
@@ -84,13 +117,30 @@ func (l *ObjectsListCache) GetVersions(key ObjectsListKey) []*data.NodeVersion {
 }
 
 // PutVersions puts a list of object versions to cache.
+// Lists with a delimiter aren't put to the shared cache since its entries are invalidated by exact keys,
+// the list of the same prefix without the delimiter is used instead.
 func (l *ObjectsListCache) PutVersions(key ObjectsListKey, versions []*data.NodeVersion) error {
+	if _, ok := l.cache.(*sharedCache); ok && key.delimiter != "" {
+		return nil
+	}
 	return l.cache.Set(key, versions)
 }
 
 // CleanCacheEntriesContainingObject deletes entries containing specified object.
 func (l *ObjectsListCache) CleanCacheEntriesContainingObject(objectName string, cnr cid.ID) {
-	keys := l.cache.Keys(true)
+	if shared, ok := l.cache.(*sharedCache); ok {
+		// keys of the shared cache can't be enumerated, so entries of all prefixes of the name are deleted
+		keys := make([]string, 0, 2*(len(objectName)+1))
+		for i := 0; i <= len(objectName); i++ {
+			keys = append(keys,
+				shared.key(CreateObjectsListCacheKey(cnr, objectName[:i], "", true)),
+				shared.key(CreateObjectsListCacheKey(cnr, objectName[:i], "", false)))
+		}
+		shared.removeKeys(keys...)
+		return
+	}
+
+	keys := l.cache.(*statCache).Keys(true)
 	for _, key := range keys {
 		k, ok := key.(ObjectsListKey)
 		if !ok {
@@ -104,13 +154,25 @@ func (l *ObjectsListCache) CleanCacheEntriesContainingObject(objectName string,
 	}
 }
 
-// CreateObjectsListCacheKey returns ObjectsListKey with the given CID, prefix and latestOnly flag.
-func CreateObjectsListCacheKey(cnr cid.ID, prefix string, latestOnly bool) ObjectsListKey {
+// CreateObjectsListCacheKey returns ObjectsListKey with the given CID, prefix, delimiter and latestOnly flag.
+func CreateObjectsListCacheKey(cnr cid.ID, prefix, delimiter string, latestOnly bool) ObjectsListKey {
 	p := ObjectsListKey{
 		cid:        cnr,
 		prefix:     prefix,
+		delimiter:  delimiter,
 		latestOnly: latestOnly,
 	}
 
 	return p
 }
+
+// DeleteMatching deletes entries with keys matching the filter and returns their number.
+// Keys are <cid>:<latest only>:<prefix> or <cid>:<latest only>:<quoted delimiter>:<prefix>.
+func (l *ObjectsListCache) DeleteMatching(match func(key string) bool) int {
+	return l.cache.removeMatching(match)
+}
+
+// Statistic returns usage counters of the cache.
+func (l *ObjectsListCache) Statistic() Statistic {
+	return l.cache.statistic()
+}
@@ -189,3 +189,36 @@ func TestCleanCacheEntriesChangedWithPutObject(t *testing.T) {
 		}
 	})
 }
+
+func TestObjectsListKeyBroaderKeys(t *testing.T) {
+	id := cidtest.ID()
+
+	key := CreateObjectsListCacheKey(id, "dir/sub/obj", "/", true)
+	require.Equal(t, []ObjectsListKey{
+		CreateObjectsListCacheKey(id, "dir/sub/obj", "", true),
+		CreateObjectsListCacheKey(id, "dir/sub/", "", true),
+		CreateObjectsListCacheKey(id, "dir/", "", true),
+		CreateObjectsListCacheKey(id, "", "", true),
+	}, key.BroaderKeys())
+
+	key = CreateObjectsListCacheKey(id, "dir/", "", false)
+	require.Equal(t, []ObjectsListKey{CreateObjectsListCacheKey(id, "", "", false)}, key.BroaderKeys())
+
+	key = CreateObjectsListCacheKey(id, "", "", false)
+	require.Empty(t, key.BroaderKeys())
+}
+
+func TestObjectsListKeyDelimiter(t *testing.T) {
+	var (
+		id       = cidtest.ID()
+		versions = []*data.NodeVersion{{BaseNodeVersion: data.BaseNodeVersion{OID: oidtest.ID()}}}
+		cache    = NewObjectsListCache(getTestObjectsListConfig())
+	)
+
+	require.NoError(t, cache.PutVersions(CreateObjectsListCacheKey(id, "dir/", "/", true), versions))
+	require.Nil(t, cache.GetVersions(CreateObjectsListCacheKey(id, "dir/", "", true)))
+	require.Equal(t, versions, cache.GetVersions(CreateObjectsListCacheKey(id, "dir/", "/", true)))
+
+	cache.CleanCacheEntriesContainingObject("dir/obj", id)
+	require.Nil(t, cache.GetVersions(CreateObjectsListCacheKey(id, "dir/", "/", true)))
+}
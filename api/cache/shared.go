@@ -0,0 +1,383 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
+)
+
+type (
+	// Backend is a storage of cache entries shared by several gateway instances,
+	// so they see the changes of metadata made by each other.
+	Backend interface {
+		// Get returns the value of the key or ErrNotFound.
+		Get(key string) ([]byte, error)
+		// Set puts the value of the key expiring after ttl.
+		Set(key string, value []byte, ttl time.Duration) error
+		// Delete removes the keys. Missing keys are ignored.
+		Delete(keys ...string) error
+		// Keys returns the keys starting with the prefix.
+		Keys(prefix string) ([]string, error)
+	}
+
+	// store is a storage of cache entries.
+	store interface {
+		Get(key interface{}) (interface{}, error)
+		Set(key, value interface{}) error
+		Remove(key interface{}) bool
+		removeMatching(match func(key string) bool) int
+		statistic() Statistic
+	}
+
+	// sharedCache keeps entries in Backend encoded in JSON.
+	sharedCache struct {
+		name     string
+		backend  Backend
+		lifetime time.Duration
+		logger   *zap.Logger
+
+		hits   uint64
+		misses uint64
+	}
+
+	sharedEntry struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	sharedNodeVersion struct {
+		ID            uint64 `json:"id"`
+		ParentID      uint64 `json:"parent_id"`
+		OID           oid.ID `json:"oid"`
+		Timestamp     uint64 `json:"timestamp"`
+		Size          int64  `json:"size"`
+		ETag          string `json:"etag"`
+		FilePath      string `json:"file_path"`
+		IsUnversioned bool   `json:"is_unversioned"`
+
+		DeleteMarker *sharedDeleteMarker `json:"delete_marker,omitempty"`
+		ObjectMeta   *sharedObjectMeta   `json:"object_meta,omitempty"`
+	}
+
+	sharedObjectMeta struct {
+		Created      time.Time `json:"created"`
+		Owner        string    `json:"owner"`
+		StorageClass string    `json:"storage_class,omitempty"`
+	}
+
+	sharedDeleteMarker struct {
+		Created time.Time `json:"created"`
+		Owner   string    `json:"owner"`
+	}
+
+	sharedLockInfo struct {
+		ID           uint64  `json:"id"`
+		LegalHold    *oid.ID `json:"legal_hold,omitempty"`
+		Retention    *oid.ID `json:"retention,omitempty"`
+		UntilDate    string  `json:"until_date,omitempty"`
+		IsCompliance bool    `json:"is_compliance,omitempty"`
+	}
+)
+
+// Types of shared entries.
+const (
+	sharedTypeAddress      = "address"
+	sharedTypeVersions     = "versions"
+	sharedTypeTagging      = "tagging"
+	sharedTypeLock         = "lock"
+	sharedTypeSettings     = "settings"
+	sharedTypeCORS         = "cors"
+	sharedTypeNotification = "notification"
+)
+
+// ErrNotFound is returned by Backend if there is no value of the key.
+var ErrNotFound = errors.New("not found")
+
+// newStore creates a shared store if config has Backend and a local LRU cache otherwise.
+func newStore(name string, config *Config) store {
+	if config.Backend != nil && !config.Disabled {
+		return &sharedCache{
+			name:     name,
+			backend:  config.Backend,
+			lifetime: config.Lifetime,
+			logger:   config.Logger,
+		}
+	}
+
+	return newStatCache(name, config)
+}
+
+// Get returns the decoded value of the key and counts a hit or a miss.
+func (c *sharedCache) Get(key interface{}) (interface{}, error) {
+	raw, err := c.backend.Get(c.key(key))
+	if err != nil {
+		atomic.AddUint64(&c.misses, 1)
+		if !errors.Is(err, ErrNotFound) {
+			c.logger.Warn("couldn't get entry from shared cache", zap.String("cache", c.name), zap.Error(err))
+		}
+		return nil, gcache.KeyNotFoundError
+	}
+
+	val, err := decodeSharedEntry(raw)
+	if err != nil {
+		atomic.AddUint64(&c.misses, 1)
+		c.logger.Warn("invalid shared cache entry", zap.String("cache", c.name), zap.Error(err))
+		return nil, gcache.KeyNotFoundError
+	}
+
+	atomic.AddUint64(&c.hits, 1)
+	return val, nil
+}
+
+// Set encodes the value and puts it to the backend.
+func (c *sharedCache) Set(key, value interface{}) error {
+	raw, err := encodeSharedEntry(value)
+	if err != nil {
+		return err
+	}
+
+	return c.backend.Set(c.key(key), raw, c.lifetime)
+}
+
+// Remove deletes the key from the backend. It returns true if the request succeeded.
+func (c *sharedCache) Remove(key interface{}) bool {
+	return c.removeKeys(c.key(key))
+}
+
+func (c *sharedCache) removeKeys(keys ...string) bool {
+	if err := c.backend.Delete(keys...); err != nil {
+		c.logger.Warn("couldn't delete entries from shared cache", zap.String("cache", c.name), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// removeMatching removes entries with keys matching the filter.
+func (c *sharedCache) removeMatching(match func(key string) bool) int {
+	prefix := c.name + ":"
+	keys, err := c.backend.Keys(prefix)
+	if err != nil {
+		c.logger.Warn("couldn't list keys of shared cache", zap.String("cache", c.name), zap.Error(err))
+		return 0
+	}
+
+	matched := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if match(strings.TrimPrefix(key, prefix)) {
+			matched = append(matched, key)
+		}
+	}
+
+	if len(matched) == 0 || !c.removeKeys(matched...) {
+		return 0
+	}
+	return len(matched)
+}
+
+// statistic returns hits and misses, the number of entries and evictions are managed by the backend.
+func (c *sharedCache) statistic() Statistic {
+	return Statistic{
+		Name:   c.name,
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+	}
+}
+
+func (c *sharedCache) key(key interface{}) string {
+	return c.name + ":" + keyString(key)
+}
+
+func encodeSharedEntry(value interface{}) ([]byte, error) {
+	var (
+		entry sharedEntry
+		v     interface{} = value
+	)
+
+	switch val := value.(type) {
+	case oid.Address:
+		entry.Type = sharedTypeAddress
+	case []*data.NodeVersion:
+		entry.Type = sharedTypeVersions
+		versions := make([]sharedNodeVersion, len(val))
+		for i, version := range val {
+			versions[i] = newSharedNodeVersion(version)
+		}
+		v = versions
+	case map[string]string:
+		entry.Type = sharedTypeTagging
+	case *data.LockInfo:
+		entry.Type = sharedTypeLock
+		v = newSharedLockInfo(val)
+	case *data.BucketSettings:
+		entry.Type = sharedTypeSettings
+	case *data.CORSConfiguration:
+		entry.Type = sharedTypeCORS
+	case *data.NotificationConfiguration:
+		entry.Type = sharedTypeNotification
+	default:
+		return nil, fmt.Errorf("unsupported type of shared cache entry: %T", value)
+	}
+
+	var err error
+	if entry.Value, err = json.Marshal(v); err != nil {
+		return nil, fmt.Errorf("encode shared cache entry: %w", err)
+	}
+
+	return json.Marshal(entry)
+}
+
+func decodeSharedEntry(raw []byte) (interface{}, error) {
+	var entry sharedEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, fmt.Errorf("decode shared cache entry: %w", err)
+	}
+
+	var err error
+	switch entry.Type {
+	case sharedTypeAddress:
+		var addr oid.Address
+		err = json.Unmarshal(entry.Value, &addr)
+		return addr, err
+	case sharedTypeVersions:
+		var versions []sharedNodeVersion
+		if err = json.Unmarshal(entry.Value, &versions); err != nil {
+			return nil, err
+		}
+		res := make([]*data.NodeVersion, len(versions))
+		for i := range versions {
+			if res[i], err = versions[i].nodeVersion(); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case sharedTypeTagging:
+		var tagSet map[string]string
+		err = json.Unmarshal(entry.Value, &tagSet)
+		return tagSet, err
+	case sharedTypeLock:
+		var lock sharedLockInfo
+		if err = json.Unmarshal(entry.Value, &lock); err != nil {
+			return nil, err
+		}
+		return lock.lockInfo(), nil
+	case sharedTypeSettings:
+		settings := new(data.BucketSettings)
+		err = json.Unmarshal(entry.Value, settings)
+		return settings, err
+	case sharedTypeCORS:
+		cors := new(data.CORSConfiguration)
+		err = json.Unmarshal(entry.Value, cors)
+		return cors, err
+	case sharedTypeNotification:
+		notification := new(data.NotificationConfiguration)
+		err = json.Unmarshal(entry.Value, notification)
+		return notification, err
+	default:
+		return nil, fmt.Errorf("unknown type of shared cache entry '%s'", entry.Type)
+	}
+}
+
+func newSharedNodeVersion(v *data.NodeVersion) sharedNodeVersion {
+	res := sharedNodeVersion{
+		ID:            v.ID,
+		ParentID:      v.ParenID,
+		OID:           v.OID,
+		Timestamp:     v.Timestamp,
+		Size:          v.Size,
+		ETag:          v.ETag,
+		FilePath:      v.FilePath,
+		IsUnversioned: v.IsUnversioned,
+	}
+
+	if v.DeleteMarker != nil {
+		res.DeleteMarker = &sharedDeleteMarker{
+			Created: v.DeleteMarker.Created,
+			Owner:   v.DeleteMarker.Owner.EncodeToString(),
+		}
+	}
+
+	if v.ObjectMeta != nil {
+		res.ObjectMeta = &sharedObjectMeta{
+			Created:      v.ObjectMeta.Created,
+			Owner:        v.ObjectMeta.Owner.EncodeToString(),
+			StorageClass: v.ObjectMeta.StorageClass,
+		}
+	}
+
+	return res
+}
+
+func (v sharedNodeVersion) nodeVersion() (*data.NodeVersion, error) {
+	res := &data.NodeVersion{
+		BaseNodeVersion: data.BaseNodeVersion{
+			ID:        v.ID,
+			ParenID:   v.ParentID,
+			OID:       v.OID,
+			Timestamp: v.Timestamp,
+			Size:      v.Size,
+			ETag:      v.ETag,
+			FilePath:  v.FilePath,
+		},
+		IsUnversioned: v.IsUnversioned,
+	}
+
+	if v.DeleteMarker != nil {
+		var owner user.ID
+		if err := owner.DecodeString(v.DeleteMarker.Owner); err != nil {
+			return nil, fmt.Errorf("decode owner of delete marker: %w", err)
+		}
+		res.DeleteMarker = &data.DeleteMarkerInfo{
+			Created: v.DeleteMarker.Created,
+			Owner:   owner,
+		}
+	}
+
+	if v.ObjectMeta != nil {
+		var owner user.ID
+		if err := owner.DecodeString(v.ObjectMeta.Owner); err != nil {
+			return nil, fmt.Errorf("decode owner of object: %w", err)
+		}
+		res.ObjectMeta = &data.ObjectMeta{
+			Created:      v.ObjectMeta.Created,
+			Owner:        owner,
+			StorageClass: v.ObjectMeta.StorageClass,
+		}
+	}
+
+	return res, nil
+}
+
+func newSharedLockInfo(l *data.LockInfo) sharedLockInfo {
+	res := sharedLockInfo{ID: l.ID()}
+	if l.IsLegalHoldSet() {
+		legalHold := l.LegalHold()
+		res.LegalHold = &legalHold
+	}
+	if l.IsRetentionSet() {
+		retention := l.Retention()
+		res.Retention = &retention
+		res.UntilDate = l.UntilDate()
+		res.IsCompliance = l.IsCompliance()
+	}
+	return res
+}
+
+func (l sharedLockInfo) lockInfo() *data.LockInfo {
+	res := data.NewLockInfo(l.ID)
+	if l.LegalHold != nil {
+		res.SetLegalHold(*l.LegalHold)
+	}
+	if l.Retention != nil {
+		res.SetRetention(*l.Retention, l.UntilDate, l.IsCompliance)
+	}
+	return res
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type memoryBackend struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{entries: make(map[string][]byte)}
+}
+
+func (m *memoryBackend) Get(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	val, ok := m.entries[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return val, nil
+}
+
+func (m *memoryBackend) Set(key string, value []byte, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = value
+	return nil
+}
+
+func (m *memoryBackend) Delete(keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+func (m *memoryBackend) Keys(prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var keys []string
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func getSharedTestConfig(backend Backend) *Config {
+	return &Config{
+		Size:     testingCacheSize,
+		Lifetime: testingCacheLifetime,
+		Logger:   zap.NewExample(),
+		Backend:  backend,
+	}
+}
+
+func TestSharedCache(t *testing.T) {
+	backend := newMemoryBackend()
+
+	t.Run("names are seen by other instance", func(t *testing.T) {
+		first, second := NewObjectsNameCache(getSharedTestConfig(backend)), NewObjectsNameCache(getSharedTestConfig(backend))
+
+		addr := oidtest.Address()
+		require.NoError(t, first.Put("bucket/name", addr))
+		require.Equal(t, &addr, second.Get("bucket/name"))
+
+		second.Delete("bucket/name")
+		require.Nil(t, first.Get("bucket/name"))
+
+		stat := first.Statistic()
+		require.EqualValues(t, 0, stat.Hits)
+		require.EqualValues(t, 1, stat.Misses)
+	})
+
+	t.Run("system entries", func(t *testing.T) {
+		first, second := NewSystemCache(getSharedTestConfig(backend)), NewSystemCache(getSharedTestConfig(backend))
+
+		settings := &data.BucketSettings{Versioning: data.VersioningEnabled}
+		require.NoError(t, first.PutSettings("settings", settings))
+		require.Equal(t, settings, second.GetSettings("settings"))
+
+		tags := map[string]string{"key": "value"}
+		require.NoError(t, first.PutTagging("tagging", tags))
+		require.Equal(t, tags, second.GetTagging("tagging"))
+
+		lock := data.NewLockInfo(1)
+		lock.SetLegalHold(oidtest.ID())
+		lock.SetRetention(oidtest.ID(), "2030-01-01T00:00:00Z", true)
+		require.NoError(t, first.PutLockInfo("lock", lock))
+		require.Equal(t, lock, second.GetLockInfo("lock"))
+
+		require.Error(t, first.PutObject("object", &data.ObjectInfo{}))
+	})
+
+	t.Run("listing is invalidated by other instance", func(t *testing.T) {
+		first, second := NewObjectsListCache(getSharedTestConfig(backend)), NewObjectsListCache(getSharedTestConfig(backend))
+
+		cnrID := cidtest.ID()
+		versions := []*data.NodeVersion{
+			{
+				BaseNodeVersion: data.BaseNodeVersion{ID: 1, OID: oidtest.ID(), FilePath: "dir/obj", ETag: "etag"},
+				ObjectMeta:      &data.ObjectMeta{Created: time.Unix(1, 0).UTC(), Owner: *usertest.ID(), StorageClass: "STANDARD"},
+			},
+			{
+				BaseNodeVersion: data.BaseNodeVersion{ID: 2, OID: oidtest.ID(), FilePath: "dir/obj"},
+				DeleteMarker:    &data.DeleteMarkerInfo{Created: time.Unix(1, 0).UTC(), Owner: *usertest.ID()},
+				IsUnversioned:   true,
+			},
+		}
+
+		prefixKey, otherKey := CreateObjectsListCacheKey(cnrID, "dir/", "", true), CreateObjectsListCacheKey(cnrID, "other/", "", true)
+		require.NoError(t, first.PutVersions(prefixKey, versions))
+		require.NoError(t, first.PutVersions(otherKey, versions))
+		require.Equal(t, versions, second.GetVersions(prefixKey))
+
+		second.CleanCacheEntriesContainingObject("dir/new", cnrID)
+		require.Nil(t, first.GetVersions(prefixKey))
+		require.Equal(t, versions, first.GetVersions(otherKey))
+
+		require.Equal(t, 1, second.DeleteMatching(func(key string) bool { return strings.HasSuffix(key, ":other/") }))
+		require.Nil(t, first.GetVersions(otherKey))
+	})
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluele/gcache"
+)
+
+type (
+	// Statistic contains usage counters of a cache.
+	Statistic struct {
+		Name string `json:"name"`
+		// Disabled is true if the cache doesn't store entries.
+		Disabled bool `json:"disabled"`
+		// Entries is the current number of entries including expired ones.
+		Entries int `json:"entries"`
+		// Hits and Misses are the numbers of lookups found and not found in the cache.
+		Hits   uint64 `json:"hits"`
+		Misses uint64 `json:"misses"`
+		// Evictions is the number of entries removed due to size limit or expiration.
+		Evictions uint64 `json:"evictions"`
+	}
+
+	// statCache is an LRU cache counting hits, misses and evictions.
+	// Disabled statCache doesn't store entries, so every lookup is a miss.
+	statCache struct {
+		gcache.Cache
+
+		name     string
+		disabled bool
+
+		hits    uint64
+		misses  uint64
+		removed uint64 // entries removed due to eviction, expiration or Remove
+		deleted uint64 // entries removed by Remove
+	}
+)
+
+// newStatCache builds an LRU cache with the size and lifetime of entries from config.
+func newStatCache(name string, config *Config) *statCache {
+	c := &statCache{name: name, disabled: config.Disabled}
+
+	size := config.Size
+	if c.disabled {
+		size = 1
+	}
+
+	c.Cache = gcache.New(size).LRU().Expiration(config.Lifetime).
+		EvictedFunc(func(interface{}, interface{}) { atomic.AddUint64(&c.removed, 1) }).
+		Build()
+
+	return c
+}
+
+// Get returns the value of the key and counts a hit or a miss.
+func (c *statCache) Get(key interface{}) (interface{}, error) {
+	if c.disabled {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, gcache.KeyNotFoundError
+	}
+
+	val, err := c.Cache.Get(key)
+	if err != nil {
+		atomic.AddUint64(&c.misses, 1)
+	} else {
+		atomic.AddUint64(&c.hits, 1)
+	}
+
+	return val, err
+}
+
+// Set puts the value unless the cache is disabled.
+func (c *statCache) Set(key, value interface{}) error {
+	if c.disabled {
+		return nil
+	}
+	return c.Cache.Set(key, value)
+}
+
+// SetWithExpire puts the value with the lifetime unless the cache is disabled.
+func (c *statCache) SetWithExpire(key, value interface{}, expiration time.Duration) error {
+	if c.disabled {
+		return nil
+	}
+	return c.Cache.SetWithExpire(key, value, expiration)
+}
+
+// Remove removes the key, it isn't counted as eviction.
+func (c *statCache) Remove(key interface{}) bool {
+	ok := c.Cache.Remove(key)
+	if ok {
+		atomic.AddUint64(&c.deleted, 1)
+	}
+	return ok
+}
+
+// removeMatching removes entries with keys matching the filter, see keyString.
+// It returns the number of removed entries.
+func (c *statCache) removeMatching(match func(key string) bool) int {
+	var n int
+	for _, key := range c.Cache.Keys(false) {
+		if match(keyString(key)) && c.Remove(key) {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *statCache) statistic() Statistic {
+	deleted := atomic.LoadUint64(&c.deleted)
+	removed := atomic.LoadUint64(&c.removed)
+
+	var evictions uint64
+	if removed > deleted {
+		evictions = removed - deleted
+	}
+
+	return Statistic{
+		Name:      c.name,
+		Disabled:  c.disabled,
+		Entries:   c.Cache.Len(false),
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: evictions,
+	}
+}
+
+// keyString returns the string form of the cache key: strings are kept as is,
+// objects addresses are <cid>/<oid> and keys of lists are formed by ObjectsListKey.String.
+func keyString(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case ObjectsListKey:
+		return k.String()
+	default:
+		return fmt.Sprint(k)
+	}
+}
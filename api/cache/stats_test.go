@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStatCache(t *testing.T) {
+	c := newStatCache("test", &Config{Size: 2, Lifetime: time.Minute, Logger: zap.NewNop()})
+
+	require.NoError(t, c.Set("a", 1))
+	require.NoError(t, c.Set("b", 2))
+
+	_, err := c.Get("a")
+	require.NoError(t, err)
+	_, err = c.Get("c")
+	require.Error(t, err)
+
+	require.NoError(t, c.Set("c", 3)) // evicts "b"
+	require.True(t, c.Remove("a"))    // isn't an eviction
+
+	require.Equal(t, Statistic{
+		Name:      "test",
+		Entries:   1,
+		Hits:      1,
+		Misses:    1,
+		Evictions: 1,
+	}, c.statistic())
+}
+
+func TestDisabledStatCache(t *testing.T) {
+	c := newStatCache("test", &Config{Size: 10, Lifetime: time.Minute, Logger: zap.NewNop(), Disabled: true})
+
+	require.NoError(t, c.Set("a", 1))
+	_, err := c.Get("a")
+	require.Error(t, err)
+
+	require.Equal(t, Statistic{
+		Name:     "test",
+		Disabled: true,
+		Misses:   1,
+	}, c.statistic())
+}
+
+func TestStatCacheRemoveMatching(t *testing.T) {
+	c := newStatCache("test", &Config{Size: 10, Lifetime: time.Minute, Logger: zap.NewNop()})
+
+	require.NoError(t, c.Set("bucket/a", 1))
+	require.NoError(t, c.Set("bucket/b", 2))
+	require.NoError(t, c.Set("other/a", 3))
+
+	require.Equal(t, 2, c.removeMatching(func(key string) bool { return strings.HasPrefix(key, "bucket/") }))
+
+	_, err := c.Get("other/a")
+	require.NoError(t, err)
+	_, err = c.Get("bucket/a")
+	require.Error(t, err)
+}
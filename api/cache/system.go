@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/bluele/gcache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"go.uber.org/zap"
 )
@@ -13,7 +12,7 @@ import (
 // This cache contains "system" objects (bucket versioning settings, tagging object etc.).
 // Key is bucketName+systemFilePath.
 type SystemCache struct {
-	cache  gcache.Cache
+	cache  store
 	logger *zap.Logger
 }
 
@@ -35,7 +34,7 @@ func DefaultSystemConfig(logger *zap.Logger) *Config {
 
 // NewSystemCache creates an object of SystemCache.
 func NewSystemCache(config *Config) *SystemCache {
-	gc := gcache.New(config.Size).LRU().Expiration(config.Lifetime).Build()
+	gc := newStore("system", config)
 	return &SystemCache{cache: gc, logger: config.Logger}
 }
 
@@ -165,3 +164,14 @@ func (o *SystemCache) PutTagging(key string, tagSet map[string]string) error {
 func (o *SystemCache) Delete(key string) bool {
 	return o.cache.Remove(key)
 }
+
+// DeleteMatching deletes entries with keys matching the filter and returns their number.
+// Keys are the ones entries are put with.
+func (o *SystemCache) DeleteMatching(match func(key string) bool) int {
+	return o.cache.removeMatching(match)
+}
+
+// Statistic returns usage counters of the cache.
+func (o *SystemCache) Statistic() Statistic {
+	return o.cache.statistic()
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// CustomDomain is a bucket and an optional prefix of object names served under a custom hostname.
+type CustomDomain struct {
+	Host   string
+	Bucket string
+	Prefix string
+}
+
+// CustomDomains is a set of custom hostnames mapped to buckets which can be updated at runtime.
+type CustomDomains struct {
+	mu      sync.RWMutex
+	domains map[string]CustomDomain
+}
+
+// NewCustomDomains creates a set of custom hostnames mapped to buckets.
+func NewCustomDomains(domains []CustomDomain) *CustomDomains {
+	c := &CustomDomains{}
+	c.Update(domains)
+	return c
+}
+
+// Update replaces custom hostnames.
+func (c *CustomDomains) Update(domains []CustomDomain) {
+	m := make(map[string]CustomDomain, len(domains))
+	for _, domain := range domains {
+		m[strings.ToLower(domain.Host)] = domain
+	}
+
+	c.mu.Lock()
+	c.domains = m
+	c.mu.Unlock()
+}
+
+// Get returns the bucket mapped to the host. Port of the host is ignored.
+func (c *CustomDomains) Get(host string) (CustomDomain, bool) {
+	if c == nil {
+		return CustomDomain{}, false
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	domain, ok := c.domains[strings.ToLower(host)]
+	return domain, ok
+}
+
+// Hosts returns all custom hostnames.
+func (c *CustomDomains) Hosts() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	hosts := make([]string, 0, len(c.domains))
+	for host := range c.domains {
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// ResolveCustomDomains rewrites requests sent to custom hostnames to path-style
+// requests to the mapped buckets, so they are routed by h as regular ones.
+func ResolveCustomDomains(h http.Handler, domains *CustomDomains) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if domain, ok := domains.Get(r.Host); ok {
+			prefix := "/" + domain.Bucket + "/"
+			escapedPrefix := prefix + (&url.URL{Path: domain.Prefix}).EscapedPath()
+			escapedPath := escapedPrefix + strings.TrimPrefix(r.URL.EscapedPath(), SlashSeparator)
+
+			r.URL.Path = prefix + domain.Prefix + strings.TrimPrefix(r.URL.Path, SlashSeparator)
+			r.URL.RawPath = ""
+			if escapedPath != r.URL.Path {
+				r.URL.RawPath = escapedPath
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
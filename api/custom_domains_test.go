@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCustomDomains(t *testing.T) {
+	domains := NewCustomDomains([]CustomDomain{
+		{Host: "www.example.com", Bucket: "site", Prefix: "public/"},
+		{Host: "cdn.example.com", Bucket: "assets"},
+	})
+
+	var path, escapedPath string
+	h := ResolveCustomDomains(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		path, escapedPath = r.URL.Path, r.URL.EscapedPath()
+	}), domains)
+
+	for _, tc := range []struct {
+		host, target, path, escapedPath string
+	}{
+		{host: "www.example.com", target: "/index.html", path: "/site/public/index.html", escapedPath: "/site/public/index.html"},
+		{host: "WWW.example.com:8080", target: "/a%20b", path: "/site/public/a b", escapedPath: "/site/public/a%20b"},
+		{host: "cdn.example.com", target: "/", path: "/assets/", escapedPath: "/assets/"},
+		{host: "s3.example.com", target: "/bucket/obj", path: "/bucket/obj", escapedPath: "/bucket/obj"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+		r.Host = tc.host
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		require.Equal(t, tc.path, path, tc.host)
+		require.Equal(t, tc.escapedPath, escapedPath, tc.host)
+	}
+
+	domains.Update(nil)
+	_, ok := domains.Get("www.example.com")
+	require.False(t, ok)
+}
@@ -17,6 +17,9 @@ const (
 	VersioningUnversioned = "Unversioned"
 	VersioningEnabled     = "Enabled"
 	VersioningSuspended   = "Suspended"
+
+	MFADeleteEnabled  = "Enabled"
+	MFADeleteDisabled = "Disabled"
 )
 
 type (
@@ -28,6 +31,15 @@ type (
 		Created            time.Time
 		LocationConstraint string
 		ObjectLockEnabled  bool
+		Quota              BucketQuota
+		// Attributes are custom attributes of the container set on bucket creation.
+		Attributes map[string]string
+	}
+
+	// BucketQuota holds limits of the bucket usage. Zero value means no limit.
+	BucketQuota struct {
+		Size    uint64
+		Objects uint64
 	}
 
 	// ObjectInfo holds S3 object data.
@@ -59,6 +71,9 @@ type (
 	BucketSettings struct {
 		Versioning        string                   `json:"versioning"`
 		LockConfiguration *ObjectLockConfiguration `json:"lock_configuration"`
+		MFADelete         bool                     `json:"mfa_delete"`
+		// MFADeleteConfigured is set if MFA delete was ever configured, so its disabled state is reported.
+		MFADeleteConfigured bool `json:"mfa_delete_configured"`
 	}
 
 	// CORSConfiguration stores CORS configuration of a request.
@@ -74,7 +89,8 @@ type (
 		AllowedMethods []string `xml:"AllowedMethod" json:"AllowedMethods"`
 		AllowedOrigins []string `xml:"AllowedOrigin" json:"AllowedOrigins"`
 		ExposeHeaders  []string `xml:"ExposeHeader" json:"ExposeHeaders"`
-		MaxAgeSeconds  int      `xml:"MaxAgeSeconds,omitempty" json:"MaxAgeSeconds,omitempty"`
+		// MaxAgeSeconds is nil if it isn't set in the rule, so zero can be returned to disable caching.
+		MaxAgeSeconds *int `xml:"MaxAgeSeconds,omitempty" json:"MaxAgeSeconds,omitempty"`
 	}
 )
 
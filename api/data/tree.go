@@ -2,6 +2,7 @@ package data
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
@@ -13,17 +14,37 @@ const (
 	UnversionedObjectVersionID = "null"
 )
 
+// BucketConfigType is a type of bucket configuration kept as an object
+// which ID is stored in the system tree.
+type BucketConfigType string
+
+// Types of bucket configurations.
+const (
+	BucketConfigLifecycle  BucketConfigType = "lifecycle"
+	BucketConfigWebsite    BucketConfigType = "website"
+	BucketConfigPolicy     BucketConfigType = "policy"
+	BucketConfigEncryption BucketConfigType = "encryption"
+)
+
 // NodeVersion represent node from tree service.
 type NodeVersion struct {
 	BaseNodeVersion
 	DeleteMarker  *DeleteMarkerInfo
 	IsUnversioned bool
+	// ObjectMeta is nil for nodes created before the meta was stored in the tree service.
+	ObjectMeta *ObjectMeta
 }
 
 func (v NodeVersion) IsDeleteMarker() bool {
 	return v.DeleteMarker != nil
 }
 
+// IsDirectoryMarker reports whether the version is a zero-byte object with the name ending with '/'.
+// Such objects are created by the AWS console and GUI clients to represent empty directories.
+func (v NodeVersion) IsDirectoryMarker() bool {
+	return !v.IsDeleteMarker() && v.Size == 0 && strings.HasSuffix(v.FilePath, "/")
+}
+
 // DeleteMarkerInfo is used to save object info if node in the tree service is delete marker.
 // We need this information because the "delete marker" object is no longer stored in NeoFS.
 type DeleteMarkerInfo struct {
@@ -31,6 +52,13 @@ type DeleteMarkerInfo struct {
 	Owner   user.ID
 }
 
+// ObjectMeta is object info stored in the tree service to list objects without heading them in NeoFS.
+type ObjectMeta struct {
+	Created      time.Time
+	Owner        user.ID
+	StorageClass string
+}
+
 // ExtendedObjectInfo contains additional node info to be able to sort versions by timestamp.
 type ExtendedObjectInfo struct {
 	ObjectInfo  *ObjectInfo
@@ -53,9 +81,25 @@ type BaseNodeVersion struct {
 	ParenID   uint64
 	OID       oid.ID
 	Timestamp uint64
-	Size      int64
-	ETag      string
-	FilePath  string
+	// MonotonicTime is the time of the version creation in nanoseconds, it strictly increases
+	// within the gateway which added the version. It's zero for versions added by old gateways.
+	MonotonicTime uint64
+	Size          int64
+	ETag          string
+	FilePath      string
+}
+
+// IsNewerThan reports whether the version was added after the other one. Versions are ordered by
+// the timestamp of the tree service. Several versions can get the same timestamp, so they are
+// ordered by the monotonic time and then by the node id to choose the same latest version everywhere.
+func (v BaseNodeVersion) IsNewerThan(other BaseNodeVersion) bool {
+	if v.Timestamp != other.Timestamp {
+		return v.Timestamp > other.Timestamp
+	}
+	if v.MonotonicTime != other.MonotonicTime {
+		return v.MonotonicTime > other.MonotonicTime
+	}
+	return v.ID > other.ID
 }
 
 type ObjectTaggingInfo struct {
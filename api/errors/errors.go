@@ -91,6 +91,13 @@ const (
 	ErrMissingCredTag
 	ErrCredMalformed
 	ErrInvalidRegion
+	ErrInvalidLocationConstraint
+	ErrQuotaExceeded
+	ErrNoSuchJob
+	ErrJobStatus
+	ErrInvalidIdentityToken
+	ErrExpiredIdentityToken
+	ErrExpiredToken
 	ErrInvalidServiceS3
 	ErrInvalidServiceSTS
 	ErrInvalidRequestVersion
@@ -116,6 +123,7 @@ const (
 	ErrUnsupportedMetadata
 	ErrMaximumExpires
 	ErrSlowDown
+	ErrServiceUnavailable
 	ErrInvalidPrefixMarker
 	ErrBadRequest
 	ErrKeyTooLongError
@@ -166,6 +174,7 @@ const (
 	ErrFilterNameSuffix
 	ErrFilterValueInvalid
 	ErrOverlappingConfigs
+	ErrNotificationDestinationValidation
 	ErrNotificationTopicNotSupported
 
 	// S3 extended errors.
@@ -273,6 +282,8 @@ const (
 	//CORS configuration errors.
 	ErrCORSUnsupportedMethod
 	ErrCORSWildcardExposeHeaders
+	ErrCORSWildcardOrigin
+	ErrCORSWildcardAllowedHeader
 )
 
 // error code to Error structure, these fields carry respective
@@ -686,6 +697,48 @@ var errorCodes = errorCodeMap{
 		Description:    "Region does not match.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrInvalidLocationConstraint: {
+		ErrCode:        ErrInvalidLocationConstraint,
+		Code:           "InvalidLocationConstraint",
+		Description:    "The specified location constraint is not valid.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrQuotaExceeded: {
+		ErrCode:        ErrQuotaExceeded,
+		Code:           "QuotaExceeded",
+		Description:    "Bucket quota exceeded.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrNoSuchJob: {
+		ErrCode:        ErrNoSuchJob,
+		Code:           "NotFoundException",
+		Description:    "The specified job does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrJobStatus: {
+		ErrCode:        ErrJobStatus,
+		Code:           "JobStatusException",
+		Description:    "The requested job status transition is not allowed.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrInvalidIdentityToken: {
+		ErrCode:        ErrInvalidIdentityToken,
+		Code:           "InvalidIdentityToken",
+		Description:    "The web identity token that was passed could not be validated.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrExpiredIdentityToken: {
+		ErrCode:        ErrExpiredIdentityToken,
+		Code:           "ExpiredTokenException",
+		Description:    "The web identity token that was passed is expired.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrExpiredToken: {
+		ErrCode:        ErrExpiredToken,
+		Code:           "ExpiredToken",
+		Description:    "The provided token has expired.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrInvalidServiceS3: {
 		ErrCode:        ErrInvalidServiceS3,
 		Code:           "AuthorizationParametersError",
@@ -764,6 +817,12 @@ var errorCodes = errorCodeMap{
 		Description:    "Please reduce your request",
 		HTTPStatusCode: http.StatusServiceUnavailable,
 	},
+	ErrServiceUnavailable: {
+		ErrCode:        ErrServiceUnavailable,
+		Code:           "ServiceUnavailable",
+		Description:    "Service is unable to handle request",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
 	ErrInvalidPrefixMarker: {
 		ErrCode:        ErrInvalidPrefixMarker,
 		Code:           "InvalidPrefixMarker",
@@ -959,6 +1018,12 @@ var errorCodes = errorCodeMap{
 		Description:    "Configurations overlap. Configurations on the same bucket cannot share a common event type.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrNotificationDestinationValidation: {
+		ErrCode:        ErrNotificationDestinationValidation,
+		Code:           "InvalidArgument",
+		Description:    "Unable to validate the following destination configurations",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrInvalidCopyPartRange: {
 		ErrCode:        ErrInvalidCopyPartRange,
 		Code:           "InvalidArgument",
@@ -973,7 +1038,7 @@ var errorCodes = errorCodeMap{
 	},
 	ErrMetadataTooLarge: {
 		ErrCode:        ErrMetadataTooLarge,
-		Code:           "InvalidArgument",
+		Code:           "MetadataTooLarge",
 		Description:    "Your metadata headers exceed the maximum allowed metadata size.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
@@ -1675,6 +1740,18 @@ var errorCodes = errorCodeMap{
 		Description:    "ExposeHeader \"*\" contains wildcard. We currently do not support wildcard for ExposeHeader",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrCORSWildcardOrigin: {
+		ErrCode:        ErrCORSWildcardOrigin,
+		Code:           "InvalidRequest",
+		Description:    "AllowedOrigin can not have more than one wildcard",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrCORSWildcardAllowedHeader: {
+		ErrCode:        ErrCORSWildcardAllowedHeader,
+		Code:           "InvalidRequest",
+		Description:    "AllowedHeader can not have more than one wildcard",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrInvalidPartNumber: {
 		ErrCode:        ErrInvalidPartNumber,
 		Code:           "InvalidArgument",
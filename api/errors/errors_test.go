@@ -3,6 +3,8 @@ package errors
 import (
 	"errors"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func BenchmarkErrCode(b *testing.B) {
@@ -24,3 +26,12 @@ func BenchmarkErrorsIs(b *testing.B) {
 		}
 	}
 }
+
+func TestErrorCodes(t *testing.T) {
+	for code, e := range errorCodes {
+		require.Equal(t, code, e.ErrCode)
+		require.NotEmpty(t, e.Code, "code %d", code)
+		require.NotEmpty(t, e.Description, e.Code)
+		require.NotZero(t, e.HTTPStatusCode, e.Code)
+	}
+}
@@ -235,15 +235,15 @@ func (h *handler) PutBucketACLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, err = h.updateBucketACL(r, astBucket, bktInfo, token); err != nil {
+	if _, err = h.updateBucketACL(r.Context(), astBucket, bktInfo, token); err != nil {
 		h.logAndSendError(w, "could not update bucket acl", reqInfo, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h *handler) updateBucketACL(r *http.Request, astChild *ast, bktInfo *data.BucketInfo, sessionToken *session.Container) (bool, error) {
-	bucketACL, err := h.obj.GetBucketACL(r.Context(), bktInfo)
+func (h *handler) updateBucketACL(ctx context.Context, astChild *ast, bktInfo *data.BucketInfo, sessionToken *session.Container) (bool, error) {
+	bucketACL, err := h.obj.GetBucketACL(ctx, bktInfo)
 	if err != nil {
 		return false, fmt.Errorf("could not get bucket eacl: %w", err)
 	}
@@ -273,7 +273,7 @@ func (h *handler) updateBucketACL(r *http.Request, astChild *ast, bktInfo *data.
 		SessionToken: sessionToken,
 	}
 
-	if err = h.obj.PutBucketACL(r.Context(), p); err != nil {
+	if err = h.obj.PutBucketACL(ctx, p); err != nil {
 		return false, fmt.Errorf("could not put bucket acl: %w", err)
 	}
 
@@ -369,7 +369,7 @@ func (h *handler) PutObjectACLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updated, err := h.updateBucketACL(r, astObject, bktInfo, token)
+	updated, err := h.updateBucketACL(r.Context(), astObject, bktInfo, token)
 	if err != nil {
 		h.logAndSendError(w, "could not update bucket acl", reqInfo, err)
 		return
@@ -453,7 +453,7 @@ func (h *handler) PutBucketPolicyHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if _, err = h.updateBucketACL(r, astPolicy, bktInfo, token); err != nil {
+	if _, err = h.updateBucketACL(r.Context(), astPolicy, bktInfo, token); err != nil {
 		h.logAndSendError(w, "could not update bucket acl", reqInfo, err)
 		return
 	}
@@ -16,6 +16,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
 	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/eacl"
@@ -1310,6 +1311,20 @@ func TestPutBucketACL(t *testing.T) {
 	checkLastRecords(t, tc, bktInfo, eacl.ActionDeny)
 }
 
+func TestPutBucketACLAnonymous(t *testing.T) {
+	tc := prepareHandlerContext(t)
+	bktName := "bucket-for-anonymous-acl"
+
+	box, _ := createAccessBox(t)
+	createBucket(t, tc, bktName, box)
+
+	w, r := prepareTestRequest(tc, bktName, "", nil)
+	r.Header.Set(api.AmzACL, "public-read")
+	r = r.WithContext(api.SetReqInfo(context.Background(), api.GetReqInfo(r.Context())))
+	tc.Handler().PutBucketACLHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrAccessDenied))
+}
+
 func TestBucketPolicy(t *testing.T) {
 	hc := prepareHandlerContext(t)
 	bktName := "bucket-for-policy"
@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"io"
 	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	"github.com/nspcc-dev/neofs-sdk-go/netmap"
 	"go.uber.org/zap"
@@ -16,6 +20,7 @@ type (
 		obj         layer.Client
 		notificator Notificator
 		cfg         *Config
+		jobs        *batchJobs
 	}
 
 	Notificator interface {
@@ -29,6 +34,55 @@ type (
 		DefaultMaxAge      int
 		NotificatorEnabled bool
 		CopiesNumber       uint32
+		// StorageClasses maps storage classes to the number of object copies.
+		StorageClasses map[string]uint32
+		// CompleteMultipartKeepalive is an interval of writing whitespaces
+		// to the response while multipart upload is being completed.
+		CompleteMultipartKeepalive time.Duration
+		// MFA validates codes of MFA devices. MFA Delete can't be used if it's nil.
+		MFA MFAValidator
+		// Transforms are applied to payload of objects returned by GetObject.
+		// The first rule matching the object is used. Operations reading the
+		// original payload of such objects are rejected.
+		Transforms []TransformRule
+		// WebIdentity issues temporary credentials for OIDC tokens. STS requests are rejected if it's nil.
+		WebIdentity auth.WebIdentity
+		// Usage provides requests and traffic of buckets served by the gateway. Traffic isn't reported if it's nil.
+		Usage *api.UsageAccounting
+		// RelaxedBucketNames allows legacy names of new buckets which aren't DNS-compatible,
+		// e.g. with uppercase letters and underscores.
+		RelaxedBucketNames bool
+		// DefaultPolicyForUnknownLocation creates buckets with unknown location constraint
+		// using the default placement policy instead of rejecting them.
+		DefaultPolicyForUnknownLocation bool
+		// DefaultCORS is applied to buckets without CORS configuration. Such buckets reject
+		// cross-origin requests if it's nil.
+		DefaultCORS *DefaultCORS
+		// ContainerAttributePrefixes are prefixes of CreateBucket headers which set custom container attributes.
+		ContainerAttributePrefixes []string
+		// ExposedContainerAttributes are container attributes returned in HeadBucket response.
+		ExposedContainerAttributes []string
+		// BatchJobs persists states of S3 Batch Operations jobs. Jobs are kept in memory only if it's nil.
+		BatchJobs BatchJobStorage
+	}
+
+	// ObjectTransformer modifies payload of objects returned by GetObject.
+	ObjectTransformer interface {
+		// Transform reads the original payload from src and returns the transformed one
+		// along with its content type. Empty content type keeps the original one.
+		Transform(ctx context.Context, info *data.ObjectInfo, src io.Reader) (io.ReadCloser, string, error)
+	}
+
+	// TransformRule applies Transformer to objects of the Bucket with names starting with Prefix.
+	TransformRule struct {
+		Bucket      string
+		Prefix      string
+		Transformer ObjectTransformer
+	}
+
+	// MFAValidator checks a one-time code of the MFA device with the given serial number.
+	MFAValidator interface {
+		Validate(ctx context.Context, serialNumber, code string) error
 	}
 
 	PlacementPolicy interface {
@@ -66,5 +120,6 @@ func New(log *zap.Logger, obj layer.Client, notificator Notificator, cfg *Config
 		obj:         obj,
 		cfg:         cfg,
 		notificator: notificator,
+		jobs:        newBatchJobs(cfg.BatchJobs),
 	}, nil
 }
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -75,6 +76,11 @@ func (h *handler) GetObjectAttributesHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if err = h.checkNotTransformed(reqInfo.BucketName, reqInfo.ObjectName); err != nil {
+		h.logAndSendError(w, "attributes of transformed object are unknown", reqInfo, err)
+		return
+	}
+
 	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
 	if err != nil {
 		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
@@ -160,11 +166,8 @@ func parseGetObjectAttributeArgs(r *http.Request) (*GetObjectAttributesArgs, err
 	}
 
 	var err error
-	maxPartsVal := r.Header.Get(api.AmzMaxParts)
-	if maxPartsVal == "" {
-		res.MaxParts = layer.MaxSizePartsList
-	} else if res.MaxParts, err = strconv.Atoi(maxPartsVal); err != nil || res.MaxParts < 0 {
-		return nil, errors.GetAPIError(errors.ErrInvalidMaxKeys)
+	if res.MaxParts, err = parseMaxKeys(r.Header.Get(api.AmzMaxParts), layer.MaxSizePartsList, errors.ErrInvalidMaxParts); err != nil {
+		return nil, err
 	}
 
 	markerVal := r.Header.Get(api.AmzPartNumberMarker)
@@ -186,11 +189,13 @@ func encodeToObjectAttributesResponse(info *data.ObjectInfo, p *GetObjectAttribu
 		case eTag:
 			resp.ETag = info.HashSum
 		case storageClass:
-			resp.StorageClass = "STANDARD"
+			resp.StorageClass = storageClassOrDefault(info.Headers[layer.AttributeStorageClass])
 		case objectSize:
 			resp.ObjectSize = info.Size
 		case checksum:
-			resp.Checksum = &Checksum{ChecksumSHA256: info.HashSum}
+			if sum := sha256Checksum(info.HashSum); sum != "" {
+				resp.Checksum = &Checksum{ChecksumSHA256: sum}
+			}
 		case objectParts:
 			parts, err := formUploadAttributes(info, p.MaxParts, p.PartNumberMarker)
 			if err != nil {
@@ -221,7 +226,7 @@ func formUploadAttributes(info *data.ObjectInfo, maxParts, marker int) (*ObjectP
 		parts[i] = Part{
 			PartNumber:     part.PartNumber,
 			Size:           int(part.Size),
-			ChecksumSHA256: part.ETag,
+			ChecksumSHA256: sha256Checksum(part.ETag),
 		}
 	}
 
@@ -255,3 +260,12 @@ func formUploadAttributes(info *data.ObjectInfo, maxParts, marker int) (*ObjectP
 
 	return res, nil
 }
+
+// sha256Checksum returns ETag if it's SHA256 checksum of the payload,
+// ETag of objects put with MD5 ETags and of multipart objects isn't.
+func sha256Checksum(etag string) string {
+	if len(etag) != 2*sha256.Size {
+		return ""
+	}
+	return etag
+}
@@ -0,0 +1,1409 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
+)
+
+const (
+	batchJobManifestFormatCSV = "S3BatchOperations_CSV_20180820"
+	batchJobReportFormatCSV   = "Report_CSV_20180820"
+
+	batchJobReportScopeAll    = "AllTasks"
+	batchJobReportScopeFailed = "FailedTasksOnly"
+
+	batchJobStatusActive     = "Active"
+	batchJobStatusCancelled  = "Cancelled"
+	batchJobStatusCancelling = "Cancelling"
+	batchJobStatusComplete   = "Complete"
+	batchJobStatusFailed     = "Failed"
+	batchJobStatusNew        = "New"
+	batchJobStatusReady      = "Ready"
+	batchJobStatusSuspended  = "Suspended"
+
+	batchJobOperationCopy          = "S3PutObjectCopy"
+	batchJobOperationTagging       = "S3PutObjectTagging"
+	batchJobOperationDeleteTagging = "S3DeleteObjectTagging"
+	batchJobOperationACL           = "S3PutObjectAcl"
+
+	batchJobTaskSucceeded = "succeeded"
+	batchJobTaskFailed    = "failed"
+
+	batchJobIDVar = "jobID"
+
+	// batchJobSaveInterval is an interval of saving the state of running jobs.
+	batchJobSaveInterval = 30 * time.Second
+	// batchJobInterruptedTimeout is a time after which the saved running job is considered
+	// interrupted, e.g. by restart of the gateway instance which ran it.
+	batchJobInterruptedTimeout = 5 * batchJobSaveInterval
+
+	s3ARNPrefix = "arn:aws:s3:::"
+)
+
+type (
+	// CreateJobRequest is a request of S3 Batch Operations job creation.
+	CreateJobRequest struct {
+		XMLName              xml.Name          `xml:"CreateJobRequest"`
+		ClientRequestToken   string            `xml:"ClientRequestToken"`
+		ConfirmationRequired bool              `xml:"ConfirmationRequired"`
+		Description          string            `xml:"Description"`
+		Manifest             BatchJobManifest  `xml:"Manifest"`
+		Operation            BatchJobOperation `xml:"Operation"`
+		Priority             int               `xml:"Priority"`
+		Report               BatchJobReport    `xml:"Report"`
+		RoleArn              string            `xml:"RoleArn"`
+	}
+
+	// BatchJobManifest describes the list of objects a job is run on.
+	BatchJobManifest struct {
+		Spec struct {
+			Format string   `xml:"Format"`
+			Fields []string `xml:"Fields>member"`
+		} `xml:"Spec"`
+		Location struct {
+			ObjectArn       string `xml:"ObjectArn"`
+			ObjectVersionID string `xml:"ObjectVersionId,omitempty"`
+			ETag            string `xml:"ETag,omitempty"`
+		} `xml:"Location"`
+	}
+
+	// BatchJobOperation describes the operation a job performs on every object of the manifest.
+	// Operations which can't be performed are decoded only to reject the job.
+	BatchJobOperation struct {
+		S3PutObjectCopy       *BatchJobCopyOperation    `xml:"S3PutObjectCopy,omitempty"`
+		S3PutObjectTagging    *BatchJobTaggingOperation `xml:"S3PutObjectTagging,omitempty"`
+		S3DeleteObjectTagging *struct{}                 `xml:"S3DeleteObjectTagging,omitempty"`
+		S3PutObjectACL        *BatchJobACLOperation     `xml:"S3PutObjectAcl,omitempty"`
+
+		S3InitiateRestoreObject *struct{} `xml:"S3InitiateRestoreObject,omitempty" json:"-"`
+		S3PutObjectLegalHold    *struct{} `xml:"S3PutObjectLegalHold,omitempty" json:"-"`
+		S3PutObjectRetention    *struct{} `xml:"S3PutObjectRetention,omitempty" json:"-"`
+		S3ReplicateObject       *struct{} `xml:"S3ReplicateObject,omitempty" json:"-"`
+		LambdaInvoke            *struct{} `xml:"LambdaInvoke,omitempty" json:"-"`
+	}
+
+	// BatchJobCopyOperation copies objects to the target bucket.
+	BatchJobCopyOperation struct {
+		TargetResource  string `xml:"TargetResource"`
+		TargetKeyPrefix string `xml:"TargetKeyPrefix,omitempty"`
+	}
+
+	// BatchJobTaggingOperation replaces tag set of objects.
+	BatchJobTaggingOperation struct {
+		TagSet []Tag `xml:"TagSet>S3Tag"`
+	}
+
+	// BatchJobACLOperation replaces access control list of objects with the canned ACL
+	// or the specified grants.
+	BatchJobACLOperation struct {
+		AccessControlPolicy     *BatchJobAccessControlPolicy `xml:"AccessControlPolicy,omitempty"`
+		CannedAccessControlList string                       `xml:"CannedAccessControlList,omitempty"`
+	}
+
+	// BatchJobAccessControlPolicy is an access control list of S3 Control API.
+	BatchJobAccessControlPolicy struct {
+		AccessControlList struct {
+			Grants []BatchJobGrant `xml:"Grants>member"`
+			Owner  struct {
+				DisplayName string `xml:"DisplayName,omitempty"`
+				ID          string `xml:"ID"`
+			} `xml:"Owner"`
+		} `xml:"AccessControlList"`
+	}
+
+	// BatchJobGrant is a grant of S3 Control API access control list.
+	BatchJobGrant struct {
+		Grantee struct {
+			DisplayName    string `xml:"DisplayName,omitempty"`
+			Identifier     string `xml:"Identifier"`
+			TypeIdentifier string `xml:"TypeIdentifier"`
+		} `xml:"Grantee"`
+		Permission AWSACL `xml:"Permission"`
+	}
+
+	// BatchJobReport describes the completion report of a job.
+	BatchJobReport struct {
+		Bucket      string `xml:"Bucket,omitempty"`
+		Enabled     bool   `xml:"Enabled"`
+		Format      string `xml:"Format,omitempty"`
+		Prefix      string `xml:"Prefix,omitempty"`
+		ReportScope string `xml:"ReportScope,omitempty"`
+	}
+
+	// CreateJobResult is a response of S3 Batch Operations job creation.
+	CreateJobResult struct {
+		XMLName xml.Name `xml:"http://awss3control.amazonaws.com/doc/2018-08-20/ CreateJobResult"`
+		JobID   string   `xml:"JobId"`
+	}
+
+	// DescribeJobResult is a response of S3 Batch Operations job description.
+	DescribeJobResult struct {
+		XMLName xml.Name      `xml:"http://awss3control.amazonaws.com/doc/2018-08-20/ DescribeJobResult"`
+		Job     JobDescriptor `xml:"Job"`
+	}
+
+	// JobDescriptor contains full information about a job.
+	JobDescriptor struct {
+		JobID                string             `xml:"JobId"`
+		ConfirmationRequired bool               `xml:"ConfirmationRequired"`
+		Description          string             `xml:"Description,omitempty"`
+		Status               string             `xml:"Status"`
+		StatusUpdateReason   string             `xml:"StatusUpdateReason,omitempty"`
+		Priority             int                `xml:"Priority"`
+		CreationTime         string             `xml:"CreationTime"`
+		TerminationDate      string             `xml:"TerminationDate,omitempty"`
+		ProgressSummary      JobProgressSummary `xml:"ProgressSummary"`
+		FailureReasons       []JobFailure       `xml:"FailureReasons>member,omitempty"`
+		Manifest             BatchJobManifest   `xml:"Manifest"`
+		Operation            BatchJobOperation  `xml:"Operation"`
+		Report               BatchJobReport     `xml:"Report"`
+	}
+
+	// JobProgressSummary describes the number of processed tasks of a job.
+	JobProgressSummary struct {
+		TotalNumberOfTasks     int64 `xml:"TotalNumberOfTasks"`
+		NumberOfTasksSucceeded int64 `xml:"NumberOfTasksSucceeded"`
+		NumberOfTasksFailed    int64 `xml:"NumberOfTasksFailed"`
+	}
+
+	// JobFailure describes the reason of a job failure.
+	JobFailure struct {
+		FailureCode   string `xml:"FailureCode"`
+		FailureReason string `xml:"FailureReason"`
+	}
+
+	// ListJobsResult is a response of S3 Batch Operations job listing.
+	ListJobsResult struct {
+		XMLName xml.Name            `xml:"http://awss3control.amazonaws.com/doc/2018-08-20/ ListJobsResult"`
+		Jobs    []JobListDescriptor `xml:"Jobs>member"`
+	}
+
+	// JobListDescriptor contains short information about a job.
+	JobListDescriptor struct {
+		JobID           string             `xml:"JobId"`
+		Description     string             `xml:"Description,omitempty"`
+		Operation       string             `xml:"Operation"`
+		Priority        int                `xml:"Priority"`
+		Status          string             `xml:"Status"`
+		CreationTime    string             `xml:"CreationTime"`
+		TerminationDate string             `xml:"TerminationDate,omitempty"`
+		ProgressSummary JobProgressSummary `xml:"ProgressSummary"`
+	}
+
+	// UpdateJobStatusResult is a response of S3 Batch Operations job status update.
+	UpdateJobStatusResult struct {
+		XMLName            xml.Name `xml:"http://awss3control.amazonaws.com/doc/2018-08-20/ UpdateJobStatusResult"`
+		JobID              string   `xml:"JobId"`
+		Status             string   `xml:"Status"`
+		StatusUpdateReason string   `xml:"StatusUpdateReason,omitempty"`
+	}
+)
+
+type (
+	// BatchJobStorage persists states of S3 Batch Operations jobs, so they survive restart
+	// of the gateway and are available on other gateway instances.
+	BatchJobStorage interface {
+		// PutJob saves the state of the job of the owner replacing the previous ones.
+		PutJob(ctx context.Context, id string, owner user.ID, state []byte) error
+		// JobStates returns saved states of the job, an empty list is returned if the job
+		// doesn't exist. Several states are returned if the previous ones weren't replaced.
+		JobStates(ctx context.Context, id string) ([][]byte, error)
+		// OwnerJobStates returns saved states of all jobs of the owner.
+		OwnerJobStates(ctx context.Context, owner user.ID) ([][]byte, error)
+	}
+
+	// batchJobs keeps jobs run by the gateway in memory. If storage is set, other jobs are
+	// read from it, otherwise jobs don't survive restart.
+	batchJobs struct {
+		storage BatchJobStorage
+
+		mu   sync.RWMutex
+		jobs map[string]*batchJob
+	}
+
+	batchJob struct {
+		mu sync.Mutex
+
+		id        string
+		owner     user.ID
+		request   *CreateJobRequest
+		box       *accessbox.Box
+		status    string
+		reason    string
+		created   time.Time
+		finished  time.Time
+		updated   time.Time
+		progress  JobProgressSummary
+		failures  []JobFailure
+		cancel    context.CancelFunc
+		cancelled bool
+		// stored jobs are read from the storage, they aren't run by the gateway.
+		stored bool
+	}
+
+	// batchJobState is the saved state of a job.
+	batchJobState struct {
+		ID       string             `json:"id"`
+		Owner    string             `json:"owner"`
+		Request  *CreateJobRequest  `json:"request"`
+		Status   string             `json:"status"`
+		Reason   string             `json:"reason,omitempty"`
+		Created  time.Time          `json:"created"`
+		Finished time.Time          `json:"finished"`
+		Updated  time.Time          `json:"updated"`
+		Progress JobProgressSummary `json:"progress"`
+		Failures []JobFailure       `json:"failures,omitempty"`
+	}
+
+	batchJobTask struct {
+		bucket    string
+		key       string
+		versionID string
+	}
+
+	batchJobTaskResult struct {
+		task    batchJobTask
+		status  string
+		err     error
+		errCode string
+		code    int
+	}
+)
+
+var (
+	errBatchJobCredentialsExpired = stderrors.New("credentials of the job creator expired")
+	errBatchJobReport             = stderrors.New("write report")
+)
+
+func newBatchJobs(storage BatchJobStorage) *batchJobs {
+	return &batchJobs{storage: storage, jobs: make(map[string]*batchJob)}
+}
+
+// add registers the job run by the gateway.
+func (j *batchJobs) add(job *batchJob) {
+	j.mu.Lock()
+	j.jobs[job.id] = job
+	j.mu.Unlock()
+}
+
+// release forgets the job which isn't run anymore, if it can be read from the storage.
+func (j *batchJobs) release(job *batchJob) {
+	if j.storage == nil || job.running() {
+		return
+	}
+
+	j.mu.Lock()
+	if j.jobs[job.id] == job {
+		delete(j.jobs, job.id)
+	}
+	j.mu.Unlock()
+}
+
+func (j *batchJobs) get(ctx context.Context, id string, owner user.ID) (*batchJob, error) {
+	j.mu.RLock()
+	job, ok := j.jobs[id]
+	j.mu.RUnlock()
+
+	if !ok && j.storage != nil {
+		states, err := j.storage.JobStates(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("get job states: %w", err)
+		}
+		jobs, err := decodeBatchJobs(states, layer.TimeNow(ctx))
+		if err != nil {
+			return nil, err
+		}
+		job, ok = jobs[id]
+	}
+
+	if !ok || !job.owner.Equals(owner) {
+		return nil, errors.GetAPIError(errors.ErrNoSuchJob)
+	}
+
+	return job, nil
+}
+
+func (j *batchJobs) list(ctx context.Context, owner user.ID) ([]*batchJob, error) {
+	jobs := make(map[string]*batchJob)
+	if j.storage != nil {
+		states, err := j.storage.OwnerJobStates(ctx, owner)
+		if err != nil {
+			return nil, fmt.Errorf("get job states: %w", err)
+		}
+		if jobs, err = decodeBatchJobs(states, layer.TimeNow(ctx)); err != nil {
+			return nil, err
+		}
+	}
+
+	j.mu.RLock()
+	for _, job := range j.jobs {
+		if job.owner.Equals(owner) {
+			jobs[job.id] = job
+		}
+	}
+	j.mu.RUnlock()
+
+	res := make([]*batchJob, 0, len(jobs))
+	for _, job := range jobs {
+		res = append(res, job)
+	}
+
+	sort.Slice(res, func(i, k int) bool {
+		return res[i].created.After(res[k].created)
+	})
+
+	return res, nil
+}
+
+// decodeBatchJobs decodes saved job states keeping the latest state of every job.
+func decodeBatchJobs(states [][]byte, now time.Time) (map[string]*batchJob, error) {
+	res := make(map[string]*batchJob, len(states))
+	for _, data := range states {
+		job, err := decodeBatchJob(data, now)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := res[job.id]; !ok || prev.updated.Before(job.updated) {
+			res[job.id] = job
+		}
+	}
+
+	return res, nil
+}
+
+// decodeBatchJob decodes the saved job state. Running jobs which state hasn't been saved
+// for batchJobInterruptedTimeout are considered failed.
+func decodeBatchJob(data []byte, now time.Time) (*batchJob, error) {
+	var state batchJobState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("unmarshal job state: %w", err)
+	}
+
+	job := &batchJob{
+		id:       state.ID,
+		request:  state.Request,
+		status:   state.Status,
+		reason:   state.Reason,
+		created:  state.Created,
+		finished: state.Finished,
+		updated:  state.Updated,
+		progress: state.Progress,
+		failures: state.Failures,
+		stored:   true,
+	}
+	if err := job.owner.DecodeString(state.Owner); err != nil {
+		return nil, fmt.Errorf("invalid owner of job '%s': %w", state.ID, err)
+	}
+	if job.request == nil {
+		return nil, fmt.Errorf("job '%s' has no request", state.ID)
+	}
+
+	if job.running() && now.Sub(job.updated) > batchJobInterruptedTimeout {
+		job.status = batchJobStatusFailed
+		job.finished = job.updated
+		job.failures = append(job.failures, JobFailure{
+			FailureCode:   "JobInterrupted",
+			FailureReason: "job is not run by any gateway",
+		})
+	}
+
+	return job, nil
+}
+
+// CreateJobHandler creates a new S3 Batch Operations job.
+func (h *handler) CreateJobHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	box, owner, err := jobOwner(r.Context())
+	if err != nil {
+		h.logAndSendError(w, "couldn't resolve job owner", reqInfo, err)
+		return
+	}
+
+	req := new(CreateJobRequest)
+	if err = xml.NewDecoder(r.Body).Decode(req); err != nil {
+		h.logAndSendError(w, "couldn't decode create job request", reqInfo, errors.GetAPIError(errors.ErrMalformedXML), zap.Error(err))
+		return
+	}
+
+	if err = checkCreateJobRequest(req); err != nil {
+		h.logAndSendError(w, "invalid create job request", reqInfo, err)
+		return
+	}
+
+	jobID := uuid.New().String()
+	if err = checkJobAction(box, "s3:GetObject", req.Manifest.Location.ObjectArn); err == nil && req.Report.Enabled {
+		err = checkJobAction(box, "s3:PutObject", req.Report.Bucket+"/"+jobReportObject(req.Report, jobID))
+	}
+	if err != nil {
+		h.logAndSendError(w, "job isn't allowed by inline policy", reqInfo, err)
+		return
+	}
+
+	job := &batchJob{
+		id:      jobID,
+		owner:   owner,
+		request: req,
+		box:     box,
+		status:  batchJobStatusNew,
+		created: layer.TimeNow(r.Context()),
+	}
+
+	if req.ConfirmationRequired {
+		job.setStatus(batchJobStatusSuspended, "AwaitingConfirmation")
+		if err = h.saveJob(r.Context(), job); err != nil {
+			h.logAndSendError(w, "couldn't save job", reqInfo, err)
+			return
+		}
+		if h.jobs.storage == nil {
+			h.jobs.add(job)
+		}
+	} else {
+		h.jobs.add(job)
+		h.startJob(job, box)
+	}
+
+	if err = api.EncodeToResponse(w, CreateJobResult{JobID: job.id}); err != nil {
+		h.logAndSendError(w, "couldn't encode create job response", reqInfo, err)
+	}
+}
+
+// DescribeJobHandler returns information about S3 Batch Operations job.
+func (h *handler) DescribeJobHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	_, job, err := h.getJob(r)
+	if err != nil {
+		h.logAndSendError(w, "couldn't get job", reqInfo, err)
+		return
+	}
+
+	if err = api.EncodeToResponse(w, DescribeJobResult{Job: job.descriptor()}); err != nil {
+		h.logAndSendError(w, "couldn't encode describe job response", reqInfo, err)
+	}
+}
+
+// ListJobsHandler lists S3 Batch Operations jobs of the request owner.
+func (h *handler) ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	_, owner, err := jobOwner(r.Context())
+	if err != nil {
+		h.logAndSendError(w, "couldn't resolve job owner", reqInfo, err)
+		return
+	}
+
+	statuses := make(map[string]struct{})
+	for _, status := range r.URL.Query()["jobStatuses"] {
+		statuses[status] = struct{}{}
+	}
+
+	jobs, err := h.jobs.list(r.Context(), owner)
+	if err != nil {
+		h.logAndSendError(w, "couldn't list jobs", reqInfo, err)
+		return
+	}
+
+	res := ListJobsResult{}
+	for _, job := range jobs {
+		descriptor := job.listDescriptor()
+		if _, ok := statuses[descriptor.Status]; len(statuses) > 0 && !ok {
+			continue
+		}
+		res.Jobs = append(res.Jobs, descriptor)
+	}
+
+	if err = api.EncodeToResponse(w, res); err != nil {
+		h.logAndSendError(w, "couldn't encode list jobs response", reqInfo, err)
+	}
+}
+
+// UpdateJobStatusHandler confirms or cancels S3 Batch Operations job.
+func (h *handler) UpdateJobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	box, job, err := h.getJob(r)
+	if err != nil {
+		h.logAndSendError(w, "couldn't get job", reqInfo, err)
+		return
+	}
+
+	reason := r.URL.Query().Get("statusUpdateReason")
+	switch requested := r.URL.Query().Get("requestedJobStatus"); requested {
+	case batchJobStatusReady:
+		if !job.compareAndSetStatus(batchJobStatusSuspended, batchJobStatusReady, reason) {
+			h.logAndSendError(w, "job can't be confirmed", reqInfo, errors.GetAPIError(errors.ErrJobStatus))
+			return
+		}
+		// the job is run with credentials of the confirmation request, since the ones
+		// of the job creation could expire while the job was waiting for confirmation
+		h.jobs.add(job)
+		h.startJob(job, box)
+	case batchJobStatusCancelled:
+		if err = job.requestCancel(reason, layer.TimeNow(r.Context())); err != nil {
+			h.logAndSendError(w, "job can't be cancelled", reqInfo, err)
+			return
+		}
+		if err = h.saveJob(r.Context(), job); err != nil {
+			h.logAndSendError(w, "couldn't save job", reqInfo, err)
+			return
+		}
+	default:
+		h.logAndSendError(w, "invalid requested job status", reqInfo, errors.GetAPIError(errors.ErrInvalidArgument),
+			zap.String("requested", requested))
+		return
+	}
+
+	job.mu.Lock()
+	res := UpdateJobStatusResult{JobID: job.id, Status: job.status, StatusUpdateReason: job.reason}
+	job.mu.Unlock()
+
+	if err = api.EncodeToResponse(w, res); err != nil {
+		h.logAndSendError(w, "couldn't encode update job status response", reqInfo, err)
+	}
+}
+
+// getJob returns the job of the request owner along with the access box of the request.
+func (h *handler) getJob(r *http.Request) (*accessbox.Box, *batchJob, error) {
+	box, owner, err := jobOwner(r.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job, err := h.jobs.get(r.Context(), mux.Vars(r)[batchJobIDVar], owner)
+	return box, job, err
+}
+
+// saveJob saves the state of the job if jobs are persisted.
+func (h *handler) saveJob(ctx context.Context, job *batchJob) error {
+	if h.jobs.storage == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(job.state(layer.TimeNow(ctx)))
+	if err != nil {
+		return fmt.Errorf("marshal job state: %w", err)
+	}
+
+	if err = h.jobs.storage.PutJob(ctx, job.id, job.owner, data); err != nil {
+		return fmt.Errorf("put job state: %w", err)
+	}
+
+	h.jobs.release(job)
+	return nil
+}
+
+func jobOwner(ctx context.Context) (*accessbox.Box, user.ID, error) {
+	box, err := layer.GetBoxData(ctx)
+	if err != nil || box.Gate == nil || box.Gate.BearerToken == nil {
+		return nil, user.ID{}, errors.GetAPIError(errors.ErrAccessDenied)
+	}
+
+	return box, bearer.ResolveIssuer(*box.Gate.BearerToken), nil
+}
+
+func checkCreateJobRequest(req *CreateJobRequest) error {
+	if op := req.Operation.unsupported(); op != "" {
+		return fmt.Errorf("%w: operation '%s' isn't supported", errors.GetAPIError(errors.ErrNotImplemented), op)
+	}
+
+	var operations int
+	if req.Operation.S3PutObjectCopy != nil {
+		if _, err := parseBucketARN(req.Operation.S3PutObjectCopy.TargetResource); err != nil {
+			return err
+		}
+		operations++
+	}
+	if req.Operation.S3PutObjectTagging != nil {
+		operations++
+	}
+	if req.Operation.S3DeleteObjectTagging != nil {
+		operations++
+	}
+	if op := req.Operation.S3PutObjectACL; op != nil {
+		if (op.AccessControlPolicy == nil) == (op.CannedAccessControlList == "") {
+			return fmt.Errorf("%w: either access control policy or canned acl must be specified", errors.GetAPIError(errors.ErrMalformedXML))
+		}
+		operations++
+	}
+
+	switch {
+	case operations == 0:
+		return fmt.Errorf("%w: operation isn't supported", errors.GetAPIError(errors.ErrNotImplemented))
+	case operations > 1:
+		return fmt.Errorf("%w: only one operation must be specified", errors.GetAPIError(errors.ErrMalformedXML))
+	}
+
+	if req.Manifest.Spec.Format != batchJobManifestFormatCSV {
+		return fmt.Errorf("%w: unsupported manifest format '%s'", errors.GetAPIError(errors.ErrInvalidArgument), req.Manifest.Spec.Format)
+	}
+	if _, _, err := parseObjectARN(req.Manifest.Location.ObjectArn); err != nil {
+		return err
+	}
+
+	if req.Report.Enabled {
+		if req.Report.Format != batchJobReportFormatCSV {
+			return fmt.Errorf("%w: unsupported report format '%s'", errors.GetAPIError(errors.ErrInvalidArgument), req.Report.Format)
+		}
+		if req.Report.ReportScope != batchJobReportScopeAll && req.Report.ReportScope != batchJobReportScopeFailed {
+			return fmt.Errorf("%w: invalid report scope '%s'", errors.GetAPIError(errors.ErrInvalidArgument), req.Report.ReportScope)
+		}
+		if _, err := parseBucketARN(req.Report.Bucket); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseBucketARN(arn string) (string, error) {
+	bucket := strings.TrimPrefix(arn, s3ARNPrefix)
+	if bucket == arn || bucket == "" || strings.Contains(bucket, "/") {
+		return "", fmt.Errorf("%w: invalid bucket arn '%s'", errors.GetAPIError(errors.ErrInvalidArgument), arn)
+	}
+	return bucket, nil
+}
+
+func parseObjectARN(arn string) (string, string, error) {
+	path := strings.TrimPrefix(arn, s3ARNPrefix)
+	parts := strings.SplitN(path, "/", 2)
+	if path == arn || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("%w: invalid object arn '%s'", errors.GetAPIError(errors.ErrInvalidArgument), arn)
+	}
+	return parts[0], parts[1], nil
+}
+
+// startJob runs the job in background. The context of the job isn't bound to the request,
+// but keeps credentials of the request which started the job.
+func (h *handler) startJob(job *batchJob, box *accessbox.Box) {
+	ctx := context.WithValue(context.Background(), api.BoxData, box)
+	tasksCtx, cancel := context.WithCancel(ctx)
+
+	job.mu.Lock()
+	job.box = box
+	job.cancel = cancel
+	job.stored = false
+	job.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		h.runJob(ctx, tasksCtx, job)
+	}()
+}
+
+// runJob processes tasks of the job until tasksCtx is cancelled. Completion report is
+// written with ctx, so it's available for cancelled jobs too. The state of the job is
+// saved periodically while it's running.
+func (h *handler) runJob(ctx, tasksCtx context.Context, job *batchJob) {
+	defer func() {
+		if err := h.saveJob(ctx, job); err != nil {
+			h.log.Error("couldn't save batch job", zap.String("job", job.id), zap.Error(err))
+		}
+	}()
+
+	if !job.activate() {
+		job.finish(layer.TimeNow(ctx))
+		return
+	}
+
+	// periodic saving is stopped before the final state is saved, so it isn't overwritten
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		h.saveJobPeriodically(ctx, job, done)
+		close(stopped)
+	}()
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+
+	var report *jobReport
+	if job.request.Report.Enabled {
+		var err error
+		if report, err = newJobReport(job.request.Report); err != nil {
+			h.log.Error("couldn't create batch job report", zap.String("job", job.id), zap.Error(err))
+			job.fail("ReportWriteFailure", err)
+			return
+		}
+		defer report.close()
+	}
+
+	err := h.readJobManifest(tasksCtx, job.request, func(task batchJobTask) error {
+		if exp := job.box.Expiration; !exp.IsZero() && layer.TimeNow(ctx).After(exp) {
+			return errBatchJobCredentialsExpired
+		}
+
+		res := h.runJobTask(tasksCtx, job, task)
+		if report != nil {
+			if err := report.add(res); err != nil {
+				return fmt.Errorf("%w: %v", errBatchJobReport, err)
+			}
+		}
+		job.addResult(res)
+
+		return tasksCtx.Err()
+	})
+	switch {
+	case err == nil, tasksCtx.Err() != nil:
+	case stderrors.Is(err, errBatchJobCredentialsExpired):
+		h.log.Error("batch job is stopped", zap.String("job", job.id), zap.Error(err))
+		job.fail("CredentialsExpired", err)
+		return
+	case stderrors.Is(err, errBatchJobReport):
+		h.log.Error("couldn't write batch job report", zap.String("job", job.id), zap.Error(err))
+		job.fail("ReportWriteFailure", err)
+		return
+	default:
+		h.log.Error("couldn't read batch job manifest", zap.String("job", job.id), zap.Error(err))
+		job.fail("ManifestReadFailure", err)
+		return
+	}
+
+	if report != nil {
+		if err = h.writeJobReport(ctx, job, report); err != nil {
+			h.log.Error("couldn't write batch job report", zap.String("job", job.id), zap.Error(err))
+			job.fail("ReportWriteFailure", err)
+			return
+		}
+	}
+
+	job.finish(layer.TimeNow(ctx))
+}
+
+// saveJobPeriodically saves the state of the running job until done is closed,
+// so other gateway instances see its progress and don't consider it interrupted.
+func (h *handler) saveJobPeriodically(ctx context.Context, job *batchJob, done <-chan struct{}) {
+	if h.jobs.storage == nil {
+		return
+	}
+
+	ticker := time.NewTicker(batchJobSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.saveJob(ctx, job); err != nil {
+			h.log.Warn("couldn't save batch job", zap.String("job", job.id), zap.Error(err))
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// readJobManifest streams the manifest object calling process for every task of it.
+// Reading is stopped on the first error returned by process.
+func (h *handler) readJobManifest(ctx context.Context, req *CreateJobRequest, process func(batchJobTask) error) error {
+	bktName, objName, _ := parseObjectARN(req.Manifest.Location.ObjectArn)
+
+	bktInfo, err := h.obj.GetBucketInfo(ctx, bktName)
+	if err != nil {
+		return fmt.Errorf("get manifest bucket: %w", err)
+	}
+
+	objInfo, err := h.obj.GetObjectInfo(ctx, &layer.HeadObjectParams{
+		BktInfo:   bktInfo,
+		Object:    objName,
+		VersionID: req.Manifest.Location.ObjectVersionID,
+	})
+	if err != nil {
+		return fmt.Errorf("get manifest object info: %w", err)
+	}
+
+	if etag := strings.Trim(req.Manifest.Location.ETag, "\""); etag != "" && etag != objInfo.HashSum {
+		return fmt.Errorf("manifest etag mismatch: expected '%s', actual '%s'", etag, objInfo.HashSum)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		err := h.obj.GetObject(ctx, &layer.GetObjectParams{
+			ObjectInfo: objInfo,
+			BucketInfo: bktInfo,
+			Writer:     pw,
+		})
+		if err != nil {
+			err = fmt.Errorf("get manifest object payload: %w", err)
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	err = parseJobManifest(pr, req.Manifest.Spec.Fields, process)
+	// unblocks the payload writer if the manifest isn't read till the end
+	_ = pr.Close()
+
+	return err
+}
+
+// parseJobManifest parses CSV manifest calling process for every record. Keys in the manifest are URL-encoded.
+func parseJobManifest(r io.Reader, fields []string, process func(batchJobTask) error) error {
+	if len(fields) == 0 {
+		fields = []string{"Bucket", "Key"}
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read manifest record: %w", err)
+		}
+		if len(record) != len(fields) {
+			return fmt.Errorf("manifest record '%s' doesn't match fields %v", strings.Join(record, ","), fields)
+		}
+
+		var task batchJobTask
+		for i, field := range fields {
+			switch field {
+			case "Bucket":
+				task.bucket = record[i]
+			case "Key":
+				if task.key, err = url.QueryUnescape(record[i]); err != nil {
+					return fmt.Errorf("invalid manifest key '%s': %w", record[i], err)
+				}
+			case "VersionId":
+				task.versionID = record[i]
+			default:
+				return fmt.Errorf("unknown manifest field '%s'", field)
+			}
+		}
+
+		if task.bucket == "" || task.key == "" {
+			return fmt.Errorf("manifest record '%s' has no bucket or key", strings.Join(record, ","))
+		}
+
+		if err = process(task); err != nil {
+			return err
+		}
+	}
+}
+
+func (h *handler) runJobTask(ctx context.Context, job *batchJob, task batchJobTask) batchJobTaskResult {
+	res := batchJobTaskResult{task: task, status: batchJobTaskSucceeded, code: http.StatusOK}
+
+	err := h.runJobOperation(ctx, job, task)
+	if err != nil {
+		s3err := transformToS3Error(err).(errors.Error)
+		res.status = batchJobTaskFailed
+		res.err = err
+		res.errCode = s3err.Code
+		res.code = s3err.HTTPStatusCode
+	}
+
+	return res
+}
+
+func (h *handler) runJobOperation(ctx context.Context, job *batchJob, task batchJobTask) error {
+	req := job.request
+	if err := checkJobPolicy(job.box, req.Operation, task); err != nil {
+		return err
+	}
+
+	bktInfo, err := h.obj.GetBucketInfo(ctx, task.bucket)
+	if err != nil {
+		return err
+	}
+
+	objVersion := &layer.ObjectVersion{
+		BktInfo:    bktInfo,
+		ObjectName: task.key,
+		VersionID:  task.versionID,
+	}
+
+	switch {
+	case req.Operation.S3PutObjectCopy != nil:
+		return h.copyObjectForJob(ctx, req.Operation.S3PutObjectCopy, bktInfo, task)
+	case req.Operation.S3PutObjectTagging != nil:
+		tagSet := make(map[string]string, len(req.Operation.S3PutObjectTagging.TagSet))
+		for _, tag := range req.Operation.S3PutObjectTagging.TagSet {
+			if err = checkTag(tag); err != nil {
+				return err
+			}
+			tagSet[tag.Key] = tag.Value
+		}
+		_, err = h.obj.PutObjectTagging(ctx, &layer.PutObjectTaggingParams{ObjectVersion: objVersion, TagSet: tagSet})
+		return err
+	case req.Operation.S3DeleteObjectTagging != nil:
+		_, err = h.obj.DeleteObjectTagging(ctx, objVersion)
+		return err
+	case req.Operation.S3PutObjectACL != nil:
+		return h.putObjectACLForJob(ctx, req.Operation.S3PutObjectACL, bktInfo, task)
+	}
+
+	return errors.GetAPIError(errors.ErrNotImplemented)
+}
+
+// checkJobPolicy checks the task against inline policy of the job creator's access box.
+// Tasks don't pass request authentication, so CreateJob permission alone doesn't allow them.
+func checkJobPolicy(box *accessbox.Box, op BatchJobOperation, task batchJobTask) error {
+	resource := s3ARNPrefix + task.bucket + "/" + task.key
+
+	switch {
+	case op.S3PutObjectCopy != nil:
+		if err := checkJobAction(box, "s3:GetObject", resource); err != nil {
+			return err
+		}
+		dstBucket, _ := parseBucketARN(op.S3PutObjectCopy.TargetResource)
+		return checkJobAction(box, "s3:PutObject", s3ARNPrefix+dstBucket+"/"+op.S3PutObjectCopy.TargetKeyPrefix+task.key)
+	case op.S3PutObjectTagging != nil:
+		return checkJobAction(box, "s3:PutObjectTagging", resource)
+	case op.S3DeleteObjectTagging != nil:
+		return checkJobAction(box, "s3:DeleteObjectTagging", resource)
+	case op.S3PutObjectACL != nil:
+		return checkJobAction(box, "s3:PutObjectAcl", resource)
+	}
+
+	return nil
+}
+
+func checkJobAction(box *accessbox.Box, action, resource string) error {
+	if box.InlinePolicy != nil && !box.InlinePolicy.IsAllowed(action, resource) {
+		return fmt.Errorf("%w: %s on '%s' isn't allowed by inline policy",
+			errors.GetAPIError(errors.ErrAccessDenied), action, resource)
+	}
+	return nil
+}
+
+func (h *handler) copyObjectForJob(ctx context.Context, op *BatchJobCopyOperation, srcBktInfo *data.BucketInfo, task batchJobTask) error {
+	if err := h.checkNotTransformed(task.bucket, task.key); err != nil {
+		return err
+	}
+
+	dstBucket, _ := parseBucketARN(op.TargetResource)
+	dstBktInfo, err := h.obj.GetBucketInfo(ctx, dstBucket)
+	if err != nil {
+		return err
+	}
+
+	srcObjInfo, err := h.obj.GetObjectInfo(ctx, &layer.HeadObjectParams{
+		BktInfo:   srcBktInfo,
+		Object:    task.key,
+		VersionID: task.versionID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if layer.FormEncryptionInfo(srcObjInfo.Headers).Enabled {
+		return fmt.Errorf("%w: encrypted objects can't be copied by batch job", errors.GetAPIError(errors.ErrBadRequest))
+	}
+
+	metadata := make(map[string]string, len(srcObjInfo.Headers)+1)
+	for key, val := range srcObjInfo.Headers {
+		metadata[key] = val
+	}
+	if len(srcObjInfo.ContentType) > 0 {
+		metadata[api.ContentType] = srcObjInfo.ContentType
+	}
+
+	copiesNumber, err := getCopiesNumberOrDefault(metadata, h.cfg.CopiesNumber)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.obj.CopyObject(ctx, &layer.CopyObjectParams{
+		SrcObject:   srcObjInfo,
+		ScrBktInfo:  srcBktInfo,
+		DstBktInfo:  dstBktInfo,
+		DstObject:   op.TargetKeyPrefix + task.key,
+		SrcSize:     srcObjInfo.Size,
+		Header:      metadata,
+		CopiesNuber: copiesNumber,
+	})
+	return err
+}
+
+// putObjectACLForJob replaces access control list of the object like PutObjectAcl request.
+func (h *handler) putObjectACLForJob(ctx context.Context, op *BatchJobACLOperation, bktInfo *data.BucketInfo, task batchJobTask) error {
+	key, err := h.bearerTokenIssuerKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	token, err := getSessionTokenSetEACL(ctx)
+	if err != nil {
+		return err
+	}
+
+	objInfo, err := h.obj.GetObjectInfo(ctx, &layer.HeadObjectParams{
+		BktInfo:   bktInfo,
+		Object:    task.key,
+		VersionID: task.versionID,
+	})
+	if err != nil {
+		return err
+	}
+
+	list, err := op.accessControlPolicy(key)
+	if err != nil {
+		return err
+	}
+
+	astObject, err := aclToAst(list, &resourceInfo{
+		Bucket:  task.bucket,
+		Object:  task.key,
+		Version: objInfo.VersionID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = h.updateBucketACL(ctx, astObject, bktInfo, token)
+	return err
+}
+
+// accessControlPolicy converts the operation to the policy of PutObjectAcl request.
+func (o *BatchJobACLOperation) accessControlPolicy(key *keys.PublicKey) (*AccessControlPolicy, error) {
+	if o.CannedAccessControlList != "" {
+		header := make(http.Header)
+		header.Set(api.AmzACL, o.CannedAccessControlList)
+		return parseACLHeaders(header, key)
+	}
+
+	acl := o.AccessControlPolicy.AccessControlList
+	list := &AccessControlPolicy{Owner: Owner{ID: acl.Owner.ID, DisplayName: acl.Owner.DisplayName}}
+	for _, grant := range acl.Grants {
+		grantee := &Grantee{DisplayName: grant.Grantee.DisplayName}
+		switch grant.Grantee.TypeIdentifier {
+		case "id":
+			grantee.Type, grantee.ID = acpCanonicalUser, grant.Grantee.Identifier
+		case "uri":
+			grantee.Type, grantee.URI = acpGroup, grant.Grantee.Identifier
+		case "emailAddress":
+			grantee.Type, grantee.EmailAddress = acpAmazonCustomerByEmail, grant.Grantee.Identifier
+		default:
+			return nil, fmt.Errorf("%w: invalid grantee type '%s'", errors.GetAPIError(errors.ErrInvalidArgument), grant.Grantee.TypeIdentifier)
+		}
+		list.AccessControlList = append(list.AccessControlList, &Grant{Grantee: grantee, Permission: grant.Permission})
+	}
+
+	return list, nil
+}
+
+// jobReport keeps rows of the completion report in a temporary file while the job
+// is running, so results of the tasks aren't kept in memory.
+type jobReport struct {
+	scope  string
+	file   *os.File
+	writer *csv.Writer
+}
+
+func newJobReport(report BatchJobReport) (*jobReport, error) {
+	file, err := os.CreateTemp("", "s3-gw-job-report-*.csv")
+	if err != nil {
+		return nil, fmt.Errorf("create temporary report file: %w", err)
+	}
+
+	return &jobReport{
+		scope:  report.ReportScope,
+		file:   file,
+		writer: csv.NewWriter(file),
+	}, nil
+}
+
+// add writes the result of the task to the report if it matches the report scope.
+func (r *jobReport) add(res batchJobTaskResult) error {
+	if r.scope == batchJobReportScopeFailed && res.status != batchJobTaskFailed {
+		return nil
+	}
+
+	var message string
+	if res.err != nil {
+		message = res.err.Error()
+	}
+
+	record := []string{res.task.bucket, url.QueryEscape(res.task.key), res.task.versionID,
+		res.status, res.errCode, strconv.Itoa(res.code), message}
+	if err := r.writer.Write(record); err != nil {
+		return fmt.Errorf("write report record: %w", err)
+	}
+	return nil
+}
+
+// reader flushes written records and returns the reader of the report from the start with its size.
+func (r *jobReport) reader() (io.Reader, int64, error) {
+	r.writer.Flush()
+	if err := r.writer.Error(); err != nil {
+		return nil, 0, fmt.Errorf("flush report: %w", err)
+	}
+
+	size, err := r.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get report size: %w", err)
+	}
+	if _, err = r.file.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, fmt.Errorf("rewind report: %w", err)
+	}
+
+	return r.file, size, nil
+}
+
+// close removes the temporary file of the report.
+func (r *jobReport) close() {
+	_ = r.file.Close()
+	_ = os.Remove(r.file.Name())
+}
+
+// writeJobReport puts CSV completion report to <prefix>/job-<id>/results/report.csv object of the report bucket.
+func (h *handler) writeJobReport(ctx context.Context, job *batchJob, report *jobReport) error {
+	bktName, _ := parseBucketARN(job.request.Report.Bucket)
+
+	bktInfo, err := h.obj.GetBucketInfo(ctx, bktName)
+	if err != nil {
+		return fmt.Errorf("get report bucket: %w", err)
+	}
+
+	r, size, err := report.reader()
+	if err != nil {
+		return err
+	}
+
+	_, err = h.obj.PutObject(ctx, &layer.PutObjectParams{
+		BktInfo:      bktInfo,
+		Object:       jobReportObject(job.request.Report, job.id),
+		Reader:       r,
+		Size:         size,
+		Header:       map[string]string{api.ContentType: "text/csv"},
+		CopiesNumber: h.cfg.CopiesNumber,
+	})
+	return err
+}
+
+func jobReportObject(report BatchJobReport, jobID string) string {
+	objName := "job-" + jobID + "/results/report.csv"
+	if report.Prefix != "" {
+		objName = strings.TrimSuffix(report.Prefix, "/") + "/" + objName
+	}
+	return objName
+}
+
+// activate marks the job as active unless it has been cancelled.
+func (j *batchJob) activate() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.cancelled {
+		return false
+	}
+	j.status = batchJobStatusActive
+	return true
+}
+
+func (j *batchJob) setStatus(status, reason string) {
+	j.mu.Lock()
+	j.status = status
+	j.reason = reason
+	j.mu.Unlock()
+}
+
+func (j *batchJob) compareAndSetStatus(expected, status, reason string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.status != expected || j.cancelled {
+		return false
+	}
+	j.status = status
+	j.reason = reason
+	return true
+}
+
+func (j *batchJob) requestCancel(reason string, now time.Time) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	switch j.status {
+	case batchJobStatusComplete, batchJobStatusFailed, batchJobStatusCancelled:
+		return errors.GetAPIError(errors.ErrJobStatus)
+	}
+
+	// running jobs can be cancelled only by the gateway which runs them
+	if j.stored && j.status != batchJobStatusSuspended {
+		return fmt.Errorf("%w: job is run by another gateway", errors.GetAPIError(errors.ErrJobStatus))
+	}
+
+	j.cancelled = true
+	j.reason = reason
+	if j.cancel == nil || j.status == batchJobStatusSuspended {
+		j.status = batchJobStatusCancelled
+		j.finished = now
+		return nil
+	}
+
+	j.status = batchJobStatusCancelling
+	j.cancel()
+	return nil
+}
+
+func (j *batchJob) addResult(res batchJobTaskResult) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.progress.TotalNumberOfTasks++
+	if res.status == batchJobTaskSucceeded {
+		j.progress.NumberOfTasksSucceeded++
+	} else {
+		j.progress.NumberOfTasksFailed++
+	}
+}
+
+func (j *batchJob) fail(code string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.status = batchJobStatusFailed
+	j.failures = append(j.failures, JobFailure{FailureCode: code, FailureReason: err.Error()})
+	j.finished = time.Now()
+}
+
+func (j *batchJob) finish(now time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.finished = now
+	if j.cancelled {
+		j.status = batchJobStatusCancelled
+		return
+	}
+	j.status = batchJobStatusComplete
+}
+
+// running checks if the job is processed or is going to be processed without confirmation.
+func (j *batchJob) running() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	switch j.status {
+	case batchJobStatusNew, batchJobStatusReady, batchJobStatusActive, batchJobStatusCancelling:
+		return true
+	}
+	return false
+}
+
+func (j *batchJob) state(now time.Time) batchJobState {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.updated = now
+	return batchJobState{
+		ID:       j.id,
+		Owner:    j.owner.EncodeToString(),
+		Request:  j.request,
+		Status:   j.status,
+		Reason:   j.reason,
+		Created:  j.created,
+		Finished: j.finished,
+		Updated:  j.updated,
+		Progress: j.progress,
+		Failures: j.failures,
+	}
+}
+
+func (j *batchJob) descriptor() JobDescriptor {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return JobDescriptor{
+		JobID:                j.id,
+		ConfirmationRequired: j.request.ConfirmationRequired,
+		Description:          j.request.Description,
+		Status:               j.status,
+		StatusUpdateReason:   j.reason,
+		Priority:             j.request.Priority,
+		CreationTime:         j.created.UTC().Format(time.RFC3339),
+		TerminationDate:      formatJobTime(j.finished),
+		ProgressSummary:      j.progress,
+		FailureReasons:       j.failures,
+		Manifest:             j.request.Manifest,
+		Operation:            j.request.Operation,
+		Report:               j.request.Report,
+	}
+}
+
+func (j *batchJob) listDescriptor() JobListDescriptor {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return JobListDescriptor{
+		JobID:           j.id,
+		Description:     j.request.Description,
+		Operation:       j.request.Operation.name(),
+		Priority:        j.request.Priority,
+		Status:          j.status,
+		CreationTime:    j.created.UTC().Format(time.RFC3339),
+		TerminationDate: formatJobTime(j.finished),
+		ProgressSummary: j.progress,
+	}
+}
+
+func (o BatchJobOperation) name() string {
+	switch {
+	case o.S3PutObjectCopy != nil:
+		return batchJobOperationCopy
+	case o.S3PutObjectTagging != nil:
+		return batchJobOperationTagging
+	case o.S3DeleteObjectTagging != nil:
+		return batchJobOperationDeleteTagging
+	case o.S3PutObjectACL != nil:
+		return batchJobOperationACL
+	}
+	return ""
+}
+
+// unsupported returns the name of the specified operation which can't be performed by jobs.
+func (o BatchJobOperation) unsupported() string {
+	for _, op := range []struct {
+		name      string
+		specified bool
+	}{
+		{"S3InitiateRestoreObject", o.S3InitiateRestoreObject != nil},
+		{"S3PutObjectLegalHold", o.S3PutObjectLegalHold != nil},
+		{"S3PutObjectRetention", o.S3PutObjectRetention != nil},
+		{"S3ReplicateObject", o.S3ReplicateObject != nil},
+		{"LambdaInvoke", o.LambdaInvoke != nil},
+	} {
+		if op.specified {
+			return op.name
+		}
+	}
+	return ""
+}
+
+func formatJobTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
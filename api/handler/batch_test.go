@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchJobCopy(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	srcBucket, dstBucket := "bucket-for-batch-src", "bucket-for-batch-dst"
+	createTestBucket(hc, srcBucket)
+	createTestBucket(hc, dstBucket)
+
+	putObjectContent(hc, srcBucket, "obj1", "content1")
+	putObjectContent(hc, srcBucket, "dir/obj 2", "content2")
+	putObjectContent(hc, srcBucket, "manifest.csv", srcBucket+",obj1\n"+srcBucket+",dir/obj+2\n"+srcBucket+",missing\n")
+
+	req := &CreateJobRequest{
+		Operation: BatchJobOperation{S3PutObjectCopy: &BatchJobCopyOperation{
+			TargetResource:  s3ARNPrefix + dstBucket,
+			TargetKeyPrefix: "copy/",
+		}},
+		Report: BatchJobReport{
+			Bucket:      s3ARNPrefix + dstBucket,
+			Enabled:     true,
+			Format:      batchJobReportFormatCSV,
+			Prefix:      "reports",
+			ReportScope: batchJobReportScopeFailed,
+		},
+	}
+	req.Manifest.Spec.Format = batchJobManifestFormatCSV
+	req.Manifest.Location.ObjectArn = s3ARNPrefix + srcBucket + "/manifest.csv"
+
+	jobID := createJob(hc, req)
+	job := waitJobStatus(hc, jobID, batchJobStatusComplete)
+	require.Equal(t, JobProgressSummary{TotalNumberOfTasks: 3, NumberOfTasksSucceeded: 2, NumberOfTasksFailed: 1}, job.ProgressSummary)
+
+	require.Equal(t, []byte("content1"), getObject(hc, dstBucket, "copy/obj1"))
+	require.Equal(t, []byte("content2"), getObject(hc, dstBucket, "copy/dir/obj 2"))
+
+	report := string(getObject(hc, dstBucket, "reports/job-"+jobID+"/results/report.csv"))
+	require.True(t, strings.HasPrefix(report, srcBucket+",missing,,failed,NoSuchKey,404,"), report)
+	require.Equal(t, 1, strings.Count(report, "\n"))
+}
+
+func TestBatchJobStatus(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-batch-status"
+	createTestBucket(hc, bktName)
+	putObjectContent(hc, bktName, "manifest.csv", bktName+",obj\n")
+
+	req := &CreateJobRequest{
+		ConfirmationRequired: true,
+		Operation:            BatchJobOperation{S3PutObjectTagging: &BatchJobTaggingOperation{TagSet: []Tag{{Key: "key", Value: "val"}}}},
+	}
+	req.Manifest.Spec.Format = batchJobManifestFormatCSV
+	req.Manifest.Location.ObjectArn = s3ARNPrefix + bktName + "/manifest.csv"
+
+	jobID := createJob(hc, req)
+	require.Equal(t, batchJobStatusSuspended, describeJob(hc, jobID).Status)
+
+	updateJobStatus(hc, jobID, batchJobStatusCancelled, http.StatusOK)
+	require.Equal(t, batchJobStatusCancelled, describeJob(hc, jobID).Status)
+	updateJobStatus(hc, jobID, batchJobStatusReady, http.StatusConflict)
+
+	w, r := prepareTestFullRequest(hc, "", "", url.Values{"jobStatuses": []string{batchJobStatusCancelled}}, nil)
+	hc.Handler().ListJobsHandler(w, r)
+	jobs := &ListJobsResult{}
+	readResponse(t, w, http.StatusOK, jobs)
+	require.Len(t, jobs.Jobs, 1)
+	require.Equal(t, jobID, jobs.Jobs[0].JobID)
+	require.Equal(t, batchJobOperationTagging, jobs.Jobs[0].Operation)
+
+	w, r = prepareTestRequest(hc, "", "", &CreateJobRequest{Manifest: req.Manifest})
+	hc.Handler().CreateJobHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrNotImplemented))
+
+	w, r = prepareTestRequest(hc, "", "", nil)
+	hc.Handler().DescribeJobHandler(w, mux.SetURLVars(r, map[string]string{batchJobIDVar: "unknown"}))
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrNoSuchJob))
+}
+
+func TestBatchJobInlinePolicy(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-batch-policy"
+	createTestBucket(hc, bktName)
+	putObjectContent(hc, bktName, "allowed/obj", "content")
+	putObjectContent(hc, bktName, "denied/obj", "content")
+	putObjectContent(hc, bktName, "manifest.csv", bktName+",allowed/obj\n"+bktName+",denied/obj\n")
+
+	policy, err := accessbox.ParseInlinePolicy([]byte(`{"Statement": [
+		{"Effect": "Allow", "Action": "s3:createjob", "Resource": "*"},
+		{"Effect": "Allow", "Action": "S3:GetObject", "Resource": "arn:aws:s3:::` + bktName + `/*"},
+		{"Effect": "Allow", "Action": "s3:putobjecttagging", "Resource": "arn:aws:s3:::` + bktName + `/allowed/*"}
+	]}`))
+	require.NoError(t, err)
+	box, err := layer.GetBoxData(hc.Context())
+	require.NoError(t, err)
+	box.InlinePolicy = policy
+
+	req := &CreateJobRequest{
+		Operation: BatchJobOperation{S3PutObjectTagging: &BatchJobTaggingOperation{TagSet: []Tag{{Key: "key", Value: "val"}}}},
+	}
+	req.Manifest.Spec.Format = batchJobManifestFormatCSV
+	req.Manifest.Location.ObjectArn = s3ARNPrefix + bktName + "/manifest.csv"
+
+	jobID := createJob(hc, req)
+	job := waitJobStatus(hc, jobID, batchJobStatusComplete)
+	require.Equal(t, JobProgressSummary{TotalNumberOfTasks: 2, NumberOfTasksSucceeded: 1, NumberOfTasksFailed: 1}, job.ProgressSummary)
+
+	req.Report = BatchJobReport{
+		Bucket:      s3ARNPrefix + bktName,
+		Enabled:     true,
+		Format:      batchJobReportFormatCSV,
+		ReportScope: batchJobReportScopeAll,
+	}
+	w, r := prepareTestRequest(hc, "", "", req)
+	hc.Handler().CreateJobHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrAccessDenied))
+}
+
+func TestBatchJobStorage(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	storage := &batchJobStorageMock{states: make(map[string][]byte), owners: make(map[string]user.ID)}
+	hc.h.jobs = newBatchJobs(storage)
+
+	bktName := "bucket-for-batch-storage"
+	createTestBucket(hc, bktName)
+	putObjectContent(hc, bktName, "obj", "content")
+	putObjectContent(hc, bktName, "manifest.csv", bktName+",obj\n")
+
+	req := &CreateJobRequest{
+		ConfirmationRequired: true,
+		Operation:            BatchJobOperation{S3PutObjectTagging: &BatchJobTaggingOperation{TagSet: []Tag{{Key: "key", Value: "val"}}}},
+	}
+	req.Manifest.Spec.Format = batchJobManifestFormatCSV
+	req.Manifest.Location.ObjectArn = s3ARNPrefix + bktName + "/manifest.csv"
+
+	jobID := createJob(hc, req)
+
+	// the job is confirmed and run by another gateway instance
+	hc.h.jobs = newBatchJobs(storage)
+	require.Equal(t, batchJobStatusSuspended, describeJob(hc, jobID).Status)
+	updateJobStatus(hc, jobID, batchJobStatusReady, http.StatusOK)
+	job := waitJobStatus(hc, jobID, batchJobStatusComplete)
+	require.Equal(t, JobProgressSummary{TotalNumberOfTasks: 1, NumberOfTasksSucceeded: 1}, job.ProgressSummary)
+
+	// finished jobs are released after their final state is saved
+	require.Eventually(t, func() bool {
+		hc.h.jobs.mu.RLock()
+		defer hc.h.jobs.mu.RUnlock()
+		return len(hc.h.jobs.jobs) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, batchJobStatusComplete, describeJob(hc, jobID).Status)
+
+	_, owner, err := jobOwner(hc.Context())
+	require.NoError(t, err)
+	running := &batchJob{id: "running", owner: owner, request: req, status: batchJobStatusActive, created: time.Now()}
+	interrupted := &batchJob{id: "interrupted", owner: owner, request: req, status: batchJobStatusActive, created: time.Now()}
+	for _, saved := range []struct {
+		job     *batchJob
+		updated time.Time
+	}{
+		{running, time.Now()},
+		{interrupted, time.Now().Add(-batchJobInterruptedTimeout - time.Minute)},
+	} {
+		data, err := json.Marshal(saved.job.state(saved.updated))
+		require.NoError(t, err)
+		require.NoError(t, storage.PutJob(hc.Context(), saved.job.id, owner, data))
+	}
+
+	require.Equal(t, batchJobStatusActive, describeJob(hc, running.id).Status)
+	updateJobStatus(hc, running.id, batchJobStatusCancelled, http.StatusConflict)
+
+	job = describeJob(hc, interrupted.id)
+	require.Equal(t, batchJobStatusFailed, job.Status)
+	require.Len(t, job.FailureReasons, 1)
+	require.Equal(t, "JobInterrupted", job.FailureReasons[0].FailureCode)
+
+	w, r := prepareTestFullRequest(hc, "", "", nil, nil)
+	hc.Handler().ListJobsHandler(w, r)
+	jobs := &ListJobsResult{}
+	readResponse(t, w, http.StatusOK, jobs)
+	require.Len(t, jobs.Jobs, 3)
+}
+
+func TestBatchJobPutObjectACL(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	box, err := layer.GetBoxData(hc.Context())
+	require.NoError(t, err)
+	for _, verb := range []session.ContainerVerb{session.VerbContainerPut, session.VerbContainerSetEACL} {
+		tok := new(session.Container)
+		tok.ForVerb(verb)
+		box.Gate.SessionTokens = append(box.Gate.SessionTokens, tok)
+	}
+
+	bktName := "bucket-for-batch-acl"
+	bktInfo := createBucket(t, hc, bktName, box)
+	putObjectContent(hc, bktName, "obj", "content")
+	putObjectContent(hc, bktName, "manifest.csv", bktName+",obj\n")
+
+	req := &CreateJobRequest{
+		Operation: BatchJobOperation{S3PutObjectACL: &BatchJobACLOperation{CannedAccessControlList: "public-read"}},
+	}
+	req.Manifest.Spec.Format = batchJobManifestFormatCSV
+	req.Manifest.Location.ObjectArn = s3ARNPrefix + bktName + "/manifest.csv"
+
+	jobID := createJob(hc, req)
+	job := waitJobStatus(hc, jobID, batchJobStatusComplete)
+	require.Equal(t, JobProgressSummary{TotalNumberOfTasks: 1, NumberOfTasksSucceeded: 1}, job.ProgressSummary)
+
+	bktACL, err := hc.Layer().GetBucketACL(hc.Context(), bktInfo)
+	require.NoError(t, err)
+
+	var publicRead bool
+	for _, rec := range bktACL.EACL.Records() {
+		if rec.Action() == eacl.ActionAllow && rec.Operation() == eacl.OperationGet && rec.Targets()[0].Role() == eacl.RoleOthers {
+			publicRead = true
+		}
+	}
+	require.True(t, publicRead)
+
+	req.Operation.S3PutObjectACL = &BatchJobACLOperation{}
+	w, r := prepareTestRequest(hc, "", "", req)
+	hc.Handler().CreateJobHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrMalformedXML))
+}
+
+func TestBatchJobUnsupportedOperation(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-batch-unsupported"
+	createTestBucket(hc, bktName)
+	putObjectContent(hc, bktName, "manifest.csv", bktName+",obj\n")
+
+	req := &CreateJobRequest{
+		Operation: BatchJobOperation{
+			S3PutObjectTagging:   &BatchJobTaggingOperation{TagSet: []Tag{{Key: "key", Value: "val"}}},
+			S3PutObjectRetention: &struct{}{},
+		},
+	}
+	req.Manifest.Spec.Format = batchJobManifestFormatCSV
+	req.Manifest.Location.ObjectArn = s3ARNPrefix + bktName + "/manifest.csv"
+
+	w, r := prepareTestRequest(hc, "", "", req)
+	hc.Handler().CreateJobHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrNotImplemented))
+}
+
+type batchJobStorageMock struct {
+	mu     sync.Mutex
+	states map[string][]byte
+	owners map[string]user.ID
+}
+
+func (s *batchJobStorageMock) PutJob(_ context.Context, id string, owner user.ID, state []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[id] = state
+	s.owners[id] = owner
+	return nil
+}
+
+func (s *batchJobStorageMock) JobStates(_ context.Context, id string) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state, ok := s.states[id]; ok {
+		return [][]byte{state}, nil
+	}
+	return nil, nil
+}
+
+func (s *batchJobStorageMock) OwnerJobStates(_ context.Context, owner user.ID) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var res [][]byte
+	for id, state := range s.states {
+		if s.owners[id].Equals(owner) {
+			res = append(res, state)
+		}
+	}
+	return res, nil
+}
+
+func createJob(hc *handlerContext, req *CreateJobRequest) string {
+	w, r := prepareTestRequest(hc, "", "", req)
+	hc.Handler().CreateJobHandler(w, r)
+	res := &CreateJobResult{}
+	readResponse(hc.t, w, http.StatusOK, res)
+	return res.JobID
+}
+
+func describeJob(hc *handlerContext, jobID string) JobDescriptor {
+	w, r := prepareTestRequest(hc, "", "", nil)
+	hc.Handler().DescribeJobHandler(w, mux.SetURLVars(r, map[string]string{batchJobIDVar: jobID}))
+	res := &DescribeJobResult{}
+	readResponse(hc.t, w, http.StatusOK, res)
+	return res.Job
+}
+
+func waitJobStatus(hc *handlerContext, jobID, status string) JobDescriptor {
+	var job JobDescriptor
+	require.Eventually(hc.t, func() bool {
+		job = describeJob(hc, jobID)
+		return job.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func updateJobStatus(hc *handlerContext, jobID, status string, code int) {
+	query := make(url.Values)
+	query.Set("requestedJobStatus", status)
+	w, r := prepareTestFullRequest(hc, "", "", query, nil)
+	hc.Handler().UpdateJobStatusHandler(w, mux.SetURLVars(r, map[string]string{batchJobIDVar: jobID}))
+	assertStatus(hc.t, w, code)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
@@ -26,7 +27,7 @@ const (
 	copyDirective    = "COPY"
 )
 
-var copySourceMatcher = auth.NewRegexpMatcher(regexp.MustCompile(`^/?(?P<bucket_name>[a-z0-9.\-]{3,63})/(?P<object_name>.+)$`))
+var copySourceMatcher = auth.NewRegexpMatcher(regexp.MustCompile(`(?s)^/?(?P<bucket_name>[a-z0-9.\-]{3,63})/(?P<object_name>.+)$`))
 
 // path2BucketObject returns a bucket and an object.
 func path2BucketObject(path string) (string, string, error) {
@@ -38,10 +39,39 @@ func path2BucketObject(path string) (string, string, error) {
 	return matches["bucket_name"], matches["object_name"], nil
 }
 
+// parseCopySource returns a bucket, an object and a version of the x-amz-copy-source header value.
+// The path is URL-encoded by the client and is decoded exactly once, so '+' and encoded '?'
+// and '%' are kept in the object name as is.
+func parseCopySource(src string) (string, string, string, error) {
+	var versionID string
+	if i := strings.Index(src, "?"); i >= 0 {
+		query, err := url.ParseQuery(src[i+1:])
+		if err != nil {
+			return "", "", "", errors.GetAPIError(errors.ErrInvalidCopySource)
+		}
+		versionID = query.Get(api.QueryVersionID)
+		src = src[:i]
+	}
+
+	path, err := url.PathUnescape(src)
+	if err != nil {
+		return "", "", "", errors.GetAPIError(errors.ErrInvalidCopySource)
+	}
+
+	bktName, objName, err := path2BucketObject(path)
+	if err != nil {
+		return "", "", "", err
+	}
+	if err = checkObjectName(objName); err != nil {
+		return "", "", "", err
+	}
+
+	return bktName, objName, versionID, nil
+}
+
 func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 	var (
 		err              error
-		versionID        string
 		metadata         map[string]string
 		tagSet           map[string]string
 		sessionTokenEACL *session.Container
@@ -51,23 +81,27 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		containsACL = containsACLHeaders(r)
 	)
 
-	src := r.Header.Get(api.AmzCopySource)
+	if err = checkObjectName(reqInfo.ObjectName); err != nil {
+		h.logAndSendError(w, "invalid object name", reqInfo, err)
+		return
+	}
+
 	// Check https://docs.aws.amazon.com/AmazonS3/latest/dev/ObjectVersioning.html
 	// Regardless of whether you have enabled versioning, each object in your bucket
 	// has a version ID. If you have not enabled versioning, Amazon S3 sets the value
 	// of the version ID to null. If you have enabled versioning, Amazon S3 assigns a
 	// unique version ID value for the object.
-	if u, err := url.Parse(src); err == nil {
-		versionID = u.Query().Get(api.QueryVersionID)
-		src = u.Path
-	}
-
-	srcBucket, srcObject, err := path2BucketObject(src)
+	srcBucket, srcObject, versionID, err := parseCopySource(r.Header.Get(api.AmzCopySource))
 	if err != nil {
 		h.logAndSendError(w, "invalid source copy", reqInfo, err)
 		return
 	}
 
+	if err = h.checkNotTransformed(srcBucket, srcObject); err != nil {
+		h.logAndSendError(w, "source object can't be copied", reqInfo, err)
+		return
+	}
+
 	srcObjPrm := &layer.HeadObjectParams{
 		Object:    srcObject,
 		VersionID: versionID,
@@ -116,7 +150,10 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if args.MetadataDirective == replaceDirective {
-		metadata = parseMetadata(r)
+		if metadata, err = parseMetadata(r); err != nil {
+			h.logAndSendError(w, "invalid metadata", reqInfo, err)
+			return
+		}
 	}
 
 	if args.TaggingDirective == replaceDirective {
@@ -159,17 +196,20 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if metadata == nil {
+		metadata = make(map[string]string, len(srcObjInfo.Headers)+1)
+		for key, val := range srcObjInfo.Headers {
+			metadata[key] = val
+		}
 		if len(srcObjInfo.ContentType) > 0 {
-			srcObjInfo.Headers[api.ContentType] = srcObjInfo.ContentType
+			metadata[api.ContentType] = srcObjInfo.ContentType
 		}
-		metadata = srcObjInfo.Headers
-	} else if contentType := r.Header.Get(api.ContentType); len(contentType) > 0 {
-		metadata[api.ContentType] = contentType
+	} else {
+		setStandardHeaders(metadata, r.Header)
 	}
 
-	copiesNumber, err := getCopiesNumberOrDefault(metadata, h.cfg.CopiesNumber)
+	copiesNumber, err := h.getCopiesNumber(metadata, r.Header)
 	if err != nil {
-		h.logAndSendError(w, "invalid copies number", reqInfo, err)
+		h.logAndSendError(w, "invalid copies number or storage class", reqInfo, err)
 		return
 	}
 
@@ -182,6 +222,7 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		Header:      metadata,
 		Encryption:  encryptionParams,
 		CopiesNuber: copiesNumber,
+		TagSet:      tagSet,
 	}
 
 	params.Lock, err = formObjectLock(r.Context(), dstBktInfo, settings.LockConfiguration, r.Header)
@@ -198,6 +239,13 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	dstObjInfo := extendedDstObjInfo.ObjectInfo
 
+	if !extendedSrcObjInfo.NodeVersion.IsUnversioned {
+		w.Header().Set(api.AmzCopySourceVersionID, extendedSrcObjInfo.Version())
+	}
+	if settings.VersioningEnabled() {
+		w.Header().Set(api.AmzVersionID, dstObjInfo.VersionID())
+	}
+
 	if err = api.EncodeToResponse(w, &CopyObjectResponse{LastModified: dstObjInfo.Created.UTC().Format(time.RFC3339), ETag: dstObjInfo.HashSum}); err != nil {
 		h.logAndSendError(w, "something went wrong", reqInfo, err, additional...)
 		return
@@ -222,22 +270,6 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if tagSet != nil {
-		tagPrm := &layer.PutObjectTaggingParams{
-			ObjectVersion: &layer.ObjectVersion{
-				BktInfo:    dstBktInfo,
-				ObjectName: reqInfo.ObjectName,
-				VersionID:  dstObjInfo.VersionID(),
-			},
-			TagSet:      tagSet,
-			NodeVersion: extendedDstObjInfo.NodeVersion,
-		}
-		if _, err = h.obj.PutObjectTagging(r.Context(), tagPrm); err != nil {
-			h.logAndSendError(w, "could not upload object tagging", reqInfo, err)
-			return
-		}
-	}
-
 	h.log.Info("object is copied",
 		zap.String("bucket", dstObjInfo.Bucket),
 		zap.String("object", dstObjInfo.Name),
@@ -4,9 +4,12 @@ import (
 	"encoding/xml"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -63,7 +66,29 @@ func TestCopyToItself(t *testing.T) {
 	copyObject(t, tc, bktName, objName, objName, copyMeta, http.StatusOK)
 }
 
-func copyObject(t *testing.T, tc *handlerContext, bktName, fromObject, toObject string, copyMeta CopyMeta, statusCode int) {
+func TestCopyVersionHeaders(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-copy", "object-for-copy"
+	_, objInfo := createVersionedBucketAndObject(t, tc, bktName, objName)
+
+	header := copyObject(t, tc, bktName, objName, "copy", CopyMeta{}, http.StatusOK)
+	require.Equal(t, objInfo.VersionID(), header.Get(api.AmzCopySourceVersionID))
+
+	versions := listVersions(t, tc, bktName)
+	require.Len(t, versions.Version, 2)
+	for _, version := range versions.Version {
+		if version.Key == "copy" {
+			require.Equal(t, version.VersionID, header.Get(api.AmzVersionID))
+		}
+	}
+
+	putBucketVersioning(t, tc, bktName, false)
+	header = copyObject(t, tc, bktName, objName, "copy", CopyMeta{}, http.StatusOK)
+	require.Empty(t, header.Get(api.AmzVersionID))
+}
+
+func copyObject(t *testing.T, tc *handlerContext, bktName, fromObject, toObject string, copyMeta CopyMeta, statusCode int) http.Header {
 	w, r := prepareTestRequest(tc, bktName, toObject, nil)
 	r.Header.Set(api.AmzCopySource, bktName+"/"+fromObject)
 
@@ -81,6 +106,7 @@ func copyObject(t *testing.T, tc *handlerContext, bktName, fromObject, toObject
 
 	tc.Handler().CopyObjectHandler(w, r)
 	assertStatus(t, w, statusCode)
+	return w.Header()
 }
 
 func putObjectTagging(t *testing.T, tc *handlerContext, bktName, objName string, tags map[string]string) {
@@ -185,3 +211,141 @@ func TestSourceCopyRegexp(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCopySource(t *testing.T) {
+	for _, tc := range []struct {
+		src     string
+		err     bool
+		objName string
+		version string
+	}{
+		{src: "bucket/object", objName: "object"},
+		{src: "/bucket/dir/object?versionId=abc", objName: "dir/object", version: "abc"},
+		{src: "bucket/a+b", objName: "a+b"},
+		{src: "bucket/a%2Bb%20c", objName: "a+b c"},
+		{src: "bucket/what%3F?versionId=null", objName: "what?", version: "null"},
+		{src: "bucket/100%25", objName: "100%"},
+		{src: "bucket/%D0%BA%D0%BB%D1%8E%D1%87", objName: "ключ"},
+		{src: "bucket/line%0Abreak%01", objName: "line\nbreak\x01"},
+		{src: "bucket/100%", err: true},
+		{src: "bucket/%FF", err: true},
+		{src: "bucket/object?versionId=%zz", err: true},
+	} {
+		t.Run(tc.src, func(t *testing.T) {
+			bktName, objName, version, err := parseCopySource(tc.src)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "bucket", bktName)
+			require.Equal(t, tc.objName, objName)
+			require.Equal(t, tc.version, version)
+		})
+	}
+}
+
+func TestCopyObjectSpecialKeys(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-special-keys"
+	createTestBucket(tc, bktName)
+
+	for i, objName := range []string{"a+b", "with space", "what?", "100%", "dir/ключ", "tab\tname"} {
+		putObject(t, tc, bktName, objName)
+
+		copyName := "copy-" + strconv.Itoa(i)
+		w, r := prepareTestRequest(tc, bktName, copyName, nil)
+		r.Header.Set(api.AmzCopySource, bktName+"/"+url.PathEscape(objName))
+		tc.Handler().CopyObjectHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+
+		require.Equal(t, []byte("content"), getObject(tc, bktName, objName))
+		checkFound(t, tc, bktName, copyName, emptyVersion)
+	}
+
+	list := listObjectsV1(t, tc, bktName, "", "", "", -1)
+	names := make([]string, 0, len(list.Contents))
+	for _, obj := range list.Contents {
+		names = append(names, obj.Key)
+	}
+	require.Contains(t, names, "a+b")
+	require.Contains(t, names, "with space")
+	require.Contains(t, names, "dir/ключ")
+}
+
+func TestCheckObjectName(t *testing.T) {
+	require.NoError(t, checkObjectName("dir/ключ+ \x01"))
+	require.NoError(t, checkObjectName(strings.Repeat("a", maxObjectNameSize)))
+
+	err := checkObjectName(strings.Repeat("a", maxObjectNameSize+1))
+	require.True(t, errors.IsS3Error(err, errors.ErrKeyTooLongError))
+
+	err = checkObjectName("invalid\xff")
+	require.True(t, errors.IsS3Error(err, errors.ErrInvalidObjectName))
+}
+
+func TestCopyWithMetadataDirective(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-copy-metadata", "object-from-copy"
+	objToCopy, objToCopy2 := "object-to-copy", "object-to-copy-2"
+	createTestBucket(tc, bktName)
+
+	w, r := prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.MetadataPrefix+"key", "val")
+	r.Header.Set(api.CacheControl, "no-cache")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	copyMeta := CopyMeta{
+		Metadata: map[string]string{"key2": "val"},
+	}
+	copyObject(t, tc, bktName, objName, objToCopy, copyMeta, http.StatusOK)
+	header := headObjectHeaders(t, tc, bktName, objToCopy)
+	require.Equal(t, []string{"val"}, header[api.MetadataPrefix+"key"])
+	require.Empty(t, header[api.MetadataPrefix+"key2"])
+	require.Equal(t, "no-cache", header.Get(api.CacheControl))
+
+	copyMeta.MetadataDirective = replaceDirective
+	copyObject(t, tc, bktName, objName, objToCopy2, copyMeta, http.StatusOK)
+	header = headObjectHeaders(t, tc, bktName, objToCopy2)
+	require.Empty(t, header[api.MetadataPrefix+"key"])
+	require.Equal(t, []string{"val"}, header[api.MetadataPrefix+"key2"])
+	require.Empty(t, header.Get(api.CacheControl))
+
+	copyObject(t, tc, bktName, objName, objToCopy2, CopyMeta{MetadataDirective: "invalid"}, http.StatusBadRequest)
+}
+
+func TestCopyWithConditions(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName, objToCopy := "bucket-for-copy-conditions", "object-from-copy", "object-to-copy"
+	createTestBucket(tc, bktName)
+	putObject(t, tc, bktName, objName)
+	etag := headObjectHeaders(t, tc, bktName, objName).Get(api.ETag)
+
+	for _, tc2 := range []struct {
+		header, value string
+		status        int
+	}{
+		{header: api.AmzCopyIfMatch, value: etag, status: http.StatusOK},
+		{header: api.AmzCopyIfMatch, value: "other", status: http.StatusPreconditionFailed},
+		{header: api.AmzCopyIfNoneMatch, value: etag, status: http.StatusPreconditionFailed},
+		{header: api.AmzCopyIfNoneMatch, value: "other", status: http.StatusOK},
+	} {
+		w, r := prepareTestRequest(tc, bktName, objToCopy, nil)
+		r.Header.Set(api.AmzCopySource, bktName+"/"+objName)
+		r.Header.Set(tc2.header, tc2.value)
+		tc.Handler().CopyObjectHandler(w, r)
+		assertStatus(t, w, tc2.status)
+	}
+}
+
+func headObjectHeaders(t *testing.T, tc *handlerContext, bktName, objName string) http.Header {
+	w, r := prepareTestRequest(tc, bktName, objName, nil)
+	tc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	return w.Header()
+}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	"go.uber.org/zap"
@@ -17,6 +20,37 @@ const (
 	wildcard      = "*"
 )
 
+// DefaultCORS is CORS configuration applied to buckets without their own configuration.
+// It can be updated at runtime.
+type DefaultCORS struct {
+	mu   sync.RWMutex
+	cors *data.CORSConfiguration
+}
+
+// NewDefaultCORS creates default CORS configuration. Nil cors means buckets without
+// CORS configuration reject cross-origin requests.
+func NewDefaultCORS(cors *data.CORSConfiguration) *DefaultCORS {
+	return &DefaultCORS{cors: cors}
+}
+
+// Update replaces default CORS configuration.
+func (d *DefaultCORS) Update(cors *data.CORSConfiguration) {
+	d.mu.Lock()
+	d.cors = cors
+	d.mu.Unlock()
+}
+
+// Get returns default CORS configuration, nil if it isn't set.
+func (d *DefaultCORS) Get() *data.CORSConfiguration {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.cors
+}
+
 func (h *handler) GetBucketCorsHandler(w http.ResponseWriter, r *http.Request) {
 	reqInfo := api.GetReqInfo(r.Context())
 
@@ -95,7 +129,7 @@ func (h *handler) AppendCORSHeaders(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cors, err := h.obj.GetBucketCORS(r.Context(), bktInfo)
+	cors, err := h.bucketCORS(r.Context(), bktInfo)
 	if err != nil {
 		h.log.Warn("get bucket cors", zap.Error(err))
 		return
@@ -105,32 +139,15 @@ func (h *handler) AppendCORSHeaders(w http.ResponseWriter, r *http.Request) {
 
 	for _, rule := range cors.CORSRules {
 		for _, o := range rule.AllowedOrigins {
-			if o == origin {
-				for _, m := range rule.AllowedMethods {
-					if m == r.Method {
-						w.Header().Set(api.AccessControlAllowOrigin, origin)
-						w.Header().Set(api.AccessControlAllowMethods, strings.Join(rule.AllowedMethods, ", "))
-						w.Header().Set(api.AccessControlAllowCredentials, "true")
-						w.Header().Set(api.Vary, api.Origin)
-						return
-					}
-				}
+			if !matchWildcard(o, origin) || !sliceContains(rule.AllowedMethods, r.Method) {
+				continue
 			}
-			if o == wildcard {
-				for _, m := range rule.AllowedMethods {
-					if m == r.Method {
-						if withCredentials {
-							w.Header().Set(api.AccessControlAllowOrigin, origin)
-							w.Header().Set(api.AccessControlAllowCredentials, "true")
-							w.Header().Set(api.Vary, api.Origin)
-						} else {
-							w.Header().Set(api.AccessControlAllowOrigin, o)
-						}
-						w.Header().Set(api.AccessControlAllowMethods, strings.Join(rule.AllowedMethods, ", "))
-						return
-					}
-				}
+			setAllowOrigin(w, o, origin, withCredentials)
+			setRuleHeaders(w, rule)
+			if rule.MaxAgeSeconds != nil {
+				w.Header().Set(api.AccessControlMaxAge, strconv.Itoa(*rule.MaxAgeSeconds))
 			}
+			return
 		}
 	}
 }
@@ -146,6 +163,7 @@ func (h *handler) Preflight(w http.ResponseWriter, r *http.Request) {
 	origin := r.Header.Get(api.Origin)
 	if origin == "" {
 		h.logAndSendError(w, "origin request header needed", reqInfo, errors.GetAPIError(errors.ErrBadRequest))
+		return
 	}
 
 	method := r.Header.Get(api.AccessControlRequestMethod)
@@ -154,13 +172,15 @@ func (h *handler) Preflight(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var headers []string
-	requestHeaders := r.Header.Get(api.AccessControlRequestHeaders)
-	if requestHeaders != "" {
-		headers = strings.Split(requestHeaders, ", ")
+	headers := parseRequestHeaders(r.Header.Values(api.AccessControlRequestHeaders))
+
+	// the actual request is signed, so it's answered with the origin as if it had credentials
+	var withCredentials bool
+	for _, header := range headers {
+		withCredentials = withCredentials || header == strings.ToLower(api.Authorization)
 	}
 
-	cors, err := h.obj.GetBucketCORS(r.Context(), bktInfo)
+	cors, err := h.bucketCORS(r.Context(), bktInfo)
 	if err != nil {
 		h.logAndSendError(w, "could not get cors", reqInfo, err)
 		return
@@ -168,53 +188,101 @@ func (h *handler) Preflight(w http.ResponseWriter, r *http.Request) {
 
 	for _, rule := range cors.CORSRules {
 		for _, o := range rule.AllowedOrigins {
-			if o == origin || o == wildcard {
-				for _, m := range rule.AllowedMethods {
-					if m == method {
-						if !checkSubslice(rule.AllowedHeaders, headers) {
-							continue
-						}
-						w.Header().Set(api.AccessControlAllowOrigin, o)
-						w.Header().Set(api.AccessControlAllowMethods, strings.Join(rule.AllowedMethods, ", "))
-						if headers != nil {
-							w.Header().Set(api.AccessControlAllowHeaders, requestHeaders)
-						}
-						if rule.ExposeHeaders != nil {
-							w.Header().Set(api.AccessControlExposeHeaders, strings.Join(rule.ExposeHeaders, ", "))
-						}
-						if rule.MaxAgeSeconds > 0 || rule.MaxAgeSeconds == -1 {
-							w.Header().Set(api.AccessControlMaxAge, strconv.Itoa(rule.MaxAgeSeconds))
-						} else {
-							w.Header().Set(api.AccessControlMaxAge, strconv.Itoa(h.cfg.DefaultMaxAge))
-						}
-						if o != wildcard {
-							w.Header().Set(api.AccessControlAllowCredentials, "true")
-						}
-						api.WriteSuccessResponseHeadersOnly(w)
-						return
-					}
-				}
+			if !matchWildcard(o, origin) || !sliceContains(rule.AllowedMethods, method) || !headersAllowed(rule.AllowedHeaders, headers) {
+				continue
 			}
+			setAllowOrigin(w, o, origin, withCredentials)
+			setRuleHeaders(w, rule)
+			if len(headers) != 0 {
+				w.Header().Set(api.AccessControlAllowHeaders, strings.Join(headers, ", "))
+			}
+			if rule.MaxAgeSeconds != nil {
+				w.Header().Set(api.AccessControlMaxAge, strconv.Itoa(*rule.MaxAgeSeconds))
+			} else {
+				w.Header().Set(api.AccessControlMaxAge, strconv.Itoa(h.cfg.DefaultMaxAge))
+			}
+			api.WriteSuccessResponseHeadersOnly(w)
+			return
 		}
 	}
 	h.logAndSendError(w, "Forbidden", reqInfo, errors.GetAPIError(errors.ErrAccessDenied))
 }
 
-func checkSubslice(slice []string, subSlice []string) bool {
-	if sliceContains(slice, wildcard) {
-		return true
+// bucketCORS returns CORS configuration of the bucket or the default configuration
+// of the gateway if the bucket has no CORS configuration.
+func (h *handler) bucketCORS(ctx context.Context, bktInfo *data.BucketInfo) (*data.CORSConfiguration, error) {
+	cors, err := h.obj.GetBucketCORS(ctx, bktInfo)
+	if err != nil && errors.IsS3Error(err, errors.ErrNoSuchCORSConfiguration) {
+		if defaultCORS := h.cfg.DefaultCORS.Get(); defaultCORS != nil {
+			return defaultCORS, nil
+		}
 	}
-	if len(subSlice) > len(slice) {
-		return false
+	return cors, err
+}
+
+// matchWildcard checks the value against the allowed origin or header of a CORS rule.
+// The pattern can contain one wildcard, e.g. "https://*.example.com" or "x-amz-*".
+func matchWildcard(pattern, value string) bool {
+	i := strings.Index(pattern, wildcard)
+	if i < 0 {
+		return pattern == value
 	}
-	for _, r := range subSlice {
-		if !sliceContains(slice, r) {
+
+	prefix, suffix := pattern[:i], pattern[i+1:]
+	return len(value) >= len(prefix)+len(suffix) && strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix)
+}
+
+// parseRequestHeaders returns lowercased names of the comma-separated Access-Control-Request-Headers values.
+func parseRequestHeaders(values []string) []string {
+	var headers []string
+	for _, value := range values {
+		for _, header := range strings.Split(value, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				headers = append(headers, strings.ToLower(header))
+			}
+		}
+	}
+	return headers
+}
+
+// headersAllowed checks that each of the lowercased request headers matches one of the allowed headers.
+// Header names are case-insensitive, so are the allowed headers.
+func headersAllowed(allowed []string, headers []string) bool {
+	for _, header := range headers {
+		var ok bool
+		for _, a := range allowed {
+			if ok = matchWildcard(strings.ToLower(a), header); ok {
+				break
+			}
+		}
+		if !ok {
 			return false
 		}
 	}
 	return true
 }
 
+// setRuleHeaders sets allowed methods and exposed headers of the matched CORS rule.
+func setRuleHeaders(w http.ResponseWriter, rule data.CORSRule) {
+	w.Header().Set(api.AccessControlAllowMethods, strings.Join(rule.AllowedMethods, ", "))
+	if len(rule.ExposeHeaders) != 0 {
+		w.Header().Set(api.AccessControlExposeHeaders, strings.Join(rule.ExposeHeaders, ", "))
+	}
+}
+
+// setAllowOrigin sets the allowed origin of the response. Any origin is allowed by the sole
+// wildcard, unless the request has credentials which require the exact origin to be returned.
+func setAllowOrigin(w http.ResponseWriter, allowed, origin string, withCredentials bool) {
+	if allowed == wildcard && !withCredentials {
+		w.Header().Set(api.AccessControlAllowOrigin, wildcard)
+		return
+	}
+
+	w.Header().Set(api.AccessControlAllowOrigin, origin)
+	w.Header().Set(api.AccessControlAllowCredentials, "true")
+	w.Header().Set(api.Vary, api.Origin)
+}
+
 func sliceContains(slice []string, str string) bool {
 	for _, s := range slice {
 		if s == str {
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchWildcard(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		value   string
+		matches bool
+	}{
+		{pattern: "*", value: "https://example.com", matches: true},
+		{pattern: "https://example.com", value: "https://example.com", matches: true},
+		{pattern: "https://example.com", value: "http://example.com"},
+		{pattern: "https://*.example.com", value: "https://www.example.com", matches: true},
+		{pattern: "https://*.example.com", value: "https://example.com"},
+		{pattern: "https://*.example.com", value: "https://www.example.org"},
+		{pattern: "http://localhost:*", value: "http://localhost:8080", matches: true},
+		{pattern: "x-amz-*", value: "x-amz-date", matches: true},
+		{pattern: "x-amz-*", value: "content-type"},
+	} {
+		require.Equal(t, tc.matches, matchWildcard(tc.pattern, tc.value), "%s %s", tc.pattern, tc.value)
+	}
+}
+
+func TestHeadersAllowed(t *testing.T) {
+	headers := parseRequestHeaders([]string{"X-Amz-Date, content-type", " x-amz-content-sha256 ,"})
+	require.Equal(t, []string{"x-amz-date", "content-type", "x-amz-content-sha256"}, headers)
+
+	require.True(t, headersAllowed([]string{"*"}, headers))
+	require.True(t, headersAllowed([]string{"X-Amz-*", "Content-Type"}, headers))
+	require.False(t, headersAllowed([]string{"x-amz-*"}, headers))
+	require.False(t, headersAllowed(nil, headers))
+	require.True(t, headersAllowed(nil, nil))
+}
+
+func TestCORSRuleHeaders(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-cors-headers"
+	createTestBucket(hc, bktName)
+
+	maxAge := 0
+	hc.h.cfg.DefaultCORS = NewDefaultCORS(&data.CORSConfiguration{CORSRules: []data.CORSRule{{
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPut},
+		AllowedHeaders: []string{"x-amz-*"},
+		ExposeHeaders:  []string{"ETag", "x-amz-version-id"},
+		MaxAgeSeconds:  &maxAge,
+	}}})
+
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	r.Method = http.MethodOptions
+	r.Header.Set(api.Origin, "https://example.com")
+	r.Header.Set(api.AccessControlRequestMethod, http.MethodPut)
+	r.Header.Set(api.AccessControlRequestHeaders, "X-Amz-Date,X-Amz-Content-Sha256")
+	hc.Handler().Preflight(w, r)
+	resp := w.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "x-amz-date, x-amz-content-sha256", resp.Header.Get(api.AccessControlAllowHeaders))
+	require.Equal(t, "ETag, x-amz-version-id", resp.Header.Get(api.AccessControlExposeHeaders))
+	require.Equal(t, "0", resp.Header.Get(api.AccessControlMaxAge))
+
+	w, r = prepareTestRequest(hc, bktName, "", nil)
+	r.Method = http.MethodOptions
+	r.Header.Set(api.Origin, "https://example.com")
+	r.Header.Set(api.AccessControlRequestMethod, http.MethodPut)
+	r.Header.Set(api.AccessControlRequestHeaders, "content-md5")
+	hc.Handler().Preflight(w, r)
+	require.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+
+	w, r = prepareTestRequest(hc, bktName, "", nil)
+	r.Method = http.MethodGet
+	r.Header.Set(api.Origin, "https://example.com")
+	hc.Handler().AppendCORSHeaders(w, r)
+	resp = w.Result()
+	require.Equal(t, "https://example.com", resp.Header.Get(api.AccessControlAllowOrigin))
+	require.Equal(t, "GET, PUT", resp.Header.Get(api.AccessControlAllowMethods))
+	require.Equal(t, "ETag, x-amz-version-id", resp.Header.Get(api.AccessControlExposeHeaders))
+	require.Equal(t, "0", resp.Header.Get(api.AccessControlMaxAge))
+}
+
+func TestPreflightDefaultCORS(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-default-cors"
+	createTestBucket(hc, bktName)
+
+	preflight := func(origin, method, headers string) *http.Response {
+		w, r := prepareTestRequest(hc, bktName, "", nil)
+		r.Method = http.MethodOptions
+		r.Header.Set(api.Origin, origin)
+		r.Header.Set(api.AccessControlRequestMethod, method)
+		if headers != "" {
+			r.Header.Set(api.AccessControlRequestHeaders, headers)
+		}
+		hc.Handler().Preflight(w, r)
+		return w.Result()
+	}
+
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	r.Method = http.MethodOptions
+	r.Header.Set(api.Origin, "https://example.com")
+	r.Header.Set(api.AccessControlRequestMethod, http.MethodGet)
+	hc.Handler().Preflight(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrNoSuchCORSConfiguration))
+
+	hc.h.cfg.DefaultCORS = NewDefaultCORS(&data.CORSConfiguration{CORSRules: []data.CORSRule{
+		{AllowedOrigins: []string{"https://*.example.com"}, AllowedMethods: []string{http.MethodPut}},
+		{AllowedOrigins: []string{"*"}, AllowedMethods: []string{http.MethodGet}, AllowedHeaders: []string{"*"}},
+	}})
+
+	resp := preflight("https://example.org", http.MethodGet, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "*", resp.Header.Get(api.AccessControlAllowOrigin))
+	require.Empty(t, resp.Header.Get(api.AccessControlAllowCredentials))
+
+	resp = preflight("https://example.org", http.MethodGet, "authorization, x-amz-date")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "https://example.org", resp.Header.Get(api.AccessControlAllowOrigin))
+	require.Equal(t, "true", resp.Header.Get(api.AccessControlAllowCredentials))
+	require.Equal(t, api.Origin, resp.Header.Get(api.Vary))
+
+	resp = preflight("https://www.example.com", http.MethodPut, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "https://www.example.com", resp.Header.Get(api.AccessControlAllowOrigin))
+
+	resp = preflight("https://www.example.org", http.MethodPut, "")
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// default configuration is reloaded at runtime
+	hc.h.cfg.DefaultCORS.Update(&data.CORSConfiguration{CORSRules: []data.CORSRule{
+		{AllowedOrigins: []string{"https://www.example.org"}, AllowedMethods: []string{http.MethodPut}},
+	}})
+	resp = preflight("https://www.example.org", http.MethodPut, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	hc.h.cfg.DefaultCORS.Update(nil)
+	w, r = prepareTestRequest(hc, bktName, "", nil)
+	r.Method = http.MethodOptions
+	r.Header.Set(api.Origin, "https://www.example.org")
+	r.Header.Set(api.AccessControlRequestMethod, http.MethodPut)
+	hc.Handler().Preflight(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrNoSuchCORSConfiguration))
+}
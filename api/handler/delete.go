@@ -11,12 +11,14 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
-	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// maxObjectsToDelete is the maximum number of keys in a single DeleteObjects request.
+const maxObjectsToDelete = 1000
+
 // DeleteObjectsRequest -- xml carrying the object key names which should be deleted.
 type DeleteObjectsRequest struct {
 	// Element to enable quiet mode for the request
@@ -77,6 +79,13 @@ func (h *handler) DeleteObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if bktSettings.MFADelete && len(versionID) != 0 {
+		if err = h.checkMFA(r); err != nil {
+			h.logAndSendError(w, "mfa authentication failed", reqInfo, err)
+			return
+		}
+	}
+
 	p := &layer.DeleteObjectParams{
 		BktInfo:  bktInfo,
 		Objects:  versionedObject,
@@ -93,37 +102,7 @@ func (h *handler) DeleteObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var m *SendNotificationParams
-
-	if bktSettings.VersioningEnabled() && len(versionID) == 0 {
-		m = &SendNotificationParams{
-			Event: EventObjectRemovedDeleteMarkerCreated,
-			NotificationInfo: &data.NotificationInfo{
-				Name:    reqInfo.ObjectName,
-				HashSum: deletedObject.DeleteMarkerEtag,
-			},
-			BktInfo: bktInfo,
-			ReqInfo: reqInfo,
-		}
-	} else {
-		var objID oid.ID
-		if len(versionID) != 0 {
-			if err = objID.DecodeString(versionID); err != nil {
-				h.log.Error("couldn't send notification: %w", zap.Error(err))
-			}
-		}
-
-		m = &SendNotificationParams{
-			Event: EventObjectRemovedDelete,
-			NotificationInfo: &data.NotificationInfo{
-				Name:    reqInfo.ObjectName,
-				Version: objID.EncodeToString(),
-			},
-			BktInfo: bktInfo,
-			ReqInfo: reqInfo,
-		}
-	}
-
+	m := removedObjectNotification(bktInfo, reqInfo, bktSettings, versionID, deletedObject)
 	if err = h.sendNotifications(r.Context(), m); err != nil {
 		h.log.Error("couldn't send notification: %w", zap.Error(err))
 	}
@@ -151,6 +130,29 @@ func isErrObjectLocked(err error) bool {
 	}
 }
 
+// removedObjectNotification makes notification about the deleted object. Deletion creates a delete marker
+// if the version isn't specified and versioning of the bucket is enabled or suspended.
+func removedObjectNotification(bktInfo *data.BucketInfo, reqInfo *api.ReqInfo, settings *data.BucketSettings,
+	versionID string, obj *layer.VersionedObject) *SendNotificationParams {
+	p := &SendNotificationParams{
+		Event: EventObjectRemovedDelete,
+		NotificationInfo: &data.NotificationInfo{
+			Name:    obj.Name,
+			Version: versionID,
+		},
+		BktInfo: bktInfo,
+		ReqInfo: reqInfo,
+	}
+
+	if len(versionID) == 0 && !settings.Unversioned() {
+		p.Event = EventObjectRemovedDeleteMarkerCreated
+		p.NotificationInfo.Version = obj.DeleteMarkVersion
+		p.NotificationInfo.HashSum = obj.DeleteMarkerEtag
+	}
+
+	return p
+}
+
 // DeleteMultipleObjectsHandler handles multiple delete requests.
 func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Request) {
 	reqInfo := api.GetReqInfo(r.Context())
@@ -176,6 +178,32 @@ func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if len(requested.Objects) == 0 || len(requested.Objects) > maxObjectsToDelete {
+		h.logAndSendError(w, "number of objects to delete must be greater than 0 and less or equal to 1000", reqInfo, errors.GetAPIError(errors.ErrMalformedXML))
+		return
+	}
+
+	response := &DeleteObjectsResponse{
+		Errors:         make([]DeleteError, 0, len(requested.Objects)),
+		DeletedObjects: make([]DeletedObject, 0, len(requested.Objects)),
+	}
+
+	allowed := requested.Objects[:0]
+	for _, obj := range requested.Objects {
+		if !isAllowedByPolicy(r.Context(), "s3:DeleteObject", reqInfo.BucketName, obj.ObjectName) {
+			accessDenied := errors.GetAPIError(errors.ErrAccessDenied)
+			response.Errors = append(response.Errors, DeleteError{
+				Code:      accessDenied.Code,
+				Message:   accessDenied.Error(),
+				Key:       obj.ObjectName,
+				VersionID: obj.VersionID,
+			})
+			continue
+		}
+		allowed = append(allowed, obj)
+	}
+	requested.Objects = allowed
+
 	removed := make(map[string]*layer.VersionedObject)
 	toRemove := make([]*layer.VersionedObject, 0, len(requested.Objects))
 	for _, obj := range requested.Objects {
@@ -187,11 +215,6 @@ func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Re
 		removed[versionedObj.String()] = versionedObj
 	}
 
-	response := &DeleteObjectsResponse{
-		Errors:         make([]DeleteError, 0, len(toRemove)),
-		DeletedObjects: make([]DeletedObject, 0, len(toRemove)),
-	}
-
 	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
 	if err != nil {
 		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
@@ -204,6 +227,19 @@ func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if bktSettings.MFADelete {
+		for _, obj := range toRemove {
+			if len(obj.VersionID) == 0 {
+				continue
+			}
+			if err = h.checkMFA(r); err != nil {
+				h.logAndSendError(w, "mfa authentication failed", reqInfo, err)
+				return
+			}
+			break
+		}
+	}
+
 	marshaler := zapcore.ArrayMarshalerFunc(func(encoder zapcore.ArrayEncoder) error {
 		for _, obj := range toRemove {
 			encoder.AppendString(obj.String())
@@ -219,7 +255,7 @@ func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Re
 	deletedObjects := h.obj.DeleteObjects(r.Context(), p)
 
 	var errs []error
-	for _, obj := range deletedObjects {
+	for i, obj := range deletedObjects {
 		if obj.Error != nil {
 			code := "BadRequest"
 			if s3err, ok := obj.Error.(errors.Error); ok {
@@ -232,7 +268,15 @@ func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Re
 				VersionID: obj.VersionID,
 			})
 			errs = append(errs, obj.Error)
-		} else if !requested.Quiet {
+			continue
+		}
+
+		m := removedObjectNotification(bktInfo, reqInfo, bktSettings, requested.Objects[i].VersionID, obj)
+		if err = h.sendNotifications(r.Context(), m); err != nil {
+			h.log.Error("couldn't send notification", zap.String("object", obj.Name), zap.Error(err))
+		}
+
+		if !requested.Quiet {
 			deletedObj := DeletedObject{
 				ObjectIdentifier: ObjectIdentifier{
 					ObjectName: obj.Name,
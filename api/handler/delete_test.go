@@ -3,11 +3,15 @@ package handler
 import (
 	"bytes"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
 	"github.com/stretchr/testify/require"
 )
 
@@ -203,6 +207,32 @@ func TestDeleteMarkers(t *testing.T) {
 	require.Len(t, listOIDsFromMockedNeoFS(t, tc, bktName), 0, "shouldn't be any object in neofs")
 }
 
+func TestDeleteMarkerHeaders(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-removal", "object-to-delete"
+	_, objInfo := createVersionedBucketAndObject(t, tc, bktName, objName)
+
+	deleteMarkerVersion, isDeleteMarker := deleteObject(t, tc, bktName, objName, emptyVersion)
+	require.True(t, isDeleteMarker)
+
+	for version, status := range map[string]int{
+		emptyVersion:        http.StatusNotFound,
+		deleteMarkerVersion: http.StatusMethodNotAllowed,
+	} {
+		query := make(url.Values)
+		query.Add(api.QueryVersionID, version)
+
+		w, r := prepareTestFullRequest(tc, bktName, objName, query, nil)
+		tc.Handler().GetObjectHandler(w, r)
+		assertStatus(t, w, status)
+		require.Equal(t, "true", w.Header().Get(api.AmzDeleteMarker))
+		require.Equal(t, deleteMarkerVersion, w.Header().Get(api.AmzVersionID))
+	}
+
+	checkFound(t, tc, bktName, objName, objInfo.VersionID())
+}
+
 func TestDeleteObjectFromListCache(t *testing.T) {
 	tc := prepareHandlerContext(t)
 
@@ -243,6 +273,39 @@ func TestDeleteObjectCheckMarkerReturn(t *testing.T) {
 	require.Equal(t, deleteMarkerVersion, deleteMarkerVersion2)
 }
 
+func TestDeleteObjects(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-multi-delete"
+	createTestBucket(tc, bktName)
+
+	objects := []string{"obj1", "obj2", "obj3"}
+	for _, objName := range objects {
+		putObject(t, tc, bktName, objName)
+	}
+
+	resp := deleteObjects(t, tc, bktName, objects[:2], false)
+	require.Empty(t, resp.Errors)
+	require.Len(t, resp.DeletedObjects, 2)
+	require.Equal(t, objects[0], resp.DeletedObjects[0].ObjectName)
+	require.Equal(t, objects[1], resp.DeletedObjects[1].ObjectName)
+	checkNotFound(t, tc, bktName, objects[0], emptyVersion)
+	checkNotFound(t, tc, bktName, objects[1], emptyVersion)
+
+	resp = deleteObjects(t, tc, bktName, objects[2:], true)
+	require.Empty(t, resp.Errors)
+	require.Empty(t, resp.DeletedObjects)
+	checkNotFound(t, tc, bktName, objects[2], emptyVersion)
+
+	tooMany := make([]string, maxObjectsToDelete+1)
+	for i := range tooMany {
+		tooMany[i] = "obj" + strconv.Itoa(i)
+	}
+	w, r := prepareDeleteObjectsRequest(t, tc, bktName, tooMany, false)
+	tc.Handler().DeleteMultipleObjectsHandler(w, r)
+	assertStatus(t, w, http.StatusBadRequest)
+}
+
 func createBucketAndObject(tc *handlerContext, bktName, objName string) (*data.BucketInfo, *data.ObjectInfo) {
 	bktInfo := createTestBucket(tc, bktName)
 
@@ -330,3 +393,59 @@ func createSuspendedBucket(t *testing.T, tc *handlerContext, bktName string) *da
 	putBucketVersioning(t, tc, bktName, false)
 	return bktInfo
 }
+
+func TestDeleteObjectsInlinePolicy(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-removal-policy"
+	createTestBucket(tc, bktName)
+
+	box, err := layer.GetBoxData(tc.Context())
+	require.NoError(t, err)
+	setPolicy := func(resource string) {
+		box.InlinePolicy, err = accessbox.ParseInlinePolicy([]byte(`{"Statement": [
+			{"Effect": "Allow", "Action": "s3:DeleteObject", "Resource": "` + resource + `"}
+		]}`))
+		require.NoError(t, err)
+	}
+
+	putObjectContent(tc, bktName, "prefix/obj", "content")
+	putObjectContent(tc, bktName, "other/obj", "content")
+
+	setPolicy("arn:aws:s3:::" + bktName)
+	resp := deleteObjects(t, tc, bktName, []string{"prefix/obj", "other/obj"}, false)
+	require.Empty(t, resp.DeletedObjects)
+	require.Len(t, resp.Errors, 2)
+	checkFound(t, tc, bktName, "prefix/obj", emptyVersion)
+	checkFound(t, tc, bktName, "other/obj", emptyVersion)
+
+	setPolicy("arn:aws:s3:::" + bktName + "/prefix/*")
+	resp = deleteObjects(t, tc, bktName, []string{"prefix/obj", "other/obj"}, false)
+	require.Len(t, resp.DeletedObjects, 1)
+	require.Equal(t, "prefix/obj", resp.DeletedObjects[0].ObjectName)
+	require.Len(t, resp.Errors, 1)
+	require.Equal(t, "other/obj", resp.Errors[0].Key)
+	require.Equal(t, "AccessDenied", resp.Errors[0].Code)
+	checkNotFound(t, tc, bktName, "prefix/obj", emptyVersion)
+	checkFound(t, tc, bktName, "other/obj", emptyVersion)
+}
+
+func deleteObjects(t *testing.T, tc *handlerContext, bktName string, objects []string, quiet bool) *DeleteObjectsResponse {
+	w, r := prepareDeleteObjectsRequest(t, tc, bktName, objects, quiet)
+	tc.Handler().DeleteMultipleObjectsHandler(w, r)
+
+	resp := &DeleteObjectsResponse{}
+	readResponse(t, w, http.StatusOK, resp)
+	return resp
+}
+
+func prepareDeleteObjectsRequest(t *testing.T, tc *handlerContext, bktName string, objects []string, quiet bool) (*httptest.ResponseRecorder, *http.Request) {
+	req := &DeleteObjectsRequest{Quiet: quiet}
+	for _, objName := range objects {
+		req.Objects = append(req.Objects, ObjectIdentifier{ObjectName: objName})
+	}
+
+	w, r := prepareTestFullRequest(tc, bktName, "", make(url.Values), req)
+	r.Header.Set(api.ContentMD5, "")
+	return w, r
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -92,7 +93,9 @@ func writeHeaders(h http.Header, requestHeader http.Header, extendedInfo *data.E
 	}
 
 	h.Set(api.ETag, info.HashSum)
-	h.Set(api.AmzTaggingCount, strconv.Itoa(tagSetLength))
+	if tagSetLength > 0 {
+		h.Set(api.AmzTaggingCount, strconv.Itoa(tagSetLength))
+	}
 
 	if !isBucketUnversioned {
 		h.Set(api.AmzVersionID, extendedInfo.Version())
@@ -104,13 +107,37 @@ func writeHeaders(h http.Header, requestHeader http.Header, extendedInfo *data.E
 	if expires := info.Headers[api.Expires]; expires != "" {
 		h.Set(api.Expires, expires)
 	}
+	if storageClass := info.Headers[layer.AttributeStorageClass]; storageClass != "" {
+		h.Set(api.AmzStorageClass, storageClass)
+	}
 
 	for key, val := range info.Headers {
 		if layer.IsSystemHeader(key) {
 			continue
 		}
-		h[api.MetadataPrefix+key] = []string{val}
+		// keys are lowercase unless the object is put by an old gateway or without the gateway
+		h[api.MetadataPrefix+strings.ToLower(key)] = []string{val}
+	}
+}
+
+// setDeleteMarkerHeaders sets delete marker headers if the object isn't found
+// because the requested version (the latest one by default) is a delete marker.
+func (h *handler) setDeleteMarkerHeaders(ctx context.Context, header http.Header, p *layer.HeadObjectParams, err error) {
+	if !errors.IsS3Error(err, errors.ErrNoSuchKey) && !errors.IsS3Error(err, errors.ErrMethodNotAllowed) {
+		return
+	}
+
+	node, err := h.obj.GetDeleteMarker(ctx, p)
+	if err != nil {
+		return
+	}
+
+	versionID := node.OID.EncodeToString()
+	if node.IsUnversioned {
+		versionID = data.UnversionedObjectVersionID
 	}
+	header.Set(api.AmzDeleteMarker, strconv.FormatBool(true))
+	header.Set(api.AmzVersionID, versionID)
 }
 
 func (h *handler) GetObjectHandler(w http.ResponseWriter, r *http.Request) {
@@ -140,11 +167,20 @@ func (h *handler) GetObjectHandler(w http.ResponseWriter, r *http.Request) {
 
 	extendedInfo, err := h.obj.GetExtendedObjectInfo(r.Context(), p)
 	if err != nil {
+		h.setDeleteMarkerHeaders(r.Context(), w.Header(), p, err)
 		h.logAndSendError(w, "could not find object", reqInfo, err)
 		return
 	}
 	info := extendedInfo.ObjectInfo
 
+	transformer := h.objectTransformer(bktInfo.Name, info.Name)
+	if transformer != nil {
+		if err = checkTransformedConditions(conditional); err != nil {
+			h.logAndSendError(w, "unsupported conditions for transformed object", reqInfo, err)
+			return
+		}
+	}
+
 	if err = checkPreconditions(info, conditional); err != nil {
 		h.logAndSendError(w, "precondition failed", reqInfo, err)
 		return
@@ -169,9 +205,12 @@ func (h *handler) GetObjectHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if params, err = fetchRangeHeader(r.Header, uint64(fullSize)); err != nil {
-		h.logAndSendError(w, "could not parse range header", reqInfo, err)
-		return
+	// stale If-Range means the client must get the full object instead of a partial one
+	if ifRangeMatches(r.Header.Get(api.IfRange), info) {
+		if params, err = fetchRangeHeader(r.Header, uint64(fullSize)); err != nil {
+			h.logAndSendError(w, "could not parse range header", reqInfo, err)
+			return
+		}
 	}
 
 	t := &layer.ObjectVersion{
@@ -201,13 +240,6 @@ func (h *handler) GetObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeHeaders(w.Header(), r.Header, extendedInfo, len(tagSet), bktSettings.Unversioned())
-	if params != nil {
-		writeRangeHeaders(w, params, info.Size)
-	} else {
-		w.WriteHeader(http.StatusOK)
-	}
-
 	getParams := &layer.GetObjectParams{
 		ObjectInfo: info,
 		Writer:     w,
@@ -215,6 +247,25 @@ func (h *handler) GetObjectHandler(w http.ResponseWriter, r *http.Request) {
 		BucketInfo: bktInfo,
 		Encryption: encryptionParams,
 	}
+
+	if transformer != nil {
+		if params != nil {
+			h.logAndSendError(w, "range requests of transformed objects aren't supported", reqInfo, errors.GetAPIError(errors.ErrNotImplemented))
+			return
+		}
+		h.getTransformedObject(w, r, transformer, getParams, func(header http.Header) {
+			writeHeaders(header, r.Header, extendedInfo, len(tagSet), bktSettings.Unversioned())
+		})
+		return
+	}
+
+	writeHeaders(w.Header(), r.Header, extendedInfo, len(tagSet), bktSettings.Unversioned())
+	if params != nil {
+		writeRangeHeaders(w, params, info.Size)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
 	if err = h.obj.GetObject(r.Context(), getParams); err != nil {
 		h.logAndSendError(w, "could not get object", reqInfo, err)
 	}
@@ -239,6 +290,31 @@ func checkPreconditions(info *data.ObjectInfo, args *conditionalArgs) error {
 	return nil
 }
 
+// ifRangeMatches checks If-Range header value that can be either
+// an entity tag or an HTTP date. Empty value always matches.
+// If-Range requires strong comparison (RFC 7233), so weak entity tags
+// and malformed dates never match.
+func ifRangeMatches(value string, info *data.ObjectInfo) bool {
+	if len(value) == 0 {
+		return true
+	}
+
+	if strings.HasPrefix(value, "W/") {
+		return false
+	}
+
+	// entity tags can't contain spaces, so such value is an HTTP date
+	if strings.ContainsRune(value, ' ') {
+		date, err := time.Parse(http.TimeFormat, value)
+		if err != nil {
+			return false
+		}
+		return info.Created.UTC().Truncate(time.Second).Equal(date)
+	}
+
+	return strings.Trim(value, `"`) == info.HashSum
+}
+
 func parseConditionalHeaders(headers http.Header) (*conditionalArgs, error) {
 	var err error
 	args := &conditionalArgs{
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
@@ -186,3 +188,71 @@ func getObjectRange(t *testing.T, tc *handlerContext, bktName, objName string, s
 	require.NoError(t, err)
 	return content
 }
+
+func TestIfRangeMatches(t *testing.T) {
+	created := time.Now()
+	info := newInfo("etag", created)
+
+	for _, tc := range []struct {
+		name     string
+		value    string
+		expected bool
+	}{
+		{name: "empty", value: "", expected: true},
+		{name: "etag match", value: "etag", expected: true},
+		{name: "quoted etag match", value: `"etag"`, expected: true},
+		{name: "etag mismatch", value: "etag2", expected: false},
+		{name: "date match", value: created.UTC().Format(http.TimeFormat), expected: true},
+		{name: "date mismatch", value: created.Add(-time.Hour).UTC().Format(http.TimeFormat), expected: false},
+		{name: "weak etag", value: `W/"etag"`, expected: false},
+		{name: "unparsable date", value: "Mon, 32 Foo 2022 25:00:00 GMT", expected: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ifRangeMatches(tc.value, info))
+		})
+	}
+}
+
+func TestGetObjectIfRange(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-if-range", "object-for-if-range"
+	createTestBucket(tc, bktName)
+
+	content := "123456789abcdef"
+	putObjectContent(tc, bktName, objName, content)
+
+	w, r := prepareTestRequest(tc, bktName, objName, nil)
+	tc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	etag := w.Header().Get(api.ETag)
+	lastModified := w.Header().Get(api.LastModified)
+	modified, err := time.Parse(http.TimeFormat, lastModified)
+	require.NoError(t, err)
+
+	for _, ifRange := range []string{etag, lastModified} {
+		w, r = prepareTestRequest(tc, bktName, objName, nil)
+		r.Header.Set("Range", "bytes=0-4")
+		r.Header.Set(api.IfRange, ifRange)
+		tc.Handler().GetObjectHandler(w, r)
+		assertStatus(t, w, http.StatusPartialContent)
+		require.Equal(t, "12345", string(readBody(t, w)))
+	}
+
+	stale := modified.Add(-time.Hour).Format(http.TimeFormat)
+	for _, ifRange := range []string{"stale-etag", stale} {
+		w, r = prepareTestRequest(tc, bktName, objName, nil)
+		r.Header.Set("Range", "bytes=0-4")
+		r.Header.Set(api.IfRange, ifRange)
+		tc.Handler().GetObjectHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+		require.Empty(t, w.Header().Get(api.ContentRange))
+		require.Equal(t, content, string(readBody(t, w)))
+	}
+}
+
+func readBody(t *testing.T, w *httptest.ResponseRecorder) []byte {
+	content, err := io.ReadAll(w.Result().Body)
+	require.NoError(t, err)
+	return content
+}
@@ -53,14 +53,16 @@ func (hc *handlerContext) Context() context.Context {
 
 type placementPolicyMock struct {
 	defaultPolicy netmap.PlacementPolicy
+	policies      map[string]netmap.PlacementPolicy
 }
 
 func (p *placementPolicyMock) Default() netmap.PlacementPolicy {
 	return p.defaultPolicy
 }
 
-func (p *placementPolicyMock) Get(string) (netmap.PlacementPolicy, bool) {
-	return netmap.PlacementPolicy{}, false
+func (p *placementPolicyMock) Get(name string) (netmap.PlacementPolicy, bool) {
+	policy, ok := p.policies[name]
+	return policy, ok
 }
 
 func prepareHandlerContext(t *testing.T) *handlerContext {
@@ -95,6 +97,7 @@ func prepareHandlerContext(t *testing.T) *handlerContext {
 		cfg: &Config{
 			Policy: &placementPolicyMock{defaultPolicy: pp},
 		},
+		jobs: newBatchJobs(nil),
 	}
 
 	return &handlerContext{
@@ -48,6 +48,7 @@ func (h *handler) HeadObjectHandler(w http.ResponseWriter, r *http.Request) {
 
 	extendedInfo, err := h.obj.GetExtendedObjectInfo(r.Context(), p)
 	if err != nil {
+		h.setDeleteMarkerHeaders(r.Context(), w.Header(), p, err)
 		h.logAndSendError(w, "could not find object", reqInfo, err)
 		return
 	}
@@ -64,6 +65,14 @@ func (h *handler) HeadObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	transformed := h.objectTransformer(bktInfo.Name, info.Name) != nil
+	if transformed {
+		if err = checkTransformedConditions(conditional); err != nil {
+			h.logAndSendError(w, "unsupported conditions for transformed object", reqInfo, err)
+			return
+		}
+	}
+
 	if err = checkPreconditions(info, conditional); err != nil {
 		h.logAndSendError(w, "precondition failed", reqInfo, err)
 		return
@@ -110,6 +119,11 @@ func (h *handler) HeadObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	writeHeaders(w.Header(), r.Header, extendedInfo, len(tagSet), bktSettings.Unversioned())
+	if transformed {
+		// size and ETag of the transformed payload are unknown, as in GetObject response
+		w.Header().Del(api.ContentLength)
+		w.Header().Del(api.ETag)
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -124,6 +138,11 @@ func (h *handler) HeadBucketHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set(api.ContainerID, bktInfo.CID.EncodeToString())
 	w.Header().Set(api.AmzBucketRegion, bktInfo.LocationConstraint)
+	for _, name := range h.cfg.ExposedContainerAttributes {
+		if val, ok := bktInfo.Attributes[name]; ok {
+			w.Header().Set(containerAttributeHeader(name), val)
+		}
+	}
 	api.WriteResponse(w, http.StatusOK, nil, api.MimeNone)
 }
 
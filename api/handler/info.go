@@ -19,3 +19,37 @@ func (h *handler) GetBucketLocationHandler(w http.ResponseWriter, r *http.Reques
 		h.logAndSendError(w, "couldn't encode bucket location response", reqInfo, err)
 	}
 }
+
+// GetBucketUsageHandler returns the current usage of the bucket, its quota and
+// requests and traffic of the bucket accounted by the gateway.
+func (h *handler) GetBucketUsageHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	usage, err := h.obj.GetBucketUsage(r.Context(), bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket usage", reqInfo, err)
+		return
+	}
+
+	traffic := h.cfg.Usage.Bucket(reqInfo.BucketName)
+
+	response := BucketUsageResponse{
+		Size:         usage.Size,
+		ObjectsCount: usage.Objects,
+		QuotaSize:    bktInfo.Quota.Size,
+		QuotaObjects: bktInfo.Quota.Objects,
+		Requests:     traffic.Requests,
+		BytesIn:      traffic.BytesIn,
+		BytesOut:     traffic.BytesOut,
+	}
+
+	if err = api.EncodeToResponse(w, response); err != nil {
+		h.logAndSendError(w, "couldn't encode bucket usage response", reqInfo, err)
+	}
+}
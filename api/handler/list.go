@@ -2,14 +2,35 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 )
 
 const maxObjectList = 1000 // Limit number of objects in a listObjectsResponse/listObjectsVersionsResponse.
 
+// parseMaxKeys parses max-keys, max-uploads and max-parts parameters of listings. The limit is
+// returned if the parameter isn't set, greater values are clamped to it. Non-numeric, negative
+// and exceeding int32 values are rejected with errCode.
+func parseMaxKeys(value string, limit int, errCode errors.ErrorCode) (int, error) {
+	if value == "" {
+		return limit, nil
+	}
+
+	val, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || val < 0 {
+		return 0, errors.GetAPIError(errCode)
+	}
+	if val > int64(limit) {
+		return limit, nil
+	}
+
+	return int(val), nil
+}
+
 // ListBucketsHandler handles bucket listing requests.
 func (h *handler) ListBucketsHandler(w http.ResponseWriter, r *http.Request) {
 	var (
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+const (
+	s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+	// listingFlushEntries is the number of listing entries after which
+	// the encoded part of the response is flushed to the client.
+	listingFlushEntries = 100
+)
+
+// listingEncoder writes ListBucketResult of ListObjectsV1/V2 incrementally.
+// Entries are encoded one by one right from the layer listing instead of
+// building the whole response in memory. Elements are written in the same
+// order as ListObjectsV1Response and ListObjectsV2Response are encoded.
+type listingEncoder struct {
+	w           http.ResponseWriter
+	enc         *xml.Encoder
+	encode      string
+	encodeOwner func(user.ID) *Owner
+	entries     int
+}
+
+func newListingEncoder(w http.ResponseWriter, encode string, encodeOwner func(user.ID) *Owner) *listingEncoder {
+	return &listingEncoder{
+		w:           w,
+		enc:         xml.NewEncoder(w),
+		encode:      encode,
+		encodeOwner: encodeOwner,
+	}
+}
+
+func writeListObjectsV1(w http.ResponseWriter, p *layer.ListObjectsParamsV1, list *layer.ListObjectsInfoV1, encodeOwner func(user.ID) *Owner) error {
+	e := newListingEncoder(w, p.Encode, encodeOwner)
+	if err := e.start(list.Prefixes, list.Objects); err != nil {
+		return err
+	}
+
+	e.optional("Delimiter", s3PathEncode(p.Delimiter, p.Encode))
+	e.optional("EncodingType", p.Encode)
+	e.element("IsTruncated", strconv.FormatBool(list.IsTruncated))
+	e.element("Marker", s3PathEncode(p.Marker, p.Encode))
+	e.element("MaxKeys", strconv.Itoa(p.MaxKeys))
+	e.element("Name", p.BktInfo.Name)
+	e.optional("NextMarker", s3PathEncode(list.NextMarker, p.Encode))
+	e.element("Prefix", s3PathEncode(p.Prefix, p.Encode))
+
+	return e.end()
+}
+
+func writeListObjectsV2(w http.ResponseWriter, p *layer.ListObjectsParamsV2, list *layer.ListObjectsInfoV2, encodeOwner func(user.ID) *Owner) error {
+	e := newListingEncoder(w, p.Encode, encodeOwner)
+	if err := e.start(list.Prefixes, list.Objects); err != nil {
+		return err
+	}
+
+	e.optional("ContinuationToken", p.ContinuationToken)
+	e.optional("Delimiter", s3PathEncode(p.Delimiter, p.Encode))
+	e.optional("EncodingType", p.Encode)
+	e.element("IsTruncated", strconv.FormatBool(list.IsTruncated))
+	e.element("KeyCount", strconv.Itoa(len(list.Objects)+len(list.Prefixes)))
+	e.element("MaxKeys", strconv.Itoa(p.MaxKeys))
+	e.element("Name", p.BktInfo.Name)
+	e.optional("NextContinuationToken", list.NextContinuationToken)
+	e.element("Prefix", s3PathEncode(p.Prefix, p.Encode))
+	e.optional("StartAfter", s3PathEncode(p.StartAfter, p.Encode))
+
+	return e.end()
+}
+
+// start writes status code, XML prolog and opening tag of the result followed
+// by common prefixes and contents of the listing.
+func (e *listingEncoder) start(prefixes []string, objects []*data.ObjectInfo) error {
+	e.w.WriteHeader(http.StatusOK)
+	if _, err := e.w.Write([]byte(xml.Header)); err != nil {
+		return fmt.Errorf("write headers: %w", err)
+	}
+
+	if err := e.enc.EncodeToken(xml.StartElement{Name: xml.Name{Space: s3Namespace, Local: "ListBucketResult"}}); err != nil {
+		return fmt.Errorf("encode xml response: %w", err)
+	}
+
+	for _, prefix := range prefixes {
+		if err := e.entry("CommonPrefixes", CommonPrefix{Prefix: s3PathEncode(prefix, e.encode)}); err != nil {
+			return err
+		}
+	}
+
+	for _, obj := range objects {
+		if err := e.entry("Contents", e.object(obj)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// object forms listing entry, owner is set only if encodeOwner is not nil.
+func (e *listingEncoder) object(obj *data.ObjectInfo) Object {
+	res := Object{
+		Key:          s3PathEncode(obj.Name, e.encode),
+		Size:         obj.Size,
+		LastModified: obj.Created.UTC().Format(time.RFC3339),
+		ETag:         obj.HashSum,
+		StorageClass: storageClassOrDefault(obj.Headers[layer.AttributeStorageClass]),
+	}
+
+	if e.encodeOwner != nil {
+		res.Owner = e.encodeOwner(obj.Owner)
+	}
+
+	return res
+}
+
+func (e *listingEncoder) entry(name string, v interface{}) error {
+	if err := e.enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+		return fmt.Errorf("encode xml response: %w", err)
+	}
+
+	e.entries++
+	if e.entries%listingFlushEntries == 0 {
+		return e.flush()
+	}
+
+	return nil
+}
+
+// element encodes scalar element of the result, errors are returned by end.
+func (e *listingEncoder) element(name, value string) {
+	_ = e.enc.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: name}})
+}
+
+// optional encodes scalar element of the result if the value isn't empty.
+func (e *listingEncoder) optional(name, value string) {
+	if value != "" {
+		e.element(name, value)
+	}
+}
+
+func (e *listingEncoder) end() error {
+	if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Space: s3Namespace, Local: "ListBucketResult"}}); err != nil {
+		return fmt.Errorf("encode xml response: %w", err)
+	}
+
+	return e.flush()
+}
+
+func (e *listingEncoder) flush() error {
+	if err := e.enc.Flush(); err != nil {
+		return fmt.Errorf("flush xml response: %w", err)
+	}
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	return nil
+}
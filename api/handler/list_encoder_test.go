@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListingEncoderMatchesResponse(t *testing.T) {
+	created := time.Now()
+	objects := make([]*data.ObjectInfo, 0, 2*listingFlushEntries)
+	contents := make([]Object, 0, 2*listingFlushEntries)
+	for i := 0; i < 2*listingFlushEntries; i++ {
+		name := "dir/obj " + strconv.Itoa(i)
+		objects = append(objects, &data.ObjectInfo{Name: name, Size: int64(i), Created: created, HashSum: "etag"})
+		contents = append(contents, Object{
+			Key:          s3PathEncode(name, urlEncodingType),
+			LastModified: created.UTC().Format(time.RFC3339),
+			ETag:         "etag",
+			Size:         int64(i),
+			StorageClass: storageClassOrDefault(""),
+		})
+	}
+
+	p := &layer.ListObjectsParamsV2{
+		ListObjectsParamsCommon: layer.ListObjectsParamsCommon{
+			BktInfo:   &data.BucketInfo{Name: "bucket"},
+			Delimiter: "/",
+			Encode:    urlEncodingType,
+			MaxKeys:   1000,
+		},
+		StartAfter: "dir/a",
+	}
+	list := &layer.ListObjectsInfoV2{
+		ListObjectsInfo:       layer.ListObjectsInfo{Prefixes: []string{"dir/sub dir/"}, Objects: objects, IsTruncated: true},
+		NextContinuationToken: "token",
+	}
+
+	expected, err := xml.Marshal(&ListObjectsV2Response{
+		CommonPrefixes:        []CommonPrefix{{Prefix: s3PathEncode("dir/sub dir/", urlEncodingType)}},
+		Contents:              contents,
+		Delimiter:             "/",
+		EncodingType:          urlEncodingType,
+		IsTruncated:           true,
+		KeyCount:              len(objects) + 1,
+		MaxKeys:               1000,
+		Name:                  "bucket",
+		NextContinuationToken: "token",
+		StartAfter:            "dir/a",
+	})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	require.NoError(t, writeListObjectsV2(w, p, list, nil))
+	require.Equal(t, xml.Header+string(expected), w.Body.String())
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// totpStep is a time step of one-time codes.
+	totpStep = 30 * time.Second
+	// totpSkew is a number of adjacent steps which codes are accepted to tolerate clock drift.
+	totpSkew = 1
+)
+
+// TOTPValidator checks time-based one-time codes (RFC 6238) of virtual MFA devices:
+// 6 digits of HMAC-SHA1 with 30 seconds step. A code can't be used twice, as well
+// as codes preceding the used one.
+type TOTPValidator struct {
+	secrets map[string][]byte
+
+	mu   sync.Mutex
+	used map[string]uint64
+}
+
+// NewTOTPValidator creates TOTPValidator for devices with the given secrets by serial numbers.
+func NewTOTPValidator(secrets map[string][]byte) *TOTPValidator {
+	return &TOTPValidator{secrets: secrets, used: make(map[string]uint64)}
+}
+
+// Validate implements MFAValidator.
+func (v *TOTPValidator) Validate(_ context.Context, serialNumber, code string) error {
+	return v.validate(serialNumber, code, time.Now())
+}
+
+func (v *TOTPValidator) validate(serialNumber, code string, now time.Time) error {
+	secret, ok := v.secrets[serialNumber]
+	if !ok {
+		return fmt.Errorf("unknown mfa device '%s'", serialNumber)
+	}
+
+	current := uint64(now.Unix()) / uint64(totpStep/time.Second)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for counter := current - totpSkew; counter <= current+totpSkew; counter++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, counter)), []byte(code)) != 1 {
+			continue
+		}
+		if used, ok := v.used[serialNumber]; ok && counter <= used {
+			return fmt.Errorf("code of mfa device '%s' has already been used", serialNumber)
+		}
+		v.used[serialNumber] = counter
+		return nil
+	}
+
+	return fmt.Errorf("invalid code of mfa device '%s'", serialNumber)
+}
+
+// totpCode returns 6-digit code of the counter (RFC 4226).
+func totpCode(secret []byte, counter uint64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", value%1000000)
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPValidator(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	// test vectors of RFC 6238 truncated to 6 digits
+	for _, tc := range []struct {
+		time int64
+		code string
+	}{
+		{time: 59, code: "287082"},
+		{time: 1111111109, code: "081804"},
+		{time: 1234567890, code: "005924"},
+		{time: 2000000000, code: "279037"},
+	} {
+		require.Equal(t, tc.code, totpCode(secret, uint64(tc.time)/30), tc.time)
+	}
+
+	v := NewTOTPValidator(map[string][]byte{"device": secret})
+	now := time.Unix(1111111109, 0)
+
+	require.Error(t, v.validate("unknown", "081804", now))
+	require.Error(t, v.validate("device", "invalid", now))
+	require.Error(t, v.validate("device", "081804", now.Add(2*totpStep)))
+
+	// code of the previous step is accepted, but only once
+	require.NoError(t, v.validate("device", "081804", now.Add(totpStep)))
+	require.Error(t, v.validate("device", "081804", now.Add(totpStep)))
+
+	require.NoError(t, v.validate("device", totpCode(secret, uint64(now.Unix())/30+1), now.Add(totpStep)))
+}
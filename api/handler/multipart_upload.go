@@ -2,8 +2,8 @@ package handler
 
 import (
 	"encoding/xml"
+	"fmt"
 	"net/http"
-	"net/url"
 	"strconv"
 	"time"
 
@@ -95,6 +95,11 @@ const (
 func (h *handler) CreateMultipartUploadHandler(w http.ResponseWriter, r *http.Request) {
 	reqInfo := api.GetReqInfo(r.Context())
 
+	if err := checkObjectName(reqInfo.ObjectName); err != nil {
+		h.logAndSendError(w, "invalid object name", reqInfo, err)
+		return
+	}
+
 	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
 	if err != nil {
 		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
@@ -143,14 +148,17 @@ func (h *handler) CreateMultipartUploadHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	p.Header = parseMetadata(r)
+	if p.Header, err = parseMetadata(r); err != nil {
+		h.logAndSendError(w, "invalid metadata", reqInfo, err)
+		return
+	}
 	if contentType := r.Header.Get(api.ContentType); len(contentType) > 0 {
 		p.Header[api.ContentType] = contentType
 	}
 
-	p.CopiesNumber, err = getCopiesNumberOrDefault(p.Header, h.cfg.CopiesNumber)
+	p.CopiesNumber, err = h.getCopiesNumber(p.Header, r.Header)
 	if err != nil {
-		h.logAndSendError(w, "invalid copies number", reqInfo, err)
+		h.logAndSendError(w, "invalid copies number or storage class", reqInfo, err)
 		return
 	}
 
@@ -215,6 +223,13 @@ func (h *handler) UploadPartHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	contentMD5, err := parseContentMD5(r.Header)
+	if err != nil {
+		h.logAndSendError(w, "invalid Content-MD5", reqInfo, err, additional...)
+		return
+	}
+
+	body := newRequestBody(r)
 	p := &layer.UploadPartParams{
 		Info: &layer.UploadInfoParams{
 			UploadID: uploadID,
@@ -223,7 +238,8 @@ func (h *handler) UploadPartHandler(w http.ResponseWriter, r *http.Request) {
 		},
 		PartNumber: partNumber,
 		Size:       r.ContentLength,
-		Reader:     r.Body,
+		Reader:     body,
+		ContentMD5: contentMD5,
 	}
 
 	p.Info.Encryption, err = formEncryptionParams(r)
@@ -234,6 +250,7 @@ func (h *handler) UploadPartHandler(w http.ResponseWriter, r *http.Request) {
 
 	hash, err := h.obj.UploadPart(r.Context(), p)
 	if err != nil {
+		additional = append(additional, zap.Errors("body close errors", body.discard(r)))
 		h.logAndSendError(w, "could not upload a part", reqInfo, err, additional...)
 		return
 	}
@@ -248,7 +265,6 @@ func (h *handler) UploadPartHandler(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) UploadPartCopy(w http.ResponseWriter, r *http.Request) {
 	var (
-		versionID   string
 		reqInfo     = api.GetReqInfo(r.Context())
 		queryValues = reqInfo.URL.Query()
 		uploadID    = queryValues.Get(uploadIDHeaderName)
@@ -261,17 +277,17 @@ func (h *handler) UploadPartCopy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	src := r.Header.Get(api.AmzCopySource)
-	if u, err := url.Parse(src); err == nil {
-		versionID = u.Query().Get(api.QueryVersionID)
-		src = u.Path
-	}
-	srcBucket, srcObject, err := path2BucketObject(src)
+	srcBucket, srcObject, versionID, err := parseCopySource(r.Header.Get(api.AmzCopySource))
 	if err != nil {
 		h.logAndSendError(w, "invalid source copy", reqInfo, err)
 		return
 	}
 
+	if err = h.checkNotTransformed(srcBucket, srcObject); err != nil {
+		h.logAndSendError(w, "source object can't be copied", reqInfo, err, additional...)
+		return
+	}
+
 	srcRange, err := parseRange(r.Header.Get(api.AmzCopySourceRange))
 	if err != nil {
 		h.logAndSendError(w, "could not parse copy range", reqInfo,
@@ -372,8 +388,6 @@ func (h *handler) CompleteMultipartUploadHandler(w http.ResponseWriter, r *http.
 	}
 
 	var (
-		sessionTokenSetEACL *session.Container
-
 		uploadID   = r.URL.Query().Get(uploadIDHeaderName)
 		uploadInfo = &layer.UploadInfoParams{
 			UploadID: uploadID,
@@ -394,44 +408,85 @@ func (h *handler) CompleteMultipartUploadHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	ifNotExists, err := parseIfNoneMatchOnWrite(r.Header)
+	if err != nil {
+		h.logAndSendError(w, "invalid conditional write header", reqInfo, err, additional...)
+		return
+	}
+
 	c := &layer.CompleteMultipartParams{
-		Info:  uploadInfo,
-		Parts: reqBody.Parts,
+		Info:        uploadInfo,
+		Parts:       reqBody.Parts,
+		IfNotExists: ifNotExists,
+	}
+
+	// Next operations might take some time, so we want to keep client's
+	// connection alive. To do so, gateway sends periodic white spaces
+	// back to the client the same way as Amazon S3 service does.
+	stopPeriodicResponseWriter := periodicXMLWriter(w, h.cfg.CompleteMultipartKeepalive)
+
+	// Start complete multipart upload which may take some time to check parts
+	// and put the object referencing them.
+	objInfo, err := h.completeMultipartUpload(r, c, bktInfo, reqInfo)
+
+	// Stop periodic writer as complete multipart upload is finished
+	// successfully or not.
+	headerIsWritten := stopPeriodicResponseWriter()
+
+	responseWriter := api.EncodeToResponse
+	errLogger := h.logAndSendError
+	// Do not send XML and HTTP headers if periodic writer was invoked at this point.
+	if headerIsWritten {
+		responseWriter = api.EncodeToResponseNoHeader
+		errLogger = h.logAndSendErrorNoHeader
 	}
 
-	uploadData, extendedObjInfo, err := h.obj.CompleteMultipartUpload(r.Context(), c)
 	if err != nil {
-		h.logAndSendError(w, "could not complete multipart upload", reqInfo, err, additional...)
+		errLogger(w, "complete multipart error", reqInfo, err, additional...)
 		return
 	}
-	objInfo := extendedObjInfo.ObjectInfo
 
-	if len(uploadData.TagSet) != 0 {
-		tagPrm := &layer.PutObjectTaggingParams{
-			ObjectVersion: &layer.ObjectVersion{
-				BktInfo:    bktInfo,
-				ObjectName: objInfo.Name,
-				VersionID:  objInfo.VersionID(),
-			},
-			TagSet:      uploadData.TagSet,
-			NodeVersion: extendedObjInfo.NodeVersion,
-		}
-		if _, err = h.obj.PutObjectTagging(r.Context(), tagPrm); err != nil {
-			h.logAndSendError(w, "could not put tagging file of completed multipart upload", reqInfo, err, additional...)
+	response := CompleteMultipartUploadResponse{
+		Bucket: objInfo.Bucket,
+		ETag:   objInfo.HashSum,
+		Key:    objInfo.Name,
+	}
+
+	// Here we previously set api.AmzVersionID header for versioned bucket.
+	// It is not possible after periodic writer has sent the response headers.
+	if !headerIsWritten {
+		bktSettings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+		if err != nil {
+			h.logAndSendError(w, "could not get bucket settings", reqInfo, err)
 			return
 		}
+		if bktSettings.VersioningEnabled() {
+			w.Header().Set(api.AmzVersionID, objInfo.VersionID())
+		}
+	}
+
+	if err = responseWriter(w, response); err != nil {
+		errLogger(w, "something went wrong", reqInfo, err)
+	}
+}
+
+func (h *handler) completeMultipartUpload(r *http.Request, c *layer.CompleteMultipartParams, bktInfo *data.BucketInfo, reqInfo *api.ReqInfo) (*data.ObjectInfo, error) {
+	var sessionTokenSetEACL *session.Container
+
+	uploadData, extendedObjInfo, err := h.obj.CompleteMultipartUpload(r.Context(), c)
+	if err != nil {
+		return nil, fmt.Errorf("could not complete multipart upload: %w", err)
 	}
+	objInfo := extendedObjInfo.ObjectInfo
 
 	if len(uploadData.ACLHeaders) != 0 {
 		key, err := h.bearerTokenIssuerKey(r.Context())
 		if err != nil {
-			h.logAndSendError(w, "couldn't get gate key", reqInfo, err)
-			return
+			return nil, fmt.Errorf("couldn't get gate key: %w", err)
 		}
 		acl, err := parseACLHeaders(r.Header, key)
 		if err != nil {
-			h.logAndSendError(w, "could not parse acl", reqInfo, err)
-			return
+			return nil, fmt.Errorf("could not parse acl: %w", err)
 		}
 
 		resInfo := &resourceInfo{
@@ -440,12 +495,10 @@ func (h *handler) CompleteMultipartUploadHandler(w http.ResponseWriter, r *http.
 		}
 		astObject, err := aclToAst(acl, resInfo)
 		if err != nil {
-			h.logAndSendError(w, "could not translate acl of completed multipart upload to ast", reqInfo, err, additional...)
-			return
+			return nil, fmt.Errorf("could not translate acl of completed multipart upload to ast: %w", err)
 		}
-		if _, err = h.updateBucketACL(r, astObject, bktInfo, sessionTokenSetEACL); err != nil {
-			h.logAndSendError(w, "could not update bucket acl while completing multipart upload", reqInfo, err, additional...)
-			return
+		if _, err = h.updateBucketACL(r.Context(), astObject, bktInfo, sessionTokenSetEACL); err != nil {
+			return nil, fmt.Errorf("could not update bucket acl while completing multipart upload: %w", err)
 		}
 	}
 
@@ -459,24 +512,68 @@ func (h *handler) CompleteMultipartUploadHandler(w http.ResponseWriter, r *http.
 		h.log.Error("couldn't send notification: %w", zap.Error(err))
 	}
 
-	bktSettings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
-	if err != nil {
-		h.logAndSendError(w, "could not get bucket settings", reqInfo, err)
-	}
+	return objInfo, nil
+}
 
-	response := CompleteMultipartUploadResponse{
-		Bucket: objInfo.Bucket,
-		ETag:   objInfo.HashSum,
-		Key:    objInfo.Name,
+// periodicXMLWriter writes xml header and whitespaces every dur to avoid connection
+// drop from the client while the response is being prepared, see tickXMLWriter.
+// Zero duration disables the writer.
+func periodicXMLWriter(w http.ResponseWriter, dur time.Duration) (stop func() bool) {
+	if dur == 0 {
+		return func() bool { return false }
 	}
 
-	if bktSettings.VersioningEnabled() {
-		w.Header().Set(api.AmzVersionID, objInfo.VersionID())
+	tick := time.NewTicker(dur)
+	stopWriter := tickXMLWriter(w, tick.C)
+
+	return func() bool {
+		tick.Stop()
+		return stopWriter()
 	}
+}
 
-	if err = api.EncodeToResponse(w, response); err != nil {
-		h.logAndSendError(w, "something went wrong", reqInfo, err)
+// tickXMLWriter creates go routine writing xml header on the first tick and a whitespace
+// on each tick. To work properly, pass `http.ResponseWriter` with implemented
+// `http.Flusher` interface. Returns stop function which waits for the go routine to exit
+// and reports whether the header has been written, so the response status is already sent.
+func tickXMLWriter(w http.ResponseWriter, ticks <-chan time.Time) (stop func() bool) {
+	whitespaceChar := []byte(" ")
+	closer := make(chan struct{})
+	done := make(chan struct{})
+	headerWritten := false
+
+	go func() {
+		defer close(done)
+
+		for {
+			select {
+			case <-ticks:
+				if !headerWritten {
+					w.Header().Set(api.ContentType, string(api.MimeXML))
+					if _, err := w.Write([]byte(xml.Header)); err != nil {
+						return
+					}
+					headerWritten = true
+				}
+				if _, err := w.Write(whitespaceChar); err != nil {
+					return
+				}
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+			case <-closer:
+				return
+			}
+		}
+	}()
+
+	stop = func() bool {
+		close(closer)
+		<-done // wait for goroutine to stop
+		return headerWritten
 	}
+
+	return stop
 }
 
 func (h *handler) ListMultipartUploadsHandler(w http.ResponseWriter, r *http.Request) {
@@ -492,24 +589,24 @@ func (h *handler) ListMultipartUploadsHandler(w http.ResponseWriter, r *http.Req
 		queryValues = reqInfo.URL.Query()
 		delimiter   = queryValues.Get("delimiter")
 		prefix      = queryValues.Get("prefix")
-		maxUploads  = layer.MaxSizeUploadsList
 	)
 
-	if queryValues.Get("max-uploads") != "" {
-		val, err := strconv.Atoi(queryValues.Get("max-uploads"))
-		if err != nil || val < 0 {
-			h.logAndSendError(w, "invalid maxUploads", reqInfo, errors.GetAPIError(errors.ErrInvalidMaxUploads))
-			return
-		}
-		if val < maxUploads {
-			maxUploads = val
-		}
+	maxUploads, err := parseMaxKeys(queryValues.Get("max-uploads"), layer.MaxSizeUploadsList, errors.ErrInvalidMaxUploads)
+	if err != nil {
+		h.logAndSendError(w, "invalid maxUploads", reqInfo, err)
+		return
+	}
+
+	encodingType, err := parseEncodingType(queryValues.Get("encoding-type"))
+	if err != nil {
+		h.logAndSendError(w, "invalid encoding type", reqInfo, err)
+		return
 	}
 
 	p := &layer.ListMultipartUploadsParams{
 		Bkt:            bktInfo,
 		Delimiter:      delimiter,
-		EncodingType:   queryValues.Get("encoding-type"),
+		EncodingType:   encodingType,
 		KeyMarker:      queryValues.Get("key-marker"),
 		MaxUploads:     maxUploads,
 		Prefix:         prefix,
@@ -542,23 +639,17 @@ func (h *handler) ListPartsHandler(w http.ResponseWriter, r *http.Request) {
 		queryValues = reqInfo.URL.Query()
 		uploadID    = queryValues.Get(uploadIDHeaderName)
 		additional  = []zap.Field{zap.String("uploadID", uploadID), zap.String("Key", reqInfo.ObjectName)}
-		maxParts    = layer.MaxSizePartsList
 	)
 
-	if queryValues.Get("max-parts") != "" {
-		val, err := strconv.Atoi(queryValues.Get("max-parts"))
-		if err != nil || val < 0 {
-			h.logAndSendError(w, "invalid MaxParts", reqInfo, errors.GetAPIError(errors.ErrInvalidMaxParts), additional...)
-			return
-		}
-		if val < layer.MaxSizePartsList {
-			maxParts = val
-		}
+	maxParts, err := parseMaxKeys(queryValues.Get("max-parts"), layer.MaxSizePartsList, errors.ErrInvalidMaxParts)
+	if err != nil {
+		h.logAndSendError(w, "invalid MaxParts", reqInfo, err, additional...)
+		return
 	}
 
 	if queryValues.Get("part-number-marker") != "" {
-		if partNumberMarker, err = strconv.Atoi(queryValues.Get("part-number-marker")); err != nil || partNumberMarker <= 0 {
-			h.logAndSendError(w, "invalid PartNumberMarker", reqInfo, err, additional...)
+		if partNumberMarker, err = strconv.Atoi(queryValues.Get("part-number-marker")); err != nil || partNumberMarker < 0 {
+			h.logAndSendError(w, "invalid PartNumberMarker", reqInfo, errors.GetAPIError(errors.ErrInvalidPartNumberMarker), additional...)
 			return
 		}
 	}
@@ -626,14 +717,14 @@ func encodeListMultipartUploadsToResponse(info *layer.ListMultipartUploadsInfo,
 	res := ListMultipartUploadsResponse{
 		Bucket:             params.Bkt.Name,
 		CommonPrefixes:     fillPrefixes(info.Prefixes, params.EncodingType),
-		Delimiter:          params.Delimiter,
+		Delimiter:          s3PathEncode(params.Delimiter, params.EncodingType),
 		EncodingType:       params.EncodingType,
 		IsTruncated:        info.IsTruncated,
-		KeyMarker:          params.KeyMarker,
+		KeyMarker:          s3PathEncode(params.KeyMarker, params.EncodingType),
 		MaxUploads:         params.MaxUploads,
-		NextKeyMarker:      info.NextKeyMarker,
+		NextKeyMarker:      s3PathEncode(info.NextKeyMarker, params.EncodingType),
 		NextUploadIDMarker: info.NextUploadIDMarker,
-		Prefix:             params.Prefix,
+		Prefix:             s3PathEncode(params.Prefix, params.EncodingType),
 		UploadIDMarker:     params.UploadIDMarker,
 	}
 
@@ -645,12 +736,13 @@ func encodeListMultipartUploadsToResponse(info *layer.ListMultipartUploadsInfo,
 				ID:          u.Owner.String(),
 				DisplayName: u.Owner.String(),
 			},
-			Key: u.Key,
+			Key: s3PathEncode(u.Key, params.EncodingType),
 			Owner: Owner{
 				ID:          u.Owner.String(),
 				DisplayName: u.Owner.String(),
 			},
-			UploadID: u.UploadID,
+			StorageClass: storageClassOrDefault(u.StorageClass),
+			UploadID:     u.UploadID,
 		}
 		uploads = append(uploads, m)
 	}
@@ -677,6 +769,7 @@ func encodeListPartsToResponse(info *layer.ListPartsInfo, params *layer.ListPart
 			DisplayName: info.Owner.String(),
 		},
 		PartNumberMarker: params.PartNumberMarker,
+		StorageClass:     storageClassOrDefault(info.StorageClass),
 		UploadID:         params.Info.UploadID,
 		Parts:            info.Parts,
 	}
@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadPartCopyRange(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, srcObjName, objName := "bucket-for-part-copy", "source-object", "object-for-part-copy"
+	createTestBucket(hc, bktName)
+
+	content := "0123456789abcdef"
+	putObjectContent(hc, bktName, srcObjName, content)
+
+	uploadInfo := createMultipartUpload(hc, bktName, objName, map[string]string{})
+
+	etag := uploadPartCopy(hc, bktName, objName, uploadInfo.UploadID, 1, "/"+bktName+"/"+srcObjName, "bytes=4-9", http.StatusOK)
+	uploadPartCopy(hc, bktName, objName, uploadInfo.UploadID, 2, "/"+bktName+"/"+srcObjName, "bytes=10-16", http.StatusBadRequest)
+
+	completeMultipartUpload(hc, bktName, objName, uploadInfo.UploadID, []string{etag})
+
+	require.Equal(t, content[4:10], string(getObject(hc, bktName, objName)))
+}
+
+func getObject(hc *handlerContext, bktName, objName string) []byte {
+	w, r := prepareTestRequest(hc, bktName, objName, nil)
+	hc.Handler().GetObjectHandler(w, r)
+	assertStatus(hc.t, w, http.StatusOK)
+
+	return readBody(hc.t, w)
+}
+
+func uploadPartCopy(hc *handlerContext, bktName, objName, uploadID string, num int, src, rng string, status int) string {
+	query := make(url.Values)
+	query.Set(uploadIDQuery, uploadID)
+	query.Set(partNumberQuery, strconv.Itoa(num))
+
+	w, r := prepareTestRequestWithQuery(hc, bktName, objName, query, nil)
+	r.Header.Set(api.AmzCopySource, src)
+	if rng != "" {
+		r.Header.Set(api.AmzCopySourceRange, rng)
+	}
+	hc.Handler().UploadPartCopy(w, r)
+	if status != http.StatusOK {
+		assertStatus(hc.t, w, status)
+		return ""
+	}
+
+	uploadPartCopyResponse := &UploadPartCopyResponse{}
+	readResponse(hc.t, w, http.StatusOK, uploadPartCopyResponse)
+
+	return uploadPartCopyResponse.ETag
+}
+
+func TestListMultipartUploads(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-to-list-uploads"
+	createTestBucket(hc, bktName)
+
+	upload1 := createMultipartUpload(hc, bktName, "dir/obj1", map[string]string{})
+	upload2 := createMultipartUpload(hc, bktName, "dir/obj2", map[string]string{})
+	createMultipartUpload(hc, bktName, "obj3", map[string]string{})
+
+	query := make(url.Values)
+	query.Set("prefix", "dir/")
+	w, r := prepareTestRequestWithQuery(hc, bktName, "", query, nil)
+	hc.Handler().ListMultipartUploadsHandler(w, r)
+	listUploads := &ListMultipartUploadsResponse{}
+	readResponse(t, w, http.StatusOK, listUploads)
+
+	require.Len(t, listUploads.Uploads, 2)
+	require.Equal(t, upload1.UploadID, listUploads.Uploads[0].UploadID)
+	require.Equal(t, upload2.UploadID, listUploads.Uploads[1].UploadID)
+}
+
+func TestMultipartReUploadPart(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-to-upload-part", "object-multipart"
+	bktInfo := createTestBucket(hc, bktName)
+
+	uploadInfo := createMultipartUpload(hc, bktName, objName, map[string]string{})
+	uploadPart(hc, bktName, objName, uploadInfo.UploadID, 1, 10)
+	objectsBefore := len(hc.MockedPool().AllObjects(bktInfo.CID))
+	etag, data := uploadPart(hc, bktName, objName, uploadInfo.UploadID, 1, 20)
+	require.Len(t, hc.MockedPool().AllObjects(bktInfo.CID), objectsBefore)
+
+	query := make(url.Values)
+	query.Set(uploadIDQuery, uploadInfo.UploadID)
+	w, r := prepareTestRequestWithQuery(hc, bktName, objName, query, nil)
+	hc.Handler().ListPartsHandler(w, r)
+	listParts := &ListPartsResponse{}
+	readResponse(t, w, http.StatusOK, listParts)
+	require.Len(t, listParts.Parts, 1)
+	require.Equal(t, int64(len(data)), listParts.Parts[0].Size)
+
+	completeMultipartUpload(hc, bktName, objName, uploadInfo.UploadID, []string{etag})
+	require.Equal(t, data, getObject(hc, bktName, objName))
+}
+
+func TestCompleteMultipartUploadWithoutReupload(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-combined-object", "object-multipart"
+	bktInfo := createTestBucket(hc, bktName)
+
+	partSize := 5 * 1048576
+	uploadInfo := createMultipartUpload(hc, bktName, objName, map[string]string{})
+	etag1, data1 := uploadPart(hc, bktName, objName, uploadInfo.UploadID, 1, partSize)
+	etag2, data2 := uploadPart(hc, bktName, objName, uploadInfo.UploadID, 2, 10)
+	completeMultipartUpload(hc, bktName, objName, uploadInfo.UploadID, []string{etag1, etag2})
+
+	// two parts and the object listing them
+	require.Len(t, hc.MockedPool().AllObjects(bktInfo.CID), 3)
+
+	headers := headObjectHeaders(t, hc, bktName, objName)
+	require.Equal(t, strconv.Itoa(partSize+10), headers.Get(api.ContentLength))
+
+	fullPayload := append(data1, data2...)
+	require.Equal(t, fullPayload, getObject(hc, bktName, objName))
+	require.Equal(t, fullPayload[partSize-5:partSize+5], getObjectRange(t, hc, bktName, objName, partSize-5, partSize+4))
+
+	deleteObject(t, hc, bktName, objName, emptyVersion)
+	require.Empty(t, hc.MockedPool().AllObjects(bktInfo.CID))
+}
+
+func TestCompleteMultipartUploadOverwrite(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-combined-overwrite", "object-multipart"
+	bktInfo := createTestBucket(hc, bktName)
+
+	uploadInfo := createMultipartUpload(hc, bktName, objName, map[string]string{})
+	etag1, _ := uploadPart(hc, bktName, objName, uploadInfo.UploadID, 1, 5*1048576)
+	etag2, _ := uploadPart(hc, bktName, objName, uploadInfo.UploadID, 2, 10)
+	completeMultipartUpload(hc, bktName, objName, uploadInfo.UploadID, []string{etag1, etag2})
+	require.Len(t, hc.MockedPool().AllObjects(bktInfo.CID), 3)
+
+	// parts of the replaced object are deleted with it
+	putObjectContent(hc, bktName, objName, "content")
+	require.Len(t, hc.MockedPool().AllObjects(bktInfo.CID), 1)
+	require.Equal(t, []byte("content"), getObject(hc, bktName, objName))
+}
+
+func TestPeriodicWriter(t *testing.T) {
+	const whitespaces = 8
+	expected := []byte(xml.Header)
+	for i := 0; i < whitespaces; i++ {
+		expected = append(expected, []byte(" ")...)
+	}
+
+	t.Run("writes data", func(t *testing.T) {
+		buf := httptest.NewRecorder()
+		ticks := make(chan time.Time)
+		stop := tickXMLWriter(buf, ticks)
+
+		// unbuffered channel: each send waits until the previous tick is written
+		for i := 0; i < whitespaces; i++ {
+			ticks <- time.Now()
+		}
+		require.True(t, stop())
+		require.Equal(t, string(expected), buf.Body.String())
+
+		t.Run("no additional data after stop", func(t *testing.T) {
+			select {
+			case ticks <- time.Now():
+				t.Fatal("tick is received after stop")
+			default:
+			}
+			require.Equal(t, string(expected), buf.Body.String())
+		})
+	})
+
+	t.Run("does not write data", func(t *testing.T) {
+		buf := httptest.NewRecorder()
+		stop := tickXMLWriter(buf, make(chan time.Time))
+		require.False(t, stop())
+		require.Empty(t, buf.Body.Bytes())
+
+		t.Run("disabled", func(t *testing.T) {
+			stop = periodicXMLWriter(buf, 0)
+			require.False(t, stop())
+			require.Empty(t, buf.Body.Bytes())
+		})
+	})
+}
+
+func TestCompleteMultipartUploadKeepalive(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	hc.h.cfg.CompleteMultipartKeepalive = time.Nanosecond
+
+	bktName, objName := "bucket-for-complete-keepalive", "object-multipart"
+	createTestBucket(hc, bktName)
+
+	uploadInfo := createMultipartUpload(hc, bktName, objName, map[string]string{})
+	etag, data := uploadPart(hc, bktName, objName, uploadInfo.UploadID, 1, 10)
+
+	completeMultipartUpload(hc, bktName, objName, uploadInfo.UploadID, []string{etag})
+	require.Equal(t, data, getObject(hc, bktName, objName))
+
+	query := make(url.Values)
+	query.Set(uploadIDQuery, uploadInfo.UploadID)
+	complete := &CompleteMultipartUpload{Parts: []*layer.CompletedPart{{ETag: etag, PartNumber: 1}}}
+	w, r := prepareTestFullRequest(hc, bktName, objName, query, complete)
+	hc.Handler().CompleteMultipartUploadHandler(w, r)
+
+	// error is reported in the body if keepalive has already sent status code
+	errResp := &api.ErrorResponse{}
+	err := xml.NewDecoder(w.Result().Body).Decode(errResp)
+	require.NoError(t, err)
+	require.Equal(t, "NoSuchUpload", errResp.Code)
+}
+
+func TestCompleteMultipartUploadETag(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-multipart-etag", "object-multipart"
+	createTestBucket(hc, bktName)
+
+	uploadInfo := createMultipartUpload(hc, bktName, objName, map[string]string{})
+	etag1, _ := uploadPart(hc, bktName, objName, uploadInfo.UploadID, 1, 5*1048576)
+	etag2, _ := uploadPart(hc, bktName, objName, uploadInfo.UploadID, 2, 10)
+
+	query := make(url.Values)
+	query.Set(uploadIDQuery, uploadInfo.UploadID)
+	complete := &CompleteMultipartUpload{Parts: []*layer.CompletedPart{
+		{ETag: etag1, PartNumber: 1},
+		{ETag: etag2, PartNumber: 2},
+	}}
+	w, r := prepareTestFullRequest(hc, bktName, objName, query, complete)
+	hc.Handler().CompleteMultipartUploadHandler(w, r)
+	completeResponse := &CompleteMultipartUploadResponse{}
+	readResponse(t, w, http.StatusOK, completeResponse)
+	require.Regexp(t, "^[0-9a-f]{32}-2$", completeResponse.ETag)
+
+	headers := headObjectHeaders(t, hc, bktName, objName)
+	require.Equal(t, completeResponse.ETag, headers.Get(api.ETag))
+
+	listResponse := listObjectsV2(t, hc, bktName, "", "", "", "", -1)
+	require.Len(t, listResponse.Contents, 1)
+	require.Equal(t, completeResponse.ETag, listResponse.Contents[0].ETag)
+}
+
+func TestListPartsPagination(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-to-list-parts", "object-multipart"
+	createTestBucket(hc, bktName)
+
+	uploadInfo := createMultipartUpload(hc, bktName, objName, map[string]string{})
+	for i := 1; i <= 3; i++ {
+		uploadPart(hc, bktName, objName, uploadInfo.UploadID, i, 10)
+	}
+
+	list := listParts(hc, bktName, objName, uploadInfo.UploadID, "2", "")
+	require.True(t, list.IsTruncated)
+	require.Equal(t, 2, list.NextPartNumberMarker)
+	require.Len(t, list.Parts, 2)
+	require.Equal(t, 1, list.Parts[0].PartNumber)
+
+	list = listParts(hc, bktName, objName, uploadInfo.UploadID, "2", strconv.Itoa(list.NextPartNumberMarker))
+	require.False(t, list.IsTruncated)
+	require.Len(t, list.Parts, 1)
+	require.Equal(t, 3, list.Parts[0].PartNumber)
+
+	list = listParts(hc, bktName, objName, uploadInfo.UploadID, "", "5")
+	require.False(t, list.IsTruncated)
+	require.Empty(t, list.Parts)
+
+	list = listParts(hc, bktName, objName, uploadInfo.UploadID, "0", "")
+	require.True(t, list.IsTruncated)
+	require.Empty(t, list.Parts)
+
+	query := make(url.Values)
+	query.Set(uploadIDQuery, uploadInfo.UploadID)
+	query.Set("part-number-marker", "-1")
+	w, r := prepareTestRequestWithQuery(hc, bktName, objName, query, nil)
+	hc.Handler().ListPartsHandler(w, r)
+	assertStatus(t, w, http.StatusBadRequest)
+}
+
+func listParts(hc *handlerContext, bktName, objName, uploadID, maxParts, partNumberMarker string) *ListPartsResponse {
+	query := make(url.Values)
+	query.Set(uploadIDQuery, uploadID)
+	if maxParts != "" {
+		query.Set("max-parts", maxParts)
+	}
+	if partNumberMarker != "" {
+		query.Set("part-number-marker", partNumberMarker)
+	}
+
+	w, r := prepareTestRequestWithQuery(hc, bktName, objName, query, nil)
+	hc.Handler().ListPartsHandler(w, r)
+	listPartsResponse := &ListPartsResponse{}
+	readResponse(hc.t, w, http.StatusOK, listPartsResponse)
+
+	return listPartsResponse
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
@@ -36,6 +37,9 @@ const (
 	filterRuleSuffixName = "suffix"
 	filterRulePrefixName = "prefix"
 
+	// maxFilterRuleValueSize is a maximum size of prefix and suffix of filter rules in bytes.
+	maxFilterRuleValueSize = 1024
+
 	EventObjectCreated                                = "s3:ObjectCreated:*"
 	EventObjectCreatedPut                             = "s3:ObjectCreated:Put"
 	EventObjectCreatedPost                            = "s3:ObjectCreated:Post"
@@ -109,11 +113,16 @@ func (h *handler) PutBucketNotificationHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if _, err = h.checkBucketConfiguration(r.Context(), conf, reqInfo); err != nil {
+	if _, err = h.checkBucketConfiguration(conf); err != nil {
 		h.logAndSendError(w, "couldn't check bucket configuration", reqInfo, err)
 		return
 	}
 
+	if err = h.checkDestinations(r.Context(), bktInfo, conf, reqInfo); err != nil {
+		h.logAndSendError(w, "couldn't validate notification destinations", reqInfo, err)
+		return
+	}
+
 	p := &layer.PutBucketNotificationConfigurationParams{
 		RequestInfo:   reqInfo,
 		BktInfo:       bktInfo,
@@ -164,6 +173,8 @@ func (h *handler) sendNotifications(ctx context.Context, p *SendNotificationPara
 	box, err := layer.GetBoxData(ctx)
 	if err == nil && box.Gate.BearerToken != nil {
 		p.User = bearer.ResolveIssuer(*box.Gate.BearerToken).EncodeToString()
+	} else {
+		p.User = api.GetPrincipal(ctx)
 	}
 
 	p.Time = layer.TimeNow(ctx)
@@ -174,7 +185,7 @@ func (h *handler) sendNotifications(ctx context.Context, p *SendNotificationPara
 }
 
 // checkBucketConfiguration checks notification configuration and generates an ID for configurations with empty ids.
-func (h *handler) checkBucketConfiguration(ctx context.Context, conf *data.NotificationConfiguration, r *api.ReqInfo) (completed bool, err error) {
+func (h *handler) checkBucketConfiguration(conf *data.NotificationConfiguration) (completed bool, err error) {
 	if conf == nil {
 		return
 	}
@@ -192,12 +203,10 @@ func (h *handler) checkBucketConfiguration(ctx context.Context, conf *data.Notif
 			return
 		}
 
-		if h.cfg.NotificatorEnabled {
-			if err = h.notificator.SendTestNotification(q.QueueArn, r.BucketName, r.RequestID, r.Host, layer.TimeNow(ctx)); err != nil {
+		for _, prev := range conf.QueueConfigurations[:i] {
+			if err = checkOverlapping(prev, q); err != nil {
 				return
 			}
-		} else {
-			h.log.Warn("failed to send test event because notifications is disabled")
 		}
 
 		if q.ID == "" {
@@ -209,26 +218,124 @@ func (h *handler) checkBucketConfiguration(ctx context.Context, conf *data.Notif
 	return
 }
 
+// checkDestinations sends s3:TestEvent to destinations which aren't used by the current
+// configuration of the bucket, so new destinations are checked to be reachable.
+func (h *handler) checkDestinations(ctx context.Context, bktInfo *data.BucketInfo, conf *data.NotificationConfiguration, r *api.ReqInfo) error {
+	if len(conf.QueueConfigurations) == 0 {
+		return nil
+	}
+
+	if !h.cfg.NotificatorEnabled {
+		h.log.Warn("failed to send test event because notifications is disabled")
+		return nil
+	}
+
+	current, err := h.obj.GetBucketNotificationConfiguration(ctx, bktInfo)
+	if err != nil {
+		return fmt.Errorf("failed to get notification configuration: %w", err)
+	}
+
+	checked := make(map[string]struct{}, len(current.QueueConfigurations))
+	for _, q := range current.QueueConfigurations {
+		checked[q.QueueArn] = struct{}{}
+	}
+
+	for _, q := range conf.QueueConfigurations {
+		if _, ok := checked[q.QueueArn]; ok {
+			continue
+		}
+		checked[q.QueueArn] = struct{}{}
+
+		err = h.notificator.SendTestNotification(q.QueueArn, r.BucketName, r.RequestID, r.DeploymentID, layer.TimeNow(ctx))
+		if err != nil {
+			if errors.IsS3Error(err, errors.ErrARNNotification) {
+				return err
+			}
+			return errors.GetAPIErrorWithError(errors.ErrNotificationDestinationValidation, fmt.Errorf("%s: %w", q.QueueArn, err))
+		}
+	}
+
+	return nil
+}
+
+// checkRules checks filter rules and brings their names to lower case, as names are case-insensitive.
 func checkRules(rules []data.FilterRule) error {
 	names := make(map[string]struct{})
 
-	for _, r := range rules {
-		if r.Name != filterRuleSuffixName && r.Name != filterRulePrefixName {
+	for i, r := range rules {
+		name := strings.ToLower(r.Name)
+		if name != filterRuleSuffixName && name != filterRulePrefixName {
 			return errors.GetAPIError(errors.ErrFilterNameInvalid)
 		}
-		if _, ok := names[r.Name]; ok {
-			if r.Name == filterRuleSuffixName {
+		if _, ok := names[name]; ok {
+			if name == filterRuleSuffixName {
 				return errors.GetAPIError(errors.ErrFilterNameSuffix)
 			}
 			return errors.GetAPIError(errors.ErrFilterNamePrefix)
 		}
+		if len(r.Value) > maxFilterRuleValueSize || !utf8.ValidString(r.Value) {
+			return errors.GetAPIError(errors.ErrFilterValueInvalid)
+		}
 
-		names[r.Name] = struct{}{}
+		names[name] = struct{}{}
+		rules[i].Name = name
 	}
 
 	return nil
 }
 
+// checkOverlapping checks that an object event can't match both configurations:
+// configurations sharing an event type must have filters which can't match the same key.
+func checkOverlapping(a, b data.QueueConfiguration) error {
+	if !eventsOverlap(a.Events, b.Events) {
+		return nil
+	}
+
+	aPrefix, aSuffix := filterRuleValues(a.Filter.Key.FilterRules)
+	bPrefix, bSuffix := filterRuleValues(b.Filter.Key.FilterRules)
+	if !(strings.HasPrefix(aPrefix, bPrefix) || strings.HasPrefix(bPrefix, aPrefix)) ||
+		!(strings.HasSuffix(aSuffix, bSuffix) || strings.HasSuffix(bSuffix, aSuffix)) {
+		return nil
+	}
+
+	if len(a.Filter.Key.FilterRules) == 0 && len(b.Filter.Key.FilterRules) == 0 {
+		return errors.GetAPIError(errors.ErrOverlappingConfigs)
+	}
+	return errors.GetAPIError(errors.ErrOverlappingFilterNotification)
+}
+
+func eventsOverlap(a, b []string) bool {
+	for _, e1 := range a {
+		for _, e2 := range b {
+			if eventMatches(e1, e2) || eventMatches(e2, e1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// eventMatches checks if the event type matches the configured event,
+// events ending with * (s3:ObjectCreated:*, s3:ObjectRemoved:* etc) match all types of the group.
+func eventMatches(eventType, configured string) bool {
+	if strings.HasSuffix(configured, "*") {
+		return strings.HasPrefix(eventType, configured[:len(configured)-1])
+	}
+	return eventType == configured
+}
+
+func filterRuleValues(rules []data.FilterRule) (prefix, suffix string) {
+	for _, r := range rules {
+		switch strings.ToLower(r.Name) {
+		case filterRulePrefixName:
+			prefix = r.Value
+		case filterRuleSuffixName:
+			suffix = r.Value
+		}
+	}
+	return
+}
+
 func checkEvents(events []string) error {
 	for _, e := range events {
 		if _, ok := validEvents[e]; !ok {
@@ -245,9 +352,7 @@ func filterSubjects(conf *data.NotificationConfiguration, eventType, objName str
 	for _, t := range conf.QueueConfigurations {
 		event := false
 		for _, e := range t.Events {
-			// the second condition is comparison with the events ending with *:
-			// s3:ObjectCreated:*, s3:ObjectRemoved:* etc without the last char
-			if eventType == e || strings.HasPrefix(eventType, e[:len(e)-1]) {
+			if eventMatches(eventType, e) {
 				event = true
 				break
 			}
@@ -257,15 +362,8 @@ func filterSubjects(conf *data.NotificationConfiguration, eventType, objName str
 			continue
 		}
 
-		filter := true
-		for _, f := range t.Filter.Key.FilterRules {
-			if f.Name == filterRulePrefixName && !strings.HasPrefix(objName, f.Value) ||
-				f.Name == filterRuleSuffixName && !strings.HasSuffix(objName, f.Value) {
-				filter = false
-				break
-			}
-		}
-		if filter {
+		prefix, suffix := filterRuleValues(t.Filter.Key.FilterRules)
+		if strings.HasPrefix(objName, prefix) && strings.HasSuffix(objName, suffix) {
 			topics[t.ID] = t.QueueArn
 		}
 	}
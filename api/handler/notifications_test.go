@@ -1,10 +1,18 @@
 package handler
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	"github.com/stretchr/testify/require"
 )
 
@@ -56,6 +64,11 @@ func TestFilterSubjects(t *testing.T) {
 		require.Len(t, topics, 1)
 		require.Equal(t, topics["test2"], "test2")
 	})
+
+	t.Run("no topics because of not suitable exact event", func(t *testing.T) {
+		topics := filterSubjects(config, EventObjectRemovedDeleteMarkerCreated, "dir/a.png")
+		require.Empty(t, topics)
+	})
 }
 
 func TestCheckRules(t *testing.T) {
@@ -112,4 +125,174 @@ func TestCheckRules(t *testing.T) {
 		err := checkRules(rules)
 		require.ErrorIs(t, err, errors.GetAPIError(errors.ErrFilterNamePrefix))
 	})
+
+	t.Run("case-insensitive names", func(t *testing.T) {
+		rules := []data.FilterRule{
+			{Name: "Prefix", Value: "dir/"},
+			{Name: "SUFFIX", Value: ".png"},
+		}
+		require.NoError(t, checkRules(rules))
+		require.Equal(t, filterRulePrefixName, rules[0].Name)
+		require.Equal(t, filterRuleSuffixName, rules[1].Name)
+
+		rules = append(rules, data.FilterRule{Name: "prefix", Value: "other/"})
+		require.ErrorIs(t, checkRules(rules), errors.GetAPIError(errors.ErrFilterNamePrefix))
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		rules := []data.FilterRule{{Name: "prefix", Value: strings.Repeat("a", maxFilterRuleValueSize+1)}}
+		require.ErrorIs(t, checkRules(rules), errors.GetAPIError(errors.ErrFilterValueInvalid))
+
+		rules = []data.FilterRule{{Name: "suffix", Value: "\xff"}}
+		require.ErrorIs(t, checkRules(rules), errors.GetAPIError(errors.ErrFilterValueInvalid))
+	})
+}
+
+func TestCheckOverlapping(t *testing.T) {
+	queue := func(events []string, prefix, suffix string) data.QueueConfiguration {
+		q := data.QueueConfiguration{Events: events}
+		if prefix != "" {
+			q.Filter.Key.FilterRules = append(q.Filter.Key.FilterRules, data.FilterRule{Name: filterRulePrefixName, Value: prefix})
+		}
+		if suffix != "" {
+			q.Filter.Key.FilterRules = append(q.Filter.Key.FilterRules, data.FilterRule{Name: filterRuleSuffixName, Value: suffix})
+		}
+		return q
+	}
+
+	for _, tc := range []struct {
+		name string
+		a, b data.QueueConfiguration
+		err  errors.ErrorCode
+	}{
+		{
+			name: "different events",
+			a:    queue([]string{EventObjectCreated}, "", ""),
+			b:    queue([]string{EventObjectRemovedDelete}, "", ""),
+		},
+		{
+			name: "same events without filters",
+			a:    queue([]string{EventObjectCreatedPut}, "", ""),
+			b:    queue([]string{EventObjectCreatedPut}, "", ""),
+			err:  errors.ErrOverlappingConfigs,
+		},
+		{
+			name: "wildcard event",
+			a:    queue([]string{EventObjectCreated}, "images/", ""),
+			b:    queue([]string{EventObjectCreatedCopy}, "images/2022/", ""),
+			err:  errors.ErrOverlappingFilterNotification,
+		},
+		{
+			name: "different prefixes",
+			a:    queue([]string{EventObjectCreated}, "images/", ".png"),
+			b:    queue([]string{EventObjectCreated}, "docs/", ".png"),
+		},
+		{
+			name: "different suffixes",
+			a:    queue([]string{EventObjectCreated}, "images/", ".png"),
+			b:    queue([]string{EventObjectCreated}, "images/", ".jpg"),
+		},
+		{
+			name: "overlapping suffixes",
+			a:    queue([]string{EventObjectCreated}, "", ".png"),
+			b:    queue([]string{EventObjectCreated}, "images/", "thumb.png"),
+			err:  errors.ErrOverlappingFilterNotification,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkOverlapping(tc.a, tc.b)
+			if tc.err == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, errors.GetAPIError(tc.err))
+		})
+	}
+}
+
+func TestRemovedObjectNotification(t *testing.T) {
+	bktInfo, reqInfo := &data.BucketInfo{Name: "bucket"}, &api.ReqInfo{}
+	obj := &layer.VersionedObject{Name: "obj", DeleteMarkVersion: "marker", DeleteMarkerEtag: "etag"}
+
+	for _, tc := range []struct {
+		name       string
+		versioning string
+		versionID  string
+		event      string
+		version    string
+	}{
+		{name: "unversioned", versioning: data.VersioningUnversioned, event: EventObjectRemovedDelete},
+		{name: "enabled", versioning: data.VersioningEnabled, event: EventObjectRemovedDeleteMarkerCreated, version: "marker"},
+		{name: "suspended", versioning: data.VersioningSuspended, event: EventObjectRemovedDeleteMarkerCreated, version: "marker"},
+		{name: "version", versioning: data.VersioningEnabled, versionID: "marker", event: EventObjectRemovedDelete, version: "marker"},
+		{name: "null version", versioning: data.VersioningSuspended, versionID: data.UnversionedObjectVersionID,
+			event: EventObjectRemovedDelete, version: data.UnversionedObjectVersionID},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := &data.BucketSettings{Versioning: tc.versioning}
+			p := removedObjectNotification(bktInfo, reqInfo, settings, tc.versionID, obj)
+			require.Equal(t, tc.event, p.Event)
+			require.Equal(t, "obj", p.NotificationInfo.Name)
+			require.Equal(t, tc.version, p.NotificationInfo.Version)
+		})
+	}
+}
+
+type testNotificator struct {
+	unreachable map[string]bool
+	tested      []string
+}
+
+func (n *testNotificator) SendNotifications(map[string]string, *SendNotificationParams) error {
+	return nil
+}
+
+func (n *testNotificator) SendTestNotification(topic, _, _, _ string, _ time.Time) error {
+	n.tested = append(n.tested, topic)
+	if n.unreachable[topic] {
+		return fmt.Errorf("connection refused")
+	}
+	return nil
+}
+
+func TestPutBucketNotificationDestinations(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	bktName := "bucket-notifications"
+	bktInfo := createTestBucket(hc, bktName)
+
+	notificator := &testNotificator{unreachable: map[string]bool{"webhook:down": true}}
+	hc.h.notificator = notificator
+	hc.h.cfg.NotificatorEnabled = true
+
+	putConfig := func(arns ...string) *httptest.ResponseRecorder {
+		conf := &data.NotificationConfiguration{}
+		for i, arn := range arns {
+			conf.QueueConfigurations = append(conf.QueueConfigurations, data.QueueConfiguration{
+				QueueArn: arn,
+				Events:   []string{EventObjectCreated},
+				Filter: data.Filter{Key: data.Key{FilterRules: []data.FilterRule{
+					{Name: filterRulePrefixName, Value: strconv.Itoa(i) + "/"},
+				}}},
+			})
+		}
+		w, r := prepareTestRequest(hc, bktName, "", conf)
+		hc.Handler().PutBucketNotificationHandler(w, r)
+		return w
+	}
+
+	assertStatus(t, putConfig("webhook:a", "webhook:a", "webhook:b"), http.StatusOK)
+	require.Equal(t, []string{"webhook:a", "webhook:b"}, notificator.tested)
+
+	// only new destinations are tested
+	assertStatus(t, putConfig("webhook:a", "webhook:c"), http.StatusOK)
+	require.Equal(t, []string{"webhook:a", "webhook:b", "webhook:c"}, notificator.tested)
+
+	w := putConfig("webhook:a", "webhook:down")
+	assertStatus(t, w, http.StatusBadRequest)
+	require.Contains(t, w.Body.String(), "webhook:down")
+
+	conf, err := hc.Layer().GetBucketNotificationConfiguration(hc.Context(), bktInfo)
+	require.NoError(t, err)
+	require.Len(t, conf.QueueConfigurations, 2)
+	require.Equal(t, "webhook:c", conf.QueueConfigurations[1].QueueArn)
 }
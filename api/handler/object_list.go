@@ -7,10 +7,10 @@ import (
 	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
-	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 )
 
 // ListObjectsV1Handler handles objects listing requests for API version 1.
@@ -33,30 +33,11 @@ func (h *handler) ListObjectsV1Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = api.EncodeToResponse(w, encodeV1(params, list)); err != nil {
+	if err = writeListObjectsV1(w, params, list, encodeOwner); err != nil {
 		h.logAndSendError(w, "something went wrong", reqInfo, err)
 	}
 }
 
-func encodeV1(p *layer.ListObjectsParamsV1, list *layer.ListObjectsInfoV1) *ListObjectsV1Response {
-	res := &ListObjectsV1Response{
-		Name:         p.BktInfo.Name,
-		EncodingType: p.Encode,
-		Marker:       p.Marker,
-		Prefix:       p.Prefix,
-		MaxKeys:      p.MaxKeys,
-		Delimiter:    p.Delimiter,
-		IsTruncated:  list.IsTruncated,
-		NextMarker:   list.NextMarker,
-	}
-
-	res.CommonPrefixes = fillPrefixes(list.Prefixes, p.Encode)
-
-	res.Contents = fillContentsWithOwner(list.Objects, p.Encode)
-
-	return res
-}
-
 // ListObjectsV2Handler handles objects listing requests for API version 2.
 func (h *handler) ListObjectsV2Handler(w http.ResponseWriter, r *http.Request) {
 	reqInfo := api.GetReqInfo(r.Context())
@@ -77,30 +58,14 @@ func (h *handler) ListObjectsV2Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = api.EncodeToResponse(w, encodeV2(params, list)); err != nil {
-		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	var ownerEncoder func(user.ID) *Owner
+	if params.FetchOwner {
+		ownerEncoder = encodeOwner
 	}
-}
 
-func encodeV2(p *layer.ListObjectsParamsV2, list *layer.ListObjectsInfoV2) *ListObjectsV2Response {
-	res := &ListObjectsV2Response{
-		Name:                  p.BktInfo.Name,
-		EncodingType:          p.Encode,
-		Prefix:                s3PathEncode(p.Prefix, p.Encode),
-		KeyCount:              len(list.Objects) + len(list.Prefixes),
-		MaxKeys:               p.MaxKeys,
-		Delimiter:             s3PathEncode(p.Delimiter, p.Encode),
-		StartAfter:            s3PathEncode(p.StartAfter, p.Encode),
-		IsTruncated:           list.IsTruncated,
-		ContinuationToken:     p.ContinuationToken,
-		NextContinuationToken: list.NextContinuationToken,
+	if err = writeListObjectsV2(w, params, list, ownerEncoder); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
 	}
-
-	res.CommonPrefixes = fillPrefixes(list.Prefixes, p.Encode)
-
-	res.Contents = fillContents(list.Objects, p.Encode, p.FetchOwner)
-
-	return res
 }
 
 func parseListObjectsArgsV1(reqInfo *api.ReqInfo) (*layer.ListObjectsParamsV1, error) {
@@ -150,12 +115,12 @@ func parseListObjectArgs(reqInfo *api.ReqInfo) (*layer.ListObjectsParamsCommon,
 	)
 
 	res.Delimiter = queryValues.Get("delimiter")
-	res.Encode = queryValues.Get("encoding-type")
+	if res.Encode, err = parseEncodingType(queryValues.Get("encoding-type")); err != nil {
+		return nil, err
+	}
 
-	if queryValues.Get("max-keys") == "" {
-		res.MaxKeys = maxObjectList
-	} else if res.MaxKeys, err = strconv.Atoi(queryValues.Get("max-keys")); err != nil || res.MaxKeys < 0 {
-		return nil, errors.GetAPIError(errors.ErrInvalidMaxKeys)
+	if res.MaxKeys, err = parseMaxKeys(queryValues.Get("max-keys"), maxObjectList, errors.ErrInvalidMaxKeys); err != nil {
+		return nil, err
 	}
 
 	res.Prefix = queryValues.Get("prefix")
@@ -184,30 +149,17 @@ func fillPrefixes(src []string, encode string) []CommonPrefix {
 	return dst
 }
 
-func fillContentsWithOwner(src []*data.ObjectInfo, encode string) []Object {
-	return fillContents(src, encode, true)
-}
-
-func fillContents(src []*data.ObjectInfo, encode string, fetchOwner bool) []Object {
-	var dst []Object
-	for _, obj := range src {
-		res := Object{
-			Key:          s3PathEncode(obj.Name, encode),
-			Size:         obj.Size,
-			LastModified: obj.Created.UTC().Format(time.RFC3339),
-			ETag:         obj.HashSum,
-		}
-
-		if fetchOwner {
-			res.Owner = &Owner{
-				ID:          obj.Owner.String(),
-				DisplayName: obj.Owner.String(),
-			}
-		}
+// encodeOwner forms Owner element of the listing, owner ID is used as display name.
+func encodeOwner(owner user.ID) *Owner {
+	// owner is unknown if object info was formed from the tree node only
+	if owner.Equals(user.ID{}) {
+		return nil
+	}
 
-		dst = append(dst, res)
+	return &Owner{
+		ID:          owner.String(),
+		DisplayName: owner.String(),
 	}
-	return dst
 }
 
 func (h *handler) ListBucketObjectVersionsHandler(w http.ResponseWriter, r *http.Request) {
@@ -229,7 +181,7 @@ func (h *handler) ListBucketObjectVersionsHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	response := encodeListObjectVersionsToResponse(info, p.BktInfo.Name)
+	response := encodeListObjectVersionsToResponse(info, p)
 	if err = api.EncodeToResponse(w, response); err != nil {
 		h.logAndSendError(w, "something went wrong", reqInfo, err)
 	}
@@ -242,54 +194,57 @@ func parseListObjectVersionsRequest(reqInfo *api.ReqInfo) (*layer.ListObjectVers
 		queryValues = reqInfo.URL.Query()
 	)
 
-	if queryValues.Get("max-keys") == "" {
-		res.MaxKeys = maxObjectList
-	} else if res.MaxKeys, err = strconv.Atoi(queryValues.Get("max-keys")); err != nil || res.MaxKeys <= 0 {
-		return nil, errors.GetAPIError(errors.ErrInvalidMaxKeys)
+	if res.MaxKeys, err = parseMaxKeys(queryValues.Get("max-keys"), maxObjectList, errors.ErrInvalidMaxKeys); err != nil {
+		return nil, err
 	}
 
 	res.Prefix = queryValues.Get("prefix")
-	res.KeyMarker = queryValues.Get("marker")
+	res.KeyMarker = queryValues.Get("key-marker")
 	res.Delimiter = queryValues.Get("delimiter")
-	res.Encode = queryValues.Get("encoding-type")
+	if res.Encode, err = parseEncodingType(queryValues.Get("encoding-type")); err != nil {
+		return nil, err
+	}
 	res.VersionIDMarker = queryValues.Get("version-id-marker")
 
 	return &res, nil
 }
 
-func encodeListObjectVersionsToResponse(info *layer.ListObjectVersionsInfo, bucketName string) *ListObjectsVersionsResponse {
+func encodeListObjectVersionsToResponse(info *layer.ListObjectVersionsInfo, p *layer.ListObjectVersionsParams) *ListObjectsVersionsResponse {
 	res := ListObjectsVersionsResponse{
-		Name:                bucketName,
+		Name:                p.BktInfo.Name,
+		EncodingType:        p.Encode,
+		Prefix:              s3PathEncode(p.Prefix, p.Encode),
+		Delimiter:           s3PathEncode(p.Delimiter, p.Encode),
+		MaxKeys:             p.MaxKeys,
 		IsTruncated:         info.IsTruncated,
-		KeyMarker:           info.KeyMarker,
-		NextKeyMarker:       info.NextKeyMarker,
+		KeyMarker:           s3PathEncode(info.KeyMarker, p.Encode),
+		NextKeyMarker:       s3PathEncode(info.NextKeyMarker, p.Encode),
 		NextVersionIDMarker: info.NextVersionIDMarker,
 		VersionIDMarker:     info.VersionIDMarker,
 	}
 
-	for _, prefix := range info.CommonPrefixes {
-		res.CommonPrefixes = append(res.CommonPrefixes, CommonPrefix{Prefix: prefix})
-	}
+	res.CommonPrefixes = fillPrefixes(info.CommonPrefixes, p.Encode)
 
 	for _, ver := range info.Version {
 		res.Version = append(res.Version, ObjectVersionResponse{
 			IsLatest:     ver.IsLatest,
-			Key:          ver.ObjectInfo.Name,
+			Key:          s3PathEncode(ver.ObjectInfo.Name, p.Encode),
 			LastModified: ver.ObjectInfo.Created.UTC().Format(time.RFC3339),
 			Owner: Owner{
 				ID:          ver.ObjectInfo.Owner.String(),
 				DisplayName: ver.ObjectInfo.Owner.String(),
 			},
-			Size:      ver.ObjectInfo.Size,
-			VersionID: ver.Version(),
-			ETag:      ver.ObjectInfo.HashSum,
+			Size:         ver.ObjectInfo.Size,
+			StorageClass: storageClassOrDefault(ver.ObjectInfo.Headers[layer.AttributeStorageClass]),
+			VersionID:    ver.Version(),
+			ETag:         ver.ObjectInfo.HashSum,
 		})
 	}
 	// this loop is not starting till versioning is not implemented
 	for _, del := range info.DeleteMarker {
 		res.DeleteMarker = append(res.DeleteMarker, DeleteMarkerEntry{
 			IsLatest:     del.IsLatest,
-			Key:          del.ObjectInfo.Name,
+			Key:          s3PathEncode(del.ObjectInfo.Name, p.Encode),
 			LastModified: del.ObjectInfo.Created.UTC().Format(time.RFC3339),
 			Owner: Owner{
 				ID:          del.ObjectInfo.Owner.String(),
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -129,6 +130,226 @@ func TestS3BucketListV2DelimiterPrefix(t *testing.T) {
 	validateListV2(t, tc, bktName, prefix, delim, "", 2, false, true, []string{"boo/bar"}, []string{"boo/baz/"})
 }
 
+func TestParseMaxKeys(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected int
+		err      bool
+	}{
+		{value: "", expected: maxObjectList},
+		{value: "0", expected: 0},
+		{value: "10", expected: 10},
+		{value: "1000", expected: maxObjectList},
+		{value: "2000", expected: maxObjectList},
+		{value: "2147483647", expected: maxObjectList},
+		{value: "2147483648", err: true},
+		{value: "-1", err: true},
+		{value: "ten", err: true},
+		{value: "1.5", err: true},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			maxKeys, err := parseMaxKeys(tc.value, maxObjectList, errors.ErrInvalidMaxKeys)
+			if tc.err {
+				require.True(t, errors.IsS3Error(err, errors.ErrInvalidMaxKeys))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, maxKeys)
+		})
+	}
+}
+
+func TestListObjectsMaxKeys(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-max-keys", "object"
+	createBucketAndObject(tc, bktName, objName)
+
+	listV1 := listObjectsV1(t, tc, bktName, "", "", "", 5000)
+	require.Equal(t, maxObjectList, listV1.MaxKeys)
+	require.Len(t, listV1.Contents, 1)
+
+	listV2 := listObjectsV2(t, tc, bktName, "", "", "", "", 0)
+	require.Equal(t, 0, listV2.MaxKeys)
+	require.Empty(t, listV2.Contents)
+	require.False(t, listV2.IsTruncated)
+
+	versions := listObjectVersions(t, tc, bktName, "", "", "", "", 0)
+	require.Empty(t, versions.Version)
+	require.False(t, versions.IsTruncated)
+
+	for _, value := range []string{"-1", "invalid"} {
+		query := make(url.Values)
+		query.Set("max-keys", value)
+		w, r := prepareTestFullRequest(tc, bktName, "", query, nil)
+		tc.Handler().ListObjectsV2Handler(w, r)
+		assertS3Error(t, w, errors.GetAPIError(errors.ErrInvalidMaxKeys))
+
+		w, r = prepareTestFullRequest(tc, bktName, "", query, nil)
+		tc.Handler().ListBucketObjectVersionsHandler(w, r)
+		assertS3Error(t, w, errors.GetAPIError(errors.ErrInvalidMaxKeys))
+	}
+}
+
+func TestListObjectsDirectoryMarkers(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-directory-markers"
+	createTestBucket(tc, bktName)
+
+	putObjectContent(tc, bktName, "dir/", "")
+	putObjectContent(tc, bktName, "dir/obj", "content")
+	putObjectContent(tc, bktName, "empty/", "")
+	putObjectContent(tc, bktName, "file/", "content")
+
+	var empty []string
+	validateListV2(t, tc, bktName, "", "/", "", -1, false, true, empty, []string{"dir/", "empty/", "file/"})
+	validateListV2(t, tc, bktName, "dir/", "/", "", -1, false, true, []string{"dir/obj"}, empty)
+	validateListV2(t, tc, bktName, "empty/", "/", "", -1, false, true, empty, empty)
+	validateListV2(t, tc, bktName, "file/", "/", "", -1, false, true, []string{"file/"}, empty)
+	validateListV2(t, tc, bktName, "", "", "", -1, false, true, []string{"dir/", "dir/obj", "empty/", "file/"}, empty)
+
+	list := listObjectsV1(t, tc, bktName, "empty/", "/", "", -1)
+	require.Empty(t, list.Contents)
+
+	checkFound(t, tc, bktName, "empty/", emptyVersion)
+	deleteObject(t, tc, bktName, "empty/", emptyVersion)
+	checkNotFound(t, tc, bktName, "empty/", emptyVersion)
+	validateListV2(t, tc, bktName, "", "/", "", -1, false, true, empty, []string{"dir/", "file/"})
+}
+
+func TestListObjectVersionsDelimiter(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-versions-listing"
+	bktInfo, _ := createVersionedBucketAndObject(t, tc, bktName, "a")
+	createTestObject(tc, bktInfo, "a")
+	for _, objName := range []string{"dir/b", "dir/c", "other/d", "z"} {
+		createTestObject(tc, bktInfo, objName)
+	}
+	deleteObject(t, tc, bktName, "other/d", emptyVersion)
+
+	res := listObjectVersions(t, tc, bktName, "", "/", "", "", -1)
+	require.False(t, res.IsTruncated)
+	require.Len(t, res.Version, 3)
+	require.Empty(t, res.DeleteMarker)
+	require.Len(t, res.CommonPrefixes, 2)
+	require.Equal(t, "dir/", res.CommonPrefixes[0].Prefix)
+	require.Equal(t, "other/", res.CommonPrefixes[1].Prefix)
+
+	res = listObjectVersions(t, tc, bktName, "", "/", "", "", 2)
+	require.True(t, res.IsTruncated)
+	require.Len(t, res.Version, 2)
+	require.Equal(t, "a", res.Version[1].Key)
+	require.Equal(t, "a", res.NextKeyMarker)
+	require.Equal(t, res.Version[1].VersionID, res.NextVersionIDMarker)
+
+	res = listObjectVersions(t, tc, bktName, "", "/", res.NextKeyMarker, res.NextVersionIDMarker, 2)
+	require.True(t, res.IsTruncated)
+	require.Empty(t, res.Version)
+	require.Len(t, res.CommonPrefixes, 2)
+	require.Equal(t, "other/", res.NextKeyMarker)
+	require.Empty(t, res.NextVersionIDMarker)
+
+	res = listObjectVersions(t, tc, bktName, "", "/", res.NextKeyMarker, res.NextVersionIDMarker, 2)
+	require.False(t, res.IsTruncated)
+	require.Len(t, res.Version, 1)
+	require.Equal(t, "z", res.Version[0].Key)
+
+	res = listObjectVersions(t, tc, bktName, "other/", "/", "", "", -1)
+	require.Len(t, res.Version, 1)
+	require.Len(t, res.DeleteMarker, 1)
+	require.True(t, res.DeleteMarker[0].IsLatest)
+	require.Equal(t, "other/d", res.DeleteMarker[0].Key)
+}
+
+func listObjectVersions(t *testing.T, tc *handlerContext, bktName, prefix, delimiter, keyMarker, versionIDMarker string, maxKeys int) *ListObjectsVersionsResponse {
+	query := prepareCommonListObjectsQuery(prefix, delimiter, maxKeys)
+	if len(keyMarker) != 0 {
+		query.Add("key-marker", keyMarker)
+	}
+	if len(versionIDMarker) != 0 {
+		query.Add("version-id-marker", versionIDMarker)
+	}
+
+	w, r := prepareTestFullRequest(tc, bktName, "", query, nil)
+	tc.Handler().ListBucketObjectVersionsHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	res := &ListObjectsVersionsResponse{}
+	parseTestResponse(t, w, res)
+	return res
+}
+
+func TestListingEncodingType(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-encoded-listing", "dir/obj\x01 name"
+	createBucketAndObject(tc, bktName, objName)
+
+	query := prepareCommonListObjectsQuery("dir/", "", -1)
+	query.Add("encoding-type", "url")
+
+	w, r := prepareTestFullRequest(tc, bktName, "", query, nil)
+	tc.Handler().ListObjectsV1Handler(w, r)
+	listV1 := &ListObjectsV1Response{}
+	parseTestResponse(t, w, listV1)
+	requireURLEncoded(t, objName, listV1.Contents[0].Key)
+
+	w, r = prepareTestFullRequest(tc, bktName, "", query, nil)
+	tc.Handler().ListObjectsV2Handler(w, r)
+	listV2 := &ListObjectsV2Response{}
+	parseTestResponse(t, w, listV2)
+	requireURLEncoded(t, objName, listV2.Contents[0].Key)
+
+	w, r = prepareTestFullRequest(tc, bktName, "", query, nil)
+	tc.Handler().ListBucketObjectVersionsHandler(w, r)
+	versions := &ListObjectsVersionsResponse{}
+	parseTestResponse(t, w, versions)
+	require.Equal(t, "url", versions.EncodingType)
+	requireURLEncoded(t, objName, versions.Version[0].Key)
+
+	createMultipartUpload(tc, bktName, objName, map[string]string{})
+	w, r = prepareTestFullRequest(tc, bktName, "", query, nil)
+	tc.Handler().ListMultipartUploadsHandler(w, r)
+	uploads := &ListMultipartUploadsResponse{}
+	parseTestResponse(t, w, uploads)
+	requireURLEncoded(t, objName, uploads.Uploads[0].Key)
+
+	query.Set("encoding-type", "invalid")
+	w, r = prepareTestFullRequest(tc, bktName, "", query, nil)
+	tc.Handler().ListObjectsV2Handler(w, r)
+	assertStatus(t, w, http.StatusBadRequest)
+}
+
+func requireURLEncoded(t *testing.T, expected, encoded string) {
+	require.NotEqual(t, expected, encoded)
+	decoded, err := url.PathUnescape(encoded)
+	require.NoError(t, err)
+	require.Equal(t, expected, decoded)
+}
+
+func TestListObjectsFetchOwner(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-fetch-owner", "object"
+	createBucketAndObject(tc, bktName, objName)
+
+	query := prepareCommonListObjectsQuery("", "", -1)
+	w, r := prepareTestFullRequest(tc, bktName, "", query, nil)
+	tc.Handler().ListObjectsV2Handler(w, r)
+	list := &ListObjectsV2Response{}
+	parseTestResponse(t, w, list)
+	require.Nil(t, list.Contents[0].Owner)
+
+	query.Add("fetch-owner", "true")
+	w, r = prepareTestFullRequest(tc, bktName, "", query, nil)
+	tc.Handler().ListObjectsV2Handler(w, r)
+	list = &ListObjectsV2Response{}
+	parseTestResponse(t, w, list)
+	require.Equal(t, tc.owner.String(), list.Contents[0].Owner.ID)
+	require.Equal(t, tc.owner.String(), list.Contents[0].Owner.DisplayName)
+}
+
 func listObjectsV2(t *testing.T, tc *handlerContext, bktName, prefix, delimiter, startAfter, continuationToken string, maxKeys int) *ListObjectsV2Response {
 	query := prepareCommonListObjectsQuery(prefix, delimiter, maxKeys)
 	if len(startAfter) != 0 {
@@ -12,9 +12,11 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
@@ -24,6 +26,7 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
 	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
 	"go.uber.org/zap"
 )
@@ -168,6 +171,24 @@ const (
 	cannedACLAuthRead = "authenticated-read"
 )
 
+// Prefixes and suffixes of bucket names reserved by AWS S3.
+var (
+	reservedBucketPrefixes = []string{"xn--", "sthree-", "amzn-s3-demo-"}
+	reservedBucketSuffixes = []string{"-s3alias", "--ol-s3", ".mrap", "--x-s3"}
+)
+
+// maxUserMetadataSize is the limit of the total size of user-defined metadata keys and values.
+const maxUserMetadataSize = 2 * 1024
+
+// maxObjectNameSize is the limit of the object key length in bytes.
+const maxObjectNameSize = 1024
+
+// Prefixes of NeoFS system container attributes and of the uppercased headers which set them.
+const (
+	systemAttributePrefix = "__NEOFS__"
+	systemHeaderPrefix    = "NEOFS-"
+)
+
 type createBucketParams struct {
 	XMLName            xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CreateBucketConfiguration" json:"-"`
 	LocationConstraint string
@@ -182,6 +203,11 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 		reqInfo          = api.GetReqInfo(r.Context())
 	)
 
+	if err = checkObjectName(reqInfo.ObjectName); err != nil {
+		h.logAndSendError(w, "invalid object name", reqInfo, err)
+		return
+	}
+
 	if containsACL {
 		if sessionTokenEACL, err = getSessionTokenSetEACL(r.Context()); err != nil {
 			h.logAndSendError(w, "could not get eacl session token from a box", reqInfo, err)
@@ -201,20 +227,16 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	metadata := parseMetadata(r)
-	if contentType := r.Header.Get(api.ContentType); len(contentType) > 0 {
-		metadata[api.ContentType] = contentType
-	}
-	if cacheControl := r.Header.Get(api.CacheControl); len(cacheControl) > 0 {
-		metadata[api.CacheControl] = cacheControl
-	}
-	if expires := r.Header.Get(api.Expires); len(expires) > 0 {
-		metadata[api.Expires] = expires
+	metadata, err := parseMetadata(r)
+	if err != nil {
+		h.logAndSendError(w, "invalid metadata", reqInfo, err)
+		return
 	}
+	setStandardHeaders(metadata, r.Header)
 
-	copiesNumber, err := getCopiesNumberOrDefault(metadata, h.cfg.CopiesNumber)
+	copiesNumber, err := h.getCopiesNumber(metadata, r.Header)
 	if err != nil {
-		h.logAndSendError(w, "invalid copies number", reqInfo, err)
+		h.logAndSendError(w, "invalid copies number or storage class", reqInfo, err)
 		return
 	}
 
@@ -224,14 +246,30 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ifNotExists, err := parseIfNoneMatchOnWrite(r.Header)
+	if err != nil {
+		h.logAndSendError(w, "invalid conditional write header", reqInfo, err)
+		return
+	}
+
+	contentMD5, err := parseContentMD5(r.Header)
+	if err != nil {
+		h.logAndSendError(w, "invalid Content-MD5", reqInfo, err)
+		return
+	}
+
+	body := newRequestBody(r)
 	params := &layer.PutObjectParams{
 		BktInfo:      bktInfo,
 		Object:       reqInfo.ObjectName,
-		Reader:       r.Body,
+		Reader:       body,
 		Size:         r.ContentLength,
 		Header:       metadata,
 		Encryption:   encryptionParams,
 		CopiesNumber: copiesNumber,
+		IfNotExists:  ifNotExists,
+		ContentMD5:   contentMD5,
+		TagSet:       tagSet,
 	}
 
 	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
@@ -248,9 +286,7 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 
 	extendedObjInfo, err := h.obj.PutObject(r.Context(), params)
 	if err != nil {
-		_, err2 := io.Copy(io.Discard, r.Body)
-		err3 := r.Body.Close()
-		h.logAndSendError(w, "could not upload object", reqInfo, err, zap.Errors("body close errors", []error{err2, err3}))
+		h.logAndSendError(w, "could not upload object", reqInfo, err, zap.Errors("body close errors", body.discard(r)))
 		return
 	}
 	objInfo := extendedObjInfo.ObjectInfo
@@ -272,22 +308,6 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if tagSet != nil {
-		tagPrm := &layer.PutObjectTaggingParams{
-			ObjectVersion: &layer.ObjectVersion{
-				BktInfo:    bktInfo,
-				ObjectName: objInfo.Name,
-				VersionID:  objInfo.VersionID(),
-			},
-			TagSet:      tagSet,
-			NodeVersion: extendedObjInfo.NodeVersion,
-		}
-		if _, err = h.obj.PutObjectTagging(r.Context(), tagPrm); err != nil {
-			h.logAndSendError(w, "could not upload object tagging", reqInfo, err)
-			return
-		}
-	}
-
 	if newEaclTable != nil {
 		p := &layer.PutBucketACLParams{
 			BktInfo:      bktInfo,
@@ -312,6 +332,35 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 	api.WriteSuccessResponseHeadersOnly(w)
 }
 
+// getCopiesNumber validates storage class from the request headers, saves it to
+// the object metadata and returns copies number configured for this class.
+// Copies number set explicitly in metadata takes precedence.
+func (h *handler) getCopiesNumber(metadata map[string]string, header http.Header) (uint32, error) {
+	delete(metadata, layer.AttributeStorageClass)
+
+	storageClass := storageClassOrDefault(header.Get(api.AmzStorageClass))
+	copiesNumber, ok := h.cfg.StorageClasses[storageClass]
+	if !ok {
+		if storageClass != api.DefaultStorageClass {
+			return 0, errors.GetAPIError(errors.ErrInvalidStorageClass)
+		}
+		copiesNumber = h.cfg.CopiesNumber
+	}
+
+	if storageClass != api.DefaultStorageClass {
+		metadata[layer.AttributeStorageClass] = storageClass
+	}
+
+	return getCopiesNumberOrDefault(metadata, copiesNumber)
+}
+
+func storageClassOrDefault(storageClass string) string {
+	if storageClass == "" {
+		return api.DefaultStorageClass
+	}
+	return storageClass
+}
+
 func getCopiesNumberOrDefault(metadata map[string]string, defaultCopiesNumber uint32) (uint32, error) {
 	copiesNumberStr, ok := metadata[layer.AttributeNeofsCopiesNumber]
 	if !ok {
@@ -370,6 +419,47 @@ func formEncryptionParams(r *http.Request) (enc encryption.Params, err error) {
 	return enc, err
 }
 
+// setStandardHeaders puts standard HTTP headers that are stored along with object to metadata.
+func setStandardHeaders(metadata map[string]string, header http.Header) {
+	if contentType := header.Get(api.ContentType); len(contentType) > 0 {
+		metadata[api.ContentType] = contentType
+	}
+	if cacheControl := header.Get(api.CacheControl); len(cacheControl) > 0 {
+		metadata[api.CacheControl] = cacheControl
+	}
+	if expires := header.Get(api.Expires); len(expires) > 0 {
+		metadata[api.Expires] = expires
+	}
+}
+
+// parseIfNoneMatchOnWrite checks If-None-Match header of write requests.
+// Only '*' (object must not exist) is supported for writes.
+func parseIfNoneMatchOnWrite(header http.Header) (bool, error) {
+	switch header.Get(api.IfNoneMatch) {
+	case "":
+		return false, nil
+	case "*":
+		return true, nil
+	default:
+		return false, errors.GetAPIError(errors.ErrNotImplemented)
+	}
+}
+
+// parseContentMD5 decodes Content-MD5 header, nil is returned if the header isn't set.
+func parseContentMD5(header http.Header) ([]byte, error) {
+	value := header.Get(api.ContentMD5)
+	if value == "" {
+		return nil, nil
+	}
+
+	contentMD5, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(contentMD5) != md5.Size {
+		return nil, errors.GetAPIError(errors.ErrInvalidDigest)
+	}
+
+	return contentMD5, nil
+}
+
 func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 	var (
 		newEaclTable     *eacl.Table
@@ -417,6 +507,14 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 		size = head.Size
 		reqInfo.ObjectName = strings.ReplaceAll(reqInfo.ObjectName, "${filename}", head.Filename)
 	}
+	if err = checkObjectName(reqInfo.ObjectName); err != nil {
+		h.logAndSendError(w, "invalid object name", reqInfo, err)
+		return
+	}
+	if !isAllowedByPolicy(r.Context(), "s3:PutObject", reqInfo.BucketName, reqInfo.ObjectName) {
+		h.logAndSendError(w, "denied by inline policy of access box", reqInfo, errors.GetAPIError(errors.ErrAccessDenied))
+		return
+	}
 	if !policy.CheckContentLength(size) {
 		h.logAndSendError(w, "invalid content-length", reqInfo, errors.GetAPIError(errors.ErrInvalidArgument))
 		return
@@ -428,12 +526,20 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ifNotExists, err := parseIfNoneMatchOnWrite(r.Header)
+	if err != nil {
+		h.logAndSendError(w, "invalid conditional write header", reqInfo, err)
+		return
+	}
+
 	params := &layer.PutObjectParams{
-		BktInfo: bktInfo,
-		Object:  reqInfo.ObjectName,
-		Reader:  contentReader,
-		Size:    size,
-		Header:  metadata,
+		BktInfo:     bktInfo,
+		Object:      reqInfo.ObjectName,
+		Reader:      contentReader,
+		Size:        size,
+		Header:      metadata,
+		IfNotExists: ifNotExists,
+		TagSet:      tagSet,
 	}
 
 	extendedObjInfo, err := h.obj.PutObject(r.Context(), params)
@@ -465,22 +571,6 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if tagSet != nil {
-		tagPrm := &layer.PutObjectTaggingParams{
-			ObjectVersion: &layer.ObjectVersion{
-				BktInfo:    bktInfo,
-				ObjectName: objInfo.Name,
-				VersionID:  objInfo.VersionID(),
-			},
-			NodeVersion: extendedObjInfo.NodeVersion,
-		}
-
-		if _, err = h.obj.PutObjectTagging(r.Context(), tagPrm); err != nil {
-			h.logAndSendError(w, "could not upload object tagging", reqInfo, err)
-			return
-		}
-	}
-
 	if newEaclTable != nil {
 		p := &layer.PutBucketACLParams{
 			BktInfo:      bktInfo,
@@ -544,6 +634,7 @@ func checkPostPolicy(r *http.Request, reqInfo *api.ReqInfo, metadata map[string]
 		policy.empty = false
 	}
 
+	userMetadata := make(map[string]string)
 	for key, v := range r.MultipartForm.Value {
 		value := v[0]
 		if key == "file" || key == "policy" || key == "x-amz-signature" || strings.HasPrefix(key, "x-ignore-") {
@@ -555,7 +646,9 @@ func checkPostPolicy(r *http.Request, reqInfo *api.ReqInfo, metadata map[string]
 
 		prefix := strings.ToLower(api.MetadataPrefix)
 		if strings.HasPrefix(key, prefix) {
-			metadata[strings.TrimPrefix(key, prefix)] = value
+			metaKey := strings.TrimPrefix(key, prefix)
+			metadata[metaKey] = value
+			userMetadata[metaKey] = value
 		}
 
 		if key == "content-type" {
@@ -575,6 +668,10 @@ func checkPostPolicy(r *http.Request, reqInfo *api.ReqInfo, metadata map[string]
 		}
 	}
 
+	if err := checkUserMetadata(userMetadata); err != nil {
+		return nil, err
+	}
+
 	return policy, nil
 }
 
@@ -655,15 +752,45 @@ func parseTaggingHeader(header http.Header) (map[string]string, error) {
 	return tagSet, nil
 }
 
-func parseMetadata(r *http.Request) map[string]string {
+// parseMetadata returns user-defined metadata of the request with lowercase keys.
+// Values of repeated headers are joined with commas.
+func parseMetadata(r *http.Request) (map[string]string, error) {
 	res := make(map[string]string)
 	for k, v := range r.Header {
 		if strings.HasPrefix(k, api.MetadataPrefix) {
 			key := strings.ToLower(strings.TrimPrefix(k, api.MetadataPrefix))
-			res[key] = v[0]
+			res[key] = strings.Join(v, ",")
+		}
+	}
+	return res, checkUserMetadata(res)
+}
+
+// checkUserMetadata returns an error if some key of user-defined metadata is empty
+// or the total size of keys and values exceeds the limit of AWS S3.
+func checkUserMetadata(metadata map[string]string) error {
+	var size int
+	for key, val := range metadata {
+		if len(key) == 0 {
+			return errors.GetAPIError(errors.ErrInvalidArgument)
 		}
+		size += len(key) + len(val)
+	}
+
+	if size > maxUserMetadataSize {
+		return errors.GetAPIError(errors.ErrMetadataTooLarge)
 	}
-	return res
+	return nil
+}
+
+// checkObjectName checks that the object key is valid UTF-8 and isn't longer than S3 allows.
+func checkObjectName(name string) error {
+	if len(name) > maxObjectNameSize {
+		return errors.GetAPIError(errors.ErrKeyTooLongError)
+	}
+	if !utf8.ValidString(name) {
+		return errors.GetAPIError(errors.ErrInvalidObjectName)
+	}
+	return nil
 }
 
 func (h *handler) CreateBucketHandler(w http.ResponseWriter, r *http.Request) {
@@ -672,7 +799,7 @@ func (h *handler) CreateBucketHandler(w http.ResponseWriter, r *http.Request) {
 		Name: reqInfo.BucketName,
 	}
 
-	if err := checkBucketName(reqInfo.BucketName); err != nil {
+	if err := h.checkBucketName(reqInfo.BucketName); err != nil {
 		h.logAndSendError(w, "invalid bucket name", reqInfo, err)
 		return
 	}
@@ -720,7 +847,31 @@ func (h *handler) CreateBucketHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.setPolicy(p, createParams.LocationConstraint, policies)
+	metadata, err := parseMetadata(r)
+	if err != nil {
+		h.logAndSendError(w, "invalid metadata", reqInfo, err)
+		return
+	}
+
+	locationConstraint, fromHeader := createParams.LocationConstraint, false
+	if locationConstraint == "" {
+		locationConstraint, fromHeader = metadata[layer.AttributeNeofsPlacementPolicy], true
+	}
+
+	if err = h.setPolicy(p, locationConstraint, fromHeader, policies); err != nil {
+		h.logAndSendError(w, "couldn't set placement policy", reqInfo, err)
+		return
+	}
+
+	if p.Quota, err = parseQuota(metadata); err != nil {
+		h.logAndSendError(w, "invalid bucket quota", reqInfo, err)
+		return
+	}
+
+	if p.Attributes, err = parseContainerAttributes(r.Header, h.cfg.ContainerAttributePrefixes); err != nil {
+		h.logAndSendError(w, "invalid container attributes", reqInfo, err)
+		return
+	}
 
 	p.ObjectLockEnabled = isLockEnabled(r.Header)
 
@@ -747,25 +898,142 @@ func (h *handler) CreateBucketHandler(w http.ResponseWriter, r *http.Request) {
 	api.WriteSuccessResponseHeadersOnly(w)
 }
 
-func (h handler) setPolicy(prm *layer.CreateBucketParams, locationConstraint string, userPolicies []*accessbox.ContainerPolicy) {
-	prm.Policy = h.cfg.Policy.Default()
+func parseQuota(metadata map[string]string) (data.BucketQuota, error) {
+	var (
+		quota data.BucketQuota
+		err   error
+	)
 
-	if locationConstraint == "" {
-		return
+	if val, ok := metadata[layer.AttributeNeofsQuotaSize]; ok {
+		if quota.Size, err = strconv.ParseUint(val, 10, 64); err != nil {
+			return quota, fmt.Errorf("%w: invalid quota size '%s'", errors.GetAPIError(errors.ErrInvalidArgument), val)
+		}
 	}
 
-	if policy, ok := h.cfg.Policy.Get(locationConstraint); ok {
-		prm.Policy = policy
-		prm.LocationConstraint = locationConstraint
+	if val, ok := metadata[layer.AttributeNeofsQuotaObjects]; ok {
+		if quota.Objects, err = strconv.ParseUint(val, 10, 64); err != nil {
+			return quota, fmt.Errorf("%w: invalid quota objects '%s'", errors.GetAPIError(errors.ErrInvalidArgument), val)
+		}
+	}
+
+	return quota, nil
+}
+
+// parseContainerAttributes returns container attributes set by the headers with one of the prefixes.
+// The rest of the header name is the name of the attribute. Names starting with "Neofs-" are names
+// of system attributes, e.g. X-Container-Attribute-Neofs-Zone header sets __NEOFS__ZONE attribute.
+func parseContainerAttributes(header http.Header, prefixes []string) ([][2]string, error) {
+	var attributes [][2]string
+
+	for key, values := range header {
+		for _, prefix := range prefixes {
+			prefix = http.CanonicalHeaderKey(prefix)
+			if len(key) <= len(prefix) || !strings.HasPrefix(key, prefix) {
+				continue
+			}
+
+			name := containerAttributeName(key[len(prefix):])
+			if layer.IsReservedContainerAttribute(name) {
+				return nil, errors.GetAPIErrorWithError(errors.ErrInvalidArgument, fmt.Errorf("reserved container attribute '%s'", name))
+			}
+			if values[0] == "" {
+				return nil, errors.GetAPIErrorWithError(errors.ErrInvalidArgument, fmt.Errorf("empty container attribute '%s'", name))
+			}
+
+			attributes = append(attributes, [2]string{name, values[0]})
+			break
+		}
+	}
+
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i][0] < attributes[j][0]
+	})
+
+	return attributes, nil
+}
+
+// containerAttributeName converts the header name to the name of the container attribute.
+func containerAttributeName(name string) string {
+	upper := strings.ToUpper(name)
+	switch {
+	case strings.HasPrefix(upper, systemAttributePrefix):
+		return upper
+	case strings.HasPrefix(upper, systemHeaderPrefix):
+		return systemAttributePrefix + strings.ReplaceAll(upper[len(systemHeaderPrefix):], "-", "_")
+	}
+	return name
+}
+
+// containerAttributeHeader converts the name of the container attribute to the HeadBucket response header.
+func containerAttributeHeader(name string) string {
+	if strings.HasPrefix(name, systemAttributePrefix) {
+		name = systemHeaderPrefix + strings.ReplaceAll(name[len(systemAttributePrefix):], "_", "-")
+	}
+	return http.CanonicalHeaderKey(api.ContainerAttributePrefix + name)
+}
+
+// setPolicy sets placement policy profile with the name of location constraint.
+// Policies provided by the user in access box take precedence over the ones from config.
+// If rawAllowed is set and there is no such profile, location constraint is parsed as
+// a placement policy itself, so the bucket gets the default location constraint.
+// Otherwise, unknown location constraint is rejected unless DefaultPolicyForUnknownLocation
+// is set, then the bucket gets the default policy and location constraint.
+func (h handler) setPolicy(prm *layer.CreateBucketParams, locationConstraint string, rawAllowed bool, userPolicies []*accessbox.ContainerPolicy) error {
+	prm.Policy = h.cfg.Policy.Default()
+
+	if locationConstraint == "" || locationConstraint == api.DefaultLocationConstraint {
+		return nil
 	}
 
 	for _, placementPolicy := range userPolicies {
 		if placementPolicy.LocationConstraint == locationConstraint {
 			prm.Policy = placementPolicy.Policy
 			prm.LocationConstraint = locationConstraint
-			return
+			return nil
+		}
+	}
+
+	if policy, ok := h.cfg.Policy.Get(locationConstraint); ok {
+		prm.Policy = policy
+		prm.LocationConstraint = locationConstraint
+		return nil
+	}
+
+	if !rawAllowed {
+		if h.cfg.DefaultPolicyForUnknownLocation {
+			return nil
 		}
+		return errors.GetAPIError(errors.ErrInvalidLocationConstraint)
 	}
+
+	policy, err := parsePlacementPolicy(locationConstraint)
+	if err != nil {
+		return err
+	}
+	prm.Policy = policy
+	return nil
+}
+
+// parsePlacementPolicy decodes placement policy in the QL or JSON format and checks that it stores
+// at least one replica of the objects.
+func parsePlacementPolicy(value string) (netmap.PlacementPolicy, error) {
+	var policy netmap.PlacementPolicy
+	if err := policy.DecodeString(value); err != nil {
+		if errJSON := policy.UnmarshalJSON([]byte(value)); errJSON != nil {
+			return policy, errors.GetAPIErrorWithError(errors.ErrInvalidArgument, fmt.Errorf("invalid placement policy '%s': %w", value, err))
+		}
+	}
+
+	if policy.NumberOfReplicas() == 0 {
+		return policy, errors.GetAPIErrorWithError(errors.ErrInvalidArgument, fmt.Errorf("placement policy '%s' has no replicas", value))
+	}
+	for i := 0; i < policy.NumberOfReplicas(); i++ {
+		if policy.ReplicaNumberByIndex(i) == 0 {
+			return policy, errors.GetAPIErrorWithError(errors.ErrInvalidArgument, fmt.Errorf("placement policy '%s' has zero replica", value))
+		}
+	}
+
+	return policy, nil
 }
 
 func isLockEnabled(header http.Header) bool {
@@ -774,13 +1042,29 @@ func isLockEnabled(header http.Header) bool {
 	return lockEnabled
 }
 
+// checkBucketName checks the name of the new bucket with DNS-compatible rules
+// or with legacy ones if relaxed bucket names are allowed.
+func (h *handler) checkBucketName(bucketName string) error {
+	if h.cfg.RelaxedBucketNames {
+		return checkLegacyBucketName(bucketName)
+	}
+	return checkBucketName(bucketName)
+}
+
 func checkBucketName(bucketName string) error {
 	if len(bucketName) < 3 || len(bucketName) > 63 {
 		return errors.GetAPIError(errors.ErrInvalidBucketName)
 	}
 
-	if strings.HasPrefix(bucketName, "xn--") || strings.HasSuffix(bucketName, "-s3alias") {
-		return errors.GetAPIError(errors.ErrInvalidBucketName)
+	for _, prefix := range reservedBucketPrefixes {
+		if strings.HasPrefix(bucketName, prefix) {
+			return errors.GetAPIError(errors.ErrInvalidBucketName)
+		}
+	}
+	for _, suffix := range reservedBucketSuffixes {
+		if strings.HasSuffix(bucketName, suffix) {
+			return errors.GetAPIError(errors.ErrInvalidBucketName)
+		}
 	}
 	if net.ParseIP(bucketName) != nil {
 		return errors.GetAPIError(errors.ErrInvalidBucketName)
@@ -804,6 +1088,22 @@ func checkBucketName(bucketName string) error {
 	return nil
 }
 
+// checkLegacyBucketName checks the name with rules of buckets created in AWS S3 before March 1, 2018:
+// up to 255 letters of both cases, digits, periods, hyphens and underscores.
+func checkLegacyBucketName(bucketName string) error {
+	if len(bucketName) < 3 || len(bucketName) > 255 {
+		return errors.GetAPIError(errors.ErrInvalidBucketName)
+	}
+
+	for _, r := range bucketName {
+		if !isAlphaNum(r) && !('A' <= r && r <= 'Z') && r != '-' && r != '.' && r != '_' {
+			return errors.GetAPIError(errors.ErrInvalidBucketName)
+		}
+	}
+
+	return nil
+}
+
 func isAlphaNum(char int32) bool {
 	return 'a' <= char && char <= 'z' || '0' <= char && char <= '9'
 }
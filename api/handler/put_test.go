@@ -1,15 +1,26 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
 	"github.com/stretchr/testify/require"
 )
 
@@ -38,6 +49,11 @@ func TestCheckBucketName(t *testing.T) {
 		{name: "buc_ket", err: true},
 		{name: "xn--bucket", err: true},
 		{name: "bucket-s3alias", err: true},
+		{name: "sthree-bucket", err: true},
+		{name: "amzn-s3-demo-bucket", err: true},
+		{name: "bucket--ol-s3", err: true},
+		{name: "bucket.mrap", err: true},
+		{name: "bucket--x-s3", err: true},
 		{name: "192.168.0.1", err: true},
 		{name: "as", err: true},
 		{name: "64aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", err: true},
@@ -51,6 +67,16 @@ func TestCheckBucketName(t *testing.T) {
 	}
 }
 
+func TestCheckLegacyBucketName(t *testing.T) {
+	for _, name := range []string{"Bucket", "buc_ket", "bucket.", "-bucket", "xn--bucket", strings.Repeat("a", 255)} {
+		require.NoError(t, checkLegacyBucketName(name), "bucket name: %s", name)
+	}
+
+	for _, name := range []string{"as", "buc!ket", "buc ket", "бакет", strings.Repeat("a", 256)} {
+		require.Error(t, checkLegacyBucketName(name), "bucket name: %s", name)
+	}
+}
+
 func TestCustomJSONMarshal(t *testing.T) {
 	data := []byte(`
 { "expiration": "2015-12-30T12:00:00.000Z",
@@ -107,6 +133,37 @@ func TestEmptyPostPolicy(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestPostObjectInlinePolicy(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-post-policy"
+	createTestBucket(tc, bktName)
+
+	box, err := layer.GetBoxData(tc.Context())
+	require.NoError(t, err)
+	box.InlinePolicy, err = accessbox.ParseInlinePolicy([]byte(`{"Statement": [
+		{"Effect": "Allow", "Action": "s3:PutObject", "Resource": "arn:aws:s3:::` + bktName + `/prefix/*"}
+	]}`))
+	require.NoError(t, err)
+
+	postObject := func(objName string) *httptest.ResponseRecorder {
+		w, r := prepareTestRequest(tc, bktName, "", nil)
+		r.Method = http.MethodPost
+		r.MultipartForm = &multipart.Form{Value: map[string][]string{
+			"key":  {objName},
+			"file": {"content"},
+		}}
+		tc.Handler().PostObject(w, r)
+		return w
+	}
+
+	assertStatus(t, postObject("prefix/obj"), http.StatusNoContent)
+	checkFound(t, tc, bktName, "prefix/obj", emptyVersion)
+
+	assertS3Error(t, postObject("other/obj"), errors.GetAPIError(errors.ErrAccessDenied))
+	checkNotFound(t, tc, bktName, "other/obj", emptyVersion)
+}
+
 func TestPutObjectOverrideCopiesNumber(t *testing.T) {
 	tc := prepareHandlerContext(t)
 
@@ -126,3 +183,505 @@ func TestPutObjectOverrideCopiesNumber(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "1", objInfo.Headers[layer.AttributeNeofsCopiesNumber])
 }
+
+func TestPutObjectIfNoneMatch(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-if-none-match", "object-for-if-none-match"
+	createTestBucket(tc, bktName)
+
+	w, r := prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.IfNoneMatch, "*")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.IfNoneMatch, "*")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusPreconditionFailed)
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.IfNoneMatch, "etag")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusNotImplemented)
+}
+
+func TestPutObjectIfNoneMatchDeleteMarker(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-if-none-match-versioned", "object-for-if-none-match"
+	createTestBucket(tc, bktName)
+	putBucketVersioning(t, tc, bktName, true)
+
+	putObject(t, tc, bktName, objName)
+	_, isDeleteMarker := deleteObject(t, tc, bktName, objName, "")
+	require.True(t, isDeleteMarker)
+
+	w, r := prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.IfNoneMatch, "*")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+}
+
+func TestCompleteMultipartUploadIfNoneMatch(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-complete-if-none-match", "object-multipart"
+	createTestBucket(hc, bktName)
+	putObject(t, hc, bktName, objName)
+
+	uploadInfo := createMultipartUpload(hc, bktName, objName, map[string]string{})
+	etag, _ := uploadPart(hc, bktName, objName, uploadInfo.UploadID, 1, 10)
+
+	query := make(url.Values)
+	query.Set(uploadIDQuery, uploadInfo.UploadID)
+	complete := &CompleteMultipartUpload{Parts: []*layer.CompletedPart{{ETag: etag, PartNumber: 1}}}
+	w, r := prepareTestFullRequest(hc, bktName, objName, query, complete)
+	r.Header.Set(api.IfNoneMatch, "*")
+	hc.Handler().CompleteMultipartUploadHandler(w, r)
+	assertStatus(t, w, http.StatusPreconditionFailed)
+}
+
+func TestPutObjectWithTagging(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-tagging", "object-with-tagging"
+	createTestBucket(tc, bktName)
+
+	w, r := prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.AmzTagging, "key1=val1&key2=val2")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	tagging := getObjectTagging(t, tc, bktName, objName, emptyVersion)
+	require.Len(t, tagging.TagSet, 2)
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	tc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	require.Equal(t, "2", w.Header().Get(api.AmzTaggingCount))
+
+	putObject(t, tc, bktName, "object-without-tagging")
+	w, r = prepareTestRequest(tc, bktName, "object-without-tagging", nil)
+	tc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	require.Empty(t, w.Header().Get(api.AmzTaggingCount))
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.AmzTagging, "%")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestPutObjectMetadata(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-metadata", "object-with-metadata"
+	createTestBucket(tc, bktName)
+
+	w, r := prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.MetadataPrefix+"My-Key", "value")
+	r.Header.Add(api.MetadataPrefix+"Repeated", "first")
+	r.Header.Add(api.MetadataPrefix+"Repeated", "second")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	tc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	require.Equal(t, []string{"value"}, w.Header()[api.MetadataPrefix+"my-key"])
+	require.Equal(t, []string{"first,second"}, w.Header()[api.MetadataPrefix+"repeated"])
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.MetadataPrefix+"Key", strings.Repeat("a", maxUserMetadataSize-len("key")))
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.MetadataPrefix+"Key", strings.Repeat("a", maxUserMetadataSize-len("key")+1))
+	tc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrMetadataTooLarge))
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.MetadataPrefix, "value")
+	tc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrInvalidArgument))
+}
+
+func TestCreateBucketWithPlacementPolicyProfile(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	const profile = "profile"
+	policyMock := hc.h.cfg.Policy.(*placementPolicyMock)
+	policyMock.policies = map[string]netmap.PlacementPolicy{profile: policyMock.defaultPolicy}
+
+	box, _ := createAccessBox(t)
+
+	bktName := "bucket-with-profile"
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	r.Header.Set(api.MetadataPrefix+strings.ToUpper(layer.AttributeNeofsPlacementPolicy), profile)
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	hc.Handler().CreateBucketHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestRequest(hc, bktName, "", nil)
+	hc.Handler().GetBucketLocationHandler(w, r)
+	location := &LocationResponse{}
+	readResponse(t, w, http.StatusOK, location)
+	require.Equal(t, profile, location.Location)
+
+	w, r = prepareTestRequest(hc, "bucket-with-unknown-profile", "", &createBucketParams{LocationConstraint: "unknown"})
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	hc.Handler().CreateBucketHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrInvalidLocationConstraint))
+
+	hc.h.cfg.DefaultPolicyForUnknownLocation = true
+	w, r = prepareTestRequest(hc, "bucket-with-unknown-profile", "", &createBucketParams{LocationConstraint: "unknown"})
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	hc.Handler().CreateBucketHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestRequest(hc, "bucket-with-unknown-profile", "", nil)
+	hc.Handler().GetBucketLocationHandler(w, r)
+	readResponse(t, w, http.StatusOK, location)
+	require.Equal(t, api.DefaultLocationConstraint, location.Location)
+}
+
+func TestCreateBucketWithRawPlacementPolicy(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	box, _ := createAccessBox(t)
+
+	createBucket := func(bktName, policy string) *httptest.ResponseRecorder {
+		w, r := prepareTestRequest(hc, bktName, "", nil)
+		r.Header.Set(api.MetadataPrefix+strings.ToUpper(layer.AttributeNeofsPlacementPolicy), policy)
+		r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+		hc.Handler().CreateBucketHandler(w, r)
+		return w
+	}
+
+	bktName := "bucket-with-raw-policy"
+	assertStatus(t, createBucket(bktName, "REP 3"), http.StatusOK)
+
+	bktInfo, err := hc.Layer().GetBucketInfo(hc.Context(), bktName)
+	require.NoError(t, err)
+	cnr, err := hc.MockedPool().Container(hc.Context(), bktInfo.CID)
+	require.NoError(t, err)
+	policy := cnr.PlacementPolicy()
+	require.Equal(t, 1, policy.NumberOfReplicas())
+	require.EqualValues(t, 3, policy.ReplicaNumberByIndex(0))
+
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	hc.Handler().GetBucketLocationHandler(w, r)
+	location := &LocationResponse{}
+	readResponse(t, w, http.StatusOK, location)
+	require.Equal(t, api.DefaultLocationConstraint, location.Location)
+
+	assertS3Error(t, createBucket("bucket-with-invalid-policy", "REP"), errors.GetAPIError(errors.ErrInvalidArgument))
+}
+
+func TestParseContainerAttributes(t *testing.T) {
+	prefixes := []string{"x-container-attribute-", "X-Amz-Meta-Attribute-"}
+
+	header := make(http.Header)
+	header.Set("X-Container-Attribute-Department", "sales")
+	header.Set("X-Container-Attribute-Neofs-Zone", "custom")
+	header.Set("X-Amz-Meta-Attribute-__neofs__expiration_epoch", "100")
+	header.Set("X-Container-Attribute-", "empty name")
+	header.Set("X-Other-Department", "other")
+
+	attributes, err := parseContainerAttributes(header, prefixes)
+	require.NoError(t, err)
+	require.Equal(t, [][2]string{
+		{"Department", "sales"},
+		{"__NEOFS__EXPIRATION_EPOCH", "100"},
+		{"__NEOFS__ZONE", "custom"},
+	}, attributes)
+
+	for _, name := range []string{"Name", "Timestamp", "Neofs-Name", "Lockenabled", ".s3-location-constraint"} {
+		header = make(http.Header)
+		header.Set("X-Container-Attribute-"+name, "value")
+		_, err = parseContainerAttributes(header, prefixes)
+		require.True(t, errors.IsS3Error(err, errors.ErrInvalidArgument), name)
+	}
+
+	require.Equal(t, "X-Container-Attribute-Neofs-Zone", containerAttributeHeader("__NEOFS__ZONE"))
+	require.Equal(t, "X-Container-Attribute-Department", containerAttributeHeader("Department"))
+}
+
+func TestCreateBucketWithContainerAttributes(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	hc.h.cfg.ContainerAttributePrefixes = []string{"X-Container-Attribute-"}
+	hc.h.cfg.ExposedContainerAttributes = []string{"__NEOFS__ZONE", "Department", "Unset"}
+
+	box, _ := createAccessBox(t)
+
+	bktName := "bucket-with-attributes"
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	r.Header.Set("X-Container-Attribute-Neofs-Zone", "custom")
+	r.Header.Set("X-Container-Attribute-Department", "sales")
+	r.Header.Set("X-Container-Attribute-Project", "s3")
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	hc.Handler().CreateBucketHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	bktInfo, err := hc.Layer().GetBucketInfo(hc.Context(), bktName)
+	require.NoError(t, err)
+	cnr, err := hc.MockedPool().Container(hc.Context(), bktInfo.CID)
+	require.NoError(t, err)
+	require.Equal(t, "custom", cnr.Attribute("__NEOFS__ZONE"))
+	require.Equal(t, "s3", cnr.Attribute("Project"))
+
+	w, r = prepareTestRequest(hc, bktName, "", nil)
+	hc.Handler().HeadBucketHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	require.Equal(t, "custom", w.Header().Get("X-Container-Attribute-Neofs-Zone"))
+	require.Equal(t, "sales", w.Header().Get("X-Container-Attribute-Department"))
+	require.Empty(t, w.Header().Get("X-Container-Attribute-Project"))
+	require.Empty(t, w.Header().Get("X-Container-Attribute-Unset"))
+
+	w, r = prepareTestRequest(hc, "bucket-with-reserved-attribute", "", nil)
+	r.Header.Set("X-Container-Attribute-Neofs-Name", "other")
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	hc.Handler().CreateBucketHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrInvalidArgument))
+}
+
+func TestPutObjectStorageClass(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	hc.h.cfg.StorageClasses = map[string]uint32{"REDUCED_REDUNDANCY": 1}
+
+	bktName, objName, copyName := "bucket-for-storage-class", "object", "object-copy"
+	createTestBucket(hc, bktName)
+
+	putObjectStorageClass(hc, bktName, objName, "REDUCED_REDUNDANCY", http.StatusOK)
+	require.Equal(t, "REDUCED_REDUNDANCY", headObjectHeaders(t, hc, bktName, objName).Get(api.AmzStorageClass))
+
+	listing := listObjectsV2(t, hc, bktName, "", "", "", "", -1)
+	require.Len(t, listing.Contents, 1)
+	require.Equal(t, "REDUCED_REDUNDANCY", listing.Contents[0].StorageClass)
+
+	// copy doesn't inherit storage class of the source object
+	copyObject(t, hc, bktName, objName, copyName, CopyMeta{}, http.StatusOK)
+	require.Empty(t, headObjectHeaders(t, hc, bktName, copyName).Get(api.AmzStorageClass))
+	require.Equal(t, "REDUCED_REDUNDANCY", headObjectHeaders(t, hc, bktName, objName).Get(api.AmzStorageClass))
+
+	multipartUpload := createMultipartUpload(hc, bktName, objName, map[string]string{api.AmzStorageClass: "REDUCED_REDUNDANCY"})
+	parts := listParts(hc, bktName, objName, multipartUpload.UploadID, "", "")
+	require.Equal(t, "REDUCED_REDUNDANCY", parts.StorageClass)
+
+	putObjectStorageClass(hc, bktName, objName, "GLACIER", http.StatusBadRequest)
+}
+
+func putObjectStorageClass(hc *handlerContext, bktName, objName, storageClass string, status int) {
+	w, r := prepareTestPayloadRequest(hc, bktName, objName, strings.NewReader("content"))
+	r.Header.Set(api.AmzStorageClass, storageClass)
+	hc.Handler().PutObjectHandler(w, r)
+	assertStatus(hc.t, w, status)
+}
+
+func TestBucketQuota(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	box, _ := createAccessBox(t)
+
+	bktName := "bucket-with-quota"
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	r.Header.Set(api.MetadataPrefix+strings.ToUpper(layer.AttributeNeofsQuotaSize), "10")
+	r.Header.Set(api.MetadataPrefix+strings.ToUpper(layer.AttributeNeofsQuotaObjects), "2")
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	hc.Handler().CreateBucketHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	putObjectContent(hc, bktName, "obj1", "content")
+
+	w, r = prepareTestPayloadRequest(hc, bktName, "obj2", strings.NewReader("content"))
+	hc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrQuotaExceeded))
+
+	putObjectContent(hc, bktName, "obj2", "abc")
+
+	w, r = prepareTestPayloadRequest(hc, bktName, "obj3", strings.NewReader(""))
+	hc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrQuotaExceeded))
+
+	w, r = prepareTestRequest(hc, bktName, "", nil)
+	hc.Handler().GetBucketUsageHandler(w, r)
+	usage := &BucketUsageResponse{}
+	readResponse(t, w, http.StatusOK, usage)
+	require.Equal(t, uint64(10), usage.Size)
+	require.Equal(t, uint64(2), usage.ObjectsCount)
+	require.Equal(t, uint64(10), usage.QuotaSize)
+	require.Equal(t, uint64(2), usage.QuotaObjects)
+
+	w, r = prepareTestRequest(hc, "bucket-with-invalid-quota", "", nil)
+	r.Header.Set(api.MetadataPrefix+strings.ToUpper(layer.AttributeNeofsQuotaSize), "-1")
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	hc.Handler().CreateBucketHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrInvalidArgument))
+}
+
+func TestBucketQuotaOverwrite(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-with-quota-overwrite", "object"
+	createQuotaBucket(hc, bktName, "10", "1")
+
+	putObjectContent(hc, bktName, objName, "content")
+	putObjectContent(hc, bktName, objName, "content2")
+
+	w, r := prepareTestPayloadRequest(hc, bktName, "obj2", strings.NewReader("a"))
+	hc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrQuotaExceeded))
+
+	w, r = prepareTestRequest(hc, bktName, "", nil)
+	hc.Handler().GetBucketUsageHandler(w, r)
+	usage := &BucketUsageResponse{}
+	readResponse(t, w, http.StatusOK, usage)
+	require.Equal(t, uint64(8), usage.Size)
+	require.Equal(t, uint64(1), usage.ObjectsCount)
+
+	deleteObject(t, hc, bktName, objName, emptyVersion)
+	putObjectContent(hc, bktName, "obj2", "abc")
+}
+
+func TestBucketQuotaCompleteMultipartUpload(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-with-quota-multipart", "object-multipart"
+	createQuotaBucket(hc, bktName, "15", "")
+
+	uploadInfo := createMultipartUpload(hc, bktName, objName, map[string]string{})
+	etag, _ := uploadPart(hc, bktName, objName, uploadInfo.UploadID, 1, 20)
+
+	query := make(url.Values)
+	query.Set(uploadIDQuery, uploadInfo.UploadID)
+	complete := &CompleteMultipartUpload{Parts: []*layer.CompletedPart{{ETag: etag, PartNumber: 1}}}
+	w, r := prepareTestFullRequest(hc, bktName, objName, query, complete)
+	hc.Handler().CompleteMultipartUploadHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrQuotaExceeded))
+}
+
+func createQuotaBucket(hc *handlerContext, bktName, quotaSize, quotaObjects string) {
+	box, _ := createAccessBox(hc.t)
+
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	if quotaSize != "" {
+		r.Header.Set(api.MetadataPrefix+strings.ToUpper(layer.AttributeNeofsQuotaSize), quotaSize)
+	}
+	if quotaObjects != "" {
+		r.Header.Set(api.MetadataPrefix+strings.ToUpper(layer.AttributeNeofsQuotaObjects), quotaObjects)
+	}
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	hc.Handler().CreateBucketHandler(w, r)
+	assertStatus(hc.t, w, http.StatusOK)
+}
+
+func TestPutObjectContentMD5(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-content-md5", "object-for-content-md5"
+	createTestBucket(tc, bktName)
+
+	content := []byte("content")
+	md5Sum := md5.Sum(content)
+	contentMD5 := base64.StdEncoding.EncodeToString(md5Sum[:])
+
+	w, r := prepareTestPayloadRequest(tc, bktName, objName, bytes.NewReader(content))
+	r.Header.Set(api.ContentMD5, contentMD5)
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestPayloadRequest(tc, bktName, objName+"-encrypted", bytes.NewReader(content))
+	r.Header.Set(api.ContentMD5, contentMD5)
+	setEncryptHeaders(r)
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestPayloadRequest(tc, bktName, objName+"-corrupted", bytes.NewReader([]byte("corrupted")))
+	r.Header.Set(api.ContentMD5, contentMD5)
+	tc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrBadDigest))
+	headObject(t, tc, bktName, objName+"-corrupted", nil, http.StatusNotFound)
+
+	w, r = prepareTestPayloadRequest(tc, bktName, objName, bytes.NewReader(content))
+	r.Header.Set(api.ContentMD5, hex.EncodeToString(md5Sum[:]))
+	tc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrInvalidDigest))
+}
+
+func TestUploadPartContentMD5(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-part-content-md5", "object-for-part-content-md5"
+	createTestBucket(tc, bktName)
+
+	multipartInfo := createMultipartUpload(tc, bktName, objName, map[string]string{})
+
+	content := []byte("content")
+	md5Sum := md5.Sum(content)
+
+	query := make(url.Values)
+	query.Set(uploadIDQuery, multipartInfo.UploadID)
+	query.Set(partNumberQuery, "1")
+
+	w, r := prepareTestRequestWithQuery(tc, bktName, objName, query, content)
+	r.Header.Set(api.ContentMD5, base64.StdEncoding.EncodeToString(md5Sum[:]))
+	tc.Handler().UploadPartHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestRequestWithQuery(tc, bktName, objName, query, []byte("corrupted"))
+	r.Header.Set(api.ContentMD5, base64.StdEncoding.EncodeToString(md5Sum[:]))
+	tc.Handler().UploadPartHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrBadDigest))
+}
+
+type readTracker struct {
+	io.Reader
+	read bool
+}
+
+func (r *readTracker) Read(p []byte) (int, error) {
+	r.read = true
+	return r.Reader.Read(p)
+}
+
+func TestPutObjectExpectContinue(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-expect-continue", "object-for-expect-continue"
+	createTestBucket(tc, bktName)
+	putObject(t, tc, bktName, objName)
+
+	content := []byte("content")
+
+	// the body of the rejected upload isn't requested if the client waits for 100 Continue
+	payload := &readTracker{Reader: bytes.NewReader(content)}
+	w, r := prepareTestPayloadRequest(tc, bktName, objName, payload)
+	r.ContentLength = int64(len(content))
+	r.Header.Set(api.Expect, "100-continue")
+	r.Header.Set(api.IfNoneMatch, "*")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusPreconditionFailed)
+	require.False(t, payload.read)
+
+	// the body is drained for clients sending it without waiting for the response
+	payload = &readTracker{Reader: bytes.NewReader(content)}
+	w, r = prepareTestPayloadRequest(tc, bktName, objName, payload)
+	r.ContentLength = int64(len(content))
+	r.Header.Set(api.IfNoneMatch, "*")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusPreconditionFailed)
+	require.True(t, payload.read)
+
+	payload = &readTracker{Reader: bytes.NewReader(content)}
+	w, r = prepareTestPayloadRequest(tc, bktName, objName+"-new", payload)
+	r.ContentLength = int64(len(content))
+	r.Header.Set(api.Expect, "100-continue")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	require.True(t, payload.read)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+)
+
+const expectContinue = "100-continue"
+
+// requestBody tracks if the payload of the upload has been requested. Clients
+// sending 'Expect: 100-continue' get 100 Continue on the first read of the body
+// only, so until then the upload is rejected without the payload being transferred.
+type requestBody struct {
+	io.ReadCloser
+	read uint32
+}
+
+func newRequestBody(r *http.Request) *requestBody {
+	return &requestBody{ReadCloser: r.Body}
+}
+
+func (b *requestBody) Read(p []byte) (int, error) {
+	atomic.StoreUint32(&b.read, 1)
+	return b.ReadCloser.Read(p)
+}
+
+// discard drains the body of the failed upload, so clients sending the whole payload
+// before reading the response get the error instead of a reset connection. Clients
+// still waiting for 100 Continue get the final status right away: their body isn't
+// requested and the connection isn't reused after the response.
+func (b *requestBody) discard(r *http.Request) []error {
+	if strings.EqualFold(r.Header.Get(api.Expect), expectContinue) && atomic.LoadUint32(&b.read) == 0 {
+		return nil
+	}
+
+	_, errRead := io.Copy(io.Discard, b.ReadCloser)
+	return []error{errRead, b.Close()}
+}
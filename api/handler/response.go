@@ -121,7 +121,7 @@ type ObjectVersionResponse struct {
 	LastModified string `xml:"LastModified"`
 	Owner        Owner  `xml:"Owner"`
 	Size         int64  `xml:"Size"`
-	StorageClass string `xml:"StorageClass,omitempty"` // is empty!!
+	StorageClass string `xml:"StorageClass,omitempty"`
 	VersionID    string `xml:"VersionId"`
 }
 
@@ -137,6 +137,18 @@ type DeleteMarkerEntry struct {
 // StringMap is a map[string]string.
 type StringMap map[string]string
 
+// BucketUsageResponse -- format for bucket usage response.
+type BucketUsageResponse struct {
+	XMLName      xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ BucketUsage" json:"-"`
+	Size         uint64   `xml:"Size"`
+	ObjectsCount uint64   `xml:"ObjectsCount"`
+	QuotaSize    uint64   `xml:"QuotaSize,omitempty"`
+	QuotaObjects uint64   `xml:"QuotaObjects,omitempty"`
+	Requests     uint64   `xml:"Requests,omitempty"`
+	BytesIn      uint64   `xml:"BytesIn,omitempty"`
+	BytesOut     uint64   `xml:"BytesOut,omitempty"`
+}
+
 // LocationResponse -- format for location response.
 type LocationResponse struct {
 	XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LocationConstraint" json:"-"`
@@ -155,6 +167,9 @@ type ListObjectsVersionsResponse struct {
 	XMLName             xml.Name                `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListVersionsResult" json:"-"`
 	EncodingType        string                  `xml:"EncodingType,omitempty"`
 	Name                string                  `xml:"Name"`
+	Prefix              string                  `xml:"Prefix"`
+	Delimiter           string                  `xml:"Delimiter,omitempty"`
+	MaxKeys             int                     `xml:"MaxKeys"`
 	IsTruncated         bool                    `xml:"IsTruncated"`
 	KeyMarker           string                  `xml:"KeyMarker"`
 	NextKeyMarker       string                  `xml:"NextKeyMarker,omitempty"`
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"strings"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 )
 
 type encoding int
@@ -109,3 +111,12 @@ func s3PathEncode(name string, encodingType string) (result string) {
 	}
 	return name
 }
+
+// parseEncodingType checks the encoding-type request parameter, "url" is the only supported value.
+func parseEncodingType(value string) (string, error) {
+	if value != "" && strings.ToLower(value) != urlEncodingType {
+		return "", errors.GetAPIError(errors.ErrInvalidEncodingMethod)
+	}
+
+	return value, nil
+}
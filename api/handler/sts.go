@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"go.uber.org/zap"
+)
+
+const stsActionAssumeRoleWithWebIdentity = "AssumeRoleWithWebIdentity"
+
+// AssumeRoleWithWebIdentityResponse is a response of STS AssumeRoleWithWebIdentity action.
+type AssumeRoleWithWebIdentityResponse struct {
+	XMLName          xml.Name                        `xml:"https://sts.amazonaws.com/doc/2011-06-15/ AssumeRoleWithWebIdentityResponse"`
+	Result           AssumeRoleWithWebIdentityResult `xml:"AssumeRoleWithWebIdentityResult"`
+	ResponseMetadata STSResponseMetadata             `xml:"ResponseMetadata"`
+}
+
+// AssumeRoleWithWebIdentityResult contains temporary credentials issued for the web identity token.
+type AssumeRoleWithWebIdentityResult struct {
+	SubjectFromWebIdentityToken string          `xml:"SubjectFromWebIdentityToken"`
+	Audience                    string          `xml:"Audience,omitempty"`
+	AssumedRoleUser             AssumedRoleUser `xml:"AssumedRoleUser"`
+	Credentials                 STSCredentials  `xml:"Credentials"`
+	Provider                    string          `xml:"Provider"`
+}
+
+// AssumedRoleUser identifies the session of the assumed role.
+type AssumedRoleUser struct {
+	Arn           string `xml:"Arn"`
+	AssumedRoleID string `xml:"AssumedRoleId"`
+}
+
+// STSCredentials are temporary credentials returned by STS actions.
+type STSCredentials struct {
+	AccessKeyID     string `xml:"AccessKeyId"`
+	SecretAccessKey string `xml:"SecretAccessKey"`
+	SessionToken    string `xml:"SessionToken"`
+	Expiration      string `xml:"Expiration"`
+}
+
+// STSResponseMetadata contains ID of STS request.
+type STSResponseMetadata struct {
+	RequestID string `xml:"RequestId"`
+}
+
+// AssumeRoleWithWebIdentityHandler exchanges a token of OIDC identity provider for temporary credentials.
+func (h *handler) AssumeRoleWithWebIdentityHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	if h.cfg.WebIdentity == nil {
+		h.logAndSendError(w, "web identity federation is disabled", reqInfo, errors.GetAPIError(errors.ErrNotImplemented))
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.logAndSendError(w, "couldn't parse sts request", reqInfo, errors.GetAPIError(errors.ErrInvalidArgument), zap.Error(err))
+		return
+	}
+
+	if action := r.Form.Get("Action"); action != stsActionAssumeRoleWithWebIdentity {
+		h.logAndSendError(w, "unsupported sts action", reqInfo, errors.GetAPIError(errors.ErrNotImplemented), zap.String("action", action))
+		return
+	}
+
+	prm := auth.AssumeRoleWithWebIdentityPrm{
+		RoleARN:         r.Form.Get("RoleArn"),
+		RoleSessionName: r.Form.Get("RoleSessionName"),
+		Token:           r.Form.Get("WebIdentityToken"),
+	}
+
+	if prm.RoleARN == "" || prm.RoleSessionName == "" || prm.Token == "" {
+		h.logAndSendError(w, "missing sts request parameters", reqInfo, errors.GetAPIError(errors.ErrInvalidArgument))
+		return
+	}
+
+	if durationStr := r.Form.Get("DurationSeconds"); durationStr != "" {
+		seconds, err := strconv.Atoi(durationStr)
+		if err != nil {
+			h.logAndSendError(w, "invalid duration", reqInfo, errors.GetAPIError(errors.ErrInvalidArgument), zap.Error(err))
+			return
+		}
+		prm.Duration = time.Duration(seconds) * time.Second
+	}
+
+	creds, err := h.cfg.WebIdentity.AssumeRoleWithWebIdentity(r.Context(), prm)
+	if err != nil {
+		h.logAndSendError(w, "couldn't assume role with web identity", reqInfo, err, zap.String("role", prm.RoleARN))
+		return
+	}
+
+	h.log.Info("issued temporary credentials",
+		zap.String("role", prm.RoleARN),
+		zap.String("subject", creds.Subject),
+		zap.String("access_key_id", creds.AccessKeyID))
+
+	res := AssumeRoleWithWebIdentityResponse{
+		Result: AssumeRoleWithWebIdentityResult{
+			SubjectFromWebIdentityToken: creds.Subject,
+			Audience:                    creds.Audience,
+			AssumedRoleUser: AssumedRoleUser{
+				Arn:           prm.RoleARN + "/" + prm.RoleSessionName,
+				AssumedRoleID: creds.AccessKeyID + ":" + prm.RoleSessionName,
+			},
+			Credentials: STSCredentials{
+				AccessKeyID:     creds.AccessKeyID,
+				SecretAccessKey: creds.SecretAccessKey,
+				SessionToken:    creds.SessionToken,
+				Expiration:      creds.Expiration.UTC().Format(time.RFC3339),
+			},
+			Provider: creds.Issuer,
+		},
+		ResponseMetadata: STSResponseMetadata{RequestID: reqInfo.RequestID},
+	}
+
+	if err = api.EncodeToResponse(w, res); err != nil {
+		h.logAndSendError(w, "couldn't encode assume role response", reqInfo, err)
+	}
+}
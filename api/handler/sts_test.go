@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type webIdentityMock struct {
+	prm auth.AssumeRoleWithWebIdentityPrm
+}
+
+func (m *webIdentityMock) AssumeRoleWithWebIdentity(_ context.Context, prm auth.AssumeRoleWithWebIdentityPrm) (*auth.TemporaryCredentials, error) {
+	m.prm = prm
+	return &auth.TemporaryCredentials{
+		AccessKeyID:     "accessKeyID",
+		SecretAccessKey: "secret",
+		Expiration:      time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Subject:         "subject",
+		Issuer:          "issuer",
+	}, nil
+}
+
+func TestAssumeRoleWithWebIdentity(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	form := url.Values{
+		"Action":           []string{stsActionAssumeRoleWithWebIdentity},
+		"RoleArn":          []string{"arn:aws:iam::000000000000:role/reader"},
+		"RoleSessionName":  []string{"session"},
+		"WebIdentityToken": []string{"token"},
+		"DurationSeconds":  []string{"900"},
+	}
+
+	assumeRole := func() *httptest.ResponseRecorder {
+		w, r := prepareTestRequestWithQuery(hc, "", "", make(url.Values), []byte(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		hc.Handler().AssumeRoleWithWebIdentityHandler(w, r)
+		return w
+	}
+
+	assertS3Error(t, assumeRole(), errors.GetAPIError(errors.ErrNotImplemented))
+
+	mock := &webIdentityMock{}
+	hc.h.cfg.WebIdentity = mock
+
+	res := &AssumeRoleWithWebIdentityResponse{}
+	parseTestResponse(t, assumeRole(), res)
+	require.Equal(t, auth.AssumeRoleWithWebIdentityPrm{
+		RoleARN:         "arn:aws:iam::000000000000:role/reader",
+		RoleSessionName: "session",
+		Token:           "token",
+		Duration:        15 * time.Minute,
+	}, mock.prm)
+	require.Equal(t, "accessKeyID", res.Result.Credentials.AccessKeyID)
+	require.Equal(t, "secret", res.Result.Credentials.SecretAccessKey)
+	require.Equal(t, "2022-01-01T00:00:00Z", res.Result.Credentials.Expiration)
+	require.Equal(t, "subject", res.Result.SubjectFromWebIdentityToken)
+	require.Equal(t, "arn:aws:iam::000000000000:role/reader/session", res.Result.AssumedRoleUser.Arn)
+
+	form.Del("WebIdentityToken")
+	assertS3Error(t, assumeRole(), errors.GetAPIError(errors.ErrInvalidArgument))
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"go.uber.org/zap"
+)
+
+// Headers of the request to HTTP transform hook describing the object.
+const (
+	transformHookBucket    = "X-S3-Bucket"
+	transformHookKey       = "X-S3-Key"
+	transformHookVersionID = "X-S3-Version-Id"
+)
+
+// HTTPObjectTransformer sends object payload in POST request to the external HTTP hook
+// and returns the response body as transformed payload.
+type HTTPObjectTransformer struct {
+	URL    string
+	Client *http.Client
+}
+
+// Transform implements ObjectTransformer.
+func (t *HTTPObjectTransformer) Transform(ctx context.Context, info *data.ObjectInfo, src io.Reader) (io.ReadCloser, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, src)
+	if err != nil {
+		return nil, "", fmt.Errorf("create hook request: %w", err)
+	}
+
+	req.Header.Set(api.ContentType, info.ContentType)
+	req.Header.Set(transformHookBucket, info.Bucket)
+	req.Header.Set(transformHookKey, info.Name)
+	req.Header.Set(transformHookVersionID, info.VersionID())
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("send hook request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, "", fmt.Errorf("unexpected hook response status: %s", resp.Status)
+	}
+
+	return resp.Body, resp.Header.Get(api.ContentType), nil
+}
+
+func (h *handler) objectTransformer(bucket, object string) ObjectTransformer {
+	for _, rule := range h.cfg.Transforms {
+		if rule.Bucket == bucket && strings.HasPrefix(object, rule.Prefix) {
+			return rule.Transformer
+		}
+	}
+	return nil
+}
+
+// checkNotTransformed rejects operations reading the original payload of the object or its
+// size and checksum, if the object is transformed, so the transformation isn't bypassed.
+func (h *handler) checkNotTransformed(bucket, object string) error {
+	if h.objectTransformer(bucket, object) != nil {
+		return fmt.Errorf("%w: operation isn't supported for transformed objects", errors.GetAPIError(errors.ErrNotImplemented))
+	}
+	return nil
+}
+
+// checkTransformedConditions rejects ETag conditions for transformed objects, because the
+// ETag of the original payload doesn't match the transformed one and the latter is unknown.
+func checkTransformedConditions(args *conditionalArgs) error {
+	if len(args.IfMatch) > 0 || len(args.IfNoneMatch) > 0 {
+		return fmt.Errorf("%w: ETag conditions aren't supported for transformed objects", errors.GetAPIError(errors.ErrNotImplemented))
+	}
+	return nil
+}
+
+// getTransformedObject streams object payload through the transformer. Object headers are
+// written by setHeaders only if the transformation has started successfully. Size and ETag
+// of the transformed payload are unknown, so these headers are removed from the response.
+func (h *handler) getTransformedObject(w http.ResponseWriter, r *http.Request, transformer ObjectTransformer, p *layer.GetObjectParams, setHeaders func(http.Header)) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	getParams := *p
+	getParams.Writer = pw
+	go func() {
+		pw.CloseWithError(h.obj.GetObject(r.Context(), &getParams))
+	}()
+
+	payload, contentType, err := transformer.Transform(r.Context(), p.ObjectInfo, pr)
+	if err != nil {
+		h.logAndSendError(w, "could not transform object", reqInfo, err)
+		return
+	}
+	defer payload.Close()
+
+	setHeaders(w.Header())
+	w.Header().Del(api.ContentLength)
+	w.Header().Del(api.ETag)
+	if contentType != "" {
+		w.Header().Set(api.ContentType, contentType)
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if _, err = io.Copy(w, payload); err != nil {
+		h.log.Error("could not write transformed object", zap.String("request_id", reqInfo.RequestID),
+			zap.String("bucket", reqInfo.BucketName), zap.String("object", reqInfo.ObjectName), zap.Error(err))
+	}
+}
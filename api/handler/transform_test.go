@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransformedObject(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-transform"
+	createTestBucket(hc, bktName)
+
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(transformHookKey) == "public/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		payload, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.Header().Set(api.ContentType, "text/transformed")
+		_, _ = w.Write(bytes.ToUpper(payload))
+	}))
+	defer hook.Close()
+
+	hc.h.cfg.Transforms = []TransformRule{{
+		Bucket:      bktName,
+		Prefix:      "public/",
+		Transformer: &HTTPObjectTransformer{URL: hook.URL},
+	}}
+
+	putObjectContent(hc, bktName, "public/obj", "content")
+	putObjectContent(hc, bktName, "public/broken", "content")
+	putObjectContent(hc, bktName, "private/obj", "content")
+
+	w, r := prepareTestRequest(hc, bktName, "public/obj", nil)
+	hc.Handler().GetObjectHandler(w, r)
+	require.Equal(t, []byte("CONTENT"), readBody(t, w))
+	require.Equal(t, "text/transformed", w.Header().Get(api.ContentType))
+	require.Empty(t, w.Header().Get(api.ETag))
+
+	require.Equal(t, []byte("content"), getObject(hc, bktName, "private/obj"))
+
+	w, r = prepareTestRequest(hc, bktName, "public/obj", nil)
+	r.Header.Set("Range", "bytes=0-1")
+	hc.Handler().GetObjectHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrNotImplemented))
+
+	w, r = prepareTestRequest(hc, bktName, "public/obj", nil)
+	r.Header.Set(api.IfNoneMatch, "etag")
+	hc.Handler().GetObjectHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrNotImplemented))
+
+	w, r = prepareTestRequest(hc, bktName, "public/obj", nil)
+	r.Header.Set(api.IfMatch, "etag")
+	hc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusNotImplemented)
+
+	w, r = prepareTestRequest(hc, bktName, "public/broken", nil)
+	hc.Handler().GetObjectHandler(w, r)
+	assertS3Error(t, w, errors.GetAPIError(errors.ErrInternalError))
+
+	w, r = prepareTestRequest(hc, bktName, "public/obj", nil)
+	hc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	require.Empty(t, w.Header().Get(api.ETag))
+	require.Empty(t, w.Header().Get(api.ContentLength))
+
+	copyObject(t, hc, bktName, "private/obj", "private/copy", CopyMeta{}, http.StatusOK)
+	copyObject(t, hc, bktName, "public/obj", "private/copy", CopyMeta{}, http.StatusNotImplemented)
+}
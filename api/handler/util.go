@@ -29,9 +29,25 @@ func (h *handler) logAndSendError(w http.ResponseWriter, logText string, reqInfo
 	h.log.Error("call method", fields...)
 }
 
+// logAndSendErrorNoHeader is the same as logAndSendError, but it must be used
+// when the response status code and the XML prolog have already been written.
+func (h *handler) logAndSendErrorNoHeader(w http.ResponseWriter, logText string, reqInfo *api.ReqInfo, err error, additional ...zap.Field) {
+	api.WriteErrorResponseNoHeader(w, reqInfo, transformToS3Error(err))
+	fields := []zap.Field{
+		zap.String("request_id", reqInfo.RequestID),
+		zap.String("method", reqInfo.API),
+		zap.String("bucket", reqInfo.BucketName),
+		zap.String("object", reqInfo.ObjectName),
+		zap.String("description", logText),
+		zap.Error(err)}
+	fields = append(fields, additional...)
+	h.log.Error("call method", fields...)
+}
+
 func transformToS3Error(err error) error {
-	if _, ok := err.(errors.Error); ok {
-		return err
+	var s3err errors.Error
+	if errorsStd.As(err, &s3err) {
+		return s3err
 	}
 
 	if errorsStd.Is(err, layer.ErrAccessDenied) ||
@@ -98,6 +114,17 @@ func parseRange(s string) (*layer.RangeParams, error) {
 	}, nil
 }
 
+// isAllowedByPolicy checks the object action against inline policy of the access box of the request.
+// Object keys of DeleteObjects and POST uploads aren't known to the auth middleware, so handlers check them.
+func isAllowedByPolicy(ctx context.Context, action, bucket, object string) bool {
+	box, err := layer.GetBoxData(ctx)
+	if err != nil || box.InlinePolicy == nil {
+		return true
+	}
+
+	return box.InlinePolicy.IsAllowed(action, s3ARNPrefix+bucket+"/"+object)
+}
+
 func getSessionTokenSetEACL(ctx context.Context) (*session.Container, error) {
 	boxData, err := layer.GetBoxData(ctx)
 	if err != nil {
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/xml"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
@@ -36,9 +38,27 @@ func (h *handler) PutBucketVersioningHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if configuration.MfaDelete != "" && configuration.MfaDelete != data.MFADeleteEnabled && configuration.MfaDelete != data.MFADeleteDisabled {
+		h.logAndSendError(w, "invalid mfa delete configuration", reqInfo, errors.GetAPIError(errors.ErrMalformedXML))
+		return
+	}
+
+	// MFA delete configuration and versioning state of the bucket with MFA delete
+	// can be changed only with MFA authentication
+	if configuration.MfaDelete != "" || settings.MFADelete {
+		if err = h.checkMFA(r); err != nil {
+			h.logAndSendError(w, "mfa authentication failed", reqInfo, err)
+			return
+		}
+	}
+
 	// settings pointer is stored in the cache, so modify a copy of the settings
 	newSettings := *settings
 	newSettings.Versioning = configuration.Status
+	if configuration.MfaDelete != "" {
+		newSettings.MFADelete = configuration.MfaDelete == data.MFADeleteEnabled
+		newSettings.MFADeleteConfigured = true
+	}
 
 	p := &layer.PutSettingsParams{
 		BktInfo:  bktInfo,
@@ -76,11 +96,42 @@ func (h *handler) GetBucketVersioningHandler(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// formVersioningConfiguration returns the configuration with Status and MfaDelete only if they were
+// ever set, as AWS S3 does, since IaC tools distinguish an empty configuration from the disabled one.
 func formVersioningConfiguration(settings *data.BucketSettings) *VersioningConfiguration {
 	res := &VersioningConfiguration{}
 	if !settings.Unversioned() {
 		res.Status = settings.Versioning
 	}
+	switch {
+	case settings.MFADelete:
+		res.MfaDelete = data.MFADeleteEnabled
+	case settings.MFADeleteConfigured:
+		res.MfaDelete = data.MFADeleteDisabled
+	}
 
 	return res
 }
+
+// checkMFA validates x-amz-mfa header which contains the serial number
+// of the MFA device and the one-time code separated by space.
+func (h *handler) checkMFA(r *http.Request) error {
+	value := r.Header.Get(api.AmzMFA)
+	if value == "" {
+		return fmt.Errorf("%w: missing %s header", errors.GetAPIError(errors.ErrAccessDenied), api.AmzMFA)
+	}
+	if h.cfg.MFA == nil {
+		return fmt.Errorf("%w: mfa validator isn't configured", errors.GetAPIError(errors.ErrNotImplemented))
+	}
+
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return fmt.Errorf("%w: invalid %s header", errors.GetAPIError(errors.ErrInvalidArgument), api.AmzMFA)
+	}
+
+	if err := h.cfg.MFA.Validate(r.Context(), fields[0], fields[1]); err != nil {
+		return fmt.Errorf("%w: %s", errors.GetAPIError(errors.ErrAccessDenied), err.Error())
+	}
+
+	return nil
+}
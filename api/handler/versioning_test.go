@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/stretchr/testify/require"
+)
+
+type mfaValidatorMock struct {
+	serialNumber string
+	code         string
+}
+
+func (m *mfaValidatorMock) Validate(_ context.Context, serialNumber, code string) error {
+	if serialNumber != m.serialNumber || code != m.code {
+		return errors.New("invalid mfa code")
+	}
+	return nil
+}
+
+func TestMFADelete(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-mfa-delete", "object-for-mfa-delete"
+	_, objInfo := createVersionedBucketAndObject(t, hc, bktName, objName)
+
+	const validMFA, invalidMFA = "device 123456", "device 654321"
+
+	putBucketVersioningMFA(hc, bktName, data.MFADeleteEnabled, validMFA, http.StatusNotImplemented)
+
+	hc.h.cfg.MFA = &mfaValidatorMock{serialNumber: "device", code: "123456"}
+	putBucketVersioningMFA(hc, bktName, data.MFADeleteEnabled, "", http.StatusForbidden)
+	putBucketVersioningMFA(hc, bktName, data.MFADeleteEnabled, invalidMFA, http.StatusForbidden)
+	putBucketVersioningMFA(hc, bktName, data.MFADeleteEnabled, "device", http.StatusBadRequest)
+	putBucketVersioningMFA(hc, bktName, data.MFADeleteEnabled, validMFA, http.StatusOK)
+
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	hc.Handler().GetBucketVersioningHandler(w, r)
+	versioning := &VersioningConfiguration{}
+	readResponse(t, w, http.StatusOK, versioning)
+	require.Equal(t, data.VersioningEnabled, versioning.Status)
+	require.Equal(t, data.MFADeleteEnabled, versioning.MfaDelete)
+
+	// versioning state can't be changed without mfa
+	putBucketVersioningMFA(hc, bktName, "", "", http.StatusForbidden)
+
+	deleteObjectVersionMFA(hc, bktName, objName, objInfo.VersionID(), "", http.StatusForbidden)
+	deleteObjectVersionMFA(hc, bktName, objName, objInfo.VersionID(), invalidMFA, http.StatusForbidden)
+	checkFound(t, hc, bktName, objName, objInfo.VersionID())
+
+	// delete markers don't require mfa
+	_, isDeleteMarker := deleteObject(t, hc, bktName, objName, emptyVersion)
+	require.True(t, isDeleteMarker)
+
+	deleteObjectVersionMFA(hc, bktName, objName, objInfo.VersionID(), validMFA, http.StatusNoContent)
+	checkNotFound(t, hc, bktName, objName, objInfo.VersionID())
+}
+
+func TestGetBucketVersioningUnset(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-versioning-status"
+	createTestBucket(hc, bktName)
+
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	hc.Handler().GetBucketVersioningHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	body := string(readBody(t, w))
+	require.NotContains(t, body, "Status")
+	require.NotContains(t, body, "MfaDelete")
+
+	putBucketVersioning(t, hc, bktName, false)
+	versioning := getBucketVersioning(hc, bktName)
+	require.Equal(t, data.VersioningSuspended, versioning.Status)
+	require.Empty(t, versioning.MfaDelete)
+
+	hc.h.cfg.MFA = &mfaValidatorMock{serialNumber: "device", code: "123456"}
+	putBucketVersioningMFA(hc, bktName, data.MFADeleteDisabled, "device 123456", http.StatusOK)
+	versioning = getBucketVersioning(hc, bktName)
+	require.Equal(t, data.VersioningEnabled, versioning.Status)
+	require.Equal(t, data.MFADeleteDisabled, versioning.MfaDelete)
+}
+
+func getBucketVersioning(hc *handlerContext, bktName string) *VersioningConfiguration {
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	hc.Handler().GetBucketVersioningHandler(w, r)
+	versioning := &VersioningConfiguration{}
+	readResponse(hc.t, w, http.StatusOK, versioning)
+	return versioning
+}
+
+func putBucketVersioningMFA(hc *handlerContext, bktName, mfaDelete, mfa string, status int) {
+	cfg := &VersioningConfiguration{Status: data.VersioningEnabled, MfaDelete: mfaDelete}
+	w, r := prepareTestRequest(hc, bktName, "", cfg)
+	if mfa != "" {
+		r.Header.Set(api.AmzMFA, mfa)
+	}
+	hc.Handler().PutBucketVersioningHandler(w, r)
+	assertStatus(hc.t, w, status)
+}
+
+func deleteObjectVersionMFA(hc *handlerContext, bktName, objName, version, mfa string, status int) {
+	query := make(url.Values)
+	query.Add(api.QueryVersionID, version)
+
+	w, r := prepareTestFullRequest(hc, bktName, objName, query, nil)
+	if mfa != "" {
+		r.Header.Set(api.AmzMFA, mfa)
+	}
+	hc.Handler().DeleteObjectHandler(w, r)
+	assertStatus(hc.t, w, status)
+}
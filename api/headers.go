@@ -12,6 +12,7 @@ const (
 	AmzDeleteMarker           = "X-Amz-Delete-Marker"
 	AmzCopySource             = "X-Amz-Copy-Source"
 	AmzCopySourceRange        = "X-Amz-Copy-Source-Range"
+	AmzCopySourceVersionID    = "X-Amz-Copy-Source-Version-Id"
 	AmzDate                   = "X-Amz-Date"
 
 	LastModified       = "Last-Modified"
@@ -38,6 +39,8 @@ const (
 	IfUnmodifiedSince  = "If-Unmodified-Since"
 	IfMatch            = "If-Match"
 	IfNoneMatch        = "If-None-Match"
+	Expect             = "Expect"
+	IfRange            = "If-Range"
 
 	AmzCopyIfModifiedSince       = "X-Amz-Copy-Source-If-Modified-Since"
 	AmzCopyIfUnmodifiedSince     = "X-Amz-Copy-Source-If-Unmodified-Since"
@@ -54,6 +57,8 @@ const (
 	AmzObjectLockMode            = "X-Amz-Object-Lock-Mode"
 	AmzObjectLockRetainUntilDate = "X-Amz-Object-Lock-Retain-Until-Date"
 	AmzBypassGovernanceRetention = "X-Amz-Bypass-Governance-Retention"
+	AmzMFA                       = "X-Amz-Mfa"
+	AmzStorageClass              = "X-Amz-Storage-Class"
 	AmzObjectAttributes          = "X-Amz-Object-Attributes"
 	AmzMaxParts                  = "X-Amz-Max-Parts"
 	AmzPartNumberMarker          = "X-Amz-Part-Number-Marker"
@@ -63,6 +68,8 @@ const (
 	AmzServerSideEncryptionCustomerKeyMD5    = "x-amz-server-side-encryption-customer-key-MD5"
 
 	ContainerID = "X-Container-Id"
+	// ContainerAttributePrefix is a prefix of HeadBucket response headers with container attributes.
+	ContainerAttributePrefix = "X-Container-Attribute-"
 
 	AccessControlAllowOrigin      = "Access-Control-Allow-Origin"
 	AccessControlAllowMethods     = "Access-Control-Allow-Methods"
@@ -78,6 +85,8 @@ const (
 	Vary = "Vary"
 
 	DefaultLocationConstraint = "default"
+
+	DefaultStorageClass = "STANDARD"
 )
 
 // S3 request query params.
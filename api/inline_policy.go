@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+)
+
+const arnS3Prefix = "arn:aws:s3:::"
+
+// policyAction is an S3 action of the route checked against inline policy of the access box.
+type policyAction struct {
+	name string
+	// object actions are checked against object resource, other ones against bucket resource.
+	object bool
+}
+
+// routeActions maps names of routes to S3 actions. Routes missing here are checked
+// as 's3:<route name>' bucket actions.
+var routeActions = map[string]policyAction{
+	"HeadObject":                {"s3:GetObject", true},
+	"GetObject":                 {"s3:GetObject", true},
+	"SelectObjectContent":       {"s3:GetObject", true},
+	"GetObjectAttributes":       {"s3:GetObjectAttributes", true},
+	"GetObjectACL":              {"s3:GetObjectAcl", true},
+	"PutObjectACL":              {"s3:PutObjectAcl", true},
+	"GetObjectTagging":          {"s3:GetObjectTagging", true},
+	"PutObjectTagging":          {"s3:PutObjectTagging", true},
+	"DeleteObjectTagging":       {"s3:DeleteObjectTagging", true},
+	"GetObjectRetention":        {"s3:GetObjectRetention", true},
+	"PutObjectRetention":        {"s3:PutObjectRetention", true},
+	"GetObjectLegalHold":        {"s3:GetObjectLegalHold", true},
+	"PutObjectLegalHold":        {"s3:PutObjectLegalHold", true},
+	"PutObject":                 {"s3:PutObject", true},
+	"CopyObject":                {"s3:PutObject", true},
+	"UploadPart":                {"s3:PutObject", true},
+	"UploadPartCopy":            {"s3:PutObject", true},
+	"CreateMultipartUpload":     {"s3:PutObject", true},
+	"CompleteMultipartUpload":   {"s3:PutObject", true},
+	"AbortMultipartUpload":      {"s3:AbortMultipartUpload", true},
+	"ListObjectParts":           {"s3:ListMultipartUploadParts", true},
+	"DeleteObject":              {"s3:DeleteObject", true},
+	"ListObjectsV1":             {"s3:ListBucket", false},
+	"ListObjectsV2":             {"s3:ListBucket", false},
+	"ListObjectsV2M":            {"s3:ListBucket", false},
+	"HeadBucket":                {"s3:ListBucket", false},
+	"ListBucketVersions":        {"s3:ListBucketVersions", false},
+	"ListMultipartUploads":      {"s3:ListBucketMultipartUploads", false},
+	"ListBuckets":               {"s3:ListAllMyBuckets", false},
+	"GetBucketACL":              {"s3:GetBucketAcl", false},
+	"PutBucketACL":              {"s3:PutBucketAcl", false},
+	"GetBucketCors":             {"s3:GetBucketCORS", false},
+	"PutBucketCors":             {"s3:PutBucketCORS", false},
+	"DeleteBucketCors":          {"s3:PutBucketCORS", false},
+	"GetBucketObjectLockConfig": {"s3:GetBucketObjectLockConfiguration", false},
+	"PutBucketObjectLockConfig": {"s3:PutBucketObjectLockConfiguration", false},
+	"GetBucketUsage":            {"s3:GetBucketUsage", false},
+}
+
+// skippedRoutes are routes which aren't restricted by inline policies here. Object keys
+// of PostObject and DeleteMultipleObjects are in the request body, so handlers check them.
+var skippedRoutes = map[string]struct{}{
+	"Options":                   {},
+	"AssumeRoleWithWebIdentity": {},
+	"PostObject":                {},
+	"DeleteMultipleObjects":     {},
+}
+
+// checkInlinePolicy checks that the request is allowed by inline policy of the access box.
+func checkInlinePolicy(r *http.Request, policy *accessbox.InlinePolicy) bool {
+	reqInfo := GetReqInfo(r.Context())
+	if _, ok := skippedRoutes[reqInfo.API]; ok {
+		return true
+	}
+
+	action, ok := routeActions[reqInfo.API]
+	if !ok {
+		action = policyAction{name: "s3:" + reqInfo.API}
+	}
+
+	if !policy.IsAllowed(action.name, policyResource(reqInfo.BucketName, reqInfo.ObjectName, action.object)) {
+		return false
+	}
+
+	// copying requires read access to the source object
+	if copySource := r.Header.Get(AmzCopySource); copySource != "" && action.object {
+		if i := strings.Index(copySource, "?"); i >= 0 {
+			copySource = copySource[:i]
+		}
+		if src, err := url.PathUnescape(copySource); err == nil {
+			copySource = src
+		}
+		return policy.IsAllowed("s3:GetObject", arnS3Prefix+strings.TrimPrefix(copySource, "/"))
+	}
+
+	return true
+}
+
+// policyResource returns ARN of the request resource. Requests without bucket are checked against '*'.
+func policyResource(bucket, object string, isObject bool) string {
+	switch {
+	case bucket == "":
+		return "*"
+	case isObject:
+		return arnS3Prefix + bucket + "/" + object
+	default:
+		return arnS3Prefix + bucket
+	}
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckInlinePolicy(t *testing.T) {
+	policy, err := accessbox.ParseInlinePolicy([]byte(`{"Statement": [
+		{"Effect": "Allow", "Action": ["s3:GetObject", "s3:ListBucket"], "Resource": ["arn:aws:s3:::src", "arn:aws:s3:::src/*"]},
+		{"Effect": "Allow", "Action": "s3:PutObject", "Resource": "arn:aws:s3:::dst/*"}
+	]}`))
+	require.NoError(t, err)
+
+	check := func(api, bucket, object, copySource string) bool {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if copySource != "" {
+			r.Header.Set(AmzCopySource, copySource)
+		}
+		r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{API: api, BucketName: bucket, ObjectName: object}))
+		return checkInlinePolicy(r, policy)
+	}
+
+	require.True(t, check("GetObject", "src", "dir/obj", ""))
+	require.True(t, check("HeadObject", "src", "obj", ""))
+	require.True(t, check("ListObjectsV2", "src", "", ""))
+	require.False(t, check("ListObjectsV2", "dst", "", ""))
+	require.False(t, check("DeleteObject", "src", "obj", ""))
+	require.False(t, check("ListBuckets", "", "", ""))
+	require.False(t, check("PutBucketVersioning", "src", "", ""))
+	require.True(t, check("Options", "dst", "", ""))
+
+	require.True(t, check("CopyObject", "dst", "obj", "/src/obj%3FversionId%3D1"))
+	require.False(t, check("CopyObject", "dst", "obj", "other/obj"))
+	require.False(t, check("CopyObject", "src", "obj", "src/obj"))
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/audit"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"go.uber.org/zap"
+)
+
+type (
+	// IPRule is a set of allowed and denied source networks in CIDR notation.
+	// Denied networks take precedence. If no networks are allowed, all sources
+	// except denied ones are allowed.
+	IPRule struct {
+		Allow []string
+		Deny  []string
+	}
+
+	// IPFilter checks source IP of requests against the global rule and rules
+	// of buckets. Rules can be updated at runtime.
+	IPFilter struct {
+		mu      sync.RWMutex
+		global  ipNetworks
+		buckets map[string]ipNetworks
+	}
+
+	ipNetworks struct {
+		allow []*net.IPNet
+		deny  []*net.IPNet
+	}
+)
+
+// NewIPFilter creates source IP filter with the global rule and rules per bucket.
+func NewIPFilter(global IPRule, buckets map[string]IPRule) (*IPFilter, error) {
+	f := &IPFilter{}
+	if err := f.Update(global, buckets); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Update replaces the rules. The rules aren't changed if any of the new ones are invalid.
+func (f *IPFilter) Update(global IPRule, buckets map[string]IPRule) error {
+	globalNetworks, err := parseIPRule(global)
+	if err != nil {
+		return err
+	}
+
+	bucketNetworks := make(map[string]ipNetworks, len(buckets))
+	for bucket, rule := range buckets {
+		if bucketNetworks[bucket], err = parseIPRule(rule); err != nil {
+			return fmt.Errorf("bucket '%s': %w", bucket, err)
+		}
+	}
+
+	f.mu.Lock()
+	f.global = globalNetworks
+	f.buckets = bucketNetworks
+	f.mu.Unlock()
+
+	return nil
+}
+
+// Allowed checks if requests from the source IP to the bucket are allowed.
+// Empty bucket means requests which don't address a bucket.
+func (f *IPFilter) Allowed(sourceIP, bucket string) bool {
+	if f == nil {
+		return true
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	global, bucketNetworks := f.global, f.buckets[bucket]
+	if global.empty() && bucketNetworks.empty() {
+		return true
+	}
+
+	ip := net.ParseIP(sourceIP)
+	if ip == nil {
+		return false
+	}
+
+	return global.allowed(ip) && bucketNetworks.allowed(ip)
+}
+
+func parseIPRule(rule IPRule) (ipNetworks, error) {
+	var (
+		res ipNetworks
+		err error
+	)
+
+	if res.allow, err = parseNetworks(rule.Allow); err != nil {
+		return res, err
+	}
+	if res.deny, err = parseNetworks(rule.Deny); err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func parseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	res := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		// single addresses are accepted as well
+		if ip := net.ParseIP(cidr); ip != nil {
+			bits := 8 * len(ip)
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network '%s': %w", cidr, err)
+		}
+		res = append(res, network)
+	}
+
+	return res, nil
+}
+
+func (n ipNetworks) empty() bool {
+	return len(n.allow) == 0 && len(n.deny) == 0
+}
+
+func (n ipNetworks) allowed(ip net.IP) bool {
+	if containsIP(n.deny, ip) {
+		return false
+	}
+	return len(n.allow) == 0 || containsIP(n.allow, ip)
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerIP returns the address of the connection peer. Forwarding headers are set
+// by clients, so they can't be used for access control.
+func peerIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// filterIP rejects requests from denied sources. The source is resolved with the trusted proxies,
+// forwarding headers of other clients are ignored.
+func filterIP(filter *IPFilter, proxies *TrustedProxies, auditLog *audit.Logger, log *zap.Logger) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqInfo := GetReqInfo(r.Context())
+
+			sourceIP := proxies.SourceIP(r)
+			if !filter.Allowed(sourceIP, reqInfo.BucketName) {
+				log.Warn("request is denied by source ip filter",
+					zap.String("request_id", reqInfo.RequestID),
+					zap.String("source_ip", sourceIP),
+					zap.String("bucket", reqInfo.BucketName),
+					zap.String("method", r.Method),
+					zap.String("url", r.URL.String()))
+				writeAudit(auditLog, log, auditRecord(r, audit.EventAccessDenied, "denied by source ip filter"))
+				WriteErrorResponse(w, reqInfo, errors.GetAPIError(errors.ErrAccessDenied))
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIPFilter(t *testing.T) {
+	t.Run("no rules", func(t *testing.T) {
+		filter, err := NewIPFilter(IPRule{}, nil)
+		require.NoError(t, err)
+		require.True(t, filter.Allowed("192.168.0.1", "bucket"))
+		require.True(t, filter.Allowed("", ""))
+	})
+
+	t.Run("global and bucket rules", func(t *testing.T) {
+		filter, err := NewIPFilter(IPRule{
+			Allow: []string{"10.0.0.0/8", "2001:db8::/32"},
+			Deny:  []string{"10.0.13.0/24"},
+		}, map[string]IPRule{
+			"internal": {Allow: []string{"10.0.1.0/24", "10.0.2.15"}},
+		})
+		require.NoError(t, err)
+
+		for _, tc := range []struct {
+			ip      string
+			bucket  string
+			allowed bool
+		}{
+			{ip: "10.1.2.3", bucket: "", allowed: true},
+			{ip: "10.1.2.3", bucket: "bucket", allowed: true},
+			{ip: "2001:db8::1", bucket: "bucket", allowed: true},
+			{ip: "192.168.0.1", bucket: "bucket", allowed: false},
+			{ip: "10.0.13.1", bucket: "bucket", allowed: false},
+			{ip: "10.0.1.1", bucket: "internal", allowed: true},
+			{ip: "10.0.2.15", bucket: "internal", allowed: true},
+			{ip: "10.0.2.16", bucket: "internal", allowed: false},
+			{ip: "invalid", bucket: "bucket", allowed: false},
+		} {
+			require.Equal(t, tc.allowed, filter.Allowed(tc.ip, tc.bucket), "%s to '%s'", tc.ip, tc.bucket)
+		}
+	})
+
+	t.Run("invalid update keeps rules", func(t *testing.T) {
+		filter, err := NewIPFilter(IPRule{Deny: []string{"10.0.0.1"}}, nil)
+		require.NoError(t, err)
+
+		err = filter.Update(IPRule{}, map[string]IPRule{"bucket": {Allow: []string{"10.0.0.0/33"}}})
+		require.Error(t, err)
+		require.False(t, filter.Allowed("10.0.0.1", ""))
+
+		_, err = NewIPFilter(IPRule{Allow: []string{"invalid"}}, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestFilterIPIgnoresForwardingHeaders(t *testing.T) {
+	filter, err := NewIPFilter(IPRule{Allow: []string{"10.0.0.0/8"}}, nil)
+	require.NoError(t, err)
+
+	handler := filterIP(filter, nil, nil, zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(remoteAddr, forwardedFor string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set(xForwardedFor, forwardedFor)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, serve("10.0.0.1:1234", "192.168.0.1"))
+	require.Equal(t, http.StatusForbidden, serve("192.168.0.1:1234", "10.0.0.1"))
+}
@@ -0,0 +1,43 @@
+package layer
+
+import (
+	"sync"
+)
+
+const (
+	// DefaultGetBufferSize is a default size of buffers copying object payload to clients.
+	DefaultGetBufferSize = 32 * 1024
+	// DefaultPutBufferSize is a default size of buffers streaming object payload to NeoFS.
+	DefaultPutBufferSize = 64 * 1024
+)
+
+// bufferPool is a pool of byte buffers of the same size used to stream object payload,
+// buffers are reused by concurrent transfers instead of being allocated for each of them.
+type bufferPool struct {
+	size int
+	pool sync.Pool
+}
+
+// detectBuffers is a pool of buffers to detect content type of objects.
+var detectBuffers = newBufferPool(contentTypeDetectSize)
+
+func newBufferPool(size int) *bufferPool {
+	p := &bufferPool{size: size}
+	p.pool.New = func() interface{} {
+		buf := make([]byte, p.size)
+		return &buf
+	}
+	return p
+}
+
+// Get returns a buffer of the pool size.
+func (p *bufferPool) Get() *[]byte {
+	buf := p.pool.Get().(*[]byte)
+	*buf = (*buf)[:p.size]
+	return buf
+}
+
+// Put returns the buffer to the pool, the buffer mustn't be used after that.
+func (p *bufferPool) Put(buf *[]byte) {
+	p.pool.Put(buf)
+}
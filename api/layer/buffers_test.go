@@ -0,0 +1,36 @@
+package layer
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferPool(t *testing.T) {
+	pool := newBufferPool(16)
+
+	buf := pool.Get()
+	require.Len(t, *buf, 16)
+	*buf = (*buf)[:4]
+	pool.Put(buf)
+
+	require.Len(t, *pool.Get(), 16)
+}
+
+func TestDetectorReleasesBuffer(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 2*contentTypeDetectSize)
+
+	d := newDetector(bytes.NewReader(content))
+	contentType, err := d.Detect()
+	require.NoError(t, err)
+	require.Equal(t, "text/plain; charset=utf-8", contentType)
+
+	var released bool
+	r := newReader(d.data, d.err, func() { released = true })
+	payload, err := io.ReadAll(io.MultiReader(r, d.Reader))
+	require.NoError(t, err)
+	require.Equal(t, content, payload)
+	require.True(t, released)
+}
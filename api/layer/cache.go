@@ -1,9 +1,13 @@
 package layer
 
 import (
+	"path"
+	"strings"
+
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"go.uber.org/zap"
@@ -17,6 +21,7 @@ type Cache struct {
 	bucketCache *cache.BucketCache
 	systemCache *cache.SystemCache
 	accessCache *cache.AccessControlCache
+	eaclCache   *cache.EACLCache
 }
 
 // CachesConfig contains params for caches.
@@ -28,6 +33,7 @@ type CachesConfig struct {
 	Buckets       *cache.Config
 	System        *cache.Config
 	AccessControl *cache.Config
+	EACL          *cache.Config
 }
 
 // DefaultCachesConfigs returns filled configs.
@@ -40,6 +46,7 @@ func DefaultCachesConfigs(logger *zap.Logger) *CachesConfig {
 		Buckets:       cache.DefaultBucketConfig(logger),
 		System:        cache.DefaultSystemConfig(logger),
 		AccessControl: cache.DefaultAccessControlConfig(logger),
+		EACL:          cache.DefaultEACLConfig(logger),
 	}
 }
 
@@ -52,9 +59,78 @@ func NewCache(cfg *CachesConfig) *Cache {
 		bucketCache: cache.NewBucketCache(cfg.Buckets),
 		systemCache: cache.NewSystemCache(cfg.System),
 		accessCache: cache.NewAccessControlCache(cfg.AccessControl),
+		eaclCache:   cache.NewEACLCache(cfg.EACL),
+	}
+}
+
+// Statistic returns usage counters of all caches.
+func (c *Cache) Statistic() []cache.Statistic {
+	return []cache.Statistic{
+		c.objCache.Statistic(),
+		c.listsCache.Statistic(),
+		c.namesCache.Statistic(),
+		c.bucketCache.Statistic(),
+		c.systemCache.Statistic(),
+		c.accessCache.Statistic(),
+		c.eaclCache.Statistic(),
 	}
 }
 
+// InvalidateBucket deletes cached entries of the bucket and returns their number.
+// If the pattern is set, only entries of objects with names matching it are deleted, see path.Match.
+func (c *Cache) InvalidateBucket(bktInfo *data.BucketInfo, pattern string) int {
+	cnrID := bktInfo.CID.EncodeToString()
+	matchObject := func(name string) bool {
+		if pattern == "" {
+			return true
+		}
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}
+	// keys of tags and locks of objects are .tagset.<cid>.<object>.<version> and .lock.<cid>.<object>.<version>
+	matchObjectSystemKey := func(key, prefix string) bool {
+		if !strings.HasPrefix(key, prefix) {
+			return false
+		}
+		name := strings.TrimPrefix(key, prefix)
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			name = name[:i]
+		}
+		return matchObject(name)
+	}
+
+	n := c.namesCache.DeleteMatching(func(key string) bool {
+		return strings.HasPrefix(key, bktInfo.Name+"/") && matchObject(strings.TrimPrefix(key, bktInfo.Name+"/"))
+	})
+	n += c.listsCache.DeleteMatching(func(key string) bool {
+		return strings.HasPrefix(key, cnrID+":")
+	})
+	n += c.systemCache.DeleteMatching(func(key string) bool {
+		switch key {
+		case bktInfo.Name + bktInfo.SettingsObjectName(),
+			bktInfo.Name + bktInfo.CORSObjectName(),
+			bktInfo.Name + bktInfo.NotificationConfigurationObjectName(),
+			bucketTaggingCacheKey(bktInfo.CID):
+			return pattern == ""
+		}
+		return matchObjectSystemKey(key, ".tagset."+cnrID+".") || matchObjectSystemKey(key, ".lock."+cnrID+".")
+	})
+
+	if pattern == "" {
+		n += c.objCache.DeleteMatching(func(key string) bool {
+			return strings.HasPrefix(key, cnrID+"/")
+		})
+		if c.bucketCache.Delete(bktInfo.Name) {
+			n++
+		}
+		if c.eaclCache.Delete(bktInfo.CID) {
+			n++
+		}
+	}
+
+	return n
+}
+
 func (c *Cache) GetBucket(name string) *data.BucketInfo {
 	return c.bucketCache.Get(name)
 }
@@ -72,6 +148,18 @@ func (c *Cache) DeleteBucket(name string) {
 	c.bucketCache.Delete(name)
 }
 
+// GetEACL returns a cached eACL table of the container.
+func (c *Cache) GetEACL(cnrID cid.ID) *eacl.Table {
+	return c.eaclCache.Get(cnrID)
+}
+
+// PutEACL caches an eACL table of the container.
+func (c *Cache) PutEACL(cnrID cid.ID, table *eacl.Table) {
+	if err := c.eaclCache.Put(cnrID, table); err != nil {
+		c.logger.Warn("couldn't cache eacl table", zap.Stringer("cid", cnrID), zap.Error(err))
+	}
+}
+
 func (c *Cache) CleanListCacheEntriesContainingObject(objectName string, cnrID cid.ID) {
 	c.listsCache.CleanCacheEntriesContainingObject(objectName, cnrID)
 }
@@ -132,6 +220,31 @@ func (c *Cache) GetList(owner user.ID, key cache.ObjectsListKey) []*data.NodeVer
 	return c.listsCache.GetVersions(key)
 }
 
+// GetListOfPrefix returns the list of the key. If the key isn't cached, the list is formed from the cached
+// list of a broader prefix (see cache.ObjectsListKey.BroaderKeys) and false is returned.
+func (c *Cache) GetListOfPrefix(owner user.ID, key cache.ObjectsListKey) ([]*data.NodeVersion, bool) {
+	if list := c.GetList(owner, key); list != nil {
+		return list, true
+	}
+
+	for _, broaderKey := range key.BroaderKeys() {
+		list := c.GetList(owner, broaderKey)
+		if list == nil {
+			continue
+		}
+
+		res := make([]*data.NodeVersion, 0, len(list))
+		for _, node := range list {
+			if strings.HasPrefix(node.FilePath, key.Prefix()) {
+				res = append(res, node)
+			}
+		}
+		return res, false
+	}
+
+	return nil, false
+}
+
 func (c *Cache) PutList(owner user.ID, key cache.ObjectsListKey, list []*data.NodeVersion) {
 	if err := c.listsCache.PutVersions(key, list); err != nil {
 		c.logger.Warn("couldn't cache list of objects", zap.Error(err))
@@ -0,0 +1,87 @@
+package layer
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestInvalidateBucketCache(t *testing.T) {
+	owner := *usertest.ID()
+	bktInfo := &data.BucketInfo{Name: "bucket", CID: cidtest.ID()}
+	otherBktInfo := &data.BucketInfo{Name: "other", CID: cidtest.ID()}
+
+	newFilledCache := func() *Cache {
+		c := NewCache(DefaultCachesConfigs(zap.NewExample()))
+		for _, bkt := range []*data.BucketInfo{bktInfo, otherBktInfo} {
+			c.PutBucket(bkt)
+			c.PutSettings(owner, bkt, &data.BucketSettings{})
+			c.PutList(owner, cache.CreateObjectsListCacheKey(bkt.CID, "", "", true), []*data.NodeVersion{})
+			for _, name := range []string{"photos/a.jpg", "docs/b.txt"} {
+				c.PutObjectWithName(owner, &data.ExtendedObjectInfo{
+					ObjectInfo: &data.ObjectInfo{ID: oidtest.ID(), CID: bkt.CID, Bucket: bkt.Name, Name: name},
+				})
+				c.PutTagging(owner, objectTaggingCacheKey(&ObjectVersion{BktInfo: bkt, ObjectName: name, VersionID: "null"}),
+					map[string]string{"key": "value"})
+			}
+		}
+		return c
+	}
+
+	t.Run("pattern", func(t *testing.T) {
+		c := newFilledCache()
+
+		// name and tags of the matching object and the listing
+		require.Equal(t, 3, c.InvalidateBucket(bktInfo, "photos/*"))
+		require.Nil(t, c.GetLastObject(owner, bktInfo.Name, "photos/a.jpg"))
+		require.NotNil(t, c.GetLastObject(owner, bktInfo.Name, "docs/b.txt"))
+		require.NotNil(t, c.GetSettings(owner, bktInfo))
+		require.NotNil(t, c.GetBucket(bktInfo.Name))
+	})
+
+	t.Run("bucket", func(t *testing.T) {
+		c := newFilledCache()
+
+		// bucket info, settings, listing, 2 names, 2 tag sets and 2 object headers
+		require.Equal(t, 9, c.InvalidateBucket(bktInfo, ""))
+		require.Nil(t, c.GetBucket(bktInfo.Name))
+		require.Nil(t, c.GetSettings(owner, bktInfo))
+		require.Nil(t, c.GetLastObject(owner, bktInfo.Name, "docs/b.txt"))
+
+		require.NotNil(t, c.GetBucket(otherBktInfo.Name))
+		require.NotNil(t, c.GetLastObject(owner, otherBktInfo.Name, "docs/b.txt"))
+	})
+}
+
+func TestGetListOfPrefix(t *testing.T) {
+	owner := *usertest.ID()
+	cnrID := cidtest.ID()
+	c := NewCache(DefaultCachesConfigs(zap.NewExample()))
+
+	nodes := []*data.NodeVersion{
+		{BaseNodeVersion: data.BaseNodeVersion{FilePath: "dir/sub/a"}},
+		{BaseNodeVersion: data.BaseNodeVersion{FilePath: "dir/b"}},
+		{BaseNodeVersion: data.BaseNodeVersion{FilePath: "other"}},
+	}
+	c.PutList(owner, cache.CreateObjectsListCacheKey(cnrID, "", "", true), nodes)
+
+	list, exact := c.GetListOfPrefix(owner, cache.CreateObjectsListCacheKey(cnrID, "dir/sub", "/", true))
+	require.False(t, exact)
+	require.Equal(t, nodes[:1], list)
+
+	list, exact = c.GetListOfPrefix(owner, cache.CreateObjectsListCacheKey(cnrID, "", "", true))
+	require.True(t, exact)
+	require.Equal(t, nodes, list)
+
+	list, _ = c.GetListOfPrefix(owner, cache.CreateObjectsListCacheKey(cnrID, "dir/", "", false))
+	require.Nil(t, list)
+
+	list, _ = c.GetListOfPrefix(*usertest.ID(), cache.CreateObjectsListCacheKey(cnrID, "dir/", "", true))
+	require.Nil(t, list)
+}
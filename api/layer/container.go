@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
@@ -13,6 +14,7 @@ import (
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"go.uber.org/zap"
 )
 
@@ -27,8 +29,33 @@ type (
 const (
 	attributeLocationConstraint = ".s3-location-constraint"
 	AttributeLockEnabled        = "LockEnabled"
+	attributeQuotaSize          = ".s3-quota-size"
+	attributeQuotaObjects       = ".s3-quota-objects"
+
+	// attributeS3Prefix is a prefix of attributes used by the gateway internally.
+	attributeS3Prefix = ".s3-"
+	// attributeZone is a system attribute of the container domain zone, "container" is the default one.
+	attributeZone = "__NEOFS__ZONE"
+	defaultZone   = "container"
 )
 
+// reservedAttributes are lowercased names of the container attributes set by the gateway and NeoFS.
+var reservedAttributes = map[string]struct{}{
+	"name":                                 {},
+	"timestamp":                            {},
+	"__neofs__name":                        {},
+	"__neofs__disable_homomorphic_hashing": {},
+	strings.ToLower(AttributeLockEnabled):  {},
+}
+
+// IsReservedContainerAttribute checks if the container attribute is set by the gateway or NeoFS,
+// so it can't be set on bucket creation. Names are compared case-insensitively.
+func IsReservedContainerAttribute(key string) bool {
+	key = strings.ToLower(key)
+	_, ok := reservedAttributes[key]
+	return ok || strings.HasPrefix(key, attributeS3Prefix)
+}
+
 func (n *layer) containerInfo(ctx context.Context, idCnr cid.ID) (*data.BucketInfo, error) {
 	var (
 		err error
@@ -71,15 +98,31 @@ func (n *layer) containerInfo(ctx context.Context, idCnr cid.ID) (*data.BucketIn
 		}
 	}
 
+	if info.Quota.Size, err = parseQuotaAttribute(cnr.Attribute(attributeQuotaSize)); err != nil {
+		log.Error("could not parse container quota size attribute", zap.Error(err))
+	}
+	if info.Quota.Objects, err = parseQuotaAttribute(cnr.Attribute(attributeQuotaObjects)); err != nil {
+		log.Error("could not parse container quota objects attribute", zap.Error(err))
+	}
+
+	cnr.IterateAttributes(func(key, val string) {
+		if IsReservedContainerAttribute(key) || key == attributeZone && val == defaultZone {
+			return
+		}
+		if info.Attributes == nil {
+			info.Attributes = make(map[string]string)
+		}
+		info.Attributes[key] = val
+	})
+
 	n.cache.PutBucket(info)
 
 	return info, nil
 }
 
-func (n *layer) containerList(ctx context.Context) ([]*data.BucketInfo, error) {
+func (n *layer) containerList(ctx context.Context, own user.ID) ([]*data.BucketInfo, error) {
 	var (
 		err error
-		own = n.Owner(ctx)
 		res []cid.ID
 		rid = api.GetRequestID(ctx)
 	)
@@ -118,6 +161,7 @@ func (n *layer) createContainer(ctx context.Context, p *CreateBucketParams) (*da
 		Created:            TimeNow(ctx),
 		LocationConstraint: p.LocationConstraint,
 		ObjectLockEnabled:  p.ObjectLockEnabled,
+		Quota:              p.Quota,
 	}
 
 	var attributes [][2]string
@@ -132,6 +176,26 @@ func (n *layer) createContainer(ctx context.Context, p *CreateBucketParams) (*da
 		})
 	}
 
+	if p.Quota.Size > 0 {
+		attributes = append(attributes, [2]string{
+			attributeQuotaSize, strconv.FormatUint(p.Quota.Size, 10),
+		})
+	}
+
+	if p.Quota.Objects > 0 {
+		attributes = append(attributes, [2]string{
+			attributeQuotaObjects, strconv.FormatUint(p.Quota.Objects, 10),
+		})
+	}
+
+	for _, attr := range p.Attributes {
+		if bktInfo.Attributes == nil {
+			bktInfo.Attributes = make(map[string]string, len(p.Attributes))
+		}
+		bktInfo.Attributes[attr[0]] = attr[1]
+		attributes = append(attributes, attr)
+	}
+
 	idCnr, err := n.neoFS.CreateContainer(ctx, PrmContainerCreate{
 		Creator:              bktInfo.Owner,
 		Policy:               p.Policy,
@@ -158,9 +222,34 @@ func (n *layer) createContainer(ctx context.Context, p *CreateBucketParams) (*da
 func (n *layer) setContainerEACLTable(ctx context.Context, idCnr cid.ID, table *eacl.Table, sessionToken *session.Container) error {
 	table.SetCID(idCnr)
 
-	return n.neoFS.SetContainerEACL(ctx, *table, sessionToken)
+	if err := n.neoFS.SetContainerEACL(ctx, *table, sessionToken); err != nil {
+		return err
+	}
+
+	// the new table is applied by NeoFS asynchronously, so it's cached to be returned right away
+	n.cache.PutEACL(idCnr, table)
+
+	return nil
 }
 
 func (n *layer) GetContainerEACL(ctx context.Context, idCnr cid.ID) (*eacl.Table, error) {
-	return n.neoFS.ContainerEACL(ctx, idCnr)
+	if table := n.cache.GetEACL(idCnr); table != nil {
+		return table, nil
+	}
+
+	table, err := n.neoFS.ContainerEACL(ctx, idCnr)
+	if err != nil {
+		return nil, err
+	}
+
+	n.cache.PutEACL(idCnr, table)
+
+	return table, nil
+}
+
+func parseQuotaAttribute(value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
 }
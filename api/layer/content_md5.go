@@ -0,0 +1,65 @@
+package layer
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"hash"
+	"io"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"go.uber.org/zap"
+)
+
+// md5Verifier computes MD5 of the payload read through it to check it against
+// Content-MD5 of the request. It wraps the payload as received from the client,
+// so the digest doesn't depend on encryption of the stored object.
+type md5Verifier struct {
+	r        io.Reader
+	hash     hash.Hash
+	expected []byte
+}
+
+func newMD5Verifier(r io.Reader, expected []byte) *md5Verifier {
+	return &md5Verifier{
+		r:        r,
+		hash:     md5.New(),
+		expected: expected,
+	}
+}
+
+func (v *md5Verifier) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	if n > 0 {
+		v.hash.Write(p[:n])
+	}
+	return n, err
+}
+
+// verify checks the digest of the payload, it must be called after the payload is read.
+func (v *md5Verifier) verify() error {
+	if !bytes.Equal(v.hash.Sum(nil), v.expected) {
+		return apiErrors.GetAPIError(apiErrors.ErrBadDigest)
+	}
+	return nil
+}
+
+// verifyPayload deletes the stored object if its payload doesn't match Content-MD5 of the request.
+// Nil verifier means Content-MD5 wasn't provided.
+func (n *layer) verifyPayload(ctx context.Context, bktInfo *data.BucketInfo, id oid.ID, v *md5Verifier) error {
+	if v == nil {
+		return nil
+	}
+
+	if err := v.verify(); err != nil {
+		if errDel := n.objectDelete(ctx, bktInfo, id); errDel != nil {
+			n.log.Warn("couldn't delete object with mismatched Content-MD5",
+				zap.Stringer("cid", bktInfo.CID), zap.Stringer("oid", id), zap.Error(errDel))
+		}
+		return err
+	}
+
+	return nil
+}
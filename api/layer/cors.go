@@ -7,6 +7,7 @@ import (
 	errorsStd "errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
@@ -32,7 +33,7 @@ func (n *layer) PutBucketCORS(ctx context.Context, p *PutCORSParams) error {
 		return errors.GetAPIError(errors.ErrMalformedXML)
 	}
 
-	if err := checkCORS(cors); err != nil {
+	if err := CheckCORS(cors); err != nil {
 		return err
 	}
 
@@ -99,13 +100,24 @@ func (n *layer) DeleteBucketCORS(ctx context.Context, bktInfo *data.BucketInfo)
 	return nil
 }
 
-func checkCORS(cors *data.CORSConfiguration) error {
+// CheckCORS validates methods, origins, allowed and exposed headers of CORS rules.
+func CheckCORS(cors *data.CORSConfiguration) error {
 	for _, r := range cors.CORSRules {
 		for _, m := range r.AllowedMethods {
 			if _, ok := supportedMethods[m]; !ok {
 				return errors.GetAPIErrorWithError(errors.ErrCORSUnsupportedMethod, fmt.Errorf("unsupported method is %s", m))
 			}
 		}
+		for _, o := range r.AllowedOrigins {
+			if strings.Count(o, wildcard) > 1 {
+				return errors.GetAPIErrorWithError(errors.ErrCORSWildcardOrigin, fmt.Errorf("invalid origin is %s", o))
+			}
+		}
+		for _, h := range r.AllowedHeaders {
+			if strings.Count(h, wildcard) > 1 {
+				return errors.GetAPIErrorWithError(errors.ErrCORSWildcardAllowedHeader, fmt.Errorf("invalid header is %s", h))
+			}
+		}
 		for _, h := range r.ExposeHeaders {
 			if h == wildcard {
 				return errors.GetAPIError(errors.ErrCORSWildcardExposeHeaders)
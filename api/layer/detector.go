@@ -9,36 +9,50 @@ type (
 	detector struct {
 		io.Reader
 		err  error
+		buf  *[]byte
 		data []byte
 	}
 	errReader struct {
-		data   []byte
-		err    error
-		offset int
+		data    []byte
+		err     error
+		offset  int
+		release func()
 	}
 )
 
 const contentTypeDetectSize = 512
 
-func newReader(data []byte, err error) *errReader {
-	return &errReader{data: data, err: err}
+// newReader returns reader of the data followed by the error, release is called once the data is read.
+func newReader(data []byte, err error, release func()) *errReader {
+	return &errReader{data: data, err: err, release: release}
 }
 
 func (r *errReader) Read(b []byte) (int, error) {
 	if r.offset >= len(r.data) {
+		r.releaseData()
 		return 0, io.EOF
 	}
 	n := copy(b, r.data[r.offset:])
 	r.offset += n
 	if r.offset >= len(r.data) {
+		r.releaseData()
 		return n, r.err
 	}
 	return n, nil
 }
 
+func (r *errReader) releaseData() {
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
+}
+
 func newDetector(reader io.Reader) *detector {
+	buf := detectBuffers.Get()
 	return &detector{
-		data:   make([]byte, contentTypeDetectSize),
+		buf:    buf,
+		data:   *buf,
 		Reader: reader,
 	}
 }
@@ -53,6 +67,8 @@ func (d *detector) Detect() (string, error) {
 	return http.DetectContentType(d.data), nil
 }
 
+// MultiReader returns reader of the detected data followed by the rest of the payload,
+// the buffer of the detector is returned to the pool once the detected data is read.
 func (d *detector) MultiReader() io.Reader {
-	return io.MultiReader(newReader(d.data, d.err), d.Reader)
+	return io.MultiReader(newReader(d.data, d.err, func() { detectBuffers.Put(d.buf) }), d.Reader)
 }
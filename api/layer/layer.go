@@ -4,16 +4,19 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"crypto/rand"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
@@ -25,6 +28,7 @@ import (
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/panjf2000/ants/v2"
 	"go.uber.org/zap"
 )
 
@@ -52,6 +56,15 @@ type (
 		ncontroller EventListener
 		cache       *Cache
 		treeService TreeService
+		objLocks    *objectLocks
+		usages      *bucketUsages
+
+		deleteWorkers int
+		listWorkers   int
+		parallelGet   ParallelGetConfig
+		getBuffers    *bufferPool
+		putBuffers    *bufferPool
+		md5ETag       bool
 	}
 
 	Config struct {
@@ -60,6 +73,18 @@ type (
 		AnonKey      AnonymousKey
 		Resolver     BucketResolver
 		TreeService  TreeService
+		// DeleteWorkers is a number of objects removed concurrently by DeleteObjects.
+		DeleteWorkers int
+		// ListWorkers is a number of objects headed concurrently by listings.
+		ListWorkers int
+		// ParallelGet is the configuration of fetching payload of large objects by ranges concurrently.
+		ParallelGet ParallelGetConfig
+		// GetBufferSize is a size of pooled buffers copying object payload to clients.
+		GetBufferSize int
+		// PutBufferSize is a size of pooled buffers streaming object payload to NeoFS.
+		PutBufferSize int
+		// MD5ETag enables MD5 of the payload as ETag of new objects instead of SHA256.
+		MD5ETag bool
 	}
 
 	// AnonymousKey contains data for anonymous requests.
@@ -107,6 +132,14 @@ type (
 		Lock         *data.ObjectLock
 		Encryption   encryption.Params
 		CopiesNumber uint32
+		// IfNotExists makes put fail with PreconditionFailed
+		// if the object already exists (If-None-Match: *).
+		IfNotExists bool
+		// ContentMD5 is the expected MD5 of the payload, it isn't checked if empty.
+		ContentMD5 []byte
+		// TagSet is put right after the version is added, the version is removed
+		// if the tagging fails, so the object never becomes visible without tags.
+		TagSet map[string]string
 	}
 
 	DeleteObjectParams struct {
@@ -140,6 +173,7 @@ type (
 		Lock        *data.ObjectLock
 		Encryption  encryption.Params
 		CopiesNuber uint32
+		TagSet      map[string]string
 	}
 	// CreateBucketParams stores bucket create request parameters.
 	CreateBucketParams struct {
@@ -150,6 +184,9 @@ type (
 		SessionEACL              *session.Container
 		LocationConstraint       string
 		ObjectLockEnabled        bool
+		Quota                    data.BucketQuota
+		// Attributes are custom attributes of the container, they mustn't be reserved ones.
+		Attributes [][2]string
 	}
 	// PutBucketACLParams stores put bucket acl request parameters.
 	PutBucketACLParams struct {
@@ -187,8 +224,11 @@ type (
 	Client interface {
 		Initialize(ctx context.Context, c EventListener) error
 		EphemeralKey() *keys.PublicKey
+		CacheStatistic() []cache.Statistic
+		InvalidateCache(ctx context.Context, bucket, pattern string) (int, error)
 
 		GetBucketSettings(ctx context.Context, bktInfo *data.BucketInfo) (*data.BucketSettings, error)
+		GetBucketUsage(ctx context.Context, bktInfo *data.BucketInfo) (*BucketUsage, error)
 		PutBucketSettings(ctx context.Context, p *PutSettingsParams) error
 
 		PutBucketCORS(ctx context.Context, p *PutCORSParams) error
@@ -196,6 +236,7 @@ type (
 		DeleteBucketCORS(ctx context.Context, bktInfo *data.BucketInfo) error
 
 		ListBuckets(ctx context.Context) ([]*data.BucketInfo, error)
+		ListOwnerBuckets(ctx context.Context, owner user.ID) ([]*data.BucketInfo, error)
 		GetBucketInfo(ctx context.Context, name string) (*data.BucketInfo, error)
 		GetBucketACL(ctx context.Context, bktInfo *data.BucketInfo) (*BucketACL, error)
 		PutBucketACL(ctx context.Context, p *PutBucketACLParams) error
@@ -205,6 +246,9 @@ type (
 		GetObject(ctx context.Context, p *GetObjectParams) error
 		GetObjectInfo(ctx context.Context, p *HeadObjectParams) (*data.ObjectInfo, error)
 		GetExtendedObjectInfo(ctx context.Context, p *HeadObjectParams) (*data.ExtendedObjectInfo, error)
+		// GetDeleteMarker returns the requested version of the object (the latest one if version id
+		// isn't specified) if it's a delete marker. ErrNodeNotFound is returned otherwise.
+		GetDeleteMarker(ctx context.Context, p *HeadObjectParams) (*data.NodeVersion, error)
 
 		GetLockInfo(ctx context.Context, obj *ObjectVersion) (*data.LockInfo, error)
 		PutLockInfo(ctx context.Context, p *PutLockInfoParams) error
@@ -254,8 +298,19 @@ const (
 	AttributeDecryptedSize       = api.NeoFSSystemMetadataPrefix + "Decrypted-Size"
 	AttributeHMACSalt            = api.NeoFSSystemMetadataPrefix + "HMAC-Salt"
 	AttributeHMACKey             = api.NeoFSSystemMetadataPrefix + "HMAC-Key"
+	AttributeStorageClass        = api.NeoFSSystemMetadataPrefix + "Storage-Class"
+	// AttributeCreated contains creation time of the object in RFC3339 format with milliseconds,
+	// since Timestamp attribute has the precision of seconds only.
+	AttributeCreated = api.NeoFSSystemMetadataPrefix + "Created"
 
 	AttributeNeofsCopiesNumber = "neofs-copies-number" // such formate to match X-Amz-Meta-Neofs-Copies-Number header
+	// AttributeNeofsPlacementPolicy matches X-Amz-Meta-Neofs-Placement-Policy header
+	// which selects placement policy profile of a new bucket or sets its raw placement policy.
+	AttributeNeofsPlacementPolicy = "neofs-placement-policy"
+	// AttributeNeofsQuotaSize and AttributeNeofsQuotaObjects match X-Amz-Meta-Neofs-Quota-Size
+	// and X-Amz-Meta-Neofs-Quota-Objects headers which set quota of a new bucket.
+	AttributeNeofsQuotaSize    = "neofs-quota-size"
+	AttributeNeofsQuotaObjects = "neofs-quota-objects"
 )
 
 func (t *VersionedObject) String() string {
@@ -269,6 +324,14 @@ func (f MsgHandlerFunc) HandleMessage(ctx context.Context, msg *nats.Msg) error
 // NewLayer creates an instance of a layer. It checks credentials
 // and establishes gRPC connection with the node.
 func NewLayer(log *zap.Logger, neoFS NeoFS, config *Config) Client {
+	getBufferSize, putBufferSize := config.GetBufferSize, config.PutBufferSize
+	if getBufferSize <= 0 {
+		getBufferSize = DefaultGetBufferSize
+	}
+	if putBufferSize <= 0 {
+		putBufferSize = DefaultPutBufferSize
+	}
+
 	return &layer{
 		neoFS:       neoFS,
 		log:         log,
@@ -276,6 +339,15 @@ func NewLayer(log *zap.Logger, neoFS NeoFS, config *Config) Client {
 		resolver:    config.Resolver,
 		cache:       NewCache(config.Caches),
 		treeService: config.TreeService,
+		objLocks:    newObjectLocks(),
+		usages:      newBucketUsages(),
+
+		deleteWorkers: config.DeleteWorkers,
+		listWorkers:   config.ListWorkers,
+		parallelGet:   config.ParallelGet,
+		getBuffers:    newBufferPool(getBufferSize),
+		putBuffers:    newBufferPool(putBufferSize),
+		md5ETag:       config.MD5ETag,
 	}
 }
 
@@ -283,6 +355,22 @@ func (n *layer) EphemeralKey() *keys.PublicKey {
 	return n.anonKey.Key.PublicKey()
 }
 
+// CacheStatistic returns usage counters of the layer caches.
+func (n *layer) CacheStatistic() []cache.Statistic {
+	return n.cache.Statistic()
+}
+
+// InvalidateCache deletes cached entries of the bucket and of its objects with names
+// matching the pattern if it's set. It returns the number of deleted entries.
+func (n *layer) InvalidateCache(ctx context.Context, bucket, pattern string) (int, error) {
+	bktInfo, err := n.GetBucketInfo(ctx, bucket)
+	if err != nil {
+		return 0, err
+	}
+
+	return n.cache.InvalidateBucket(bktInfo, pattern), nil
+}
+
 func (n *layer) Initialize(ctx context.Context, c EventListener) error {
 	if n.IsNotificationEnabled() {
 		return fmt.Errorf("already initialized")
@@ -306,13 +394,14 @@ func IsAuthenticatedRequest(ctx context.Context) bool {
 	return ok
 }
 
-// TimeNow returns client time from request or time.Now().
+// TimeNow returns client time from request or time.Now(). The time is truncated to milliseconds
+// which is the precision of creation time stored in object attributes and the tree service.
 func TimeNow(ctx context.Context) time.Time {
 	if now, ok := ctx.Value(api.ClientTime).(time.Time); ok {
-		return now
+		return now.Truncate(time.Millisecond)
 	}
 
-	return time.Now()
+	return time.Now().Truncate(time.Millisecond)
 }
 
 // Owner returns owner id from BearerToken (context) or from client owner.
@@ -379,7 +468,12 @@ func (n *layer) PutBucketACL(ctx context.Context, param *PutBucketACLParams) err
 // ListBuckets returns all user containers. The name of the bucket is a container
 // id. Timestamp is omitted since it is not saved in neofs container.
 func (n *layer) ListBuckets(ctx context.Context) ([]*data.BucketInfo, error) {
-	return n.containerList(ctx)
+	return n.containerList(ctx, n.Owner(ctx))
+}
+
+// ListOwnerBuckets returns all containers of the owner regardless of the owner of the request.
+func (n *layer) ListOwnerBuckets(ctx context.Context, owner user.ID) ([]*data.BucketInfo, error) {
+	return n.containerList(ctx, owner)
 }
 
 // GetObject from storage.
@@ -408,18 +502,26 @@ func (n *layer) GetObject(ctx context.Context, p *GetObjectParams) error {
 		}
 	}
 
-	payload, err := n.initObjectPayloadReader(ctx, params)
+	var payload io.Reader
+	var err error
+	if _, ok := p.ObjectInfo.Headers[MultipartObjectSize]; ok {
+		payload, err = n.initCombinedPayloadReader(ctx, params)
+	} else if ln := payloadLength(params, p.ObjectInfo); n.parallelGet.enabled(ln) {
+		params.ln = ln
+		payload = n.initParallelPayloadReader(ctx, params)
+	} else {
+		payload, err = n.initObjectPayloadReader(ctx, params)
+	}
 	if err != nil {
 		return fmt.Errorf("init object payload reader: %w", err)
 	}
-
-	bufSize := uint64(32 * 1024) // configure?
-	if params.ln != 0 && params.ln < bufSize {
-		bufSize = params.ln
+	if closer, ok := payload.(io.Closer); ok {
+		defer closer.Close()
 	}
 
-	// alloc buffer for copying
-	buf := make([]byte, bufSize) // sync-pool it?
+	// buffer for copying
+	buf := n.getBuffers.Get()
+	defer n.getBuffers.Put(buf)
 
 	r := payload
 	if decReader != nil {
@@ -430,7 +532,7 @@ func (n *layer) GetObject(ctx context.Context, p *GetObjectParams) error {
 	}
 
 	// copy full payload
-	written, err := io.CopyBuffer(p.Writer, r, buf)
+	written, err := io.CopyBuffer(p.Writer, r, *buf)
 	if err != nil {
 		if decReader != nil {
 			return fmt.Errorf("copy object payload written: '%d', decLength: '%d', params.ln: '%d' : %w", written, decReader.DecryptedLength(), params.ln, err)
@@ -441,6 +543,14 @@ func (n *layer) GetObject(ctx context.Context, p *GetObjectParams) error {
 	return nil
 }
 
+// payloadLength returns the length of the payload range, zero range corresponds to the full payload.
+func payloadLength(p getParams, objInfo *data.ObjectInfo) uint64 {
+	if p.ln != 0 {
+		return p.ln
+	}
+	return uint64(objInfo.Size) - p.off
+}
+
 func getDecrypter(p *GetObjectParams) (*encryption.Decrypter, error) {
 	var encRange *encryption.Range
 	if p.Range != nil {
@@ -489,35 +599,93 @@ func (n *layer) GetExtendedObjectInfo(ctx context.Context, p *HeadObjectParams)
 	return n.headVersion(ctx, p.BktInfo, p)
 }
 
+// GetDeleteMarker implements Client interface.
+func (n *layer) GetDeleteMarker(ctx context.Context, p *HeadObjectParams) (*data.NodeVersion, error) {
+	var (
+		node *data.NodeVersion
+		err  error
+	)
+
+	switch p.VersionID {
+	case "":
+		node, err = n.treeService.GetLatestVersion(ctx, p.BktInfo, p.Object)
+	case data.UnversionedObjectVersionID:
+		node, err = n.treeService.GetUnversioned(ctx, p.BktInfo, p.Object)
+	default:
+		var versions []*data.NodeVersion
+		if versions, err = n.treeService.GetVersions(ctx, p.BktInfo, p.Object); err != nil {
+			return nil, fmt.Errorf("couldn't get versions: %w", err)
+		}
+		for _, version := range versions {
+			if version.OID.EncodeToString() == p.VersionID {
+				node = version
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if node == nil || !node.IsDeleteMarker() {
+		return nil, ErrNodeNotFound
+	}
+
+	return node, nil
+}
+
 // CopyObject from one bucket into another bucket.
 func (n *layer) CopyObject(ctx context.Context, p *CopyObjectParams) (*data.ExtendedObjectInfo, error) {
-	pr, pw := io.Pipe()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	payload := n.streamObject(ctx, &GetObjectParams{
+		ObjectInfo: p.SrcObject,
+		Range:      p.Range,
+		BucketInfo: p.ScrBktInfo,
+		Encryption: p.Encryption,
+	})
+	defer payload.Close()
 
-	go func() {
-		err := n.GetObject(ctx, &GetObjectParams{
-			ObjectInfo: p.SrcObject,
-			Writer:     pw,
-			Range:      p.Range,
-			BucketInfo: p.ScrBktInfo,
-			Encryption: p.Encryption,
-		})
-
-		if err = pw.CloseWithError(err); err != nil {
-			n.log.Error("could not get object", zap.Error(err))
+	// copy contains full payload, not the list of parts
+	header := make(map[string]string, len(p.Header))
+	for key, val := range p.Header {
+		if key != MultipartObjectSize && key != MultipartObjectETag {
+			header[key] = val
 		}
-	}()
+	}
 
 	return n.PutObject(ctx, &PutObjectParams{
 		BktInfo:      p.DstBktInfo,
 		Object:       p.DstObject,
 		Size:         p.SrcSize,
-		Reader:       pr,
-		Header:       p.Header,
+		Reader:       payload,
+		Header:       header,
 		Encryption:   p.Encryption,
 		CopiesNumber: p.CopiesNuber,
+		TagSet:       p.TagSet,
 	})
 }
 
+// streamObject reads object payload in the background and returns reader of it,
+// so payload is piped from source object to the consumer without being buffered.
+// Reader must be closed to stop reading if consumer fails before reaching EOF.
+func (n *layer) streamObject(ctx context.Context, p *GetObjectParams) io.ReadCloser {
+	pr, pw := io.Pipe()
+	p.Writer = pw
+
+	go func() {
+		err := n.GetObject(ctx, p)
+		if err != nil && !stderrors.Is(err, io.ErrClosedPipe) {
+			n.log.Error("could not get object", zap.Stringer("cid", p.BucketInfo.CID),
+				zap.Stringer("oid", p.ObjectInfo.ID), zap.Error(err))
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	return pr
+}
+
 func getRandomOID() (oid.ID, error) {
 	b := [32]byte{}
 	if _, err := rand.Read(b[:]); err != nil {
@@ -542,6 +710,7 @@ func (n *layer) deleteObject(ctx context.Context, bkt *data.BucketInfo, settings
 
 		obj.Error = n.treeService.RemoveVersion(ctx, bkt, nodeVersion.ID)
 		n.cache.CleanListCacheEntriesContainingObject(obj.Name, bkt.CID)
+		n.usages.invalidate(bkt.CID)
 		return obj
 	}
 
@@ -585,6 +754,9 @@ func (n *layer) deleteObject(ctx context.Context, bkt *data.BucketInfo, settings
 	}
 
 	n.cache.DeleteObjectName(bkt.CID, bkt.Name, obj.Name)
+	if settings.VersioningSuspended() {
+		n.usages.invalidate(bkt.CID)
+	}
 
 	return obj
 }
@@ -614,18 +786,80 @@ func (n *layer) removeOldVersion(ctx context.Context, bkt *data.BucketInfo, node
 		return obj.VersionID, nil
 	}
 
-	return "", n.objectDelete(ctx, bkt, nodeVersion.OID)
+	return "", n.objectDeleteWithParts(ctx, bkt, nodeVersion.OID)
 }
 
-// DeleteObjects from the storage.
+// objectDeleteWithParts deletes object and, if it's completed multipart object, all its parts.
+func (n *layer) objectDeleteWithParts(ctx context.Context, bkt *data.BucketInfo, objID oid.ID) error {
+	meta, err := n.objectHead(ctx, bkt, objID)
+	if err != nil {
+		n.log.Warn("couldn't head object to delete", zap.Stringer("oid", objID), zap.Error(err))
+		return n.objectDelete(ctx, bkt, objID)
+	}
+
+	for _, attr := range meta.Attributes() {
+		if attr.Key() != MultipartObjectSize {
+			continue
+		}
+
+		parts, err := n.getMultipartParts(ctx, bkt, objID)
+		if err != nil {
+			return err
+		}
+		for _, part := range parts {
+			if err = n.objectDelete(ctx, bkt, part.OID); err != nil {
+				n.log.Warn("couldn't delete part of object", zap.Stringer("oid", objID),
+					zap.Stringer("part oid", part.OID), zap.Error(err))
+			}
+		}
+		break
+	}
+
+	return n.objectDelete(ctx, bkt, objID)
+}
+
+// DeleteObjects from the storage. Objects are removed concurrently, but
+// removals of the versions of the same object are serialized.
 func (n *layer) DeleteObjects(ctx context.Context, p *DeleteObjectParams) []*VersionedObject {
+	size := n.deleteWorkers
+	if size <= 0 {
+		size = 1
+	}
+
+	pool, err := ants.NewPool(size, ants.WithLogger(&logWrapper{n.log}))
+	if err != nil {
+		n.log.Warn("couldn't init go pool for deletion, delete sequentially", zap.Error(err))
+		for i, obj := range p.Objects {
+			p.Objects[i] = n.deleteObjectLocked(ctx, p.BktInfo, p.Settings, obj)
+		}
+		return p.Objects
+	}
+	defer pool.Release()
+
+	var wg sync.WaitGroup
 	for i, obj := range p.Objects {
-		p.Objects[i] = n.deleteObject(ctx, p.BktInfo, p.Settings, obj)
+		i, obj := i, obj
+		wg.Add(1)
+		if err = pool.Submit(func() {
+			defer wg.Done()
+			p.Objects[i] = n.deleteObjectLocked(ctx, p.BktInfo, p.Settings, obj)
+		}); err != nil {
+			wg.Done()
+			obj.Error = fmt.Errorf("submit deletion task: %w", err)
+		}
 	}
+	wg.Wait()
 
 	return p.Objects
 }
 
+func (n *layer) deleteObjectLocked(ctx context.Context, bkt *data.BucketInfo, settings *data.BucketSettings, obj *VersionedObject) *VersionedObject {
+	unlock := n.objLocks.Lock(bkt.CID, obj.Name)
+	defer unlock()
+
+	return n.deleteObject(ctx, bkt, settings, obj)
+}
+
 func (n *layer) CreateBucket(ctx context.Context, p *CreateBucketParams) (*data.BucketInfo, error) {
 	bktInfo, err := n.GetBucketInfo(ctx, p.Name)
 	if err != nil {
@@ -1,8 +1,11 @@
 package layer
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
 	stderrors "errors"
 	"fmt"
 	"io"
@@ -24,6 +27,12 @@ const (
 	UploadIDAttributeName         = "S3-Upload-Id"
 	UploadPartNumberAttributeName = "S3-Upload-Part-Number"
 	UploadCompletedParts          = "S3-Completed-Parts"
+	// MultipartObjectSize is set on objects of completed multipart uploads,
+	// whose payload lists the parts instead of containing the data itself.
+	MultipartObjectSize = "S3-Multipart-Object-Size"
+	// MultipartObjectETag is set on objects of completed multipart uploads
+	// and contains ETag in the "md5-of-part-checksums-N" form.
+	MultipartObjectETag = "S3-Multipart-Object-ETag"
 
 	metaPrefix = "meta-"
 	aclPrefix  = "acl-"
@@ -61,6 +70,8 @@ type (
 		PartNumber int
 		Size       int64
 		Reader     io.Reader
+		// ContentMD5 is the expected MD5 of the part payload, it isn't checked if empty.
+		ContentMD5 []byte
 	}
 
 	UploadCopyParams struct {
@@ -74,6 +85,9 @@ type (
 	CompleteMultipartParams struct {
 		Info  *UploadInfoParams
 		Parts []*CompletedPart
+		// IfNotExists makes completion fail with PreconditionFailed
+		// if the object already exists (If-None-Match: *).
+		IfNotExists bool
 	}
 
 	CompletedPart struct {
@@ -114,6 +128,7 @@ type (
 		Owner                user.ID
 		NextPartNumberMarker int
 		IsTruncated          bool
+		StorageClass         string
 	}
 
 	ListMultipartUploadsInfo struct {
@@ -124,11 +139,12 @@ type (
 		NextUploadIDMarker string
 	}
 	UploadInfo struct {
-		IsDir    bool
-		Key      string
-		UploadID string
-		Owner    user.ID
-		Created  time.Time
+		IsDir        bool
+		Key          string
+		UploadID     string
+		Owner        user.ID
+		Created      time.Time
+		StorageClass string
 	}
 )
 
@@ -199,6 +215,12 @@ func (n *layer) uploadPart(ctx context.Context, multipartInfo *data.MultipartInf
 		return nil, errors.GetAPIError(errors.ErrInvalidEncryptionParameters)
 	}
 
+	var verifier *md5Verifier
+	if len(p.ContentMD5) != 0 {
+		verifier = newMD5Verifier(p.Reader, p.ContentMD5)
+		p.Reader = verifier
+	}
+
 	bktInfo := p.Info.Bkt
 	prm := PrmObjectCreate{
 		Container:    bktInfo.CID,
@@ -227,6 +249,9 @@ func (n *layer) uploadPart(ctx context.Context, multipartInfo *data.MultipartInf
 	if err != nil {
 		return nil, err
 	}
+	if err = n.verifyPayload(ctx, bktInfo, id, verifier); err != nil {
+		return nil, err
+	}
 
 	partInfo := &data.PartInfo{
 		Key:      p.Info.Key,
@@ -277,29 +302,24 @@ func (n *layer) UploadPartCopy(ctx context.Context, p *UploadCopyParams) (*data.
 
 	size := p.SrcObjInfo.Size
 	if p.Range != nil {
-		size = int64(p.Range.End - p.Range.Start + 1)
-		if p.Range.End > uint64(p.SrcObjInfo.Size) {
+		if p.Range.End >= uint64(p.SrcObjInfo.Size) {
 			return nil, errors.GetAPIError(errors.ErrInvalidCopyPartRangeSource)
 		}
+		size = int64(p.Range.End - p.Range.Start + 1)
 	}
 	if size > uploadMaxSize {
 		return nil, errors.GetAPIError(errors.ErrEntityTooLarge)
 	}
 
-	pr, pw := io.Pipe()
-
-	go func() {
-		err = n.GetObject(ctx, &GetObjectParams{
-			ObjectInfo: p.SrcObjInfo,
-			Writer:     pw,
-			Range:      p.Range,
-			BucketInfo: p.SrcBktInfo,
-		})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		if err = pw.CloseWithError(err); err != nil {
-			n.log.Error("could not get object", zap.Error(err))
-		}
-	}()
+	pr := n.streamObject(ctx, &GetObjectParams{
+		ObjectInfo: p.SrcObjInfo,
+		Range:      p.Range,
+		BucketInfo: p.SrcBktInfo,
+	})
+	defer pr.Close()
 
 	params := &UploadPartParams{
 		Info:       p.Info,
@@ -311,17 +331,65 @@ func (n *layer) UploadPartCopy(ctx context.Context, p *UploadCopyParams) (*data.
 	return n.uploadPart(ctx, multipartInfo, params)
 }
 
+// MultipartPart is a part object referenced by the completed multipart object.
+type MultipartPart struct {
+	OID oid.ID
+	// Size is the payload size of the part object.
+	Size uint64
+}
+
+// partRef is an element of the payload of the completed multipart object.
+type partRef struct {
+	OID  string `json:"oid"`
+	Size uint64 `json:"size"`
+}
+
+// EncodeMultipartParts encodes the payload of the completed multipart object.
+func EncodeMultipartParts(parts []MultipartPart) ([]byte, error) {
+	refs := make([]partRef, len(parts))
+	for i, part := range parts {
+		refs[i] = partRef{OID: part.OID.EncodeToString(), Size: part.Size}
+	}
+	return json.Marshal(refs)
+}
+
+// DecodeMultipartParts decodes the payload of the completed multipart object, i.e. the object
+// with MultipartObjectSize attribute, into the list of its parts.
+func DecodeMultipartParts(payload []byte) ([]MultipartPart, error) {
+	var refs []partRef
+	if err := json.Unmarshal(payload, &refs); err != nil {
+		return nil, fmt.Errorf("unmarshal parts of combined object: %w", err)
+	}
+
+	parts := make([]MultipartPart, len(refs))
+	for i, ref := range refs {
+		if err := parts[i].OID.DecodeString(ref.OID); err != nil {
+			return nil, fmt.Errorf("invalid part oid '%s': %w", ref.OID, err)
+		}
+		parts[i].Size = ref.Size
+	}
+
+	return parts, nil
+}
+
+// partRange is a payload range of the single part.
+// Zero length corresponds to the full payload of the part.
+type partRange struct {
+	oid     oid.ID
+	off, ln uint64
+}
+
 // implements io.Reader of payloads of the object list stored in the NeoFS network.
 type multiObjectReader struct {
 	ctx context.Context
 
 	layer *layer
 
-	prm getParams
+	bktInfo *data.BucketInfo
 
 	curReader io.Reader
 
-	parts []*data.PartInfo
+	parts []partRange
 }
 
 func (x *multiObjectReader) Read(p []byte) (n int, err error) {
@@ -336,9 +404,12 @@ func (x *multiObjectReader) Read(p []byte) (n int, err error) {
 		return n, io.EOF
 	}
 
-	x.prm.oid = x.parts[0].OID
-
-	x.curReader, err = x.layer.initObjectPayloadReader(x.ctx, x.prm)
+	x.curReader, err = x.layer.initObjectPayloadReader(x.ctx, getParams{
+		oid:     x.parts[0].oid,
+		bktInfo: x.bktInfo,
+		off:     x.parts[0].off,
+		ln:      x.parts[0].ln,
+	})
 	if err != nil {
 		return n, fmt.Errorf("init payload reader for the next part: %w", err)
 	}
@@ -350,6 +421,89 @@ func (x *multiObjectReader) Read(p []byte) (n int, err error) {
 	return n + next, err
 }
 
+// initCombinedPayloadReader initializes reader of the payload of the completed multipart object
+// by reading the list of its parts. Zero range corresponds to full payload.
+func (n *layer) initCombinedPayloadReader(ctx context.Context, p getParams) (io.Reader, error) {
+	parts, err := n.getMultipartParts(ctx, p.bktInfo, p.oid)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges, err := partRanges(parts, p.off, p.ln)
+	if err != nil {
+		return nil, err
+	}
+
+	return &multiObjectReader{
+		ctx:     ctx,
+		layer:   n,
+		bktInfo: p.bktInfo,
+		parts:   ranges,
+	}, nil
+}
+
+func (n *layer) getMultipartParts(ctx context.Context, bktInfo *data.BucketInfo, objID oid.ID) ([]MultipartPart, error) {
+	obj, err := n.objectGet(ctx, bktInfo, objID)
+	if err != nil {
+		return nil, fmt.Errorf("get combined object: %w", err)
+	}
+
+	return DecodeMultipartParts(obj.Payload())
+}
+
+// partRanges converts payload range of the combined object to ranges of its parts.
+func partRanges(parts []MultipartPart, off, ln uint64) ([]partRange, error) {
+	ranges := make([]partRange, 0, len(parts))
+	full := ln == 0
+
+	for _, part := range parts {
+		if !full && ln == 0 {
+			break
+		}
+		if off >= part.Size {
+			off -= part.Size
+			continue
+		}
+
+		rng := partRange{oid: part.OID}
+		take := part.Size - off
+		if !full && ln < take {
+			take = ln
+		}
+		if off != 0 || take != part.Size {
+			rng.off, rng.ln = off, take
+		}
+		if !full {
+			ln -= take
+		}
+
+		off = 0
+		ranges = append(ranges, rng)
+	}
+
+	if !full && ln != 0 {
+		return nil, fmt.Errorf("range is out of combined object payload")
+	}
+
+	return ranges, nil
+}
+
+// multipartETag computes ETag of the completed multipart upload: hex encoded
+// md5 of the concatenated binary part checksums followed by the number of parts.
+// It matches ETag of AWS S3 only if part checksums are MD5, see Config.MD5ETag.
+func multipartETag(partETags []string) (string, error) {
+	hash := md5.New()
+	for _, etag := range partETags {
+		sum, err := hex.DecodeString(etag)
+		if err != nil {
+			return "", fmt.Errorf("decode part etag '%s': %w", etag, err)
+		}
+		hash.Write(sum)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)) + "-" + strconv.Itoa(len(partETags)), nil
+}
+
 func (n *layer) CompleteMultipartUpload(ctx context.Context, p *CompleteMultipartParams) (*UploadData, *data.ExtendedObjectInfo, error) {
 	for i := 1; i < len(p.Parts); i++ {
 		if p.Parts[i].PartNumber <= p.Parts[i-1].PartNumber {
@@ -369,9 +523,11 @@ func (n *layer) CompleteMultipartUpload(ctx context.Context, p *CompleteMultipar
 
 	var multipartObjetSize int64
 	var encMultipartObjectSize uint64
-	parts := make([]*data.PartInfo, 0, len(p.Parts))
+	parts := make([]MultipartPart, 0, len(p.Parts))
+	completed := make(map[int]struct{}, len(p.Parts))
 
 	var completedPartsHeader strings.Builder
+	partETags := make([]string, 0, len(p.Parts))
 	for i, part := range p.Parts {
 		partInfo := partsInfo[part.PartNumber]
 		if partInfo == nil || part.ETag != partInfo.ETag {
@@ -381,16 +537,20 @@ func (n *layer) CompleteMultipartUpload(ctx context.Context, p *CompleteMultipar
 		if i != len(p.Parts)-1 && partInfo.Size < uploadMinSize {
 			return nil, nil, errors.GetAPIError(errors.ErrEntityTooSmall)
 		}
-		parts = append(parts, partInfo)
+		completed[part.PartNumber] = struct{}{}
 		multipartObjetSize += partInfo.Size // even if encryption is enabled size is actual (decrypted)
 
+		part := MultipartPart{OID: partInfo.OID, Size: uint64(partInfo.Size)}
 		if encInfo.Enabled {
 			encPartSize, err := sio.EncryptedSize(uint64(partInfo.Size))
 			if err != nil {
 				return nil, nil, fmt.Errorf("compute encrypted size: %w", err)
 			}
 			encMultipartObjectSize += encPartSize
+			part.Size = encPartSize
 		}
+		parts = append(parts, part)
+		partETags = append(partETags, partInfo.ETag)
 
 		partInfoStr := partInfo.ToHeaderString()
 		if i != len(p.Parts)-1 {
@@ -426,22 +586,26 @@ func (n *layer) CompleteMultipartUpload(ctx context.Context, p *CompleteMultipar
 		multipartObjetSize = int64(encMultipartObjectSize)
 	}
 
-	r := &multiObjectReader{
-		ctx:   ctx,
-		layer: n,
-		parts: parts,
+	initMetadata[MultipartObjectSize] = strconv.FormatInt(multipartObjetSize, 10)
+	if initMetadata[MultipartObjectETag], err = multipartETag(partETags); err != nil {
+		return nil, nil, err
 	}
 
-	r.prm.bktInfo = p.Info.Bkt
+	// parts are referenced by the completed object instead of being re-uploaded
+	payload, err := EncodeMultipartParts(parts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal parts of completed object: %w", err)
+	}
 
 	extObjInfo, err := n.PutObject(ctx, &PutObjectParams{
 		BktInfo:      p.Info.Bkt,
 		Object:       p.Info.Key,
-		Reader:       r,
+		Reader:       bytes.NewReader(payload),
 		Header:       initMetadata,
-		Size:         multipartObjetSize,
-		Encryption:   p.Info.Encryption,
+		Size:         int64(len(payload)),
 		CopiesNumber: multipartInfo.CopiesNumber,
+		IfNotExists:  p.IfNotExists,
+		TagSet:       uploadData.TagSet,
 	})
 	if err != nil {
 		n.log.Error("could not put a completed object (multipart upload)",
@@ -449,20 +613,23 @@ func (n *layer) CompleteMultipartUpload(ctx context.Context, p *CompleteMultipar
 			zap.String("uploadKey", p.Info.Key),
 			zap.Error(err))
 
+		if errors.IsS3Error(err, errors.ErrPreconditionFailed) || errors.IsS3Error(err, errors.ErrQuotaExceeded) {
+			return nil, nil, err
+		}
 		return nil, nil, errors.GetAPIError(errors.ErrInternalError)
 	}
+	extObjInfo.ObjectInfo.Size = multipartObjetSize
 
-	var addr oid.Address
-	addr.SetContainer(p.Info.Bkt.CID)
-	for _, partInfo := range partsInfo {
+	for num, partInfo := range partsInfo {
+		if _, ok := completed[num]; ok {
+			continue
+		}
 		if err = n.objectDelete(ctx, p.Info.Bkt, partInfo.OID); err != nil {
 			n.log.Warn("could not delete upload part",
 				zap.Stringer("object id", &partInfo.OID),
 				zap.Stringer("bucket id", p.Info.Bkt.CID),
 				zap.Error(err))
 		}
-		addr.SetObject(partInfo.OID)
-		n.cache.DeleteObject(addr)
 	}
 
 	return uploadData, extObjInfo, n.treeService.DeleteMultipartUpload(ctx, p.Info.Bkt, multipartInfo.ID)
@@ -558,6 +725,7 @@ func (n *layer) ListParts(ctx context.Context, p *ListPartsParams) (*ListPartsIn
 	}
 
 	res.Owner = multipartInfo.Owner
+	res.StorageClass = multipartInfo.Meta[metaPrefix+AttributeStorageClass]
 
 	parts := make([]*Part, 0, len(partsInfo))
 
@@ -575,17 +743,18 @@ func (n *layer) ListParts(ctx context.Context, p *ListPartsParams) (*ListPartsIn
 	})
 
 	if p.PartNumberMarker != 0 {
-		for i, part := range parts {
-			if part.PartNumber > p.PartNumberMarker {
-				parts = parts[i:]
-				break
-			}
-		}
+		idx := sort.Search(len(parts), func(i int) bool {
+			return parts[i].PartNumber > p.PartNumberMarker
+		})
+		parts = parts[idx:]
 	}
 
 	if len(parts) > p.MaxParts {
 		res.IsTruncated = true
-		res.NextPartNumberMarker = parts[p.MaxParts-1].PartNumber
+		res.NextPartNumberMarker = p.PartNumberMarker
+		if p.MaxParts > 0 {
+			res.NextPartNumberMarker = parts[p.MaxParts-1].PartNumber
+		}
 		parts = parts[:p.MaxParts]
 	}
 
@@ -664,10 +833,11 @@ func uploadInfoFromMultipartInfo(uploadInfo *data.MultipartInfo, prefix, delimit
 	}
 
 	return &UploadInfo{
-		IsDir:    isDir,
-		Key:      key,
-		UploadID: uploadInfo.UploadID,
-		Owner:    uploadInfo.Owner,
-		Created:  uploadInfo.Created,
+		IsDir:        isDir,
+		Key:          key,
+		UploadID:     uploadInfo.UploadID,
+		Owner:        uploadInfo.Owner,
+		Created:      uploadInfo.Created,
+		StorageClass: uploadInfo.Meta[metaPrefix+AttributeStorageClass],
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"sort"
 	"testing"
 
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
 	"github.com/stretchr/testify/require"
 )
 
@@ -106,3 +107,62 @@ func TestTrimAfterUploadKey(t *testing.T) {
 		require.Empty(t, keys)
 	})
 }
+
+func TestPartRanges(t *testing.T) {
+	id1, id2, id3 := oidtest.ID(), oidtest.ID(), oidtest.ID()
+	parts := []MultipartPart{
+		{OID: id1, Size: 10},
+		{OID: id2, Size: 5},
+		{OID: id3, Size: 10},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		off, ln  uint64
+		expected []partRange
+		err      bool
+	}{
+		{name: "full", expected: []partRange{{oid: id1}, {oid: id2}, {oid: id3}}},
+		{name: "first part", off: 0, ln: 10, expected: []partRange{{oid: id1}}},
+		{name: "inside part", off: 2, ln: 3, expected: []partRange{{oid: id1, off: 2, ln: 3}}},
+		{name: "across parts", off: 8, ln: 10, expected: []partRange{{oid: id1, off: 8, ln: 2}, {oid: id2}, {oid: id3, off: 0, ln: 3}}},
+		{name: "last part tail", off: 20, ln: 5, expected: []partRange{{oid: id3, off: 5, ln: 5}}},
+		{name: "out of payload", off: 20, ln: 10, err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ranges, err := partRanges(parts, tc.off, tc.ln)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ranges)
+		})
+	}
+}
+
+func TestMultipartParts(t *testing.T) {
+	parts := []MultipartPart{{OID: oidtest.ID(), Size: 10}, {OID: oidtest.ID(), Size: 5}}
+
+	payload, err := EncodeMultipartParts(parts)
+	require.NoError(t, err)
+
+	decoded, err := DecodeMultipartParts(payload)
+	require.NoError(t, err)
+	require.Equal(t, parts, decoded)
+
+	_, err = DecodeMultipartParts([]byte(`[{"oid":"invalid","size":1}]`))
+	require.Error(t, err)
+
+	_, err = DecodeMultipartParts([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestMultipartETag(t *testing.T) {
+	etag, err := multipartETag([]string{"0a0b", "ff"})
+	require.NoError(t, err)
+	require.Equal(t, "725d34c955fcb3c3ebd81e1d6d3fca7b-2", etag)
+
+	_, err = multipartETag([]string{"not hex"})
+	require.Error(t, err)
+}
@@ -174,6 +174,13 @@ func (t *TestNeoFS) CreateObject(ctx context.Context, prm PrmObjectCreate) (oid.
 
 	attrs := make([]object.Attribute, 0)
 
+	if !prm.CreationTime.IsZero() {
+		a := object.NewAttribute()
+		a.SetKey(AttributeCreated)
+		a.SetValue(prm.CreationTime.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano))
+		attrs = append(attrs, *a)
+	}
+
 	if prm.Filepath != "" {
 		a := object.NewAttribute()
 		a.SetKey(object.AttributeFilePath)
@@ -1,7 +1,9 @@
 package layer
 
 import (
+	"container/heap"
 	"context"
+	"crypto/md5"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
@@ -9,7 +11,6 @@ import (
 	"io"
 	"mime"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,11 +20,13 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"github.com/panjf2000/ants/v2"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -182,10 +185,78 @@ func ParseCompletedPartHeader(hdr string) (*Part, error) {
 	}, nil
 }
 
+// objectLocks serializes conditional writes of the same object within the gateway.
+type objectLocks struct {
+	mu    sync.Mutex
+	locks map[string]*objectLock
+}
+
+type objectLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newObjectLocks() *objectLocks {
+	return &objectLocks{locks: make(map[string]*objectLock)}
+}
+
+// Lock acquires lock for the object in the container and returns function to release it.
+func (l *objectLocks) Lock(cnrID cid.ID, objectName string) func() {
+	key := cnrID.EncodeToString() + "/" + objectName
+
+	l.mu.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = new(objectLock)
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.Lock()
+
+	return func() {
+		lock.Unlock()
+
+		l.mu.Lock()
+		if lock.refs--; lock.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// checkObjectNotExists returns PreconditionFailed error if the latest version
+// of the object exists and isn't a delete marker. Cache isn't used intentionally.
+func (n *layer) checkObjectNotExists(ctx context.Context, bktInfo *data.BucketInfo, objectName string) error {
+	node, err := n.treeService.GetLatestVersion(ctx, bktInfo, objectName)
+	if err != nil {
+		if errors.Is(err, ErrNodeNotFound) {
+			return nil
+		}
+		return fmt.Errorf("get latest version: %w", err)
+	}
+
+	if node.IsDeleteMarker() {
+		return nil
+	}
+
+	return apiErrors.GetAPIError(apiErrors.ErrPreconditionFailed)
+}
+
 // PutObject stores object into NeoFS, took payload from io.Reader.
 func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.ExtendedObjectInfo, error) {
 	owner := n.Owner(ctx)
 
+	if p.IfNotExists {
+		unlock := n.objLocks.Lock(p.BktInfo.CID, p.Object)
+		defer unlock()
+
+		if err := n.checkObjectNotExists(ctx, p.BktInfo, p.Object); err != nil {
+			return nil, err
+		}
+	}
+
 	bktSettings, err := n.GetBucketSettings(ctx, p.BktInfo)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't get versioning settings object: %w", err)
@@ -198,6 +269,33 @@ func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.Extend
 		},
 		IsUnversioned: !bktSettings.VersioningEnabled(),
 	}
+	if val, ok := p.Header[MultipartObjectSize]; ok {
+		if newVersion.Size, err = strconv.ParseInt(val, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid multipart object size '%s': %w", val, err)
+		}
+	}
+
+	// the unversioned version is replaced by the new one, so its object becomes unreachable
+	var replaced *data.NodeVersion
+	if newVersion.IsUnversioned {
+		if replaced, err = n.treeService.GetUnversioned(ctx, p.BktInfo, p.Object); err != nil {
+			if !errors.Is(err, ErrNodeNotFound) {
+				return nil, fmt.Errorf("get unversioned version: %w", err)
+			}
+			replaced = nil
+		}
+	}
+
+	quotaDelta, err := n.checkQuota(ctx, p.BktInfo, newVersion, replaced)
+	if err != nil {
+		return nil, err
+	}
+
+	var verifier *md5Verifier
+	if len(p.ContentMD5) != 0 {
+		verifier = newMD5Verifier(p.Reader, p.ContentMD5)
+		p.Reader = verifier
+	}
 
 	r := p.Reader
 	if p.Encryption.Enabled() {
@@ -247,12 +345,52 @@ func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.Extend
 	if err != nil {
 		return nil, err
 	}
+	if err = n.verifyPayload(ctx, p.BktInfo, id, verifier); err != nil {
+		return nil, err
+	}
+
+	if p.IfNotExists {
+		// Check once more right before the version becomes visible: this narrows the window
+		// for concurrent writers from other gateways, but doesn't close it, since objLocks
+		// serializes writers only within this process and the tree has no conditional insert.
+		if err = n.checkObjectNotExists(ctx, p.BktInfo, p.Object); err != nil {
+			if errDel := n.objectDelete(ctx, p.BktInfo, id); errDel != nil {
+				n.log.Warn("couldn't delete object after failed precondition",
+					zap.Stringer("cid", p.BktInfo.CID), zap.Stringer("oid", id), zap.Error(errDel))
+			}
+			return nil, err
+		}
+	}
 
 	newVersion.OID = id
 	newVersion.ETag = hex.EncodeToString(hash)
+	newVersion.ObjectMeta = &data.ObjectMeta{
+		Created:      prm.CreationTime,
+		Owner:        owner,
+		StorageClass: p.Header[AttributeStorageClass],
+	}
+	if etag, ok := p.Header[MultipartObjectETag]; ok {
+		newVersion.ETag = etag
+	}
 	if newVersion.ID, err = n.treeService.AddVersion(ctx, p.BktInfo, newVersion); err != nil {
 		return nil, fmt.Errorf("couldn't add new verion to tree service: %w", err)
 	}
+	if quotaDelta != nil {
+		n.usages.add(p.BktInfo.CID, *quotaDelta)
+	}
+	if replaced != nil && !replaced.IsDeleteMarker() {
+		if err = n.objectDeleteWithParts(ctx, p.BktInfo, replaced.OID); err != nil {
+			n.log.Warn("couldn't delete replaced object", zap.Stringer("cid", p.BktInfo.CID),
+				zap.String("object", p.Object), zap.Stringer("oid", replaced.OID), zap.Error(err))
+		}
+	}
+
+	if len(p.TagSet) != 0 {
+		if err = n.treeService.PutObjectTagging(ctx, p.BktInfo, newVersion, p.TagSet); err != nil {
+			n.removeNewVersion(ctx, p.BktInfo, newVersion)
+			return nil, fmt.Errorf("couldn't put tagging of new version: %w", err)
+		}
+	}
 
 	if p.Lock != nil && (p.Lock.Retention != nil || p.Lock.LegalHold != nil) {
 		putLockInfoPrms := &PutLockInfoParams{
@@ -293,10 +431,27 @@ func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.Extend
 	}
 
 	n.cache.PutObjectWithName(owner, extendedObjInfo)
+	if len(p.TagSet) != 0 {
+		objVersion := &ObjectVersion{BktInfo: p.BktInfo, ObjectName: p.Object, VersionID: id.EncodeToString()}
+		n.cache.PutTagging(owner, objectTaggingCacheKey(objVersion), p.TagSet)
+	}
 
 	return extendedObjInfo, nil
 }
 
+// removeNewVersion removes the version which failed to be completed and its object.
+func (n *layer) removeNewVersion(ctx context.Context, bktInfo *data.BucketInfo, version *data.NodeVersion) {
+	if err := n.treeService.RemoveVersion(ctx, bktInfo, version.ID); err != nil {
+		n.log.Warn("couldn't remove failed version", zap.Stringer("cid", bktInfo.CID),
+			zap.String("object", version.FilePath), zap.Stringer("oid", version.OID), zap.Error(err))
+		return
+	}
+	if err := n.objectDelete(ctx, bktInfo, version.OID); err != nil {
+		n.log.Warn("couldn't delete object of failed version", zap.Stringer("cid", bktInfo.CID),
+			zap.Stringer("oid", version.OID), zap.Error(err))
+	}
+}
+
 func (n *layer) headLastVersionIfNotDeleted(ctx context.Context, bkt *data.BucketInfo, objectName string) (*data.ExtendedObjectInfo, error) {
 	owner := n.Owner(ctx)
 	if extObjInfo := n.cache.GetLastObject(owner, bkt.Name, objectName); extObjInfo != nil {
@@ -319,7 +474,7 @@ func (n *layer) headLastVersionIfNotDeleted(ctx context.Context, bkt *data.Bucke
 	if err != nil {
 		return nil, err
 	}
-	objInfo := objectInfoFromMeta(bkt, meta)
+	objInfo := objectInfoFromMetaAndNode(bkt, meta, node)
 
 	extObjInfo := &data.ExtendedObjectInfo{
 		ObjectInfo:  objInfo,
@@ -359,6 +514,10 @@ func (n *layer) headVersion(ctx context.Context, bkt *data.BucketInfo, p *HeadOb
 		}
 	}
 
+	if foundVersion.IsDeleteMarker() {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrMethodNotAllowed)
+	}
+
 	owner := n.Owner(ctx)
 	if extObjInfo := n.cache.GetObject(owner, newAddress(bkt.CID, foundVersion.OID)); extObjInfo != nil {
 		return extObjInfo, nil
@@ -371,7 +530,7 @@ func (n *layer) headVersion(ctx context.Context, bkt *data.BucketInfo, p *HeadOb
 		}
 		return nil, err
 	}
-	objInfo := objectInfoFromMeta(bkt, meta)
+	objInfo := objectInfoFromMetaAndNode(bkt, meta, foundVersion)
 
 	extObjInfo := &data.ExtendedObjectInfo{
 		ObjectInfo:  objInfo,
@@ -398,11 +557,14 @@ func (n *layer) objectDelete(ctx context.Context, bktInfo *data.BucketInfo, idOb
 }
 
 // objectPutAndHash prepare auth parameters and invoke neofs.CreateObject.
-// Returns object ID and payload sha256 hash.
+// Returns object ID and payload sha256 hash (md5 hash if MD5 ETags are enabled).
 func (n *layer) objectPutAndHash(ctx context.Context, prm PrmObjectCreate, bktInfo *data.BucketInfo) (oid.ID, []byte, error) {
 	n.prepareAuthParameters(ctx, &prm.PrmAuth, bktInfo.Owner)
 	hash := sha256.New()
-	prm.Payload = wrapReader(prm.Payload, 64*1024, func(buf []byte) {
+	if n.md5ETag {
+		hash = md5.New()
+	}
+	prm.Payload = wrapReader(prm.Payload, n.putBuffers, func(buf []byte) {
 		hash.Write(buf)
 	})
 	id, err := n.neoFS.CreateObject(ctx, prm)
@@ -480,15 +642,24 @@ func (n *layer) getLatestObjectsVersions(ctx context.Context, p allObjectParams)
 		return nil, nil, nil
 	}
 
+	ctx, span := tracing.StartSpan(ctx, "layer.ListLatestVersions", attribute.String("prefix", p.Prefix))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	owner := n.Owner(ctx)
-	cacheKey := cache.CreateObjectsListCacheKey(p.Bucket.CID, p.Prefix, true)
-	nodeVersions := n.cache.GetList(owner, cacheKey)
+	cacheKey := cache.CreateObjectsListCacheKey(p.Bucket.CID, p.Prefix, p.Delimiter, true)
+	nodeVersions, exact := n.cache.GetListOfPrefix(owner, cacheKey)
+	span.SetAttributes(attribute.Bool("cache_hit", nodeVersions != nil))
 
 	if nodeVersions == nil {
 		nodeVersions, err = n.treeService.GetLatestVersionsByPrefix(ctx, p.Bucket, p.Prefix)
 		if err != nil {
 			return nil, nil, err
 		}
+		n.cache.PutList(owner, cache.CreateObjectsListCacheKey(p.Bucket.CID, p.Prefix, "", true), nodeVersions)
+	}
+
+	if !exact && p.Delimiter != "" {
+		nodeVersions = collapseDirectories(nodeVersions, p.Prefix, p.Delimiter)
 		n.cache.PutList(owner, cacheKey, nodeVersions)
 	}
 
@@ -496,26 +667,29 @@ func (n *layer) getLatestObjectsVersions(ctx context.Context, p allObjectParams)
 		return nil, nil, nil
 	}
 
-	sort.Slice(nodeVersions, func(i, j int) bool {
-		return nodeVersions[i].FilePath < nodeVersions[j].FilePath
-	})
-
-	poolCtx, cancel := context.WithCancel(ctx)
+	headCtx, headSpan := tracing.StartSpan(ctx, "layer.HeadObjects")
+	poolCtx, cancel := context.WithCancel(headCtx)
 	defer cancel()
-	objOutCh, err := n.initWorkerPool(poolCtx, 2, p, nodesGenerator(poolCtx, p, nodeVersions))
+	objOutCh, err := n.initWorkerPool(poolCtx, n.listWorkersNumber(), p, nodesGenerator(poolCtx, p, nodeVersions))
 	if err != nil {
+		tracing.EndSpan(headSpan, err)
 		return nil, nil, fmt.Errorf("failed to init worker pool: %w", err)
 	}
 
-	objects = make([]*data.ObjectInfo, 0, p.MaxKeys)
-
+	// nodes are generated in key order, so objects are placed by the index of their node
+	ordered := make([]*data.ObjectInfo, p.MaxKeys+1)
 	for obj := range objOutCh {
-		objects = append(objects, obj)
+		ordered[obj.index] = obj.info
 	}
 
-	sort.Slice(objects, func(i, j int) bool {
-		return objects[i].Name < objects[j].Name
-	})
+	objects = make([]*data.ObjectInfo, 0, p.MaxKeys+1)
+	for _, obj := range ordered {
+		if obj != nil {
+			objects = append(objects, obj)
+		}
+	}
+	headSpan.SetAttributes(attribute.Int("objects", len(objects)))
+	headSpan.End()
 
 	if len(objects) > p.MaxKeys {
 		next = objects[p.MaxKeys]
@@ -525,14 +699,19 @@ func (n *layer) getLatestObjectsVersions(ctx context.Context, p allObjectParams)
 	return
 }
 
+// nodesGenerator sends the nodes to be listed in the order of their names. Only the nodes after
+// the marker are ordered and lazily, so a page of a large bucket doesn't require sorting all its nodes.
 func nodesGenerator(ctx context.Context, p allObjectParams, nodeVersions []*data.NodeVersion) <-chan *data.NodeVersion {
 	nodeCh := make(chan *data.NodeVersion)
-	existed := make(map[string]struct{}, len(nodeVersions)) // to squash the same directories
+	existed := make(map[string]struct{}, p.MaxKeys+1) // to squash the same directories
+	nodes := nodeVersionsHeap(nodesAfterMarker(nodeVersions, p))
+	heap.Init(&nodes)
 
 	go func() {
 		var generated int
 	LOOP:
-		for _, node := range nodeVersions {
+		for nodes.Len() > 0 {
+			node := heap.Pop(&nodes).(*data.NodeVersion)
 			if shouldSkip(node, p, existed) {
 				continue
 			}
@@ -553,15 +732,103 @@ func nodesGenerator(ctx context.Context, p allObjectParams, nodeVersions []*data
 	return nodeCh
 }
 
-func (n *layer) initWorkerPool(ctx context.Context, size int, p allObjectParams, input <-chan *data.NodeVersion) (<-chan *data.ObjectInfo, error) {
+// nodesAfterMarker returns the nodes which names are after the marker. If the continuation
+// token is set, the nodes starting from the name of the token node are returned.
+func nodesAfterMarker(nodeVersions []*data.NodeVersion, p allObjectParams) []*data.NodeVersion {
+	marker, inclusive := p.Marker, false
+	if p.ContinuationToken != "" {
+		for _, node := range nodeVersions {
+			if node.OID.EncodeToString() == p.ContinuationToken {
+				if name := listedName(node, p); name > marker {
+					marker, inclusive = name, true
+				}
+				break
+			}
+		}
+	}
+
+	res := make([]*data.NodeVersion, 0, len(nodeVersions))
+	for _, node := range nodeVersions {
+		if node.IsDeleteMarker() {
+			continue
+		}
+		if name := listedName(node, p); name < marker || name == marker && !inclusive {
+			continue
+		}
+		res = append(res, node)
+	}
+
+	return res
+}
+
+// collapseDirectories drops delete markers and collapses nodes of the same directory into
+// the node with the least file path, so objects under common prefixes are neither ordered nor headed.
+func collapseDirectories(nodeVersions []*data.NodeVersion, prefix, delimiter string) []*data.NodeVersion {
+	res := make([]*data.NodeVersion, 0, len(nodeVersions))
+	dirs := make(map[string]int)
+	for _, node := range nodeVersions {
+		if node.IsDeleteMarker() {
+			continue
+		}
+		if dirName := tryDirectoryName(node, prefix, delimiter); len(dirName) != 0 {
+			if i, ok := dirs[dirName]; ok {
+				if node.FilePath < res[i].FilePath {
+					res[i] = node
+				}
+				continue
+			}
+			dirs[dirName] = len(res)
+		}
+		res = append(res, node)
+	}
+
+	return res
+}
+
+// listedName returns the name of the node in the listing: the directory name or the file path.
+func listedName(node *data.NodeVersion, p allObjectParams) string {
+	if dirName := tryDirectoryName(node, p.Prefix, p.Delimiter); len(dirName) != 0 {
+		return dirName
+	}
+	return node.FilePath
+}
+
+// nodeVersionsHeap is a min-heap of nodes by file path.
+type nodeVersionsHeap []*data.NodeVersion
+
+func (h nodeVersionsHeap) Len() int           { return len(h) }
+func (h nodeVersionsHeap) Less(i, j int) bool { return h[i].FilePath < h[j].FilePath }
+func (h nodeVersionsHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *nodeVersionsHeap) Push(x interface{}) {
+	*h = append(*h, x.(*data.NodeVersion))
+}
+
+func (h *nodeVersionsHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return x
+}
+
+// listedObject is an object info with the index of its node in the listing.
+type listedObject struct {
+	index int
+	info  *data.ObjectInfo
+}
+
+func (n *layer) initWorkerPool(ctx context.Context, size int, p allObjectParams, input <-chan *data.NodeVersion) (<-chan listedObject, error) {
 	pool, err := ants.NewPool(size, ants.WithLogger(&logWrapper{n.log}))
 	if err != nil {
 		return nil, fmt.Errorf("coudln't init go pool for listing: %w", err)
 	}
-	objCh := make(chan *data.ObjectInfo)
+	objCh := make(chan listedObject)
 
 	go func() {
 		var wg sync.WaitGroup
+		var index int
 
 	LOOP:
 		for node := range input {
@@ -571,9 +838,20 @@ func (n *layer) initWorkerPool(ctx context.Context, size int, p allObjectParams,
 			default:
 			}
 
+			// common prefixes don't need heads, so they don't wait for the workers
+			if oi := tryDirectory(p.Bucket, node, p.Prefix, p.Delimiter); oi != nil {
+				select {
+				case <-ctx.Done():
+					break LOOP
+				case objCh <- listedObject{index: index, info: oi}:
+				}
+				index++
+				continue
+			}
+
 			// We have to make a copy of pointer to data.NodeVersion
 			// to get correct value in submitted task function.
-			func(node *data.NodeVersion) {
+			func(node *data.NodeVersion, index int) {
 				wg.Add(1)
 				err = pool.Submit(func() {
 					defer wg.Done()
@@ -587,14 +865,15 @@ func (n *layer) initWorkerPool(ctx context.Context, size int, p allObjectParams,
 					}
 					select {
 					case <-ctx.Done():
-					case objCh <- oi:
+					case objCh <- listedObject{index: index, info: oi}:
 					}
 				})
 				if err != nil {
 					wg.Done()
 					n.log.Warn("failed to submit task to pool", zap.Error(err))
 				}
-			}(node)
+			}(node, index)
+			index++
 		}
 		wg.Wait()
 		close(objCh)
@@ -616,12 +895,27 @@ func getPartialObjectInfo(bktInfo *data.BucketInfo, node *data.NodeVersion) *dat
 	}
 }
 
+// objectInfoFromNodeVersion forms data.ObjectInfo using object meta stored in data.NodeVersion.
+func objectInfoFromNodeVersion(bktInfo *data.BucketInfo, node *data.NodeVersion) *data.ObjectInfo {
+	oi := getPartialObjectInfo(bktInfo, node)
+	oi.Created = node.ObjectMeta.Created
+	oi.Owner = node.ObjectMeta.Owner
+	if len(node.ObjectMeta.StorageClass) > 0 {
+		oi.Headers = map[string]string{AttributeStorageClass: node.ObjectMeta.StorageClass}
+	}
+	return oi
+}
+
 func (n *layer) bucketNodeVersions(ctx context.Context, bkt *data.BucketInfo, prefix string) ([]*data.NodeVersion, error) {
 	var err error
 
+	ctx, span := tracing.StartSpan(ctx, "layer.ListAllVersions", attribute.String("prefix", prefix))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	owner := n.Owner(ctx)
-	cacheKey := cache.CreateObjectsListCacheKey(bkt.CID, prefix, false)
-	nodeVersions := n.cache.GetList(owner, cacheKey)
+	cacheKey := cache.CreateObjectsListCacheKey(bkt.CID, prefix, "", false)
+	nodeVersions, _ := n.cache.GetListOfPrefix(owner, cacheKey)
+	span.SetAttributes(attribute.Bool("cache_hit", nodeVersions != nil))
 
 	if nodeVersions == nil {
 		nodeVersions, err = n.treeService.GetAllVersionsByPrefix(ctx, bkt, prefix)
@@ -641,19 +935,33 @@ func (n *layer) getAllObjectsVersions(ctx context.Context, bkt *data.BucketInfo,
 		return nil, err
 	}
 
+	// objects are headed concurrently, versions are grouped afterwards to keep their order
+	objectNodes := make([]*data.NodeVersion, 0, len(nodeVersions))
+	for _, nodeVersion := range nodeVersions {
+		if !nodeVersion.IsDeleteMarker() && tryDirectoryName(nodeVersion, prefix, delimiter) == "" {
+			objectNodes = append(objectNodes, nodeVersion)
+		}
+	}
+	objectInfos := n.objectsInfo(ctx, bkt, objectNodes, prefix, delimiter)
+
 	versions := make(map[string][]*data.ExtendedObjectInfo, len(nodeVersions))
 
+	var objectIndex int
 	for _, nodeVersion := range nodeVersions {
 		oi := &data.ObjectInfo{}
 
-		if nodeVersion.IsDeleteMarker() { // delete marker does not match any object in NeoFS
+		if oiDir := tryDirectory(bkt, nodeVersion, prefix, delimiter); oiDir != nil {
+			oi = oiDir
+		} else if nodeVersion.IsDeleteMarker() { // delete marker does not match any object in NeoFS
 			oi.ID = nodeVersion.OID
 			oi.Name = nodeVersion.FilePath
 			oi.Owner = nodeVersion.DeleteMarker.Owner
 			oi.Created = nodeVersion.DeleteMarker.Created
 			oi.IsDeleteMarker = true
 		} else {
-			if oi = n.objectInfoFromObjectsCacheOrNeoFS(ctx, bkt, nodeVersion, prefix, delimiter); oi == nil {
+			oi = objectInfos[objectIndex]
+			objectIndex++
+			if oi == nil {
 				continue
 			}
 		}
@@ -675,6 +983,45 @@ func (n *layer) getAllObjectsVersions(ctx context.Context, bkt *data.BucketInfo,
 	return versions, nil
 }
 
+// objectsInfo returns info of the objects of the nodes in the same order. Objects are headed
+// concurrently by the list workers, info of objects failed to be headed is nil.
+func (n *layer) objectsInfo(ctx context.Context, bkt *data.BucketInfo, nodes []*data.NodeVersion, prefix, delimiter string) []*data.ObjectInfo {
+	res := make([]*data.ObjectInfo, len(nodes))
+
+	pool, err := ants.NewPool(n.listWorkersNumber(), ants.WithLogger(&logWrapper{n.log}))
+	if err != nil {
+		n.log.Warn("couldn't init go pool for listing, head objects sequentially", zap.Error(err))
+		for i, node := range nodes {
+			res[i] = n.objectInfoFromObjectsCacheOrNeoFS(ctx, bkt, node, prefix, delimiter)
+		}
+		return res
+	}
+	defer pool.Release()
+
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		i, node := i, node
+		wg.Add(1)
+		if err = pool.Submit(func() {
+			defer wg.Done()
+			res[i] = n.objectInfoFromObjectsCacheOrNeoFS(ctx, bkt, node, prefix, delimiter)
+		}); err != nil {
+			wg.Done()
+			n.log.Warn("failed to submit task to pool", zap.Error(err))
+		}
+	}
+	wg.Wait()
+
+	return res
+}
+
+func (n *layer) listWorkersNumber() int {
+	if n.listWorkers <= 0 {
+		return 1
+	}
+	return n.listWorkers
+}
+
 func IsSystemHeader(key string) bool {
 	_, ok := api.SystemMetadata[key]
 	return ok || strings.HasPrefix(key, api.NeoFSSystemMetadataPrefix)
@@ -685,6 +1032,11 @@ func shouldSkip(node *data.NodeVersion, p allObjectParams, existed map[string]st
 		return true
 	}
 
+	// the directory marker of the listed prefix is the directory itself, its content is listed instead
+	if p.Delimiter != "" && node.FilePath == p.Prefix && node.IsDirectoryMarker() {
+		return true
+	}
+
 	filePath := node.FilePath
 	if dirName := tryDirectoryName(node, p.Prefix, p.Delimiter); len(dirName) != 0 {
 		filePath = dirName
@@ -744,13 +1096,19 @@ func (n *layer) objectInfoFromObjectsCacheOrNeoFS(ctx context.Context, bktInfo *
 		return extInfo.ObjectInfo
 	}
 
+	if node.ObjectMeta != nil {
+		// the tree node contains everything listings need, so the object isn't headed
+		// and the partial info isn't cached to not be returned by HeadObject
+		return objectInfoFromNodeVersion(bktInfo, node)
+	}
+
 	meta, err := n.objectHead(ctx, bktInfo, node.OID)
 	if err != nil {
 		n.log.Warn("could not fetch object meta", zap.Error(err))
 		return nil
 	}
 
-	oi = objectInfoFromMeta(bktInfo, meta)
+	oi = objectInfoFromMetaAndNode(bktInfo, meta, node)
 	n.cache.PutObject(owner, &data.ExtendedObjectInfo{ObjectInfo: oi, NodeVersion: node})
 
 	return oi
@@ -783,20 +1141,23 @@ func tryDirectoryName(node *data.NodeVersion, prefix, delimiter string) string {
 	tail := strings.TrimPrefix(node.FilePath, prefix)
 	index := strings.Index(tail, delimiter)
 	if index >= 0 {
-		return prefix + tail[:index+1]
+		return prefix + tail[:index+len(delimiter)]
 	}
 
 	return ""
 }
 
-func wrapReader(input io.Reader, bufSize int, f func(buf []byte)) io.Reader {
+func wrapReader(input io.Reader, buffers *bufferPool, f func(buf []byte)) io.Reader {
 	if input == nil {
 		return nil
 	}
 
 	r, w := io.Pipe()
 	go func() {
-		var buf = make([]byte, bufSize)
+		pooled := buffers.Get()
+		defer buffers.Put(pooled)
+
+		buf := *pooled
 		for {
 			n, err := input.Read(buf)
 			if n > 0 {
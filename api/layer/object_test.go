@@ -2,12 +2,23 @@ package layer
 
 import (
 	"bytes"
+	"context"
+	"crypto/md5"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestWrapReader(t *testing.T) {
@@ -18,7 +29,7 @@ func TestWrapReader(t *testing.T) {
 
 	streamHash := sha256.New()
 	reader := bytes.NewReader(src)
-	wrappedReader := wrapReader(reader, 64*1024, func(buf []byte) {
+	wrappedReader := wrapReader(reader, newBufferPool(64*1024), func(buf []byte) {
 		streamHash.Write(buf)
 	})
 
@@ -27,3 +38,168 @@ func TestWrapReader(t *testing.T) {
 	require.Equal(t, src, dst)
 	require.Equal(t, h[:], streamHash.Sum(nil))
 }
+
+func TestObjectLocks(t *testing.T) {
+	locks := newObjectLocks()
+	cnrID := cidtest.ID()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		holders int
+		counter int
+	)
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.Lock(cnrID, "obj")
+			defer unlock()
+
+			mu.Lock()
+			holders++
+			require.Equal(t, 1, holders)
+			mu.Unlock()
+
+			counter++
+
+			mu.Lock()
+			holders--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 50, counter)
+	require.Empty(t, locks.locks)
+
+	unlock := locks.Lock(cnrID, "obj")
+	require.Len(t, locks.locks, 1)
+	unlock()
+	require.Empty(t, locks.locks)
+}
+
+func TestCopyObject(t *testing.T) {
+	tc := prepareContext(t)
+
+	content := make([]byte, 1024*1024+1)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+	srcInfo := tc.putObject(content)
+
+	_, err = tc.layer.CopyObject(tc.ctx, &CopyObjectParams{
+		SrcObject:  srcInfo,
+		ScrBktInfo: tc.bktInfo,
+		DstBktInfo: tc.bktInfo,
+		DstObject:  "obj-copy",
+		SrcSize:    srcInfo.Size,
+		Header:     make(map[string]string),
+	})
+	require.NoError(t, err)
+
+	_, copied := tc.getObject("obj-copy", "", false)
+	require.Equal(t, content, copied)
+}
+
+func TestListingFromTreeNodes(t *testing.T) {
+	cachesConfig := DefaultCachesConfigs(zap.NewExample())
+	cachesConfig.Objects.Disabled = true
+	tc := prepareContext(t, cachesConfig)
+
+	objInfo := tc.putObject([]byte("content"))
+
+	// the object can't be headed anymore, so listings use the info stored in the tree
+	err := tc.testNeoFS.DeleteObject(tc.ctx, PrmObjectDelete{Container: tc.bktInfo.CID, Object: objInfo.ID})
+	require.NoError(t, err)
+
+	objects := tc.listObjectsV2()
+	require.Len(t, objects, 1)
+	require.Equal(t, objInfo.ID, objects[0].ID)
+	require.Equal(t, objInfo.Owner, objects[0].Owner)
+	require.Equal(t, objInfo.Created.Unix(), objects[0].Created.Unix())
+	require.Equal(t, objInfo.HashSum, objects[0].HashSum)
+
+	versions := tc.listVersions()
+	require.Len(t, versions.Version, 1)
+	require.Equal(t, objInfo.Owner, versions.Version[0].ObjectInfo.Owner)
+}
+
+func TestNodesGenerator(t *testing.T) {
+	nodes := make([]*data.NodeVersion, 0, 6)
+	for _, name := range []string{"e", "b", "dir/b", "a", "dir/a", "c"} {
+		nodes = append(nodes, &data.NodeVersion{BaseNodeVersion: data.BaseNodeVersion{OID: oidtest.ID(), FilePath: name}})
+	}
+
+	generate := func(p allObjectParams) []string {
+		var res []string
+		for node := range nodesGenerator(context.Background(), p, nodes) {
+			res = append(res, listedName(node, p))
+		}
+		return res
+	}
+
+	t.Run("key order", func(t *testing.T) {
+		require.Equal(t, []string{"a", "b", "c", "dir/"}, generate(allObjectParams{MaxKeys: 3, Delimiter: "/"}))
+	})
+
+	t.Run("marker", func(t *testing.T) {
+		require.Equal(t, []string{"c", "dir/", "e"}, generate(allObjectParams{MaxKeys: 5, Delimiter: "/", Marker: "b"}))
+	})
+
+	t.Run("collapsed directories", func(t *testing.T) {
+		res := collapseDirectories(nodes, "", "/")
+		require.Len(t, res, 5)
+		require.Contains(t, res, nodes[4]) // dir/a
+		require.NotContains(t, res, nodes[2])
+	})
+
+	t.Run("continuation token", func(t *testing.T) {
+		p := allObjectParams{MaxKeys: 5, ContinuationToken: nodes[4].OID.EncodeToString()}
+		require.Equal(t, []string{"dir/a", "dir/b", "e"}, generate(p))
+	})
+}
+
+func TestMD5ETag(t *testing.T) {
+	cachesConfig := DefaultCachesConfigs(zap.NewExample())
+	cachesConfig.Objects.Disabled = true
+	tc := prepareContext(t, cachesConfig)
+	tc.layer.(*layer).md5ETag = true
+
+	content := []byte("content")
+	md5Sum := md5.Sum(content)
+
+	objInfo := tc.putObject(content)
+	require.Equal(t, hex.EncodeToString(md5Sum[:]), objInfo.HashSum)
+
+	// the header contains SHA256 payload checksum, ETag is taken from the tree
+	headInfo, payload := tc.getObject(tc.obj, "", false)
+	require.Equal(t, content, payload)
+	require.Equal(t, objInfo.HashSum, headInfo.HashSum)
+}
+
+func TestCreationTimeFromAttribute(t *testing.T) {
+	cachesConfig := DefaultCachesConfigs(zap.NewExample())
+	cachesConfig.Objects.Disabled = true
+	tc := prepareContext(t, cachesConfig)
+
+	objInfo := tc.putObject([]byte("content"))
+	require.Equal(t, objInfo.Created.Truncate(time.Millisecond), objInfo.Created)
+
+	// the object is headed since the objects cache is disabled
+	headed, _ := tc.getObject(tc.obj, "", false)
+	require.True(t, objInfo.Created.Equal(headed.Created))
+
+	objects := tc.listObjectsV2()
+	require.Len(t, objects, 1)
+	require.True(t, objInfo.Created.Equal(objects[0].Created))
+
+	// objects put by older gateways have creation time in seconds only
+	attr := object.NewAttribute()
+	attr.SetKey(object.AttributeTimestamp)
+	attr.SetValue(strconv.FormatInt(objInfo.Created.Unix(), 10))
+	obj := object.New()
+	obj.SetAttributes(*attr)
+	obj.SetOwnerID(&objInfo.Owner)
+	require.Equal(t, time.Unix(objInfo.Created.Unix(), 0), objectInfoFromMeta(tc.bktInfo, obj).Created)
+}
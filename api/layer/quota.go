@@ -0,0 +1,157 @@
+package layer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+)
+
+// bucketUsageLifetime limits how long usage counted by the gateway is trusted.
+// It's recounted after that to take into account changes made by other gateways.
+const bucketUsageLifetime = time.Minute
+
+// BucketUsage holds current usage of the bucket.
+type BucketUsage struct {
+	Size    uint64
+	Objects uint64
+}
+
+// usageDelta is a change of the bucket usage made by a write.
+type usageDelta struct {
+	size    int64
+	objects int64
+}
+
+// bucketUsages keeps usage of the buckets with quota between writes, so
+// the version tree isn't walked on each of them.
+type bucketUsages struct {
+	mu      sync.Mutex
+	entries map[cid.ID]*bucketUsageEntry
+}
+
+type bucketUsageEntry struct {
+	usage   BucketUsage
+	expires time.Time
+}
+
+func newBucketUsages() *bucketUsages {
+	return &bucketUsages{entries: make(map[cid.ID]*bucketUsageEntry)}
+}
+
+func (u *bucketUsages) get(cnrID cid.ID) (BucketUsage, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	entry, ok := u.entries[cnrID]
+	if !ok {
+		return BucketUsage{}, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(u.entries, cnrID)
+		return BucketUsage{}, false
+	}
+	return entry.usage, true
+}
+
+func (u *bucketUsages) put(cnrID cid.ID, usage BucketUsage) {
+	u.mu.Lock()
+	u.entries[cnrID] = &bucketUsageEntry{usage: usage, expires: time.Now().Add(bucketUsageLifetime)}
+	u.mu.Unlock()
+}
+
+// add applies the delta to the remembered usage of the bucket, if any.
+func (u *bucketUsages) add(cnrID cid.ID, delta usageDelta) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	entry, ok := u.entries[cnrID]
+	if !ok {
+		return
+	}
+	entry.usage.Size = addDelta(entry.usage.Size, delta.size)
+	entry.usage.Objects = addDelta(entry.usage.Objects, delta.objects)
+}
+
+// invalidate forgets usage of the bucket, it's recounted on the next write.
+func (u *bucketUsages) invalidate(cnrID cid.ID) {
+	u.mu.Lock()
+	delete(u.entries, cnrID)
+	u.mu.Unlock()
+}
+
+func addDelta(val uint64, delta int64) uint64 {
+	if delta < 0 && uint64(-delta) > val {
+		return 0
+	}
+	return uint64(int64(val) + delta)
+}
+
+// GetBucketUsage returns the total size and the number of all object versions
+// stored in the bucket. Delete markers aren't taken into account.
+func (n *layer) GetBucketUsage(ctx context.Context, bktInfo *data.BucketInfo) (*BucketUsage, error) {
+	versions, err := n.treeService.GetAllVersionsByPrefix(ctx, bktInfo, "")
+	if err != nil {
+		return nil, fmt.Errorf("get all versions: %w", err)
+	}
+
+	var usage BucketUsage
+	for _, version := range versions {
+		if version.IsDeleteMarker() {
+			continue
+		}
+		usage.Size += uint64(version.Size)
+		usage.Objects++
+	}
+
+	return &usage, nil
+}
+
+// bucketUsage returns usage of the bucket remembered by the gateway or counts it.
+func (n *layer) bucketUsage(ctx context.Context, bktInfo *data.BucketInfo) (BucketUsage, error) {
+	if usage, ok := n.usages.get(bktInfo.CID); ok {
+		return usage, nil
+	}
+
+	usage, err := n.GetBucketUsage(ctx, bktInfo)
+	if err != nil {
+		return BucketUsage{}, err
+	}
+	n.usages.put(bktInfo.CID, *usage)
+
+	return *usage, nil
+}
+
+// checkQuota returns ErrQuotaExceeded if storing the new version exceeds the bucket quota.
+// The version replaced by the new unversioned one isn't counted. The returned delta must be
+// applied with n.usages.add once the version is stored, it's nil for buckets without quota.
+func (n *layer) checkQuota(ctx context.Context, bktInfo *data.BucketInfo, newVersion, replaced *data.NodeVersion) (*usageDelta, error) {
+	if bktInfo.Quota.Size == 0 && bktInfo.Quota.Objects == 0 {
+		return nil, nil
+	}
+
+	delta := &usageDelta{size: newVersion.Size, objects: 1}
+	if replaced != nil && !replaced.IsDeleteMarker() {
+		delta.size -= replaced.Size
+		delta.objects--
+	}
+
+	usage, err := n.bucketUsage(ctx, bktInfo)
+	if err != nil {
+		return nil, fmt.Errorf("get bucket usage: %w", err)
+	}
+
+	if bktInfo.Quota.Size > 0 && delta.size > 0 && addDelta(usage.Size, delta.size) > bktInfo.Quota.Size {
+		return nil, errors.GetAPIError(errors.ErrQuotaExceeded)
+	}
+
+	if bktInfo.Quota.Objects > 0 && delta.objects > 0 && addDelta(usage.Objects, delta.objects) > bktInfo.Quota.Objects {
+		return nil, errors.GetAPIError(errors.ErrQuotaExceeded)
+	}
+
+	return delta, nil
+}
@@ -0,0 +1,130 @@
+package layer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// ParallelGetConfig contains parameters of fetching payload of large objects
+// by ranges concurrently.
+type ParallelGetConfig struct {
+	// Threshold is the minimum length of the payload to be fetched by ranges, 0 disables the feature.
+	Threshold uint64
+	// PartSize is the length of a single range.
+	PartSize uint64
+	// Workers is the number of ranges fetched concurrently.
+	Workers int
+}
+
+// enabled checks if the payload of the given length is fetched by ranges.
+func (c ParallelGetConfig) enabled(ln uint64) bool {
+	return c.Threshold != 0 && c.PartSize != 0 && c.Workers > 1 && ln >= c.Threshold
+}
+
+// rangePart is a fetched range of the payload.
+type rangePart struct {
+	data []byte
+	err  error
+}
+
+// parallelRangeReader implements io.ReadCloser of the object payload fetched by ranges concurrently.
+// Ranges are returned in order, at most the number of workers ranges are kept in memory.
+type parallelRangeReader struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	parts  chan chan rangePart
+	cur    *bytes.Reader
+	err    error
+}
+
+// initParallelPayloadReader initializes reader of the payload range of the object fetched by parts.
+// The range must be set explicitly.
+func (n *layer) initParallelPayloadReader(ctx context.Context, p getParams) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &parallelRangeReader{
+		ctx:    ctx,
+		cancel: cancel,
+		// the part being read is the one more part kept in memory
+		parts: make(chan chan rangePart, n.parallelGet.Workers-1),
+	}
+
+	go func() {
+		defer close(r.parts)
+
+		for off, end := p.off, p.off+p.ln; off < end; off += n.parallelGet.PartSize {
+			ln := n.parallelGet.PartSize
+			if end-off < ln {
+				ln = end - off
+			}
+
+			res := make(chan rangePart, 1)
+			select {
+			case <-ctx.Done():
+				return
+			case r.parts <- res:
+			}
+
+			go func(off, ln uint64) {
+				res <- n.readPayloadRange(ctx, p, off, ln)
+			}(off, ln)
+		}
+	}()
+
+	return r
+}
+
+func (n *layer) readPayloadRange(ctx context.Context, p getParams, off, ln uint64) rangePart {
+	payload, err := n.initObjectPayloadReader(ctx, getParams{
+		oid:     p.oid,
+		bktInfo: p.bktInfo,
+		off:     off,
+		ln:      ln,
+	})
+	if err != nil {
+		return rangePart{err: fmt.Errorf("init payload reader of range %d-%d: %w", off, off+ln-1, err)}
+	}
+	if closer, ok := payload.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	data := make([]byte, ln)
+	if _, err = io.ReadFull(payload, data); err != nil {
+		return rangePart{err: fmt.Errorf("read payload range %d-%d: %w", off, off+ln-1, err)}
+	}
+
+	return rangePart{data: data}
+}
+
+func (r *parallelRangeReader) Read(p []byte) (int, error) {
+	for r.err == nil {
+		if r.cur != nil && r.cur.Len() > 0 {
+			return r.cur.Read(p)
+		}
+
+		res, ok := <-r.parts
+		if !ok {
+			// parts aren't sent anymore if the context is done
+			if r.err = r.ctx.Err(); r.err == nil {
+				r.err = io.EOF
+			}
+			break
+		}
+
+		part := <-res
+		if part.err != nil {
+			r.err = part.err
+			break
+		}
+		r.cur = bytes.NewReader(part.data)
+	}
+
+	return 0, r.err
+}
+
+// Close stops fetching of the ranges.
+func (r *parallelRangeReader) Close() error {
+	r.cancel()
+	return nil
+}
@@ -0,0 +1,34 @@
+package layer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelGet(t *testing.T) {
+	tc := prepareContext(t)
+	tc.layer.(*layer).parallelGet = ParallelGetConfig{Threshold: 10, PartSize: 7, Workers: 3}
+
+	content := make([]byte, 100)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+	objInfo := tc.putObject(content)
+
+	_, payload := tc.getObject(tc.obj, "", false)
+	require.Equal(t, content, payload)
+
+	for _, rng := range []*RangeParams{{Start: 5, End: 60}, {Start: 0, End: 6}, {Start: 93, End: 99}} {
+		buf := bytes.NewBuffer(nil)
+		err = tc.layer.GetObject(tc.ctx, &GetObjectParams{
+			ObjectInfo: objInfo,
+			BucketInfo: tc.bktInfo,
+			Writer:     buf,
+			Range:      rng,
+		})
+		require.NoError(t, err)
+		require.Equal(t, content[rng.Start:rng.End+1], buf.Bytes())
+	}
+}
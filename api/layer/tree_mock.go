@@ -10,6 +10,9 @@ import (
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 )
 
+// corsConfigType is a key of the bucket CORS node in the mocked system tree.
+const corsConfigType data.BucketConfigType = "bucket-cors"
+
 type TreeServiceMock struct {
 	settings   map[string]*data.BucketSettings
 	versions   map[string]map[string][]*data.NodeVersion
@@ -18,6 +21,9 @@ type TreeServiceMock struct {
 	tags       map[string]map[uint64]map[string]string
 	multiparts map[string]map[string][]*data.MultipartInfo
 	parts      map[string]map[int]*data.PartInfo
+
+	lastMultipartID uint64
+	lastVersionID   uint64
 }
 
 func (t *TreeServiceMock) GetObjectTaggingAndLock(ctx context.Context, bktInfo *data.BucketInfo, objVersion *data.NodeVersion) (map[string]string, *data.LockInfo, error) {
@@ -109,15 +115,50 @@ func (t *TreeServiceMock) PutNotificationConfigurationNode(ctx context.Context,
 }
 
 func (t *TreeServiceMock) GetBucketCORS(ctx context.Context, bktInfo *data.BucketInfo) (oid.ID, error) {
-	panic("implement me")
+	return t.GetBucketConfig(ctx, bktInfo, corsConfigType)
 }
 
 func (t *TreeServiceMock) PutBucketCORS(ctx context.Context, bktInfo *data.BucketInfo, objID oid.ID) (oid.ID, error) {
-	panic("implement me")
+	return t.PutBucketConfig(ctx, bktInfo, corsConfigType, objID)
 }
 
 func (t *TreeServiceMock) DeleteBucketCORS(ctx context.Context, bktInfo *data.BucketInfo) (oid.ID, error) {
-	panic("implement me")
+	return t.DeleteBucketConfig(ctx, bktInfo, corsConfigType)
+}
+
+func (t *TreeServiceMock) GetBucketConfig(_ context.Context, bktInfo *data.BucketInfo, cfgType data.BucketConfigType) (oid.ID, error) {
+	node, ok := t.system[bktInfo.CID.EncodeToString()][string(cfgType)]
+	if !ok {
+		return oid.ID{}, ErrNodeNotFound
+	}
+
+	return node.OID, nil
+}
+
+func (t *TreeServiceMock) PutBucketConfig(_ context.Context, bktInfo *data.BucketInfo, cfgType data.BucketConfigType, objID oid.ID) (oid.ID, error) {
+	cnrSystemMap, ok := t.system[bktInfo.CID.EncodeToString()]
+	if !ok {
+		cnrSystemMap = make(map[string]*data.BaseNodeVersion)
+		t.system[bktInfo.CID.EncodeToString()] = cnrSystemMap
+	}
+
+	node, ok := cnrSystemMap[string(cfgType)]
+	cnrSystemMap[string(cfgType)] = &data.BaseNodeVersion{OID: objID, FilePath: string(cfgType)}
+	if !ok {
+		return oid.ID{}, ErrNoNodeToRemove
+	}
+
+	return node.OID, nil
+}
+
+func (t *TreeServiceMock) DeleteBucketConfig(_ context.Context, bktInfo *data.BucketInfo, cfgType data.BucketConfigType) (oid.ID, error) {
+	node, ok := t.system[bktInfo.CID.EncodeToString()][string(cfgType)]
+	if !ok {
+		return oid.ID{}, ErrNoNodeToRemove
+	}
+
+	delete(t.system[bktInfo.CID.EncodeToString()], string(cfgType))
+	return node.OID, nil
 }
 
 func (t *TreeServiceMock) GetVersions(_ context.Context, bktInfo *data.BucketInfo, objectName string) ([]*data.NodeVersion, error) {
@@ -202,6 +243,10 @@ func (t *TreeServiceMock) GetUnversioned(_ context.Context, bktInfo *data.Bucket
 }
 
 func (t *TreeServiceMock) AddVersion(_ context.Context, bktInfo *data.BucketInfo, newVersion *data.NodeVersion) (uint64, error) {
+	// node ids are unique within the tree as RemoveVersion looks up nodes by id only
+	t.lastVersionID++
+	newVersion.ID = t.lastVersionID
+
 	cnrVersionsMap, ok := t.versions[bktInfo.CID.EncodeToString()]
 	if !ok {
 		t.versions[bktInfo.CID.EncodeToString()] = map[string][]*data.NodeVersion{
@@ -221,7 +266,6 @@ func (t *TreeServiceMock) AddVersion(_ context.Context, bktInfo *data.BucketInfo
 	})
 
 	if len(versions) != 0 {
-		newVersion.ID = versions[len(versions)-1].ID + 1
 		newVersion.Timestamp = versions[len(versions)-1].Timestamp + 1
 	}
 
@@ -276,6 +320,9 @@ func (t *TreeServiceMock) GetAllVersionsByPrefix(_ context.Context, bktInfo *dat
 }
 
 func (t *TreeServiceMock) CreateMultipartUpload(_ context.Context, bktInfo *data.BucketInfo, info *data.MultipartInfo) error {
+	t.lastMultipartID++
+	info.ID = t.lastMultipartID
+
 	cnrMultipartsMap, ok := t.multiparts[bktInfo.CID.EncodeToString()]
 	if !ok {
 		t.multiparts[bktInfo.CID.EncodeToString()] = map[string][]*data.MultipartInfo{
@@ -284,17 +331,22 @@ func (t *TreeServiceMock) CreateMultipartUpload(_ context.Context, bktInfo *data
 		return nil
 	}
 
-	multiparts := cnrMultipartsMap[info.Key]
-	if len(multiparts) != 0 {
-		info.ID = multiparts[len(multiparts)-1].ID + 1
-	}
-	cnrMultipartsMap[info.Key] = append(multiparts, info)
+	cnrMultipartsMap[info.Key] = append(cnrMultipartsMap[info.Key], info)
 
 	return nil
 }
 
-func (t *TreeServiceMock) GetMultipartUploadsByPrefix(ctx context.Context, bktInfo *data.BucketInfo, prefix string) ([]*data.MultipartInfo, error) {
-	panic("implement me")
+func (t *TreeServiceMock) GetMultipartUploadsByPrefix(_ context.Context, bktInfo *data.BucketInfo, prefix string) ([]*data.MultipartInfo, error) {
+	cnrMultipartsMap := t.multiparts[bktInfo.CID.EncodeToString()]
+
+	var result []*data.MultipartInfo
+	for key, multiparts := range cnrMultipartsMap {
+		if strings.HasPrefix(key, prefix) {
+			result = append(result, multiparts...)
+		}
+	}
+
+	return result, nil
 }
 
 func (t *TreeServiceMock) GetMultipartUpload(_ context.Context, bktInfo *data.BucketInfo, objectName, uploadID string) (*data.MultipartInfo, error) {
@@ -328,10 +380,14 @@ func (t *TreeServiceMock) AddPart(ctx context.Context, bktInfo *data.BucketInfo,
 		partsMap = make(map[int]*data.PartInfo)
 	}
 
+	oldPart, found := partsMap[info.Number]
 	partsMap[info.Number] = info
 
 	t.parts[info.UploadID] = partsMap
-	return oid.ID{}, nil
+	if !found {
+		return oid.ID{}, ErrNoNodeToRemove
+	}
+	return oldPart.OID, nil
 }
 
 func (t *TreeServiceMock) GetParts(_ context.Context, bktInfo *data.BucketInfo, multipartNodeID uint64) ([]*data.PartInfo, error) {
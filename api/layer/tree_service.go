@@ -44,6 +44,24 @@ type TreeService interface {
 	// If object id to remove is not found returns ErrNoNodeToRemove error.
 	DeleteBucketCORS(ctx context.Context, bktInfo *data.BucketInfo) (oid.ID, error)
 
+	// GetBucketConfig gets an object id that corresponds to object with bucket configuration of the type
+	// (lifecycle, website, policy or encryption).
+	//
+	// If object id is not found returns ErrNodeNotFound error.
+	GetBucketConfig(ctx context.Context, bktInfo *data.BucketInfo, cfgType data.BucketConfigType) (oid.ID, error)
+
+	// PutBucketConfig puts a node of bucket configuration of the type to a system tree and returns objectID
+	// of a previous configuration which must be deleted in NeoFS.
+	//
+	// If object id to remove is not found returns ErrNoNodeToRemove error.
+	PutBucketConfig(ctx context.Context, bktInfo *data.BucketInfo, cfgType data.BucketConfigType, objID oid.ID) (oid.ID, error)
+
+	// DeleteBucketConfig removes a node of bucket configuration of the type from a system tree
+	// and returns objID which must be deleted in NeoFS.
+	//
+	// If object id to remove is not found returns ErrNoNodeToRemove error.
+	DeleteBucketConfig(ctx context.Context, bktInfo *data.BucketInfo, cfgType data.BucketConfigType) (oid.ID, error)
+
 	GetObjectTagging(ctx context.Context, bktInfo *data.BucketInfo, objVersion *data.NodeVersion) (map[string]string, error)
 	PutObjectTagging(ctx context.Context, bktInfo *data.BucketInfo, objVersion *data.NodeVersion, tagSet map[string]string) error
 	DeleteObjectTagging(ctx context.Context, bktInfo *data.BucketInfo, objVersion *data.NodeVersion) error
@@ -11,6 +11,7 @@ import (
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
@@ -80,9 +81,28 @@ func objectInfoFromMeta(bkt *data.BucketInfo, meta *object.Object) *data.ObjectI
 		creation = time.Unix(dt, 0)
 		delete(headers, object.AttributeTimestamp)
 	}
+	// precise creation time is preferred, it's absent in objects put by older gateways
+	if val, ok := headers[AttributeCreated]; ok {
+		if created, err := time.Parse(time.RFC3339Nano, val); err == nil {
+			creation = created
+		}
+		delete(headers, AttributeCreated)
+	}
+
+	size := int64(meta.PayloadSize())
+	if val, ok := headers[MultipartObjectSize]; ok {
+		if multipartSize, err := strconv.ParseInt(val, 10, 64); err == nil {
+			size = multipartSize
+		}
+	}
 
 	objID, _ := meta.ID()
 	payloadChecksum, _ := meta.PayloadChecksum()
+	hashSum := hex.EncodeToString(payloadChecksum.Value())
+	if etag, ok := headers[MultipartObjectETag]; ok {
+		hashSum = etag
+	}
+
 	return &data.ObjectInfo{
 		ID:    objID,
 		CID:   bkt.CID,
@@ -94,9 +114,19 @@ func objectInfoFromMeta(bkt *data.BucketInfo, meta *object.Object) *data.ObjectI
 		ContentType: mimeType,
 		Headers:     headers,
 		Owner:       *meta.OwnerID(),
-		Size:        int64(meta.PayloadSize()),
-		HashSum:     hex.EncodeToString(payloadChecksum.Value()),
+		Size:        size,
+		HashSum:     hashSum,
+	}
+}
+
+// objectInfoFromMetaAndNode forms object info from the object header and its tree node.
+// ETag is taken from the node since it's MD5 of the payload for objects put with MD5 ETags.
+func objectInfoFromMetaAndNode(bkt *data.BucketInfo, meta *object.Object, node *data.NodeVersion) *data.ObjectInfo {
+	objInfo := objectInfoFromMeta(bkt, meta)
+	if node.ETag != "" {
+		objInfo.HashSum = node.ETag
 	}
+	return objInfo
 }
 
 func FormEncryptionInfo(headers map[string]string) encryption.ObjectEncryption {
@@ -142,7 +172,8 @@ func GetBoxData(ctx context.Context) (*accessbox.Box, error) {
 	var boxData *accessbox.Box
 	data, ok := ctx.Value(api.BoxData).(*accessbox.Box)
 	if !ok || data == nil {
-		return nil, fmt.Errorf("couldn't get box data from context")
+		// requests without box are anonymous, so they can't use tokens of any user
+		return nil, fmt.Errorf("%w: couldn't get box data from context", errors.GetAPIError(errors.ErrAccessDenied))
 	}
 
 	boxData = data
@@ -10,9 +10,13 @@ import (
 func (n *layer) ListObjectVersions(ctx context.Context, p *ListObjectVersionsParams) (*ListObjectVersionsInfo, error) {
 	var (
 		allObjects = make([]*data.ExtendedObjectInfo, 0, p.MaxKeys)
-		res        = &ListObjectVersionsInfo{}
+		res        = &ListObjectVersionsInfo{KeyMarker: p.KeyMarker, VersionIDMarker: p.VersionIDMarker}
 	)
 
+	if p.MaxKeys == 0 {
+		return res, nil
+	}
+
 	versions, err := n.getAllObjectsVersions(ctx, p.BktInfo, p.Prefix, p.Delimiter)
 	if err != nil {
 		return nil, err
@@ -27,7 +31,7 @@ func (n *layer) ListObjectVersions(ctx context.Context, p *ListObjectVersionsPar
 	for _, name := range sortedNames {
 		sortedVersions := versions[name]
 		sort.Slice(sortedVersions, func(i, j int) bool {
-			return sortedVersions[j].NodeVersion.Timestamp < sortedVersions[i].NodeVersion.Timestamp // sort in reverse order
+			return sortedVersions[i].NodeVersion.IsNewerThan(sortedVersions[j].NodeVersion.BaseNodeVersion) // sort in reverse order
 		})
 
 		for i, version := range sortedVersions {
@@ -36,29 +40,47 @@ func (n *layer) ListObjectVersions(ctx context.Context, p *ListObjectVersionsPar
 		}
 	}
 
-	for i, obj := range allObjects {
-		if obj.ObjectInfo.Name >= p.KeyMarker && obj.ObjectInfo.VersionID() >= p.VersionIDMarker {
-			allObjects = allObjects[i:]
-			break
-		}
-	}
-
-	res.CommonPrefixes, allObjects = triageExtendedObjects(allObjects)
+	allObjects = filterVersionsByMarker(allObjects, p)
 
+	// common prefixes are counted as keys, so truncate the list before triage
 	if len(allObjects) > p.MaxKeys {
 		res.IsTruncated = true
-		res.NextKeyMarker = allObjects[p.MaxKeys].ObjectInfo.Name
-		res.NextVersionIDMarker = allObjects[p.MaxKeys].ObjectInfo.VersionID()
-
 		allObjects = allObjects[:p.MaxKeys]
-		res.KeyMarker = allObjects[p.MaxKeys-1].ObjectInfo.Name
-		res.VersionIDMarker = allObjects[p.MaxKeys-1].ObjectInfo.VersionID()
+
+		last := allObjects[p.MaxKeys-1]
+		res.NextKeyMarker = last.ObjectInfo.Name
+		if !last.ObjectInfo.IsDir {
+			res.NextVersionIDMarker = last.Version()
+		}
 	}
 
+	res.CommonPrefixes, allObjects = triageExtendedObjects(allObjects)
 	res.Version, res.DeleteMarker = triageVersions(allObjects)
 	return res, nil
 }
 
+// filterVersionsByMarker returns versions following the key marker and the version id marker.
+// Without version id marker, listing starts from the key following the key marker.
+func filterVersionsByMarker(objects []*data.ExtendedObjectInfo, p *ListObjectVersionsParams) []*data.ExtendedObjectInfo {
+	if len(p.KeyMarker) == 0 {
+		return objects
+	}
+
+	for i, obj := range objects {
+		if obj.ObjectInfo.Name == p.KeyMarker && len(p.VersionIDMarker) != 0 {
+			if obj.Version() == p.VersionIDMarker {
+				return objects[i+1:]
+			}
+			continue
+		}
+		if obj.ObjectInfo.Name > p.KeyMarker {
+			return objects[i:]
+		}
+	}
+
+	return nil
+}
+
 func triageVersions(objVersions []*data.ExtendedObjectInfo) ([]*data.ExtendedObjectInfo, []*data.ExtendedObjectInfo) {
 	if len(objVersions) == 0 {
 		return nil, nil
@@ -3,11 +3,13 @@ package metrics
 import (
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -61,8 +63,44 @@ var (
 		},
 		[]string{"api"},
 	)
+	httpRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_http_requests_total",
+			Help: "Total number of requests served by current NeoFS S3 Gate instance by response status code",
+		},
+		[]string{"api", "code"},
+	)
+	bucketRequestsDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "neofs_s3_bucket_request_seconds",
+			Help:    "Time taken by requests to buckets served by current NeoFS S3 Gate instance",
+			Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"api", "bucket"},
+	)
+	bucketRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_bucket_requests_total",
+			Help: "Total number of requests to buckets served by current NeoFS S3 Gate instance by response status code",
+		},
+		[]string{"api", "bucket", "code"},
+	)
+
+	// bucketMetricsEnabled is set to 1 if metrics labeled by bucket are collected.
+	bucketMetricsEnabled uint32
 )
 
+// SetBucketMetricsEnabled turns on or off metrics labeled by bucket. Only successful requests are labeled
+// by their bucket, so the number of series is limited by the existing buckets. Failed requests, including
+// requests to missing buckets, are counted with empty bucket label.
+func SetBucketMetricsEnabled(enabled bool) {
+	var val uint32
+	if enabled {
+		val = 1
+	}
+	atomic.StoreUint32(&bucketMetricsEnabled, val)
+}
+
 // Collects HTTP metrics for NeoFS S3 Gate in Prometheus specific format
 // and sends to the given channel.
 func collectHTTPMetrics(ch chan<- prometheus.Metric) {
@@ -199,6 +237,21 @@ func (st *HTTPStats) updateStats(api string, w http.ResponseWriter, r *http.Requ
 		// Increment the prometheus http request response histogram with appropriate label
 		httpRequestsDuration.With(prometheus.Labels{"api": api}).Observe(durationSecs)
 	}
+
+	if code == 0 {
+		// handler wrote the body without explicit header
+		code = http.StatusOK
+	}
+	httpRequestsTotal.WithLabelValues(api, strconv.Itoa(code)).Inc()
+
+	if atomic.LoadUint32(&bucketMetricsEnabled) == 1 {
+		var bucket string
+		if code < http.StatusBadRequest {
+			bucket = mux.Vars(r)["bucket"]
+		}
+		bucketRequestsTotal.WithLabelValues(api, bucket, strconv.Itoa(code)).Inc()
+		bucketRequestsDuration.WithLabelValues(api, bucket).Observe(durationSecs)
+	}
 }
 
 // WriteHeader -- writes http status code.
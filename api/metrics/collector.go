@@ -31,6 +31,13 @@ func init() {
 	prometheus.MustRegister(versionInfo)
 	prometheus.MustRegister(statsMetrics)
 	prometheus.MustRegister(httpRequestsDuration)
+	prometheus.MustRegister(httpRequestsTotal)
+	prometheus.MustRegister(bucketRequestsDuration)
+	prometheus.MustRegister(bucketRequestsTotal)
+	prometheus.MustRegister(usageRequests)
+	prometheus.MustRegister(usageBytesIn)
+	prometheus.MustRegister(usageBytesOut)
+	prometheus.MustRegister(slowRequestsTotal)
 }
 
 func collectNetworkMetrics(ch chan<- prometheus.Metric) {
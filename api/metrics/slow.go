@@ -0,0 +1,18 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var slowRequestsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "neofs_s3_slow_requests_total",
+		Help: "Total number of requests which took longer than the slow request threshold",
+	},
+	[]string{"api", "bucket"},
+)
+
+// IncSlowRequests accounts a slow request of the API to the bucket.
+func IncSlowRequests(api, bucket string) {
+	slowRequestsTotal.WithLabelValues(api, bucket).Inc()
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	usageRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_usage_requests_total",
+			Help: "Total number of requests to the bucket made with the access key",
+		},
+		[]string{"bucket", "access_key"},
+	)
+	usageBytesIn = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_usage_in_bytes_total",
+			Help: "Total number of bytes received in requests to the bucket made with the access key",
+		},
+		[]string{"bucket", "access_key"},
+	)
+	usageBytesOut = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_usage_out_bytes_total",
+			Help: "Total number of bytes sent in responses to requests to the bucket made with the access key",
+		},
+		[]string{"bucket", "access_key"},
+	)
+
+	// usageMetricsEnabled is set to 1 if usage is exposed by metrics.
+	usageMetricsEnabled uint32
+)
+
+// SetUsageMetricsEnabled turns on or off usage metrics. They are labeled by access key IDs,
+// so they aren't exposed unless explicitly enabled.
+func SetUsageMetricsEnabled(enabled bool) {
+	var val uint32
+	if enabled {
+		val = 1
+	}
+	atomic.StoreUint32(&usageMetricsEnabled, val)
+}
+
+// AddUsage accounts a request to the bucket made with the access key
+// along with the received and sent bytes if usage metrics are enabled.
+func AddUsage(bucket, accessKeyID string, bytesIn, bytesOut uint64) {
+	if atomic.LoadUint32(&usageMetricsEnabled) == 0 {
+		return
+	}
+	usageRequests.WithLabelValues(bucket, accessKeyID).Inc()
+	usageBytesIn.WithLabelValues(bucket, accessKeyID).Add(float64(bytesIn))
+	usageBytesOut.WithLabelValues(bucket, accessKeyID).Add(float64(bytesOut))
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"go.uber.org/zap"
+)
+
+// Mode is an operation mode of the gateway.
+type Mode int32
+
+const (
+	// ModeNormal allows all requests.
+	ModeNormal Mode = iota
+	// ModeReadOnly rejects requests which modify data with ServiceUnavailable error.
+	ModeReadOnly
+	// ModeMaintenance rejects all requests with SlowDown error.
+	ModeMaintenance
+)
+
+// Names of the modes in configuration.
+const (
+	ModeNameNormal      = "normal"
+	ModeNameReadOnly    = "read_only"
+	ModeNameMaintenance = "maintenance"
+)
+
+// ParseMode parses the name of the mode.
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "", ModeNameNormal:
+		return ModeNormal, nil
+	case ModeNameReadOnly:
+		return ModeReadOnly, nil
+	case ModeNameMaintenance:
+		return ModeMaintenance, nil
+	default:
+		return ModeNormal, fmt.Errorf("unknown mode '%s'", name)
+	}
+}
+
+func (m Mode) String() string {
+	switch m {
+	case ModeReadOnly:
+		return ModeNameReadOnly
+	case ModeMaintenance:
+		return ModeNameMaintenance
+	default:
+		return ModeNameNormal
+	}
+}
+
+// GatewayMode is a current mode of the gateway which can be switched at runtime.
+type GatewayMode struct {
+	mode int32
+}
+
+// NewGatewayMode creates the gateway mode.
+func NewGatewayMode(mode Mode) *GatewayMode {
+	m := &GatewayMode{}
+	m.Set(mode)
+	return m
+}
+
+// Set switches the gateway to the mode.
+func (m *GatewayMode) Set(mode Mode) {
+	atomic.StoreInt32(&m.mode, int32(mode))
+}
+
+// Get returns the current mode.
+func (m *GatewayMode) Get() Mode {
+	if m == nil {
+		return ModeNormal
+	}
+	return Mode(atomic.LoadInt32(&m.mode))
+}
+
+// isReadRequest checks if the request doesn't modify data.
+func isReadRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		// SelectObjectContent
+		_, ok := r.URL.Query()["select"]
+		return ok
+	default:
+		return false
+	}
+}
+
+func checkMode(mode *GatewayMode, log *zap.Logger) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var errCode errors.ErrorCode
+			current := mode.Get()
+			switch {
+			case current == ModeMaintenance:
+				errCode = errors.ErrSlowDown
+			case current == ModeReadOnly && !isReadRequest(r):
+				errCode = errors.ErrServiceUnavailable
+			default:
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			log.Debug("request is rejected by gateway mode",
+				zap.Stringer("mode", current),
+				zap.String("method", r.Method),
+				zap.String("url", r.URL.String()))
+			WriteErrorResponse(w, GetReqInfo(r.Context()), errors.GetAPIError(errCode))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGatewayMode(t *testing.T) {
+	mode := NewGatewayMode(ModeNormal)
+	h := checkMode(mode, zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tc := range []struct {
+		mode   Mode
+		method string
+		target string
+		status int
+	}{
+		{mode: ModeNormal, method: http.MethodPut, target: "/bucket/object", status: http.StatusOK},
+		{mode: ModeReadOnly, method: http.MethodGet, target: "/bucket/object", status: http.StatusOK},
+		{mode: ModeReadOnly, method: http.MethodHead, target: "/bucket/object", status: http.StatusOK},
+		{mode: ModeReadOnly, method: http.MethodPost, target: "/bucket/object?select&select-type=2", status: http.StatusOK},
+		{mode: ModeReadOnly, method: http.MethodPut, target: "/bucket/object", status: http.StatusServiceUnavailable},
+		{mode: ModeReadOnly, method: http.MethodDelete, target: "/bucket/object", status: http.StatusServiceUnavailable},
+		{mode: ModeReadOnly, method: http.MethodPost, target: "/bucket?delete", status: http.StatusServiceUnavailable},
+		{mode: ModeMaintenance, method: http.MethodGet, target: "/bucket/object", status: http.StatusServiceUnavailable},
+	} {
+		mode.Set(tc.mode)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tc.method, tc.target, nil))
+		require.Equal(t, tc.status, w.Code, "%s %s in %s mode", tc.method, tc.target, tc.mode)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, name := range []string{ModeNameNormal, ModeNameReadOnly, ModeNameMaintenance} {
+		mode, err := ParseMode(name)
+		require.NoError(t, err)
+		require.Equal(t, name, mode.String())
+	}
+
+	mode, err := ParseMode("")
+	require.NoError(t, err)
+	require.Equal(t, ModeNormal, mode)
+
+	_, err = ParseMode("readonly")
+	require.Error(t, err)
+}
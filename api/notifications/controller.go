@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -22,6 +25,11 @@ const (
 	EventVersion22 = "2.2"
 	// EventVersion21 is used for all other notification types.
 	EventVersion21 = "2.1"
+
+	eventPrefix       = "s3:"
+	bucketArnPrefix   = "arn:aws:s3:::"
+	responseRequestID = "x-amz-request-id"
+	responseHostID    = "x-amz-id-2"
 )
 
 type (
@@ -51,8 +59,8 @@ type (
 		Event     string
 		Time      time.Time
 		Bucket    string
-		RequestID string
-		HostID    string
+		RequestID string `json:"RequestId"`
+		HostID    string `json:"HostId"`
 	}
 
 	Event struct {
@@ -198,53 +206,67 @@ func (c *Controller) SendNotifications(topics map[string]string, p *handler.Send
 }
 
 func (c *Controller) SendTestNotification(topic, bucketName, requestID, HostID string, now time.Time) error {
+	msg, err := prepareTestEvent(bucketName, requestID, HostID, now)
+	if err != nil {
+		return err
+	}
+
+	return c.publish(topic, msg)
+}
+
+func prepareTestEvent(bucketName, requestID, hostID string, now time.Time) ([]byte, error) {
 	event := &TestEvent{
 		Service:   "NeoFS S3",
 		Event:     "s3:TestEvent",
 		Time:      now,
 		Bucket:    bucketName,
 		RequestID: requestID,
-		HostID:    HostID,
+		HostID:    hostID,
 	}
 
 	msg, err := json.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("couldn't marshal test event: %w", err)
+		return nil, fmt.Errorf("couldn't marshal test event: %w", err)
 	}
 
-	return c.publish(topic, msg)
+	return msg, nil
 }
 
 func prepareEvent(p *handler.SendNotificationParams) *Event {
 	return &Event{
 		Records: []EventRecord{
 			{
-				EventVersion: EventVersion21,
+				EventVersion: eventVersion(p.Event),
 				EventSource:  "neofs:s3",
 				AWSRegion:    "",
 				EventTime:    p.Time,
-				EventName:    p.Event,
+				// event names in records don't contain "s3:" prefix of the names in configurations
+				EventName: strings.TrimPrefix(p.Event, eventPrefix),
 				UserIdentity: UserIdentity{
 					PrincipalID: p.User,
 				},
 				RequestParameters: RequestParameters{
 					SourceIPAddress: p.ReqInfo.RemoteHost,
 				},
-				ResponseElements: nil,
+				ResponseElements: map[string]string{
+					responseRequestID: p.ReqInfo.RequestID,
+					responseHostID:    p.ReqInfo.DeploymentID,
+				},
 				S3: S3Entity{
 					SchemaVersion: "1.0",
 					// ConfigurationID is skipped and will be placed later
 					Bucket: Bucket{
 						Name:          p.BktInfo.Name,
 						OwnerIdentity: UserIdentity{PrincipalID: p.BktInfo.Owner.String()},
-						Arn:           p.BktInfo.Name,
+						Arn:           bucketArnPrefix + p.BktInfo.Name,
 					},
 					Object: Object{
-						Key:       p.NotificationInfo.Name,
+						Key:       eventObjectKey(p.NotificationInfo.Name),
 						Size:      p.NotificationInfo.Size,
 						VersionID: p.NotificationInfo.Version,
 						ETag:      p.NotificationInfo.HashSum,
-						Sequencer: "",
+						// sequencer orders events of the same object, it's the event time as a hex string
+						Sequencer: strings.ToUpper(strconv.FormatInt(p.Time.UnixNano(), 16)),
 					},
 				},
 			},
@@ -252,6 +274,25 @@ func prepareEvent(p *handler.SendNotificationParams) *Event {
 	}
 }
 
+// eventObjectKey encodes object name the way it's done in the records of S3 events:
+// as a query value keeping slashes unescaped.
+func eventObjectKey(name string) string {
+	return strings.ReplaceAll(url.QueryEscape(name), "%2F", "/")
+}
+
+// eventVersion returns version of the record structure of the event type.
+func eventVersion(event string) string {
+	for _, prefix := range []string{"s3:Lifecycle", "s3:IntelligentTiering", "s3:ObjectAcl", "s3:ObjectTagging", "s3:ObjectRestore"} {
+		if strings.HasPrefix(event, prefix) {
+			return EventVersion23
+		}
+	}
+	if strings.HasPrefix(event, "s3:Replication") {
+		return EventVersion22
+	}
+	return EventVersion21
+}
+
 func (c *Controller) publish(topic string, msg []byte) error {
 	if _, err := c.jsClient.Publish(topic, msg); err != nil {
 		return fmt.Errorf("couldn't send  event: %w", err)
@@ -0,0 +1,50 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareEvent(t *testing.T) {
+	p := &handler.SendNotificationParams{
+		Event:            handler.EventObjectCreatedPut,
+		NotificationInfo: &data.NotificationInfo{Name: "dir/my file.txt", Version: "version", Size: 7, HashSum: "etag"},
+		BktInfo:          &data.BucketInfo{Name: "bucket"},
+		ReqInfo:          &api.ReqInfo{RemoteHost: "127.0.0.1", RequestID: "request", DeploymentID: "host"},
+		User:             "user",
+		Time:             time.Unix(0, 255),
+	}
+
+	record := prepareEvent(p).Records[0]
+	require.Equal(t, EventVersion21, record.EventVersion)
+	require.Equal(t, "ObjectCreated:Put", record.EventName)
+	require.Equal(t, "user", record.UserIdentity.PrincipalID)
+	require.Equal(t, "127.0.0.1", record.RequestParameters.SourceIPAddress)
+	require.Equal(t, map[string]string{"x-amz-request-id": "request", "x-amz-id-2": "host"}, record.ResponseElements)
+	require.Equal(t, "arn:aws:s3:::bucket", record.S3.Bucket.Arn)
+	require.Equal(t, "dir/my+file.txt", record.S3.Object.Key)
+	require.Equal(t, "FF", record.S3.Object.Sequencer)
+
+	for event, version := range map[string]string{
+		handler.EventObjectRemovedDeleteMarkerCreated:       EventVersion21,
+		handler.EventObjectCreatedCompleteMultipartUpload:   EventVersion21,
+		handler.EventObjectTaggingPut:                       EventVersion23,
+		handler.EventObjectACLPut:                           EventVersion23,
+		handler.EventLifecycleExpirationDeleteMarkerCreated: EventVersion23,
+		handler.EventReplicationOperationNotTracked:         EventVersion22,
+	} {
+		require.Equal(t, version, eventVersion(event), event)
+	}
+}
+
+func TestPrepareTestEvent(t *testing.T) {
+	msg, err := prepareTestEvent("bucket", "request", "host", time.Date(2022, 12, 1, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Service":"NeoFS S3","Event":"s3:TestEvent","Time":"2022-12-01T15:00:00Z",`+
+		`"Bucket":"bucket","RequestId":"request","HostId":"host"}`, string(msg))
+}
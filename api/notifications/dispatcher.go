@@ -0,0 +1,245 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
+	"go.uber.org/zap"
+)
+
+type (
+	// Dispatcher sends notifications to the destinations chosen by queue ARNs of bucket
+	// notification configurations: ARNs with WebhookPrefix, KafkaPrefix or AMQPPrefix
+	// refer to configured destinations, other ARNs are NATS subjects.
+	Dispatcher struct {
+		log     *zap.Logger
+		nats    *Controller
+		queue   *Queue
+		targets map[string]target
+	}
+
+	// Destinations are destinations of notifications other than NATS.
+	Destinations struct {
+		Webhooks []WebhookOptions
+		Kafka    []KafkaOptions
+		AMQP     []AMQPOptions
+	}
+
+	// target is a destination of notifications other than NATS.
+	target interface {
+		// send delivers the event, key identifies the object of the event.
+		send(ctx context.Context, key string, msg []byte) error
+		close() error
+	}
+)
+
+// IsEmpty checks if no destinations are configured.
+func (d Destinations) IsEmpty() bool {
+	return len(d.Webhooks) == 0 && len(d.Kafka) == 0 && len(d.AMQP) == 0
+}
+
+// NewDispatcher creates Dispatcher. NATS controller is nil if NATS is disabled,
+// events are sent without the persistent queue if it's nil. The queue is closed
+// with the dispatcher.
+func NewDispatcher(nats *Controller, dst Destinations, queue *Queue, l *zap.Logger) (*Dispatcher, error) {
+	d := &Dispatcher{
+		log:     l,
+		nats:    nats,
+		queue:   queue,
+		targets: make(map[string]target),
+	}
+
+	for _, opts := range dst.Webhooks {
+		wh, err := newWebhook(opts)
+		if err != nil {
+			return nil, d.closeOnError(err)
+		}
+		if err = d.addTarget(WebhookPrefix+opts.Name, wh); err != nil {
+			return nil, d.closeOnError(err)
+		}
+	}
+
+	for _, opts := range dst.Kafka {
+		k, err := newKafka(opts)
+		if err != nil {
+			return nil, d.closeOnError(err)
+		}
+		if err = d.addTarget(KafkaPrefix+opts.Name, k); err != nil {
+			_ = k.close()
+			return nil, d.closeOnError(err)
+		}
+	}
+
+	for _, opts := range dst.AMQP {
+		a, err := newAMQP(opts)
+		if err != nil {
+			return nil, d.closeOnError(err)
+		}
+		if err = d.addTarget(AMQPPrefix+opts.Name, a); err != nil {
+			return nil, d.closeOnError(err)
+		}
+	}
+
+	return d, nil
+}
+
+func (d *Dispatcher) addTarget(arn string, t target) error {
+	if _, ok := d.targets[arn]; ok {
+		return fmt.Errorf("duplicated destination '%s'", arn)
+	}
+	d.targets[arn] = t
+	return nil
+}
+
+func (d *Dispatcher) closeOnError(err error) error {
+	d.Close()
+	return err
+}
+
+// Close closes connections to the destinations and the queue.
+func (d *Dispatcher) Close() {
+	for arn, t := range d.targets {
+		if err := t.close(); err != nil {
+			d.log.Warn("couldn't close notification destination", zap.String("subject", arn), zap.Error(err))
+		}
+	}
+
+	if d.queue != nil {
+		if err := d.queue.close(); err != nil {
+			d.log.Warn("couldn't close events queue", zap.Error(err))
+		}
+	}
+}
+
+// Run delivers events of the persistent queue until ctx is done. It returns immediately
+// if the queue is disabled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	if d.queue == nil {
+		return
+	}
+	d.queue.run(ctx, d.deliver)
+}
+
+// SendNotifications sends the event to the topics. Events are sent to destinations other
+// than NATS in the background, so slow destinations don't delay responses. If the queue
+// is enabled, events to all destinations are put to the queue and are sent from it.
+func (d *Dispatcher) SendNotifications(topics map[string]string, p *handler.SendNotificationParams) error {
+	if d.queue != nil {
+		return d.queueNotifications(topics, p)
+	}
+
+	natsTopics := make(map[string]string)
+	for id, topic := range topics {
+		if !isTargetARN(topic) {
+			natsTopics[id] = topic
+			continue
+		}
+
+		t, err := d.target(topic)
+		if err != nil {
+			d.log.Error("couldn't send an event", zap.String("subject", topic), zap.Error(err))
+			continue
+		}
+
+		event := prepareEvent(p)
+		event.Records[0].S3.ConfigurationID = id
+		msg, err := json.Marshal(event)
+		if err != nil {
+			d.log.Error("couldn't marshal an event", zap.String("subject", topic), zap.Error(err))
+			continue
+		}
+
+		key := p.BktInfo.Name + "/" + p.NotificationInfo.Name
+		go func(topic string) {
+			if err := t.send(context.Background(), key, msg); err != nil {
+				d.log.Error("couldn't send an event to topic", zap.String("subject", topic), zap.Error(err))
+			}
+		}(topic)
+	}
+
+	if len(natsTopics) == 0 {
+		return nil
+	}
+	if d.nats == nil {
+		return fmt.Errorf("nats is disabled, events aren't sent to %d topics", len(natsTopics))
+	}
+
+	return d.nats.SendNotifications(natsTopics, p)
+}
+
+func (d *Dispatcher) queueNotifications(topics map[string]string, p *handler.SendNotificationParams) error {
+	event := prepareEvent(p)
+	key := p.BktInfo.Name + "/" + p.NotificationInfo.Name
+
+	for id, topic := range topics {
+		event.Records[0].S3.ConfigurationID = id
+		msg, err := json.Marshal(event)
+		if err != nil {
+			d.log.Error("couldn't marshal an event", zap.String("subject", topic), zap.Error(err))
+			continue
+		}
+
+		if err = d.queue.push(topic, key, msg); err != nil {
+			d.log.Error("couldn't queue an event", zap.String("subject", topic), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// deliver sends the event from the queue to the destination.
+func (d *Dispatcher) deliver(ctx context.Context, arn, key string, msg []byte) error {
+	if !isTargetARN(arn) {
+		if d.nats == nil {
+			return fmt.Errorf("nats is disabled, event isn't sent to '%s'", arn)
+		}
+		return d.nats.publish(arn, msg)
+	}
+
+	t, err := d.target(arn)
+	if err != nil {
+		return err
+	}
+	return t.send(ctx, key, msg)
+}
+
+// SendTestNotification sends the test event to the topic. Test events are sent
+// synchronously to check the destination when a notification configuration is put.
+func (d *Dispatcher) SendTestNotification(topic, bucketName, requestID, hostID string, now time.Time) error {
+	if !isTargetARN(topic) {
+		if d.nats == nil {
+			return fmt.Errorf("nats is disabled, can't send test event to '%s'", topic)
+		}
+		return d.nats.SendTestNotification(topic, bucketName, requestID, hostID, now)
+	}
+
+	t, err := d.target(topic)
+	if err != nil {
+		return err
+	}
+
+	msg, err := prepareTestEvent(bucketName, requestID, hostID, now)
+	if err != nil {
+		return err
+	}
+
+	return t.send(context.Background(), bucketName, msg)
+}
+
+func (d *Dispatcher) target(arn string) (target, error) {
+	t, ok := d.targets[arn]
+	if !ok {
+		return nil, errors.GetAPIErrorWithError(errors.ErrARNNotification, fmt.Errorf("unknown destination '%s'", arn))
+	}
+	return t, nil
+}
+
+func isTargetARN(arn string) bool {
+	return strings.HasPrefix(arn, WebhookPrefix) || strings.HasPrefix(arn, KafkaPrefix) ||
+		strings.HasPrefix(arn, AMQPPrefix)
+}
@@ -0,0 +1,120 @@
+package notifications
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// KafkaPrefix is a prefix of queue ARNs of bucket notification configurations
+// which send events to the Kafka destination with the name following the prefix.
+const KafkaPrefix = "kafka:"
+
+// SASL mechanisms of Kafka authentication.
+const (
+	SASLPlain       = "plain"
+	SASLScramSHA256 = "scram-sha-256"
+	SASLScramSHA512 = "scram-sha-512"
+)
+
+// kafkaBatchTimeout limits the time events wait for other events to be written in the same batch.
+const kafkaBatchTimeout = 10 * time.Millisecond
+
+type (
+	// KafkaOptions are parameters of a Kafka destination of notifications.
+	KafkaOptions struct {
+		// Name is used to refer to the destination in notification configurations.
+		Name    string
+		Brokers []string
+		Topic   string
+		// TLS is nil if TLS is disabled.
+		TLS  *tls.Config
+		SASL SASLOptions
+		// Timeout of a single write.
+		Timeout time.Duration
+		// MaxAttempts is a maximum number of attempts to write an event.
+		MaxAttempts int
+	}
+
+	// SASLOptions are parameters of SASL authentication, empty mechanism disables it.
+	SASLOptions struct {
+		Mechanism string
+		Username  string
+		Password  string
+	}
+
+	kafkaTarget struct {
+		writer *kafka.Writer
+	}
+)
+
+func newKafka(opts KafkaOptions) (*kafkaTarget, error) {
+	if opts.Name == "" {
+		return nil, errors.New("empty kafka destination name")
+	}
+	if len(opts.Brokers) == 0 || opts.Topic == "" {
+		return nil, fmt.Errorf("brokers and topic of kafka destination '%s' must be set", opts.Name)
+	}
+
+	mechanism, err := saslMechanism(opts.SASL)
+	if err != nil {
+		return nil, fmt.Errorf("kafka destination '%s': %w", opts.Name, err)
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	return &kafkaTarget{
+		writer: &kafka.Writer{
+			Addr:  kafka.TCP(opts.Brokers...),
+			Topic: opts.Topic,
+			// events of the same object get to the same partition, so they are consumed in order
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			MaxAttempts:  opts.MaxAttempts,
+			BatchTimeout: kafkaBatchTimeout,
+			WriteTimeout: opts.Timeout,
+			Transport: &kafka.Transport{
+				DialTimeout: opts.Timeout,
+				TLS:         opts.TLS,
+				SASL:        mechanism,
+			},
+		},
+	}, nil
+}
+
+func saslMechanism(opts SASLOptions) (sasl.Mechanism, error) {
+	switch strings.ToLower(opts.Mechanism) {
+	case "":
+		return nil, nil
+	case SASLPlain:
+		return plain.Mechanism{Username: opts.Username, Password: opts.Password}, nil
+	case SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, opts.Username, opts.Password)
+	case SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, opts.Username, opts.Password)
+	default:
+		return nil, fmt.Errorf("unknown sasl mechanism '%s'", opts.Mechanism)
+	}
+}
+
+// send writes the event to the topic with the object as a message key.
+func (k *kafkaTarget) send(ctx context.Context, key string, msg []byte) error {
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(key),
+		Value: msg,
+	})
+}
+
+func (k *kafkaTarget) close() error {
+	return k.writer.Close()
+}
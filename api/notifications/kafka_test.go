@@ -0,0 +1,31 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKafka(t *testing.T) {
+	opts := KafkaOptions{Name: "events", Brokers: []string{"localhost:9092"}, Topic: "s3-events"}
+
+	k, err := newKafka(opts)
+	require.NoError(t, err)
+	require.Equal(t, "s3-events", k.writer.Topic)
+	require.IsType(t, &kafka.Hash{}, k.writer.Balancer)
+	require.NoError(t, k.close())
+
+	for _, mechanism := range []string{SASLPlain, SASLScramSHA256, "SCRAM-SHA-512"} {
+		opts.SASL = SASLOptions{Mechanism: mechanism, Username: "user", Password: "password"}
+		_, err = newKafka(opts)
+		require.NoError(t, err, mechanism)
+	}
+
+	opts.SASL.Mechanism = "gssapi"
+	_, err = newKafka(opts)
+	require.Error(t, err)
+
+	_, err = newKafka(KafkaOptions{Name: "events", Topic: "s3-events"})
+	require.Error(t, err)
+}
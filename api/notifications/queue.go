@@ -0,0 +1,315 @@
+package notifications
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+const (
+	// queueBatchSize is a maximum number of events delivered in one pass over the queue.
+	queueBatchSize = 1000
+	// queueIdleInterval is an interval of checks of the queue if no events are expected to be due.
+	queueIdleInterval = time.Minute
+
+	deadLetterTimeFormat = "20060102T150405Z"
+)
+
+var queueBucket = []byte("events")
+
+type (
+	// QueueOptions are parameters of the persistent queue of events.
+	QueueOptions struct {
+		// Path to the queue file, it's created if it doesn't exist.
+		Path string
+		// Retry defines delays between delivery attempts. Events which aren't
+		// delivered in MaxAttempts are moved to the dead letter.
+		Retry RetryPolicy
+		// DeadLetter stores undelivered events, they are dropped if it's nil.
+		DeadLetter DeadLetter
+	}
+
+	// DeadLetter stores events which couldn't be delivered.
+	DeadLetter interface {
+		PutDeadLetter(ctx context.Context, name string, payload []byte) error
+	}
+
+	// Queue keeps events on disk until they are delivered, so events aren't lost
+	// if destinations are unavailable or the gateway is restarted.
+	Queue struct {
+		log    *zap.Logger
+		db     *bbolt.DB
+		opts   QueueOptions
+		notify chan struct{}
+	}
+
+	queuedEvent struct {
+		Destination string          `json:"destination"`
+		Key         string          `json:"key"`
+		Event       json.RawMessage `json:"event"`
+		Queued      time.Time       `json:"queued"`
+		Attempts    int             `json:"attempts"`
+		NextAttempt time.Time       `json:"next_attempt"`
+		LastError   string          `json:"last_error,omitempty"`
+	}
+
+	queueEntry struct {
+		id    uint64
+		event *queuedEvent
+	}
+
+	// deliverFunc sends the event to the destination.
+	deliverFunc func(ctx context.Context, destination, key string, msg []byte) error
+)
+
+// OpenQueue opens the queue file, events remaining from the previous run are delivered first.
+func OpenQueue(opts QueueOptions, l *zap.Logger) (*Queue, error) {
+	if opts.Path == "" {
+		return nil, errors.New("empty path of events queue")
+	}
+
+	db, err := bbolt.Open(opts.Path, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open events queue: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(queueBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("couldn't init events queue: %w", err)
+	}
+
+	return &Queue{
+		log:    l,
+		db:     db,
+		opts:   opts,
+		notify: make(chan struct{}, 1),
+	}, nil
+}
+
+// push adds the event to the queue and wakes the worker up.
+func (q *Queue) push(destination, key string, msg []byte) error {
+	now := time.Now()
+	data, err := json.Marshal(&queuedEvent{
+		Destination: destination,
+		Key:         key,
+		Event:       msg,
+		Queued:      now,
+		NextAttempt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = q.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(queueBucket)
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(queueKey(id), data)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't put event to queue: %w", err)
+	}
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// run delivers queued events until ctx is done.
+func (q *Queue) run(ctx context.Context, deliver deliverFunc) {
+	for {
+		wait := q.deliverDue(ctx, deliver)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-q.notify:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// deliverDue delivers due events and returns the time to wait for the next ones.
+// Events of every destination are delivered in order of queueing, the rest of
+// events of the destination are postponed if one of them fails.
+func (q *Queue) deliverDue(ctx context.Context, deliver deliverFunc) time.Duration {
+	due, wait, err := q.due(time.Now())
+	if err != nil {
+		q.log.Error("couldn't read events queue", zap.Error(err))
+		return queueIdleInterval
+	}
+
+	var destinations []string
+	groups := make(map[string][]queueEntry)
+	for _, e := range due {
+		if _, ok := groups[e.event.Destination]; !ok {
+			destinations = append(destinations, e.event.Destination)
+		}
+		groups[e.event.Destination] = append(groups[e.event.Destination], e)
+	}
+
+	var wg sync.WaitGroup
+	for _, dst := range destinations {
+		wg.Add(1)
+		go func(entries []queueEntry) {
+			defer wg.Done()
+			q.deliverEntries(ctx, deliver, entries)
+		}(groups[dst])
+	}
+	wg.Wait()
+
+	return wait
+}
+
+func (q *Queue) deliverEntries(ctx context.Context, deliver deliverFunc, entries []queueEntry) {
+	for i, e := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err := deliver(ctx, e.event.Destination, e.event.Key, e.event.Event)
+		if err == nil {
+			q.update(e.id, nil)
+			continue
+		}
+		if ctx.Err() != nil {
+			// the gateway is stopped, the event is delivered after restart
+			return
+		}
+
+		e.event.Attempts++
+		e.event.LastError = err.Error()
+		if e.event.Attempts >= q.opts.Retry.MaxAttempts {
+			q.moveToDeadLetter(ctx, e)
+			continue
+		}
+
+		e.event.NextAttempt = time.Now().Add(q.opts.Retry.backoff(e.event.Attempts))
+		q.log.Warn("couldn't deliver an event, it will be retried", zap.String("subject", e.event.Destination),
+			zap.Int("attempts", e.event.Attempts), zap.Time("next attempt", e.event.NextAttempt), zap.Error(err))
+		q.update(e.id, e.event)
+
+		// the destination is unavailable, so the next events wait for the retry
+		for _, next := range entries[i+1:] {
+			next.event.NextAttempt = e.event.NextAttempt
+			q.update(next.id, next.event)
+		}
+		return
+	}
+}
+
+func (q *Queue) moveToDeadLetter(ctx context.Context, e queueEntry) {
+	l := q.log.With(zap.String("subject", e.event.Destination), zap.String("key", e.event.Key),
+		zap.Int("attempts", e.event.Attempts), zap.String("last error", e.event.LastError))
+
+	if q.opts.DeadLetter == nil {
+		l.Error("event isn't delivered and is dropped")
+		q.update(e.id, nil)
+		return
+	}
+
+	payload, err := json.Marshal(e.event)
+	if err == nil {
+		name := fmt.Sprintf("%s-%016x.json", e.event.Queued.UTC().Format(deadLetterTimeFormat), e.id)
+		err = q.opts.DeadLetter.PutDeadLetter(ctx, name, payload)
+	}
+	if err != nil {
+		// the event is kept in the queue to be retried
+		e.event.NextAttempt = time.Now().Add(q.opts.Retry.backoff(e.event.Attempts))
+		l.Error("couldn't move an event to dead letter", zap.Error(err))
+		q.update(e.id, e.event)
+		return
+	}
+
+	l.Warn("event isn't delivered and is moved to dead letter")
+	q.update(e.id, nil)
+}
+
+// due returns events which are due at the moment and the time to wait for the next due event.
+func (q *Queue) due(now time.Time) ([]queueEntry, time.Duration, error) {
+	var (
+		res     []queueEntry
+		invalid []uint64
+		wait    = queueIdleInterval
+	)
+
+	err := q.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(queueBucket).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			e := new(queuedEvent)
+			if err := json.Unmarshal(v, e); err != nil {
+				q.log.Error("invalid event in queue is dropped", zap.Uint64("id", binary.BigEndian.Uint64(k)), zap.Error(err))
+				invalid = append(invalid, binary.BigEndian.Uint64(k))
+				continue
+			}
+
+			if until := e.NextAttempt.Sub(now); until > 0 {
+				if until < wait {
+					wait = until
+				}
+				continue
+			}
+
+			if len(res) == queueBatchSize {
+				wait = 0
+				break
+			}
+			res = append(res, queueEntry{id: binary.BigEndian.Uint64(k), event: e})
+		}
+		return nil
+	})
+
+	for _, id := range invalid {
+		q.update(id, nil)
+	}
+
+	return res, wait, err
+}
+
+// update stores the event, nil event is removed from the queue.
+func (q *Queue) update(id uint64, e *queuedEvent) {
+	err := q.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(queueBucket)
+		if e == nil {
+			return b.Delete(queueKey(id))
+		}
+
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		return b.Put(queueKey(id), data)
+	})
+	if err != nil {
+		q.log.Error("couldn't update events queue", zap.Uint64("id", id), zap.Error(err))
+	}
+}
+
+func (q *Queue) close() error {
+	return q.db.Close()
+}
+
+func queueKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
@@ -0,0 +1,92 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testDeadLetter struct {
+	names    []string
+	payloads [][]byte
+}
+
+func (d *testDeadLetter) PutDeadLetter(_ context.Context, name string, payload []byte) error {
+	d.names = append(d.names, name)
+	d.payloads = append(d.payloads, payload)
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	dl := &testDeadLetter{}
+	opts := QueueOptions{
+		Path:       filepath.Join(t.TempDir(), "queue.db"),
+		Retry:      RetryPolicy{MaxAttempts: 2},
+		DeadLetter: dl,
+	}
+
+	q, err := OpenQueue(opts, zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, q.push(WebhookPrefix+"a", "bucket/obj1", []byte(`{"n":1}`)))
+	require.NoError(t, q.push(WebhookPrefix+"a", "bucket/obj2", []byte(`{"n":2}`)))
+	require.NoError(t, q.push(WebhookPrefix+"b", "bucket/obj3", []byte(`{"n":3}`)))
+
+	var (
+		mu        sync.Mutex
+		delivered []string
+		failing   = map[string]bool{WebhookPrefix + "a": true}
+	)
+	deliver := func(_ context.Context, dst, key string, _ []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if failing[dst] {
+			return errors.New("unavailable")
+		}
+		delivered = append(delivered, key)
+		return nil
+	}
+
+	q.deliverDue(ctx, deliver)
+	require.Equal(t, []string{"bucket/obj3"}, delivered)
+
+	due, _, err := q.due(time.Now())
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	require.Equal(t, 1, due[0].event.Attempts)
+	require.Equal(t, "unavailable", due[0].event.LastError)
+	require.Equal(t, 0, due[1].event.Attempts, "postponed events aren't attempted")
+
+	// events survive restarts
+	require.NoError(t, q.close())
+	q, err = OpenQueue(opts, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = q.close() })
+
+	q.deliverDue(ctx, deliver)
+	require.Len(t, dl.names, 1)
+
+	var dead queuedEvent
+	require.NoError(t, json.Unmarshal(dl.payloads[0], &dead))
+	require.Equal(t, WebhookPrefix+"a", dead.Destination)
+	require.Equal(t, "bucket/obj1", dead.Key)
+	require.Equal(t, 2, dead.Attempts)
+	require.JSONEq(t, `{"n":1}`, string(dead.Event))
+
+	failing[WebhookPrefix+"a"] = false
+	q.deliverDue(ctx, deliver)
+	require.Equal(t, []string{"bucket/obj3", "bucket/obj2"}, delivered)
+
+	due, wait, err := q.due(time.Now())
+	require.NoError(t, err)
+	require.Empty(t, due)
+	require.Equal(t, queueIdleInterval, wait)
+}
@@ -0,0 +1,158 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WebhookPrefix is a prefix of queue ARNs of bucket notification configurations
+// which send events to the webhook with the name following the prefix.
+const WebhookPrefix = "webhook:"
+
+// maxWebhookResponseSize is a size of the response body read to reuse the connection.
+const maxWebhookResponseSize = 64 << 10
+
+type (
+	// WebhookOptions are parameters of a webhook destination of notifications.
+	WebhookOptions struct {
+		// Name is used to refer to the webhook in notification configurations.
+		Name string
+		// URL events are posted to.
+		URL string
+		// Headers are added to every request, e.g. to authenticate the gateway.
+		Headers map[string]string
+		// Timeout of a single request.
+		Timeout time.Duration
+		Retry   RetryPolicy
+	}
+
+	// RetryPolicy defines retries of events failed to be delivered. Delays between attempts
+	// grow exponentially from InitialBackoff up to MaxBackoff with full jitter.
+	RetryPolicy struct {
+		// MaxAttempts is a maximum number of attempts including the first one.
+		// Values less than 2 disable retries.
+		MaxAttempts    int
+		InitialBackoff time.Duration
+		MaxBackoff     time.Duration
+	}
+
+	webhook struct {
+		opts   WebhookOptions
+		client *http.Client
+	}
+
+	// webhookStatusError is returned if the webhook responded with non-2xx status.
+	webhookStatusError struct {
+		code int
+	}
+)
+
+func newWebhook(opts WebhookOptions) (*webhook, error) {
+	if opts.Name == "" {
+		return nil, errors.New("empty webhook name")
+	}
+
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url of webhook '%s': %w", opts.Name, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid url of webhook '%s': scheme must be http or https", opts.Name)
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	return &webhook{
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+	}, nil
+}
+
+func (e webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.code)
+}
+
+// retryable checks if the request can succeed later: client errors except timeouts
+// and rate limiting mean the event is rejected by the webhook.
+func (e webhookStatusError) retryable() bool {
+	return e.code >= http.StatusInternalServerError ||
+		e.code == http.StatusRequestTimeout || e.code == http.StatusTooManyRequests
+}
+
+// send posts the event to the webhook retrying failed attempts according to the retry policy.
+func (w *webhook) send(ctx context.Context, _ string, msg []byte) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = w.post(ctx, msg); err == nil {
+			return nil
+		}
+
+		var statusErr webhookStatusError
+		if errors.As(err, &statusErr) && !statusErr.retryable() || attempt >= w.opts.Retry.MaxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(w.opts.Retry.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (w *webhook) post(ctx context.Context, msg []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.opts.URL, bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, val := range w.opts.Headers {
+		req.Header.Set(key, val)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// the body is drained to reuse the connection
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponseSize))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return webhookStatusError{code: resp.StatusCode}
+	}
+
+	return nil
+}
+
+func (w *webhook) close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	limit := p.InitialBackoff
+	for i := 1; i < attempt && limit < p.MaxBackoff; i++ {
+		limit *= 2
+	}
+	if p.MaxBackoff > 0 && limit > p.MaxBackoff {
+		limit = p.MaxBackoff
+	}
+	if limit <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(limit)) + 1)
+}
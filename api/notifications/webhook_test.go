@@ -0,0 +1,131 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type webhookServer struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	received chan struct{}
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	status := http.StatusOK
+	if len(s.requests) < len(s.statuses) {
+		status = s.statuses[len(s.requests)]
+	}
+	s.requests = append(s.requests, r)
+	s.bodies = append(s.bodies, body)
+	s.mu.Unlock()
+
+	w.WriteHeader(status)
+	if status == http.StatusOK && s.received != nil {
+		s.received <- struct{}{}
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	ctx := context.Background()
+	retry := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	newWebhookServer := func(statuses ...int) (*webhookServer, *webhook) {
+		s := &webhookServer{statuses: statuses}
+		srv := httptest.NewServer(s)
+		t.Cleanup(srv.Close)
+
+		wh, err := newWebhook(WebhookOptions{
+			Name:    "test",
+			URL:     srv.URL,
+			Headers: map[string]string{"authorization": "Bearer token"},
+			Retry:   retry,
+		})
+		require.NoError(t, err)
+		return s, wh
+	}
+
+	t.Run("retry", func(t *testing.T) {
+		s, wh := newWebhookServer(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+
+		require.NoError(t, wh.send(ctx, "", []byte(`{}`)))
+		require.Len(t, s.requests, 3)
+		require.Equal(t, "Bearer token", s.requests[2].Header.Get("Authorization"))
+		require.Equal(t, "application/json", s.requests[2].Header.Get("Content-Type"))
+		require.Equal(t, []byte(`{}`), s.bodies[2])
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		s, wh := newWebhookServer(http.StatusForbidden)
+
+		require.ErrorIs(t, wh.send(ctx, "", []byte(`{}`)), webhookStatusError{code: http.StatusForbidden})
+		require.Len(t, s.requests, 1)
+	})
+
+	t.Run("attempts exceeded", func(t *testing.T) {
+		s, wh := newWebhookServer(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+
+		require.Error(t, wh.send(ctx, "", []byte(`{}`)))
+		require.Len(t, s.requests, 3)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		_, err := newWebhook(WebhookOptions{Name: "test", URL: "nats://localhost:4222"})
+		require.Error(t, err)
+	})
+}
+
+func TestDispatcherWebhook(t *testing.T) {
+	s := &webhookServer{received: make(chan struct{}, 1)}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	d, err := NewDispatcher(nil, Destinations{Webhooks: []WebhookOptions{{Name: "test", URL: srv.URL}}}, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, d.SendTestNotification(WebhookPrefix+"test", "bucket", "request", "host", time.Now()))
+	<-s.received
+
+	err = d.SendTestNotification(WebhookPrefix+"unknown", "bucket", "request", "host", time.Now())
+	require.True(t, errors.IsS3Error(err, errors.ErrARNNotification))
+	require.Error(t, d.SendTestNotification("nats-subject", "bucket", "request", "host", time.Now()))
+
+	p := &handler.SendNotificationParams{
+		Event:            handler.EventObjectCreatedPut,
+		NotificationInfo: &data.NotificationInfo{Name: "obj"},
+		BktInfo:          &data.BucketInfo{Name: "bucket"},
+		ReqInfo:          &api.ReqInfo{},
+	}
+	require.NoError(t, d.SendNotifications(map[string]string{"config": WebhookPrefix + "test"}, p))
+	<-s.received
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	require.Len(t, s.bodies, 2)
+
+	var event Event
+	require.NoError(t, json.Unmarshal(s.bodies[1], &event))
+	require.Equal(t, "config", event.Records[0].S3.ConfigurationID)
+	require.Equal(t, "obj", event.Records[0].S3.Object.Key)
+
+	_, err = NewDispatcher(nil, Destinations{Webhooks: []WebhookOptions{{Name: "test", URL: srv.URL}, {Name: "test", URL: srv.URL}}}, nil, zap.NewNop())
+	require.Error(t, err)
+}
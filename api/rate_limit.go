@@ -0,0 +1,257 @@
+package api
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"go.uber.org/zap"
+)
+
+type (
+	// RateLimit is a limit of requests and bytes of request and response payload per second.
+	// Zero value means no limit.
+	RateLimit struct {
+		RequestsPerSecond float64
+		BytesPerSecond    float64
+	}
+
+	// RateLimits limits requests per access key (source IP for anonymous requests) and per bucket
+	// with token buckets. Limits can be updated at runtime.
+	RateLimits struct {
+		mu      sync.Mutex
+		client  RateLimit
+		bucket  RateLimit
+		clients map[string]*rateCounters
+		buckets map[string]*rateCounters
+	}
+
+	rateCounters struct {
+		requests tokenBucket
+		bytes    tokenBucket
+	}
+
+	// tokenBucket is refilled with rate tokens per second up to one second of the rate.
+	// Requests bigger than the bucket are allowed when the bucket is full and put it in debt.
+	tokenBucket struct {
+		tokens  float64
+		updated time.Time
+	}
+
+	// rateLimitedReader consumes byte tokens for the request body read by the handler.
+	rateLimitedReader struct {
+		io.ReadCloser
+		consume func(n int)
+	}
+
+	// rateLimitedResponseWriter consumes byte tokens for the response body.
+	rateLimitedResponseWriter struct {
+		http.ResponseWriter
+		consume func(n int)
+	}
+)
+
+const (
+	// maxRateCounters is a number of tracked clients or buckets after which idle ones are forgotten.
+	maxRateCounters = 10000
+	// idleRateCountersTimeout is a time after which unused counters are considered idle.
+	idleRateCountersTimeout = time.Minute
+)
+
+// NewRateLimits creates rate limits per client and per bucket.
+func NewRateLimits(client, bucket RateLimit) *RateLimits {
+	l := &RateLimits{
+		clients: make(map[string]*rateCounters),
+		buckets: make(map[string]*rateCounters),
+	}
+	l.Update(client, bucket)
+	return l
+}
+
+// Update replaces rate limits. Counters are kept.
+func (l *RateLimits) Update(client, bucket RateLimit) {
+	l.mu.Lock()
+	l.client = client
+	l.bucket = bucket
+	l.mu.Unlock()
+}
+
+type limitedCounters struct {
+	counters *rateCounters
+	limit    RateLimit
+}
+
+// Allow checks if the request of the client to the bucket with payload of size bytes fits
+// the limits and consumes a request token. If it doesn't, the duration to retry after is
+// returned. Byte tokens are consumed by Consume as the payload is transferred, requests
+// aren't allowed while the client or the bucket is in debt of bytes.
+func (l *RateLimits) Allow(client, bucket string, size int64, now time.Time) (time.Duration, bool) {
+	if l == nil {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	checks := l.counters(client, bucket, now)
+
+	var retryAfter time.Duration
+	for _, c := range checks {
+		if wait := c.counters.wait(c.limit, size, now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter > 0 {
+		return retryAfter, false
+	}
+
+	for _, c := range checks {
+		c.counters.requests.consume(c.limit.RequestsPerSecond, 1, now)
+	}
+	return 0, true
+}
+
+// Consume takes n byte tokens of the client and the bucket. The tokens are taken
+// even if they aren't available, so the following requests wait for the debt.
+func (l *RateLimits) Consume(client, bucket string, n int64, now time.Time) {
+	if l == nil || n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, c := range l.counters(client, bucket, now) {
+		c.counters.bytes.consume(c.limit.BytesPerSecond, float64(n), now)
+	}
+}
+
+// counters returns counters of the client and the bucket which are limited.
+func (l *RateLimits) counters(client, bucket string, now time.Time) []limitedCounters {
+	var res []limitedCounters
+	if client != "" && (l.client != RateLimit{}) {
+		res = append(res, limitedCounters{getRateCounters(l.clients, client, now), l.client})
+	}
+	if bucket != "" && (l.bucket != RateLimit{}) {
+		res = append(res, limitedCounters{getRateCounters(l.buckets, bucket, now), l.bucket})
+	}
+	return res
+}
+
+func getRateCounters(m map[string]*rateCounters, key string, now time.Time) *rateCounters {
+	counters, ok := m[key]
+	if ok {
+		return counters
+	}
+
+	if len(m) >= maxRateCounters {
+		for k, c := range m {
+			if c.idle(now) {
+				delete(m, k)
+			}
+		}
+	}
+
+	counters = &rateCounters{}
+	m[key] = counters
+	return counters
+}
+
+// wait returns the duration after which the request with payload of size bytes can be made.
+// Requests without known payload, e.g. downloads, wait for at least one byte token.
+func (c *rateCounters) wait(limit RateLimit, size int64, now time.Time) time.Duration {
+	wait := c.requests.wait(limit.RequestsPerSecond, 1, now)
+	if bytesWait := c.bytes.wait(limit.BytesPerSecond, math.Max(float64(size), 1), now); bytesWait > wait {
+		wait = bytesWait
+	}
+	return wait
+}
+
+// idle is true if the counters weren't used for a while, so they can be dropped.
+func (c *rateCounters) idle(now time.Time) bool {
+	return now.Sub(c.requests.updated) > idleRateCountersTimeout && now.Sub(c.bytes.updated) > idleRateCountersTimeout
+}
+
+func (b *tokenBucket) available(rate float64, now time.Time) float64 {
+	if b.updated.IsZero() {
+		return rate
+	}
+	return math.Min(rate, b.tokens+now.Sub(b.updated).Seconds()*rate)
+}
+
+// wait returns the duration after which n tokens can be taken.
+func (b *tokenBucket) wait(rate, n float64, now time.Time) time.Duration {
+	if rate <= 0 || n <= 0 {
+		return 0
+	}
+
+	if need, tokens := math.Min(n, rate), b.available(rate, now); tokens < need {
+		return time.Duration((need - tokens) / rate * float64(time.Second))
+	}
+	return 0
+}
+
+func (b *tokenBucket) consume(rate, n float64, now time.Time) {
+	if rate <= 0 || n <= 0 {
+		return
+	}
+
+	b.tokens = b.available(rate, now) - n
+	b.updated = now
+}
+
+func rateLimit(limits *RateLimits, log *zap.Logger) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqInfo := GetReqInfo(r.Context())
+
+			client := GetAccessKeyID(r.Context())
+			if client == "" {
+				client = reqInfo.RemoteHost
+			}
+
+			if retryAfter, ok := limits.Allow(client, reqInfo.BucketName, r.ContentLength, time.Now()); !ok {
+				log.Debug("request is rate limited",
+					zap.String("client", client),
+					zap.String("bucket", reqInfo.BucketName),
+					zap.Duration("retry_after", retryAfter))
+				w.Header().Set(hdrRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				WriteErrorResponse(w, reqInfo, errors.GetAPIError(errors.ErrSlowDown))
+				return
+			}
+
+			consume := func(n int) {
+				limits.Consume(client, reqInfo.BucketName, int64(n), time.Now())
+			}
+			if r.Body != nil {
+				r.Body = &rateLimitedReader{ReadCloser: r.Body, consume: consume}
+			}
+
+			h.ServeHTTP(&rateLimitedResponseWriter{ResponseWriter: w, consume: consume}, r)
+		})
+	}
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.consume(n)
+	return n, err
+}
+
+func (w *rateLimitedResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.consume(n)
+	return n, err
+}
+
+// Flush calls the underlying Flush.
+func (w *rateLimitedResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRateLimits(t *testing.T) {
+	now := time.Now()
+
+	t.Run("requests per client", func(t *testing.T) {
+		limits := NewRateLimits(RateLimit{RequestsPerSecond: 2}, RateLimit{})
+
+		for i := 0; i < 2; i++ {
+			_, ok := limits.Allow("client", "bucket", 0, now)
+			require.True(t, ok)
+		}
+
+		retryAfter, ok := limits.Allow("client", "bucket", 0, now)
+		require.False(t, ok)
+		require.Equal(t, 500*time.Millisecond, retryAfter)
+
+		_, ok = limits.Allow("other", "bucket", 0, now)
+		require.True(t, ok)
+
+		_, ok = limits.Allow("client", "bucket", 0, now.Add(500*time.Millisecond))
+		require.True(t, ok)
+	})
+
+	t.Run("bytes per bucket", func(t *testing.T) {
+		limits := NewRateLimits(RateLimit{}, RateLimit{BytesPerSecond: 100})
+
+		// request bigger than the limit is allowed and puts the bucket in debt
+		_, ok := limits.Allow("client", "bucket", 300, now)
+		require.True(t, ok)
+		limits.Consume("client", "bucket", 300, now)
+
+		retryAfter, ok := limits.Allow("other", "bucket", 1, now.Add(time.Second))
+		require.False(t, ok)
+		require.Equal(t, time.Second+10*time.Millisecond, retryAfter)
+
+		_, ok = limits.Allow("client", "other", 300, now)
+		require.True(t, ok)
+
+		_, ok = limits.Allow("other", "bucket", 1, now.Add(2*time.Second+10*time.Millisecond))
+		require.True(t, ok)
+	})
+
+	t.Run("rejected request doesn't consume tokens", func(t *testing.T) {
+		limits := NewRateLimits(RateLimit{RequestsPerSecond: 1}, RateLimit{RequestsPerSecond: 1})
+
+		_, ok := limits.Allow("client", "bucket", 0, now)
+		require.True(t, ok)
+
+		_, ok = limits.Allow("other", "bucket", 0, now)
+		require.False(t, ok)
+
+		_, ok = limits.Allow("other", "another", 0, now)
+		require.True(t, ok)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		limits := NewRateLimits(RateLimit{RequestsPerSecond: 1}, RateLimit{})
+
+		_, ok := limits.Allow("client", "bucket", 0, now)
+		require.True(t, ok)
+		_, ok = limits.Allow("client", "bucket", 0, now)
+		require.False(t, ok)
+
+		limits.Update(RateLimit{}, RateLimit{})
+		_, ok = limits.Allow("client", "bucket", 0, now)
+		require.True(t, ok)
+	})
+}
+
+func TestRateLimitTraffic(t *testing.T) {
+	limits := NewRateLimits(RateLimit{}, RateLimit{BytesPerSecond: 100})
+	h := rateLimit(limits, zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write(make([]byte, 60))
+	}))
+
+	serve := func(bucket string) int {
+		// body of unknown size, e.g. chunked upload
+		r := httptest.NewRequest(http.MethodPut, "/"+bucket+"/object", io.MultiReader(strings.NewReader(strings.Repeat("a", 60))))
+		r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{BucketName: bucket}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// both request and response bytes are counted, so the first request puts the bucket in debt
+	require.Equal(t, http.StatusOK, serve("bucket"))
+	require.Equal(t, http.StatusServiceUnavailable, serve("bucket"))
+	require.Equal(t, http.StatusOK, serve("other"))
+}
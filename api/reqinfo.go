@@ -30,6 +30,7 @@ type (
 		API          string   // API name -- GetObject PutObject NewMultipartUpload etc.
 		BucketName   string   // Bucket name
 		ObjectName   string   // Object name
+		AccessKeyID  string   // Access key id the request is signed with
 		URL          *url.URL // Request url
 		tags         []KeyVal // Any additional info not accommodated by above fields
 	}
@@ -111,13 +112,6 @@ func prepareContext(w http.ResponseWriter, r *http.Request) context.Context {
 	if err != nil {
 		object = vars["object"]
 	}
-	prefix, err := url.QueryUnescape(vars["prefix"])
-	if err != nil {
-		prefix = vars["prefix"]
-	}
-	if prefix != "" {
-		object = prefix
-	}
 	return SetReqInfo(r.Context(),
 		// prepare request info
 		NewReqInfo(w, r, ObjectRequest{
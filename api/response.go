@@ -48,6 +48,9 @@ const (
 	// Response request id.
 	hdrAmzRequestID = "x-amz-request-id"
 
+	// Response host id, the same as HostId of error responses.
+	hdrAmzID2 = "x-amz-id-2"
+
 	// hdrSSE is the general AWS SSE HTTP header key.
 	hdrSSE = "X-Amz-Server-Side-Encryption"
 
@@ -117,10 +120,13 @@ func WriteErrorResponse(w http.ResponseWriter, reqInfo *ReqInfo, err error) int
 		code = e.HTTPStatusCode
 
 		switch e.Code {
-		case "SlowDown", "XNeoFSServerNotInitialized", "XNeoFSReadQuorum", "XNeoFSWriteQuorum":
-			// Set retry-after header to indicate user-agents to retry request after 120secs.
+		case "SlowDown", "ServiceUnavailable", "XNeoFSServerNotInitialized", "XNeoFSReadQuorum", "XNeoFSWriteQuorum":
+			// Set retry-after header to indicate user-agents to retry request after 120secs
+			// if it isn't set by the caller.
 			// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Retry-After
-			w.Header().Set(hdrRetryAfter, "120")
+			if w.Header().Get(hdrRetryAfter) == "" {
+				w.Header().Set(hdrRetryAfter, "120")
+			}
 		case "AccessDenied":
 			// TODO process when the request is from browser and also if browser
 		}
@@ -133,6 +139,14 @@ func WriteErrorResponse(w http.ResponseWriter, reqInfo *ReqInfo, err error) int
 	return code
 }
 
+// WriteErrorResponseNoHeader writes XML encoded error to the response body.
+// It is used when the status code and the XML prolog have already been sent.
+func WriteErrorResponseNoHeader(w http.ResponseWriter, reqInfo *ReqInfo, err error) {
+	errorResponse := getAPIErrorResponse(reqInfo, err)
+	encodedErrorResponse := EncodeResponseNoHeader(errorResponse)
+	WriteResponseBody(w, encodedErrorResponse)
+}
+
 // If none of the http routes match respond with appropriate errors.
 func errorResponseHandler(w http.ResponseWriter, r *http.Request) {
 	desc := fmt.Sprintf("Unknown API request at %s", r.URL.Path)
@@ -143,6 +157,11 @@ func errorResponseHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// If the http route matches but not its method respond with MethodNotAllowed.
+func methodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	WriteErrorResponse(w, GetReqInfo(r.Context()), errors.GetAPIError(errors.ErrMethodNotAllowed))
+}
+
 // Write http common headers.
 func setCommonHeaders(w http.ResponseWriter) {
 	w.Header().Set(hdrServerInfo, version.Server)
@@ -178,6 +197,14 @@ func WriteResponse(w http.ResponseWriter, statusCode int, response []byte, mType
 	}
 }
 
+// WriteResponseBody writes response into w without setting headers and status code.
+func WriteResponseBody(w http.ResponseWriter, response []byte) {
+	_, _ = w.Write(response)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // EncodeResponse encodes the response headers into XML format.
 func EncodeResponse(response interface{}) []byte {
 	var bytesBuffer bytes.Buffer
@@ -188,6 +215,13 @@ func EncodeResponse(response interface{}) []byte {
 	return bytesBuffer.Bytes()
 }
 
+// EncodeResponseNoHeader encodes response to XML format without XML prolog.
+func EncodeResponseNoHeader(response interface{}) []byte {
+	var bytesBuffer bytes.Buffer
+	_ = xml.NewEncoder(&bytesBuffer).Encode(response)
+	return bytesBuffer.Bytes()
+}
+
 // EncodeToResponse encodes the response into ResponseWriter.
 func EncodeToResponse(w http.ResponseWriter, response interface{}) error {
 	w.WriteHeader(http.StatusOK)
@@ -201,6 +235,16 @@ func EncodeToResponse(w http.ResponseWriter, response interface{}) error {
 	return nil
 }
 
+// EncodeToResponseNoHeader encodes the response into ResponseWriter without
+// status code and XML prolog.
+func EncodeToResponseNoHeader(w http.ResponseWriter, response interface{}) error {
+	if err := xml.NewEncoder(w).Encode(response); err != nil {
+		return fmt.Errorf("encode xml response: %w", err)
+	}
+
+	return nil
+}
+
 // // WriteSuccessResponseXML writes success headers and response if any,
 // // with content-type set to `application/xml`.
 // func WriteSuccessResponseXML(w http.ResponseWriter, response []byte) {
@@ -241,6 +285,11 @@ func getAPIErrorResponse(info *ReqInfo, err error) ErrorResponse {
 		resource = info.URL.Path
 	}
 
+	hostID := info.DeploymentID
+	if hostID == "" {
+		hostID = deploymentID.String()
+	}
+
 	return ErrorResponse{
 		Code:       code,
 		Message:    desc,
@@ -248,6 +297,6 @@ func getAPIErrorResponse(info *ReqInfo, err error) ErrorResponse {
 		Key:        info.ObjectName,
 		Resource:   resource,
 		RequestID:  info.RequestID,
-		HostID:     info.DeploymentID,
+		HostID:     hostID,
 	}
 }
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponseFields(t *testing.T) {
+	for _, tc := range []struct {
+		handler http.HandlerFunc
+		status  int
+		code    string
+	}{
+		{handler: errorResponseHandler, status: http.StatusBadRequest, code: "UnknownAPIRequest"},
+		{handler: methodNotAllowedHandler, status: http.StatusMethodNotAllowed, code: "MethodNotAllowed"},
+	} {
+		w := httptest.NewRecorder()
+		setRequestID(tc.handler).ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/bucket/object", nil))
+		require.Equal(t, tc.status, w.Code)
+
+		var resp ErrorResponse
+		require.NoError(t, xml.NewDecoder(w.Body).Decode(&resp))
+		require.Equal(t, tc.code, resp.Code)
+		require.NotEmpty(t, resp.Message)
+		require.Equal(t, "/bucket/object", resp.Resource)
+		require.Equal(t, w.Header().Get(hdrAmzRequestID), resp.RequestID)
+		require.NotEmpty(t, resp.RequestID)
+		require.Equal(t, w.Header().Get(hdrAmzID2), resp.HostID)
+		require.Equal(t, deploymentID.String(), resp.HostID)
+	}
+}
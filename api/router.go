@@ -7,8 +7,12 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/audit"
 	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
 	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/metadata"
 )
@@ -33,6 +37,12 @@ type (
 		PutObjectHandler(http.ResponseWriter, *http.Request)
 		DeleteObjectHandler(http.ResponseWriter, *http.Request)
 		GetBucketLocationHandler(http.ResponseWriter, *http.Request)
+		GetBucketUsageHandler(http.ResponseWriter, *http.Request)
+		CreateJobHandler(http.ResponseWriter, *http.Request)
+		DescribeJobHandler(http.ResponseWriter, *http.Request)
+		ListJobsHandler(http.ResponseWriter, *http.Request)
+		UpdateJobStatusHandler(http.ResponseWriter, *http.Request)
+		AssumeRoleWithWebIdentityHandler(http.ResponseWriter, *http.Request)
 		GetBucketPolicyHandler(http.ResponseWriter, *http.Request)
 		GetBucketLifecycleHandler(http.ResponseWriter, *http.Request)
 		GetBucketEncryptionHandler(http.ResponseWriter, *http.Request)
@@ -84,6 +94,39 @@ type (
 		ListMultipartUploadsHandler(http.ResponseWriter, *http.Request)
 	}
 
+	// AttachConfig contains parameters of S3 API attached by Attach. Optional
+	// middlewares are disabled if their parameters are nil.
+	AttachConfig struct {
+		// Domains are listen domains of virtual-hosted-style requests.
+		Domains []string
+		// ControlDomains are S3 Control domains serving S3 Batch Operations jobs.
+		ControlDomains []string
+		// MaxClients limits the number of concurrently processed requests.
+		MaxClients MaxClients
+		// RateLimits limits requests and traffic per access key and per bucket.
+		RateLimits *RateLimits
+		// Mode rejects requests in read-only and maintenance modes.
+		Mode *GatewayMode
+		// IPFilter rejects requests from denied source addresses.
+		IPFilter *IPFilter
+		// TrustedProxies are allowed to pass client address in forwarding headers.
+		TrustedProxies *TrustedProxies
+		// Usage accounts requests and their traffic.
+		Usage *UsageAccounting
+		// AccessLog writes records of requests.
+		AccessLog *AccessLog
+		// AuditLog writes security events.
+		AuditLog *audit.Logger
+		// SlowRequests logs requests taking too long.
+		SlowRequests *SlowRequests
+		// Handler serves S3 requests.
+		Handler Handler
+		// Center authenticates requests.
+		Center auth.Center
+		// Log is used to log errors of requests.
+		Log *zap.Logger
+	}
+
 	// mimeType represents various MIME types used in API responses.
 	mimeType string
 
@@ -115,13 +158,21 @@ func (lrw *logResponseWriter) WriteHeader(code int) {
 	})
 }
 
+// Flush calls the underlying Flush.
+func (lrw *logResponseWriter) Flush() {
+	if f, ok := lrw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func setRequestID(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// generate random UUIDv4
 		id, _ := uuid.NewRandom()
 
-		// set request id into response header
+		// set request and host ids into response header
 		w.Header().Set(hdrAmzRequestID, id.String())
+		w.Header().Set(hdrAmzID2, deploymentID.String())
 
 		// set request id into gRPC meta header
 		r = r.WithContext(metadata.AppendToOutgoingContext(
@@ -136,6 +187,35 @@ func setRequestID(h http.Handler) http.Handler {
 	})
 }
 
+// traceRequest starts a span of the request which continues the trace sent by the client.
+func traceRequest(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqInfo := GetReqInfo(r.Context())
+
+		name := "S3"
+		if route := mux.CurrentRoute(r); route != nil && route.GetName() != "" {
+			name += " " + route.GetName()
+		}
+
+		ctx, span := tracing.StartSpan(tracing.ExtractHTTP(r.Context(), r.Header), name,
+			attribute.String("http.method", r.Method),
+			attribute.String("s3.bucket", reqInfo.BucketName),
+			attribute.String("s3.object", reqInfo.ObjectName),
+			attribute.String("request_id", reqInfo.RequestID))
+		defer span.End()
+
+		lw := &logResponseWriter{ResponseWriter: w}
+		h.ServeHTTP(lw, r.WithContext(ctx))
+
+		if lw.statusCode != 0 {
+			span.SetAttributes(attribute.Int("http.status_code", lw.statusCode))
+		}
+		if lw.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(lw.statusCode))
+		}
+	})
+}
+
 func appendCORS(handler Handler) mux.MiddlewareFunc {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -183,26 +263,87 @@ func GetRequestID(v interface{}) string {
 	}
 }
 
-// Attach adds S3 API handlers from h to r for domains with m client limit using
-// center authentication and log logger.
-func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center auth.Center, log *zap.Logger) {
+// attachJobs adds S3 Control API handlers of S3 Batch Operations jobs from h to the router
+// of S3 Control host. Other requests to the host are rejected instead of being treated as
+// bucket requests.
+func attachJobs(control *mux.Router, m MaxClients, h Handler) {
+	jobs := control.PathPrefix("/v20180820/jobs").Subrouter()
+	// UpdateJobStatus
+	jobs.Methods(http.MethodPost).Path("/{jobID}/status").HandlerFunc(
+		m.Handle(metrics.APIStats("updatejobstatus", h.UpdateJobStatusHandler))).Queries("requestedJobStatus", "{requestedJobStatus}").
+		Name("UpdateJobStatus")
+	// DescribeJob
+	jobs.Methods(http.MethodGet).Path("/{jobID}").HandlerFunc(
+		m.Handle(metrics.APIStats("describejob", h.DescribeJobHandler))).
+		Name("DescribeJob")
+	// CreateJob
+	jobs.Methods(http.MethodPost).HandlerFunc(
+		m.Handle(metrics.APIStats("createjob", h.CreateJobHandler))).
+		Name("CreateJob")
+	// ListJobs
+	jobs.Methods(http.MethodGet).HandlerFunc(
+		m.Handle(metrics.APIStats("listjobs", h.ListJobsHandler))).
+		Name("ListJobs")
+
+	control.NewRoute().HandlerFunc(metrics.APIStats("notfound", errorResponseHandler))
+}
+
+// Attach adds S3 API handlers of cfg to r for listen domains of cfg. S3 Batch
+// Operations jobs are served on control domains of cfg.
+func Attach(r *mux.Router, cfg *AttachConfig) {
+	m, h, log := cfg.MaxClients, cfg.Handler, cfg.Log
+
 	api := r.PathPrefix(SlashSeparator).Subrouter()
 
 	api.Use(
 		// -- prepare request
 		setRequestID,
 
+		// -- start span of the request
+		traceRequest,
+
+		// -- resolve client address behind trusted proxies
+		resolveSourceIP(cfg.TrustedProxies),
+
+		// -- log slow requests with time of their phases
+		logSlowRequests(cfg.SlowRequests),
+
+		// -- write access log records
+		accessLogging(cfg.AccessLog),
+
 		// -- logging error requests
 		logErrorResponse(log),
+
+		// -- reject requests from denied source addresses
+		filterIP(cfg.IPFilter, cfg.TrustedProxies, cfg.AuditLog, log),
+
+		// -- reject requests in read-only and maintenance modes
+		checkMode(cfg.Mode, log),
 	)
 
 	// Attach user authentication for all S3 routes.
-	AttachUserAuth(api, center, log)
+	AttachUserAuth(api, cfg.Center, cfg.AuditLog, log)
+
+	// Denials by bucket policies and ACLs and changes of them are audited after authentication.
+	api.Use(auditRequests(cfg.AuditLog, log))
 
-	buckets := make([]*mux.Router, 0, len(domains)+1)
+	// Rate limits are checked after authentication to be applied per access key.
+	api.Use(rateLimit(cfg.RateLimits, log))
+
+	// Usage is accounted after authentication to be collected per access key.
+	api.Use(accountUsage(cfg.Usage))
+
+	// S3 Batch Operations jobs are served on S3 Control hosts only, so their paths aren't
+	// confused with buckets. They must be attached before bucket routes of listen domains.
+	for _, domain := range cfg.ControlDomains {
+		attachJobs(api.Host(domain).Subrouter(), m, h)
+		attachJobs(api.Host("{account:[^.]+}."+domain).Subrouter(), m, h)
+	}
+
+	buckets := make([]*mux.Router, 0, len(cfg.Domains)+1)
 	buckets = append(buckets, api.PathPrefix("/{bucket}").Subrouter())
 
-	for _, domain := range domains {
+	for _, domain := range cfg.Domains {
 		buckets = append(buckets, api.Host("{bucket:.+}."+domain).Subrouter())
 	}
 
@@ -309,6 +450,10 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 		bucket.Methods(http.MethodGet).HandlerFunc(
 			m.Handle(metrics.APIStats("getbucketlocation", h.GetBucketLocationHandler))).Queries("location", "").
 			Name("GetBucketLocation")
+		// GetBucketUsage
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("getbucketusage", h.GetBucketUsageHandler))).Queries("usage", "").
+			Name("GetBucketUsage")
 		// GetBucketPolicy
 		bucket.Methods(http.MethodGet).HandlerFunc(
 			m.Handle(metrics.APIStats("getbucketpolicy", h.GetBucketPolicyHandler))).Queries("policy", "").
@@ -483,7 +628,13 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 		m.Handle(metrics.APIStats("listbuckets", h.ListBucketsHandler))).
 		Name("ListBuckets")
 
-	// If none of the routes match, add default error handler routes
-	api.NotFoundHandler = metrics.APIStats("notfound", errorResponseHandler)
-	api.MethodNotAllowedHandler = metrics.APIStats("methodnotallowed", errorResponseHandler)
+	// STS AssumeRoleWithWebIdentity
+	api.Methods(http.MethodPost).Path(SlashSeparator).HeadersRegexp(hdrContentType, "application/x-www-form-urlencoded*").HandlerFunc(
+		m.Handle(metrics.APIStats("assumerolewithwebidentity", h.AssumeRoleWithWebIdentityHandler))).
+		Name("AssumeRoleWithWebIdentity")
+
+	// If none of the routes match, add default error handler routes.
+	// Middlewares aren't applied to them, so request info is prepared here.
+	api.NotFoundHandler = metrics.APIStats("notfound", setRequestID(http.HandlerFunc(errorResponseHandler)).ServeHTTP)
+	api.MethodNotAllowedHandler = metrics.APIStats("methodnotallowed", setRequestID(http.HandlerFunc(methodNotAllowedHandler)).ServeHTTP)
 }
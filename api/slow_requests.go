@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/phases"
+	"go.uber.org/zap"
+)
+
+// SlowRequests logs requests taking longer than the threshold along with the time
+// spent in authentication, tree service calls and storage operations.
+// The threshold can be updated at runtime, zero threshold disables logging.
+type SlowRequests struct {
+	log       *zap.Logger
+	threshold int64
+}
+
+// NewSlowRequests creates SlowRequests logging to log.
+func NewSlowRequests(log *zap.Logger, threshold time.Duration) *SlowRequests {
+	s := &SlowRequests{log: log}
+	s.Update(threshold)
+	return s
+}
+
+// Update replaces the threshold.
+func (s *SlowRequests) Update(threshold time.Duration) {
+	atomic.StoreInt64(&s.threshold, int64(threshold))
+}
+
+// Threshold returns the current threshold.
+func (s *SlowRequests) Threshold() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.threshold))
+}
+
+// logSlowRequests measures phases of requests and logs the slow ones.
+func logSlowRequests(s *SlowRequests) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		if s == nil {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx, durations := phases.WithDurations(r.Context())
+			lw := &logResponseWriter{ResponseWriter: w}
+
+			h.ServeHTTP(lw, r.WithContext(ctx))
+
+			elapsed := time.Since(start)
+			threshold := s.Threshold()
+			if threshold <= 0 || elapsed < threshold {
+				return
+			}
+
+			reqInfo := GetReqInfo(r.Context())
+			metrics.IncSlowRequests(reqInfo.API, reqInfo.BucketName)
+
+			if lw.statusCode == 0 {
+				lw.statusCode = http.StatusOK
+			}
+
+			fields := []zap.Field{
+				zap.String("request_id", reqInfo.RequestID),
+				zap.String("api", reqInfo.API),
+				zap.String("bucket", reqInfo.BucketName),
+				zap.String("object", reqInfo.ObjectName),
+				zap.String("access_key_id", reqInfo.AccessKeyID),
+				zap.String("method", r.Method),
+				zap.Int("status", lw.statusCode),
+				zap.Duration("duration", elapsed),
+			}
+			for _, phase := range phases.All() {
+				fields = append(fields, zap.Duration(phase.String(), durations.Get(phase)))
+			}
+
+			s.log.Warn("slow request", fields...)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/internal/phases"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSlowRequests(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	slow := NewSlowRequests(zap.New(core), time.Hour)
+
+	h := logSlowRequests(slow)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		phases.Observe(r.Context(), phases.Storage, time.Now().Add(-time.Second))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func() {
+		r := httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+		r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{API: "GetObject", BucketName: "bucket"}))
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	serve()
+	require.Zero(t, logs.Len())
+
+	slow.Update(time.Nanosecond)
+	serve()
+	require.Equal(t, 1, logs.Len())
+
+	fields := logs.All()[0].ContextMap()
+	require.Equal(t, "GetObject", fields["api"])
+	require.EqualValues(t, http.StatusNoContent, fields["status"])
+	require.GreaterOrEqual(t, fields["storage"], time.Second)
+
+	slow.Update(0)
+	serve()
+	require.Equal(t, 1, logs.Len())
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// TrustedProxies is a set of networks of proxies which are allowed to pass the address
+// of the client in X-Forwarded-For, X-Real-IP and Forwarded headers. It can be updated at runtime.
+type TrustedProxies struct {
+	mu       sync.RWMutex
+	networks []*net.IPNet
+}
+
+// NewTrustedProxies creates a set of trusted proxies from networks in CIDR notation or single addresses.
+func NewTrustedProxies(cidrs []string) (*TrustedProxies, error) {
+	p := &TrustedProxies{}
+	if err := p.Update(cidrs); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Update replaces trusted proxies. They aren't changed if any of the new networks is invalid.
+func (p *TrustedProxies) Update(cidrs []string) error {
+	networks, err := parseNetworks(cidrs)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.networks = networks
+	p.mu.Unlock()
+
+	return nil
+}
+
+// SourceIP returns the address of the client which sent the request.
+//
+// The headers are used only if the request is received from a trusted proxy, so
+// if no proxies are configured, the address of the connection is returned.
+// X-Forwarded-For is processed from right to left skipping trusted proxies, so
+// the addresses added by the client itself are ignored.
+func (p *TrustedProxies) SourceIP(r *http.Request) string {
+	peer := peerIP(r)
+	if p == nil {
+		return peer
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.trusted(peer) {
+		return peer
+	}
+
+	if fwd := r.Header.Values(xForwardedFor); len(fwd) > 0 {
+		addrs := strings.Split(strings.Join(fwd, ","), ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			addr := strings.TrimSpace(addrs[i])
+			if addr == "" {
+				continue
+			}
+			if i == 0 || !p.trusted(addr) {
+				return addr
+			}
+		}
+	}
+
+	if addr := GetSourceIP(r); addr != "" {
+		return addr
+	}
+
+	return peer
+}
+
+func (p *TrustedProxies) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && containsIP(p.networks, ip)
+}
+
+// resolveSourceIP sets the address of the client in request info according to the trusted proxies.
+func resolveSourceIP(proxies *TrustedProxies) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			GetReqInfo(r.Context()).RemoteHost = proxies.SourceIP(r)
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	newRequest := func(remoteAddr string, headers map[string]string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		return r
+	}
+
+	t.Run("no trusted proxies", func(t *testing.T) {
+		var proxies *TrustedProxies
+		r := newRequest("10.0.0.1:1234", map[string]string{xForwardedFor: "192.168.0.1, 10.0.0.2"})
+		require.Equal(t, "10.0.0.1", proxies.SourceIP(r))
+
+		proxies, err := NewTrustedProxies(nil)
+		require.NoError(t, err)
+		require.Equal(t, "10.0.0.1", proxies.SourceIP(r))
+	})
+
+	proxies, err := NewTrustedProxies([]string{"10.0.0.0/24", "172.16.0.1"})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		sourceIP   string
+	}{
+		{
+			name:       "untrusted peer",
+			remoteAddr: "192.168.0.1:1234",
+			headers:    map[string]string{xForwardedFor: "1.1.1.1", xRealIP: "1.1.1.1"},
+			sourceIP:   "192.168.0.1",
+		},
+		{
+			name:       "trusted peer without headers",
+			remoteAddr: "10.0.0.1:1234",
+			sourceIP:   "10.0.0.1",
+		},
+		{
+			name:       "forwarded for",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{xForwardedFor: "1.1.1.1"},
+			sourceIP:   "1.1.1.1",
+		},
+		{
+			name:       "spoofed forwarded for",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{xForwardedFor: "8.8.8.8, 1.1.1.1, 172.16.0.1"},
+			sourceIP:   "1.1.1.1",
+		},
+		{
+			name:       "all forwarders are trusted",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{xForwardedFor: "10.0.0.2, 172.16.0.1"},
+			sourceIP:   "10.0.0.2",
+		},
+		{
+			name:       "real ip",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{xRealIP: "1.1.1.1"},
+			sourceIP:   "1.1.1.1",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.sourceIP, proxies.SourceIP(newRequest(tc.remoteAddr, tc.headers)))
+		})
+	}
+
+	_, err = NewTrustedProxies([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+}
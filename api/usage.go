@@ -0,0 +1,200 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+)
+
+type (
+	// UsageRecord contains the number of requests to the bucket made with the access key
+	// and traffic of the requests. Access key is empty for anonymous requests.
+	UsageRecord struct {
+		Bucket      string `json:"bucket"`
+		AccessKeyID string `json:"access_key_id"`
+		Requests    uint64 `json:"requests"`
+		BytesIn     uint64 `json:"bytes_in"`
+		BytesOut    uint64 `json:"bytes_out"`
+	}
+
+	// UsageAccounting collects requests and traffic per bucket and access key.
+	UsageAccounting struct {
+		since time.Time
+
+		mu      sync.RWMutex
+		records map[usageKey]*UsageRecord
+	}
+
+	usageKey struct {
+		bucket      string
+		accessKeyID string
+	}
+
+	// countingReader counts bytes read from the request body.
+	countingReader struct {
+		io.ReadCloser
+		count uint64
+	}
+
+	// countingResponseWriter counts bytes of the response body and remembers its status code.
+	countingResponseWriter struct {
+		http.ResponseWriter
+		count      uint64
+		statusCode int
+	}
+)
+
+// NewUsageAccounting creates empty UsageAccounting.
+func NewUsageAccounting() *UsageAccounting {
+	return &UsageAccounting{since: time.Now().UTC(), records: make(map[usageKey]*UsageRecord)}
+}
+
+// Since returns the time the usage is accounted from.
+func (u *UsageAccounting) Since() time.Time {
+	return u.since
+}
+
+// Add accounts a request to the bucket made with the access key.
+func (u *UsageAccounting) Add(bucket, accessKeyID string, bytesIn, bytesOut uint64) {
+	key := usageKey{bucket: bucket, accessKeyID: accessKeyID}
+
+	u.mu.Lock()
+	rec, ok := u.records[key]
+	if !ok {
+		rec = &UsageRecord{Bucket: bucket, AccessKeyID: accessKeyID}
+		u.records[key] = rec
+	}
+	rec.Requests++
+	rec.BytesIn += bytesIn
+	rec.BytesOut += bytesOut
+	u.mu.Unlock()
+
+	metrics.AddUsage(bucket, accessKeyID, bytesIn, bytesOut)
+}
+
+// Records returns the usage accounted since the start of the gateway
+// sorted by bucket and access key.
+func (u *UsageAccounting) Records() []UsageRecord {
+	u.mu.RLock()
+	res := make([]UsageRecord, 0, len(u.records))
+	for _, rec := range u.records {
+		res = append(res, *rec)
+	}
+	u.mu.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Bucket != res[j].Bucket {
+			return res[i].Bucket < res[j].Bucket
+		}
+		return res[i].AccessKeyID < res[j].AccessKeyID
+	})
+
+	return res
+}
+
+// Bucket returns the usage of the bucket summed over all access keys.
+func (u *UsageAccounting) Bucket(bucket string) UsageRecord {
+	res := UsageRecord{Bucket: bucket}
+	if u == nil {
+		return res
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	for key, rec := range u.records {
+		if key.bucket == bucket {
+			res.Requests += rec.Requests
+			res.BytesIn += rec.BytesIn
+			res.BytesOut += rec.BytesOut
+		}
+	}
+
+	return res
+}
+
+// UsageDiff returns the usage accounted between prev and cur snapshots obtained
+// by Records. Records without requests in the period are omitted.
+func UsageDiff(prev, cur []UsageRecord) []UsageRecord {
+	before := make(map[usageKey]UsageRecord, len(prev))
+	for _, rec := range prev {
+		before[usageKey{bucket: rec.Bucket, accessKeyID: rec.AccessKeyID}] = rec
+	}
+
+	res := make([]UsageRecord, 0, len(cur))
+	for _, rec := range cur {
+		old := before[usageKey{bucket: rec.Bucket, accessKeyID: rec.AccessKeyID}]
+		if rec.Requests == old.Requests {
+			continue
+		}
+		rec.Requests -= old.Requests
+		rec.BytesIn -= old.BytesIn
+		rec.BytesOut -= old.BytesOut
+		res = append(res, rec)
+	}
+
+	return res
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	atomic.AddUint64(&r.count, uint64(n))
+	return n, err
+}
+
+// WriteHeader remembers the status code and writes it.
+func (w *countingResponseWriter) WriteHeader(code int) {
+	if w.statusCode == 0 {
+		w.statusCode = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *countingResponseWriter) Write(p []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	atomic.AddUint64(&w.count, uint64(n))
+	return n, err
+}
+
+// Flush calls the underlying Flush.
+func (w *countingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// accountUsage counts requests and their traffic per bucket and access key, failed requests
+// are accounted with empty bucket. Usage isn't accounted if it's nil.
+func accountUsage(usage *UsageAccounting) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		if usage == nil {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			in := &countingReader{ReadCloser: r.Body}
+			out := &countingResponseWriter{ResponseWriter: w}
+			r.Body = in
+
+			h.ServeHTTP(out, r)
+
+			// failed requests may address missing buckets, they aren't accounted per bucket
+			// to keep the number of records limited by the existing buckets
+			bucket := GetReqInfo(r.Context()).BucketName
+			if out.statusCode >= http.StatusBadRequest {
+				bucket = ""
+			}
+
+			usage.Add(bucket, GetAccessKeyID(r.Context()), atomic.LoadUint64(&in.count), atomic.LoadUint64(&out.count))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageAccounting(t *testing.T) {
+	usage := NewUsageAccounting()
+
+	usage.Add("bucket", "key1", 10, 100)
+	usage.Add("bucket", "key1", 5, 0)
+	usage.Add("bucket", "", 0, 20)
+	usage.Add("another", "key2", 1, 1)
+
+	prev := usage.Records()
+	require.Equal(t, []UsageRecord{
+		{Bucket: "another", AccessKeyID: "key2", Requests: 1, BytesIn: 1, BytesOut: 1},
+		{Bucket: "bucket", AccessKeyID: "", Requests: 1, BytesIn: 0, BytesOut: 20},
+		{Bucket: "bucket", AccessKeyID: "key1", Requests: 2, BytesIn: 15, BytesOut: 100},
+	}, prev)
+
+	require.Equal(t, UsageRecord{Bucket: "bucket", Requests: 3, BytesIn: 15, BytesOut: 120}, usage.Bucket("bucket"))
+	require.Equal(t, UsageRecord{Bucket: "unknown"}, usage.Bucket("unknown"))
+
+	usage.Add("bucket", "key1", 1, 2)
+	usage.Add("new", "key1", 3, 4)
+
+	require.Equal(t, []UsageRecord{
+		{Bucket: "bucket", AccessKeyID: "key1", Requests: 1, BytesIn: 1, BytesOut: 2},
+		{Bucket: "new", AccessKeyID: "key1", Requests: 1, BytesIn: 3, BytesOut: 4},
+	}, UsageDiff(prev, usage.Records()))
+}
+
+func TestAccountUsage(t *testing.T) {
+	usage := NewUsageAccounting()
+
+	h := accountUsage(usage)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.Copy(io.Discard, r.Body)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("response"))
+		require.NoError(t, err)
+	}))
+
+	r := httptest.NewRequest(http.MethodPut, "/bucket/object", strings.NewReader("payload"))
+	ctx := SetReqInfo(r.Context(), &ReqInfo{BucketName: "bucket"})
+	ctx = context.WithValue(ctx, AccessKeyID, "key")
+	h.ServeHTTP(httptest.NewRecorder(), r.WithContext(ctx))
+
+	require.Equal(t, []UsageRecord{
+		{Bucket: "bucket", AccessKeyID: "key", Requests: 1, BytesIn: 7, BytesOut: 8},
+	}, usage.Records())
+
+	h = accountUsage(usage)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	r = httptest.NewRequest(http.MethodGet, "/missing-bucket", nil)
+	ctx = SetReqInfo(r.Context(), &ReqInfo{BucketName: "missing-bucket"})
+	ctx = context.WithValue(ctx, AccessKeyID, "key")
+	h.ServeHTTP(httptest.NewRecorder(), r.WithContext(ctx))
+
+	require.Equal(t, []UsageRecord{
+		{Bucket: "", AccessKeyID: "key", Requests: 1},
+		{Bucket: "bucket", AccessKeyID: "key", Requests: 1, BytesIn: 7, BytesOut: 8},
+	}, usage.Records())
+
+	// nil accounting doesn't wrap handler
+	accountUsage(nil)(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -3,10 +3,15 @@ package api
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/audit"
 	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/phases"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"go.uber.org/zap"
 )
 
@@ -19,18 +24,51 @@ var BoxData = KeyWrapper("__context_box_key")
 // ClientTime is an ID used to store client time.Time in a context.
 var ClientTime = KeyWrapper("__context_client_time")
 
+// Principal is an ID used to store the request sender in a context.
+var Principal = KeyWrapper("__context_principal")
+
+// AccessKeyID is an ID used to store access key id of the request in a context.
+var AccessKeyID = KeyWrapper("__context_access_key_id")
+
+// AnonymousPrincipal is the sender of requests without authentication.
+const AnonymousPrincipal = "anonymous"
+
+// GetPrincipal returns the request sender from a context. Empty string is
+// returned if the request wasn't passed through user authentication.
+func GetPrincipal(ctx context.Context) string {
+	principal, _ := ctx.Value(Principal).(string)
+	return principal
+}
+
+// GetAccessKeyID returns access key id the request is signed with from a context.
+// Empty string is returned for anonymous requests.
+func GetAccessKeyID(ctx context.Context) string {
+	accessKeyID, _ := ctx.Value(AccessKeyID).(string)
+	return accessKeyID
+}
+
 // AttachUserAuth adds user authentication via center to router using log for logging.
-func AttachUserAuth(router *mux.Router, center auth.Center, log *zap.Logger) {
+// Authentication failures and uses of credentials are written to auditLog.
+func AttachUserAuth(router *mux.Router, center auth.Center, auditLog *audit.Logger, log *zap.Logger) {
 	router.Use(func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var ctx context.Context
-			box, err := center.Authenticate(r)
+			authStart := time.Now()
+			authCtx, span := tracing.StartSpan(r.Context(), "auth")
+			box, err := center.Authenticate(r.WithContext(authCtx))
+			phases.Observe(r.Context(), phases.Auth, authStart)
+			if err == auth.ErrNoAuthorizationHeader {
+				span.End()
+			} else {
+				tracing.EndSpan(span, err)
+			}
 			if err != nil {
 				if err == auth.ErrNoAuthorizationHeader {
 					log.Debug("couldn't receive access box for gate key, random key will be used")
-					ctx = r.Context()
+					ctx = context.WithValue(r.Context(), Principal, AnonymousPrincipal)
 				} else {
 					log.Error("failed to pass authentication", zap.Error(err))
+					writeAudit(auditLog, log, auditRecord(r, audit.EventAuthFailure, err.Error()))
 					if _, ok := err.(errors.Error); !ok {
 						err = errors.GetAPIError(errors.ErrAccessDenied)
 					}
@@ -38,7 +76,21 @@ func AttachUserAuth(router *mux.Router, center auth.Center, log *zap.Logger) {
 					return
 				}
 			} else {
+				if box.AccessBox.InlinePolicy != nil && !checkInlinePolicy(r, box.AccessBox.InlinePolicy) {
+					log.Debug("request is denied by inline policy of access box", zap.String("api", GetReqInfo(r.Context()).API))
+					GetReqInfo(r.Context()).AccessKeyID = box.AccessKeyID
+					writeAudit(auditLog, log, auditRecord(r, audit.EventAccessDenied, "denied by inline policy of access box"))
+					WriteErrorResponse(w, GetReqInfo(r.Context()), errors.GetAPIError(errors.ErrAccessDenied))
+					return
+				}
+
 				ctx = context.WithValue(r.Context(), BoxData, box.AccessBox)
+				ctx = context.WithValue(ctx, AccessKeyID, box.AccessKeyID)
+				GetReqInfo(ctx).AccessKeyID = box.AccessKeyID
+				writeAudit(auditLog, log, auditRecord(r, audit.EventCredentialUse, ""))
+				if box.AccessBox.Gate != nil && box.AccessBox.Gate.BearerToken != nil {
+					ctx = context.WithValue(ctx, Principal, bearer.ResolveIssuer(*box.AccessBox.Gate.BearerToken).EncodeToString())
+				}
 				if !box.ClientTime.IsZero() {
 					ctx = context.WithValue(ctx, ClientTime, box.ClientTime)
 				}
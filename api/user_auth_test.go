@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type centerMock struct{}
+
+func (centerMock) Authenticate(*http.Request) (*auth.Box, error) {
+	return nil, auth.ErrNoAuthorizationHeader
+}
+
+func (centerMock) AccessKeyOwner(context.Context, string) (user.ID, error) {
+	return user.ID{}, auth.ErrNoAuthorizationHeader
+}
+
+func TestAttachUserAuthAnonymous(t *testing.T) {
+	router := mux.NewRouter()
+	AttachUserAuth(router, centerMock{}, nil, zap.NewNop())
+
+	var principal string
+	var box *accessbox.Box
+	router.HandleFunc("/bucket/object", func(w http.ResponseWriter, r *http.Request) {
+		principal = GetPrincipal(r.Context())
+		box, _ = r.Context().Value(BoxData).(*accessbox.Box)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/bucket/object", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, AnonymousPrincipal, principal)
+	require.Nil(t, box)
+}
@@ -1,6 +1,7 @@
 package authmate
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
@@ -9,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -62,7 +64,7 @@ type NeoFS interface {
 	// It sets 'Timestamp' attribute to the current time.
 	// It returns the ID of the saved container.
 	//
-	// The container must be private with GET access for OTHERS group.
+	// The container must be private with GET and SEARCH access for OTHERS group.
 	// Creation time should also be stamped.
 	//
 	// It returns exactly one non-nil value. It returns any error encountered which
@@ -76,6 +78,11 @@ type NeoFS interface {
 	//
 	// It returns any error encountered which prevented computing epochs.
 	TimeToEpoch(context.Context, time.Time) (uint64, uint64, error)
+
+	// DeleteObject marks the object to be removed from NeoFS.
+	//
+	// It returns any error encountered which prevented the removal request from being sent.
+	DeleteObject(context.Context, oid.Address) error
 }
 
 // Agent contains client communicating with NeoFS and logger.
@@ -104,6 +111,11 @@ type (
 		Lifetime              time.Duration
 		AwsCliCredentialsFile string
 		ContainerPolicies     ContainerPolicies
+		InlinePolicy          []byte
+		// AccessKeyID and SecretAccessKey are the imported credentials.
+		// They are generated if empty.
+		AccessKeyID     string
+		SecretAccessKey string
 	}
 
 	// ContainerOptions groups parameters of auth container to put the secret into.
@@ -118,6 +130,37 @@ type (
 		SecretAddress  string
 		GatePrivateKey *keys.PrivateKey
 	}
+
+	// UpdateSecretOptions contains options for passing to Agent.UpdateSecret method.
+	UpdateSecretOptions struct {
+		SecretAddress  string
+		NeoFSKey       *keys.PrivateKey
+		GatePrivateKey *keys.PrivateKey
+		// GracePeriod is a time the previous secret remains valid after rotation.
+		GracePeriod time.Duration
+	}
+
+	// RenewSecretOptions contains options for passing to Agent.RenewSecret method.
+	RenewSecretOptions struct {
+		SecretAddress  string
+		NeoFSKey       *keys.PrivateKey
+		GatePrivateKey *keys.PrivateKey
+		Lifetime       time.Duration
+	}
+
+	// ListSecretsOptions contains options for passing to Agent.ListSecrets method.
+	ListSecretsOptions struct {
+		Container cid.ID
+		// Owner is an issuer of the secrets. Secrets of all issuers are listed if nil.
+		Owner *user.ID
+		// GatePublicKey is a key of the gate the secrets are issued for. Secrets of all gates are listed if nil.
+		GatePublicKey *keys.PublicKey
+	}
+
+	// RevokeSecretOptions contains options for passing to Agent.RevokeSecret method.
+	RevokeSecretOptions struct {
+		SecretAddress string
+	}
 )
 
 // lifetimeOptions holds NeoFS epochs, iat -- epoch which the token was issued at, exp -- epoch when the token expires.
@@ -128,11 +171,33 @@ type lifetimeOptions struct {
 
 type (
 	issuingResult struct {
+		AccessKeyID       string `json:"access_key_id"`
+		NativeAccessKeyID string `json:"native_access_key_id,omitempty"`
+		SecretAccessKey   string `json:"secret_access_key"`
+		OwnerPrivateKey   string `json:"owner_private_key"`
+		WalletPublicKey   string `json:"wallet_public_key"`
+		ContainerID       string `json:"container_id"`
+	}
+
+	updatingResult struct {
 		AccessKeyID     string `json:"access_key_id"`
 		SecretAccessKey string `json:"secret_access_key"`
-		OwnerPrivateKey string `json:"owner_private_key"`
-		WalletPublicKey string `json:"wallet_public_key"`
-		ContainerID     string `json:"container_id"`
+	}
+
+	renewingResult struct {
+		AccessKeyID     string `json:"access_key_id"`
+		ExpirationEpoch uint64 `json:"expiration_epoch"`
+	}
+
+	listingResult struct {
+		AccessKeyID       string   `json:"access_key_id"`
+		NativeAccessKeyID string   `json:"native_access_key_id,omitempty"`
+		GatesPublicKeys   []string `json:"gates_public_keys"`
+	}
+
+	revokingResult struct {
+		AccessKeyID    string `json:"access_key_id"`
+		RemovedObjects int    `json:"removed_objects"`
 	}
 
 	obtainingResult struct {
@@ -141,6 +206,45 @@ type (
 	}
 )
 
+// checkImportedCredentials checks that both access key id and secret are imported and the access key id
+// can't be confused with the ones of generated credentials.
+func checkImportedCredentials(accessKeyID, secretAccessKey string) error {
+	if (accessKeyID == "") != (secretAccessKey == "") {
+		return errors.New("both access key id and secret access key must be provided")
+	}
+	if accessKeyID == "" {
+		return nil
+	}
+
+	if strings.ContainsAny(accessKeyID, "/ ") {
+		return fmt.Errorf("access key id '%s' contains '/' or space", accessKeyID)
+	}
+
+	var addr oid.Address
+	if addr.DecodeString(strings.Replace(accessKeyID, "0", "/", 1)) == nil {
+		return fmt.Errorf("access key id '%s' is reserved for generated credentials", accessKeyID)
+	}
+
+	return nil
+}
+
+// checkAccessKeyIDUnique checks that the container has no access box with the imported access key id.
+func (a *Agent) checkAccessKeyIDUnique(ctx context.Context, idCnr cid.ID, accessKeyID string) error {
+	ids, err := a.neoFS.SearchObjects(ctx, tokens.PrmObjectSearch{
+		Container: idCnr,
+		Attribute: tokens.AttributeAccessKeyID,
+		Value:     accessKeyID,
+	})
+	if err != nil {
+		return fmt.Errorf("search access boxes: %w", err)
+	}
+	if len(ids) != 0 {
+		return fmt.Errorf("%w: '%s'", tokens.ErrAccessKeyIDExists, accessKeyID)
+	}
+
+	return nil
+}
+
 func (a *Agent) checkContainer(ctx context.Context, opts ContainerOptions, idOwner user.ID) (cid.ID, error) {
 	if !opts.ID.Equals(cid.ID{}) {
 		return opts.ID, a.neoFS.ContainerExists(ctx, opts.ID)
@@ -213,22 +317,39 @@ func (a *Agent) IssueSecret(ctx context.Context, w io.Writer, options *IssueSecr
 		return fmt.Errorf("prepare policies: %w", err)
 	}
 
+	if len(options.InlinePolicy) != 0 {
+		if _, err = accessbox.ParseInlinePolicy(options.InlinePolicy); err != nil {
+			return fmt.Errorf("invalid inline policy: %w", err)
+		}
+	}
+
 	lifetime.Iat, lifetime.Exp, err = a.neoFS.TimeToEpoch(ctx, time.Now().Add(options.Lifetime))
 	if err != nil {
 		return fmt.Errorf("fetch time to epoch: %w", err)
 	}
 
+	if err = checkImportedCredentials(options.AccessKeyID, options.SecretAccessKey); err != nil {
+		return fmt.Errorf("invalid imported credentials: %w", err)
+	}
+
 	gatesData, err := createTokens(options, lifetime)
 	if err != nil {
 		return fmt.Errorf("create tokens: %w", err)
 	}
 
-	box, secrets, err := accessbox.PackTokens(gatesData)
+	var secrets *accessbox.Secrets
+	if options.SecretAccessKey != "" {
+		box, secrets, err = accessbox.PackTokensWithSecret(gatesData, options.SecretAccessKey)
+	} else {
+		box, secrets, err = accessbox.PackTokens(gatesData)
+	}
 	if err != nil {
 		return fmt.Errorf("pack tokens: %w", err)
 	}
 
 	box.ContainerPolicy = policies
+	box.InlinePolicy = options.InlinePolicy
+	box.AccessKeyId = options.AccessKeyID
 
 	var idOwner user.ID
 	user.IDFromKey(&idOwner, options.NeoFSKey.PrivateKey.PublicKey)
@@ -241,6 +362,12 @@ func (a *Agent) IssueSecret(ctx context.Context, w io.Writer, options *IssueSecr
 		return fmt.Errorf("check container: %w", err)
 	}
 
+	if options.AccessKeyID != "" {
+		if err = a.checkAccessKeyIDUnique(ctx, id, options.AccessKeyID); err != nil {
+			return err
+		}
+	}
+
 	a.log.Info("store bearer token into NeoFS",
 		zap.Stringer("owner_tkn", idOwner))
 
@@ -264,6 +391,12 @@ func (a *Agent) IssueSecret(ctx context.Context, w io.Writer, options *IssueSecr
 		ContainerID:     id.EncodeToString(),
 	}
 
+	if options.AccessKeyID != "" {
+		ir.NativeAccessKeyID = accessKeyID
+		ir.AccessKeyID = options.AccessKeyID
+		accessKeyID = options.AccessKeyID
+	}
+
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	if err = enc.Encode(ir); err != nil {
@@ -313,6 +446,248 @@ func (a *Agent) ObtainSecret(ctx context.Context, w io.Writer, options *ObtainSe
 	return enc.Encode(or)
 }
 
+// UpdateSecret generates a new secret of the existing access key and puts the new version of
+// the access box with it into NeoFS. Then writes to io.Writer the new secret access key.
+// The access key id remains the same.
+func (a *Agent) UpdateSecret(ctx context.Context, w io.Writer, options *UpdateSecretOptions) error {
+	var addr oid.Address
+	if err := addr.DecodeString(options.SecretAddress); err != nil {
+		return fmt.Errorf("failed to parse secret address: %w", err)
+	}
+
+	bearerCreds := tokens.New(a.neoFS, options.GatePrivateKey, cache.DefaultAccessBoxConfig(a.log))
+	box, err := bearerCreds.GetBox(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("failed to get tokens: %w", err)
+	}
+
+	newBox, secrets, err := accessbox.PackTokens([]*accessbox.GateData{{
+		BearerToken:   box.Gate.BearerToken,
+		SessionTokens: box.Gate.SessionTokens,
+		GateKey:       options.GatePrivateKey.PublicKey(),
+	}})
+	if err != nil {
+		return fmt.Errorf("pack tokens: %w", err)
+	}
+
+	for _, policy := range box.Policies {
+		newBox.ContainerPolicy = append(newBox.ContainerPolicy, &accessbox.AccessBox_ContainerPolicy{
+			LocationConstraint: policy.LocationConstraint,
+			Policy:             policy.Policy.Marshal(),
+		})
+	}
+
+	if box.InlinePolicy != nil {
+		if newBox.InlinePolicy, err = json.Marshal(box.InlinePolicy); err != nil {
+			return fmt.Errorf("marshal inline policy: %w", err)
+		}
+	}
+
+	if options.GracePeriod > 0 {
+		newBox.GraceExpiration = time.Now().Add(options.GracePeriod).Unix()
+	}
+
+	// the new version must not expire before the tokens, otherwise the previous secret becomes valid again
+	expiration := box.Gate.BearerTokenExpiration()
+
+	var idOwner user.ID
+	user.IDFromKey(&idOwner, options.NeoFSKey.PrivateKey.PublicKey)
+
+	a.log.Info("store updated access box into NeoFS",
+		zap.Stringer("owner_tkn", idOwner),
+		zap.Stringer("address", addr))
+
+	if _, err = bearerCreds.Update(ctx, addr, idOwner, newBox, expiration, options.GatePrivateKey.PublicKey()); err != nil {
+		return fmt.Errorf("failed to update access box: %w", err)
+	}
+
+	ur := &updatingResult{
+		AccessKeyID:     addr.Container().EncodeToString() + "0" + addr.Object().EncodeToString(),
+		SecretAccessKey: secrets.AccessKey,
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ur)
+}
+
+// RenewSecret issues new tokens of the access box with the extended lifetime and
+// stores them as a new version of the box. The secret access key isn't changed.
+func (a *Agent) RenewSecret(ctx context.Context, w io.Writer, options *RenewSecretOptions) error {
+	var addr oid.Address
+	if err := addr.DecodeString(options.SecretAddress); err != nil {
+		return fmt.Errorf("failed to parse secret address: %w", err)
+	}
+
+	bearerCreds := tokens.New(a.neoFS, options.GatePrivateKey, cache.DefaultAccessBoxConfig(a.log))
+	box, err := bearerCreds.GetBox(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("failed to get tokens: %w", err)
+	}
+
+	var lifetime lifetimeOptions
+	lifetime.Iat, lifetime.Exp, err = a.neoFS.TimeToEpoch(ctx, time.Now().Add(options.Lifetime))
+	if err != nil {
+		return fmt.Errorf("fetch time to epoch: %w", err)
+	}
+
+	gateKey := options.GatePrivateKey.PublicKey()
+	table := box.Gate.BearerToken.EACLTable()
+	bearerToken, err := buildBearerToken(options.NeoFSKey, &table, lifetime, gateKey)
+	if err != nil {
+		return fmt.Errorf("failed to build bearer token: %w", err)
+	}
+
+	gateData := accessbox.NewGateData(gateKey, bearerToken)
+	for _, tok := range box.Gate.SessionTokens {
+		sessionCtx, err := tokenContext(tok)
+		if err != nil {
+			return fmt.Errorf("failed to get session token context: %w", err)
+		}
+
+		sessionToken, err := buildSessionToken(options.NeoFSKey, lifetime, sessionCtx, gateKey)
+		if err != nil {
+			return fmt.Errorf("failed to build session token: %w", err)
+		}
+		gateData.SessionTokens = append(gateData.SessionTokens, sessionToken)
+	}
+
+	newBox, _, err := accessbox.PackTokensWithSecret([]*accessbox.GateData{gateData}, box.Gate.AccessKey)
+	if err != nil {
+		return fmt.Errorf("pack tokens: %w", err)
+	}
+
+	for _, policy := range box.Policies {
+		newBox.ContainerPolicy = append(newBox.ContainerPolicy, &accessbox.AccessBox_ContainerPolicy{
+			LocationConstraint: policy.LocationConstraint,
+			Policy:             policy.Policy.Marshal(),
+		})
+	}
+
+	if box.InlinePolicy != nil {
+		if newBox.InlinePolicy, err = json.Marshal(box.InlinePolicy); err != nil {
+			return fmt.Errorf("marshal inline policy: %w", err)
+		}
+	}
+
+	var idOwner user.ID
+	user.IDFromKey(&idOwner, options.NeoFSKey.PrivateKey.PublicKey)
+
+	a.log.Info("store renewed access box into NeoFS",
+		zap.Stringer("owner_tkn", idOwner),
+		zap.Stringer("address", addr),
+		zap.Uint64("expiration_epoch", lifetime.Exp))
+
+	if _, err = bearerCreds.Update(ctx, addr, idOwner, newBox, lifetime.Exp, gateKey); err != nil {
+		return fmt.Errorf("failed to update access box: %w", err)
+	}
+
+	rr := &renewingResult{
+		AccessKeyID:     addr.Container().EncodeToString() + "0" + addr.Object().EncodeToString(),
+		ExpirationEpoch: lifetime.Exp,
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rr)
+}
+
+// ListSecrets finds access boxes stored in the container and writes to io.Writer
+// access key ids with public keys of the gates they are issued for.
+func (a *Agent) ListSecrets(ctx context.Context, w io.Writer, options *ListSecretsOptions) error {
+	ids, err := a.neoFS.SearchObjects(ctx, tokens.PrmObjectSearch{
+		Container: options.Container,
+		Owner:     options.Owner,
+	})
+	if err != nil {
+		return fmt.Errorf("search access boxes: %w", err)
+	}
+
+	result := make([]listingResult, 0, len(ids))
+	for _, id := range ids {
+		var addr oid.Address
+		addr.SetContainer(options.Container)
+		addr.SetObject(id)
+
+		data, err := a.neoFS.ReadObjectPayload(ctx, addr)
+		if err != nil {
+			return fmt.Errorf("read access box '%s': %w", addr, err)
+		}
+
+		var box accessbox.AccessBox
+		if err = box.Unmarshal(data); err != nil || len(box.Gates) == 0 {
+			a.log.Debug("skip object which isn't access box", zap.Stringer("address", addr))
+			continue
+		}
+
+		// versions with rotated secrets share access key id with the original box
+		if box.Version != 0 {
+			continue
+		}
+
+		item := listingResult{AccessKeyID: addr.Container().EncodeToString() + "0" + id.EncodeToString()}
+		if box.AccessKeyId != "" {
+			item.NativeAccessKeyID = item.AccessKeyID
+			item.AccessKeyID = box.AccessKeyId
+		}
+		var issuedForGate bool
+		for _, gate := range box.Gates {
+			item.GatesPublicKeys = append(item.GatesPublicKeys, hex.EncodeToString(gate.GatePublicKey))
+			if options.GatePublicKey != nil && bytes.Equal(gate.GatePublicKey, options.GatePublicKey.Bytes()) {
+				issuedForGate = true
+			}
+		}
+
+		if options.GatePublicKey == nil || issuedForGate {
+			result = append(result, item)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// RevokeSecret removes the access box and all its versions with rotated secrets from NeoFS.
+// Gateways reject the access key id after the access box is removed from their cache,
+// to reject it immediately it must be added to the revoked access key ids of the gateways.
+func (a *Agent) RevokeSecret(ctx context.Context, w io.Writer, options *RevokeSecretOptions) error {
+	var addr oid.Address
+	if err := addr.DecodeString(options.SecretAddress); err != nil {
+		return fmt.Errorf("failed to parse secret address: %w", err)
+	}
+
+	versions, err := a.neoFS.SearchObjects(ctx, tokens.PrmObjectSearch{
+		Container: addr.Container(),
+		Attribute: tokens.AttributeAccessBoxOrigin,
+		Value:     addr.Object().EncodeToString(),
+	})
+	if err != nil {
+		return fmt.Errorf("search access box versions: %w", err)
+	}
+
+	// remove the original box last to be able to retry revocation on failure
+	for _, id := range append(versions, addr.Object()) {
+		var objAddr oid.Address
+		objAddr.SetContainer(addr.Container())
+		objAddr.SetObject(id)
+
+		a.log.Info("remove access box", zap.Stringer("address", objAddr))
+		if err = a.neoFS.DeleteObject(ctx, objAddr); err != nil {
+			return fmt.Errorf("failed to remove access box '%s': %w", objAddr, err)
+		}
+	}
+
+	rr := &revokingResult{
+		AccessKeyID:    addr.Container().EncodeToString() + "0" + addr.Object().EncodeToString(),
+		RemovedObjects: len(versions) + 1,
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rr)
+}
+
 func buildEACLTable(eaclTable []byte) (*eacl.Table, error) {
 	table := eacl.NewTable()
 	if len(eaclTable) != 0 {
@@ -81,3 +81,23 @@ func buildContext(rules []byte) ([]sessionTokenContext, error) {
 		{verb: session.VerbContainerSetEACL},
 	}, nil
 }
+
+// tokenContext returns the context the container session token is issued for.
+func tokenContext(tok *session.Container) (sessionTokenContext, error) {
+	var m apisession.Token
+	tok.WriteToV2(&m)
+
+	c, ok := m.GetBody().GetContext().(*apisession.ContainerSessionContext)
+	if !ok {
+		return sessionTokenContext{}, fmt.Errorf("invalid session token context %T", m.GetBody().GetContext())
+	}
+
+	res := sessionTokenContext{verb: session.ContainerVerb(c.Verb())}
+	if cnr := c.ContainerID(); cnr != nil && !c.Wildcard() {
+		if err := res.containerID.ReadFromV2(*cnr); err != nil {
+			return sessionTokenContext{}, fmt.Errorf("invalid container ID: %w", err)
+		}
+	}
+
+	return res, nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/internal/wallet"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/spf13/viper"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
@@ -36,6 +37,7 @@ const (
 	poolRequestTimeout = 5 * time.Second
 	// a month.
 	defaultLifetime          = 30 * 24 * time.Hour
+	defaultGracePeriod       = time.Hour
 	defaultPresignedLifetime = 12 * time.Hour
 )
 
@@ -63,8 +65,12 @@ var (
 	regionFlag               string
 	secretAccessKeyFlag      string
 	containerPolicies        string
+	inlinePolicyFlag         string
 	awcCliCredFile           string
 	timeoutFlag              time.Duration
+	gracePeriodFlag          time.Duration
+	gatePublicKeyFlag        string
+	allIssuersFlag           bool
 )
 
 const (
@@ -157,6 +163,10 @@ func appCommands() []*cli.Command {
 	return []*cli.Command{
 		issueSecret(),
 		obtainSecret(),
+		updateSecret(),
+		renewSecret(),
+		listSecrets(),
+		revokeSecret(),
 		generatePresignedURL(),
 	}
 }
@@ -239,12 +249,30 @@ It will be ceil rounded to the nearest amount of epoch.`,
 				Required:    false,
 				Destination: &containerPolicies,
 			},
+			&cli.StringFlag{
+				Name:        "inline-policy",
+				Usage:       "policy restricting actions allowed with the issued access key as plain json string or path to json file",
+				Required:    false,
+				Destination: &inlinePolicyFlag,
+			},
 			&cli.StringFlag{
 				Name:        "aws-cli-credentials",
 				Usage:       "path to the aws cli credential file",
 				Required:    false,
 				Destination: &awcCliCredFile,
 			},
+			&cli.StringFlag{
+				Name:        "aws-access-key-id",
+				Usage:       "existing AWS access key id to import instead of generating a new one (requires --aws-secret-access-key)",
+				Required:    false,
+				Destination: &accessKeyIDFlag,
+			},
+			&cli.StringFlag{
+				Name:        "aws-secret-access-key",
+				Usage:       "existing AWS secret access key to import instead of generating a new one (requires --aws-access-key-id)",
+				Required:    false,
+				Destination: &secretAccessKeyFlag,
+			},
 		},
 		Action: func(c *cli.Context) error {
 			ctx, log := prepare()
@@ -300,6 +328,11 @@ It will be ceil rounded to the nearest amount of epoch.`,
 				return cli.Exit(fmt.Sprintf("couldn't parse 'session-tokens' flag: %s", err.Error()), 8)
 			}
 
+			inlinePolicy, err := getJSONRules(inlinePolicyFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("couldn't parse 'inline-policy' flag: %s", err.Error()), 8)
+			}
+
 			issueSecretOptions := &authmate.IssueSecretOptions{
 				Container: authmate.ContainerOptions{
 					ID:              containerID,
@@ -312,8 +345,11 @@ It will be ceil rounded to the nearest amount of epoch.`,
 				SessionTokenRules:     sessionRules,
 				SkipSessionRules:      skipSessionRules,
 				ContainerPolicies:     policies,
+				InlinePolicy:          inlinePolicy,
 				Lifetime:              lifetimeFlag,
 				AwsCliCredentialsFile: awcCliCredFile,
+				AccessKeyID:           accessKeyIDFlag,
+				SecretAccessKey:       secretAccessKeyFlag,
 			}
 
 			var tcancel context.CancelFunc
@@ -591,6 +627,380 @@ func obtainSecret() *cli.Command {
 	return command
 }
 
+func updateSecret() *cli.Command {
+	command := &cli.Command{
+		Name:  "update-secret",
+		Usage: "Generate a new secret for the existing access key id",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "wallet",
+				Value:       "",
+				Usage:       "path to the wallet of the access key issuer",
+				Required:    true,
+				Destination: &walletPathFlag,
+			},
+			&cli.StringFlag{
+				Name:        "address",
+				Value:       "",
+				Usage:       "address of wallet account",
+				Required:    false,
+				Destination: &accountAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "peer",
+				Value:       "",
+				Usage:       "address of neofs peer to connect to",
+				Required:    true,
+				Destination: &peerAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "gate-wallet",
+				Value:       "",
+				Usage:       "path to the wallet",
+				Required:    true,
+				Destination: &gateWalletPathFlag,
+			},
+			&cli.StringFlag{
+				Name:        "gate-address",
+				Value:       "",
+				Usage:       "address of wallet account",
+				Required:    false,
+				Destination: &gateAccountAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "access-key-id",
+				Usage:       "access key id for s3",
+				Required:    true,
+				Destination: &accessKeyIDFlag,
+			},
+			&cli.DurationFlag{
+				Name:        "grace-period",
+				Usage:       "time the previous secret remains valid after rotation",
+				Required:    false,
+				Destination: &gracePeriodFlag,
+				Value:       defaultGracePeriod,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, log := prepare()
+
+			password := wallet.GetPassword(viper.GetViper(), envWalletPassphrase)
+			key, err := wallet.GetKeyFromPath(walletPathFlag, accountAddressFlag, password)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to load neofs private key: %s", err), 1)
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			neoFS, err := createNeoFS(ctx, log, &key.PrivateKey, peerAddressFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to create NeoFS component: %s", err), 2)
+			}
+
+			agent := authmate.New(log, neoFS)
+
+			password = wallet.GetPassword(viper.GetViper(), envWalletGatePassphrase)
+			gateCreds, err := wallet.GetKeyFromPath(gateWalletPathFlag, gateAccountAddressFlag, password)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to create owner's private key: %s", err), 4)
+			}
+
+			if gracePeriodFlag < 0 {
+				return cli.Exit(fmt.Sprintf("grace period must not be negative, current value: %s", gracePeriodFlag), 5)
+			}
+
+			updateSecretOptions := &authmate.UpdateSecretOptions{
+				SecretAddress:  strings.Replace(accessKeyIDFlag, "0", "/", 1),
+				NeoFSKey:       key,
+				GatePrivateKey: gateCreds,
+				GracePeriod:    gracePeriodFlag,
+			}
+
+			var tcancel context.CancelFunc
+			ctx, tcancel = context.WithTimeout(ctx, timeoutFlag)
+			defer tcancel()
+
+			if err = agent.UpdateSecret(ctx, os.Stdout, updateSecretOptions); err != nil {
+				return cli.Exit(fmt.Sprintf("failed to update secret: %s", err), 6)
+			}
+
+			return nil
+		},
+	}
+	return command
+}
+
+func renewSecret() *cli.Command {
+	command := &cli.Command{
+		Name:  "renew-secret",
+		Usage: "Issue new tokens with extended lifetime for the existing access key id",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "wallet",
+				Value:       "",
+				Usage:       "path to the wallet of the access key issuer",
+				Required:    true,
+				Destination: &walletPathFlag,
+			},
+			&cli.StringFlag{
+				Name:        "address",
+				Value:       "",
+				Usage:       "address of wallet account",
+				Required:    false,
+				Destination: &accountAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "peer",
+				Value:       "",
+				Usage:       "address of neofs peer to connect to",
+				Required:    true,
+				Destination: &peerAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "gate-wallet",
+				Value:       "",
+				Usage:       "path to the wallet",
+				Required:    true,
+				Destination: &gateWalletPathFlag,
+			},
+			&cli.StringFlag{
+				Name:        "gate-address",
+				Value:       "",
+				Usage:       "address of wallet account",
+				Required:    false,
+				Destination: &gateAccountAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "access-key-id",
+				Usage:       "access key id for s3",
+				Required:    true,
+				Destination: &accessKeyIDFlag,
+			},
+			&cli.DurationFlag{
+				Name: "lifetime",
+				Usage: `Lifetime of tokens starting from now. For example: 50h30m (note: max time unit is an hour so to set a day you should use 24h).
+It will be ceil rounded to the nearest amount of epoch.`,
+				Required:    false,
+				Destination: &lifetimeFlag,
+				Value:       defaultLifetime,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, log := prepare()
+
+			password := wallet.GetPassword(viper.GetViper(), envWalletPassphrase)
+			key, err := wallet.GetKeyFromPath(walletPathFlag, accountAddressFlag, password)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to load neofs private key: %s", err), 1)
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			neoFS, err := createNeoFS(ctx, log, &key.PrivateKey, peerAddressFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to create NeoFS component: %s", err), 2)
+			}
+
+			agent := authmate.New(log, neoFS)
+
+			password = wallet.GetPassword(viper.GetViper(), envWalletGatePassphrase)
+			gateCreds, err := wallet.GetKeyFromPath(gateWalletPathFlag, gateAccountAddressFlag, password)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to create owner's private key: %s", err), 4)
+			}
+
+			if lifetimeFlag <= 0 {
+				return cli.Exit(fmt.Sprintf("lifetime must be greater 0, current value: %d", lifetimeFlag), 5)
+			}
+
+			renewSecretOptions := &authmate.RenewSecretOptions{
+				SecretAddress:  strings.Replace(accessKeyIDFlag, "0", "/", 1),
+				NeoFSKey:       key,
+				GatePrivateKey: gateCreds,
+				Lifetime:       lifetimeFlag,
+			}
+
+			var tcancel context.CancelFunc
+			ctx, tcancel = context.WithTimeout(ctx, timeoutFlag)
+			defer tcancel()
+
+			if err = agent.RenewSecret(ctx, os.Stdout, renewSecretOptions); err != nil {
+				return cli.Exit(fmt.Sprintf("failed to renew secret: %s", err), 6)
+			}
+
+			return nil
+		},
+	}
+	return command
+}
+
+func listSecrets() *cli.Command {
+	command := &cli.Command{
+		Name:  "list-secrets",
+		Usage: "List access key ids of the secrets stored in the auth container",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "wallet",
+				Value:       "",
+				Usage:       "path to the wallet of the secrets issuer",
+				Required:    true,
+				Destination: &walletPathFlag,
+			},
+			&cli.StringFlag{
+				Name:        "address",
+				Value:       "",
+				Usage:       "address of wallet account",
+				Required:    false,
+				Destination: &accountAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "peer",
+				Value:       "",
+				Usage:       "address of neofs peer to connect to",
+				Required:    true,
+				Destination: &peerAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "container-id",
+				Usage:       "auth container id to list the secrets from",
+				Required:    true,
+				Destination: &containerIDFlag,
+			},
+			&cli.StringFlag{
+				Name:        "gate-public-key",
+				Usage:       "public 256r1 key of a gate to list the secrets issued for",
+				Required:    false,
+				Destination: &gatePublicKeyFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "all-issuers",
+				Usage:       "list the secrets issued by any wallet, not only by the specified one",
+				Required:    false,
+				Destination: &allIssuersFlag,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, log := prepare()
+
+			password := wallet.GetPassword(viper.GetViper(), envWalletPassphrase)
+			key, err := wallet.GetKeyFromPath(walletPathFlag, accountAddressFlag, password)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to load neofs private key: %s", err), 1)
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			neoFS, err := createNeoFS(ctx, log, &key.PrivateKey, peerAddressFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to create NeoFS component: %s", err), 2)
+			}
+
+			agent := authmate.New(log, neoFS)
+
+			listSecretsOptions := &authmate.ListSecretsOptions{}
+			if err = listSecretsOptions.Container.DecodeString(containerIDFlag); err != nil {
+				return cli.Exit(fmt.Sprintf("failed to parse auth container id: %s", err), 3)
+			}
+
+			if gatePublicKeyFlag != "" {
+				if listSecretsOptions.GatePublicKey, err = keys.NewPublicKeyFromString(gatePublicKeyFlag); err != nil {
+					return cli.Exit(fmt.Sprintf("failed to load gate's public key: %s", err), 4)
+				}
+			}
+
+			if !allIssuersFlag {
+				var owner user.ID
+				user.IDFromKey(&owner, key.PrivateKey.PublicKey)
+				listSecretsOptions.Owner = &owner
+			}
+
+			var tcancel context.CancelFunc
+			ctx, tcancel = context.WithTimeout(ctx, timeoutFlag)
+			defer tcancel()
+
+			if err = agent.ListSecrets(ctx, os.Stdout, listSecretsOptions); err != nil {
+				return cli.Exit(fmt.Sprintf("failed to list secrets: %s", err), 5)
+			}
+
+			return nil
+		},
+	}
+	return command
+}
+
+func revokeSecret() *cli.Command {
+	command := &cli.Command{
+		Name:  "revoke-secret",
+		Usage: "Revoke a secret removing its access box from NeoFS network",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "wallet",
+				Value:       "",
+				Usage:       "path to the wallet of the secret issuer",
+				Required:    true,
+				Destination: &walletPathFlag,
+			},
+			&cli.StringFlag{
+				Name:        "address",
+				Value:       "",
+				Usage:       "address of wallet account",
+				Required:    false,
+				Destination: &accountAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "peer",
+				Value:       "",
+				Usage:       "address of neofs peer to connect to",
+				Required:    true,
+				Destination: &peerAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "access-key-id",
+				Usage:       "access key id for s3",
+				Required:    true,
+				Destination: &accessKeyIDFlag,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, log := prepare()
+
+			password := wallet.GetPassword(viper.GetViper(), envWalletPassphrase)
+			key, err := wallet.GetKeyFromPath(walletPathFlag, accountAddressFlag, password)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to load neofs private key: %s", err), 1)
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			neoFS, err := createNeoFS(ctx, log, &key.PrivateKey, peerAddressFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to create NeoFS component: %s", err), 2)
+			}
+
+			agent := authmate.New(log, neoFS)
+
+			revokeSecretOptions := &authmate.RevokeSecretOptions{
+				SecretAddress: strings.Replace(accessKeyIDFlag, "0", "/", 1),
+			}
+
+			var tcancel context.CancelFunc
+			ctx, tcancel = context.WithTimeout(ctx, timeoutFlag)
+			defer tcancel()
+
+			if err = agent.RevokeSecret(ctx, os.Stdout, revokeSecretOptions); err != nil {
+				return cli.Exit(fmt.Sprintf("failed to revoke secret: %s", err), 3)
+			}
+
+			return nil
+		},
+	}
+	return command
+}
+
 func createNeoFS(ctx context.Context, log *zap.Logger, key *ecdsa.PrivateKey, peerAddress string) (authmate.NeoFS, error) {
 	log.Debug("prepare connection pool")
 
@@ -5,59 +5,97 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/audit"
 	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
 	"github.com/nspcc-dev/neofs-s3-gw/api/notifications"
 	"github.com/nspcc-dev/neofs-s3-gw/api/resolver"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/redis"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/version"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/wallet"
 	"github.com/nspcc-dev/neofs-sdk-go/netmap"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 type (
 	// App is the main application structure.
 	App struct {
-		ctr  auth.Center
-		log  *zap.Logger
-		cfg  *viper.Viper
-		pool *pool.Pool
-		key  *keys.PrivateKey
-		nc   *notifications.Controller
-		obj  layer.Client
-		api  api.Handler
+		ctr   auth.Center
+		creds tokens.Credentials
+		log   *zap.Logger
+		cfg   *viper.Viper
+		pool  *pool.Pool
+		neoFS *neofs.NeoFS
+		key   *keys.PrivateKey
+		nc    *notifications.Controller
+		obj   layer.Client
+		api   api.Handler
 
 		servers []Server
+		acme    *autocert.Manager
+		handler *reloadableHandler
 
 		metrics        *appMetrics
+		health         *healthChecker
 		bucketResolver *resolver.BucketResolver
 		services       []*Service
 		settings       *appSettings
-		maxClients     api.MaxClients
+		usage          *api.UsageAccounting
+		stopTracing    func(context.Context) error
+		usageExporter  *usageExporter
+		cacheBackend   *redis.Backend
+		treeService    *neofs.TreeClient
+		notificator    *notifications.Dispatcher
+		multipartGC    *garbageCollector
+		deleteMarkerGC *garbageCollector
 
 		webDone chan struct{}
 		wrkDone chan struct{}
 	}
 
 	appSettings struct {
-		logLevel zap.AtomicLevel
-		policies *placementPolicy
+		logLevel          zap.AtomicLevel
+		policies          *placementPolicy
+		revokedAccessKeys *auth.RevokedAccessKeys
+		replayProtection  *auth.ReplayProtection
+		customDomains     *api.CustomDomains
+		rateLimits        *api.RateLimits
+		mode              *api.GatewayMode
+		ipFilter          *api.IPFilter
+		trustedProxies    *api.TrustedProxies
+		accessLog         *api.AccessLog
+		auditLog          *audit.Logger
+		slowRequests      *api.SlowRequests
+		defaultCORS       *handler.DefaultCORS
+		auditLogCloser    io.Closer
+	}
+
+	// reloadableHandler is an http.Handler which can be replaced at runtime.
+	reloadableHandler struct {
+		v atomic.Value
 	}
 
 	Logger struct {
@@ -86,22 +124,34 @@ type (
 
 func newApp(ctx context.Context, log *Logger, v *viper.Viper) *App {
 	conns, key := getPool(ctx, log.logger, v)
+	settings := newAppSettings(log, v)
+
+	neoFS := neofs.NewNeoFS(conns)
+	neoFS.LimitConcurrency(v.GetInt(cfgMaxDataOperations), v.GetInt(cfgMaxMetadataOperations))
+	neoFS.SetTimeouts(fetchOperationTimeouts(v))
+	neoFS.SetRetryPolicy(fetchRetryPolicy(v))
 
 	// prepare auth center
-	ctr := auth.New(neofs.NewAuthmateNeoFS(conns), key, v.GetStringSlice(cfgAllowedAccessKeyIDPrefixes), getAccessBoxCacheConfig(v, log.logger))
+	creds := tokens.New(neoFS.AuthmateNeoFS(), key, getAccessBoxCacheConfig(v, log.logger))
+	ctr := auth.New(creds, v.GetStringSlice(cfgAllowedAccessKeyIDPrefixes), settings.revokedAccessKeys,
+		fetchImportedAccessKeysContainer(log.logger, v), settings.replayProtection)
 
 	app := &App{
-		ctr:  ctr,
-		log:  log.logger,
-		cfg:  v,
-		pool: conns,
-		key:  key,
+		ctr:   ctr,
+		creds: creds,
+		log:   log.logger,
+		cfg:   v,
+		pool:  conns,
+		neoFS: neoFS,
+		key:   key,
 
 		webDone: make(chan struct{}, 1),
 		wrkDone: make(chan struct{}, 1),
 
-		maxClients: newMaxClients(v),
-		settings:   newAppSettings(log, v),
+		handler:  new(reloadableHandler),
+		health:   newHealthChecker(v.GetDuration(cfgHealthTimeout)),
+		settings: settings,
+		usage:    api.NewUsageAccounting(),
 	}
 
 	app.init(ctx)
@@ -110,7 +160,11 @@ func newApp(ctx context.Context, log *Logger, v *viper.Viper) *App {
 }
 
 func (a *App) init(ctx context.Context) {
+	a.initTracing(ctx)
 	a.initAPI(ctx)
+	a.usageExporter = newUsageExporter(a.log, a.cfg, a.obj, a.usage)
+	a.multipartGC = newMultipartCollector(a.log, a.cfg, a.neoFS, a.treeService)
+	a.deleteMarkerGC = newDeleteMarkersCollector(a.log, a.cfg, a.neoFS, a.treeService)
 	a.initMetrics()
 	a.initServers(ctx)
 }
@@ -118,12 +172,20 @@ func (a *App) init(ctx context.Context) {
 func (a *App) initLayer(ctx context.Context) {
 	a.initResolver()
 
-	treeServiceEndpoint := a.cfg.GetString(cfgTreeServiceEndpoint)
-	treeService, err := neofs.NewTreeClient(ctx, treeServiceEndpoint, a.key)
+	treeServiceEndpoints := a.cfg.GetStringSlice(cfgTreeServiceEndpoint)
+	treeTLSConfig, err := fetchTreeTLSConfig(a.cfg)
+	if err != nil {
+		a.log.Fatal("invalid tree service tls configuration", zap.Error(err))
+	}
+
+	treeService, err := neofs.NewTreeClient(ctx, treeServiceEndpoints, a.key, treeTLSConfig)
 	if err != nil {
 		a.log.Fatal("failed to create tree service", zap.Error(err))
 	}
-	a.log.Info("init tree service", zap.String("endpoint", treeServiceEndpoint))
+	treeService.SetRetryPolicy(fetchTreeRetryPolicy(a.cfg))
+	a.neoFS.LimitTreeClient(treeService)
+	a.treeService = treeService
+	a.log.Info("init tree service", zap.Strings("endpoints", treeServiceEndpoints), zap.Bool("tls", treeTLSConfig != nil))
 
 	// prepare random key for anonymous requests
 	randomKey, err := keys.NewPrivateKey()
@@ -131,17 +193,46 @@ func (a *App) initLayer(ctx context.Context) {
 		a.log.Fatal("couldn't generate random key", zap.Error(err))
 	}
 
+	cacheCfg := getCacheOptions(a.cfg, a.log)
+	if a.cacheBackend, err = newCacheBackend(ctx, a.cfg); err != nil {
+		a.log.Fatal("couldn't create cache backend", zap.Error(err))
+	}
+	if a.cacheBackend != nil {
+		cacheCfg.ObjectsList.Backend = a.cacheBackend
+		cacheCfg.Names.Backend = a.cacheBackend
+		cacheCfg.System.Backend = a.cacheBackend
+		a.log.Info("names, list and system caches are shared", zap.String("backend", a.cfg.GetString(cfgCacheBackend)))
+	}
+
+	md5ETag, err := isMD5ETag(a.cfg)
+	if err != nil {
+		a.log.Fatal("invalid etag source", zap.Error(err))
+	}
+
 	layerCfg := &layer.Config{
-		Caches: getCacheOptions(a.cfg, a.log),
+		Caches: cacheCfg,
 		AnonKey: layer.AnonymousKey{
 			Key: randomKey,
 		},
-		Resolver:    a.bucketResolver,
-		TreeService: treeService,
+		Resolver:      a.bucketResolver,
+		TreeService:   treeService,
+		DeleteWorkers: a.cfg.GetInt(cfgDeleteWorkers),
+		ListWorkers:   a.cfg.GetInt(cfgListWorkers),
+		ParallelGet: layer.ParallelGetConfig{
+			Threshold: a.cfg.GetUint64(cfgParallelGetThreshold),
+			PartSize:  a.cfg.GetUint64(cfgParallelGetPartSize),
+			Workers:   a.cfg.GetInt(cfgParallelGetWorkers),
+		},
+		GetBufferSize: a.cfg.GetInt(cfgBufferSizeGet),
+		PutBufferSize: a.cfg.GetInt(cfgBufferSizePut),
+		MD5ETag:       md5ETag,
 	}
 
 	// prepare object layer
-	a.obj = layer.NewLayer(a.log, neofs.NewNeoFS(a.pool), layerCfg)
+	a.obj = layer.NewLayer(a.log, a.neoFS, layerCfg)
+
+	a.health.addCheck("neofs", a.neoFS.Healthcheck)
+	a.health.addCheck("tree", treeService.Healthcheck)
 
 	if a.cfg.GetBool(cfgEnableNATS) {
 		nopts := getNotificationsOptions(a.cfg, a.log)
@@ -154,6 +245,22 @@ func (a *App) initLayer(ctx context.Context) {
 			a.log.Fatal("couldn't initialize layer", zap.Error(err))
 		}
 	}
+
+	dst := notifications.Destinations{Webhooks: fetchWebhooks(a.cfg)}
+	if dst.Kafka, err = fetchKafka(a.cfg); err != nil {
+		a.log.Fatal("invalid kafka configuration", zap.Error(err))
+	}
+	if dst.AMQP, err = fetchAMQP(a.cfg); err != nil {
+		a.log.Fatal("invalid amqp configuration", zap.Error(err))
+	}
+
+	if a.nc != nil || !dst.IsEmpty() {
+		queue := newEventsQueue(a.log, a.cfg, a.obj)
+		a.notificator, err = notifications.NewDispatcher(a.nc, dst, queue, a.log)
+		if err != nil {
+			a.log.Fatal("failed to enable notifications", zap.Error(err))
+		}
+	}
 }
 
 func newAppSettings(log *Logger, v *viper.Viper) *appSettings {
@@ -162,9 +269,67 @@ func newAppSettings(log *Logger, v *viper.Viper) *appSettings {
 		log.logger.Fatal("failed to create new policy mapping", zap.Error(err))
 	}
 
+	mode, err := getMode(v)
+	if err != nil {
+		log.logger.Fatal("invalid mode", zap.Error(err))
+	}
+
+	ipFilter, err := api.NewIPFilter(fetchIPFilterRules(v))
+	if err != nil {
+		log.logger.Fatal("invalid ip filter", zap.Error(err))
+	}
+
+	trustedProxies, err := api.NewTrustedProxies(v.GetStringSlice(cfgTrustedProxies))
+	if err != nil {
+		log.logger.Fatal("invalid trusted proxies", zap.Error(err))
+	}
+
+	var accessLog *api.AccessLog
+	if v.GetBool(cfgAccessLogEnabled) {
+		accessLogger, err := newAccessLogger(v)
+		if err != nil {
+			log.logger.Fatal("couldn't create access logger", zap.Error(err))
+		}
+
+		if accessLog, err = api.NewAccessLog(accessLogger, fetchAccessLogSettings(v)); err != nil {
+			log.logger.Fatal("invalid access log", zap.Error(err))
+		}
+	}
+
+	defaultCORS, err := fetchDefaultCORS(v)
+	if err != nil {
+		log.logger.Fatal("invalid cors configuration", zap.Error(err))
+	}
+	if defaultCORS != nil {
+		log.logger.Info("default cors configuration is applied to buckets without cors", zap.Int("rules", len(defaultCORS.CORSRules)))
+	}
+
+	var (
+		auditLog       *audit.Logger
+		auditLogCloser io.Closer
+	)
+	if v.GetBool(cfgAuditLogEnabled) {
+		if auditLog, auditLogCloser, err = newAuditLogger(v); err != nil {
+			log.logger.Fatal("couldn't create audit logger", zap.Error(err))
+		}
+	}
+
 	return &appSettings{
-		logLevel: log.lvl,
-		policies: policies,
+		logLevel:          log.lvl,
+		policies:          policies,
+		revokedAccessKeys: auth.NewRevokedAccessKeys(v.GetStringSlice(cfgRevokedAccessKeyIDs)),
+		replayProtection: auth.NewReplayProtection(v.GetDuration(cfgReplayProtectionClockSkew),
+			v.GetDuration(cfgReplayProtectionMaxPresignedExpiration), fetchPresignedNoncesSize(log.logger, v)),
+		customDomains:  api.NewCustomDomains(fetchCustomDomains(log.logger, v)),
+		rateLimits:     api.NewRateLimits(fetchRateLimits(v)),
+		mode:           api.NewGatewayMode(mode),
+		ipFilter:       ipFilter,
+		trustedProxies: trustedProxies,
+		accessLog:      accessLog,
+		auditLog:       auditLog,
+		auditLogCloser: auditLogCloser,
+		slowRequests:   api.NewSlowRequests(log.logger, v.GetDuration(cfgSlowRequestThreshold)),
+		defaultCORS:    handler.NewDefaultCORS(defaultCORS),
 	}
 }
 
@@ -182,9 +347,31 @@ func (a *App) initAPI(ctx context.Context) {
 	a.initHandler()
 }
 
+func (a *App) initTracing(ctx context.Context) {
+	if !a.cfg.GetBool(cfgTracingEnabled) {
+		return
+	}
+
+	cfg := tracing.Config{
+		Endpoint:    a.cfg.GetString(cfgTracingEndpoint),
+		Insecure:    a.cfg.GetBool(cfgTracingInsecure),
+		SampleRatio: a.cfg.GetFloat64(cfgTracingSampleRatio),
+	}
+
+	stop, err := tracing.Init(ctx, cfg, version.Version)
+	if err != nil {
+		a.log.Fatal("couldn't initialize tracing", zap.Error(err))
+	}
+	a.stopTracing = stop
+
+	a.log.Info("tracing is enabled", zap.String("endpoint", cfg.Endpoint), zap.Float64("sample_ratio", cfg.SampleRatio))
+}
+
 func (a *App) initMetrics() {
-	gateMetricsProvider := newGateMetrics(neofs.NewPoolStatistic(a.pool))
+	gateMetricsProvider := newGateMetrics(neofs.NewPoolStatistic(a.pool), a.neoFS, a.obj)
 	a.metrics = newAppMetrics(a.log, gateMetricsProvider, a.cfg.GetBool(cfgPrometheusEnabled))
+	metrics.SetBucketMetricsEnabled(a.cfg.GetBool(cfgPrometheusBucketMetrics))
+	metrics.SetUsageMetricsEnabled(a.cfg.GetBool(cfgUsageMetrics))
 }
 
 func (a *App) initResolver() {
@@ -413,22 +600,33 @@ func (a *App) Wait() {
 
 func (a *App) setHealthStatus() {
 	a.metrics.SetHealth(1)
+	a.health.setReady(true)
 }
 
 // Serve runs HTTP server to handle S3 API requests.
 func (a *App) Serve(ctx context.Context) {
-	// Attach S3 API:
-	domains := a.cfg.GetStringSlice(cfgListenDomains)
-	a.log.Info("fetch domains, prepare to use API", zap.Strings("domains", domains))
-	router := mux.NewRouter().SkipClean(true).UseEncodedPath()
-	api.Attach(router, domains, a.maxClients, a.api, a.ctr, a.log)
+	a.handler.Store(a.newHandler())
 
-	// Use mux.Router as http.Handler
 	srv := new(http.Server)
-	srv.Handler = router
+	srv.Handler = a.handler
 	srv.ErrorLog = zap.NewStdLog(a.log)
 
 	a.startServices()
+	go a.renewAccessBoxes(ctx)
+	go a.checkTreeEndpoints(ctx)
+	go a.watchCerts(ctx)
+	if a.usageExporter != nil {
+		go a.usageExporter.Run(ctx)
+	}
+	if a.notificator != nil {
+		go a.notificator.Run(ctx)
+	}
+	if a.multipartGC != nil {
+		go a.multipartGC.Run(ctx)
+	}
+	if a.deleteMarkerGC != nil {
+		go a.deleteMarkerGC.Run(ctx)
+	}
 
 	for i := range a.servers {
 		go func(i int) {
@@ -453,21 +651,236 @@ LOOP:
 		}
 	}
 
-	ctx, cancel := shutdownContext()
-	defer cancel()
-
-	a.log.Info("stopping server", zap.Error(srv.Shutdown(ctx)))
+	// report not ready, so the gateway is taken out of rotation while requests are drained
+	a.health.setReady(false)
+	a.drainServer(srv)
+	a.exportRemainingUsage()
 
 	a.metrics.Shutdown()
 	a.stopServices()
+	a.shutdownTracing()
+	a.closeAuditLog()
+	a.closeCacheBackend()
+	if a.notificator != nil {
+		a.notificator.Close()
+	}
+	a.pool.Close()
 
 	close(a.webDone)
 }
 
+// drainServer stops accepting new connections and waits for in-flight requests
+// to be finished up to the drain timeout. Requests left after timeout are aborted.
+func (a *App) drainServer(srv *http.Server) {
+	timeout := a.cfg.GetDuration(cfgShutdownTimeout)
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	a.log.Info("stopping server, draining requests", zap.Duration("timeout", timeout))
+
+	if err := srv.Shutdown(ctx); err != nil {
+		a.log.Warn("couldn't drain requests, closing connections", zap.Error(err))
+		if err = srv.Close(); err != nil {
+			a.log.Error("couldn't close server", zap.Error(err))
+		}
+		return
+	}
+
+	a.log.Info("server stopped")
+}
+
+// shutdownTracing exports remaining spans.
+func (a *App) shutdownTracing() {
+	if a.stopTracing == nil {
+		return
+	}
+
+	ctx, cancel := shutdownContext()
+	defer cancel()
+
+	if err := a.stopTracing(ctx); err != nil {
+		a.log.Error("couldn't export remaining spans", zap.Error(err))
+	}
+}
+
+// closeCacheBackend closes the connection to the shared cache backend.
+func (a *App) closeCacheBackend() {
+	if a.cacheBackend == nil {
+		return
+	}
+
+	if err := a.cacheBackend.Close(); err != nil {
+		a.log.Error("couldn't close cache backend", zap.Error(err))
+	}
+}
+
+// closeAuditLog closes the audit log sink.
+func (a *App) closeAuditLog() {
+	if a.settings.auditLogCloser == nil {
+		return
+	}
+
+	if err := a.settings.auditLogCloser.Close(); err != nil {
+		a.log.Error("couldn't close audit log", zap.Error(err))
+	}
+}
+
+// exportRemainingUsage exports the usage accounted since the last periodic export on shutdown.
+func (a *App) exportRemainingUsage() {
+	if a.usageExporter == nil {
+		return
+	}
+
+	ctx, cancel := shutdownContext()
+	defer cancel()
+
+	if err := a.usageExporter.Export(ctx); err != nil {
+		a.log.Error("couldn't export usage on shutdown", zap.Error(err))
+	}
+}
+
+// newHandler attaches S3 API to a new router using current domains and
+// client limits, so they can be changed on SIGHUP by replacing the handler.
+func (a *App) newHandler() http.Handler {
+	domains := a.cfg.GetStringSlice(cfgListenDomains)
+	a.log.Info("fetch domains, prepare to use API", zap.Strings("domains", domains))
+	router := mux.NewRouter().SkipClean(true).UseEncodedPath()
+
+	var usage *api.UsageAccounting
+	if a.cfg.GetBool(cfgUsageEnabled) {
+		usage = a.usage
+	}
+	api.Attach(router, &api.AttachConfig{
+		Domains:        domains,
+		ControlDomains: a.cfg.GetStringSlice(cfgBatchJobsDomains),
+		MaxClients:     newMaxClients(a.cfg),
+		RateLimits:     a.settings.rateLimits,
+		Mode:           a.settings.mode,
+		IPFilter:       a.settings.ipFilter,
+		TrustedProxies: a.settings.trustedProxies,
+		Usage:          usage,
+		AccessLog:      a.settings.accessLog,
+		AuditLog:       a.settings.auditLog,
+		SlowRequests:   a.settings.slowRequests,
+		Handler:        a.api,
+		Center:         a.ctr,
+		Log:            a.log,
+	})
+
+	// Use mux.Router as http.Handler, requests to custom domains are rewritten to path-style ones
+	var h http.Handler = api.ResolveCustomDomains(router, a.settings.customDomains)
+	if a.acme != nil {
+		// Serve ACME HTTP-01 challenges, other requests are passed to the router.
+		h = a.acme.HTTPHandler(h)
+	}
+
+	return h
+}
+
+// ServeHTTP passes the request to the current handler.
+func (h *reloadableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.v.Load().(http.Handler).ServeHTTP(w, r)
+}
+
+// Store replaces the current handler.
+func (h *reloadableHandler) Store(handler http.Handler) {
+	h.v.Store(handler)
+}
+
 func shutdownContext() (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), defaultShutdownTimeout)
 }
 
+// renewAccessBoxes periodically reloads cached access boxes which bearer tokens
+// expire soon to pick up their renewed versions.
+// checkTreeEndpoints periodically checks health of tree service endpoints,
+// unhealthy endpoints are used by requests only if healthy ones fail.
+func (a *App) checkTreeEndpoints(ctx context.Context) {
+	interval := a.cfg.GetDuration(cfgTreeHealthcheckInterval)
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, st := range a.treeService.CheckEndpoints(ctx) {
+				if st.Err != nil {
+					a.log.Warn("tree service endpoint is unhealthy", zap.String("endpoint", st.Address), zap.Error(st.Err))
+				}
+			}
+		}
+	}
+}
+
+func (a *App) renewAccessBoxes(ctx context.Context) {
+	interval := a.cfg.GetDuration(cfgAccessBoxRenewalInterval)
+	if interval <= 0 {
+		a.log.Info("access box renewal is disabled")
+		return
+	}
+
+	neoFS := a.neoFS.AuthmateNeoFS()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			threshold := a.cfg.GetDuration(cfgAccessBoxRenewalThreshold)
+			_, epoch, err := neoFS.TimeToEpoch(ctx, time.Now().Add(threshold))
+			if err != nil {
+				a.log.Error("couldn't compute renewal epoch", zap.Error(err))
+				continue
+			}
+
+			if err = a.creds.RefreshExpiring(ctx, epoch); err != nil {
+				a.log.Error("couldn't renew access boxes", zap.Error(err))
+			}
+		}
+	}
+}
+
+// watchCerts periodically reloads TLS certificates of the servers which files were
+// changed, so renewed certificates are picked up without SIGHUP.
+func (a *App) watchCerts(ctx context.Context) {
+	interval := a.cfg.GetDuration(cfgTLSReloadInterval)
+	if interval <= 0 {
+		a.log.Info("tls certificates watching is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, srv := range a.servers {
+				reloaded, err := srv.ReloadCert()
+				if err != nil {
+					a.log.Error("couldn't reload tls certificate", zap.String("address", srv.Address()), zap.Error(err))
+				} else if reloaded {
+					a.log.Info("tls certificate reloaded", zap.String("address", srv.Address()))
+				}
+			}
+		}
+	}
+}
+
 func (a *App) configReload() {
 	a.log.Info("SIGHUP config reload started")
 
@@ -475,6 +888,13 @@ func (a *App) configReload() {
 		a.log.Warn("failed to reload config because it's missed")
 		return
 	}
+
+	// The new config is validated before applying, so an invalid
+	// config is rejected as a whole and the current one is kept.
+	if err := validateConfig(a.cfg.GetString(cmdConfig)); err != nil {
+		a.log.Warn("failed to reload config, invalid config is rejected", zap.Error(err))
+		return
+	}
 	if err := readConfig(a.cfg); err != nil {
 		a.log.Warn("failed to reload config", zap.Error(err))
 		return
@@ -492,8 +912,11 @@ func (a *App) configReload() {
 	a.startServices()
 
 	a.updateSettings()
+	a.handler.Store(a.newHandler())
 
 	a.metrics.SetEnabled(a.cfg.GetBool(cfgPrometheusEnabled))
+	metrics.SetBucketMetricsEnabled(a.cfg.GetBool(cfgPrometheusBucketMetrics))
+	metrics.SetUsageMetricsEnabled(a.cfg.GetBool(cfgUsageMetrics))
 	a.setHealthStatus()
 
 	a.log.Info("SIGHUP config reload completed")
@@ -509,6 +932,41 @@ func (a *App) updateSettings() {
 	if err := a.settings.policies.update(getDefaultPolicyValue(a.cfg), a.cfg.GetString(cfgPolicyRegionMapFile)); err != nil {
 		a.log.Warn("policies won't be updated", zap.Error(err))
 	}
+
+	a.settings.revokedAccessKeys.Update(a.cfg.GetStringSlice(cfgRevokedAccessKeyIDs))
+	a.settings.replayProtection.SetClockSkew(a.cfg.GetDuration(cfgReplayProtectionClockSkew))
+	a.settings.replayProtection.SetMaxPresignedExpiration(a.cfg.GetDuration(cfgReplayProtectionMaxPresignedExpiration))
+	a.settings.customDomains.Update(fetchCustomDomains(a.log, a.cfg))
+	a.settings.rateLimits.Update(fetchRateLimits(a.cfg))
+
+	if err := a.settings.ipFilter.Update(fetchIPFilterRules(a.cfg)); err != nil {
+		a.log.Warn("ip filter won't be updated", zap.Error(err))
+	}
+
+	if err := a.settings.trustedProxies.Update(a.cfg.GetStringSlice(cfgTrustedProxies)); err != nil {
+		a.log.Warn("trusted proxies won't be updated", zap.Error(err))
+	}
+
+	a.settings.slowRequests.Update(a.cfg.GetDuration(cfgSlowRequestThreshold))
+
+	if defaultCORS, err := fetchDefaultCORS(a.cfg); err != nil {
+		a.log.Warn("default cors configuration won't be updated", zap.Error(err))
+	} else {
+		a.settings.defaultCORS.Update(defaultCORS)
+	}
+
+	if a.settings.accessLog != nil {
+		if err := a.settings.accessLog.Update(fetchAccessLogSettings(a.cfg)); err != nil {
+			a.log.Warn("access log settings won't be updated", zap.Error(err))
+		}
+	}
+
+	if mode, err := getMode(a.cfg); err != nil {
+		a.log.Warn("mode won't be updated", zap.Error(err))
+	} else if mode != a.settings.mode.Get() {
+		a.settings.mode.Set(mode)
+		a.log.Info("gateway mode is changed", zap.Stringer("mode", mode))
+	}
 }
 
 func (a *App) startServices() {
@@ -521,17 +979,27 @@ func (a *App) startServices() {
 	prometheusService := NewPrometheusService(a.cfg, a.log)
 	a.services = append(a.services, prometheusService)
 	go prometheusService.Start()
+
+	healthService := NewHealthService(a.cfg, a.log, a.health)
+	a.services = append(a.services, healthService)
+	go healthService.Start()
+
+	adminService := NewAdminService(a.cfg, a.log, a.settings.logLevel, a.obj, a.obj, a.ctr, a.usage)
+	a.services = append(a.services, adminService)
+	go adminService.Start()
 }
 
 func (a *App) initServers(ctx context.Context) {
 	serversInfo := fetchServers(a.cfg)
+	a.acme = fetchACMEManager(a.log, a.cfg, a.settings.customDomains)
 
 	a.servers = make([]Server, len(serversInfo))
 	for i, serverInfo := range serversInfo {
 		a.log.Info("added server",
 			zap.String("address", serverInfo.Address), zap.Bool("tls enabled", serverInfo.TLS.Enabled),
-			zap.String("tls cert", serverInfo.TLS.CertFile), zap.String("tls key", serverInfo.TLS.KeyFile))
-		a.servers[i] = newServer(ctx, serverInfo, a.log)
+			zap.String("tls cert", serverInfo.TLS.CertFile), zap.String("tls key", serverInfo.TLS.KeyFile),
+			zap.Bool("acme", serverInfo.TLS.ACME), zap.Bool("http2", serverInfo.TLS.HTTP2))
+		a.servers[i] = newServer(ctx, serverInfo, a.acme, a.log)
 	}
 }
 
@@ -547,7 +1015,7 @@ func (a *App) updateServers() error {
 			return fmt.Errorf("invalid servers configuration: addresses mismatch: old '%s', new '%s", a.servers[i].Address(), serverInfo.Address)
 		}
 
-		if serverInfo.TLS.Enabled {
+		if serverInfo.TLS.Enabled && !serverInfo.TLS.ACME {
 			if err := a.servers[i].UpdateCert(serverInfo.TLS.CertFile, serverInfo.TLS.KeyFile); err != nil {
 				return fmt.Errorf("failed to update tls certs: %w", err)
 			}
@@ -587,24 +1055,32 @@ func getNotificationsOptions(v *viper.Viper, l *zap.Logger) *notifications.Optio
 func getCacheOptions(v *viper.Viper, l *zap.Logger) *layer.CachesConfig {
 	cacheCfg := layer.DefaultCachesConfigs(l)
 
+	cacheCfg.Objects.Disabled = !v.GetBool(cfgObjectsCacheEnabled)
 	cacheCfg.Objects.Lifetime = getLifetime(v, l, cfgObjectsCacheLifetime, cacheCfg.Objects.Lifetime)
 	cacheCfg.Objects.Size = getSize(v, l, cfgObjectsCacheSize, cacheCfg.Objects.Size)
 
+	cacheCfg.ObjectsList.Disabled = !v.GetBool(cfgListObjectsCacheEnabled)
 	cacheCfg.ObjectsList.Lifetime = getLifetime(v, l, cfgListObjectsCacheLifetime, cacheCfg.ObjectsList.Lifetime)
 	cacheCfg.ObjectsList.Size = getSize(v, l, cfgListObjectsCacheSize, cacheCfg.ObjectsList.Size)
 
 	cacheCfg.Buckets.Lifetime = getLifetime(v, l, cfgBucketsCacheLifetime, cacheCfg.Buckets.Lifetime)
 	cacheCfg.Buckets.Size = getSize(v, l, cfgBucketsCacheSize, cacheCfg.Buckets.Size)
 
+	cacheCfg.Names.Disabled = !v.GetBool(cfgNamesCacheEnabled)
 	cacheCfg.Names.Lifetime = getLifetime(v, l, cfgNamesCacheLifetime, cacheCfg.Names.Lifetime)
 	cacheCfg.Names.Size = getSize(v, l, cfgNamesCacheSize, cacheCfg.Names.Size)
 
+	cacheCfg.System.Disabled = !v.GetBool(cfgSystemCacheEnabled)
 	cacheCfg.System.Lifetime = getLifetime(v, l, cfgSystemCacheLifetime, cacheCfg.System.Lifetime)
 	cacheCfg.System.Size = getSize(v, l, cfgSystemCacheSize, cacheCfg.System.Size)
 
 	cacheCfg.AccessControl.Lifetime = getLifetime(v, l, cfgAccessControlCacheLifetime, cacheCfg.AccessControl.Lifetime)
 	cacheCfg.AccessControl.Size = getSize(v, l, cfgAccessControlCacheSize, cacheCfg.AccessControl.Size)
 
+	cacheCfg.EACL.Disabled = !v.GetBool(cfgEACLCacheEnabled)
+	cacheCfg.EACL.Lifetime = getLifetime(v, l, cfgEACLCacheLifetime, cacheCfg.EACL.Lifetime)
+	cacheCfg.EACL.Size = getSize(v, l, cfgEACLCacheSize, cacheCfg.EACL.Size)
+
 	return cacheCfg
 }
 
@@ -651,7 +1127,7 @@ func (a *App) initHandler() {
 	cfg := &handler.Config{
 		Policy:             a.settings.policies,
 		DefaultMaxAge:      handler.DefaultMaxAge,
-		NotificatorEnabled: a.cfg.GetBool(cfgEnableNATS),
+		NotificatorEnabled: a.notificator != nil,
 		CopiesNumber:       handler.DefaultCopiesNumber,
 	}
 
@@ -665,13 +1141,35 @@ func (a *App) initHandler() {
 		}
 		cfg.DefaultMaxAge = defaultMaxAge
 	}
+	cfg.DefaultCORS = a.settings.defaultCORS
 
 	if val := a.cfg.GetUint32(cfgSetCopiesNumber); val > 0 {
 		cfg.CopiesNumber = val
 	}
 
+	cfg.StorageClasses = fetchStorageClasses(a.log, a.cfg)
+	cfg.Transforms = fetchTransforms(a.log, a.cfg)
+	cfg.MFA = fetchMFA(a.log, a.cfg)
+
+	if webIdentityCfg := fetchWebIdentity(a.log, a.cfg); webIdentityCfg != nil {
+		cfg.WebIdentity = auth.NewWebIdentity(a.neoFS.AuthmateNeoFS(), a.key, webIdentityCfg, getAccessBoxCacheConfig(a.cfg, a.log))
+	}
+
+	cfg.CompleteMultipartKeepalive = a.cfg.GetDuration(cfgKludgeCompleteMultipartUploadKeepalive)
+	cfg.RelaxedBucketNames = a.cfg.GetBool(cfgKludgeRelaxedBucketNames)
+	cfg.DefaultPolicyForUnknownLocation = a.cfg.GetBool(cfgKludgeDefaultPolicyForUnknownLocation)
+	cfg.ContainerAttributePrefixes = a.cfg.GetStringSlice(cfgContainerAttributesHeaderPrefixes)
+	cfg.ExposedContainerAttributes = a.cfg.GetStringSlice(cfgContainerAttributesExpose)
+	cfg.Usage = a.usage
+
+	if cnrID := fetchBatchJobsContainer(a.log, a.cfg); cnrID != nil {
+		var owner user.ID
+		user.IDFromKey(&owner, a.key.PrivateKey.PublicKey)
+		cfg.BatchJobs = a.neoFS.BatchJobStorage(*cnrID, owner)
+	}
+
 	var err error
-	a.api, err = handler.New(a.log, a.obj, a.nc, cfg)
+	a.api, err = handler.New(a.log, a.obj, a.notificator, cfg)
 	if err != nil {
 		a.log.Fatal("could not initialize API handler", zap.Error(err))
 	}
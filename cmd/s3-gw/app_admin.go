@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+type (
+	// CacheAdmin provides statistic and invalidation of the gateway caches.
+	CacheAdmin interface {
+		CacheStatistic() []cache.Statistic
+		InvalidateCache(ctx context.Context, bucket, pattern string) (int, error)
+	}
+
+	// BucketAdmin provides inventory of buckets.
+	BucketAdmin interface {
+		ListOwnerBuckets(ctx context.Context, owner user.ID) ([]*data.BucketInfo, error)
+		GetBucketSettings(ctx context.Context, bktInfo *data.BucketInfo) (*data.BucketSettings, error)
+		GetBucketUsage(ctx context.Context, bktInfo *data.BucketInfo) (*layer.BucketUsage, error)
+	}
+
+	// AccessKeyResolver resolves owners of access keys.
+	AccessKeyResolver interface {
+		AccessKeyOwner(ctx context.Context, accessKeyID string) (user.ID, error)
+	}
+
+	adminBucket struct {
+		Name               string               `json:"name"`
+		ContainerID        string               `json:"container_id"`
+		Owner              string               `json:"owner"`
+		Created            time.Time            `json:"created"`
+		LocationConstraint string               `json:"location_constraint,omitempty"`
+		ObjectLockEnabled  bool                 `json:"object_lock_enabled"`
+		QuotaSize          uint64               `json:"quota_size,omitempty"`
+		QuotaObjects       uint64               `json:"quota_objects,omitempty"`
+		Settings           *data.BucketSettings `json:"settings,omitempty"`
+		// Size and Objects are omitted if the usage couldn't be calculated.
+		Size    *uint64 `json:"size,omitempty"`
+		Objects *uint64 `json:"objects,omitempty"`
+	}
+
+	adminUsage struct {
+		AccessKeyID string    `json:"access_key_id"`
+		Owner       string    `json:"owner,omitempty"`
+		PeriodStart time.Time `json:"period_start"`
+		Requests    uint64    `json:"requests"`
+		BytesIn     uint64    `json:"bytes_in"`
+		BytesOut    uint64    `json:"bytes_out"`
+		// Buckets contains requests and traffic of the access key per bucket.
+		Buckets []api.UsageRecord `json:"buckets"`
+		// Size and Objects are stored in the buckets of the owner, they are omitted
+		// if the owner or the usage of its buckets couldn't be determined.
+		Size    *uint64 `json:"size,omitempty"`
+		Objects *uint64 `json:"objects,omitempty"`
+	}
+
+	ownerStorage struct {
+		size, objects uint64
+	}
+)
+
+// NewAdminService creates a new service of administrative endpoints. Requests must
+// contain the configured token in the Authorization header as a bearer token.
+func NewAdminService(v *viper.Viper, l *zap.Logger, logLevel zap.AtomicLevel, caches CacheAdmin, buckets BucketAdmin,
+	accessKeys AccessKeyResolver, usage *api.UsageAccounting) *Service {
+	log := l.With(zap.String("service", "Admin"))
+
+	enabled := v.GetBool(cfgAdminEnabled)
+	token := v.GetString(cfgAdminToken)
+	if enabled && token == "" {
+		log.Warn("admin token isn't set, service is disabled")
+		enabled = false
+	}
+
+	handler := http.NewServeMux()
+	// GET returns the current log level, PUT with {"level":"debug"} body changes it
+	handler.Handle("/log/level", logLevel)
+	// GET returns statistic of caches
+	handler.HandleFunc("/caches", cacheStatisticHandler(caches, log))
+	// POST with bucket and optional key (path.Match pattern of object names) query parameters
+	// deletes cached entries of the bucket
+	handler.HandleFunc("/caches/invalidate", cacheInvalidateHandler(caches, log))
+	// GET with optional owner query parameters lists buckets of the owners, configured owners
+	// are used if the parameters aren't set
+	handler.HandleFunc("/buckets", bucketsHandler(buckets, fetchAdminBucketOwners(v, log), log))
+	// GET with optional access_key query parameters returns requests and traffic of the access keys
+	// and the storage used by their owners, all accounted access keys are returned if the parameters aren't set
+	handler.HandleFunc("/usage", usageHandler(usage, accessKeys, buckets, log))
+
+	return &Service{
+		Server: &http.Server{
+			Addr:    v.GetString(cfgAdminAddress),
+			Handler: adminAuth(token, log, handler),
+		},
+		enabled:     enabled,
+		serviceType: "Admin",
+		log:         log,
+	}
+}
+
+// adminAuth rejects requests without the bearer token.
+func adminAuth(token string, log *zap.Logger, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(reqToken), []byte(token)) != 1 {
+			log.Warn("unauthorized admin request", zap.String("remote", r.RemoteAddr),
+				zap.String("method", r.Method), zap.String("path", r.URL.Path))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		log.Info("admin request", zap.String("remote", r.RemoteAddr),
+			zap.String("method", r.Method), zap.String("path", r.URL.Path))
+		h.ServeHTTP(w, r)
+	})
+}
+
+func cacheStatisticHandler(caches CacheAdmin, log *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeAdminJSON(w, log, caches.CacheStatistic())
+	}
+}
+
+func cacheInvalidateHandler(caches CacheAdmin, log *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		bucket, pattern := r.URL.Query().Get("bucket"), r.URL.Query().Get("key")
+		if bucket == "" {
+			http.Error(w, "bucket isn't set", http.StatusBadRequest)
+			return
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			http.Error(w, "invalid key pattern: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		n, err := caches.InvalidateCache(r.Context(), bucket, pattern)
+		if err != nil {
+			if errors.IsS3Error(err, errors.ErrNoSuchBucket) {
+				http.Error(w, "bucket not found", http.StatusNotFound)
+				return
+			}
+			log.Error("couldn't invalidate caches", zap.String("bucket", bucket), zap.Error(err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("caches invalidated", zap.String("bucket", bucket), zap.String("key", pattern), zap.Int("entries", n))
+		writeAdminJSON(w, log, struct {
+			Invalidated int `json:"invalidated"`
+		}{Invalidated: n})
+	}
+}
+
+func fetchAdminBucketOwners(v *viper.Viper, log *zap.Logger) []user.ID {
+	var owners []user.ID
+	for _, s := range v.GetStringSlice(cfgAdminBucketOwners) {
+		var owner user.ID
+		if err := owner.DecodeString(s); err != nil {
+			log.Warn("invalid bucket owner is skipped", zap.String("owner", s), zap.Error(err))
+			continue
+		}
+		owners = append(owners, owner)
+	}
+	return owners
+}
+
+func bucketsHandler(buckets BucketAdmin, defaultOwners []user.ID, log *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		owners := defaultOwners
+		if values := r.URL.Query()["owner"]; len(values) > 0 {
+			owners = make([]user.ID, len(values))
+			for i := range values {
+				if err := owners[i].DecodeString(values[i]); err != nil {
+					http.Error(w, "invalid owner: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		if len(owners) == 0 {
+			http.Error(w, "owner isn't set", http.StatusBadRequest)
+			return
+		}
+
+		res := make([]adminBucket, 0)
+		for _, owner := range owners {
+			list, err := buckets.ListOwnerBuckets(r.Context(), owner)
+			if err != nil {
+				log.Error("couldn't list buckets", zap.Stringer("owner", owner), zap.Error(err))
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			for _, bktInfo := range list {
+				res = append(res, inventoryBucket(r.Context(), buckets, bktInfo, log))
+			}
+		}
+
+		writeAdminJSON(w, log, res)
+	}
+}
+
+// inventoryBucket collects info of the bucket, settings and usage which can't be read are omitted.
+func inventoryBucket(ctx context.Context, buckets BucketAdmin, bktInfo *data.BucketInfo, log *zap.Logger) adminBucket {
+	res := adminBucket{
+		Name:               bktInfo.Name,
+		ContainerID:        bktInfo.CID.EncodeToString(),
+		Owner:              bktInfo.Owner.EncodeToString(),
+		Created:            bktInfo.Created,
+		LocationConstraint: bktInfo.LocationConstraint,
+		ObjectLockEnabled:  bktInfo.ObjectLockEnabled,
+		QuotaSize:          bktInfo.Quota.Size,
+		QuotaObjects:       bktInfo.Quota.Objects,
+	}
+
+	l := log.With(zap.String("bucket", bktInfo.Name), zap.Stringer("cid", bktInfo.CID))
+
+	settings, err := buckets.GetBucketSettings(ctx, bktInfo)
+	if err != nil {
+		l.Warn("couldn't get bucket settings", zap.Error(err))
+	} else {
+		res.Settings = settings
+	}
+
+	usage, err := buckets.GetBucketUsage(ctx, bktInfo)
+	if err != nil {
+		l.Warn("couldn't get bucket usage", zap.Error(err))
+	} else {
+		res.Size, res.Objects = &usage.Size, &usage.Objects
+	}
+
+	return res
+}
+
+func usageHandler(usage *api.UsageAccounting, accessKeys AccessKeyResolver, buckets BucketAdmin, log *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		requested := r.URL.Query()["access_key"]
+		records := make(map[string][]api.UsageRecord)
+		var ids []string
+		for _, rec := range usage.Records() {
+			if rec.AccessKeyID == "" {
+				continue
+			}
+			if _, ok := records[rec.AccessKeyID]; !ok {
+				ids = append(ids, rec.AccessKeyID)
+			}
+			records[rec.AccessKeyID] = append(records[rec.AccessKeyID], rec)
+		}
+		if len(requested) > 0 {
+			ids = requested
+		}
+		sort.Strings(ids)
+
+		storage := make(map[string]*ownerStorage)
+		res := make([]adminUsage, 0, len(ids))
+		for _, id := range ids {
+			item := adminUsage{
+				AccessKeyID: id,
+				PeriodStart: usage.Since(),
+				Buckets:     make([]api.UsageRecord, 0, len(records[id])),
+			}
+			for _, rec := range records[id] {
+				item.Requests += rec.Requests
+				item.BytesIn += rec.BytesIn
+				item.BytesOut += rec.BytesOut
+				item.Buckets = append(item.Buckets, rec)
+			}
+
+			owner, err := accessKeys.AccessKeyOwner(r.Context(), id)
+			if err != nil {
+				if len(requested) > 0 && errors.IsS3Error(err, errors.ErrInvalidAccessKeyID) {
+					http.Error(w, "access key not found: "+id, http.StatusNotFound)
+					return
+				}
+				log.Warn("couldn't get owner of access key", zap.String("access_key_id", id), zap.Error(err))
+				res = append(res, item)
+				continue
+			}
+			item.Owner = owner.EncodeToString()
+
+			stored, ok := storage[item.Owner]
+			if !ok {
+				stored = calculateOwnerStorage(r.Context(), buckets, owner, log)
+				storage[item.Owner] = stored
+			}
+			if stored != nil {
+				item.Size, item.Objects = &stored.size, &stored.objects
+			}
+
+			res = append(res, item)
+		}
+
+		writeAdminJSON(w, log, res)
+	}
+}
+
+// calculateOwnerStorage sums usage of the buckets of the owner, nil is returned if it can't be calculated.
+func calculateOwnerStorage(ctx context.Context, buckets BucketAdmin, owner user.ID, log *zap.Logger) *ownerStorage {
+	l := log.With(zap.Stringer("owner", owner))
+
+	list, err := buckets.ListOwnerBuckets(ctx, owner)
+	if err != nil {
+		l.Warn("couldn't list buckets", zap.Error(err))
+		return nil
+	}
+
+	res := new(ownerStorage)
+	for _, bktInfo := range list {
+		bktUsage, err := buckets.GetBucketUsage(ctx, bktInfo)
+		if err != nil {
+			l.Warn("couldn't get bucket usage", zap.String("bucket", bktInfo.Name), zap.Error(err))
+			return nil
+		}
+		res.size += bktUsage.Size
+		res.objects += bktUsage.Objects
+	}
+
+	return res
+}
+
+func writeAdminJSON(w http.ResponseWriter, log *zap.Logger, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error("couldn't write admin response", zap.Error(err))
+	}
+}
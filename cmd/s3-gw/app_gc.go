@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/cleanup"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+type (
+	// gcFunc collects garbage of the bucket and logs the result.
+	gcFunc func(ctx context.Context, bktInfo *data.BucketInfo, maxAge time.Duration, dryRun bool) error
+
+	// garbageCollector periodically collects garbage of the configured containers.
+	garbageCollector struct {
+		log        *zap.Logger
+		neoFS      *neofs.NeoFS
+		collect    gcFunc
+		containers []cid.ID
+		interval   time.Duration
+		maxAge     time.Duration
+	}
+)
+
+// newMultipartCollector creates collector of multipart garbage. Nil is returned if collection isn't configured.
+func newMultipartCollector(log *zap.Logger, v *viper.Viper, neoFS *neofs.NeoFS, tree cleanup.TreeService) *garbageCollector {
+	return newGarbageCollector(log.With(zap.String("gc", "multipart")), neoFS, multipartGC(log, neoFS, tree),
+		v.GetDuration(cfgMultipartGCInterval), v.GetDuration(cfgMultipartGCMaxAge), v.GetStringSlice(cfgMultipartGCContainers))
+}
+
+// newDeleteMarkersCollector creates collector of expired delete markers. Nil is returned if collection isn't configured.
+func newDeleteMarkersCollector(log *zap.Logger, v *viper.Viper, neoFS *neofs.NeoFS, tree cleanup.VersionsTree) *garbageCollector {
+	return newGarbageCollector(log.With(zap.String("gc", "delete_markers")), neoFS, deleteMarkersGC(log, tree),
+		v.GetDuration(cfgDeleteMarkersGCInterval), v.GetDuration(cfgDeleteMarkersGCMaxAge), v.GetStringSlice(cfgDeleteMarkersGCContainers))
+}
+
+func newGarbageCollector(log *zap.Logger, neoFS *neofs.NeoFS, collect gcFunc, interval, maxAge time.Duration, containers []string) *garbageCollector {
+	if interval <= 0 {
+		return nil
+	}
+
+	cnrIDs, err := parseContainerIDs(containers)
+	if err != nil {
+		log.Fatal("invalid containers of garbage collection", zap.Error(err))
+	}
+
+	return &garbageCollector{
+		log:        log,
+		neoFS:      neoFS,
+		collect:    collect,
+		containers: cnrIDs,
+		interval:   interval,
+		maxAge:     maxAge,
+	}
+}
+
+// Run collects garbage every interval until ctx is done.
+func (c *garbageCollector) Run(ctx context.Context) {
+	c.log.Info("garbage collection started", zap.Duration("interval", c.interval),
+		zap.Duration("max_age", c.maxAge), zap.Int("containers", len(c.containers)))
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.collectContainers(ctx)
+		}
+	}
+}
+
+func (c *garbageCollector) collectContainers(ctx context.Context) {
+	for _, cnrID := range c.containers {
+		if ctx.Err() != nil {
+			return
+		}
+
+		bktInfo, err := containerBucketInfo(ctx, c.neoFS, cnrID)
+		if err == nil {
+			err = c.collect(ctx, bktInfo, c.maxAge, false)
+		}
+		if err != nil {
+			c.log.Error("couldn't collect garbage", zap.Stringer("cid", cnrID), zap.Error(err))
+		}
+	}
+}
+
+func multipartGC(log *zap.Logger, neoFS cleanup.NeoFS, tree cleanup.TreeService) gcFunc {
+	gc := cleanup.NewMultipart(neoFS, tree, log)
+
+	return func(ctx context.Context, bktInfo *data.BucketInfo, maxAge time.Duration, dryRun bool) error {
+		res, err := gc.Collect(ctx, bktInfo, maxAge, dryRun)
+		if err != nil {
+			return err
+		}
+
+		log.Info("multipart garbage of container is collected",
+			zap.Stringer("cid", bktInfo.CID),
+			zap.Bool("dry_run", dryRun),
+			zap.Int("stale_uploads", res.StaleUploads),
+			zap.Int("parts", res.Parts),
+			zap.Uint64("reclaimed_bytes", res.Size))
+		return nil
+	}
+}
+
+func deleteMarkersGC(log *zap.Logger, tree cleanup.VersionsTree) gcFunc {
+	gc := cleanup.NewDeleteMarkers(tree, log)
+
+	return func(ctx context.Context, bktInfo *data.BucketInfo, maxAge time.Duration, dryRun bool) error {
+		res, err := gc.Collect(ctx, bktInfo, maxAge, dryRun)
+		if err != nil {
+			return err
+		}
+
+		log.Info("expired delete markers of container are removed",
+			zap.Stringer("cid", bktInfo.CID),
+			zap.Bool("dry_run", dryRun),
+			zap.Int("objects", res.Objects),
+			zap.Int("removed", res.Removed))
+		return nil
+	}
+}
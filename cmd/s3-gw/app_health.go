@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	healthStatusOK   = "ok"
+	healthStatusFail = "fail"
+)
+
+type (
+	// healthChecker checks readiness of the gateway to serve requests.
+	healthChecker struct {
+		timeout time.Duration
+		checks  []healthCheck
+
+		// ready is set when the object layer with its caches and the listeners are initialized
+		// and the gateway has started to serve requests. It's reset on shutdown.
+		ready int32
+	}
+
+	healthCheck struct {
+		name  string
+		check func(context.Context) error
+	}
+
+	healthResponse struct {
+		Status string                       `json:"status"`
+		Checks map[string]healthCheckResult `json:"checks,omitempty"`
+	}
+
+	healthCheckResult struct {
+		Status string `json:"status"`
+		Error  string `json:"error,omitempty"`
+	}
+)
+
+var errNotServing = errors.New("gateway isn't serving requests")
+
+// NewHealthService creates a new service with liveness (/healthz) and readiness (/readyz) endpoints.
+func NewHealthService(v *viper.Viper, l *zap.Logger, checker *healthChecker) *Service {
+	handler := http.NewServeMux()
+	handler.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeHealthResponse(w, l, healthResponse{Status: healthStatusOK})
+	})
+	handler.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealthResponse(w, l, checker.check(r.Context()))
+	})
+
+	return &Service{
+		Server: &http.Server{
+			Addr:    v.GetString(cfgHealthAddress),
+			Handler: handler,
+		},
+		enabled:     v.GetBool(cfgHealthEnabled),
+		serviceType: "Health",
+		log:         l.With(zap.String("service", "Health")),
+	}
+}
+
+func newHealthChecker(timeout time.Duration) *healthChecker {
+	return &healthChecker{timeout: timeout}
+}
+
+// addCheck adds a named readiness check.
+func (h *healthChecker) addCheck(name string, check func(context.Context) error) {
+	h.checks = append(h.checks, healthCheck{name: name, check: check})
+}
+
+// setReady marks whether the gateway serves requests.
+func (h *healthChecker) setReady(ready bool) {
+	var v int32
+	if ready {
+		v = 1
+	}
+	atomic.StoreInt32(&h.ready, v)
+}
+
+// check runs all checks concurrently and returns their results.
+func (h *healthChecker) check(ctx context.Context) healthResponse {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	results := make(chan result, len(h.checks))
+	for _, c := range h.checks {
+		go func(c healthCheck) {
+			results <- result{name: c.name, err: c.check(ctx)}
+		}(c)
+	}
+
+	resp := healthResponse{
+		Status: healthStatusOK,
+		Checks: make(map[string]healthCheckResult, len(h.checks)+1),
+	}
+
+	serving := healthCheckResult{Status: healthStatusOK}
+	if atomic.LoadInt32(&h.ready) == 0 {
+		serving = healthCheckResult{Status: healthStatusFail, Error: errNotServing.Error()}
+		resp.Status = healthStatusFail
+	}
+	resp.Checks["serving"] = serving
+
+	for range h.checks {
+		res := <-results
+		if res.err != nil {
+			resp.Status = healthStatusFail
+			resp.Checks[res.name] = healthCheckResult{Status: healthStatusFail, Error: res.err.Error()}
+		} else {
+			resp.Checks[res.name] = healthCheckResult{Status: healthStatusOK}
+		}
+	}
+
+	return resp
+}
+
+func writeHealthResponse(w http.ResponseWriter, l *zap.Logger, resp healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != healthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		l.Warn("couldn't write health response", zap.Error(err))
+	}
+}
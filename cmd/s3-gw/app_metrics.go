@@ -3,6 +3,7 @@ package main
 import (
 	"net/http"
 
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -14,6 +15,7 @@ const (
 	namespace      = "neofs_s3_gw"
 	stateSubsystem = "state"
 	poolSubsystem  = "pool"
+	cacheSubsystem = "cache"
 
 	methodGetBalance       = "get_balance"
 	methodPutContainer     = "put_container"
@@ -36,9 +38,18 @@ type StatisticScraper interface {
 	Statistic() pool.Statistic
 }
 
+type RetryStatisticScraper interface {
+	RetriesExhausted() map[string]uint64
+}
+
+type CacheStatisticScraper interface {
+	CacheStatistic() []cache.Statistic
+}
+
 type GateMetrics struct {
 	stateMetrics
 	poolMetricsCollector
+	cacheMetricsCollector
 }
 
 type stateMetrics struct {
@@ -47,29 +58,36 @@ type stateMetrics struct {
 
 type poolMetricsCollector struct {
 	poolStatScraper     StatisticScraper
+	retryStatScraper    RetryStatisticScraper
 	overallErrors       prometheus.Gauge
 	overallNodeErrors   *prometheus.GaugeVec
 	overallNodeRequests *prometheus.GaugeVec
 	currentErrors       *prometheus.GaugeVec
 	requestDuration     *prometheus.GaugeVec
+	retriesExhausted    *prometheus.GaugeVec
 }
 
-func newGateMetrics(scraper StatisticScraper) *GateMetrics {
+func newGateMetrics(scraper StatisticScraper, retryScraper RetryStatisticScraper, cacheScraper CacheStatisticScraper) *GateMetrics {
 	stateMetric := newStateMetrics()
 	stateMetric.register()
 
-	poolMetric := newPoolMetricsCollector(scraper)
+	poolMetric := newPoolMetricsCollector(scraper, retryScraper)
 	poolMetric.register()
 
+	cacheMetric := newCacheMetricsCollector(cacheScraper)
+	cacheMetric.register()
+
 	return &GateMetrics{
-		stateMetrics:         *stateMetric,
-		poolMetricsCollector: *poolMetric,
+		stateMetrics:          *stateMetric,
+		poolMetricsCollector:  *poolMetric,
+		cacheMetricsCollector: *cacheMetric,
 	}
 }
 
 func (g *GateMetrics) Unregister() {
 	g.stateMetrics.unregister()
 	prometheus.Unregister(&g.poolMetricsCollector)
+	prometheus.Unregister(&g.cacheMetricsCollector)
 }
 
 func newStateMetrics() *stateMetrics {
@@ -95,7 +113,7 @@ func (m stateMetrics) SetHealth(s int32) {
 	m.healthCheck.Set(float64(s))
 }
 
-func newPoolMetricsCollector(scraper StatisticScraper) *poolMetricsCollector {
+func newPoolMetricsCollector(scraper StatisticScraper, retryScraper RetryStatisticScraper) *poolMetricsCollector {
 	overallErrors := prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -154,13 +172,27 @@ func newPoolMetricsCollector(scraper StatisticScraper) *poolMetricsCollector {
 		},
 	)
 
+	retriesExhausted := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: poolSubsystem,
+			Name:      "retries_exhausted",
+			Help:      "Total number of object operations failed with transient errors after all retry attempts",
+		},
+		[]string{
+			"operation",
+		},
+	)
+
 	return &poolMetricsCollector{
 		poolStatScraper:     scraper,
+		retryStatScraper:    retryScraper,
 		overallErrors:       overallErrors,
 		overallNodeErrors:   overallNodeErrors,
 		overallNodeRequests: overallNodeRequests,
 		currentErrors:       currentErrors,
 		requestDuration:     requestsDuration,
+		retriesExhausted:    retriesExhausted,
 	}
 }
 
@@ -171,6 +203,7 @@ func (m *poolMetricsCollector) Collect(ch chan<- prometheus.Metric) {
 	m.overallNodeRequests.Collect(ch)
 	m.currentErrors.Collect(ch)
 	m.requestDuration.Collect(ch)
+	m.retriesExhausted.Collect(ch)
 }
 
 func (m *poolMetricsCollector) Describe(descs chan<- *prometheus.Desc) {
@@ -179,6 +212,7 @@ func (m *poolMetricsCollector) Describe(descs chan<- *prometheus.Desc) {
 	m.overallNodeRequests.Describe(descs)
 	m.currentErrors.Describe(descs)
 	m.requestDuration.Describe(descs)
+	m.retriesExhausted.Describe(descs)
 }
 
 func (m *poolMetricsCollector) register() {
@@ -202,6 +236,10 @@ func (m *poolMetricsCollector) updateStatistic() {
 	}
 
 	m.overallErrors.Set(float64(stat.OverallErrors()))
+
+	for operation, count := range m.retryStatScraper.RetriesExhausted() {
+		m.retriesExhausted.WithLabelValues(operation).Set(float64(count))
+	}
 }
 
 func (m *poolMetricsCollector) updateRequestsDuration(node pool.NodeStatistic) {
@@ -222,6 +260,66 @@ func (m *poolMetricsCollector) updateRequestsDuration(node pool.NodeStatistic) {
 	m.requestDuration.WithLabelValues(node.Address(), methodCreateSession).Set(float64(node.AverageCreateSession().Milliseconds()))
 }
 
+type cacheMetricsCollector struct {
+	scraper   CacheStatisticScraper
+	entries   *prometheus.GaugeVec
+	hits      *prometheus.GaugeVec
+	misses    *prometheus.GaugeVec
+	evictions *prometheus.GaugeVec
+}
+
+func newCacheMetricsCollector(scraper CacheStatisticScraper) *cacheMetricsCollector {
+	newGaugeVec := func(name, help string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: cacheSubsystem,
+				Name:      name,
+				Help:      help,
+			},
+			[]string{
+				"cache",
+			},
+		)
+	}
+
+	return &cacheMetricsCollector{
+		scraper:   scraper,
+		entries:   newGaugeVec("entries", "Number of entries in cache"),
+		hits:      newGaugeVec("hits", "Total number of lookups found in cache"),
+		misses:    newGaugeVec("misses", "Total number of lookups not found in cache"),
+		evictions: newGaugeVec("evictions", "Total number of entries removed from cache due to size limit or expiration"),
+	}
+}
+
+func (m *cacheMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	m.updateStatistic()
+	m.entries.Collect(ch)
+	m.hits.Collect(ch)
+	m.misses.Collect(ch)
+	m.evictions.Collect(ch)
+}
+
+func (m *cacheMetricsCollector) Describe(descs chan<- *prometheus.Desc) {
+	m.entries.Describe(descs)
+	m.hits.Describe(descs)
+	m.misses.Describe(descs)
+	m.evictions.Describe(descs)
+}
+
+func (m *cacheMetricsCollector) register() {
+	prometheus.MustRegister(m)
+}
+
+func (m *cacheMetricsCollector) updateStatistic() {
+	for _, stat := range m.scraper.CacheStatistic() {
+		m.entries.WithLabelValues(stat.Name).Set(float64(stat.Entries))
+		m.hits.WithLabelValues(stat.Name).Set(float64(stat.Hits))
+		m.misses.WithLabelValues(stat.Name).Set(float64(stat.Misses))
+		m.evictions.WithLabelValues(stat.Name).Set(float64(stat.Evictions))
+	}
+}
+
 // NewPrometheusService creates a new service for gathering prometheus metrics.
 func NewPrometheusService(v *viper.Viper, log *zap.Logger) *Service {
 	if log == nil {
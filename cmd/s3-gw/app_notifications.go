@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/notifications"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// deadLetterBucket puts notification events which couldn't be delivered to the bucket.
+type deadLetterBucket struct {
+	obj    layer.Client
+	bucket string
+	prefix string
+}
+
+// newEventsQueue opens the persistent queue of notifications. Nil is returned if the queue isn't configured.
+func newEventsQueue(log *zap.Logger, v *viper.Viper, obj layer.Client) *notifications.Queue {
+	path := v.GetString(cfgEventsQueuePath)
+	if path == "" {
+		return nil
+	}
+
+	opts := notifications.QueueOptions{
+		Path: path,
+		Retry: notifications.RetryPolicy{
+			MaxAttempts:    v.GetInt(cfgEventsQueueMaxAttempts),
+			InitialBackoff: v.GetDuration(cfgEventsQueueInitialBackoff),
+			MaxBackoff:     v.GetDuration(cfgEventsQueueMaxBackoff),
+		},
+	}
+
+	if bucket := v.GetString(cfgEventsQueueDeadLetterBucket); bucket != "" {
+		opts.DeadLetter = &deadLetterBucket{
+			obj:    obj,
+			bucket: bucket,
+			prefix: v.GetString(cfgEventsQueueDeadLetterPrefix),
+		}
+	} else {
+		log.Warn("dead letter bucket isn't set, undelivered events will be dropped")
+	}
+
+	queue, err := notifications.OpenQueue(opts, log)
+	if err != nil {
+		log.Fatal("failed to open events queue", zap.Error(err))
+	}
+
+	return queue
+}
+
+// PutDeadLetter implements notifications.DeadLetter.
+func (d *deadLetterBucket) PutDeadLetter(ctx context.Context, name string, payload []byte) error {
+	bktInfo, err := d.obj.GetBucketInfo(ctx, d.bucket)
+	if err != nil {
+		return fmt.Errorf("get dead letter bucket info: %w", err)
+	}
+
+	_, err = d.obj.PutObject(ctx, &layer.PutObjectParams{
+		BktInfo: bktInfo,
+		Object:  d.prefix + name,
+		Size:    int64(len(payload)),
+		Reader:  bytes.NewReader(payload),
+		Header:  map[string]string{api.ContentType: "application/json"},
+	})
+	if err != nil {
+		return fmt.Errorf("put dead letter object '%s': %w", d.prefix+name, err)
+	}
+
+	return nil
+}
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base32"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"runtime"
 	"sort"
@@ -9,13 +16,25 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/audit"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/notifications"
 	"github.com/nspcc-dev/neofs-s3-gw/api/resolver"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/redis"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/version"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 const (
@@ -25,10 +44,61 @@ const (
 	defaultStreamTimeout      = 10 * time.Second
 	defaultShutdownTimeout    = 15 * time.Second
 
+	defaultProxyProtocolHeaderTimeout = 10 * time.Second
+
 	defaultPoolErrorThreshold uint32 = 100
 
 	defaultMaxClientsCount    = 100
 	defaultMaxClientsDeadline = time.Second * 30
+
+	defaultCompleteMultipartKeepalive = 10 * time.Second
+
+	defaultDeleteWorkers = 16
+	defaultListWorkers   = 16
+
+	defaultParallelGetPartSize = 8 << 20
+	defaultParallelGetWorkers  = 4
+
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 2 * time.Second
+
+	defaultEventsQueueMaxAttempts    = 10
+	defaultEventsQueueInitialBackoff = time.Second
+	defaultEventsQueueMaxBackoff     = 5 * time.Minute
+	defaultEventsDeadLetterPrefix    = "dead-letter/"
+
+	defaultTreeHealthcheckInterval = 10 * time.Second
+
+	defaultAccessBoxRenewalInterval  = time.Minute
+	defaultAccessBoxRenewalThreshold = 10 * time.Minute
+
+	defaultAccessLogOutput      = "stdout"
+	defaultAccessLogSampleRatio = 1.0
+
+	defaultHealthTimeout = 5 * time.Second
+
+	etagSourceChecksum = "checksum"
+	etagSourceMD5      = "md5"
+
+	cacheBackendLocal        = "local"
+	cacheBackendRedis        = "redis"
+	defaultCacheRedisTimeout = time.Second
+
+	defaultAuditLogOutput    = "file"
+	defaultAuditLogSyslogTag = "neofs-s3-gw"
+
+	defaultTracingSampleRatio = 1.0
+
+	defaultUsageExportInterval = time.Hour
+	defaultUsageExportPrefix   = "usage/"
+	defaultUsageExportFormat   = usageFormatCSV
+
+	defaultMultipartGCMaxAge     = 7 * 24 * time.Hour
+	defaultDeleteMarkersGCMaxAge = 24 * time.Hour
+
+	defaultReplayProtectionClockSkew = 15 * time.Minute
+	defaultPresignedNoncesSize       = 1e5
 )
 
 const ( // Settings.
@@ -47,6 +117,19 @@ const ( // Settings.
 	cfgTLSEnabled  = "tls.enabled"
 	cfgTLSKeyFile  = "tls.key_file"
 	cfgTLSCertFile = "tls.cert_file"
+	cfgTLSHTTP2    = "tls.http2"
+
+	cfgProxyProtocol = "proxy_protocol"
+
+	// Interval of checking TLS certificate files for changes.
+	cfgTLSReloadInterval = "tls_reload_interval"
+
+	// ACME certificates management.
+	cfgACMEEnabled      = "acme.enabled"
+	cfgACMEEmail        = "acme.email"
+	cfgACMEDirectoryURL = "acme.directory_url"
+	cfgACMECacheDir     = "acme.cache_dir"
+	cfgACMEDomains      = "acme.domains"
 
 	// Pool config.
 	cfgConnectTimeout     = "connect_timeout"
@@ -56,20 +139,34 @@ const ( // Settings.
 	cfgPoolErrorThreshold = "pool_error_threshold"
 
 	// Caching.
+	cfgObjectsCacheEnabled        = "cache.objects.enabled"
 	cfgObjectsCacheLifetime       = "cache.objects.lifetime"
 	cfgObjectsCacheSize           = "cache.objects.size"
+	cfgListObjectsCacheEnabled    = "cache.list.enabled"
 	cfgListObjectsCacheLifetime   = "cache.list.lifetime"
 	cfgListObjectsCacheSize       = "cache.list.size"
 	cfgBucketsCacheLifetime       = "cache.buckets.lifetime"
 	cfgBucketsCacheSize           = "cache.buckets.size"
+	cfgNamesCacheEnabled          = "cache.names.enabled"
 	cfgNamesCacheLifetime         = "cache.names.lifetime"
 	cfgNamesCacheSize             = "cache.names.size"
+	cfgSystemCacheEnabled         = "cache.system.enabled"
 	cfgSystemCacheLifetime        = "cache.system.lifetime"
 	cfgSystemCacheSize            = "cache.system.size"
 	cfgAccessBoxCacheLifetime     = "cache.accessbox.lifetime"
 	cfgAccessBoxCacheSize         = "cache.accessbox.size"
 	cfgAccessControlCacheLifetime = "cache.accesscontrol.lifetime"
 	cfgAccessControlCacheSize     = "cache.accesscontrol.size"
+	cfgEACLCacheEnabled           = "cache.eacl.enabled"
+	cfgEACLCacheLifetime          = "cache.eacl.lifetime"
+	cfgEACLCacheSize              = "cache.eacl.size"
+
+	// Shared cache backend.
+	cfgCacheBackend       = "cache.backend"
+	cfgCacheRedisAddress  = "cache.redis.address"
+	cfgCacheRedisPassword = "cache.redis.password"
+	cfgCacheRedisDB       = "cache.redis.db"
+	cfgCacheRedisTimeout  = "cache.redis.timeout"
 
 	// NATS.
 	cfgEnableNATS             = "nats.enabled"
@@ -79,29 +176,97 @@ const ( // Settings.
 	cfgNATSAuthPrivateKeyFile = "nats.key_file"
 	cfgNATSRootCAFiles        = "nats.root_ca"
 
+	// Webhook, Kafka and AMQP destinations of notifications.
+	cfgWebhooks = "webhooks"
+	cfgKafka    = "kafka"
+	cfgAMQP     = "amqp"
+
+	// Persistent queue of notifications.
+	cfgEventsQueuePath             = "events_queue.path"
+	cfgEventsQueueMaxAttempts      = "events_queue.max_attempts"
+	cfgEventsQueueInitialBackoff   = "events_queue.initial_backoff"
+	cfgEventsQueueMaxBackoff       = "events_queue.max_backoff"
+	cfgEventsQueueDeadLetterBucket = "events_queue.dead_letter.bucket"
+	cfgEventsQueueDeadLetterPrefix = "events_queue.dead_letter.prefix"
+
 	// Policy.
 	cfgPolicyDefault       = "placement_policy.default"
 	cfgPolicyRegionMapFile = "placement_policy.region_mapping"
 
 	// CORS.
-	cfgDefaultMaxAge = "cors.default_max_age"
+	cfgDefaultMaxAge    = "cors.default_max_age"
+	cfgCORSDefaultRules = "cors.default_rules"
+
+	// Custom container attributes of buckets.
+	cfgContainerAttributesHeaderPrefixes = "container_attributes.header_prefixes"
+	cfgContainerAttributesExpose         = "container_attributes.expose"
+
+	// Timeout of requests draining on shutdown.
+	cfgShutdownTimeout = "shutdown_timeout"
+
+	// Operation mode: normal, read_only or maintenance.
+	cfgMode = "mode"
+
+	// Requests taking longer are logged with the time of their phases, 0 disables logging.
+	cfgSlowRequestThreshold = "slow_request_threshold"
+
+	// Source of ETag of new objects: `checksum` (SHA256 payload checksum) or `md5`.
+	cfgETagSource = "etag_source"
+
+	// Networks of proxies allowed to pass client address in X-Forwarded-For, X-Real-IP and Forwarded headers.
+	cfgTrustedProxies = "trusted_proxies"
+
+	// Source IP filter.
+	cfgIPFilterAllow   = "ip_filter.allow"
+	cfgIPFilterDeny    = "ip_filter.deny"
+	cfgIPFilterBuckets = "ip_filter.buckets"
 
 	// MaxClients.
 	cfgMaxClientsCount    = "max_clients_count"
 	cfgMaxClientsDeadline = "max_clients_deadline"
 
 	// Metrics / Profiler / Web.
-	cfgPrometheusEnabled = "prometheus.enabled"
-	cfgPrometheusAddress = "prometheus.address"
-	cfgPProfEnabled      = "pprof.enabled"
-	cfgPProfAddress      = "pprof.address"
+	cfgPrometheusEnabled       = "prometheus.enabled"
+	cfgPrometheusAddress       = "prometheus.address"
+	cfgPrometheusBucketMetrics = "prometheus.bucket_metrics"
+	cfgPProfEnabled            = "pprof.enabled"
+	cfgPProfAddress            = "pprof.address"
+
+	cfgHealthEnabled = "health.enabled"
+	cfgHealthAddress = "health.address"
+	cfgHealthTimeout = "health.timeout"
+
+	cfgAdminEnabled      = "admin.enabled"
+	cfgAdminAddress      = "admin.address"
+	cfgAdminToken        = "admin.token"
+	cfgAdminBucketOwners = "admin.bucket_owners"
 
 	cfgListenDomains = "listen_domains"
 
+	// Custom domains mapped to buckets.
+	cfgCustomDomains = "custom_domains"
+
+	// Rate limits.
+	cfgRateLimitClientRequests = "rate_limit.client.requests_per_second"
+	cfgRateLimitClientBytes    = "rate_limit.client.bytes_per_second"
+	cfgRateLimitBucketRequests = "rate_limit.bucket.requests_per_second"
+	cfgRateLimitBucketBytes    = "rate_limit.bucket.bytes_per_second"
+
 	// Peers.
 	cfgPeers = "peers"
 
 	cfgTreeServiceEndpoint = "tree.service"
+	// Failover between tree service endpoints.
+	cfgTreeHealthcheckInterval = "tree.healthcheck_interval"
+	cfgTreeRetryMaxAttempts    = "tree.retry.max_attempts"
+	cfgTreeRetryInitialBackoff = "tree.retry.initial_backoff"
+	cfgTreeRetryMaxBackoff     = "tree.retry.max_backoff"
+	// TLS of the connection to the tree service.
+	cfgTreeTLSEnabled    = "tree.tls.enabled"
+	cfgTreeTLSCAFile     = "tree.tls.ca_file"
+	cfgTreeTLSCertFile   = "tree.tls.cert_file"
+	cfgTreeTLSKeyFile    = "tree.tls.key_file"
+	cfgTreeTLSServerName = "tree.tls.server_name"
 
 	// NeoGo.
 	cfgRPCEndpoint = "rpc_endpoint"
@@ -121,13 +286,123 @@ const ( // Settings.
 
 	cmdListenAddress = "listen_address"
 
+	// Commands run instead of the gateway.
+	cmdMigrateVersions = "migrate-versions"
+	cmdGCMultipart     = "gc-multipart"
+	cmdGCDeleteMarkers = "gc-delete-markers"
+	cmdDryRun          = "dry-run"
+
 	// Configuration of parameters of requests to NeoFS.
 	// Number of the object copies to consider PUT to NeoFS successful.
 	cfgSetCopiesNumber = "neofs.set_copies_number"
+	// Number of objects removed concurrently in DeleteObjects request.
+	cfgDeleteWorkers = "neofs.delete_workers"
+	cfgListWorkers   = "neofs.list_workers"
+	// Fetching of payload of large objects by ranges concurrently.
+	cfgParallelGetThreshold = "neofs.parallel_get.threshold"
+	cfgParallelGetPartSize  = "neofs.parallel_get.part_size"
+	cfgParallelGetWorkers   = "neofs.parallel_get.workers"
+	// Sizes of pooled buffers streaming object payload.
+	cfgBufferSizeGet = "neofs.buffer_size.get"
+	cfgBufferSizePut = "neofs.buffer_size.put"
+	// Storage classes to copies number mapping.
+	cfgStorageClasses = "neofs.storage_classes"
+	// Number of concurrent object payload operations (put, get) to NeoFS.
+	cfgMaxDataOperations = "neofs.max_data_operations"
+	// Number of concurrent object metadata operations (head, delete) to NeoFS.
+	cfgMaxMetadataOperations = "neofs.max_metadata_operations"
+	// Timeouts of object operations to NeoFS by operation class.
+	cfgTimeoutHead   = "neofs.timeouts.head"
+	cfgTimeoutSearch = "neofs.timeouts.search"
+	cfgTimeoutGet    = "neofs.timeouts.get"
+	cfgTimeoutPut    = "neofs.timeouts.put"
+	cfgTimeoutDelete = "neofs.timeouts.delete"
+	// Retries of object operations to NeoFS failed with transient errors.
+	cfgRetryMaxAttempts    = "neofs.retry.max_attempts"
+	cfgRetryInitialBackoff = "neofs.retry.initial_backoff"
+	cfgRetryMaxBackoff     = "neofs.retry.max_backoff"
+
+	// Transforms.
+	cfgTransforms = "transforms"
+
+	// Virtual MFA devices for MFA Delete.
+	cfgMFADevices = "mfa.devices"
+
+	// Web identity federation.
+	cfgWebIdentityIssuer      = "web_identity.issuer"
+	cfgWebIdentityJWKSURL     = "web_identity.jwks_url"
+	cfgWebIdentityAudiences   = "web_identity.audiences"
+	cfgWebIdentityContainerID = "web_identity.container_id"
+	cfgWebIdentityTimeout     = "web_identity.timeout"
+	cfgWebIdentityRoles       = "web_identity.roles"
+
+	// Kludge.
+	cfgKludgeCompleteMultipartUploadKeepalive = "kludge.complete_multipart_keepalive"
+	cfgKludgeRelaxedBucketNames               = "kludge.relaxed_bucket_names"
+	cfgKludgeDefaultPolicyForUnknownLocation  = "kludge.default_policy_for_unknown_location"
 
 	// List of allowed AccessKeyID prefixes.
 	cfgAllowedAccessKeyIDPrefixes = "allowed_access_key_id_prefixes"
 
+	// List of revoked AccessKeyIDs.
+	cfgRevokedAccessKeyIDs = "revoked_access_key_ids"
+
+	// Container with access boxes of imported AccessKeyIDs.
+	cfgImportedAccessKeysContainerID = "imported_access_keys_container_id"
+
+	// Replay protection.
+	cfgReplayProtectionClockSkew              = "replay_protection.clock_skew"
+	cfgReplayProtectionMaxPresignedExpiration = "replay_protection.max_presigned_expiration"
+	cfgReplayProtectionSingleUsePresign       = "replay_protection.single_use_presigned"
+	cfgReplayProtectionNoncesSize             = "replay_protection.nonces_size"
+
+	// Renewal of access boxes.
+	cfgAccessBoxRenewalInterval  = "accessbox_renewal.interval"
+	cfgAccessBoxRenewalThreshold = "accessbox_renewal.threshold"
+
+	// Access log.
+	cfgAccessLogEnabled     = "access_log.enabled"
+	cfgAccessLogOutput      = "access_log.output"
+	cfgAccessLogFields      = "access_log.fields"
+	cfgAccessLogSampleRatio = "access_log.sample_ratio"
+	cfgAccessLogLogErrors   = "access_log.log_errors"
+
+	// Security audit log.
+	cfgAuditLogEnabled       = "audit_log.enabled"
+	cfgAuditLogOutput        = "audit_log.output"
+	cfgAuditLogPath          = "audit_log.path"
+	cfgAuditLogSyslogNetwork = "audit_log.syslog.network"
+	cfgAuditLogSyslogAddress = "audit_log.syslog.address"
+	cfgAuditLogSyslogTag     = "audit_log.syslog.tag"
+
+	// OpenTelemetry tracing.
+	cfgTracingEnabled     = "tracing.enabled"
+	cfgTracingEndpoint    = "tracing.endpoint"
+	cfgTracingInsecure    = "tracing.insecure"
+	cfgTracingSampleRatio = "tracing.sample_ratio"
+
+	// Usage accounting.
+	cfgUsageEnabled        = "usage.enabled"
+	cfgUsageMetrics        = "usage.metrics"
+	cfgUsageExportBucket   = "usage.export.bucket"
+	cfgUsageExportPrefix   = "usage.export.prefix"
+	cfgUsageExportFormat   = "usage.export.format"
+	cfgUsageExportInterval = "usage.export.interval"
+
+	// Garbage collection of multipart uploads.
+	cfgMultipartGCInterval   = "multipart_gc.interval"
+	cfgMultipartGCMaxAge     = "multipart_gc.max_age"
+	cfgMultipartGCContainers = "multipart_gc.containers"
+
+	// Removal of expired delete markers.
+	cfgDeleteMarkersGCInterval   = "delete_markers_gc.interval"
+	cfgDeleteMarkersGCMaxAge     = "delete_markers_gc.max_age"
+	cfgDeleteMarkersGCContainers = "delete_markers_gc.containers"
+
+	// S3 Batch Operations jobs served on S3 Control hosts.
+	cfgBatchJobsDomains     = "batch_jobs.domains"
+	cfgBatchJobsContainerID = "batch_jobs.container_id"
+
 	// envPrefix is an environment variables prefix used for configuration.
 	envPrefix = "S3_GW"
 )
@@ -139,6 +414,7 @@ var ignore = map[string]struct{}{
 
 	cmdHelp:    {},
 	cmdVersion: {},
+	cmdDryRun:  {},
 }
 
 func fetchPeers(l *zap.Logger, v *viper.Viper) []pool.NodeParam {
@@ -181,6 +457,9 @@ func fetchServers(v *viper.Viper) []ServerInfo {
 		serverInfo.TLS.Enabled = v.GetBool(key + cfgTLSEnabled)
 		serverInfo.TLS.KeyFile = v.GetString(key + cfgTLSKeyFile)
 		serverInfo.TLS.CertFile = v.GetString(key + cfgTLSCertFile)
+		serverInfo.TLS.ACME = v.GetBool(cfgACMEEnabled) && serverInfo.TLS.CertFile == "" && serverInfo.TLS.KeyFile == ""
+		serverInfo.TLS.HTTP2 = v.GetBool(key + cfgTLSHTTP2)
+		serverInfo.ProxyProtocol = v.GetBool(key + cfgProxyProtocol)
 
 		if serverInfo.Address == "" {
 			break
@@ -192,7 +471,501 @@ func fetchServers(v *viper.Viper) []ServerInfo {
 	return servers
 }
 
-func newSettings() *viper.Viper {
+func fetchACMEManager(l *zap.Logger, v *viper.Viper, customDomains *api.CustomDomains) *autocert.Manager {
+	if !v.GetBool(cfgACMEEnabled) {
+		return nil
+	}
+
+	cacheDir := v.GetString(cfgACMECacheDir)
+	if cacheDir == "" {
+		l.Fatal("acme cache dir must be set", zap.String("parameter", cfgACMECacheDir))
+	}
+
+	// Certificates can be issued for the exact hosts only, wildcard hosts
+	// of virtual-hosted-style requests aren't supported by HTTP-01 and TLS-ALPN-01 challenges.
+	hosts := v.GetStringSlice(cfgACMEDomains)
+	hosts = append(hosts, v.GetStringSlice(cfgListenDomains)...)
+	hosts = append(hosts, customDomains.Hosts()...)
+	hosts = append(hosts, v.GetStringSlice(cfgBatchJobsDomains)...)
+
+	if len(hosts) == 0 {
+		l.Fatal("no domains to issue acme certificates for")
+	}
+
+	l.Info("acme certificates management is enabled",
+		zap.Strings("domains", hosts),
+		zap.String("cache_dir", cacheDir))
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cacheDir),
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Email:      v.GetString(cfgACMEEmail),
+		Client:     &acme.Client{DirectoryURL: v.GetString(cfgACMEDirectoryURL)},
+	}
+}
+
+func fetchRateLimits(v *viper.Viper) (api.RateLimit, api.RateLimit) {
+	client := api.RateLimit{
+		RequestsPerSecond: v.GetFloat64(cfgRateLimitClientRequests),
+		BytesPerSecond:    v.GetFloat64(cfgRateLimitClientBytes),
+	}
+	bucket := api.RateLimit{
+		RequestsPerSecond: v.GetFloat64(cfgRateLimitBucketRequests),
+		BytesPerSecond:    v.GetFloat64(cfgRateLimitBucketBytes),
+	}
+
+	return client, bucket
+}
+
+// fetchTreeTLSConfig returns TLS config of the connection to the tree service
+// or nil if TLS is disabled.
+func fetchTreeTLSConfig(v *viper.Viper) (*tls.Config, error) {
+	if !v.GetBool(cfgTreeTLSEnabled) {
+		return nil, nil
+	}
+
+	return loadTLSConfig(v.GetString(cfgTreeTLSCAFile), v.GetString(cfgTreeTLSCertFile),
+		v.GetString(cfgTreeTLSKeyFile), v.GetString(cfgTreeTLSServerName))
+}
+
+// loadTLSConfig creates client TLS config verifying servers with certificates from caFile or system
+// root certificates if caFile is empty. Client certificate is used if certFile is set.
+func loadTLSConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+
+		tlsCfg.RootCAs = x509.NewCertPool()
+		if !tlsCfg.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA file '%s'", caFile)
+		}
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+func fetchOperationTimeouts(v *viper.Viper) neofs.OperationTimeouts {
+	return neofs.OperationTimeouts{
+		Head:   v.GetDuration(cfgTimeoutHead),
+		Search: v.GetDuration(cfgTimeoutSearch),
+		Get:    v.GetDuration(cfgTimeoutGet),
+		Put:    v.GetDuration(cfgTimeoutPut),
+		Delete: v.GetDuration(cfgTimeoutDelete),
+	}
+}
+
+func fetchRetryPolicy(v *viper.Viper) neofs.RetryPolicy {
+	return neofs.RetryPolicy{
+		MaxAttempts:    v.GetInt(cfgRetryMaxAttempts),
+		InitialBackoff: v.GetDuration(cfgRetryInitialBackoff),
+		MaxBackoff:     v.GetDuration(cfgRetryMaxBackoff),
+	}
+}
+
+func fetchTreeRetryPolicy(v *viper.Viper) neofs.RetryPolicy {
+	return neofs.RetryPolicy{
+		MaxAttempts:    v.GetInt(cfgTreeRetryMaxAttempts),
+		InitialBackoff: v.GetDuration(cfgTreeRetryInitialBackoff),
+		MaxBackoff:     v.GetDuration(cfgTreeRetryMaxBackoff),
+	}
+}
+
+func fetchIPFilterRules(v *viper.Viper) (api.IPRule, map[string]api.IPRule) {
+	global := api.IPRule{
+		Allow: v.GetStringSlice(cfgIPFilterAllow),
+		Deny:  v.GetStringSlice(cfgIPFilterDeny),
+	}
+
+	buckets := make(map[string]api.IPRule)
+	for i := 0; ; i++ {
+		key := cfgIPFilterBuckets + "." + strconv.Itoa(i) + "."
+		name := v.GetString(key + "name")
+		if name == "" {
+			break
+		}
+
+		buckets[name] = api.IPRule{
+			Allow: v.GetStringSlice(key + "allow"),
+			Deny:  v.GetStringSlice(key + "deny"),
+		}
+	}
+
+	return global, buckets
+}
+
+func fetchWebhooks(v *viper.Viper) []notifications.WebhookOptions {
+	var webhooks []notifications.WebhookOptions
+
+	for i := 0; ; i++ {
+		key := cfgWebhooks + "." + strconv.Itoa(i) + "."
+		name := v.GetString(key + "name")
+		if name == "" {
+			break
+		}
+
+		retry := notifications.RetryPolicy{
+			MaxAttempts:    defaultRetryMaxAttempts,
+			InitialBackoff: defaultRetryInitialBackoff,
+			MaxBackoff:     defaultRetryMaxBackoff,
+		}
+		if v.IsSet(key + "retry.max_attempts") {
+			retry.MaxAttempts = v.GetInt(key + "retry.max_attempts")
+		}
+		if v.IsSet(key + "retry.initial_backoff") {
+			retry.InitialBackoff = v.GetDuration(key + "retry.initial_backoff")
+		}
+		if v.IsSet(key + "retry.max_backoff") {
+			retry.MaxBackoff = v.GetDuration(key + "retry.max_backoff")
+		}
+
+		webhooks = append(webhooks, notifications.WebhookOptions{
+			Name:    name,
+			URL:     v.GetString(key + "url"),
+			Headers: v.GetStringMapString(key + "headers"),
+			Timeout: v.GetDuration(key + "timeout"),
+			Retry:   retry,
+		})
+	}
+
+	return webhooks
+}
+
+func fetchKafka(v *viper.Viper) ([]notifications.KafkaOptions, error) {
+	var destinations []notifications.KafkaOptions
+
+	for i := 0; ; i++ {
+		key := cfgKafka + "." + strconv.Itoa(i) + "."
+		name := v.GetString(key + "name")
+		if name == "" {
+			break
+		}
+
+		opts := notifications.KafkaOptions{
+			Name:    name,
+			Brokers: v.GetStringSlice(key + "brokers"),
+			Topic:   v.GetString(key + "topic"),
+			SASL: notifications.SASLOptions{
+				Mechanism: v.GetString(key + "sasl.mechanism"),
+				Username:  v.GetString(key + "sasl.username"),
+				Password:  v.GetString(key + "sasl.password"),
+			},
+			Timeout:     v.GetDuration(key + "timeout"),
+			MaxAttempts: defaultRetryMaxAttempts,
+		}
+		if v.IsSet(key + "max_attempts") {
+			opts.MaxAttempts = v.GetInt(key + "max_attempts")
+		}
+
+		if v.GetBool(key + "tls.enabled") {
+			var err error
+			opts.TLS, err = loadTLSConfig(v.GetString(key+"tls.ca_file"), v.GetString(key+"tls.cert_file"),
+				v.GetString(key+"tls.key_file"), v.GetString(key+"tls.server_name"))
+			if err != nil {
+				return nil, fmt.Errorf("kafka destination '%s': %w", name, err)
+			}
+		}
+
+		destinations = append(destinations, opts)
+	}
+
+	return destinations, nil
+}
+
+func fetchAMQP(v *viper.Viper) ([]notifications.AMQPOptions, error) {
+	var destinations []notifications.AMQPOptions
+
+	for i := 0; ; i++ {
+		key := cfgAMQP + "." + strconv.Itoa(i) + "."
+		name := v.GetString(key + "name")
+		if name == "" {
+			break
+		}
+
+		opts := notifications.AMQPOptions{
+			Name:         name,
+			URL:          v.GetString(key + "url"),
+			Exchange:     v.GetString(key + "exchange"),
+			ExchangeType: v.GetString(key + "exchange_type"),
+			RoutingKey:   v.GetString(key + "routing_key"),
+			Durable:      v.GetBool(key + "durable"),
+			Timeout:      v.GetDuration(key + "timeout"),
+		}
+
+		if v.GetBool(key + "tls.enabled") {
+			var err error
+			opts.TLS, err = loadTLSConfig(v.GetString(key+"tls.ca_file"), v.GetString(key+"tls.cert_file"),
+				v.GetString(key+"tls.key_file"), v.GetString(key+"tls.server_name"))
+			if err != nil {
+				return nil, fmt.Errorf("amqp destination '%s': %w", name, err)
+			}
+		}
+
+		destinations = append(destinations, opts)
+	}
+
+	return destinations, nil
+}
+
+func fetchCustomDomains(l *zap.Logger, v *viper.Viper) []api.CustomDomain {
+	var domains []api.CustomDomain
+
+	for i := 0; ; i++ {
+		key := cfgCustomDomains + "." + strconv.Itoa(i) + "."
+		host := v.GetString(key + "host")
+		bucket := v.GetString(key + "bucket")
+		prefix := v.GetString(key + "prefix")
+
+		if host == "" || bucket == "" {
+			break
+		}
+
+		domains = append(domains, api.CustomDomain{
+			Host:   host,
+			Bucket: bucket,
+			Prefix: prefix,
+		})
+
+		l.Info("added custom domain",
+			zap.String("host", host),
+			zap.String("bucket", bucket),
+			zap.String("prefix", prefix))
+	}
+
+	return domains
+}
+
+func fetchStorageClasses(l *zap.Logger, v *viper.Viper) map[string]uint32 {
+	storageClasses := make(map[string]uint32)
+
+	for i := 0; ; i++ {
+		key := cfgStorageClasses + "." + strconv.Itoa(i) + "."
+		name := v.GetString(key + "name")
+		copiesNumber := v.GetUint32(key + "copies_number")
+
+		if name == "" {
+			break
+		}
+
+		storageClasses[name] = copiesNumber
+
+		l.Info("added storage class",
+			zap.String("name", name),
+			zap.Uint32("copies_number", copiesNumber))
+	}
+
+	return storageClasses
+}
+
+func fetchTransforms(l *zap.Logger, v *viper.Viper) []handler.TransformRule {
+	var rules []handler.TransformRule
+
+	for i := 0; ; i++ {
+		key := cfgTransforms + "." + strconv.Itoa(i) + "."
+		bucket := v.GetString(key + "bucket")
+		prefix := v.GetString(key + "prefix")
+		url := v.GetString(key + "url")
+		timeout := v.GetDuration(key + "timeout")
+
+		if bucket == "" || url == "" {
+			break
+		}
+
+		rules = append(rules, handler.TransformRule{
+			Bucket:      bucket,
+			Prefix:      prefix,
+			Transformer: &handler.HTTPObjectTransformer{URL: url, Client: &http.Client{Timeout: timeout}},
+		})
+
+		l.Info("added object transform hook",
+			zap.String("bucket", bucket),
+			zap.String("prefix", prefix),
+			zap.String("url", url))
+	}
+
+	return rules
+}
+
+// fetchMFA returns nil if no MFA devices are configured.
+func fetchMFA(l *zap.Logger, v *viper.Viper) handler.MFAValidator {
+	secrets := make(map[string][]byte)
+
+	for i := 0; ; i++ {
+		key := cfgMFADevices + "." + strconv.Itoa(i) + "."
+		serialNumber := v.GetString(key + "serial_number")
+		secret := v.GetString(key + "secret")
+
+		if serialNumber == "" {
+			break
+		}
+
+		decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+		if err != nil || len(decoded) == 0 {
+			l.Fatal("invalid secret of mfa device", zap.String("serial_number", serialNumber), zap.Error(err))
+		}
+		secrets[serialNumber] = decoded
+
+		l.Info("added mfa device", zap.String("serial_number", serialNumber))
+	}
+
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	return handler.NewTOTPValidator(secrets)
+}
+
+// fetchDefaultCORS returns nil if no default CORS rules are configured.
+func fetchDefaultCORS(v *viper.Viper) (*data.CORSConfiguration, error) {
+	cors := &data.CORSConfiguration{}
+
+	for i := 0; ; i++ {
+		key := cfgCORSDefaultRules + "." + strconv.Itoa(i) + "."
+		rule := data.CORSRule{
+			AllowedOrigins: v.GetStringSlice(key + "allowed_origins"),
+			AllowedMethods: v.GetStringSlice(key + "allowed_methods"),
+			AllowedHeaders: v.GetStringSlice(key + "allowed_headers"),
+			ExposeHeaders:  v.GetStringSlice(key + "expose_headers"),
+		}
+		if v.IsSet(key + "max_age_seconds") {
+			maxAge := v.GetInt(key + "max_age_seconds")
+			rule.MaxAgeSeconds = &maxAge
+		}
+
+		if len(rule.AllowedOrigins) == 0 || len(rule.AllowedMethods) == 0 {
+			break
+		}
+
+		cors.CORSRules = append(cors.CORSRules, rule)
+	}
+
+	if len(cors.CORSRules) == 0 {
+		return nil, nil
+	}
+
+	if err := layer.CheckCORS(cors); err != nil {
+		return nil, fmt.Errorf("invalid default cors rules '%s': %w", cfgCORSDefaultRules, err)
+	}
+
+	return cors, nil
+}
+
+// fetchPresignedNoncesSize returns zero if presigned URLs can be reused.
+func fetchPresignedNoncesSize(l *zap.Logger, v *viper.Viper) int {
+	if !v.GetBool(cfgReplayProtectionSingleUsePresign) {
+		return 0
+	}
+
+	size := v.GetInt(cfgReplayProtectionNoncesSize)
+	if size <= 0 {
+		l.Fatal("invalid size of presigned URLs nonces cache", zap.Int("size", size))
+	}
+
+	return size
+}
+
+// fetchImportedAccessKeysContainer returns nil if imported access key ids aren't accepted.
+func fetchImportedAccessKeysContainer(l *zap.Logger, v *viper.Viper) *cid.ID {
+	cnrStr := v.GetString(cfgImportedAccessKeysContainerID)
+	if cnrStr == "" {
+		return nil
+	}
+
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(cnrStr); err != nil {
+		l.Fatal("invalid container id of imported access keys", zap.Error(err))
+	}
+
+	return &cnrID
+}
+
+// fetchBatchJobsContainer returns nil if states of batch jobs aren't persisted.
+func fetchBatchJobsContainer(l *zap.Logger, v *viper.Viper) *cid.ID {
+	cnrStr := v.GetString(cfgBatchJobsContainerID)
+	if cnrStr == "" {
+		return nil
+	}
+
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(cnrStr); err != nil {
+		l.Fatal("invalid container id of batch jobs", zap.Error(err))
+	}
+
+	return &cnrID
+}
+
+// fetchWebIdentity returns nil if web identity federation isn't configured.
+func fetchWebIdentity(l *zap.Logger, v *viper.Viper) *auth.WebIdentityConfig {
+	issuer := v.GetString(cfgWebIdentityIssuer)
+	if issuer == "" {
+		return nil
+	}
+
+	cfg := &auth.WebIdentityConfig{
+		Issuer:    issuer,
+		JWKSURL:   v.GetString(cfgWebIdentityJWKSURL),
+		Audiences: v.GetStringSlice(cfgWebIdentityAudiences),
+		Client:    &http.Client{Timeout: v.GetDuration(cfgWebIdentityTimeout)},
+	}
+
+	if err := cfg.Container.DecodeString(v.GetString(cfgWebIdentityContainerID)); err != nil {
+		l.Fatal("invalid web identity container id", zap.Error(err))
+	}
+
+	cfg.Roles = fetchWebIdentityRoles(v)
+	for _, role := range cfg.Roles {
+		l.Info("added web identity role",
+			zap.String("arn", role.ARN),
+			zap.String("access_key_id", role.AccessKeyID))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		l.Fatal("invalid web identity config", zap.Error(err))
+	}
+
+	return cfg
+}
+
+func fetchWebIdentityRoles(v *viper.Viper) []auth.WebIdentityRole {
+	var roles []auth.WebIdentityRole
+
+	for i := 0; ; i++ {
+		key := cfgWebIdentityRoles + "." + strconv.Itoa(i) + "."
+		role := auth.WebIdentityRole{
+			ARN:         v.GetString(key + "arn"),
+			AccessKeyID: v.GetString(key + "access_key_id"),
+			Claims:      v.GetStringMapString(key + "claims"),
+			MaxDuration: v.GetDuration(key + "max_duration"),
+		}
+
+		if role.ARN == "" || role.AccessKeyID == "" {
+			break
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles
+}
+
+// newSettings parses configuration and command line, it returns the command with
+// its arguments if the command is set instead of running the gateway.
+func newSettings() (*viper.Viper, []string) {
 	v := viper.New()
 
 	v.AutomaticEnv()
@@ -215,6 +988,7 @@ func newSettings() *viper.Viper {
 	flags.StringP(cmdWallet, "w", "", `path to the wallet`)
 	flags.String(cmdAddress, "", `address of wallet account`)
 	flags.String(cmdConfig, "", "config path")
+	flags.Bool(cmdDryRun, false, "only report changes of "+cmdMigrateVersions+", "+cmdGCMultipart+" and "+cmdGCDeleteMarkers+" commands")
 
 	flags.Duration(cfgHealthcheckTimeout, defaultHealthcheckTimeout, "set timeout to check node health during rebalance")
 	flags.Duration(cfgConnectTimeout, defaultConnectTimeout, "set timeout to connect to NeoFS nodes")
@@ -242,9 +1016,82 @@ func newSettings() *viper.Viper {
 	// pool:
 	v.SetDefault(cfgPoolErrorThreshold, defaultPoolErrorThreshold)
 	v.SetDefault(cfgStreamTimeout, defaultStreamTimeout)
+	v.SetDefault(cfgETagSource, etagSourceChecksum)
 
 	v.SetDefault(cfgPProfAddress, "localhost:8085")
 	v.SetDefault(cfgPrometheusAddress, "localhost:8086")
+	v.SetDefault(cfgHealthAddress, "localhost:8087")
+
+	// caches
+	v.SetDefault(cfgObjectsCacheEnabled, true)
+	v.SetDefault(cfgListObjectsCacheEnabled, true)
+	v.SetDefault(cfgNamesCacheEnabled, true)
+	v.SetDefault(cfgSystemCacheEnabled, true)
+	v.SetDefault(cfgEACLCacheEnabled, true)
+	v.SetDefault(cfgCacheBackend, cacheBackendLocal)
+	v.SetDefault(cfgCacheRedisTimeout, defaultCacheRedisTimeout)
+	v.SetDefault(cfgHealthTimeout, defaultHealthTimeout)
+	v.SetDefault(cfgAdminAddress, "localhost:8088")
+
+	// tree
+	v.SetDefault(cfgTreeHealthcheckInterval, defaultTreeHealthcheckInterval)
+	v.SetDefault(cfgTreeRetryMaxAttempts, defaultRetryMaxAttempts)
+	v.SetDefault(cfgTreeRetryInitialBackoff, defaultRetryInitialBackoff)
+	v.SetDefault(cfgTreeRetryMaxBackoff, defaultRetryMaxBackoff)
+
+	// neofs
+	v.SetDefault(cfgDeleteWorkers, defaultDeleteWorkers)
+	v.SetDefault(cfgListWorkers, defaultListWorkers)
+	v.SetDefault(cfgParallelGetPartSize, defaultParallelGetPartSize)
+	v.SetDefault(cfgParallelGetWorkers, defaultParallelGetWorkers)
+	v.SetDefault(cfgBufferSizeGet, layer.DefaultGetBufferSize)
+	v.SetDefault(cfgBufferSizePut, layer.DefaultPutBufferSize)
+	v.SetDefault(cfgRetryMaxAttempts, defaultRetryMaxAttempts)
+	v.SetDefault(cfgRetryInitialBackoff, defaultRetryInitialBackoff)
+	v.SetDefault(cfgRetryMaxBackoff, defaultRetryMaxBackoff)
+
+	// access box renewal
+	v.SetDefault(cfgAccessBoxRenewalInterval, defaultAccessBoxRenewalInterval)
+	v.SetDefault(cfgAccessBoxRenewalThreshold, defaultAccessBoxRenewalThreshold)
+
+	// acme
+	v.SetDefault(cfgACMEDirectoryURL, acme.LetsEncryptURL)
+
+	// replay protection
+	v.SetDefault(cfgReplayProtectionClockSkew, defaultReplayProtectionClockSkew)
+	v.SetDefault(cfgReplayProtectionNoncesSize, defaultPresignedNoncesSize)
+
+	// access log
+	v.SetDefault(cfgAccessLogOutput, defaultAccessLogOutput)
+	v.SetDefault(cfgAccessLogSampleRatio, defaultAccessLogSampleRatio)
+	v.SetDefault(cfgAccessLogLogErrors, true)
+
+	// audit log
+	v.SetDefault(cfgAuditLogOutput, defaultAuditLogOutput)
+	v.SetDefault(cfgAuditLogSyslogTag, defaultAuditLogSyslogTag)
+
+	// tracing
+	v.SetDefault(cfgTracingSampleRatio, defaultTracingSampleRatio)
+
+	// usage
+	v.SetDefault(cfgUsageExportInterval, defaultUsageExportInterval)
+	v.SetDefault(cfgUsageExportPrefix, defaultUsageExportPrefix)
+	v.SetDefault(cfgUsageExportFormat, defaultUsageExportFormat)
+
+	// multipart gc
+	v.SetDefault(cfgMultipartGCMaxAge, defaultMultipartGCMaxAge)
+
+	// delete markers gc
+	v.SetDefault(cfgDeleteMarkersGCMaxAge, defaultDeleteMarkersGCMaxAge)
+
+	// events queue
+	v.SetDefault(cfgEventsQueueMaxAttempts, defaultEventsQueueMaxAttempts)
+	v.SetDefault(cfgEventsQueueInitialBackoff, defaultEventsQueueInitialBackoff)
+	v.SetDefault(cfgEventsQueueMaxBackoff, defaultEventsQueueMaxBackoff)
+	v.SetDefault(cfgEventsQueueDeadLetterPrefix, defaultEventsDeadLetterPrefix)
+
+	// kludge
+	v.SetDefault(cfgKludgeCompleteMultipartUploadKeepalive, defaultCompleteMultipartKeepalive)
 
 	// Bind flags
 	if err := bindFlags(v, flags); err != nil {
@@ -307,6 +1154,16 @@ func newSettings() *viper.Viper {
 		fmt.Printf("%s_%s_[N]_ADDRESS = string\n", envPrefix, strings.ToUpper(cfgPeers))
 		fmt.Printf("%s_%s_[N]_WEIGHT = 0..1 (float)\n", envPrefix, strings.ToUpper(cfgPeers))
 
+		fmt.Println()
+		fmt.Println("Commands:")
+		fmt.Println()
+		fmt.Printf("%s [--%s] <container ID>...\n", cmdMigrateVersions, cmdDryRun)
+		fmt.Println("    add versions of objects kept in attributes by older gateways to the tree service")
+		fmt.Printf("%s [--%s] <container ID>...\n", cmdGCMultipart, cmdDryRun)
+		fmt.Println("    abort stale multipart uploads and delete parts which don't belong to any upload or object")
+		fmt.Printf("%s [--%s] <container ID>...\n", cmdGCDeleteMarkers, cmdDryRun)
+		fmt.Println("    remove delete markers of objects which have no other versions")
+
 		os.Exit(0)
 	case versionFlag != nil && *versionFlag:
 		fmt.Printf("NeoFS S3 Gateway\nVersion: %s\nGoVersion: %s\n", version.Version, runtime.Version())
@@ -319,7 +1176,8 @@ func newSettings() *viper.Viper {
 		}
 	}
 
-	return v
+	// the first argument is the path of the executable
+	return v, flags.Args()[1:]
 }
 
 func bindFlags(v *viper.Viper, flags *pflag.FlagSet) error {
@@ -332,6 +1190,9 @@ func bindFlags(v *viper.Viper, flags *pflag.FlagSet) error {
 	if err := v.BindPFlag(cmdConfig, flags.Lookup(cmdConfig)); err != nil {
 		return err
 	}
+	if err := v.BindPFlag(cmdDryRun, flags.Lookup(cmdDryRun)); err != nil {
+		return err
+	}
 	if err := v.BindPFlag(cfgWalletPath, flags.Lookup(cmdWallet)); err != nil {
 		return err
 	}
@@ -370,6 +1231,83 @@ func bindFlags(v *viper.Viper, flags *pflag.FlagSet) error {
 	return nil
 }
 
+// validateConfig reads the config file into separate settings and checks
+// the parameters reloaded on SIGHUP.
+func validateConfig(cfgFileName string) error {
+	v := viper.New()
+	v.AutomaticEnv()
+	v.SetEnvPrefix(envPrefix)
+	v.SetConfigType("yaml")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AllowEmptyEnv(true)
+	v.Set(cmdConfig, cfgFileName)
+
+	if err := readConfig(v); err != nil {
+		return err
+	}
+
+	if _, err := getLogLevel(v); err != nil {
+		return err
+	}
+
+	if _, err := newPlacementPolicy(getDefaultPolicyValue(v), v.GetString(cfgPolicyRegionMapFile)); err != nil {
+		return fmt.Errorf("invalid placement policy: %w", err)
+	}
+
+	if _, err := getMode(v); err != nil {
+		return err
+	}
+
+	if _, err := isMD5ETag(v); err != nil {
+		return err
+	}
+
+	if _, err := api.NewIPFilter(fetchIPFilterRules(v)); err != nil {
+		return fmt.Errorf("invalid ip filter: %w", err)
+	}
+
+	if _, err := api.NewTrustedProxies(v.GetStringSlice(cfgTrustedProxies)); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	if _, err := api.NewAccessLog(zap.NewNop(), fetchAccessLogSettings(v)); err != nil {
+		return fmt.Errorf("invalid access log: %w", err)
+	}
+
+	if _, err := fetchDefaultCORS(v); err != nil {
+		return err
+	}
+
+	if v.GetString(cfgWebIdentityIssuer) != "" {
+		webIdentityCfg := &auth.WebIdentityConfig{
+			Audiences: v.GetStringSlice(cfgWebIdentityAudiences),
+			Roles:     fetchWebIdentityRoles(v),
+		}
+		if err := webIdentityCfg.Validate(); err != nil {
+			return fmt.Errorf("invalid web identity config: %w", err)
+		}
+	}
+
+	if output := v.GetString(cfgAuditLogOutput); v.GetBool(cfgAuditLogEnabled) && output != "file" && output != "syslog" {
+		return fmt.Errorf("unknown audit log output '%s'", output)
+	}
+
+	for i := 0; ; i++ {
+		key := cfgServer + "." + strconv.Itoa(i) + "."
+		if v.GetString(key+"address") == "" {
+			break
+		}
+
+		if v.GetBool(key+cfgTLSEnabled) && v.GetString(key+cfgTLSCertFile) != "" {
+			if _, err := tls.LoadX509KeyPair(v.GetString(key+cfgTLSCertFile), v.GetString(key+cfgTLSKeyFile)); err != nil {
+				return fmt.Errorf("invalid tls certificate of server '%s': %w", v.GetString(key+"address"), err)
+			}
+		}
+	}
+
+	return nil
+}
+
 func readConfig(v *viper.Viper) error {
 	cfgFileName := v.GetString(cmdConfig)
 	cfgFile, err := os.Open(cfgFileName)
@@ -420,6 +1358,88 @@ func newLogger(v *viper.Viper) *Logger {
 	}
 }
 
+// newAccessLogger returns a logger writing access log records in JSON to the configured output.
+func newAccessLogger(v *viper.Viper) (*zap.Logger, error) {
+	c := zap.NewProductionConfig()
+	c.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+	c.Encoding = "json"
+	c.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	c.DisableCaller = true
+	c.DisableStacktrace = true
+	c.Sampling = nil
+	c.OutputPaths = []string{v.GetString(cfgAccessLogOutput)}
+
+	return c.Build()
+}
+
+// newCacheBackend returns the backend shared by gateway instances or nil if caches are local.
+func newCacheBackend(ctx context.Context, v *viper.Viper) (*redis.Backend, error) {
+	switch backend := v.GetString(cfgCacheBackend); backend {
+	case cacheBackendLocal:
+		return nil, nil
+	case cacheBackendRedis:
+		address := v.GetString(cfgCacheRedisAddress)
+		if address == "" {
+			return nil, errors.New("address of redis isn't set")
+		}
+		return redis.NewBackend(ctx, redis.Config{
+			Address:  address,
+			Password: v.GetString(cfgCacheRedisPassword),
+			DB:       v.GetInt(cfgCacheRedisDB),
+			Timeout:  v.GetDuration(cfgCacheRedisTimeout),
+		})
+	default:
+		return nil, fmt.Errorf("unknown cache backend '%s'", backend)
+	}
+}
+
+// newAuditLogger returns a logger writing audit records to the configured file or syslog.
+func newAuditLogger(v *viper.Viper) (*audit.Logger, io.Closer, error) {
+	switch output := v.GetString(cfgAuditLogOutput); output {
+	case "file":
+		path := v.GetString(cfgAuditLogPath)
+		if path == "" {
+			return nil, nil, errors.New("path of audit log file isn't set")
+		}
+		return audit.OpenFile(path)
+	case "syslog":
+		return audit.DialSyslog(v.GetString(cfgAuditLogSyslogNetwork), v.GetString(cfgAuditLogSyslogAddress),
+			v.GetString(cfgAuditLogSyslogTag))
+	default:
+		return nil, nil, fmt.Errorf("unknown audit log output '%s'", output)
+	}
+}
+
+func fetchAccessLogSettings(v *viper.Viper) api.AccessLogSettings {
+	return api.AccessLogSettings{
+		Fields:      v.GetStringSlice(cfgAccessLogFields),
+		SampleRatio: v.GetFloat64(cfgAccessLogSampleRatio),
+		LogErrors:   v.GetBool(cfgAccessLogLogErrors),
+	}
+}
+
+func getMode(v *viper.Viper) (api.Mode, error) {
+	mode, err := api.ParseMode(v.GetString(cfgMode))
+	if err != nil {
+		return mode, fmt.Errorf("incorrect mode configuration: %w, value should be one of %v", err,
+			[...]string{api.ModeNameNormal, api.ModeNameReadOnly, api.ModeNameMaintenance})
+	}
+	return mode, nil
+}
+
+// isMD5ETag checks if ETag of new objects is MD5 of the payload.
+func isMD5ETag(v *viper.Viper) (bool, error) {
+	switch source := v.GetString(cfgETagSource); source {
+	case etagSourceChecksum:
+		return false, nil
+	case etagSourceMD5:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown etag source '%s', value should be one of %v", source,
+			[...]string{etagSourceChecksum, etagSourceMD5})
+	}
+}
+
 func getLogLevel(v *viper.Viper) (zapcore.Level, error) {
 	var lvl zapcore.Level
 	lvlStr := v.GetString(cfgLoggerLevel)
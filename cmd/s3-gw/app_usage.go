@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	usageFormatCSV  = "csv"
+	usageFormatJSON = "json"
+
+	usageTimeFormat = "20060102T150405Z"
+)
+
+type (
+	// usageExporter periodically puts the usage accounted since the previous export
+	// to the bucket as CSV or JSON object.
+	usageExporter struct {
+		log      *zap.Logger
+		obj      layer.Client
+		usage    *api.UsageAccounting
+		bucket   string
+		prefix   string
+		format   string
+		interval time.Duration
+
+		mu   sync.Mutex
+		prev []api.UsageRecord
+		from time.Time
+	}
+
+	usageReport struct {
+		PeriodStart time.Time         `json:"period_start"`
+		PeriodEnd   time.Time         `json:"period_end"`
+		Records     []api.UsageRecord `json:"records"`
+	}
+)
+
+// newUsageExporter creates exporter of usage. Nil is returned if export isn't configured.
+func newUsageExporter(log *zap.Logger, v *viper.Viper, obj layer.Client, usage *api.UsageAccounting) *usageExporter {
+	bucket := v.GetString(cfgUsageExportBucket)
+	if bucket == "" {
+		return nil
+	}
+
+	format := v.GetString(cfgUsageExportFormat)
+	if format != usageFormatCSV && format != usageFormatJSON {
+		log.Fatal("invalid usage export format", zap.String("format", format))
+	}
+
+	interval := v.GetDuration(cfgUsageExportInterval)
+	if interval <= 0 {
+		log.Warn("invalid usage export interval, default one will be used",
+			zap.Duration("value in config", interval), zap.Duration("default", defaultUsageExportInterval))
+		interval = defaultUsageExportInterval
+	}
+
+	return &usageExporter{
+		log:      log.With(zap.String("bucket", bucket)),
+		obj:      obj,
+		usage:    usage,
+		bucket:   bucket,
+		prefix:   v.GetString(cfgUsageExportPrefix),
+		format:   format,
+		interval: interval,
+		from:     time.Now().UTC(),
+	}
+}
+
+// Run exports usage every interval until ctx is done.
+func (e *usageExporter) Run(ctx context.Context) {
+	e.log.Info("usage export started", zap.Duration("interval", e.interval), zap.String("format", e.format))
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Export(ctx); err != nil {
+				e.log.Error("couldn't export usage", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Export puts the usage accounted since the previous successful export to the bucket.
+// Nothing is put if there were no requests.
+func (e *usageExporter) Export(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cur := e.usage.Records()
+	report := usageReport{
+		PeriodStart: e.from,
+		PeriodEnd:   time.Now().UTC(),
+		Records:     api.UsageDiff(e.prev, cur),
+	}
+
+	if len(report.Records) == 0 {
+		return nil
+	}
+
+	payload, err := encodeUsageReport(report, e.format)
+	if err != nil {
+		return err
+	}
+
+	bktInfo, err := e.obj.GetBucketInfo(ctx, e.bucket)
+	if err != nil {
+		return fmt.Errorf("get bucket info: %w", err)
+	}
+
+	name := e.prefix + report.PeriodEnd.Format(usageTimeFormat) + "." + e.format
+	contentType := "text/csv"
+	if e.format == usageFormatJSON {
+		contentType = "application/json"
+	}
+
+	_, err = e.obj.PutObject(ctx, &layer.PutObjectParams{
+		BktInfo: bktInfo,
+		Object:  name,
+		Size:    int64(len(payload)),
+		Reader:  bytes.NewReader(payload),
+		Header:  map[string]string{api.ContentType: contentType},
+	})
+	if err != nil {
+		return fmt.Errorf("put usage object '%s': %w", name, err)
+	}
+
+	e.log.Info("usage exported", zap.String("object", name), zap.Int("records", len(report.Records)))
+
+	e.prev = cur
+	e.from = report.PeriodEnd
+
+	return nil
+}
+
+func encodeUsageReport(report usageReport, format string) ([]byte, error) {
+	if format == usageFormatJSON {
+		return json.Marshal(report)
+	}
+
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+
+	records := make([][]string, 0, len(report.Records)+1)
+	records = append(records, []string{"period_start", "period_end", "bucket", "access_key_id", "requests", "bytes_in", "bytes_out"})
+	for _, rec := range report.Records {
+		records = append(records, []string{
+			report.PeriodStart.Format(time.RFC3339),
+			report.PeriodEnd.Format(time.RFC3339),
+			rec.Bucket,
+			rec.AccessKeyID,
+			strconv.FormatUint(rec.Requests, 10),
+			strconv.FormatUint(rec.BytesIn, 10),
+			strconv.FormatUint(rec.BytesOut, 10),
+		})
+	}
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("encode usage to csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -4,14 +4,23 @@ import (
 	"context"
 	"os/signal"
 	"syscall"
+
+	"go.uber.org/zap"
 )
 
 func main() {
 	g, _ := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 
-	v := newSettings()
+	v, args := newSettings()
 	l := newLogger(v)
 
+	if len(args) != 0 {
+		if err := runCommand(g, l.logger, v, args); err != nil {
+			l.logger.Fatal("command failed", zap.String("command", args[0]), zap.Error(err))
+		}
+		return
+	}
+
 	a := newApp(g, l, v)
 
 	go a.Serve(g)
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/migration"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// runCommand runs the command given in the command line instead of the gateway.
+func runCommand(ctx context.Context, log *zap.Logger, v *viper.Viper, args []string) error {
+	switch args[0] {
+	case cmdMigrateVersions:
+		return migrateVersions(ctx, log, v, args[1:])
+	case cmdGCMultipart:
+		return gcMultipart(ctx, log, v, args[1:])
+	case cmdGCDeleteMarkers:
+		return gcDeleteMarkers(ctx, log, v, args[1:])
+	default:
+		return fmt.Errorf("unknown command '%s'", args[0])
+	}
+}
+
+// migrateVersions adds versions of objects of the containers kept in object attributes
+// by older gateways to the tree service. The wallet of the gateway must be allowed
+// to search and head objects of the containers and to change their trees.
+func migrateVersions(ctx context.Context, log *zap.Logger, v *viper.Viper, containers []string) error {
+	cnrIDs, err := parseContainerIDs(containers)
+	if err != nil {
+		return err
+	}
+
+	neoFS, treeService, closeClients, err := newCommandClients(ctx, log, v)
+	if err != nil {
+		return err
+	}
+	defer closeClients()
+
+	dryRun := v.GetBool(cmdDryRun)
+	migrator := migration.NewVersions(neoFS, treeService, log)
+
+	for _, cnrID := range cnrIDs {
+		bktInfo, err := containerBucketInfo(ctx, neoFS, cnrID)
+		if err != nil {
+			return err
+		}
+
+		res, err := migrator.Migrate(ctx, bktInfo, dryRun)
+		if err != nil {
+			return fmt.Errorf("migrate container '%s': %w", cnrID, err)
+		}
+
+		log.Info("versions of container are migrated",
+			zap.Stringer("cid", cnrID),
+			zap.Bool("dry_run", dryRun),
+			zap.Int("objects", res.Objects),
+			zap.Int("added", res.Added),
+			zap.Int("deleted", res.Deleted),
+			zap.Int("skipped_objects", res.SkippedPaths))
+	}
+
+	return nil
+}
+
+// gcMultipart aborts multipart uploads of the containers older than the configured age and deletes
+// part objects which belong neither to uploads nor to completed objects. The wallet of the gateway
+// must be allowed to search, get and delete objects of the containers and to change their trees.
+func gcMultipart(ctx context.Context, log *zap.Logger, v *viper.Viper, containers []string) error {
+	return collectGarbage(ctx, log, v, containers, v.GetDuration(cfgMultipartGCMaxAge),
+		func(neoFS *neofs.NeoFS, tree *neofs.TreeClient) gcFunc {
+			return multipartGC(log, neoFS, tree)
+		})
+}
+
+// gcDeleteMarkers removes delete markers of objects without other versions from the trees of the containers.
+// The wallet of the gateway must be allowed to change the trees.
+func gcDeleteMarkers(ctx context.Context, log *zap.Logger, v *viper.Viper, containers []string) error {
+	return collectGarbage(ctx, log, v, containers, v.GetDuration(cfgDeleteMarkersGCMaxAge),
+		func(_ *neofs.NeoFS, tree *neofs.TreeClient) gcFunc {
+			return deleteMarkersGC(log, tree)
+		})
+}
+
+func collectGarbage(ctx context.Context, log *zap.Logger, v *viper.Viper, containers []string, maxAge time.Duration,
+	newGC func(*neofs.NeoFS, *neofs.TreeClient) gcFunc) error {
+	cnrIDs, err := parseContainerIDs(containers)
+	if err != nil {
+		return err
+	}
+
+	neoFS, treeService, closeClients, err := newCommandClients(ctx, log, v)
+	if err != nil {
+		return err
+	}
+	defer closeClients()
+
+	dryRun := v.GetBool(cmdDryRun)
+	collect := newGC(neoFS, treeService)
+
+	for _, cnrID := range cnrIDs {
+		bktInfo, err := containerBucketInfo(ctx, neoFS, cnrID)
+		if err != nil {
+			return err
+		}
+
+		if err = collect(ctx, bktInfo, maxAge, dryRun); err != nil {
+			return fmt.Errorf("collect garbage of container '%s': %w", cnrID, err)
+		}
+	}
+
+	return nil
+}
+
+func parseContainerIDs(containers []string) ([]cid.ID, error) {
+	if len(containers) == 0 {
+		return nil, errors.New("no containers given")
+	}
+
+	cnrIDs := make([]cid.ID, len(containers))
+	for i := range containers {
+		if err := cnrIDs[i].DecodeString(containers[i]); err != nil {
+			return nil, fmt.Errorf("invalid container ID '%s': %w", containers[i], err)
+		}
+	}
+
+	return cnrIDs, nil
+}
+
+// newCommandClients connects to NeoFS and the tree service with the wallet of the gateway.
+// The returned function closes the connections.
+func newCommandClients(ctx context.Context, log *zap.Logger, v *viper.Viper) (*neofs.NeoFS, *neofs.TreeClient, func(), error) {
+	conns, key := getPool(ctx, log, v)
+
+	neoFS := neofs.NewNeoFS(conns)
+	neoFS.SetTimeouts(fetchOperationTimeouts(v))
+	neoFS.SetRetryPolicy(fetchRetryPolicy(v))
+
+	treeTLSConfig, err := fetchTreeTLSConfig(v)
+	if err != nil {
+		conns.Close()
+		return nil, nil, nil, fmt.Errorf("invalid tree service tls configuration: %w", err)
+	}
+
+	treeService, err := neofs.NewTreeClient(ctx, v.GetStringSlice(cfgTreeServiceEndpoint), key, treeTLSConfig)
+	if err != nil {
+		conns.Close()
+		return nil, nil, nil, fmt.Errorf("create tree service: %w", err)
+	}
+	treeService.SetRetryPolicy(fetchTreeRetryPolicy(v))
+
+	return neoFS, treeService, func() {
+		treeService.Close()
+		conns.Close()
+	}, nil
+}
+
+// containerBucketInfo returns bucket info sufficient to access objects and the tree of the container.
+func containerBucketInfo(ctx context.Context, neoFS *neofs.NeoFS, cnrID cid.ID) (*data.BucketInfo, error) {
+	cnr, err := neoFS.Container(ctx, cnrID)
+	if err != nil {
+		return nil, fmt.Errorf("get container '%s': %w", cnrID, err)
+	}
+
+	return &data.BucketInfo{
+		CID:   cnrID,
+		Owner: cnr.Owner(),
+	}, nil
+}
@@ -6,27 +6,39 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"sync"
+	"time"
 
+	"github.com/nspcc-dev/neofs-s3-gw/internal/proxyproto"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 type (
 	ServerInfo struct {
 		Address string
 		TLS     ServerTLSInfo
+		// ProxyProtocol requires PROXY protocol header on accepted connections.
+		ProxyProtocol bool
 	}
 
 	ServerTLSInfo struct {
 		Enabled  bool
 		CertFile string
 		KeyFile  string
+		// ACME is true if the certificate is managed by ACME client instead of the files.
+		ACME bool
+		// HTTP2 enables HTTP/2 protocol negotiation.
+		HTTP2 bool
 	}
 
 	Server interface {
 		Address() string
 		Listener() net.Listener
 		UpdateCert(certFile, keyFile string) error
+		ReloadCert() (bool, error)
 	}
 
 	server struct {
@@ -38,10 +50,12 @@ type (
 	certProvider struct {
 		Enabled bool
 
-		mu       sync.RWMutex
-		certPath string
-		keyPath  string
-		cert     *tls.Certificate
+		mu          sync.RWMutex
+		certPath    string
+		keyPath     string
+		certModTime time.Time
+		keyModTime  time.Time
+		cert        *tls.Certificate
 	}
 )
 
@@ -57,25 +71,46 @@ func (s *server) UpdateCert(certFile, keyFile string) error {
 	return s.tlsProvider.UpdateCert(certFile, keyFile)
 }
 
-func newServer(ctx context.Context, serverInfo ServerInfo, logger *zap.Logger) *server {
+// ReloadCert reloads the TLS certificate if its files were modified.
+func (s *server) ReloadCert() (bool, error) {
+	return s.tlsProvider.ReloadIfChanged()
+}
+
+func newServer(ctx context.Context, serverInfo ServerInfo, acmeManager *autocert.Manager, logger *zap.Logger) *server {
 	var lic net.ListenConfig
 	ln, err := lic.Listen(ctx, "tcp", serverInfo.Address)
 	if err != nil {
 		logger.Fatal("could not prepare listener", zap.String("address", serverInfo.Address), zap.Error(err))
 	}
 
+	// PROXY protocol header precedes TLS handshake.
+	if serverInfo.ProxyProtocol {
+		ln = proxyproto.NewListener(ln, defaultProxyProtocolHeaderTimeout)
+	}
+
 	tlsProvider := &certProvider{
-		Enabled: serverInfo.TLS.Enabled,
+		Enabled: serverInfo.TLS.Enabled && !serverInfo.TLS.ACME,
 	}
 
 	if serverInfo.TLS.Enabled {
-		if err = tlsProvider.UpdateCert(serverInfo.TLS.CertFile, serverInfo.TLS.KeyFile); err != nil {
+		tlsConfig := &tls.Config{
+			GetCertificate: tlsProvider.GetCertificate,
+			NextProtos:     []string{"http/1.1"},
+		}
+
+		if serverInfo.TLS.ACME {
+			tlsConfig = acmeManager.TLSConfig()
+			tlsConfig.NextProtos = []string{"http/1.1", acme.ALPNProto}
+		} else if err = tlsProvider.UpdateCert(serverInfo.TLS.CertFile, serverInfo.TLS.KeyFile); err != nil {
 			logger.Fatal("failed to update cert", zap.Error(err))
 		}
 
-		ln = tls.NewListener(ln, &tls.Config{
-			GetCertificate: tlsProvider.GetCertificate,
-		})
+		// HTTP/2 is served by http.Server for the connections which negotiated 'h2' protocol.
+		if serverInfo.TLS.HTTP2 {
+			tlsConfig.NextProtos = append([]string{"h2"}, tlsConfig.NextProtos...)
+		}
+
+		ln = tls.NewListener(ln, tlsConfig)
 	}
 
 	return &server{
@@ -100,6 +135,11 @@ func (p *certProvider) UpdateCert(certPath, keyPath string) error {
 		return fmt.Errorf("tls disabled")
 	}
 
+	certModTime, keyModTime, err := modTimes(certPath, keyPath)
+	if err != nil {
+		return err
+	}
+
 	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
 	if err != nil {
 		return fmt.Errorf("cannot load TLS key pair from certFile '%s' and keyFile '%s': %w", certPath, keyPath, err)
@@ -108,11 +148,51 @@ func (p *certProvider) UpdateCert(certPath, keyPath string) error {
 	p.mu.Lock()
 	p.certPath = certPath
 	p.keyPath = keyPath
+	p.certModTime = certModTime
+	p.keyModTime = keyModTime
 	p.cert = &cert
 	p.mu.Unlock()
 	return nil
 }
 
+// ReloadIfChanged loads the certificate again if modification time of the certificate
+// or the key file differs from the loaded one. The previous certificate is kept on error.
+func (p *certProvider) ReloadIfChanged() (bool, error) {
+	if !p.Enabled {
+		return false, nil
+	}
+
+	p.mu.RLock()
+	certPath, keyPath := p.certPath, p.keyPath
+	certModTime, keyModTime := p.certModTime, p.keyModTime
+	p.mu.RUnlock()
+
+	newCertModTime, newKeyModTime, err := modTimes(certPath, keyPath)
+	if err != nil {
+		return false, err
+	}
+
+	if newCertModTime.Equal(certModTime) && newKeyModTime.Equal(keyModTime) {
+		return false, nil
+	}
+
+	return true, p.UpdateCert(certPath, keyPath)
+}
+
+func modTimes(certPath, keyPath string) (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(certPath)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("cannot stat certFile '%s': %w", certPath, err)
+	}
+
+	keyInfo, err := os.Stat(keyPath)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("cannot stat keyFile '%s': %w", keyPath, err)
+	}
+
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
 func (p *certProvider) FilePaths() (string, string) {
 	if !p.Enabled {
 		return "", ""
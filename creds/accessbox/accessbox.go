@@ -7,10 +7,13 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/v2/acl"
 	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/netmap"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
@@ -23,6 +26,21 @@ import (
 type Box struct {
 	Gate     *GateData
 	Policies []*ContainerPolicy
+	// InlinePolicy restricts actions allowed with the box. Box without inline policy isn't restricted.
+	InlinePolicy *InlinePolicy
+	// PreviousAccessKey is a secret replaced by rotation. It remains valid until GraceExpiration.
+	PreviousAccessKey string
+	GraceExpiration   time.Time
+	// Expiration is the time the temporary credentials expire at. It's zero for permanent credentials.
+	Expiration time.Time
+}
+
+// AccessKeys returns the secrets the requests can be signed with at the moment.
+func (b *Box) AccessKeys() []string {
+	if b.PreviousAccessKey != "" && time.Now().Before(b.GraceExpiration) {
+		return []string{b.Gate.AccessKey, b.PreviousAccessKey}
+	}
+	return []string{b.Gate.AccessKey}
 }
 
 // ContainerPolicy represents friendly AccessBox_ContainerPolicy.
@@ -44,6 +62,13 @@ func NewGateData(gateKey *keys.PublicKey, bearerTkn *bearer.Token) *GateData {
 	return &GateData{GateKey: gateKey, BearerToken: bearerTkn}
 }
 
+// BearerTokenExpiration returns the last epoch the bearer token is valid at.
+func (g *GateData) BearerTokenExpiration() uint64 {
+	var m acl.BearerToken
+	g.BearerToken.WriteToV2(&m)
+	return m.GetBody().GetLifetime().GetExp()
+}
+
 // SessionTokenForPut returns the first suitable container session context for PUT operation.
 func (g *GateData) SessionTokenForPut() *session.Container {
 	return g.containerSessionToken(session.VerbContainerPut)
@@ -96,6 +121,34 @@ func (x *AccessBox) Unmarshal(data []byte) error {
 // PackTokens adds bearer and session tokens to BearerTokens and SessionToken lists respectively.
 // Session token can be nil.
 func PackTokens(gatesData []*GateData) (*AccessBox, *Secrets, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate accessKey as hex: %w", err)
+	}
+
+	box, ephemeralKey, err := packTokens(gatesData, secret, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return box, &Secrets{hex.EncodeToString(secret), ephemeralKey}, nil
+}
+
+// PackTokensWithSecret packs tokens like PackTokens but uses the provided secret access key as is.
+func PackTokensWithSecret(gatesData []*GateData, accessKey string) (*AccessBox, *Secrets, error) {
+	if accessKey == "" {
+		return nil, nil, errors.New("empty accessKey")
+	}
+
+	box, ephemeralKey, err := packTokens(gatesData, []byte(accessKey), true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return box, &Secrets{accessKey, ephemeralKey}, nil
+}
+
+func packTokens(gatesData []*GateData, secret []byte, plain bool) (*AccessBox, *keys.PrivateKey, error) {
 	box := &AccessBox{}
 	ephemeralKey, err := keys.NewPrivateKey()
 	if err != nil {
@@ -103,16 +156,11 @@ func PackTokens(gatesData []*GateData) (*AccessBox, *Secrets, error) {
 	}
 	box.OwnerPublicKey = ephemeralKey.PublicKey().Bytes()
 
-	secret, err := generateSecret()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate accessKey as hex: %w", err)
-	}
-
-	if err := box.addTokens(gatesData, ephemeralKey, secret); err != nil {
+	if err := box.addTokens(gatesData, ephemeralKey, secret, plain); err != nil {
 		return nil, nil, fmt.Errorf("failed to add tokens to accessbox: %w", err)
 	}
 
-	return box, &Secrets{hex.EncodeToString(secret), ephemeralKey}, err
+	return box, ephemeralKey, nil
 }
 
 // GetTokens returns gate tokens from AccessBox.
@@ -166,13 +214,25 @@ func (x *AccessBox) GetBox(owner *keys.PrivateKey) (*Box, error) {
 		return nil, fmt.Errorf("get policy: %w", err)
 	}
 
-	return &Box{
+	box := &Box{
 		Gate:     tokens,
 		Policies: policy,
-	}, nil
+	}
+
+	if x.Expiration != 0 {
+		box.Expiration = time.Unix(x.Expiration, 0)
+	}
+
+	if len(x.InlinePolicy) > 0 {
+		if box.InlinePolicy, err = ParseInlinePolicy(x.InlinePolicy); err != nil {
+			return nil, fmt.Errorf("get inline policy: %w", err)
+		}
+	}
+
+	return box, nil
 }
 
-func (x *AccessBox) addTokens(gatesData []*GateData, ephemeralKey *keys.PrivateKey, secret []byte) error {
+func (x *AccessBox) addTokens(gatesData []*GateData, ephemeralKey *keys.PrivateKey, secret []byte, plain bool) error {
 	for _, gate := range gatesData {
 		encBearer := gate.BearerToken.Marshal()
 		encSessions := make([][]byte, len(gate.SessionTokens))
@@ -182,6 +242,7 @@ func (x *AccessBox) addTokens(gatesData []*GateData, ephemeralKey *keys.PrivateK
 
 		tokens := new(Tokens)
 		tokens.AccessKey = secret
+		tokens.PlainAccessKey = plain
 		tokens.BearerToken = encBearer
 		tokens.SessionTokens = encSessions
 
@@ -238,6 +299,9 @@ func decodeGate(gate *AccessBox_Gate, owner *keys.PrivateKey, sender *keys.Publi
 	gateData := NewGateData(owner.PublicKey(), &bearerTkn)
 	gateData.SessionTokens = sessionTkns
 	gateData.AccessKey = hex.EncodeToString(tokens.AccessKey)
+	if tokens.PlainAccessKey {
+		gateData.AccessKey = string(tokens.AccessKey)
+	}
 	return gateData, nil
 }
 
@@ -0,0 +1,135 @@
+package accessbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	policyEffectAllow = "Allow"
+	policyEffectDeny  = "Deny"
+)
+
+type (
+	// InlinePolicy restricts S3 actions allowed with the access box. Actions and
+	// resources of statements may contain '*' and '?' wildcards. Actions are
+	// case-insensitive, resources are case-sensitive. Deny statements take
+	// precedence, actions not allowed by any statement are denied.
+	InlinePolicy struct {
+		Statement []InlinePolicyStatement `json:"Statement"`
+	}
+
+	// InlinePolicyStatement allows or denies Action on Resource.
+	InlinePolicyStatement struct {
+		Effect   string       `json:"Effect"`
+		Action   policyValues `json:"Action"`
+		Resource policyValues `json:"Resource"`
+	}
+
+	// policyValues can be unmarshalled from a single string or a list of strings.
+	policyValues []string
+)
+
+// ParseInlinePolicy decodes and validates JSON inline policy.
+func ParseInlinePolicy(data []byte) (*InlinePolicy, error) {
+	var policy InlinePolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("unmarshal inline policy: %w", err)
+	}
+
+	if len(policy.Statement) == 0 {
+		return nil, errors.New("inline policy must contain statements")
+	}
+
+	for i, st := range policy.Statement {
+		if st.Effect != policyEffectAllow && st.Effect != policyEffectDeny {
+			return nil, fmt.Errorf("statement %d: invalid effect '%s'", i, st.Effect)
+		}
+		if len(st.Action) == 0 || len(st.Resource) == 0 {
+			return nil, fmt.Errorf("statement %d: action and resource must be set", i)
+		}
+	}
+
+	return &policy, nil
+}
+
+// IsAllowed checks if action on resource is allowed by the policy.
+func (p *InlinePolicy) IsAllowed(action, resource string) bool {
+	var allowed bool
+	for _, st := range p.Statement {
+		if !st.Action.matchFold(action) || !st.Resource.match(resource) {
+			continue
+		}
+		if st.Effect == policyEffectDeny {
+			return false
+		}
+		allowed = true
+	}
+
+	return allowed
+}
+
+func (v *policyValues) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*v = policyValues{str}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*v = list
+
+	return nil
+}
+
+func (v policyValues) match(value string) bool {
+	for _, pattern := range v {
+		if matchWildcard(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFold is a case-insensitive version of match.
+func (v policyValues) matchFold(value string) bool {
+	value = strings.ToLower(value)
+	for _, pattern := range v {
+		if matchWildcard(strings.ToLower(pattern), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcard matches value against pattern where '*' matches any sequence
+// of characters including '/' and '?' matches any single character.
+func matchWildcard(pattern, value string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(value); i >= 0; i-- {
+				if matchWildcard(pattern[1:], value[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(value) == 0 {
+				return false
+			}
+		default:
+			if len(value) == 0 || pattern[0] != value[0] {
+				return false
+			}
+		}
+		pattern, value = pattern[1:], value[1:]
+	}
+
+	return len(value) == 0
+}
@@ -0,0 +1,84 @@
+package accessbox
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/stretchr/testify/require"
+)
+
+const testInlinePolicy = `{
+  "Statement": [
+    {"Effect": "Allow", "Action": ["s3:GetObject", "s3:ListBucket"], "Resource": ["arn:aws:s3:::bucket", "arn:aws:s3:::bucket/*"]},
+    {"Effect": "Allow", "Action": "s3:Put*", "Resource": "arn:aws:s3:::bucket/uploads/*"},
+    {"Effect": "Deny", "Action": "*", "Resource": "arn:aws:s3:::bucket/private/*"}
+  ]
+}`
+
+func TestInlinePolicyIsAllowed(t *testing.T) {
+	policy, err := ParseInlinePolicy([]byte(testInlinePolicy))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		action, resource string
+		allowed          bool
+	}{
+		{"s3:GetObject", "arn:aws:s3:::bucket/dir/obj", true},
+		{"s3:ListBucket", "arn:aws:s3:::bucket", true},
+		{"s3:PutObject", "arn:aws:s3:::bucket/uploads/obj", true},
+		{"s3:getobject", "arn:aws:s3:::bucket/dir/obj", true},
+		{"S3:PUTOBJECT", "arn:aws:s3:::bucket/uploads/obj", true},
+		{"s3:GetObject", "arn:aws:s3:::BUCKET/dir/obj", false},
+		{"s3:getobject", "arn:aws:s3:::bucket/private/obj", false},
+		{"s3:PutObject", "arn:aws:s3:::bucket/obj", false},
+		{"s3:GetObject", "arn:aws:s3:::bucket/private/obj", false},
+		{"s3:GetObject", "arn:aws:s3:::bucket2/obj", false},
+		{"s3:DeleteObject", "arn:aws:s3:::bucket/obj", false},
+	} {
+		require.Equal(t, tc.allowed, policy.IsAllowed(tc.action, tc.resource), "%s %s", tc.action, tc.resource)
+	}
+}
+
+func TestParseInlinePolicyInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{}`,
+		`{"Statement": [{"Effect": "Maybe", "Action": "*", "Resource": "*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Resource": "*"}]}`,
+		`{"Statement": [{"Effect": "Allow", "Action": 1, "Resource": "*"}]}`,
+	} {
+		_, err := ParseInlinePolicy([]byte(data))
+		require.Error(t, err, data)
+	}
+}
+
+func TestInlinePolicyInAccessBox(t *testing.T) {
+	var (
+		box2 AccessBox
+		tkn  bearer.Token
+	)
+
+	sec, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	cred, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	tkn.SetEACLTable(*eacl.NewTable())
+	require.NoError(t, tkn.Sign(sec.PrivateKey))
+
+	box, _, err := PackTokens([]*GateData{NewGateData(cred.PublicKey(), &tkn)})
+	require.NoError(t, err)
+	box.InlinePolicy = []byte(testInlinePolicy)
+
+	data, err := box.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, box2.Unmarshal(data))
+
+	res, err := box2.GetBox(cred)
+	require.NoError(t, err)
+	require.NotNil(t, res.InlinePolicy)
+	require.True(t, res.InlinePolicy.IsAllowed("s3:GetObject", "arn:aws:s3:::bucket/obj"))
+	require.False(t, res.InlinePolicy.IsAllowed("s3:DeleteObject", "arn:aws:s3:::bucket/obj"))
+}
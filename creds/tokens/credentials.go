@@ -4,15 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
 )
 
 type (
@@ -20,12 +23,28 @@ type (
 	Credentials interface {
 		GetBox(context.Context, oid.Address) (*accessbox.Box, error)
 		Put(context.Context, cid.ID, user.ID, *accessbox.AccessBox, uint64, ...*keys.PublicKey) (oid.Address, error)
+		Update(context.Context, oid.Address, user.ID, *accessbox.AccessBox, uint64, ...*keys.PublicKey) (oid.Address, error)
+		// RefreshExpiring reloads cached boxes which bearer tokens expire before the epoch
+		// to pick up their renewed versions. Boxes which can't be reloaded are removed from cache.
+		RefreshExpiring(context.Context, uint64) error
+		// ResolveAccessKeyID returns address of the access box with the imported access key ID
+		// stored in the container by the container owner.
+		ResolveAccessKeyID(context.Context, cid.ID, string) (oid.Address, error)
 	}
 
 	cred struct {
 		key   *keys.PrivateKey
 		neoFS NeoFS
 		cache *cache.AccessBoxCache
+		log   *zap.Logger
+
+		resolved *cache.AccessKeyIDCache
+	}
+
+	// accessBoxVersion is a version of the access box along with its decoded tokens.
+	accessBoxVersion struct {
+		raw *accessbox.AccessBox
+		box *accessbox.Box
 	}
 )
 
@@ -45,6 +64,25 @@ type PrmObjectCreate struct {
 
 	// Object payload.
 	Payload []byte
+
+	// Additional object attributes.
+	Attributes [][2]string
+}
+
+// PrmObjectSearch groups parameters of objects search by credential tool.
+type PrmObjectSearch struct {
+	// NeoFS container to search objects in.
+	Container cid.ID
+
+	// Key of the attribute the objects must have.
+	// Objects aren't filtered by attribute if empty.
+	Attribute string
+
+	// Value of the attribute.
+	Value string
+
+	// Owner of the objects. Objects aren't filtered by owner if nil.
+	Owner *user.ID
 }
 
 // NeoFS represents virtual connection to NeoFS network.
@@ -63,20 +101,52 @@ type NeoFS interface {
 	// It returns exactly one non-nil value. It returns any error encountered which
 	// prevented the object payload from being read.
 	ReadObjectPayload(context.Context, oid.Address) ([]byte, error)
+
+	// SearchObjects returns identifiers of root objects from the specified
+	// NeoFS container matching the search parameters.
+	//
+	// It returns any error encountered which prevented the objects from being found.
+	SearchObjects(context.Context, PrmObjectSearch) ([]oid.ID, error)
+
+	// ContainerOwner returns the owner of the NeoFS container.
+	//
+	// It returns any error encountered which prevented the container from being read.
+	ContainerOwner(context.Context, cid.ID) (user.ID, error)
 }
 
+const (
+	// AttributeAccessBoxOrigin is an attribute of the access box with the rotated secret.
+	// It contains ID of the original access box object.
+	AttributeAccessBoxOrigin = "S3-Access-Box-Origin"
+
+	// AttributeAccessKeyID is an attribute of the access box with the imported access key ID.
+	AttributeAccessKeyID = "S3-Access-Key-Id"
+)
+
 var (
 	// ErrEmptyPublicKeys is returned when no HCS keys are provided.
 	ErrEmptyPublicKeys = errors.New("HCS public keys could not be empty")
 	// ErrEmptyBearerToken is returned when no bearer token is provided.
 	ErrEmptyBearerToken = errors.New("Bearer token could not be empty")
+	// ErrMultipleGates is returned on update of the access box issued for multiple gates.
+	ErrMultipleGates = errors.New("access box issued for multiple gates can't be updated")
+	// ErrAccessKeyIDNotFound is returned when no access box with the imported access key ID is found.
+	ErrAccessKeyIDNotFound = errors.New("access key id not found")
+	// ErrAccessKeyIDExists is returned when several access boxes with the imported access key ID are found.
+	ErrAccessKeyIDExists = errors.New("access key id already exists")
 )
 
 var _ = New
 
 // New creates a new Credentials instance using the given cli and key.
 func New(neoFS NeoFS, key *keys.PrivateKey, config *cache.Config) Credentials {
-	return &cred{neoFS: neoFS, key: key, cache: cache.NewAccessBoxCache(config)}
+	return &cred{
+		neoFS:    neoFS,
+		key:      key,
+		cache:    cache.NewAccessBoxCache(config),
+		log:      config.Logger,
+		resolved: cache.NewAccessKeyIDCache(config),
+	}
 }
 
 func (c *cred) GetBox(ctx context.Context, addr oid.Address) (*accessbox.Box, error) {
@@ -85,21 +155,168 @@ func (c *cred) GetBox(ctx context.Context, addr oid.Address) (*accessbox.Box, er
 		return cachedBox, nil
 	}
 
-	box, err := c.getAccessBox(ctx, addr)
+	cachedBox, err := c.loadBox(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.cache.Put(addr, cachedBox); err != nil {
+		return nil, fmt.Errorf("put box into cache: %w", err)
+	}
+
+	return cachedBox, nil
+}
+
+func (c *cred) RefreshExpiring(ctx context.Context, epoch uint64) error {
+	for _, addr := range c.cache.Addresses() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		cachedBox := c.cache.Get(addr)
+		if cachedBox == nil || cachedBox.Gate.BearerTokenExpiration() > epoch {
+			continue
+		}
+
+		box, err := c.loadBox(ctx, addr)
+		if err != nil {
+			// the box is read from NeoFS again on the next request
+			c.cache.Delete(addr)
+			c.log.Warn("couldn't reload access box", zap.Stringer("address", addr), zap.Error(err))
+			continue
+		}
+
+		if err = c.cache.Put(addr, box); err != nil {
+			c.log.Warn("couldn't put access box into cache", zap.Stringer("address", addr), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+func (c *cred) ResolveAccessKeyID(ctx context.Context, idCnr cid.ID, accessKeyID string) (oid.Address, error) {
+	if addr := c.resolved.Get(accessKeyID); addr != nil && addr.Container().Equals(idCnr) {
+		return *addr, nil
+	}
+
+	// anyone allowed to put objects into the container could add a box with the same
+	// access key ID, so only the boxes of the container owner are taken into account
+	owner, err := c.neoFS.ContainerOwner(ctx, idCnr)
+	if err != nil {
+		return oid.Address{}, fmt.Errorf("get container owner: %w", err)
+	}
+
+	ids, err := c.neoFS.SearchObjects(ctx, PrmObjectSearch{
+		Container: idCnr,
+		Attribute: AttributeAccessKeyID,
+		Value:     accessKeyID,
+		Owner:     &owner,
+	})
+	if err != nil {
+		return oid.Address{}, fmt.Errorf("search access box: %w", err)
+	}
+
+	switch len(ids) {
+	case 0:
+		return oid.Address{}, ErrAccessKeyIDNotFound
+	case 1:
+	default:
+		return oid.Address{}, ErrAccessKeyIDExists
+	}
+
+	var addr oid.Address
+	addr.SetContainer(idCnr)
+	addr.SetObject(ids[0])
+
+	if err = c.resolved.Put(accessKeyID, addr); err != nil {
+		c.log.Warn("couldn't put access key id into cache", zap.String("access_key_id", accessKeyID), zap.Error(err))
+	}
+
+	return addr, nil
+}
+
+// loadBox reads the latest version of the access box from NeoFS.
+func (c *cred) loadBox(ctx context.Context, addr oid.Address) (*accessbox.Box, error) {
+	versions, err := c.getAccessBoxVersions(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := versions[0]
+	box := latest.box
+	if len(versions) > 1 && latest.raw.GraceExpiration > time.Now().Unix() {
+		box.PreviousAccessKey = versions[1].box.Gate.AccessKey
+		box.GraceExpiration = time.Unix(latest.raw.GraceExpiration, 0)
+	}
+
+	return box, nil
+}
+
+// getAccessBoxVersions returns the original access box and the ones with rotated secrets
+// sorted from the latest version to the original one. Only versions created by the issuer
+// of the original bearer token are taken into account, unreadable versions are skipped.
+func (c *cred) getAccessBoxVersions(ctx context.Context, addr oid.Address) ([]accessBoxVersion, error) {
+	original, err := c.getAccessBox(ctx, addr)
 	if err != nil {
 		return nil, fmt.Errorf("get access box: %w", err)
 	}
 
-	cachedBox, err = box.GetBox(c.key)
+	box, err := original.GetBox(c.key)
 	if err != nil {
 		return nil, fmt.Errorf("get box: %w", err)
 	}
 
-	if err = c.cache.Put(addr, cachedBox); err != nil {
-		return nil, fmt.Errorf("put box into cache: %w", err)
+	issuer := bearer.ResolveIssuer(*box.Gate.BearerToken)
+	ids, err := c.neoFS.SearchObjects(ctx, PrmObjectSearch{
+		Container: addr.Container(),
+		Attribute: AttributeAccessBoxOrigin,
+		Value:     addr.Object().EncodeToString(),
+		Owner:     &issuer,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search access box versions: %w", err)
 	}
 
-	return cachedBox, nil
+	versions := []accessBoxVersion{{raw: original, box: box}}
+	for _, id := range ids {
+		var versionAddr oid.Address
+		versionAddr.SetContainer(addr.Container())
+		versionAddr.SetObject(id)
+
+		version, err := c.getAccessBoxVersion(ctx, versionAddr, issuer)
+		if err != nil {
+			c.log.Warn("skip access box version",
+				zap.Stringer("address", versionAddr), zap.Error(err))
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].raw.Version > versions[j].raw.Version
+	})
+
+	return versions, nil
+}
+
+// getAccessBoxVersion reads the version of the access box and checks that its bearer token
+// is issued by the same user as the token of the original box.
+func (c *cred) getAccessBoxVersion(ctx context.Context, addr oid.Address, issuer user.ID) (accessBoxVersion, error) {
+	raw, err := c.getAccessBox(ctx, addr)
+	if err != nil {
+		return accessBoxVersion{}, err
+	}
+
+	box, err := raw.GetBox(c.key)
+	if err != nil {
+		return accessBoxVersion{}, fmt.Errorf("get box: %w", err)
+	}
+
+	if tokenIssuer := bearer.ResolveIssuer(*box.Gate.BearerToken); !tokenIssuer.Equals(issuer) {
+		return accessBoxVersion{}, fmt.Errorf("bearer token is issued by '%s' instead of '%s'", tokenIssuer, issuer)
+	}
+
+	return accessBoxVersion{raw: raw, box: box}, nil
 }
 
 func (c *cred) getAccessBox(ctx context.Context, addr oid.Address) (*accessbox.AccessBox, error) {
@@ -128,12 +345,18 @@ func (c *cred) Put(ctx context.Context, idCnr cid.ID, issuer user.ID, box *acces
 		return oid.Address{}, fmt.Errorf("marshall box: %w", err)
 	}
 
+	var attributes [][2]string
+	if box.AccessKeyId != "" {
+		attributes = append(attributes, [2]string{AttributeAccessKeyID, box.AccessKeyId})
+	}
+
 	idObj, err := c.neoFS.CreateObject(ctx, PrmObjectCreate{
 		Creator:         issuer,
 		Container:       idCnr,
 		Filepath:        strconv.FormatInt(time.Now().Unix(), 10) + "_access.box",
 		ExpirationEpoch: expiration,
 		Payload:         data,
+		Attributes:      attributes,
 	})
 	if err != nil {
 		return oid.Address{}, fmt.Errorf("create object: %w", err)
@@ -145,3 +368,45 @@ func (c *cred) Put(ctx context.Context, idCnr cid.ID, issuer user.ID, box *acces
 
 	return addr, nil
 }
+
+// Update puts the new version of the access box with the specified address.
+// The version of the box is set to the next one after the latest existing version.
+func (c *cred) Update(ctx context.Context, addr oid.Address, issuer user.ID, box *accessbox.AccessBox, expiration uint64, keys ...*keys.PublicKey) (oid.Address, error) {
+	if len(keys) == 0 {
+		return oid.Address{}, ErrEmptyPublicKeys
+	} else if box == nil {
+		return oid.Address{}, ErrEmptyBearerToken
+	}
+
+	versions, err := c.getAccessBoxVersions(ctx, addr)
+	if err != nil {
+		return oid.Address{}, err
+	}
+	if len(versions[0].raw.Gates) > 1 {
+		return oid.Address{}, ErrMultipleGates
+	}
+	box.Version = versions[0].raw.Version + 1
+
+	data, err := box.Marshal()
+	if err != nil {
+		return oid.Address{}, fmt.Errorf("marshall box: %w", err)
+	}
+
+	idObj, err := c.neoFS.CreateObject(ctx, PrmObjectCreate{
+		Creator:         issuer,
+		Container:       addr.Container(),
+		Filepath:        strconv.FormatInt(time.Now().Unix(), 10) + "_access.box",
+		ExpirationEpoch: expiration,
+		Payload:         data,
+		Attributes:      [][2]string{{AttributeAccessBoxOrigin, addr.Object().EncodeToString()}},
+	})
+	if err != nil {
+		return oid.Address{}, fmt.Errorf("create object: %w", err)
+	}
+
+	var newAddr oid.Address
+	newAddr.SetObject(idObj)
+	newAddr.SetContainer(addr.Container())
+
+	return newAddr, nil
+}
@@ -0,0 +1,209 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type neoFSMock struct {
+	objects    map[oid.Address][]byte
+	attributes map[oid.Address][][2]string
+	owners     map[oid.Address]user.ID
+
+	containerOwner user.ID
+}
+
+func newNeoFSMock() *neoFSMock {
+	return &neoFSMock{
+		objects:    make(map[oid.Address][]byte),
+		attributes: make(map[oid.Address][][2]string),
+		owners:     make(map[oid.Address]user.ID),
+	}
+}
+
+func (n *neoFSMock) CreateObject(_ context.Context, prm PrmObjectCreate) (oid.ID, error) {
+	var addr oid.Address
+	addr.SetContainer(prm.Container)
+	addr.SetObject(oidtest.ID())
+
+	n.objects[addr] = prm.Payload
+	n.attributes[addr] = prm.Attributes
+	n.owners[addr] = prm.Creator
+
+	return addr.Object(), nil
+}
+
+func (n *neoFSMock) ContainerOwner(context.Context, cid.ID) (user.ID, error) {
+	return n.containerOwner, nil
+}
+
+func (n *neoFSMock) ReadObjectPayload(_ context.Context, addr oid.Address) ([]byte, error) {
+	data, ok := n.objects[addr]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (n *neoFSMock) SearchObjects(_ context.Context, prm PrmObjectSearch) ([]oid.ID, error) {
+	var res []oid.ID
+	for addr, attrs := range n.attributes {
+		if !addr.Container().Equals(prm.Container) {
+			continue
+		}
+		if prm.Owner != nil && !n.owners[addr].Equals(*prm.Owner) {
+			continue
+		}
+		for _, attr := range attrs {
+			if attr[0] == prm.Attribute && attr[1] == prm.Value {
+				res = append(res, addr.Object())
+			}
+		}
+	}
+	return res, nil
+}
+
+func TestAccessBoxVersions(t *testing.T) {
+	gateKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	var tkn bearer.Token
+	tkn.SetEACLTable(*eacl.NewTable())
+	require.NoError(t, tkn.Sign(gateKey.PrivateKey))
+	issuer := bearer.ResolveIssuer(tkn)
+
+	neoFS := newNeoFSMock()
+	newCreds := func() Credentials {
+		return New(neoFS, gateKey, cache.DefaultAccessBoxConfig(zap.NewNop()))
+	}
+
+	packBox := func() (*accessbox.AccessBox, *accessbox.Secrets) {
+		box, secrets, err := accessbox.PackTokens([]*accessbox.GateData{accessbox.NewGateData(gateKey.PublicKey(), &tkn)})
+		require.NoError(t, err)
+		return box, secrets
+	}
+
+	box, secrets := packBox()
+	addr, err := newCreds().Put(context.Background(), cidtest.ID(), issuer, box, 10, gateKey.PublicKey())
+	require.NoError(t, err)
+
+	rotatedBox, rotatedSecrets := packBox()
+	rotatedBox.GraceExpiration = time.Now().Add(time.Hour).Unix()
+	_, err = newCreds().Update(context.Background(), addr, issuer, rotatedBox, 10, gateKey.PublicKey())
+	require.NoError(t, err)
+
+	res, err := newCreds().GetBox(context.Background(), addr)
+	require.NoError(t, err)
+	require.Equal(t, rotatedSecrets.AccessKey, res.Gate.AccessKey)
+	require.Equal(t, []string{rotatedSecrets.AccessKey, secrets.AccessKey}, res.AccessKeys())
+
+	lastBox, lastSecrets := packBox()
+	_, err = newCreds().Update(context.Background(), addr, issuer, lastBox, 10, gateKey.PublicKey())
+	require.NoError(t, err)
+	require.EqualValues(t, 2, lastBox.Version)
+
+	res, err = newCreds().GetBox(context.Background(), addr)
+	require.NoError(t, err)
+	require.Equal(t, []string{lastSecrets.AccessKey}, res.AccessKeys())
+
+	otherKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	var otherTkn bearer.Token
+	otherTkn.SetEACLTable(*eacl.NewTable())
+	require.NoError(t, otherTkn.Sign(otherKey.PrivateKey))
+
+	foreignBox, _, err := accessbox.PackTokens([]*accessbox.GateData{accessbox.NewGateData(gateKey.PublicKey(), &otherTkn)})
+	require.NoError(t, err)
+	_, err = newCreds().Update(context.Background(), addr, issuer, foreignBox, 10, gateKey.PublicKey())
+	require.NoError(t, err)
+
+	otherOwnerBox, _ := packBox()
+	_, err = newCreds().Update(context.Background(), addr, bearer.ResolveIssuer(otherTkn), otherOwnerBox, 10, gateKey.PublicKey())
+	require.NoError(t, err)
+
+	var brokenAddr oid.Address
+	brokenAddr.SetContainer(addr.Container())
+	brokenAddr.SetObject(oidtest.ID())
+	neoFS.objects[brokenAddr] = []byte("garbage")
+	neoFS.attributes[brokenAddr] = [][2]string{{AttributeAccessBoxOrigin, addr.Object().EncodeToString()}}
+	neoFS.owners[brokenAddr] = issuer
+
+	res, err = newCreds().GetBox(context.Background(), addr)
+	require.NoError(t, err)
+	require.Equal(t, []string{lastSecrets.AccessKey}, res.AccessKeys())
+}
+
+func TestRefreshExpiring(t *testing.T) {
+	ctx := context.Background()
+	gateKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	var issuer user.ID
+	user.IDFromKey(&issuer, gateKey.PrivateKey.PublicKey)
+
+	neoFS := newNeoFSMock()
+	creds := New(neoFS, gateKey, cache.DefaultAccessBoxConfig(zap.NewNop()))
+
+	packBox := func(exp uint64) (*accessbox.AccessBox, *accessbox.Secrets) {
+		var tkn bearer.Token
+		tkn.SetEACLTable(*eacl.NewTable())
+		tkn.SetExp(exp)
+		require.NoError(t, tkn.Sign(gateKey.PrivateKey))
+
+		box, secrets, err := accessbox.PackTokens([]*accessbox.GateData{accessbox.NewGateData(gateKey.PublicKey(), &tkn)})
+		require.NoError(t, err)
+		return box, secrets
+	}
+
+	box, _ := packBox(10)
+	addr, err := creds.Put(ctx, cidtest.ID(), issuer, box, 10, gateKey.PublicKey())
+	require.NoError(t, err)
+
+	res, err := creds.GetBox(ctx, addr)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, res.Gate.BearerTokenExpiration())
+
+	renewedBox, renewedSecrets := packBox(20)
+	_, err = New(neoFS, gateKey, cache.DefaultAccessBoxConfig(zap.NewNop())).Update(ctx, addr, issuer, renewedBox, 20, gateKey.PublicKey())
+	require.NoError(t, err)
+
+	require.NoError(t, creds.RefreshExpiring(ctx, 5))
+	res, err = creds.GetBox(ctx, addr)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, res.Gate.BearerTokenExpiration())
+
+	require.NoError(t, creds.RefreshExpiring(ctx, 10))
+	res, err = creds.GetBox(ctx, addr)
+	require.NoError(t, err)
+	require.EqualValues(t, 20, res.Gate.BearerTokenExpiration())
+	require.Equal(t, renewedSecrets.AccessKey, res.Gate.AccessKey)
+
+	lostBox, _ := packBox(10)
+	lostAddr, err := creds.Put(ctx, cidtest.ID(), issuer, lostBox, 10, gateKey.PublicKey())
+	require.NoError(t, err)
+	_, err = creds.GetBox(ctx, lostAddr)
+	require.NoError(t, err)
+	delete(neoFS.objects, lostAddr)
+
+	require.NoError(t, creds.RefreshExpiring(ctx, 20))
+	_, err = creds.GetBox(ctx, lostAddr)
+	require.Error(t, err)
+	res, err = creds.GetBox(ctx, addr)
+	require.NoError(t, err)
+	require.EqualValues(t, 20, res.Gate.BearerTokenExpiration())
+}
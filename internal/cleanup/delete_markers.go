@@ -0,0 +1,98 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"go.uber.org/zap"
+)
+
+type (
+	// VersionsTree is a part of the tree service used to store versions.
+	VersionsTree interface {
+		GetAllVersionsByPrefix(ctx context.Context, bktInfo *data.BucketInfo, prefix string) ([]*data.NodeVersion, error)
+		RemoveVersion(ctx context.Context, bktInfo *data.BucketInfo, nodeID uint64) error
+	}
+
+	// DeleteMarkers removes delete markers of objects which have no other versions.
+	DeleteMarkers struct {
+		tree VersionsTree
+		log  *zap.Logger
+	}
+
+	// DeleteMarkersResult is a result of the cleanup of a bucket.
+	DeleteMarkersResult struct {
+		// Objects is the number of object names whose versions are all delete markers.
+		Objects int
+		// Removed is the number of removed delete markers.
+		Removed int
+	}
+)
+
+// NewDeleteMarkers creates DeleteMarkers cleanup.
+func NewDeleteMarkers(tree VersionsTree, log *zap.Logger) *DeleteMarkers {
+	return &DeleteMarkers{
+		tree: tree,
+		log:  log,
+	}
+}
+
+// Collect removes expired delete markers of the bucket: markers of objects which have no versions
+// except delete markers. Markers created less than maxAge ago are kept, so recent deletions stay
+// visible in the version listing for a while. If dryRun is set, the tree service isn't changed.
+func (d *DeleteMarkers) Collect(ctx context.Context, bktInfo *data.BucketInfo, maxAge time.Duration, dryRun bool) (DeleteMarkersResult, error) {
+	var res DeleteMarkersResult
+	deadline := time.Now().Add(-maxAge)
+
+	versions, err := d.tree.GetAllVersionsByPrefix(ctx, bktInfo, "")
+	if err != nil {
+		return res, fmt.Errorf("get versions: %w", err)
+	}
+
+	byPath := make(map[string][]*data.NodeVersion)
+	for _, v := range versions {
+		byPath[v.FilePath] = append(byPath[v.FilePath], v)
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if !onlyDeleteMarkers(byPath[path]) {
+			continue
+		}
+		res.Objects++
+
+		for _, v := range byPath[path] {
+			if v.DeleteMarker.Created.After(deadline) {
+				continue
+			}
+
+			if !dryRun {
+				if err = d.tree.RemoveVersion(ctx, bktInfo, v.ID); err != nil {
+					return res, fmt.Errorf("remove delete marker '%s' of '%s': %w", v.OID.EncodeToString(), path, err)
+				}
+			}
+			d.log.Debug("expired delete marker is removed", zap.Stringer("cid", bktInfo.CID),
+				zap.String("object", path), zap.Stringer("version", v.OID), zap.Bool("dry_run", dryRun))
+			res.Removed++
+		}
+	}
+
+	return res, nil
+}
+
+func onlyDeleteMarkers(versions []*data.NodeVersion) bool {
+	for _, v := range versions {
+		if !v.IsDeleteMarker() {
+			return false
+		}
+	}
+	return true
+}
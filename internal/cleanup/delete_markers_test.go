@@ -0,0 +1,67 @@
+package cleanup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCollectDeleteMarkers(t *testing.T) {
+	ctx := context.Background()
+	bktInfo := &data.BucketInfo{CID: cidtest.ID()}
+	tree := layer.NewTreeService()
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+
+	add := func(path string, deleteMarker *time.Time) {
+		v := &data.NodeVersion{BaseNodeVersion: data.BaseNodeVersion{OID: oidtest.ID(), FilePath: path}}
+		if deleteMarker != nil {
+			v.DeleteMarker = &data.DeleteMarkerInfo{Created: *deleteMarker}
+		}
+		_, err := tree.AddVersion(ctx, bktInfo, v)
+		require.NoError(t, err)
+	}
+
+	add("expired", &old)
+	add("expired", &old)
+	add("recent", &old)
+	add("recent", &now)
+	add("versioned", nil)
+	add("versioned", &old)
+	add("object", nil)
+
+	d := NewDeleteMarkers(tree, zap.NewNop())
+
+	res, err := d.Collect(ctx, bktInfo, 24*time.Hour, true)
+	require.NoError(t, err)
+	require.Equal(t, DeleteMarkersResult{Objects: 2, Removed: 3}, res)
+
+	res, err = d.Collect(ctx, bktInfo, 24*time.Hour, false)
+	require.NoError(t, err)
+	require.Equal(t, DeleteMarkersResult{Objects: 2, Removed: 3}, res)
+
+	versions, err := tree.GetVersions(ctx, bktInfo, "expired")
+	require.NoError(t, err)
+	require.Empty(t, versions)
+
+	versions, err = tree.GetVersions(ctx, bktInfo, "recent")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	require.Equal(t, now, versions[0].DeleteMarker.Created)
+
+	versions, err = tree.GetVersions(ctx, bktInfo, "versioned")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+
+	res, err = d.Collect(ctx, bktInfo, 24*time.Hour, false)
+	require.NoError(t, err)
+	require.Equal(t, DeleteMarkersResult{Objects: 1}, res)
+}
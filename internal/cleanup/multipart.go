@@ -0,0 +1,279 @@
+// Package cleanup deletes data left in buckets by abandoned or interrupted operations.
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"go.uber.org/zap"
+)
+
+type (
+	// NeoFS is a part of NeoFS used to find and delete objects of the buckets.
+	NeoFS interface {
+		// SearchObjectsWithAttribute returns IDs of root objects of the container having the attribute.
+		SearchObjectsWithAttribute(ctx context.Context, cnrID cid.ID, attribute string) ([]oid.ID, error)
+		ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer.ObjectPart, error)
+		DeleteObject(ctx context.Context, prm layer.PrmObjectDelete) error
+	}
+
+	// TreeService is a part of the tree service used to store multipart uploads.
+	TreeService interface {
+		GetMultipartUploadsByPrefix(ctx context.Context, bktInfo *data.BucketInfo, prefix string) ([]*data.MultipartInfo, error)
+		GetParts(ctx context.Context, bktInfo *data.BucketInfo, multipartNodeID uint64) ([]*data.PartInfo, error)
+		DeleteMultipartUpload(ctx context.Context, bktInfo *data.BucketInfo, multipartNodeID uint64) error
+	}
+
+	// Multipart deletes parts of stale multipart uploads and parts which don't belong to any upload.
+	Multipart struct {
+		neoFS NeoFS
+		tree  TreeService
+		log   *zap.Logger
+	}
+
+	// MultipartResult is a result of the cleanup of a bucket.
+	MultipartResult struct {
+		// StaleUploads is the number of aborted uploads which weren't completed in time.
+		StaleUploads int
+		// Parts is the number of deleted part objects.
+		Parts int
+		// Size is the total payload size of the deleted part objects.
+		Size uint64
+	}
+
+	// partObject is a part of a multipart upload found in the container.
+	partObject struct {
+		id        oid.ID
+		uploadID  string
+		size      uint64
+		timestamp int64
+	}
+)
+
+// NewMultipart creates Multipart cleanup.
+func NewMultipart(neoFS NeoFS, tree TreeService, log *zap.Logger) *Multipart {
+	return &Multipart{
+		neoFS: neoFS,
+		tree:  tree,
+		log:   log,
+	}
+}
+
+// Collect aborts multipart uploads of the bucket created more than maxAge ago and deletes
+// part objects which belong neither to uploads in progress nor to completed objects.
+// Uploads with parts added less than maxAge ago aren't stale. Parts created less than
+// maxAge ago are kept even if they don't belong to any upload, since the upload of the
+// part can be in progress. If dryRun is set, nothing is deleted.
+func (m *Multipart) Collect(ctx context.Context, bktInfo *data.BucketInfo, maxAge time.Duration, dryRun bool) (MultipartResult, error) {
+	var res MultipartResult
+	deadline := time.Now().Add(-maxAge)
+
+	uploads, err := m.tree.GetMultipartUploadsByPrefix(ctx, bktInfo, "")
+	if err != nil {
+		return res, fmt.Errorf("get multipart uploads: %w", err)
+	}
+
+	inUse := make(map[oid.ID]struct{})
+	staleParts := make(map[oid.ID]struct{})
+	var staleUploads []*data.MultipartInfo
+	for _, upload := range uploads {
+		parts, err := m.tree.GetParts(ctx, bktInfo, upload.ID)
+		if err != nil {
+			return res, fmt.Errorf("get parts of upload '%s': %w", upload.UploadID, err)
+		}
+
+		set := inUse
+		if upload.Created.Before(deadline) && !hasRecentParts(parts, deadline) {
+			staleUploads = append(staleUploads, upload)
+			set = staleParts
+		}
+		for _, part := range parts {
+			set[part.OID] = struct{}{}
+		}
+	}
+
+	// completed objects are immutable, so each of them is read once
+	completedObjects := make(map[oid.ID]struct{})
+	if err = m.addCompletedRefs(ctx, bktInfo.CID, completedObjects, inUse); err != nil {
+		return res, err
+	}
+
+	parts, err := m.findParts(ctx, bktInfo.CID)
+	if err != nil {
+		return res, err
+	}
+
+	var garbage []partObject
+	for _, part := range parts {
+		if _, ok := inUse[part.id]; ok {
+			continue
+		}
+		if _, ok := staleParts[part.id]; !ok && (part.timestamp == 0 || time.Unix(part.timestamp, 0).After(deadline)) {
+			continue
+		}
+		garbage = append(garbage, part)
+	}
+
+	// a stale upload can be completed while the container is scanned, so parts referenced
+	// by new completed objects are searched once more right before the deletion
+	if len(garbage) > 0 && !dryRun {
+		if err = m.addCompletedRefs(ctx, bktInfo.CID, completedObjects, inUse); err != nil {
+			return res, err
+		}
+	}
+
+	completed := make(map[string]struct{})
+	for _, part := range garbage {
+		if _, ok := inUse[part.id]; ok {
+			completed[part.uploadID] = struct{}{}
+			m.log.Info("part of stale multipart upload is referenced by completed object, skip it",
+				zap.Stringer("cid", bktInfo.CID), zap.Stringer("oid", part.id), zap.String("upload id", part.uploadID))
+			continue
+		}
+
+		if !dryRun {
+			if err = m.neoFS.DeleteObject(ctx, layer.PrmObjectDelete{Container: bktInfo.CID, Object: part.id}); err != nil && !isRemoved(err) {
+				return res, fmt.Errorf("delete part '%s': %w", part.id.EncodeToString(), err)
+			}
+		}
+		m.log.Debug("part of multipart upload is collected", zap.Stringer("cid", bktInfo.CID),
+			zap.Stringer("oid", part.id), zap.String("upload id", part.uploadID), zap.Bool("dry_run", dryRun))
+		res.Parts++
+		res.Size += part.size
+	}
+
+	// uploads are deleted after their parts, so the parts aren't lost if the deletion fails
+	for _, upload := range staleUploads {
+		if _, ok := completed[upload.UploadID]; ok {
+			continue
+		}
+		if !dryRun {
+			if err = m.tree.DeleteMultipartUpload(ctx, bktInfo, upload.ID); err != nil {
+				return res, fmt.Errorf("delete upload '%s': %w", upload.UploadID, err)
+			}
+		}
+		m.log.Info("stale multipart upload is aborted", zap.Stringer("cid", bktInfo.CID),
+			zap.String("object", upload.Key), zap.String("upload id", upload.UploadID),
+			zap.Time("created", upload.Created), zap.Bool("dry_run", dryRun))
+		res.StaleUploads++
+	}
+
+	return res, nil
+}
+
+func hasRecentParts(parts []*data.PartInfo, deadline time.Time) bool {
+	for _, part := range parts {
+		if part.Created.After(deadline) {
+			return true
+		}
+	}
+	return false
+}
+
+// findParts returns part objects of the container. Parts deleted while they are headed are skipped.
+func (m *Multipart) findParts(ctx context.Context, cnrID cid.ID) ([]partObject, error) {
+	ids, err := m.neoFS.SearchObjectsWithAttribute(ctx, cnrID, layer.UploadIDAttributeName)
+	if err != nil {
+		return nil, fmt.Errorf("search parts: %w", err)
+	}
+
+	res := make([]partObject, 0, len(ids))
+	for _, id := range ids {
+		obj, err := m.neoFS.ReadObject(ctx, layer.PrmObjectRead{
+			Container:  cnrID,
+			Object:     id,
+			WithHeader: true,
+		})
+		if err != nil {
+			if isRemoved(err) {
+				continue
+			}
+			return nil, fmt.Errorf("head part '%s': %w", id.EncodeToString(), err)
+		}
+
+		part := partObject{id: id, size: obj.Head.PayloadSize()}
+		var combined bool
+		for _, attr := range obj.Head.Attributes() {
+			switch attr.Key() {
+			case layer.UploadIDAttributeName:
+				part.uploadID = attr.Value()
+			case layer.MultipartObjectSize:
+				combined = true
+			case object.AttributeTimestamp:
+				part.timestamp, _ = strconv.ParseInt(attr.Value(), 10, 64)
+			}
+		}
+		if !combined {
+			res = append(res, part)
+		}
+	}
+
+	return res, nil
+}
+
+// addCompletedRefs adds parts of completed multipart objects of the container to inUse.
+// Objects from the read set are skipped, new ones are added to it. Objects deleted
+// while they are read are skipped.
+func (m *Multipart) addCompletedRefs(ctx context.Context, cnrID cid.ID, read, inUse map[oid.ID]struct{}) error {
+	ids, err := m.neoFS.SearchObjectsWithAttribute(ctx, cnrID, layer.MultipartObjectSize)
+	if err != nil {
+		return fmt.Errorf("search completed objects: %w", err)
+	}
+
+	for _, id := range ids {
+		if _, ok := read[id]; ok {
+			continue
+		}
+		if err = m.addPartRefs(ctx, cnrID, id, inUse); err != nil {
+			if isRemoved(err) {
+				continue
+			}
+			return err
+		}
+		read[id] = struct{}{}
+	}
+
+	return nil
+}
+
+// addPartRefs adds parts the completed multipart object consists of to the set.
+func (m *Multipart) addPartRefs(ctx context.Context, cnrID cid.ID, objID oid.ID, set map[oid.ID]struct{}) error {
+	obj, err := m.neoFS.ReadObject(ctx, layer.PrmObjectRead{
+		Container:   cnrID,
+		Object:      objID,
+		WithPayload: true,
+	})
+	if err != nil {
+		return fmt.Errorf("read combined object '%s': %w", objID.EncodeToString(), err)
+	}
+	payload, err := io.ReadAll(obj.Payload)
+	_ = obj.Payload.Close()
+	if err != nil {
+		return fmt.Errorf("read combined object '%s': %w", objID.EncodeToString(), err)
+	}
+
+	parts, err := layer.DecodeMultipartParts(payload)
+	if err != nil {
+		return fmt.Errorf("combined object '%s': %w", objID.EncodeToString(), err)
+	}
+
+	for _, part := range parts {
+		set[part.OID] = struct{}{}
+	}
+
+	return nil
+}
+
+// isRemoved checks if the object is deleted concurrently with the cleanup.
+func isRemoved(err error) bool {
+	return client.IsErrObjectNotFound(err) || client.IsErrObjectAlreadyRemoved(err)
+}
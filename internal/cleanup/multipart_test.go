@@ -0,0 +1,212 @@
+package cleanup
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testNeoFS struct {
+	*layer.TestNeoFS
+	// beforeSearch is called before objects are searched, if set.
+	beforeSearch func()
+	// removed objects are found by search, but can't be read.
+	removed []oid.ID
+}
+
+func (t testNeoFS) SearchObjectsWithAttribute(ctx context.Context, cnrID cid.ID, attribute string) ([]oid.ID, error) {
+	if t.beforeSearch != nil {
+		t.beforeSearch()
+	}
+
+	res := append([]oid.ID{}, t.removed...)
+	for _, id := range t.AllObjects(cnrID) {
+		obj, err := t.TestNeoFS.ReadObject(ctx, layer.PrmObjectRead{Container: cnrID, Object: id, WithHeader: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, attr := range obj.Head.Attributes() {
+			if attr.Key() == attribute {
+				res = append(res, id)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func (t testNeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer.ObjectPart, error) {
+	for _, id := range t.removed {
+		if id.Equals(prm.Object) {
+			return nil, apistatus.ObjectNotFound{}
+		}
+	}
+	return t.TestNeoFS.ReadObject(ctx, prm)
+}
+
+type testBucket struct {
+	t       *testing.T
+	neoFS   testNeoFS
+	tree    *layer.TreeServiceMock
+	bktInfo *data.BucketInfo
+}
+
+func (b *testBucket) put(path string, created time.Time, payload string, attrs ...[2]string) oid.ID {
+	attrs = append(attrs, [2]string{object.AttributeTimestamp, strconv.FormatInt(created.Unix(), 10)})
+	id, err := b.neoFS.CreateObject(context.Background(), layer.PrmObjectCreate{
+		Container:  b.bktInfo.CID,
+		Filepath:   path,
+		Attributes: attrs,
+		Payload:    bytes.NewReader([]byte(payload)),
+	})
+	require.NoError(b.t, err)
+	return id
+}
+
+func (b *testBucket) putPart(uploadID string, created time.Time) oid.ID {
+	return b.put("", created, "content", [2]string{layer.UploadIDAttributeName, uploadID},
+		[2]string{layer.UploadPartNumberAttributeName, "1"})
+}
+
+func (b *testBucket) putCompleted(key string, created time.Time, part oid.ID) oid.ID {
+	return b.put(key, created, `[{"oid":"`+part.EncodeToString()+`","size":7}]`,
+		[2]string{layer.MultipartObjectSize, "7"})
+}
+
+func (b *testBucket) createUpload(key, uploadID string, created time.Time, parts ...oid.ID) {
+	b.createUploadWithParts(key, uploadID, created, created, parts...)
+}
+
+func (b *testBucket) createUploadWithParts(key, uploadID string, created, partsCreated time.Time, parts ...oid.ID) {
+	ctx := context.Background()
+	info := &data.MultipartInfo{Key: key, UploadID: uploadID, Created: created}
+	require.NoError(b.t, b.tree.CreateMultipartUpload(ctx, b.bktInfo, info))
+
+	for i, part := range parts {
+		_, err := b.tree.AddPart(ctx, b.bktInfo, info.ID, &data.PartInfo{
+			Key:      key,
+			UploadID: uploadID,
+			Number:   i + 1,
+			OID:      part,
+			Size:     int64(len("content")),
+			Created:  partsCreated,
+		})
+		if err != nil {
+			require.ErrorIs(b.t, err, layer.ErrNoNodeToRemove)
+		}
+	}
+}
+
+func (b *testBucket) exists(id oid.ID) bool {
+	_, err := b.neoFS.ReadObject(context.Background(), layer.PrmObjectRead{Container: b.bktInfo.CID, Object: id, WithHeader: true})
+	return err == nil
+}
+
+func TestCollectMultipart(t *testing.T) {
+	ctx := context.Background()
+	b := &testBucket{
+		t:       t,
+		neoFS:   testNeoFS{TestNeoFS: layer.NewTestNeoFS()},
+		tree:    layer.NewTreeService(),
+		bktInfo: &data.BucketInfo{CID: cidtest.ID()},
+	}
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+
+	activePart := b.putPart("active", old)
+	b.createUpload("active", "active", now, activePart)
+
+	stalePart := b.putPart("stale", old)
+	b.createUpload("stale", "stale", old, stalePart)
+
+	completedPart := b.putPart("completed", old)
+	b.putCompleted("completed", old, completedPart)
+
+	orphanPart := b.putPart("aborted", old)
+	recentPart := b.putPart("aborted", now)
+	obj := b.put("obj", old, "content")
+	// objects deleted concurrently with the cleanup are skipped
+	b.neoFS.removed = []oid.ID{oidtest.ID(), oidtest.ID()}
+
+	m := NewMultipart(b.neoFS, b.tree, zap.NewNop())
+	expected := MultipartResult{StaleUploads: 1, Parts: 2, Size: 2 * uint64(len("content"))}
+
+	res, err := m.Collect(ctx, b.bktInfo, 24*time.Hour, true)
+	require.NoError(t, err)
+	require.Equal(t, expected, res)
+	require.True(t, b.exists(stalePart))
+	require.True(t, b.exists(orphanPart))
+
+	res, err = m.Collect(ctx, b.bktInfo, 24*time.Hour, false)
+	require.NoError(t, err)
+	require.Equal(t, expected, res)
+
+	for _, id := range []oid.ID{activePart, completedPart, recentPart, obj} {
+		require.True(t, b.exists(id))
+	}
+	require.False(t, b.exists(stalePart))
+	require.False(t, b.exists(orphanPart))
+
+	uploads, err := b.tree.GetMultipartUploadsByPrefix(ctx, b.bktInfo, "")
+	require.NoError(t, err)
+	require.Len(t, uploads, 1)
+	require.Equal(t, "active", uploads[0].UploadID)
+
+	res, err = m.Collect(ctx, b.bktInfo, 24*time.Hour, false)
+	require.NoError(t, err)
+	require.Equal(t, MultipartResult{}, res)
+}
+
+func TestCollectMultipartConcurrentCompletion(t *testing.T) {
+	ctx := context.Background()
+	b := &testBucket{
+		t:       t,
+		neoFS:   testNeoFS{TestNeoFS: layer.NewTestNeoFS()},
+		tree:    layer.NewTreeService(),
+		bktInfo: &data.BucketInfo{CID: cidtest.ID()},
+	}
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+
+	recentPart := b.putPart("recent", old)
+	b.createUploadWithParts("recent", "recent", old, now, recentPart)
+
+	completingPart := b.putPart("completing", old)
+	b.createUpload("completing", "completing", old, completingPart)
+
+	var searches int
+	b.neoFS.beforeSearch = func() {
+		// the upload is completed after completed objects are searched the first time
+		if searches++; searches == 2 {
+			b.putCompleted("completing", now, completingPart)
+		}
+	}
+
+	m := NewMultipart(b.neoFS, b.tree, zap.NewNop())
+	res, err := m.Collect(ctx, b.bktInfo, 24*time.Hour, false)
+	require.NoError(t, err)
+	require.Equal(t, MultipartResult{}, res)
+	require.Equal(t, 3, searches)
+
+	require.True(t, b.exists(recentPart))
+	require.True(t, b.exists(completingPart))
+
+	uploads, err := b.tree.GetMultipartUploadsByPrefix(ctx, b.bktInfo, "")
+	require.NoError(t, err)
+	require.Len(t, uploads, 2)
+}
@@ -0,0 +1,271 @@
+// Package migration converts data of buckets created by older versions of the gateway.
+package migration
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"go.uber.org/zap"
+)
+
+// Attributes of objects which kept versioning state before versions were moved to the tree service.
+const (
+	// attrVersionsAdd contains comma-separated IDs of the versions the object was put after.
+	attrVersionsAdd = "S3-Versions-add"
+	// attrVersionsDel contains comma-separated IDs of the versions deleted by the object.
+	attrVersionsDel = "S3-Versions-del"
+	// attrVersionsUnversioned is set for objects put while versioning wasn't enabled.
+	attrVersionsUnversioned = "S3-Versions-unversioned"
+	// attrVersionsDeleteMark is set for delete markers. It contains delMarkFullObject
+	// for markers of the object or ID of the deleted version otherwise.
+	attrVersionsDeleteMark = "S3-Versions-delete-mark"
+	// attrSystemName is set for system objects such as bucket settings.
+	attrSystemName = "S3-System-name"
+
+	delMarkFullObject = "*"
+)
+
+type (
+	// NeoFS is a part of NeoFS used to read objects of the buckets.
+	NeoFS interface {
+		// SearchObjects returns IDs of all root objects of the container.
+		SearchObjects(ctx context.Context, cnrID cid.ID) ([]oid.ID, error)
+		ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer.ObjectPart, error)
+	}
+
+	// TreeService is a part of the tree service used to store versions.
+	TreeService interface {
+		GetVersions(ctx context.Context, bktInfo *data.BucketInfo, objectName string) ([]*data.NodeVersion, error)
+		AddVersion(ctx context.Context, bktInfo *data.BucketInfo, newVersion *data.NodeVersion) (uint64, error)
+	}
+
+	// Versions adds versions of objects kept in object attributes to the tree service.
+	Versions struct {
+		neoFS NeoFS
+		tree  TreeService
+		log   *zap.Logger
+	}
+
+	// VersionsResult is a result of the migration of a bucket.
+	VersionsResult struct {
+		// Objects is the number of objects with file path in the bucket.
+		Objects int
+		// Added is the number of versions added to the tree service.
+		Added int
+		// Deleted is the number of objects deleted by later versions or delete markers, they aren't added.
+		Deleted int
+		// SkippedPaths is the number of object names which already have versions in the tree service.
+		SkippedPaths int
+	}
+
+	// attrVersion is an object version described by the attributes of the object.
+	attrVersion struct {
+		id          oid.ID
+		filePath    string
+		timestamp   int64
+		addList     []string
+		delList     []string
+		deleteMark  string
+		unversioned bool
+		meta        data.ObjectMeta
+		size        int64
+		etag        string
+	}
+)
+
+// NewVersions creates Versions migration.
+func NewVersions(neoFS NeoFS, tree TreeService, log *zap.Logger) *Versions {
+	return &Versions{
+		neoFS: neoFS,
+		tree:  tree,
+		log:   log,
+	}
+}
+
+// Migrate adds versions of all objects of the bucket to the tree service in the order they were put.
+// Objects deleted by later versions are skipped. Object names which already have versions in the
+// tree service are skipped too: migrated versions would become the latest ones, and the names
+// migrated before aren't migrated twice. If dryRun is set, the tree service isn't changed.
+func (m *Versions) Migrate(ctx context.Context, bktInfo *data.BucketInfo, dryRun bool) (VersionsResult, error) {
+	var res VersionsResult
+
+	ids, err := m.neoFS.SearchObjects(ctx, bktInfo.CID)
+	if err != nil {
+		return res, fmt.Errorf("search objects: %w", err)
+	}
+
+	byPath := make(map[string][]*attrVersion)
+	for _, id := range ids {
+		obj, err := m.neoFS.ReadObject(ctx, layer.PrmObjectRead{
+			Container:  bktInfo.CID,
+			Object:     id,
+			WithHeader: true,
+		})
+		if err != nil {
+			return res, fmt.Errorf("head object '%s': %w", id.EncodeToString(), err)
+		}
+
+		if v := newAttrVersion(obj.Head); v != nil {
+			byPath[v.filePath] = append(byPath[v.filePath], v)
+			res.Objects++
+		}
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		existing, err := m.tree.GetVersions(ctx, bktInfo, path)
+		if err != nil && !errors.Is(err, layer.ErrNodeNotFound) {
+			return res, fmt.Errorf("get versions of '%s': %w", path, err)
+		}
+		if len(existing) != 0 {
+			m.log.Warn("object already has versions in tree service, skip it",
+				zap.Stringer("cid", bktInfo.CID), zap.String("object", path))
+			res.SkippedPaths++
+			continue
+		}
+
+		versions := orderVersions(byPath[path])
+		res.Deleted += len(byPath[path]) - len(versions)
+
+		for _, v := range versions {
+			if !dryRun {
+				if _, err = m.tree.AddVersion(ctx, bktInfo, v.nodeVersion()); err != nil {
+					return res, fmt.Errorf("add version '%s' of '%s': %w", v.id.EncodeToString(), path, err)
+				}
+			}
+			res.Added++
+		}
+	}
+
+	return res, nil
+}
+
+// newAttrVersion parses version of the object header, nil is returned for objects
+// which aren't object versions: system objects, parts of multipart uploads and objects without file path.
+func newAttrVersion(head *object.Object) *attrVersion {
+	v := &attrVersion{size: int64(head.PayloadSize())}
+	v.id, _ = head.ID()
+	if owner := head.OwnerID(); owner != nil {
+		v.meta.Owner = *owner
+	}
+	if checksum, ok := head.PayloadChecksum(); ok {
+		v.etag = hex.EncodeToString(checksum.Value())
+	}
+
+	for _, attr := range head.Attributes() {
+		switch attr.Key() {
+		case attrSystemName, layer.UploadIDAttributeName:
+			return nil
+		case object.AttributeFilePath:
+			v.filePath = attr.Value()
+		case object.AttributeTimestamp:
+			v.timestamp, _ = strconv.ParseInt(attr.Value(), 10, 64)
+		case attrVersionsAdd:
+			v.addList = splitIDs(attr.Value())
+		case attrVersionsDel:
+			v.delList = splitIDs(attr.Value())
+		case attrVersionsDeleteMark:
+			v.deleteMark = attr.Value()
+		case attrVersionsUnversioned:
+			v.unversioned = attr.Value() == "true"
+		case layer.AttributeStorageClass:
+			v.meta.StorageClass = attr.Value()
+		case layer.MultipartObjectSize:
+			if size, err := strconv.ParseInt(attr.Value(), 10, 64); err == nil {
+				v.size = size
+			}
+		case layer.MultipartObjectETag:
+			v.etag = attr.Value()
+		}
+	}
+
+	if v.filePath == "" {
+		return nil
+	}
+	v.meta.Created = time.Unix(v.timestamp, 0)
+
+	return v
+}
+
+func splitIDs(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// orderVersions drops deleted versions and markers of deleted versions and orders
+// the rest by the time they were put. Versions put within the same second are
+// ordered by the number of versions they were put after.
+func orderVersions(versions []*attrVersion) []*attrVersion {
+	deleted := make(map[string]struct{})
+	for _, v := range versions {
+		for _, id := range v.delList {
+			deleted[id] = struct{}{}
+		}
+		if v.deleteMark != "" && v.deleteMark != delMarkFullObject {
+			deleted[v.deleteMark] = struct{}{}
+			deleted[v.id.EncodeToString()] = struct{}{}
+		}
+	}
+
+	res := make([]*attrVersion, 0, len(versions))
+	for _, v := range versions {
+		if _, ok := deleted[v.id.EncodeToString()]; !ok {
+			res = append(res, v)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].timestamp != res[j].timestamp {
+			return res[i].timestamp < res[j].timestamp
+		}
+		if len(res[i].addList) != len(res[j].addList) {
+			return len(res[i].addList) < len(res[j].addList)
+		}
+		return res[i].id.EncodeToString() < res[j].id.EncodeToString()
+	})
+
+	return res
+}
+
+func (v *attrVersion) nodeVersion() *data.NodeVersion {
+	res := &data.NodeVersion{
+		BaseNodeVersion: data.BaseNodeVersion{
+			OID:      v.id,
+			FilePath: v.filePath,
+		},
+		IsUnversioned: v.unversioned,
+	}
+
+	if v.deleteMark == delMarkFullObject {
+		res.DeleteMarker = &data.DeleteMarkerInfo{
+			Created: v.meta.Created,
+			Owner:   v.meta.Owner,
+		}
+		return res
+	}
+
+	meta := v.meta
+	res.Size = v.size
+	res.ETag = v.etag
+	res.ObjectMeta = &meta
+
+	return res
+}
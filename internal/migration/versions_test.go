@@ -0,0 +1,105 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testNeoFS struct {
+	*layer.TestNeoFS
+}
+
+func (t testNeoFS) SearchObjects(_ context.Context, cnrID cid.ID) ([]oid.ID, error) {
+	return t.AllObjects(cnrID), nil
+}
+
+type testBucket struct {
+	t       *testing.T
+	neoFS   testNeoFS
+	bktInfo *data.BucketInfo
+}
+
+func (b *testBucket) put(path string, timestamp int64, attrs ...[2]string) oid.ID {
+	attrs = append(attrs, [2]string{object.AttributeTimestamp, strconv.FormatInt(timestamp, 10)})
+	id, err := b.neoFS.CreateObject(context.Background(), layer.PrmObjectCreate{
+		Container:  b.bktInfo.CID,
+		Filepath:   path,
+		Attributes: attrs,
+		Payload:    bytes.NewReader([]byte("content")),
+	})
+	require.NoError(b.t, err)
+	return id
+}
+
+func idList(ids ...oid.ID) string {
+	res := make([]string, len(ids))
+	for i := range ids {
+		res[i] = ids[i].EncodeToString()
+	}
+	return strings.Join(res, ",")
+}
+
+func TestMigrateVersions(t *testing.T) {
+	ctx := context.Background()
+	b := &testBucket{
+		t:       t,
+		neoFS:   testNeoFS{layer.NewTestNeoFS()},
+		bktInfo: &data.BucketInfo{CID: cidtest.ID()},
+	}
+	tree := layer.NewTreeService()
+
+	v1 := b.put("obj", 1)
+	v2 := b.put("obj", 2, [2]string{attrVersionsAdd, idList(v1)})
+	v3 := b.put("obj", 2, [2]string{attrVersionsAdd, idList(v1, v2)})
+	delMark := b.put("obj", 3, [2]string{attrVersionsAdd, idList(v1, v2, v3)},
+		[2]string{attrVersionsDeleteMark, delMarkFullObject})
+	v4 := b.put("obj", 4, [2]string{attrVersionsAdd, idList(v2, v3, delMark)},
+		[2]string{attrVersionsDel, idList(v1)})
+	b.put("obj", 5, [2]string{attrVersionsDeleteMark, v3.EncodeToString()})
+
+	b.put("", 1, [2]string{attrSystemName, "bucket-settings"})
+	b.put("part", 1, [2]string{layer.UploadIDAttributeName, "upload-id"})
+
+	b.put("migrated", 1)
+	_, err := tree.AddVersion(ctx, b.bktInfo, &data.NodeVersion{BaseNodeVersion: data.BaseNodeVersion{FilePath: "migrated"}})
+	require.NoError(t, err)
+
+	m := NewVersions(b.neoFS, tree, zap.NewNop())
+	expected := VersionsResult{Objects: 7, Added: 3, Deleted: 3, SkippedPaths: 1}
+
+	res, err := m.Migrate(ctx, b.bktInfo, true)
+	require.NoError(t, err)
+	require.Equal(t, expected, res)
+	_, err = tree.GetVersions(ctx, b.bktInfo, "obj")
+	require.ErrorIs(t, err, layer.ErrNodeNotFound)
+
+	res, err = m.Migrate(ctx, b.bktInfo, false)
+	require.NoError(t, err)
+	require.Equal(t, expected, res)
+
+	versions, err := tree.GetVersions(ctx, b.bktInfo, "obj")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	require.Equal(t, v2, versions[0].OID)
+	require.Nil(t, versions[0].DeleteMarker)
+	require.Equal(t, int64(len("content")), versions[0].Size)
+	require.Equal(t, delMark, versions[1].OID)
+	require.NotNil(t, versions[1].DeleteMarker)
+	require.Equal(t, v4, versions[2].OID)
+
+	res, err = m.Migrate(ctx, b.bktInfo, false)
+	require.NoError(t, err)
+	require.Equal(t, VersionsResult{Objects: 7, SkippedPaths: 2}, res)
+}
@@ -0,0 +1,80 @@
+package neofs
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// operationsLimiter limits the number of concurrent operations. Zero limit means no limit.
+type operationsLimiter chan struct{}
+
+func newOperationsLimiter(limit int) operationsLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return make(operationsLimiter, limit)
+}
+
+// acquire waits for a free slot. The returned function releases the slot.
+func (l operationsLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() { once.Do(func() { <-l }) }, nil
+}
+
+// limitedPayload releases the slot of the operation when the payload is read up to
+// the end or with an error, the reader is closed or the context is done.
+type limitedPayload struct {
+	io.ReadCloser
+	release func()
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newLimitedPayload(ctx context.Context, r io.ReadCloser, release func()) *limitedPayload {
+	p := &limitedPayload{
+		ReadCloser: r,
+		release:    release,
+		done:       make(chan struct{}),
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.finish()
+		case <-p.done:
+		}
+	}()
+
+	return p
+}
+
+func (p *limitedPayload) finish() {
+	p.once.Do(func() {
+		p.release()
+		close(p.done)
+	})
+}
+
+func (p *limitedPayload) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	if err != nil {
+		p.finish()
+	}
+	return n, err
+}
+
+func (p *limitedPayload) Close() error {
+	p.finish()
+	return p.ReadCloser.Close()
+}
@@ -0,0 +1,70 @@
+package neofs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationsLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no limit", func(t *testing.T) {
+		limiter := newOperationsLimiter(0)
+		for i := 0; i < 10; i++ {
+			_, err := limiter.acquire(ctx)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		limiter := newOperationsLimiter(1)
+
+		release, err := limiter.acquire(ctx)
+		require.NoError(t, err)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err = limiter.acquire(waitCtx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		release()
+		release()
+		_, err = limiter.acquire(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("payload is released at the end", func(t *testing.T) {
+		limiter := newOperationsLimiter(1)
+
+		release, err := limiter.acquire(ctx)
+		require.NoError(t, err)
+
+		payload := newLimitedPayload(ctx, io.NopCloser(bytes.NewReader([]byte("payload"))), release)
+		_, err = io.ReadAll(payload)
+		require.NoError(t, err)
+
+		_, err = limiter.acquire(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("payload is released on context done", func(t *testing.T) {
+		limiter := newOperationsLimiter(1)
+
+		release, err := limiter.acquire(ctx)
+		require.NoError(t, err)
+
+		reqCtx, cancel := context.WithCancel(ctx)
+		newLimitedPayload(reqCtx, io.NopCloser(bytes.NewReader([]byte("payload"))), release)
+		cancel()
+
+		waitCtx, waitCancel := context.WithTimeout(ctx, time.Second)
+		defer waitCancel()
+		_, err = limiter.acquire(waitCtx)
+		require.NoError(t, err)
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	"github.com/nspcc-dev/neofs-s3-gw/authmate"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
 	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
 	"github.com/nspcc-dev/neofs-sdk-go/container"
 	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
@@ -32,6 +33,15 @@ import (
 type NeoFS struct {
 	pool  *pool.Pool
 	await pool.WaitParams
+
+	// Limiters of concurrent object payload operations (put, get, range)
+	// and object metadata operations (head, delete, search, tree service requests).
+	dataLimiter     operationsLimiter
+	metadataLimiter operationsLimiter
+
+	timeouts  OperationTimeouts
+	retry     RetryPolicy
+	retryStat retryStatistic
 }
 
 const (
@@ -51,6 +61,20 @@ func NewNeoFS(p *pool.Pool) *NeoFS {
 	}
 }
 
+// LimitConcurrency sets the maximum number of concurrent object payload and object
+// metadata operations, so a burst of one kind of operations can't starve another one.
+// Zero value means no limit. It must be called before NeoFS is used.
+func (x *NeoFS) LimitConcurrency(data, metadata int) {
+	x.dataLimiter = newOperationsLimiter(data)
+	x.metadataLimiter = newOperationsLimiter(metadata)
+}
+
+// LimitTreeClient makes requests of the tree service client share the limit of concurrent
+// metadata operations. It must be called after LimitConcurrency and before the client is used.
+func (x *NeoFS) LimitTreeClient(c *TreeClient) {
+	c.limiter = x.metadataLimiter
+}
+
 // TimeToEpoch implements neofs.NeoFS interface method.
 func (x *NeoFS) TimeToEpoch(ctx context.Context, now, futureTime time.Time) (uint64, uint64, error) {
 	dur := futureTime.Sub(now)
@@ -87,6 +111,15 @@ func (x *NeoFS) TimeToEpoch(ctx context.Context, now, futureTime time.Time) (uin
 	return curr, epoch, nil
 }
 
+// Healthcheck checks that NeoFS network is reachable via the pool.
+func (x *NeoFS) Healthcheck(ctx context.Context) error {
+	if _, err := x.pool.NetworkInfo(ctx); err != nil {
+		return fmt.Errorf("get network info via client: %w", err)
+	}
+
+	return nil
+}
+
 // Container implements neofs.NeoFS interface method.
 func (x *NeoFS) Container(ctx context.Context, idCnr cid.ID) (*container.Container, error) {
 	var prm pool.PrmContainerGet
@@ -220,7 +253,7 @@ func (x *NeoFS) DeleteContainer(ctx context.Context, id cid.ID, token *session.C
 
 // CreateObject implements neofs.NeoFS interface method.
 func (x *NeoFS) CreateObject(ctx context.Context, prm layer.PrmObjectCreate) (oid.ID, error) {
-	attrNum := len(prm.Attributes) + 1 // + creation time
+	attrNum := len(prm.Attributes) + 2 // + creation time in seconds and milliseconds
 
 	if prm.Filepath != "" {
 		attrNum++
@@ -240,6 +273,12 @@ func (x *NeoFS) CreateObject(ctx context.Context, prm layer.PrmObjectCreate) (oi
 
 	attrs = append(attrs, *a)
 
+	a = object.NewAttribute()
+	a.SetKey(layer.AttributeCreated)
+	a.SetValue(creationTime.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano))
+
+	attrs = append(attrs, *a)
+
 	for i := range prm.Attributes {
 		a = object.NewAttribute()
 		a.SetKey(prm.Attributes[i][0])
@@ -277,7 +316,17 @@ func (x *NeoFS) CreateObject(ctx context.Context, prm layer.PrmObjectCreate) (oi
 		prmPut.UseKey(prm.PrivateKey)
 	}
 
-	idObj, err := x.pool.PutObject(ctx, prmPut)
+	release, err := x.dataLimiter.acquire(ctx)
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("wait for data operations limit: %w", err)
+	}
+	defer release()
+
+	var idObj oid.ID
+	cancel, err := x.call(ctx, OperationPut, func(ctx context.Context) (err error) {
+		idObj, err = x.pool.PutObject(ctx, prmPut)
+		return err
+	})
 	if err != nil {
 		reason, ok := isErrAccessDenied(err)
 		if ok {
@@ -285,6 +334,7 @@ func (x *NeoFS) CreateObject(ctx context.Context, prm layer.PrmObjectCreate) (oi
 		}
 		return oid.ID{}, fmt.Errorf("save object via connection pool: %w", err)
 	}
+	cancel()
 
 	return idObj, nil
 }
@@ -321,25 +371,45 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 		prmGet.UseKey(prm.PrivateKey)
 	}
 
+	limiter := x.dataLimiter
+	if !prm.WithPayload {
+		limiter = x.metadataLimiter
+	}
+
+	release, err := limiter.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("wait for operations limit: %w", err)
+	}
+
 	if prm.WithHeader {
+		defer release()
+
 		if prm.WithPayload {
-			res, err := x.pool.GetObject(ctx, prmGet)
-			if err != nil {
-				if reason, ok := isErrAccessDenied(err); ok {
-					return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
+			var res pool.ResGetObject
+			cancel, err := x.call(ctx, OperationGet, func(ctx context.Context) error {
+				var err error
+				if res, err = x.pool.GetObject(ctx, prmGet); err != nil {
+					return fmt.Errorf("init full object reading via connection pool: %w", err)
 				}
 
-				return nil, fmt.Errorf("init full object reading via connection pool: %w", err)
-			}
+				defer res.Payload.Close()
 
-			defer res.Payload.Close()
+				payload, err := io.ReadAll(res.Payload)
+				if err != nil {
+					return fmt.Errorf("read full object payload: %w", err)
+				}
 
-			payload, err := io.ReadAll(res.Payload)
+				res.Header.SetPayload(payload)
+				return nil
+			})
 			if err != nil {
-				return nil, fmt.Errorf("read full object payload: %w", err)
-			}
+				if reason, ok := isErrAccessDenied(err); ok {
+					return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
+				}
 
-			res.Header.SetPayload(payload)
+				return nil, err
+			}
+			cancel()
 
 			return &layer.ObjectPart{
 				Head: &res.Header,
@@ -355,7 +425,11 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 			prmHead.UseKey(prm.PrivateKey)
 		}
 
-		hdr, err := x.pool.HeadObject(ctx, prmHead)
+		var hdr object.Object
+		cancel, err := x.call(ctx, OperationHead, func(ctx context.Context) (err error) {
+			hdr, err = x.pool.HeadObject(ctx, prmHead)
+			return err
+		})
 		if err != nil {
 			if reason, ok := isErrAccessDenied(err); ok {
 				return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
@@ -363,13 +437,19 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 
 			return nil, fmt.Errorf("read object header via connection pool: %w", err)
 		}
+		cancel()
 
 		return &layer.ObjectPart{
 			Head: &hdr,
 		}, nil
 	} else if prm.PayloadRange[0]+prm.PayloadRange[1] == 0 {
-		res, err := x.pool.GetObject(ctx, prmGet)
+		var res pool.ResGetObject
+		cancel, err := x.call(ctx, OperationGet, func(ctx context.Context) (err error) {
+			res, err = x.pool.GetObject(ctx, prmGet)
+			return err
+		})
 		if err != nil {
+			release()
 			if reason, ok := isErrAccessDenied(err); ok {
 				return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
 			}
@@ -378,7 +458,7 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 		}
 
 		return &layer.ObjectPart{
-			Payload: res.Payload,
+			Payload: newLimitedPayload(ctx, res.Payload, func() { cancel(); release() }),
 		}, nil
 	}
 
@@ -393,8 +473,13 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 		prmRange.UseKey(prm.PrivateKey)
 	}
 
-	res, err := x.pool.ObjectRange(ctx, prmRange)
+	var res pool.ResObjectRange
+	cancel, err := x.call(ctx, OperationGet, func(ctx context.Context) (err error) {
+		res, err = x.pool.ObjectRange(ctx, prmRange)
+		return err
+	})
 	if err != nil {
+		release()
 		if reason, ok := isErrAccessDenied(err); ok {
 			return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
 		}
@@ -403,7 +488,7 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 	}
 
 	return &layer.ObjectPart{
-		Payload: payloadReader{&res},
+		Payload: newLimitedPayload(ctx, payloadReader{&res}, func() { cancel(); release() }),
 	}, nil
 }
 
@@ -422,7 +507,15 @@ func (x *NeoFS) DeleteObject(ctx context.Context, prm layer.PrmObjectDelete) err
 		prmDelete.UseKey(prm.PrivateKey)
 	}
 
-	err := x.pool.DeleteObject(ctx, prmDelete)
+	release, err := x.metadataLimiter.acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("wait for metadata operations limit: %w", err)
+	}
+	defer release()
+
+	cancel, err := x.call(ctx, OperationDelete, func(ctx context.Context) error {
+		return x.pool.DeleteObject(ctx, prmDelete)
+	})
 	if err != nil {
 		if reason, ok := isErrAccessDenied(err); ok {
 			return fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
@@ -430,6 +523,7 @@ func (x *NeoFS) DeleteObject(ctx context.Context, prm layer.PrmObjectDelete) err
 
 		return fmt.Errorf("mark object removal via connection pool: %w", err)
 	}
+	cancel()
 
 	return nil
 }
@@ -477,6 +571,61 @@ func (x *ResolverNeoFS) SystemDNS(ctx context.Context) (string, error) {
 	return string(domain), nil
 }
 
+// SearchObjects returns IDs of all root objects of the container.
+func (x *NeoFS) SearchObjects(ctx context.Context, cnrID cid.ID) ([]oid.ID, error) {
+	var filters object.SearchFilters
+	filters.AddRootFilter()
+
+	return x.searchObjects(ctx, cnrID, filters)
+}
+
+// SearchObjectsWithAttribute returns IDs of root objects of the container having the attribute with any value.
+func (x *NeoFS) SearchObjectsWithAttribute(ctx context.Context, cnrID cid.ID, attribute string) ([]oid.ID, error) {
+	var filters object.SearchFilters
+	filters.AddRootFilter()
+	filters.AddFilter(attribute, "", object.MatchCommonPrefix)
+
+	return x.searchObjects(ctx, cnrID, filters)
+}
+
+func (x *NeoFS) searchObjects(ctx context.Context, cnrID cid.ID, filters object.SearchFilters) ([]oid.ID, error) {
+	var prmSearch pool.PrmObjectSearch
+	prmSearch.SetContainerID(cnrID)
+	prmSearch.SetFilters(filters)
+
+	release, err := x.metadataLimiter.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("wait for metadata operations limit: %w", err)
+	}
+	defer release()
+
+	var ids []oid.ID
+	cancel, err := x.call(ctx, OperationSearch, func(ctx context.Context) error {
+		res, err := x.pool.SearchObjects(ctx, prmSearch)
+		if err != nil {
+			return fmt.Errorf("init object search via connection pool: %w", err)
+		}
+
+		defer res.Close()
+
+		ids = ids[:0]
+		err = res.Iterate(func(id oid.ID) bool {
+			ids = append(ids, id)
+			return false
+		})
+		if err != nil {
+			return fmt.Errorf("read object list: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	cancel()
+
+	return ids, nil
+}
+
 // AuthmateNeoFS is a mediator which implements authmate.NeoFS through pool.Pool.
 type AuthmateNeoFS struct {
 	neoFS *NeoFS
@@ -487,6 +636,11 @@ func NewAuthmateNeoFS(p *pool.Pool) *AuthmateNeoFS {
 	return &AuthmateNeoFS{neoFS: NewNeoFS(p)}
 }
 
+// AuthmateNeoFS returns AuthmateNeoFS which shares limits, timeouts and retry policy of x.
+func (x *NeoFS) AuthmateNeoFS() *AuthmateNeoFS {
+	return &AuthmateNeoFS{neoFS: x}
+}
+
 // ContainerExists implements authmate.NeoFS interface method.
 func (x *AuthmateNeoFS) ContainerExists(ctx context.Context, idCnr cid.ID) error {
 	_, err := x.neoFS.Container(ctx, idCnr)
@@ -507,6 +661,8 @@ func (x *AuthmateNeoFS) CreateContainer(ctx context.Context, prm authmate.PrmCon
 	basicACL := acl.Private
 	// allow reading objects to OTHERS in order to provide read access to S3 gateways
 	basicACL.AllowOp(acl.OpObjectGet, acl.RoleOthers)
+	// allow searching objects to OTHERS in order to find access boxes with rotated secrets
+	basicACL.AllowOp(acl.OpObjectSearch, acl.RoleOthers)
 
 	return x.neoFS.CreateContainer(ctx, layer.PrmContainerCreate{
 		Creator:  prm.Owner,
@@ -516,6 +672,16 @@ func (x *AuthmateNeoFS) CreateContainer(ctx context.Context, prm authmate.PrmCon
 	})
 }
 
+// ContainerOwner implements authmate.NeoFS interface method.
+func (x *AuthmateNeoFS) ContainerOwner(ctx context.Context, idCnr cid.ID) (user.ID, error) {
+	cnr, err := x.neoFS.Container(ctx, idCnr)
+	if err != nil {
+		return user.ID{}, fmt.Errorf("get container via connection pool: %w", err)
+	}
+
+	return cnr.Owner(), nil
+}
+
 // ReadObjectPayload implements authmate.NeoFS interface method.
 func (x *AuthmateNeoFS) ReadObjectPayload(ctx context.Context, addr oid.Address) ([]byte, error) {
 	res, err := x.neoFS.ReadObject(ctx, layer.PrmObjectRead{
@@ -538,12 +704,155 @@ func (x *AuthmateNeoFS) CreateObject(ctx context.Context, prm tokens.PrmObjectCr
 		Creator:   prm.Creator,
 		Container: prm.Container,
 		Filepath:  prm.Filepath,
-		Attributes: [][2]string{
-			{"__NEOFS__EXPIRATION_EPOCH", strconv.FormatUint(prm.ExpirationEpoch, 10)}},
+		Attributes: append([][2]string{
+			{"__NEOFS__EXPIRATION_EPOCH", strconv.FormatUint(prm.ExpirationEpoch, 10)}}, prm.Attributes...),
 		Payload: bytes.NewReader(prm.Payload),
 	})
 }
 
+// DeleteObject implements authmate.NeoFS interface method.
+func (x *AuthmateNeoFS) DeleteObject(ctx context.Context, addr oid.Address) error {
+	return x.neoFS.DeleteObject(ctx, layer.PrmObjectDelete{
+		Container: addr.Container(),
+		Object:    addr.Object(),
+	})
+}
+
+// SearchObjects implements authmate.NeoFS interface method.
+func (x *AuthmateNeoFS) SearchObjects(ctx context.Context, prm tokens.PrmObjectSearch) ([]oid.ID, error) {
+	var filters object.SearchFilters
+	filters.AddRootFilter()
+	if prm.Attribute != "" {
+		filters.AddFilter(prm.Attribute, prm.Value, object.MatchStringEqual)
+	}
+	if prm.Owner != nil {
+		filters.AddObjectOwnerIDFilter(object.MatchStringEqual, *prm.Owner)
+	}
+
+	ids, err := x.neoFS.searchObjects(ctx, prm.Container, filters)
+	if err != nil {
+		// auth containers created before secret rotation support don't allow
+		// others to search objects, so rotated access boxes can't be found there
+		if _, ok := isErrAccessDenied(err); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// BatchJobStorage is a mediator which implements handler.BatchJobStorage through pool.Pool.
+// States of jobs are kept in objects of the container created by the gateway.
+type BatchJobStorage struct {
+	neoFS     *NeoFS
+	container cid.ID
+	owner     user.ID
+}
+
+const (
+	attrBatchJobID    = "S3-Batch-Job-Id"
+	attrBatchJobOwner = "S3-Batch-Job-Owner"
+)
+
+// BatchJobStorage returns BatchJobStorage which stores jobs in the container on behalf of owner.
+func (x *NeoFS) BatchJobStorage(cnrID cid.ID, owner user.ID) *BatchJobStorage {
+	return &BatchJobStorage{neoFS: x, container: cnrID, owner: owner}
+}
+
+// PutJob implements handler.BatchJobStorage interface method.
+func (x *BatchJobStorage) PutJob(ctx context.Context, id string, owner user.ID, state []byte) error {
+	prev, err := x.search(ctx, attrBatchJobID, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = x.neoFS.CreateObject(ctx, layer.PrmObjectCreate{
+		Container: x.container,
+		Creator:   x.owner,
+		Attributes: [][2]string{
+			{attrBatchJobID, id},
+			{attrBatchJobOwner, owner.EncodeToString()},
+		},
+		PayloadSize: uint64(len(state)),
+		Payload:     bytes.NewReader(state),
+	})
+	if err != nil {
+		return fmt.Errorf("save job state: %w", err)
+	}
+
+	for _, objID := range prev {
+		if err = x.neoFS.DeleteObject(ctx, layer.PrmObjectDelete{
+			Container: x.container,
+			Object:    objID,
+		}); err != nil {
+			return fmt.Errorf("delete previous job state: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// JobStates implements handler.BatchJobStorage interface method.
+func (x *BatchJobStorage) JobStates(ctx context.Context, id string) ([][]byte, error) {
+	ids, err := x.search(ctx, attrBatchJobID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return x.read(ctx, ids)
+}
+
+// OwnerJobStates implements handler.BatchJobStorage interface method.
+func (x *BatchJobStorage) OwnerJobStates(ctx context.Context, owner user.ID) ([][]byte, error) {
+	ids, err := x.search(ctx, attrBatchJobOwner, owner.EncodeToString())
+	if err != nil {
+		return nil, err
+	}
+
+	return x.read(ctx, ids)
+}
+
+func (x *BatchJobStorage) search(ctx context.Context, attr, value string) ([]oid.ID, error) {
+	var filters object.SearchFilters
+	filters.AddRootFilter()
+	filters.AddFilter(attr, value, object.MatchStringEqual)
+
+	ids, err := x.neoFS.searchObjects(ctx, x.container, filters)
+	if err != nil {
+		return nil, fmt.Errorf("search job states: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (x *BatchJobStorage) read(ctx context.Context, ids []oid.ID) ([][]byte, error) {
+	states := make([][]byte, 0, len(ids))
+	for _, objID := range ids {
+		res, err := x.neoFS.ReadObject(ctx, layer.PrmObjectRead{
+			Container:   x.container,
+			Object:      objID,
+			WithPayload: true,
+		})
+		if err != nil {
+			// the state may be replaced concurrently
+			if client.IsErrObjectNotFound(err) || client.IsErrObjectAlreadyRemoved(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read job state: %w", err)
+		}
+
+		state, err := io.ReadAll(res.Payload)
+		_ = res.Payload.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read job state payload: %w", err)
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
 // PoolStatistic is a mediator which implements authmate.NeoFS through pool.Pool.
 type PoolStatistic struct {
 	pool *pool.Pool
@@ -0,0 +1,199 @@
+package neofs
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/internal/phases"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Operation classes which have separate timeouts.
+const (
+	OperationHead   = "head"
+	OperationSearch = "search"
+	OperationGet    = "get"
+	OperationPut    = "put"
+	OperationDelete = "delete"
+)
+
+type (
+	// OperationTimeouts are timeouts of a single attempt of object operations by operation class.
+	// Timeouts of get and put operations include transfer of the payload. Zero value means no timeout.
+	OperationTimeouts struct {
+		Head   time.Duration
+		Search time.Duration
+		Get    time.Duration
+		Put    time.Duration
+		Delete time.Duration
+	}
+
+	// RetryPolicy defines retries of object operations failed with transient errors.
+	// Delays between attempts grow exponentially from InitialBackoff up to MaxBackoff
+	// with full jitter. Put operations aren't retried because payload can't be re-read.
+	RetryPolicy struct {
+		// MaxAttempts is a maximum number of attempts including the first one.
+		// Values less than 2 disable retries.
+		MaxAttempts    int
+		InitialBackoff time.Duration
+		MaxBackoff     time.Duration
+	}
+
+	// retryStatistic counts operations which failed after all retry attempts by operation class.
+	retryStatistic struct {
+		head   uint64
+		search uint64
+		get    uint64
+		delete uint64
+	}
+)
+
+func (s *retryStatistic) counter(class string) *uint64 {
+	switch class {
+	case OperationHead:
+		return &s.head
+	case OperationSearch:
+		return &s.search
+	case OperationGet:
+		return &s.get
+	case OperationDelete:
+		return &s.delete
+	default:
+		return nil
+	}
+}
+
+func (t OperationTimeouts) timeout(class string) time.Duration {
+	switch class {
+	case OperationHead:
+		return t.Head
+	case OperationSearch:
+		return t.Search
+	case OperationGet:
+		return t.Get
+	case OperationPut:
+		return t.Put
+	case OperationDelete:
+		return t.Delete
+	default:
+		return 0
+	}
+}
+
+// backoff returns a random delay before the next attempt after the attempt with the given number failed.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	limit := p.InitialBackoff
+	for i := 1; i < attempt && limit < p.MaxBackoff; i++ {
+		limit *= 2
+	}
+	if p.MaxBackoff > 0 && limit > p.MaxBackoff {
+		limit = p.MaxBackoff
+	}
+	if limit <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(limit)) + 1)
+}
+
+// SetTimeouts sets timeouts of object operations. It must be called before NeoFS is used.
+func (x *NeoFS) SetTimeouts(timeouts OperationTimeouts) {
+	x.timeouts = timeouts
+}
+
+// SetRetryPolicy sets retries of object operations. It must be called before NeoFS is used.
+func (x *NeoFS) SetRetryPolicy(policy RetryPolicy) {
+	x.retry = policy
+}
+
+// RetriesExhausted returns the number of object operations which failed with
+// transient errors after all retry attempts by operation class.
+func (x *NeoFS) RetriesExhausted() map[string]uint64 {
+	res := make(map[string]uint64, 4)
+	for _, class := range []string{OperationHead, OperationSearch, OperationGet, OperationDelete} {
+		res[class] = atomic.LoadUint64(x.retryStat.counter(class))
+	}
+	return res
+}
+
+// call runs op with the timeout of the operation class and retries it on transient errors.
+// On success the returned function must be called when the result of op is no longer used,
+// it releases the context passed to op and ends the span of the operation.
+func (x *NeoFS) call(ctx context.Context, class string, op func(context.Context) error) (context.CancelFunc, error) {
+	defer phases.Observe(ctx, phases.Storage, time.Now())
+
+	ctx, span := tracing.StartSpan(ctx, "neofs."+class)
+	ctx = tracing.InjectGRPC(ctx)
+
+	for attempt := 1; ; attempt++ {
+		span.SetAttributes(attribute.Int("attempts", attempt))
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout := x.timeouts.timeout(class); timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+
+		err := op(attemptCtx)
+		if err == nil {
+			return func() {
+				cancel()
+				span.End()
+			}, nil
+		}
+		cancel()
+
+		counter := x.retryStat.counter(class)
+		if counter == nil || x.retry.MaxAttempts < 2 || !isTransientError(ctx, err) {
+			tracing.EndSpan(span, err)
+			return nil, err
+		}
+
+		if attempt >= x.retry.MaxAttempts {
+			atomic.AddUint64(counter, 1)
+			tracing.EndSpan(span, err)
+			return nil, err
+		}
+
+		timer := time.NewTimer(x.retry.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			tracing.EndSpan(span, err)
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// isTransientError checks if the operation failed with err can succeed on retry.
+// Connection errors, timeouts of the attempt and internal errors of storage nodes are transient.
+func isTransientError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	unwrappedErr := errors.Unwrap(err)
+	for unwrappedErr != nil {
+		err = unwrappedErr
+		unwrappedErr = errors.Unwrap(err)
+	}
+
+	switch err.(type) {
+	case apistatus.ServerInternal, *apistatus.ServerInternal,
+		apistatus.NodeUnderMaintenance, *apistatus.NodeUnderMaintenance:
+		return true
+	case apistatus.StatusV2:
+		return false
+	default:
+		return !errors.Is(err, context.Canceled)
+	}
+}
@@ -0,0 +1,107 @@
+package neofs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	errTransient := errors.New("connection refused")
+
+	newNeoFS := func() *NeoFS {
+		x := &NeoFS{}
+		x.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+		return x
+	}
+
+	t.Run("transient error", func(t *testing.T) {
+		x := newNeoFS()
+
+		var attempts int
+		cancel, err := x.call(ctx, OperationHead, func(context.Context) error {
+			if attempts++; attempts < 3 {
+				return errTransient
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		cancel()
+		require.Equal(t, 3, attempts)
+		require.Zero(t, x.RetriesExhausted()[OperationHead])
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		x := newNeoFS()
+
+		var attempts int
+		_, err := x.call(ctx, OperationGet, func(context.Context) error {
+			attempts++
+			return fmt.Errorf("init: %w", errTransient)
+		})
+		require.ErrorIs(t, err, errTransient)
+		require.Equal(t, 3, attempts)
+		require.Equal(t, uint64(1), x.RetriesExhausted()[OperationGet])
+	})
+
+	t.Run("permanent error", func(t *testing.T) {
+		x := newNeoFS()
+
+		var attempts int
+		_, err := x.call(ctx, OperationHead, func(context.Context) error {
+			attempts++
+			return fmt.Errorf("head: %w", apistatus.ObjectNotFound{})
+		})
+		require.Error(t, err)
+		require.Equal(t, 1, attempts)
+		require.Zero(t, x.RetriesExhausted()[OperationHead])
+	})
+
+	t.Run("put isn't retried", func(t *testing.T) {
+		x := newNeoFS()
+
+		var attempts int
+		_, err := x.call(ctx, OperationPut, func(context.Context) error {
+			attempts++
+			return errTransient
+		})
+		require.ErrorIs(t, err, errTransient)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("attempt timeout", func(t *testing.T) {
+		x := newNeoFS()
+		x.SetTimeouts(OperationTimeouts{Delete: 10 * time.Millisecond})
+
+		var attempts int
+		cancel, err := x.call(ctx, OperationDelete, func(ctx context.Context) error {
+			if attempts++; attempts == 1 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		cancel()
+		require.Equal(t, 2, attempts)
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	for i := 0; i < 100; i++ {
+		require.LessOrEqual(t, policy.backoff(1), 100*time.Millisecond)
+		require.LessOrEqual(t, policy.backoff(2), 200*time.Millisecond)
+		require.LessOrEqual(t, policy.backoff(10), 300*time.Millisecond)
+		require.Positive(t, policy.backoff(1))
+	}
+
+	require.Zero(t, RetryPolicy{}.backoff(1))
+}
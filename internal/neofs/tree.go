@@ -2,11 +2,13 @@ package neofs
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
@@ -19,23 +21,34 @@ import (
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type (
 	TreeClient struct {
-		key     *keys.PrivateKey
-		conn    *grpc.ClientConn
-		service tree.TreeServiceClient
+		key       *keys.PrivateKey
+		endpoints []*treeEndpoint
+		retry     RetryPolicy
+		clock     monotonicClock
+		// limiter is shared with NeoFS object metadata operations, see NeoFS.LimitTreeClient.
+		limiter operationsLimiter
 	}
 
 	TreeNode struct {
-		ID        uint64
-		ParentID  uint64
-		ObjID     oid.ID
-		TimeStamp uint64
-		Size      int64
-		Meta      map[string]string
+		ID            uint64
+		ParentID      uint64
+		ObjID         oid.ID
+		TimeStamp     uint64
+		MonotonicTime uint64
+		Size          int64
+		Meta          map[string]string
+	}
+
+	// monotonicClock returns the current unix time in nanoseconds which is greater than
+	// any previously returned one, so versions added in a row are ordered even if they get
+	// the same timestamp of the tree service.
+	monotonicClock struct {
+		mu   sync.Mutex
+		last uint64
 	}
 
 	getNodesParams struct {
@@ -51,6 +64,7 @@ type (
 const (
 	versioningKV        = "Versioning"
 	lockConfigurationKV = "LockConfiguration"
+	mfaDeleteKV         = "MFADelete"
 	oidKV               = "OID"
 	fileNameKV          = "FileName"
 	isUnversionedKV     = "IsUnversioned"
@@ -59,6 +73,7 @@ const (
 	partNumberKV        = "Number"
 	sizeKV              = "Size"
 	etagKV              = "ETag"
+	monotonicTimeKV     = "MonotonicTime"
 
 	// keys for lock.
 	isLockKV       = "IsLock"
@@ -67,21 +82,25 @@ const (
 	untilDateKV    = "UntilDate"
 	isComplianceKV = "IsCompliance"
 
-	// keys for delete marker nodes.
+	// keys for delete marker nodes, owner and creation time are also kept for object versions.
 	isDeleteMarkerKV = "IsDeleteMarker"
 	ownerKV          = "Owner"
 	createdKV        = "Created"
+	storageClassKV   = "StorageClass"
 
 	settingsFileName      = "bucket-settings"
 	notifConfFileName     = "bucket-notifications"
 	corsFilename          = "bucket-cors"
 	bucketTaggingFilename = "bucket-tagging"
+	// bucketConfigPrefix is a prefix of file names of nodes with lifecycle, website, policy and encryption configurations.
+	bucketConfigPrefix = "bucket-"
 
 	// versionTree -- ID of a tree with object versions.
 	versionTree = "version"
 
 	// systemTree -- ID of a tree with system objects
-	// i.e. bucket settings with versioning and lock configuration, cors, notifications.
+	// i.e. bucket settings with versioning and lock configuration, cors, notifications
+	// and other bucket configurations.
 	systemTree = "system"
 
 	separator            = "/"
@@ -90,23 +109,35 @@ const (
 	maxGetSubTreeDepth = 0 // means all subTree
 )
 
-// NewTreeClient creates instance of TreeClient using provided address and create grpc connection.
-func NewTreeClient(ctx context.Context, addr string, key *keys.PrivateKey) (*TreeClient, error) {
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return nil, fmt.Errorf("did not connect: %v", err)
+// NewTreeClient creates instance of TreeClient using provided addresses and create grpc connections.
+// Requests are sent to the endpoints in the given order, failing over to the next one while the
+// endpoint is unavailable. At least one endpoint must pass the healthcheck.
+// Connections are established over TLS if tlsCfg isn't nil.
+func NewTreeClient(ctx context.Context, addrs []string, key *keys.PrivateKey, tlsCfg *tls.Config) (*TreeClient, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("no tree service endpoints")
+	}
+
+	c := &TreeClient{
+		key:       key,
+		endpoints: make([]*treeEndpoint, 0, len(addrs)),
 	}
 
-	c := tree.NewTreeServiceClient(conn)
-	if _, err = c.Healthcheck(ctx, &tree.HealthcheckRequest{}); err != nil {
-		return nil, fmt.Errorf("healthcheck: %w", err)
+	for _, addr := range addrs {
+		e, err := dialTreeEndpoint(addr, tlsCfg)
+		if err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+		c.endpoints = append(c.endpoints, e)
 	}
 
-	return &TreeClient{
-		key:     key,
-		conn:    conn,
-		service: c,
-	}, nil
+	if err := c.Healthcheck(ctx); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+
+	return c, nil
 }
 
 type NodeResponse interface {
@@ -137,6 +168,8 @@ func newTreeNode(nodeInfo NodeResponse) (*TreeNode, error) {
 					return nil, fmt.Errorf("invalid size value '%s': %w", sizeStr, err)
 				}
 			}
+		case monotonicTimeKV:
+			treeNode.MonotonicTime = parseMonotonicTime(kv.GetValue())
 		default:
 			treeNode.Meta[kv.GetKey()] = string(kv.GetValue())
 		}
@@ -171,38 +204,91 @@ func newNodeVersionFromTreeNode(filePath string, treeNode *TreeNode) *data.NodeV
 
 	version := &data.NodeVersion{
 		BaseNodeVersion: data.BaseNodeVersion{
-			ID:        treeNode.ID,
-			ParenID:   treeNode.ParentID,
-			OID:       treeNode.ObjID,
-			Timestamp: treeNode.TimeStamp,
-			ETag:      eTag,
-			Size:      treeNode.Size,
-			FilePath:  filePath,
+			ID:            treeNode.ID,
+			ParenID:       treeNode.ParentID,
+			OID:           treeNode.ObjID,
+			Timestamp:     treeNode.TimeStamp,
+			MonotonicTime: treeNode.MonotonicTime,
+			ETag:          eTag,
+			Size:          treeNode.Size,
+			FilePath:      filePath,
 		},
 		IsUnversioned: isUnversioned,
 	}
 
-	if isDeleteMarker {
-		var created time.Time
-		if createdStr, ok := treeNode.Get(createdKV); ok {
-			if utcMilli, err := strconv.ParseInt(createdStr, 10, 64); err == nil {
-				created = time.UnixMilli(utcMilli)
-			}
-		}
+	createdStr, hasCreated := treeNode.Get(createdKV)
+	ownerStr, hasOwner := treeNode.Get(ownerKV)
 
-		var owner user.ID
-		if ownerStr, ok := treeNode.Get(ownerKV); ok {
-			_ = owner.DecodeString(ownerStr)
+	var created time.Time
+	if hasCreated {
+		if utcMilli, err := strconv.ParseInt(createdStr, 10, 64); err == nil {
+			created = time.UnixMilli(utcMilli)
+		} else {
+			hasCreated = false
 		}
+	}
+
+	var owner user.ID
+	if hasOwner {
+		hasOwner = owner.DecodeString(ownerStr) == nil
+	}
 
+	if isDeleteMarker {
 		version.DeleteMarker = &data.DeleteMarkerInfo{
 			Created: created,
 			Owner:   owner,
 		}
+	} else if hasCreated && hasOwner {
+		storageClass, _ := treeNode.Get(storageClassKV)
+		version.ObjectMeta = &data.ObjectMeta{
+			Created:      created,
+			Owner:        owner,
+			StorageClass: storageClass,
+		}
+	}
+
+	return version
+}
+
+// parseMonotonicTime returns zero for the invalid value, so such versions are ordered as added by old gateways.
+func parseMonotonicTime(value []byte) uint64 {
+	res, _ := strconv.ParseUint(string(value), 10, 64)
+	return res
+}
+
+// isNewerNode reports whether the version node is newer than the other one, see data.BaseNodeVersion.IsNewerThan.
+func isNewerNode(node, other NodeResponse) bool {
+	return nodeOrder(node).IsNewerThan(nodeOrder(other))
+}
+
+func nodeOrder(node NodeResponse) data.BaseNodeVersion {
+	version := data.BaseNodeVersion{
+		ID:        node.GetNodeId(),
+		Timestamp: node.GetTimestamp(),
+	}
+
+	for _, kv := range node.GetMeta() {
+		if kv.GetKey() == monotonicTimeKV {
+			version.MonotonicTime = parseMonotonicTime(kv.GetValue())
+		}
 	}
+
 	return version
 }
 
+func (c *monotonicClock) now() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := uint64(time.Now().UnixNano())
+	if now <= c.last {
+		now = c.last + 1
+	}
+	c.last = now
+
+	return now
+}
+
 func newMultipartInfo(node NodeResponse) (*data.MultipartInfo, error) {
 	multipartInfo := &data.MultipartInfo{
 		ID:   node.GetNodeId(),
@@ -271,7 +357,7 @@ func newPartInfo(node NodeResponse) (*data.PartInfo, error) {
 }
 
 func (c *TreeClient) GetSettingsNode(ctx context.Context, bktInfo *data.BucketInfo) (*data.BucketSettings, error) {
-	keysToReturn := []string{versioningKV, lockConfigurationKV}
+	keysToReturn := []string{versioningKV, lockConfigurationKV, mfaDeleteKV}
 	node, err := c.getSystemNode(ctx, bktInfo, []string{settingsFileName}, keysToReturn)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't get node: %w", err)
@@ -288,6 +374,11 @@ func (c *TreeClient) GetSettingsNode(ctx context.Context, bktInfo *data.BucketIn
 		}
 	}
 
+	if mfaDeleteValue, ok := node.Get(mfaDeleteKV); ok {
+		settings.MFADelete = mfaDeleteValue == "true"
+		settings.MFADeleteConfigured = settings.MFADelete || mfaDeleteValue == data.MFADeleteDisabled
+	}
+
 	return settings, nil
 }
 
@@ -309,37 +400,44 @@ func (c *TreeClient) PutSettingsNode(ctx context.Context, bktInfo *data.BucketIn
 }
 
 func (c *TreeClient) GetNotificationConfigurationNode(ctx context.Context, bktInfo *data.BucketInfo) (oid.ID, error) {
-	node, err := c.getSystemNode(ctx, bktInfo, []string{notifConfFileName}, []string{oidKV})
-	if err != nil {
-		return oid.ID{}, err
-	}
-
-	return node.ObjID, nil
+	return c.getSystemObjectNode(ctx, bktInfo, notifConfFileName)
 }
 
 func (c *TreeClient) PutNotificationConfigurationNode(ctx context.Context, bktInfo *data.BucketInfo, objID oid.ID) (oid.ID, error) {
-	node, err := c.getSystemNode(ctx, bktInfo, []string{notifConfFileName}, []string{oidKV})
-	isErrNotFound := errors.Is(err, layer.ErrNodeNotFound)
-	if err != nil && !isErrNotFound {
-		return oid.ID{}, fmt.Errorf("couldn't get node: %w", err)
-	}
+	return c.putSystemObjectNode(ctx, bktInfo, notifConfFileName, objID)
+}
 
-	meta := make(map[string]string)
-	meta[fileNameKV] = notifConfFileName
-	meta[oidKV] = objID.EncodeToString()
+func (c *TreeClient) GetBucketCORS(ctx context.Context, bktInfo *data.BucketInfo) (oid.ID, error) {
+	return c.getSystemObjectNode(ctx, bktInfo, corsFilename)
+}
 
-	if isErrNotFound {
-		if _, err = c.addNode(ctx, bktInfo, systemTree, 0, meta); err != nil {
-			return oid.ID{}, err
-		}
-		return oid.ID{}, layer.ErrNoNodeToRemove
-	}
+func (c *TreeClient) PutBucketCORS(ctx context.Context, bktInfo *data.BucketInfo, objID oid.ID) (oid.ID, error) {
+	return c.putSystemObjectNode(ctx, bktInfo, corsFilename, objID)
+}
 
-	return node.ObjID, c.moveNode(ctx, bktInfo, systemTree, node.ID, 0, meta)
+func (c *TreeClient) DeleteBucketCORS(ctx context.Context, bktInfo *data.BucketInfo) (oid.ID, error) {
+	return c.deleteSystemObjectNode(ctx, bktInfo, corsFilename)
 }
 
-func (c *TreeClient) GetBucketCORS(ctx context.Context, bktInfo *data.BucketInfo) (oid.ID, error) {
-	node, err := c.getSystemNode(ctx, bktInfo, []string{corsFilename}, []string{oidKV})
+func (c *TreeClient) GetBucketConfig(ctx context.Context, bktInfo *data.BucketInfo, cfgType data.BucketConfigType) (oid.ID, error) {
+	return c.getSystemObjectNode(ctx, bktInfo, configFileName(cfgType))
+}
+
+func (c *TreeClient) PutBucketConfig(ctx context.Context, bktInfo *data.BucketInfo, cfgType data.BucketConfigType, objID oid.ID) (oid.ID, error) {
+	return c.putSystemObjectNode(ctx, bktInfo, configFileName(cfgType), objID)
+}
+
+func (c *TreeClient) DeleteBucketConfig(ctx context.Context, bktInfo *data.BucketInfo, cfgType data.BucketConfigType) (oid.ID, error) {
+	return c.deleteSystemObjectNode(ctx, bktInfo, configFileName(cfgType))
+}
+
+func configFileName(cfgType data.BucketConfigType) string {
+	return bucketConfigPrefix + string(cfgType)
+}
+
+// getSystemObjectNode returns ID of the object kept in the system node with the file name.
+func (c *TreeClient) getSystemObjectNode(ctx context.Context, bktInfo *data.BucketInfo, fileName string) (oid.ID, error) {
+	node, err := c.getSystemNode(ctx, bktInfo, []string{fileName}, []string{oidKV})
 	if err != nil {
 		return oid.ID{}, err
 	}
@@ -347,15 +445,17 @@ func (c *TreeClient) GetBucketCORS(ctx context.Context, bktInfo *data.BucketInfo
 	return node.ObjID, nil
 }
 
-func (c *TreeClient) PutBucketCORS(ctx context.Context, bktInfo *data.BucketInfo, objID oid.ID) (oid.ID, error) {
-	node, err := c.getSystemNode(ctx, bktInfo, []string{corsFilename}, []string{oidKV})
+// putSystemObjectNode creates or updates the system node with the file name to keep ID of the object,
+// ID of the object kept before is returned.
+func (c *TreeClient) putSystemObjectNode(ctx context.Context, bktInfo *data.BucketInfo, fileName string, objID oid.ID) (oid.ID, error) {
+	node, err := c.getSystemNode(ctx, bktInfo, []string{fileName}, []string{oidKV})
 	isErrNotFound := errors.Is(err, layer.ErrNodeNotFound)
 	if err != nil && !isErrNotFound {
 		return oid.ID{}, fmt.Errorf("couldn't get node: %w", err)
 	}
 
 	meta := make(map[string]string)
-	meta[fileNameKV] = corsFilename
+	meta[fileNameKV] = fileName
 	meta[oidKV] = objID.EncodeToString()
 
 	if isErrNotFound {
@@ -368,8 +468,9 @@ func (c *TreeClient) PutBucketCORS(ctx context.Context, bktInfo *data.BucketInfo
 	return node.ObjID, c.moveNode(ctx, bktInfo, systemTree, node.ID, 0, meta)
 }
 
-func (c *TreeClient) DeleteBucketCORS(ctx context.Context, bktInfo *data.BucketInfo) (oid.ID, error) {
-	node, err := c.getSystemNode(ctx, bktInfo, []string{corsFilename}, []string{oidKV})
+// deleteSystemObjectNode removes the system node with the file name and returns ID of the object kept in it.
+func (c *TreeClient) deleteSystemObjectNode(ctx context.Context, bktInfo *data.BucketInfo, fileName string) (oid.ID, error) {
+	node, err := c.getSystemNode(ctx, bktInfo, []string{fileName}, []string{oidKV})
 	if err != nil && !errors.Is(err, layer.ErrNodeNotFound) {
 		return oid.ID{}, err
 	}
@@ -536,15 +637,17 @@ func (c *TreeClient) GetVersions(ctx context.Context, bktInfo *data.BucketInfo,
 }
 
 func (c *TreeClient) GetLatestVersion(ctx context.Context, bktInfo *data.BucketInfo, objectName string) (*data.NodeVersion, error) {
-	meta := []string{oidKV, isUnversionedKV, isDeleteMarkerKV, etagKV, sizeKV}
+	meta := []string{oidKV, isUnversionedKV, isDeleteMarkerKV, etagKV, sizeKV, monotonicTimeKV}
 	path := pathFromName(objectName)
 
+	// the latest version is chosen here rather than by the tree service
+	// to resolve versions with the same timestamp the same way as listings do
 	p := &getNodesParams{
 		BktInfo:    bktInfo,
 		TreeID:     versionTree,
 		Path:       path,
 		Meta:       meta,
-		LatestOnly: true,
+		LatestOnly: false,
 		AllAttrs:   false,
 	}
 	nodes, err := c.getNodes(ctx, p)
@@ -552,11 +655,30 @@ func (c *TreeClient) GetLatestVersion(ctx context.Context, bktInfo *data.BucketI
 		return nil, err
 	}
 
-	if len(nodes) == 0 {
+	var latest *tree.GetNodeByPathResponse_Info
+	for _, node := range nodes {
+		if !hasMeta(node, oidKV) { // intermediate node with the same name
+			continue
+		}
+		if latest == nil || isNewerNode(node, latest) {
+			latest = node
+		}
+	}
+
+	if latest == nil {
 		return nil, layer.ErrNodeNotFound
 	}
 
-	return newNodeVersion(objectName, nodes[0])
+	return newNodeVersion(objectName, latest)
+}
+
+func hasMeta(node NodeResponse, key string) bool {
+	for _, kv := range node.GetMeta() {
+		if kv.GetKey() == key {
+			return true
+		}
+	}
+	return false
 }
 
 // pathFromName splits name by '/'.
@@ -653,7 +775,7 @@ func (c *TreeClient) getSubTreeByPrefix(ctx context.Context, bktInfo *data.Bucke
 			nodes = append(nodes, node)
 		} else if isIntermediate(nodes[0]) {
 			nodes = append([]*tree.GetSubTreeResponse_Body{node}, nodes...)
-		} else if node.GetTimestamp() > nodes[0].GetTimestamp() {
+		} else if isNewerNode(node, nodes[0]) {
 			nodes[0] = node
 		}
 
@@ -722,13 +844,14 @@ func (c *TreeClient) getSubTreeVersions(ctx context.Context, bktInfo *data.Bucke
 		}
 
 		key := formLatestNodeKey(node.GetParentId(), fileName)
+		nodeVersion := newNodeVersionFromTreeNode(filepath, treeNode)
 		versionNodes, ok := versions[key]
 		if !ok {
-			versionNodes = []*data.NodeVersion{newNodeVersionFromTreeNode(filepath, treeNode)}
+			versionNodes = []*data.NodeVersion{nodeVersion}
 		} else if !latestOnly {
-			versionNodes = append(versionNodes, newNodeVersionFromTreeNode(filepath, treeNode))
-		} else if versionNodes[0].Timestamp <= treeNode.TimeStamp {
-			versionNodes[0] = newNodeVersionFromTreeNode(filepath, treeNode)
+			versionNodes = append(versionNodes, nodeVersion)
+		} else if nodeVersion.IsNewerThan(versionNodes[0].BaseNodeVersion) {
+			versionNodes[0] = nodeVersion
 		}
 
 		versions[key] = versionNodes
@@ -927,7 +1050,7 @@ func (c *TreeClient) AddPart(ctx context.Context, bktInfo *data.BucketInfo, mult
 		}
 	}
 
-	if foundPartID != multipartNodeID {
+	if foundPartID == 0 {
 		if _, err = c.addNode(ctx, bktInfo, systemTree, multipartNodeID, meta); err != nil {
 			return oid.ID{}, err
 		}
@@ -1034,19 +1157,36 @@ func (c *TreeClient) GetObjectTaggingAndLock(ctx context.Context, bktInfo *data.
 	return getObjectTagging(nodes[isTagKV]), lockInfo, nil
 }
 
+// Healthcheck checks that the tree service is reachable via at least one endpoint.
+func (c *TreeClient) Healthcheck(ctx context.Context) error {
+	errs := make([]error, 0, len(c.endpoints))
+	for _, st := range c.CheckEndpoints(ctx) {
+		if st.Err == nil {
+			return nil
+		}
+		errs = append(errs, st.Err)
+	}
+
+	return joinEndpointErrors(errs)
+}
+
 func (c *TreeClient) Close() error {
-	if c.conn != nil {
-		return c.conn.Close()
+	var err error
+	for _, e := range c.endpoints {
+		if errClose := e.conn.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
 	}
 
-	return nil
+	return err
 }
 
 func (c *TreeClient) addVersion(ctx context.Context, bktInfo *data.BucketInfo, treeID string, version *data.NodeVersion) (uint64, error) {
 	path := pathFromName(version.FilePath)
 	meta := map[string]string{
-		oidKV:      version.OID.EncodeToString(),
-		fileNameKV: path[len(path)-1],
+		oidKV:           version.OID.EncodeToString(),
+		fileNameKV:      path[len(path)-1],
+		monotonicTimeKV: strconv.FormatUint(c.clock.now(), 10),
 	}
 
 	if version.Size > 0 {
@@ -1060,6 +1200,12 @@ func (c *TreeClient) addVersion(ctx context.Context, bktInfo *data.BucketInfo, t
 		meta[isDeleteMarkerKV] = "true"
 		meta[ownerKV] = version.DeleteMarker.Owner.EncodeToString()
 		meta[createdKV] = strconv.FormatInt(version.DeleteMarker.Created.UTC().UnixMilli(), 10)
+	} else if version.ObjectMeta != nil {
+		meta[ownerKV] = version.ObjectMeta.Owner.EncodeToString()
+		meta[createdKV] = strconv.FormatInt(version.ObjectMeta.Created.UTC().UnixMilli(), 10)
+		if len(version.ObjectMeta.StorageClass) > 0 {
+			meta[storageClassKV] = version.ObjectMeta.StorageClass
+		}
 	}
 
 	if version.IsUnversioned {
@@ -1095,7 +1241,7 @@ func (c *TreeClient) clearOutdatedVersionInfo(ctx context.Context, bktInfo *data
 }
 
 func (c *TreeClient) getVersions(ctx context.Context, bktInfo *data.BucketInfo, treeID, filepath string, onlyUnversioned bool) ([]*data.NodeVersion, error) {
-	keysToReturn := []string{oidKV, isUnversionedKV, isDeleteMarkerKV, etagKV, sizeKV}
+	keysToReturn := []string{oidKV, isUnversionedKV, isDeleteMarkerKV, etagKV, sizeKV, monotonicTimeKV}
 	path := pathFromName(filepath)
 	p := &getNodesParams{
 		BktInfo:    bktInfo,
@@ -1150,31 +1296,43 @@ func (c *TreeClient) getSubTree(ctx context.Context, bktInfo *data.BucketInfo, t
 		return nil, err
 	}
 
-	cli, err := c.service.GetSubTree(ctx, request)
-	if err != nil {
-		return nil, handleError("failed to get sub tree client", err)
-	}
-
 	var subtree []*tree.GetSubTreeResponse_Body
-	for {
-		resp, err := cli.Recv()
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return nil, handleError("failed to get sub tree", err)
+	err := c.call(ctx, func(service tree.TreeServiceClient) error {
+		cli, err := service.GetSubTree(ctx, request)
+		if err != nil {
+			return err
 		}
-		subtree = append(subtree, resp.Body)
+
+		// the stream is read from the start if the endpoint becomes unavailable in the middle
+		subtree = subtree[:0]
+		for {
+			resp, err := cli.Recv()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			subtree = append(subtree, resp.Body)
+		}
+	})
+	if err != nil {
+		return nil, handleError("failed to get sub tree", err)
 	}
 
 	return subtree, nil
 }
 
 func metaFromSettings(settings *data.BucketSettings) map[string]string {
-	results := make(map[string]string, 3)
+	results := make(map[string]string, 4)
 
 	results[fileNameKV] = settingsFileName
 	results[versioningKV] = settings.Versioning
 	results[lockConfigurationKV] = encodeLockConfiguration(settings.LockConfiguration)
+	results[mfaDeleteKV] = strconv.FormatBool(settings.MFADelete)
+	if settings.MFADeleteConfigured && !settings.MFADelete {
+		// "false" is kept for buckets where MFA delete was never configured
+		results[mfaDeleteKV] = data.MFADeleteDisabled
+	}
 
 	return results
 }
@@ -1242,7 +1400,11 @@ func (c *TreeClient) getNodes(ctx context.Context, p *getNodesParams) ([]*tree.G
 		return nil, err
 	}
 
-	resp, err := c.service.GetNodeByPath(ctx, request)
+	var resp *tree.GetNodeByPathResponse
+	err := c.call(ctx, func(service tree.TreeServiceClient) (err error) {
+		resp, err = service.GetNodeByPath(ctx, request)
+		return err
+	})
 	if err != nil {
 		return nil, handleError("failed to get node by path", err)
 	}
@@ -1289,7 +1451,11 @@ func (c *TreeClient) addNode(ctx context.Context, bktInfo *data.BucketInfo, tree
 		return 0, err
 	}
 
-	resp, err := c.service.Add(ctx, request)
+	var resp *tree.AddResponse
+	err := c.callWrite(ctx, func(service tree.TreeServiceClient, opts ...grpc.CallOption) (err error) {
+		resp, err = service.Add(ctx, request, opts...)
+		return err
+	})
 	if err != nil {
 		return 0, handleError("failed to add node", err)
 	}
@@ -1318,7 +1484,11 @@ func (c *TreeClient) addNodeByPath(ctx context.Context, bktInfo *data.BucketInfo
 		return 0, err
 	}
 
-	resp, err := c.service.AddByPath(ctx, request)
+	var resp *tree.AddByPathResponse
+	err := c.callWrite(ctx, func(service tree.TreeServiceClient, opts ...grpc.CallOption) (err error) {
+		resp, err = service.AddByPath(ctx, request, opts...)
+		return err
+	})
 	if err != nil {
 		return 0, handleError("failed to add node by path", err)
 	}
@@ -1355,7 +1525,10 @@ func (c *TreeClient) moveNode(ctx context.Context, bktInfo *data.BucketInfo, tre
 		return err
 	}
 
-	if _, err := c.service.Move(ctx, request); err != nil {
+	if err := c.callWrite(ctx, func(service tree.TreeServiceClient, opts ...grpc.CallOption) error {
+		_, err := service.Move(ctx, request, opts...)
+		return err
+	}); err != nil {
 		return handleError("failed to move node", err)
 	}
 
@@ -1380,7 +1553,10 @@ func (c *TreeClient) removeNode(ctx context.Context, bktInfo *data.BucketInfo, t
 		return err
 	}
 
-	if _, err := c.service.Remove(ctx, request); err != nil {
+	if err := c.callWrite(ctx, func(service tree.TreeServiceClient, opts ...grpc.CallOption) error {
+		_, err := service.Remove(ctx, request, opts...)
+		return err
+	}); err != nil {
 		return handleError("failed to remove node", err)
 	}
 
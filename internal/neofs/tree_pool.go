@@ -0,0 +1,180 @@
+package neofs
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs/services/tree"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/phases"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// treeEndpoint is a connection to a single tree service endpoint.
+type treeEndpoint struct {
+	address string
+	conn    *grpc.ClientConn
+	service tree.TreeServiceClient
+	// unhealthy is set if the last request or healthcheck failed because the endpoint is unavailable.
+	unhealthy uint32
+}
+
+// TreeEndpointStatus is a result of the healthcheck of a tree service endpoint.
+type TreeEndpointStatus struct {
+	Address string
+	Err     error
+}
+
+func (e *treeEndpoint) healthy() bool {
+	return atomic.LoadUint32(&e.unhealthy) == 0
+}
+
+func (e *treeEndpoint) setHealthy(healthy bool) {
+	var unhealthy uint32
+	if !healthy {
+		unhealthy = 1
+	}
+	atomic.StoreUint32(&e.unhealthy, unhealthy)
+}
+
+func (e *treeEndpoint) healthcheck(ctx context.Context) error {
+	_, err := e.service.Healthcheck(ctx, &tree.HealthcheckRequest{})
+	e.setHealthy(err == nil)
+	if err != nil {
+		return fmt.Errorf("healthcheck %s: %w", e.address, err)
+	}
+	return nil
+}
+
+func dialTreeEndpoint(addr string, tlsCfg *tls.Config) (*treeEndpoint, error) {
+	creds := insecure.NewCredentials()
+	if tlsCfg != nil {
+		creds = credentials.NewTLS(tlsCfg)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor(), phases.UnaryClientInterceptor(phases.Tree)),
+		grpc.WithChainStreamInterceptor(tracing.StreamClientInterceptor(), phases.StreamClientInterceptor(phases.Tree)))
+	if err != nil {
+		return nil, fmt.Errorf("did not connect to %s: %v", addr, err)
+	}
+
+	return &treeEndpoint{
+		address: addr,
+		conn:    conn,
+		service: tree.NewTreeServiceClient(conn),
+	}, nil
+}
+
+// SetRetryPolicy sets retries of tree service requests failed because all endpoints are unavailable.
+// It must be called before TreeClient is used.
+func (c *TreeClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// CheckEndpoints runs healthchecks of all tree service endpoints. Endpoints failed the check
+// are tried by requests only after the healthy ones until they pass the check or serve a request.
+func (c *TreeClient) CheckEndpoints(ctx context.Context) []TreeEndpointStatus {
+	res := make([]TreeEndpointStatus, len(c.endpoints))
+	for i, e := range c.endpoints {
+		res[i] = TreeEndpointStatus{Address: e.address, Err: e.healthcheck(ctx)}
+	}
+	return res
+}
+
+// endpointsByHealth returns healthy endpoints followed by the unhealthy ones, both in the configured order.
+func (c *TreeClient) endpointsByHealth() []*treeEndpoint {
+	res := make([]*treeEndpoint, 0, len(c.endpoints))
+	for _, e := range c.endpoints {
+		if e.healthy() {
+			res = append(res, e)
+		}
+	}
+	for _, e := range c.endpoints {
+		if !e.healthy() {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// call runs op with the tree service endpoints until it doesn't fail with Unavailable status.
+// When all endpoints are unavailable the round is repeated according to the retry policy.
+// op must be safe to repeat, use callWrite for the requests changing the tree.
+func (c *TreeClient) call(ctx context.Context, op func(service tree.TreeServiceClient) error) error {
+	return c.callEndpoints(ctx, func(service tree.TreeServiceClient) (bool, error) {
+		err := op(service)
+		return isUnavailable(err), err
+	})
+}
+
+// callWrite runs non-idempotent op like call, but sends it to another endpoint only if
+// it failed before a stream to the endpoint was opened. Otherwise the endpoint could have
+// applied the request, so it isn't repeated to avoid duplicate tree nodes.
+func (c *TreeClient) callWrite(ctx context.Context, op func(service tree.TreeServiceClient, opts ...grpc.CallOption) error) error {
+	return c.callEndpoints(ctx, func(service tree.TreeServiceClient) (bool, error) {
+		var p peer.Peer
+		err := op(service, grpc.Peer(&p))
+		return isUnavailable(err) && p.Addr == nil, err
+	})
+}
+
+// callEndpoints runs op with the tree service endpoints while it reports the request
+// can be sent to another endpoint.
+func (c *TreeClient) callEndpoints(ctx context.Context, op func(service tree.TreeServiceClient) (bool, error)) error {
+	var (
+		failover bool
+		err      error
+	)
+	for attempt := 1; ; attempt++ {
+		for _, e := range c.endpointsByHealth() {
+			release, limitErr := c.limiter.acquire(ctx)
+			if limitErr != nil {
+				return fmt.Errorf("wait for metadata operations limit: %w", limitErr)
+			}
+			failover, err = op(e.service)
+			release()
+			e.setHealthy(!isUnavailable(err))
+			if !failover {
+				return err
+			}
+		}
+
+		if attempt >= c.retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(c.retry.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// isUnavailable checks if the request wasn't served because the endpoint is unreachable.
+// Such requests can be safely sent to another endpoint.
+func isUnavailable(err error) bool {
+	return err != nil && status.Code(err) == codes.Unavailable
+}
+
+// joinEndpointErrors combines errors of endpoints into a single error.
+func joinEndpointErrors(errs []error) error {
+	msgs := make([]string, len(errs))
+	for i := range errs {
+		msgs[i] = errs[i].Error()
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
@@ -0,0 +1,146 @@
+package neofs
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs/services/tree"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testTreeService struct {
+	tree.TreeServiceClient
+	err   error
+	calls int
+	// reached is set if the requests fail after the stream to the endpoint is opened.
+	reached bool
+}
+
+func (s *testTreeService) Healthcheck(context.Context, *tree.HealthcheckRequest, ...grpc.CallOption) (*tree.HealthcheckResponse, error) {
+	s.calls++
+	return &tree.HealthcheckResponse{}, s.err
+}
+
+func (s *testTreeService) Add(_ context.Context, _ *tree.AddRequest, opts ...grpc.CallOption) (*tree.AddResponse, error) {
+	s.calls++
+	if s.reached {
+		for _, o := range opts {
+			if p, ok := o.(grpc.PeerCallOption); ok {
+				p.PeerAddr.Addr = &net.TCPAddr{}
+			}
+		}
+	}
+	return &tree.AddResponse{}, s.err
+}
+
+func TestTreeClientFailover(t *testing.T) {
+	ctx := context.Background()
+	errUnavailable := status.Error(codes.Unavailable, "connection refused")
+
+	newClient := func(errs ...error) (*TreeClient, []*testTreeService) {
+		c := &TreeClient{}
+		c.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+		services := make([]*testTreeService, len(errs))
+		for i, err := range errs {
+			services[i] = &testTreeService{err: err}
+			c.endpoints = append(c.endpoints, &treeEndpoint{address: "endpoint", service: services[i]})
+		}
+		return c, services
+	}
+
+	healthcheck := func(service tree.TreeServiceClient) error {
+		_, err := service.Healthcheck(ctx, &tree.HealthcheckRequest{})
+		return err
+	}
+
+	t.Run("failover", func(t *testing.T) {
+		c, services := newClient(errUnavailable, nil)
+
+		require.NoError(t, c.call(ctx, healthcheck))
+		require.False(t, c.endpoints[0].healthy())
+		require.True(t, c.endpoints[1].healthy())
+
+		// healthy endpoint is tried first
+		require.NoError(t, c.call(ctx, healthcheck))
+		require.Equal(t, 1, services[0].calls)
+		require.Equal(t, 2, services[1].calls)
+
+		// endpoint passed the healthcheck is tried first again
+		services[0].err = nil
+		require.NoError(t, c.Healthcheck(ctx))
+		require.True(t, c.endpoints[0].healthy())
+		require.Equal(t, c.endpoints, c.endpointsByHealth())
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		errNotFound := status.Error(codes.NotFound, "not found")
+		c, services := newClient(errNotFound, nil)
+
+		require.ErrorIs(t, c.call(ctx, healthcheck), errNotFound)
+		require.True(t, c.endpoints[0].healthy())
+		require.Zero(t, services[1].calls)
+	})
+
+	t.Run("all unavailable", func(t *testing.T) {
+		c, services := newClient(errUnavailable, errUnavailable)
+
+		err := c.call(ctx, healthcheck)
+		require.Equal(t, codes.Unavailable, status.Code(err))
+		require.Equal(t, 2, services[0].calls)
+		require.Equal(t, 2, services[1].calls)
+		require.Error(t, c.Healthcheck(ctx))
+	})
+
+	t.Run("no retries", func(t *testing.T) {
+		c, services := newClient(errUnavailable)
+		c.SetRetryPolicy(RetryPolicy{})
+
+		require.ErrorIs(t, c.call(ctx, healthcheck), errUnavailable)
+		require.Equal(t, 1, services[0].calls)
+	})
+
+	add := func(service tree.TreeServiceClient, opts ...grpc.CallOption) error {
+		_, err := service.Add(ctx, &tree.AddRequest{}, opts...)
+		return err
+	}
+
+	t.Run("write not reached endpoint", func(t *testing.T) {
+		c, services := newClient(errUnavailable, nil)
+
+		require.NoError(t, c.callWrite(ctx, add))
+		require.Equal(t, 1, services[0].calls)
+		require.Equal(t, 1, services[1].calls)
+	})
+
+	t.Run("write reached endpoint", func(t *testing.T) {
+		c, services := newClient(errUnavailable, nil)
+		services[0].reached = true
+
+		require.ErrorIs(t, c.callWrite(ctx, add), errUnavailable)
+		require.False(t, c.endpoints[0].healthy())
+		require.Equal(t, 1, services[0].calls)
+		require.Zero(t, services[1].calls)
+	})
+
+	t.Run("metadata limit", func(t *testing.T) {
+		c, services := newClient(nil)
+		c.limiter = newOperationsLimiter(1)
+
+		release, err := c.limiter.acquire(ctx)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, c.call(ctx, healthcheck), context.DeadlineExceeded)
+		require.Zero(t, services[0].calls)
+
+		release()
+		require.NoError(t, c.call(context.Background(), healthcheck))
+	})
+}
@@ -2,10 +2,12 @@ package neofs
 
 import (
 	"errors"
+	"strconv"
 	"testing"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs/services/tree"
 	"github.com/stretchr/testify/require"
 )
 
@@ -121,3 +123,41 @@ func TestHandleError(t *testing.T) {
 		})
 	}
 }
+
+func TestVersionNodeOrder(t *testing.T) {
+	newNode := func(id, timestamp, monotonic uint64) *tree.GetNodeByPathResponse_Info {
+		node := &tree.GetNodeByPathResponse_Info{NodeId: id, Timestamp: timestamp}
+		if monotonic != 0 {
+			node.Meta = []*tree.KeyValue{{Key: monotonicTimeKV, Value: []byte(strconv.FormatUint(monotonic, 10))}}
+		}
+		return node
+	}
+
+	for _, tc := range []struct {
+		name          string
+		node, other   *tree.GetNodeByPathResponse_Info
+		expectedNewer bool
+	}{
+		{name: "timestamp", node: newNode(1, 2, 1), other: newNode(2, 1, 2), expectedNewer: true},
+		{name: "monotonic time", node: newNode(1, 1, 2), other: newNode(2, 1, 1), expectedNewer: true},
+		{name: "old gateway", node: newNode(1, 1, 1), other: newNode(2, 1, 0), expectedNewer: true},
+		{name: "node id", node: newNode(2, 1, 0), other: newNode(1, 1, 0), expectedNewer: true},
+		{name: "older", node: newNode(2, 1, 1), other: newNode(1, 1, 2), expectedNewer: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedNewer, isNewerNode(tc.node, tc.other))
+			require.Equal(t, !tc.expectedNewer, isNewerNode(tc.other, tc.node))
+		})
+	}
+}
+
+func TestMonotonicClock(t *testing.T) {
+	var clock monotonicClock
+
+	last := clock.now()
+	for i := 0; i < 1000; i++ {
+		now := clock.now()
+		require.Greater(t, now, last)
+		last = now
+	}
+}
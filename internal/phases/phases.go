@@ -0,0 +1,110 @@
+// Package phases accumulates time spent by a request in its phases: authentication,
+// tree service lookups and storage operations. It's used to explain slow requests.
+package phases
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Phase is a part of request processing.
+type Phase int
+
+// Phases of request processing.
+const (
+	// Auth is an authentication of the request including access box fetching.
+	Auth Phase = iota
+	// Tree is a time of tree service calls.
+	Tree
+	// Storage is a time of object operations to NeoFS.
+	Storage
+
+	count
+)
+
+// Durations contains time spent in each phase. Phases may overlap if operations
+// are run in parallel, so the sum can exceed the request duration.
+type Durations struct {
+	durations [count]int64
+}
+
+type ctxKey struct{}
+
+// String returns the name of the phase.
+func (p Phase) String() string {
+	switch p {
+	case Auth:
+		return "auth"
+	case Tree:
+		return "tree"
+	case Storage:
+		return "storage"
+	default:
+		return "unknown"
+	}
+}
+
+// All returns all phases in the order of output.
+func All() []Phase {
+	return []Phase{Auth, Tree, Storage}
+}
+
+// WithDurations returns ctx with new Durations which accumulate time of phases
+// observed with the returned context and contexts derived from it.
+func WithDurations(ctx context.Context) (context.Context, *Durations) {
+	d := new(Durations)
+	return context.WithValue(ctx, ctxKey{}, d), d
+}
+
+// Observe adds time since start to the phase of the request from ctx.
+// It does nothing if ctx has no Durations.
+func Observe(ctx context.Context, phase Phase, start time.Time) {
+	if d, ok := ctx.Value(ctxKey{}).(*Durations); ok {
+		d.Add(phase, time.Since(start))
+	}
+}
+
+// Add adds dur to the phase.
+func (d *Durations) Add(phase Phase, dur time.Duration) {
+	atomic.AddInt64(&d.durations[phase], int64(dur))
+}
+
+// Get returns time spent in the phase.
+func (d *Durations) Get(phase Phase) time.Duration {
+	return time.Duration(atomic.LoadInt64(&d.durations[phase]))
+}
+
+// UnaryClientInterceptor accounts time of unary gRPC calls to the phase.
+func UnaryClientInterceptor(phase Phase) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		defer Observe(ctx, phase, time.Now())
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor accounts time of opening gRPC streams and waiting for their messages to the phase.
+func StreamClientInterceptor(phase Phase) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		Observe(ctx, phase, start)
+		if err != nil {
+			return nil, err
+		}
+		return &observedStream{ClientStream: stream, ctx: ctx, phase: phase}, nil
+	}
+}
+
+type observedStream struct {
+	grpc.ClientStream
+	ctx   context.Context
+	phase Phase
+}
+
+func (s *observedStream) RecvMsg(m interface{}) error {
+	defer Observe(s.ctx, s.phase, time.Now())
+	return s.ClientStream.RecvMsg(m)
+}
@@ -0,0 +1,23 @@
+package phases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserve(t *testing.T) {
+	// no durations in context
+	Observe(context.Background(), Tree, time.Now())
+
+	ctx, d := WithDurations(context.Background())
+	Observe(ctx, Storage, time.Now().Add(-time.Second))
+	d.Add(Storage, time.Second)
+	d.Add(Auth, time.Millisecond)
+
+	require.GreaterOrEqual(t, d.Get(Storage), 2*time.Second)
+	require.Equal(t, time.Millisecond, d.Get(Auth))
+	require.Zero(t, d.Get(Tree))
+}
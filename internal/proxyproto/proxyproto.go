@@ -0,0 +1,261 @@
+// Package proxyproto implements the server side of PROXY protocol v1 and v2,
+// which load balancers use to pass the address of the client to the backend.
+// See https://www.haproxy.org/download/2.6/doc/proxy-protocol.txt.
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxV1HeaderLength is a maximum length of v1 header including CRLF.
+	maxV1HeaderLength = 107
+	// v2HeaderLength is a length of fixed part of v2 header.
+	v2HeaderLength = 16
+)
+
+var (
+	v1Signature = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// ErrNoHeader is returned if the connection doesn't start with PROXY protocol header.
+var ErrNoHeader = errors.New("no proxy protocol header")
+
+// Listener wraps net.Listener and reads PROXY protocol header of accepted connections.
+// Connections without the header are closed.
+type Listener struct {
+	net.Listener
+	headerTimeout time.Duration
+}
+
+// Conn is a connection with the addresses from PROXY protocol header.
+type Conn struct {
+	net.Conn
+	reader        *bufio.Reader
+	headerTimeout time.Duration
+
+	once       sync.Once
+	err        error
+	remoteAddr net.Addr
+	localAddr  net.Addr
+}
+
+// NewListener creates Listener. Header of accepted connection must be received within headerTimeout.
+func NewListener(ln net.Listener, headerTimeout time.Duration) *Listener {
+	return &Listener{
+		Listener:      ln,
+		headerTimeout: headerTimeout,
+	}
+}
+
+// Accept waits for the next connection. The header is read on the first use of the connection,
+// so a slow client doesn't block accepting other connections.
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{
+		Conn:          conn,
+		reader:        bufio.NewReader(conn),
+		headerTimeout: l.headerTimeout,
+	}, nil
+}
+
+func (c *Conn) readHeader() error {
+	c.once.Do(func() {
+		if c.headerTimeout > 0 {
+			if c.err = c.Conn.SetReadDeadline(time.Now().Add(c.headerTimeout)); c.err != nil {
+				return
+			}
+		}
+
+		c.remoteAddr, c.localAddr, c.err = readHeader(c.reader)
+		if c.err != nil {
+			c.err = fmt.Errorf("read proxy protocol header from %s: %w", c.Conn.RemoteAddr(), c.err)
+			_ = c.Conn.Close()
+			return
+		}
+
+		if c.headerTimeout > 0 {
+			c.err = c.Conn.SetReadDeadline(time.Time{})
+		}
+	})
+
+	return c.err
+}
+
+// Read reads data from the connection after PROXY protocol header.
+func (c *Conn) Read(b []byte) (int, error) {
+	if err := c.readHeader(); err != nil {
+		return 0, err
+	}
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the client address from PROXY protocol header.
+// If the header doesn't contain the address, the address of the peer is returned.
+func (c *Conn) RemoteAddr() net.Addr {
+	if err := c.readHeader(); err != nil || c.remoteAddr == nil {
+		return c.Conn.RemoteAddr()
+	}
+	return c.remoteAddr
+}
+
+// LocalAddr returns the destination address from PROXY protocol header.
+// If the header doesn't contain the address, the local address of the connection is returned.
+func (c *Conn) LocalAddr() net.Addr {
+	if err := c.readHeader(); err != nil || c.localAddr == nil {
+		return c.Conn.LocalAddr()
+	}
+	return c.localAddr
+}
+
+// readHeader reads PROXY protocol header of v1 or v2. Nil addresses are returned
+// if the connection was established by the proxy itself (LOCAL or UNKNOWN).
+func readHeader(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	sig, err := r.Peek(len(v1Signature))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if bytes.Equal(sig, v1Signature) {
+		return readV1Header(r)
+	}
+
+	if sig, err = r.Peek(len(v2Signature)); err == nil && bytes.Equal(sig, v2Signature) {
+		return readV2Header(r)
+	}
+
+	return nil, nil, ErrNoHeader
+}
+
+func readV1Header(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) >= maxV1HeaderLength {
+			return nil, nil, errors.New("v1 header is too long")
+		}
+
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		line = append(line, b)
+	}
+
+	fields := strings.Split(strings.TrimSuffix(string(line), "\r\n"), " ")
+	if len(fields) < 2 {
+		return nil, nil, errors.New("invalid v1 header")
+	}
+
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, nil, fmt.Errorf("unsupported v1 protocol '%s'", fields[1])
+	}
+
+	if len(fields) != 6 {
+		return nil, nil, errors.New("invalid v1 header")
+	}
+
+	src, err := parseV1Addr(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid source address: %w", err)
+	}
+	dst, err := parseV1Addr(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid destination address: %w", err)
+	}
+
+	return src, dst, nil
+}
+
+func parseV1Addr(ip, port string) (*net.TCPAddr, error) {
+	addr := &net.TCPAddr{IP: net.ParseIP(ip)}
+	if addr.IP == nil {
+		return nil, fmt.Errorf("invalid ip '%s'", ip)
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port '%s'", port)
+	}
+	addr.Port = int(p)
+
+	return addr, nil
+}
+
+func readV2Header(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	hdr := make([]byte, v2HeaderLength)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, nil, err
+	}
+
+	if version := hdr[12] >> 4; version != 2 {
+		return nil, nil, fmt.Errorf("unsupported v2 version %d", version)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(hdr[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, err
+	}
+
+	const (
+		cmdLocal = 0x0
+		cmdProxy = 0x1
+	)
+
+	switch cmd := hdr[12] & 0x0f; cmd {
+	case cmdLocal:
+		return nil, nil, nil
+	case cmdProxy:
+	default:
+		return nil, nil, fmt.Errorf("unsupported v2 command %d", cmd)
+	}
+
+	const (
+		familyInet  = 0x1
+		familyInet6 = 0x2
+	)
+
+	var ipLen int
+	switch family := hdr[13] >> 4; family {
+	case familyInet:
+		ipLen = net.IPv4len
+	case familyInet6:
+		ipLen = net.IPv6len
+	default:
+		// unix sockets and unspecified family don't carry IP addresses
+		return nil, nil, nil
+	}
+
+	if len(payload) < 2*ipLen+4 {
+		return nil, nil, errors.New("v2 addresses are too short")
+	}
+
+	src := &net.TCPAddr{
+		IP:   net.IP(payload[:ipLen]),
+		Port: int(binary.BigEndian.Uint16(payload[2*ipLen:])),
+	}
+	dst := &net.TCPAddr{
+		IP:   net.IP(payload[ipLen : 2*ipLen]),
+		Port: int(binary.BigEndian.Uint16(payload[2*ipLen+2:])),
+	}
+
+	return src, dst, nil
+}
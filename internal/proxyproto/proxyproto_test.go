@@ -0,0 +1,134 @@
+package proxyproto
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func v2Header(cmd, family byte, addrs []byte) []byte {
+	hdr := append([]byte{}, v2Signature...)
+	hdr = append(hdr, 0x20|cmd, family<<4|0x1, 0, 0)
+	binary.BigEndian.PutUint16(hdr[14:], uint16(len(addrs)))
+	return append(hdr, addrs...)
+}
+
+func TestListener(t *testing.T) {
+	ipv4Addrs := []byte{192, 168, 0, 1, 10, 0, 0, 1, 0x30, 0x39, 0x01, 0xbb}
+	ipv6Addrs := make([]byte, 36)
+	copy(ipv6Addrs, net.ParseIP("2001:db8::1"))
+	copy(ipv6Addrs[16:], net.ParseIP("2001:db8::2"))
+	binary.BigEndian.PutUint16(ipv6Addrs[32:], 12345)
+	binary.BigEndian.PutUint16(ipv6Addrs[34:], 443)
+
+	for _, tc := range []struct {
+		name       string
+		header     []byte
+		remoteAddr string
+		localAddr  string
+		err        bool
+	}{
+		{
+			name:       "v1 tcp4",
+			header:     []byte("PROXY TCP4 192.168.0.1 10.0.0.1 12345 443\r\n"),
+			remoteAddr: "192.168.0.1:12345",
+			localAddr:  "10.0.0.1:443",
+		},
+		{
+			name:       "v1 tcp6",
+			header:     []byte("PROXY TCP6 2001:db8::1 2001:db8::2 12345 443\r\n"),
+			remoteAddr: "[2001:db8::1]:12345",
+			localAddr:  "[2001:db8::2]:443",
+		},
+		{
+			name:   "v1 unknown",
+			header: []byte("PROXY UNKNOWN\r\n"),
+		},
+		{
+			name:       "v2 ipv4",
+			header:     v2Header(0x1, 0x1, ipv4Addrs),
+			remoteAddr: "192.168.0.1:12345",
+			localAddr:  "10.0.0.1:443",
+		},
+		{
+			name:       "v2 ipv6 with tlv",
+			header:     v2Header(0x1, 0x2, append(ipv6Addrs, 0x04, 0x00, 0x01, 0x00)),
+			remoteAddr: "[2001:db8::1]:12345",
+			localAddr:  "[2001:db8::2]:443",
+		},
+		{
+			name:   "v2 local",
+			header: v2Header(0x0, 0x0, nil),
+		},
+		{
+			name:   "no header",
+			header: []byte("GET / HTTP/1.1\r\n"),
+			err:    true,
+		},
+		{
+			name:   "invalid v1 address",
+			header: []byte("PROXY TCP4 192.168.0 10.0.0.1 12345 443\r\n"),
+			err:    true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer ln.Close()
+
+			pln := NewListener(ln, time.Second)
+
+			client, err := net.Dial("tcp", ln.Addr().String())
+			require.NoError(t, err)
+			defer client.Close()
+
+			_, err = client.Write(append(tc.header, []byte("payload")...))
+			require.NoError(t, err)
+
+			conn, err := pln.Accept()
+			require.NoError(t, err)
+			defer conn.Close()
+
+			buf := make([]byte, len("payload"))
+			_, err = io.ReadFull(conn, buf)
+			if tc.err {
+				require.Error(t, err)
+				require.Equal(t, client.LocalAddr().String(), conn.RemoteAddr().String())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "payload", string(buf))
+
+			if tc.remoteAddr == "" {
+				require.Equal(t, client.LocalAddr().String(), conn.RemoteAddr().String())
+				require.Equal(t, ln.Addr().String(), conn.LocalAddr().String())
+				return
+			}
+			require.Equal(t, tc.remoteAddr, conn.RemoteAddr().String())
+			require.Equal(t, tc.localAddr, conn.LocalAddr().String())
+		})
+	}
+}
+
+func TestListenerHeaderTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	pln := NewListener(ln, 50*time.Millisecond)
+
+	client, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	conn, err := pln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Read(make([]byte, 1))
+	require.Error(t, err)
+}
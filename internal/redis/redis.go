@@ -0,0 +1,126 @@
+// Package redis implements shared cache backend on top of Redis.
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+)
+
+// scanCount is a hint of the number of keys returned by a single SCAN call.
+const scanCount = 1000
+
+// Config contains parameters of connection to Redis.
+type Config struct {
+	Address  string
+	Password string
+	DB       int
+	// Timeout of a single operation.
+	Timeout time.Duration
+}
+
+// Backend is a cache.Backend storing entries in Redis.
+type Backend struct {
+	client  *redis.Client
+	timeout time.Duration
+}
+
+// NewBackend connects to Redis and checks the connection.
+func NewBackend(ctx context.Context, cfg Config) (*Backend, error) {
+	b := &Backend{
+		client: redis.NewClient(&redis.Options{
+			Addr:         cfg.Address,
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			DialTimeout:  cfg.Timeout,
+			ReadTimeout:  cfg.Timeout,
+			WriteTimeout: cfg.Timeout,
+		}),
+		timeout: cfg.Timeout,
+	}
+
+	ctx, cancel := b.context(ctx)
+	defer cancel()
+
+	if err := b.client.Ping(ctx).Err(); err != nil {
+		_ = b.client.Close()
+		return nil, fmt.Errorf("ping redis '%s': %w", cfg.Address, err)
+	}
+
+	return b, nil
+}
+
+// Get implements cache.Backend.
+func (b *Backend) Get(key string) ([]byte, error) {
+	ctx, cancel := b.context(context.Background())
+	defer cancel()
+
+	val, err := b.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, cache.ErrNotFound
+	}
+	return val, err
+}
+
+// Set implements cache.Backend.
+func (b *Backend) Set(key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := b.context(context.Background())
+	defer cancel()
+
+	return b.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete implements cache.Backend.
+func (b *Backend) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ctx, cancel := b.context(context.Background())
+	defer cancel()
+
+	return b.client.Del(ctx, keys...).Err()
+}
+
+// Keys implements cache.Backend.
+func (b *Backend) Keys(prefix string) ([]string, error) {
+	ctx, cancel := b.context(context.Background())
+	defer cancel()
+
+	var keys []string
+	iter := b.client.Scan(ctx, 0, escapePattern(prefix)+"*", scanCount).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+// Close closes the connection to Redis.
+func (b *Backend) Close() error {
+	return b.client.Close()
+}
+
+func (b *Backend) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, b.timeout)
+}
+
+// escapePattern escapes special characters of glob-style patterns of Redis.
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+// Package tracing contains helpers to instrument the gateway with OpenTelemetry spans
+// and to propagate trace context to NeoFS nodes and the tree service in gRPC metadata.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	instrumentationName = "github.com/nspcc-dev/neofs-s3-gw"
+	serviceName         = "neofs-s3-gw"
+)
+
+// Config contains parameters of spans export.
+type Config struct {
+	// Endpoint is an address of OTLP gRPC collector.
+	Endpoint string
+	// Insecure disables TLS of the connection to the collector.
+	Insecure bool
+	// SampleRatio is a fraction of traces started by the gateway to be recorded.
+	// Traces started by clients are recorded according to their sampling decision.
+	SampleRatio float64
+}
+
+type metadataCarrier struct {
+	md metadata.MD
+}
+
+// Init sets global tracer provider exporting spans to OTLP collector and W3C trace context propagation.
+// Returned function flushes remaining spans and must be called on shutdown.
+func Init(ctx context.Context, cfg Config, version string) (func(context.Context) error, error) {
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String(version),
+		)),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span which is a child of the span from ctx if any.
+// Spans are not recorded unless Init is called.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan marks the span as failed if err isn't nil and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// ExtractHTTP returns ctx with the trace context sent by the client in the request headers.
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// InjectGRPC returns ctx with the trace context of the current span in outgoing gRPC metadata,
+// so it's passed to NeoFS nodes with requests made with the context.
+func InjectGRPC(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier{md: md})
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// UnaryClientInterceptor creates a span for each unary gRPC call and propagates its context to the server.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := StartSpan(ctx, method, attribute.String("rpc.system", "grpc"))
+		err := invoker(InjectGRPC(ctx), method, req, reply, cc, opts...)
+		EndSpan(span, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor propagates trace context to the server of streaming gRPC calls.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		trace.SpanFromContext(ctx).AddEvent(method)
+		return streamer(InjectGRPC(ctx), desc, cc, method, opts...)
+	}
+}
+
+func (c metadataCarrier) Get(key string) string {
+	if vals := c.md.Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	c.md.Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.md))
+	for key := range c.md {
+		keys = append(keys, key)
+	}
+	return keys
+}
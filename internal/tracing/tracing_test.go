@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestPropagation(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	header := http.Header{}
+	header.Set("traceparent", traceparent)
+
+	ctx := ExtractHTTP(context.Background(), header)
+	sc := trace.SpanContextFromContext(ctx)
+	require.True(t, sc.IsRemote())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-amz-request-id", "id")
+	ctx = InjectGRPC(ctx)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, []string{traceparent}, md.Get("traceparent"))
+	require.Equal(t, []string{"id"}, md.Get("x-amz-request-id"))
+}